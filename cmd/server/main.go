@@ -1,7 +1,10 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
+
 	"github.com/rainset/gophkeeper/internal/server/app"
 	"github.com/rainset/gophkeeper/internal/server/config"
 )
@@ -26,5 +29,33 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	app.Run(cfg)
+
+	// команды после флагов: server [flags] migrate up|down|status, server [flags] gc [-delete] [-grace=24h],
+	// server [flags] backup [-base=previous.tar] archive.tar, server [flags] restore full.tar [incremental.tar...]
+	args := flag.Args()
+	if len(args) == 0 {
+		app.Run(cfg)
+		return
+	}
+
+	switch args[0] {
+	case "migrate":
+		if err = app.Migrate(cfg, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+	case "gc":
+		if err = app.GC(cfg, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+	case "backup":
+		if err = app.Backup(cfg, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+	case "restore":
+		if err = app.Restore(cfg, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown command %q", args[0])
+	}
 }
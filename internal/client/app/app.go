@@ -4,9 +4,11 @@ import (
 	"embed"
 	_ "embed"
 	"errors"
+	"fmt"
 	"github.com/rainset/gophkeeper/pkg/crypt"
 	"image/color"
 	"log"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -30,6 +32,7 @@ import (
 	smodel "github.com/rainset/gophkeeper/internal/server/model"
 	"github.com/rainset/gophkeeper/pkg/hash"
 	"github.com/rainset/gophkeeper/pkg/logger"
+	"github.com/rainset/gophkeeper/pkg/totp"
 )
 
 //go:embed icons/*
@@ -42,6 +45,11 @@ type App struct {
 	HTTPService *service.HTTPService
 	FileService *service.FileService
 	Channels    channel.Channels
+	filter      itemFilter
+	tickers     []func()
+	sshAgent    net.Listener
+	// customTemplate шаблон, записи которого показаны во вкладке записей по шаблонам
+	customTemplate int
 }
 
 func New(cfg *config.Config) *App {
@@ -71,6 +79,8 @@ func New(cfg *config.Config) *App {
 	}
 
 	HTTPService := service.NewHTTPService(cfg)
+	// незавершенные загрузки файлов продолжаются после перезапуска клиента
+	HTTPService.SetUploadStore(db)
 
 	return &App{
 		window:      w,
@@ -91,6 +101,7 @@ func (a *App) Run() {
 }
 
 func (a *App) pageAuth() {
+	a.stopSSHAgent()
 
 	authForm := a.authForm()
 	regForm := a.regForm()
@@ -131,7 +142,7 @@ func (a *App) pageMain(dataType ui.DataType) {
 		}
 	}()
 
-	addBtn := widget.NewButtonWithIcon("Добавить", theme.ContentAddIcon(), func() {
+	currentType := func() ui.DataType {
 		selectedTab := tabs.Selected()
 		switch selectedTab.Text {
 		case ui.TabCard.String():
@@ -142,8 +153,18 @@ func (a *App) pageMain(dataType ui.DataType) {
 			dataType = ui.TypeText
 		case ui.TabFile.String():
 			dataType = ui.TypeFile
+		case ui.TabSSH.String():
+			dataType = ui.TypeSSH
+		case ui.TabIdentity.String():
+			dataType = ui.TypeIdentity
+		case ui.TabCustom.String():
+			dataType = ui.TypeCustom
 		}
-		a.pageAdd(0, dataType)
+		return dataType
+	}
+
+	addBtn := widget.NewButtonWithIcon("Добавить", theme.ContentAddIcon(), func() {
+		a.pageAdd(0, currentType())
 	})
 
 	tasksBar := container.NewHBox(
@@ -154,7 +175,31 @@ func (a *App) pageMain(dataType ui.DataType) {
 			a.SyncData()
 		}),
 		addBtn,
+		widget.NewButtonWithIcon("Папки", theme.FolderIcon(), func() {
+			a.pageFolders(currentType())
+		}),
+		widget.NewButtonWithIcon("Метки", theme.ListIcon(), func() {
+			a.pageTags(currentType())
+		}),
+		widget.NewButtonWithIcon("Шаблоны", theme.DocumentIcon(), func() {
+			a.pageTemplates(currentType())
+		}),
+		widget.NewButtonWithIcon("Доступные мне", theme.AccountIcon(), func() {
+			a.pageIncomingShares(currentType())
+		}),
+		widget.NewButtonWithIcon("Организации", theme.HomeIcon(), func() {
+			a.pageOrganizations(currentType())
+		}),
+		widget.NewButtonWithIcon("Экстренный доступ", theme.WarningIcon(), func() {
+			a.pageEmergency(currentType())
+		}),
+		widget.NewButtonWithIcon("Одноразовые ссылки", theme.MailSendIcon(), func() {
+			a.pageSecrets(currentType())
+		}),
 		layout.NewSpacer(),
+		widget.NewButtonWithIcon("Настройки", theme.SettingsIcon(), func() {
+			a.pageSettings(currentType())
+		}),
 		widget.NewButtonWithIcon("Выйти", theme.ContentClearIcon(), func() {
 			a.pageAuth()
 		}),
@@ -164,12 +209,18 @@ func (a *App) pageMain(dataType ui.DataType) {
 	tabCred := container.NewTabItem(ui.TabCred.String(), container.New(layout.NewPaddedLayout(), a.credList()))
 	tabText := container.NewTabItem(ui.TabText.String(), container.New(layout.NewPaddedLayout(), a.textList()))
 	tabFile := container.NewTabItem(ui.TabFile.String(), container.New(layout.NewPaddedLayout(), a.fileList()))
+	tabSSH := container.NewTabItem(ui.TabSSH.String(), container.New(layout.NewPaddedLayout(), a.sshKeyList()))
+	tabIdentity := container.NewTabItem(ui.TabIdentity.String(), container.New(layout.NewPaddedLayout(), a.identityList()))
+	tabCustom := container.NewTabItem(ui.TabCustom.String(), container.New(layout.NewPaddedLayout(), a.customList()))
 
 	tabs = container.NewAppTabs(
 		tabCard,
 		tabCred,
 		tabText,
 		tabFile,
+		tabSSH,
+		tabIdentity,
+		tabCustom,
 	)
 
 	switch dataType {
@@ -181,12 +232,22 @@ func (a *App) pageMain(dataType ui.DataType) {
 		tabs.Select(tabText)
 	case ui.TypeFile:
 		tabs.Select(tabFile)
+	case ui.TypeSSH:
+		tabs.Select(tabSSH)
+	case ui.TypeIdentity:
+		tabs.Select(tabIdentity)
+	case ui.TypeCustom:
+		tabs.Select(tabCustom)
 	}
 
 	content = container.NewVBox(
 		tasksBar,
 		canvas.NewLine(color.Black),
 		syncBar,
+		a.collectionBar(currentType),
+		a.reminderBar(),
+		a.urlSearchBar(currentType),
+		a.filterBar(currentType),
 		tabs,
 	)
 
@@ -212,12 +273,18 @@ func (a *App) pageAdd(localID int, dataType ui.DataType) {
 	tabCred := container.NewTabItem(ui.TabCred.String(), container.New(layout.NewPaddedLayout(), a.addCredForm(localID)))
 	tabText := container.NewTabItem(ui.TabText.String(), container.New(layout.NewPaddedLayout(), a.addTextForm(localID)))
 	tabFile := container.NewTabItem(ui.TabFile.String(), container.New(layout.NewPaddedLayout(), a.addFileForm(localID)))
+	tabSSH := container.NewTabItem(ui.TabSSH.String(), container.New(layout.NewPaddedLayout(), a.addSSHKeyForm(localID)))
+	tabIdentity := container.NewTabItem(ui.TabIdentity.String(), container.New(layout.NewPaddedLayout(), a.addIdentityForm(localID)))
+	tabCustom := container.NewTabItem(ui.TabCustom.String(), container.New(layout.NewPaddedLayout(), a.addCustomForm(localID)))
 
 	tabs := container.NewAppTabs(
 		tabCard,
 		tabCred,
 		tabText,
 		tabFile,
+		tabSSH,
+		tabIdentity,
+		tabCustom,
 	)
 
 	switch dataType {
@@ -229,6 +296,12 @@ func (a *App) pageAdd(localID int, dataType ui.DataType) {
 		tabs.Select(tabText)
 	case ui.TypeFile:
 		tabs.Select(tabFile)
+	case ui.TypeSSH:
+		tabs.Select(tabSSH)
+	case ui.TypeIdentity:
+		tabs.Select(tabIdentity)
+	case ui.TypeCustom:
+		tabs.Select(tabCustom)
 	}
 
 	content = container.NewVBox(
@@ -250,18 +323,7 @@ func (a *App) pageEdit(localID int, dataType ui.DataType) {
 	deleteBtn := widget.NewButtonWithIcon("Удалить", theme.DeleteIcon(), func() {
 		dialog.ShowConfirm("Удалить", "Вы действительно хотите удалить запись?", func(b bool) {
 			if b {
-				var err error
-				switch dataType {
-				case ui.TypeCard:
-					err = a.DeleteCard(localID)
-				case ui.TypeCred:
-					err = a.DeleteCred(localID)
-				case ui.TypeText:
-					err = a.DeleteText(localID)
-				case ui.TypeFile:
-					err = a.DeleteFile(localID)
-				}
-
+				err := a.DeleteItem(dataType, localID)
 				if err != nil {
 
 					logger.Error("delete:", err, dataType, localID)
@@ -297,6 +359,12 @@ func (a *App) pageEdit(localID int, dataType ui.DataType) {
 		editCont = container.New(layout.NewPaddedLayout(), a.addTextForm(localID))
 	case ui.TypeFile:
 		editCont = container.New(layout.NewPaddedLayout(), a.addFileForm(localID))
+	case ui.TypeSSH:
+		editCont = container.New(layout.NewPaddedLayout(), a.addSSHKeyForm(localID))
+	case ui.TypeIdentity:
+		editCont = container.New(layout.NewPaddedLayout(), a.addIdentityForm(localID))
+	case ui.TypeCustom:
+		editCont = container.New(layout.NewPaddedLayout(), a.addCustomForm(localID))
 	}
 
 	content = container.NewVBox(
@@ -305,7 +373,16 @@ func (a *App) pageEdit(localID int, dataType ui.DataType) {
 		editCont,
 	)
 
+	if localID > 0 {
+		content.Add(a.attachmentsBox(dataType, localID))
+	}
+
+	if localID > 0 && shareItemTypes[dataType] {
+		content.Add(a.sharesBox(dataType, localID))
+	}
+
 	a.window.SetContent(content)
+	a.runTickers(content)
 }
 
 func (a *App) authForm() *widget.Form {
@@ -411,6 +488,13 @@ func (a *App) regForm() *widget.Form {
 				return
 			}
 
+			var respErr *service.ResponseError
+			if errors.As(err, &respErr) {
+				dialog.ShowError(respErr, a.window)
+
+				return
+			}
+
 			dialog.ShowError(service.ErrServer, a.window)
 
 			return
@@ -510,6 +594,14 @@ func (a *App) addCardForm(localID int) *fyne.Container {
 		metaFormItem,
 	)
 
+	fieldsBox, getFields := fieldsEditor(item.Fields)
+	addForm.Append("Поля", fieldsBox)
+
+	refsFormItems, getRefs := a.itemRefsFormItems(item.FolderID, item.TagIDs)
+	for _, v := range refsFormItems {
+		addForm.AppendItem(v)
+	}
+
 	addForm.CancelText = "Отмена"
 	addForm.OnCancel = func() {
 		a.pageMain(ui.TypeCard)
@@ -527,6 +619,14 @@ func (a *App) addCardForm(localID int) *fyne.Container {
 			UpdatedAt: time.Now(),
 		}
 
+		cardData.FolderID, cardData.TagIDs = getRefs()
+
+		cardData.Fields, err = getFields()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
 		if localID > 0 {
 			cardData.LocalID = item.LocalID
 			cardData.ExternalID = item.ExternalID
@@ -543,7 +643,7 @@ func (a *App) addCardForm(localID int) *fyne.Container {
 
 		a.pageMain(ui.TypeCard)
 	}
-	return container.NewVBox(addForm)
+	return container.NewVBox(append(a.totpViews("", item.Fields), addForm)...)
 }
 
 func (a *App) addCredForm(localID int) *fyne.Container {
@@ -554,17 +654,20 @@ func (a *App) addCredForm(localID int) *fyne.Container {
 	var username *widget.Entry
 	var password *widget.Entry
 	var meta *widget.Entry
+	var otp *widget.Entry
 	var addForm *widget.Form
 
 	var titleFormItem *widget.FormItem
 	var usernameFormItem *widget.FormItem
 	var passwordFormItem *widget.FormItem
 	var metaFormItem *widget.FormItem
+	var otpFormItem *widget.FormItem
 
 	title = widget.NewEntry()
 	username = widget.NewEntry()
 	password = widget.NewPasswordEntry()
 	meta = widget.NewMultiLineEntry()
+	otp = widget.NewPasswordEntry()
 
 	if localID > 0 {
 		item, err = a.GetCred(localID)
@@ -575,24 +678,54 @@ func (a *App) addCredForm(localID int) *fyne.Container {
 		username.Text = item.Username
 		password.Text = item.Password
 		meta.Text = item.Meta
+		otp.Text = item.TOTP
 	}
 
 	title.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
 	username.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
 	password.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
+	otp.Validator = validateTOTP
+	otp.SetPlaceHolder("otpauth://totp/... или секрет base32")
+	// при импорте otpauth:// URI заполняем пустые заголовок и имя пользователя из метки
+	otp.OnChanged = func(s string) {
+		key, err := totp.ParseURI(s)
+		if err != nil {
+			return
+		}
+
+		if title.Text == "" && key.Issuer != "" {
+			title.SetText(key.Issuer)
+		}
+		if username.Text == "" && key.Account != "" {
+			username.SetText(key.Account)
+		}
+	}
 
 	titleFormItem = widget.NewFormItem("Заголовок", title)
 	usernameFormItem = widget.NewFormItem("Имя пользователя", username)
 	passwordFormItem = widget.NewFormItem("Пароль", password)
 	metaFormItem = widget.NewFormItem("Дополнительно", meta)
+	otpFormItem = widget.NewFormItem("TOTP", otp)
 
 	addForm = widget.NewForm(
 		titleFormItem,
 		usernameFormItem,
 		passwordFormItem,
+		otpFormItem,
 		metaFormItem,
 	)
 
+	urlsBox, getURLs := credURLsEditor(item.URLs)
+	addForm.Append("Сайты", urlsBox)
+
+	fieldsBox, getFields := fieldsEditor(item.Fields)
+	addForm.Append("Поля", fieldsBox)
+
+	refsFormItems, getRefs := a.itemRefsFormItems(item.FolderID, item.TagIDs)
+	for _, v := range refsFormItems {
+		addForm.AppendItem(v)
+	}
+
 	addForm.CancelText = "Отмена"
 	addForm.OnCancel = func() {
 		a.pageMain(ui.TypeCred)
@@ -606,9 +739,24 @@ func (a *App) addCredForm(localID int) *fyne.Container {
 			Username:  username.Text,
 			Password:  password.Text,
 			Meta:      meta.Text,
+			TOTP:      otp.Text,
 			UpdatedAt: time.Now(),
 		}
 
+		credData.FolderID, credData.TagIDs = getRefs()
+
+		credData.Fields, err = getFields()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
+		credData.URLs, err = getURLs()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
 		if localID > 0 {
 			credData.LocalID = item.LocalID
 			credData.ExternalID = item.ExternalID
@@ -624,6 +772,10 @@ func (a *App) addCredForm(localID int) *fyne.Container {
 
 		a.pageMain(ui.TypeCred)
 	}
+	if localID > 0 {
+		return container.NewVBox(append(a.totpViews(item.TOTP, item.Fields), addForm)...)
+	}
+
 	return container.NewVBox(addForm)
 }
 
@@ -667,6 +819,14 @@ func (a *App) addTextForm(localID int) *fyne.Container {
 		metaFormItem,
 	)
 
+	fieldsBox, getFields := fieldsEditor(item.Fields)
+	addForm.Append("Поля", fieldsBox)
+
+	refsFormItems, getRefs := a.itemRefsFormItems(item.FolderID, item.TagIDs)
+	for _, v := range refsFormItems {
+		addForm.AppendItem(v)
+	}
+
 	addForm.CancelText = "Отмена"
 	addForm.OnCancel = func() {
 		a.pageMain(ui.TypeText)
@@ -682,6 +842,14 @@ func (a *App) addTextForm(localID int) *fyne.Container {
 			UpdatedAt: time.Now(),
 		}
 
+		textData.FolderID, textData.TagIDs = getRefs()
+
+		textData.Fields, err = getFields()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
 		if localID > 0 {
 			textData.LocalID = item.LocalID
 			textData.ExternalID = item.ExternalID
@@ -697,7 +865,7 @@ func (a *App) addTextForm(localID int) *fyne.Container {
 
 		a.pageMain(ui.TypeText)
 	}
-	return container.NewVBox(addForm)
+	return container.NewVBox(append(a.totpViews("", item.Fields), addForm)...)
 }
 
 func (a *App) addFileForm(localID int) *fyne.Container {
@@ -760,6 +928,14 @@ func (a *App) addFileForm(localID int) *fyne.Container {
 		metaFormItem,
 	)
 
+	fieldsBox, getFields := fieldsEditor(item.Fields)
+	addForm.Append("Поля", fieldsBox)
+
+	refsFormItems, getRefs := a.itemRefsFormItems(item.FolderID, item.TagIDs)
+	for _, v := range refsFormItems {
+		addForm.AppendItem(v)
+	}
+
 	addForm.CancelText = "Отмена"
 	addForm.OnCancel = func() {
 		a.pageMain(ui.TypeFile)
@@ -775,6 +951,13 @@ func (a *App) addFileForm(localID int) *fyne.Container {
 			Meta:      meta.Text,
 			UpdatedAt: time.Now(),
 		}
+		saveItem.FolderID, saveItem.TagIDs = getRefs()
+
+		saveItem.Fields, err = getFields()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
 
 		if tempFile.exists {
 			filePath, err := a.FileService.SaveFile(tempFile.r, tempFile.ext)
@@ -819,7 +1002,7 @@ func (a *App) addFileForm(localID int) *fyne.Container {
 		linkContainer := container.NewVBox(widget.NewLabel(""), canvas.NewLine(color.Black), link)
 
 		//return container.NewVBox(addForm, linkContainer, container.NewCenter(widget.NewLabel(item.Path)))
-		return container.NewVBox(addForm, linkContainer)
+		return container.NewVBox(append(a.totpViews("", item.Fields), addForm, linkContainer)...)
 	}
 
 	return container.NewVBox(addForm)
@@ -832,10 +1015,15 @@ func (a *App) cardList() *fyne.Container {
 
 	noItems := container.NewCenter(canvas.NewText("Нет записей", color.Black))
 
-	cards, err = a.GetAllCards()
+	allCards, err := a.GetAllCards()
 	if err != nil {
 		logger.Error(err)
 	}
+	for _, v := range allCards {
+		if a.filter.match(v.FolderID, v.TagIDs) {
+			cards = append(cards, v)
+		}
+	}
 	cardsList = widget.NewList(
 		func() int {
 			return len(cards)
@@ -870,10 +1058,15 @@ func (a *App) credList() *fyne.Container {
 
 	noItems := container.NewCenter(canvas.NewText("Нет записей", color.Black))
 
-	creds, err = a.GetAllCreds()
+	allCreds, err := a.GetAllCreds()
 	if err != nil {
 		logger.Error(err)
 	}
+	for _, v := range allCreds {
+		if a.filter.match(v.FolderID, v.TagIDs) {
+			creds = append(creds, v)
+		}
+	}
 	credsList = widget.NewList(
 		func() int {
 			return len(creds)
@@ -908,10 +1101,15 @@ func (a *App) textList() *fyne.Container {
 
 	noItems := container.NewCenter(canvas.NewText("Нет записей", color.Black))
 
-	texts, err = a.GetAllTexts()
+	allTexts, err := a.GetAllTexts()
 	if err != nil {
 		logger.Error(err)
 	}
+	for _, v := range allTexts {
+		if a.filter.match(v.FolderID, v.TagIDs) {
+			texts = append(texts, v)
+		}
+	}
 	textsList = widget.NewList(
 		func() int {
 			return len(texts)
@@ -946,10 +1144,15 @@ func (a *App) fileList() *fyne.Container {
 
 	noItems := container.NewCenter(canvas.NewText("Нет записей", color.Black))
 
-	files, err = a.GetAllFiles()
+	allFiles, err := a.GetAllFiles()
 	if err != nil {
 		logger.Error(err)
 	}
+	for _, v := range allFiles {
+		if a.filter.match(v.FolderID, v.TagIDs) {
+			files = append(files, v)
+		}
+	}
 	filesList = widget.NewList(
 		func() int {
 			return len(files)
@@ -1023,6 +1226,11 @@ func (a *App) AddCard(card *model.DataCard, encrypted bool) (err error) {
 		card.Date = crypt.EncodeBase64(encDate)
 		card.Cvv = crypt.EncodeBase64(encCvv)
 		card.Meta = crypt.EncodeBase64(encMeta)
+
+		card.Fields, err = encryptFields(card.Fields, sKey)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = a.db.AddCard(card)
@@ -1033,20 +1241,8 @@ func (a *App) AddCard(card *model.DataCard, encrypted bool) (err error) {
 	return err
 }
 
-func (a *App) GetCard(localID int) (card model.DataCard, err error) {
-
-	c, err := a.GetUserConfig()
-	if err != nil {
-		return card, err
-	}
-
-	card, err = a.db.GetCard(localID)
-	if err != nil {
-		return card, err
-	}
-
-	sKey := crypt.DecodeBase64(c.SignKey)
-
+// decryptCard расшифровывает карту из локальной БД.
+func decryptCard(card model.DataCard, sKey []byte) (model.DataCard, error) {
 	decNumber, err := crypt.Decrypt(crypt.DecodeBase64(card.Number), sKey)
 	if err != nil {
 		return card, err
@@ -1072,9 +1268,26 @@ func (a *App) GetCard(localID int) (card model.DataCard, err error) {
 	card.Cvv = string(decCvv)
 	card.Meta = string(decMeta)
 
+	card.Fields, err = decryptFields(card.Fields, sKey)
+
 	return card, err
 }
 
+func (a *App) GetCard(localID int) (card model.DataCard, err error) {
+
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return card, err
+	}
+
+	card, err = a.db.GetCard(localID)
+	if err != nil {
+		return card, err
+	}
+
+	return decryptCard(card, crypt.DecodeBase64(c.SignKey))
+}
+
 func (a *App) GetAllCards() (cards []model.DataCard, err error) {
 	c, err := a.GetUserConfig()
 	if err != nil {
@@ -1085,30 +1298,10 @@ func (a *App) GetAllCards() (cards []model.DataCard, err error) {
 	cardEnc, err := a.db.GetAllCards()
 
 	for _, v := range cardEnc {
-		decNumber, err := crypt.Decrypt(crypt.DecodeBase64(v.Number), sKey)
-		if err != nil {
-			return cards, err
-		}
-
-		decDate, err := crypt.Decrypt(crypt.DecodeBase64(v.Date), sKey)
-		if err != nil {
-			return cards, err
-		}
-
-		decCvv, err := crypt.Decrypt(crypt.DecodeBase64(v.Cvv), sKey)
+		v, err = decryptCard(v, sKey)
 		if err != nil {
 			return cards, err
 		}
-
-		decMeta, err := crypt.Decrypt(crypt.DecodeBase64(v.Meta), sKey)
-		if err != nil {
-			return cards, err
-		}
-
-		v.Number = string(decNumber)
-		v.Date = string(decDate)
-		v.Cvv = string(decCvv)
-		v.Meta = string(decMeta)
 		cards = append(cards, v)
 	}
 
@@ -1168,6 +1361,21 @@ func (a *App) AddCred(cred *model.DataCred, encrypted bool) (err error) {
 		cred.Username = crypt.EncodeBase64(encUsername)
 		cred.Password = crypt.EncodeBase64(encPass)
 		cred.Meta = crypt.EncodeBase64(encMeta)
+
+		cred.Fields, err = encryptFields(cred.Fields, sKey)
+		if err != nil {
+			return err
+		}
+
+		cred.URLs, err = encryptURLs(cred.URLs, sKey)
+		if err != nil {
+			return err
+		}
+
+		cred.TOTP, err = encryptOptional(cred.TOTP, sKey)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = a.db.AddCred(cred)
@@ -1178,41 +1386,56 @@ func (a *App) AddCred(cred *model.DataCred, encrypted bool) (err error) {
 	return err
 }
 
-func (a *App) GetCred(localID int) (cred model.DataCred, err error) {
-	c, err := a.GetUserConfig()
+// decryptCred расшифровывает учетную запись из локальной БД.
+func decryptCred(cred model.DataCred, sKey []byte) (model.DataCred, error) {
+	decUsername, err := crypt.Decrypt(crypt.DecodeBase64(cred.Username), sKey)
 	if err != nil {
 		return cred, err
 	}
 
-	cred, err = a.db.GetCred(localID)
+	decPass, err := crypt.Decrypt(crypt.DecodeBase64(cred.Password), sKey)
 	if err != nil {
 		return cred, err
 	}
 
-	sKey := crypt.DecodeBase64(c.SignKey)
-
-	decUsername, err := crypt.Decrypt(crypt.DecodeBase64(cred.Username), sKey)
+	decMeta, err := crypt.Decrypt(crypt.DecodeBase64(cred.Meta), sKey)
 	if err != nil {
 		return cred, err
 	}
 
-	decPass, err := crypt.Decrypt(crypt.DecodeBase64(cred.Password), sKey)
+	cred.Username = string(decUsername)
+	cred.Password = string(decPass)
+	cred.Meta = string(decMeta)
+
+	cred.Fields, err = decryptFields(cred.Fields, sKey)
 	if err != nil {
 		return cred, err
 	}
 
-	decMeta, err := crypt.Decrypt(crypt.DecodeBase64(cred.Meta), sKey)
+	cred.URLs, err = decryptURLs(cred.URLs, sKey)
 	if err != nil {
 		return cred, err
 	}
 
-	cred.Username = string(decUsername)
-	cred.Password = string(decPass)
-	cred.Meta = string(decMeta)
+	cred.TOTP, err = decryptOptional(cred.TOTP, sKey)
 
 	return cred, err
 }
 
+func (a *App) GetCred(localID int) (cred model.DataCred, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return cred, err
+	}
+
+	cred, err = a.db.GetCred(localID)
+	if err != nil {
+		return cred, err
+	}
+
+	return decryptCred(cred, crypt.DecodeBase64(c.SignKey))
+}
+
 func (a *App) GetAllCreds() (creds []model.DataCred, err error) {
 	c, err := a.GetUserConfig()
 	if err != nil {
@@ -1223,26 +1446,11 @@ func (a *App) GetAllCreds() (creds []model.DataCred, err error) {
 	credsEnc, err := a.db.GetAllCreds()
 
 	for _, v := range credsEnc {
-
-		decUsername, err := crypt.Decrypt(crypt.DecodeBase64(v.Username), sKey)
-		if err != nil {
-			return creds, err
-		}
-
-		decPass, err := crypt.Decrypt(crypt.DecodeBase64(v.Password), sKey)
-		if err != nil {
-			return creds, err
-		}
-
-		decMeta, err := crypt.Decrypt(crypt.DecodeBase64(v.Meta), sKey)
+		v, err = decryptCred(v, sKey)
 		if err != nil {
 			return creds, err
 		}
 
-		v.Username = string(decUsername)
-		v.Password = string(decPass)
-		v.Meta = string(decMeta)
-
 		creds = append(creds, v)
 	}
 
@@ -1295,6 +1503,11 @@ func (a *App) AddText(text *model.DataText, encrypted bool) (err error) {
 
 		text.Text = crypt.EncodeBase64(encText)
 		text.Meta = crypt.EncodeBase64(encMeta)
+
+		text.Fields, err = encryptFields(text.Fields, sKey)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = a.db.AddText(text)
@@ -1305,33 +1518,38 @@ func (a *App) AddText(text *model.DataText, encrypted bool) (err error) {
 	return err
 }
 
-func (a *App) GetText(localID int) (text model.DataText, err error) {
-	c, err := a.GetUserConfig()
+// decryptText расшифровывает текстовую запись из локальной БД.
+func decryptText(text model.DataText, sKey []byte) (model.DataText, error) {
+	decText, err := crypt.Decrypt(crypt.DecodeBase64(text.Text), sKey)
 	if err != nil {
 		return text, err
 	}
 
-	text, err = a.db.GetText(localID)
+	decMeta, err := crypt.Decrypt(crypt.DecodeBase64(text.Meta), sKey)
 	if err != nil {
 		return text, err
 	}
 
-	sKey := crypt.DecodeBase64(c.SignKey)
+	text.Text = string(decText)
+	text.Meta = string(decMeta)
 
-	decText, err := crypt.Decrypt(crypt.DecodeBase64(text.Text), sKey)
+	text.Fields, err = decryptFields(text.Fields, sKey)
+
+	return text, err
+}
+
+func (a *App) GetText(localID int) (text model.DataText, err error) {
+	c, err := a.GetUserConfig()
 	if err != nil {
 		return text, err
 	}
 
-	decMeta, err := crypt.Decrypt(crypt.DecodeBase64(text.Meta), sKey)
+	text, err = a.db.GetText(localID)
 	if err != nil {
 		return text, err
 	}
 
-	text.Text = string(decText)
-	text.Meta = string(decMeta)
-
-	return text, err
+	return decryptText(text, crypt.DecodeBase64(c.SignKey))
 }
 
 func (a *App) GetAllTexts() (texts []model.DataText, err error) {
@@ -1344,20 +1562,11 @@ func (a *App) GetAllTexts() (texts []model.DataText, err error) {
 	textsEnc, err := a.db.GetAllTexts()
 
 	for _, v := range textsEnc {
-
-		decText, err := crypt.Decrypt(crypt.DecodeBase64(v.Text), sKey)
+		v, err = decryptText(v, sKey)
 		if err != nil {
 			return texts, err
 		}
 
-		decMeta, err := crypt.Decrypt(crypt.DecodeBase64(v.Meta), sKey)
-		if err != nil {
-			return texts, err
-		}
-
-		v.Text = string(decText)
-		v.Meta = string(decMeta)
-
 		texts = append(texts, v)
 	}
 
@@ -1404,6 +1613,11 @@ func (a *App) AddFile(file *model.DataFile, encrypted bool) (err error) {
 		}
 
 		file.Meta = crypt.EncodeBase64(encMeta)
+
+		file.Fields, err = encryptFields(file.Fields, sKey)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = a.db.AddFile(file)
@@ -1434,6 +1648,8 @@ func (a *App) GetFile(localID int) (file model.DataFile, err error) {
 
 	file.Meta = string(decMeta)
 
+	file.Fields, err = decryptFields(file.Fields, sKey)
+
 	return file, err
 }
 
@@ -1455,6 +1671,11 @@ func (a *App) GetAllFiles() (files []model.DataFile, err error) {
 
 		v.Meta = string(decMeta)
 
+		v.Fields, err = decryptFields(v.Fields, sKey)
+		if err != nil {
+			return files, err
+		}
+
 		files = append(files, v)
 	}
 
@@ -1503,18 +1724,16 @@ func (a *App) SyncData() {
 	if err != nil {
 		logger.Error(err)
 
-		if err == service.ErrStatusUnauthorized {
+		if errors.Is(err, service.ErrStatusUnauthorized) {
 			dialog.ShowError(errors.New("сессия устарела, авторизуйтесь повторно"), a.window)
 			a.pageAuth()
 
 			return
 		}
 
-		if err == service.ErrStatusUnauthorized {
-			dialog.ShowError(errors.New("ошибка соединения с сервером"), a.window)
+		dialog.ShowError(fmt.Errorf("%w: %v", service.ErrServer, err), a.window)
 
-			return
-		}
+		return
 	}
 
 	c.RefreshToken = tokens.RefreshToken
@@ -1527,9 +1746,27 @@ func (a *App) SyncData() {
 		return
 	}
 
+	err = a.SyncFolders(tokens.AccessToken)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
+		return
+	}
+
+	err = a.SyncTags(tokens.AccessToken)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
+		return
+	}
+
+	err = a.SyncTemplates(tokens.AccessToken)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
+		return
+	}
+
 	err = a.SyncCards(tokens.AccessToken)
 	if err != nil {
-		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
 		return
 	}
 
@@ -1537,7 +1774,7 @@ func (a *App) SyncData() {
 
 	err = a.SyncCreds(tokens.AccessToken)
 	if err != nil {
-		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
 		return
 	}
 
@@ -1545,7 +1782,25 @@ func (a *App) SyncData() {
 
 	err = a.SyncTexts(tokens.AccessToken)
 	if err != nil {
-		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
+		return
+	}
+
+	err = a.SyncSSHKeys(tokens.AccessToken)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
+		return
+	}
+
+	err = a.SyncIdentities(tokens.AccessToken)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
+		return
+	}
+
+	err = a.SyncCustomItems(tokens.AccessToken)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
 		return
 	}
 
@@ -1553,7 +1808,19 @@ func (a *App) SyncData() {
 
 	err = a.SyncFiles(tokens.AccessToken)
 	if err != nil {
-		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
+		return
+	}
+
+	err = a.SyncAttachments(tokens.AccessToken)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
+		return
+	}
+
+	err = a.SyncShares(tokens.AccessToken)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("ошибка запроса списка с сервера: %w", err), a.window)
 		return
 	}
 
@@ -1588,6 +1855,11 @@ func (a *App) SyncCards(accessToken string) (err error) {
 		getCardsMap[v.ExternalID] = v
 	}
 
+	refs, err := a.loadItemRefs()
+	if err != nil {
+		return err
+	}
+
 	// создаем записи в бд клиента
 	for _, v := range getCards {
 
@@ -1599,6 +1871,7 @@ func (a *App) SyncCards(accessToken string) (err error) {
 		}
 
 		updateCard := v
+		updateCard.FolderID, updateCard.TagIDs = refs.toLocal(v.FolderID, v.TagIDs)
 		if ok {
 			updateCard.LocalID = val.LocalID
 		}
@@ -1629,6 +1902,8 @@ func (a *App) SyncCards(accessToken string) (err error) {
 			Meta:      v.Meta,
 			UpdatedAt: v.UpdatedAt,
 		}
+		reqBody.FolderID, reqBody.TagIDs = refs.toExternal(v.FolderID, v.TagIDs)
+		reqBody.Fields = toServerFields(v.Fields)
 
 		id, err := a.HTTPService.AddCard(c.AccessToken, reqBody)
 		if err != nil {
@@ -1671,6 +1946,11 @@ func (a *App) SyncCreds(accessToken string) (err error) {
 		getCredsMap[v.ExternalID] = v
 	}
 
+	refs, err := a.loadItemRefs()
+	if err != nil {
+		return err
+	}
+
 	// создаем записи в бд клиента
 	for _, v := range getCreds {
 		if val, ok := credsMap[v.ExternalID]; ok {
@@ -1678,6 +1958,7 @@ func (a *App) SyncCreds(accessToken string) (err error) {
 			if val.UpdatedAt.Unix() < v.UpdatedAt.Unix() {
 				updateCred := v
 				updateCred.LocalID = val.LocalID
+				updateCred.FolderID, updateCred.TagIDs = refs.toLocal(v.FolderID, v.TagIDs)
 				errUpdate := a.AddCred(updateCred, true)
 				if errUpdate != nil {
 					logger.Error(errUpdate)
@@ -1692,6 +1973,10 @@ func (a *App) SyncCreds(accessToken string) (err error) {
 				Meta:       v.Meta,
 				UpdatedAt:  v.UpdatedAt,
 			}
+			newCred.FolderID, newCred.TagIDs = refs.toLocal(v.FolderID, v.TagIDs)
+			newCred.Fields = v.Fields
+			newCred.URLs = v.URLs
+			newCred.TOTP = v.TOTP
 
 			errAdd := a.AddCred(&newCred, true)
 			if errAdd != nil {
@@ -1719,6 +2004,10 @@ func (a *App) SyncCreds(accessToken string) (err error) {
 			Meta:      v.Meta,
 			UpdatedAt: v.UpdatedAt,
 		}
+		reqBody.FolderID, reqBody.TagIDs = refs.toExternal(v.FolderID, v.TagIDs)
+		reqBody.Fields = toServerFields(v.Fields)
+		reqBody.URLs = toServerURLs(v.URLs)
+		reqBody.TOTP = v.TOTP
 
 		id, err := a.HTTPService.AddCred(c.AccessToken, reqBody)
 		if err != nil {
@@ -1761,6 +2050,11 @@ func (a *App) SyncTexts(accessToken string) (err error) {
 		getTextsMap[v.ExternalID] = v
 	}
 
+	refs, err := a.loadItemRefs()
+	if err != nil {
+		return err
+	}
+
 	// создаем записи в бд клиента
 	for _, v := range getTexts {
 		if val, ok := textsMap[v.ExternalID]; ok {
@@ -1768,6 +2062,7 @@ func (a *App) SyncTexts(accessToken string) (err error) {
 			if val.UpdatedAt.Unix() < v.UpdatedAt.Unix() {
 				updateText := v
 				updateText.LocalID = val.LocalID
+				updateText.FolderID, updateText.TagIDs = refs.toLocal(v.FolderID, v.TagIDs)
 				errUpdate := a.AddText(updateText, true)
 				if errUpdate != nil {
 					logger.Error(errUpdate)
@@ -1781,6 +2076,8 @@ func (a *App) SyncTexts(accessToken string) (err error) {
 				Meta:       v.Meta,
 				UpdatedAt:  v.UpdatedAt,
 			}
+			newText.FolderID, newText.TagIDs = refs.toLocal(v.FolderID, v.TagIDs)
+			newText.Fields = v.Fields
 
 			errAdd := a.AddText(&newText, true)
 			if errAdd != nil {
@@ -1807,6 +2104,8 @@ func (a *App) SyncTexts(accessToken string) (err error) {
 			Meta:      v.Meta,
 			UpdatedAt: v.UpdatedAt,
 		}
+		reqBody.FolderID, reqBody.TagIDs = refs.toExternal(v.FolderID, v.TagIDs)
+		reqBody.Fields = toServerFields(v.Fields)
 
 		id, err := a.HTTPService.AddText(c.AccessToken, reqBody)
 		if err != nil {
@@ -1852,34 +2151,41 @@ func (a *App) SyncFiles(accessToken string) (err error) {
 		getFilesMap[v.ExternalID] = v
 	}
 
-	// создаем записи в бд клиента
-	for _, v := range getFiles {
+	refs, err := a.loadItemRefs()
+	if err != nil {
+		return err
+	}
 
-		val, ok := filesMap[v.ExternalID]
+	downloads := make([]*model.DataFile, 0, len(getFiles))
+	var total int64
 
-		if ok {
+	for _, v := range getFiles {
+		if val, ok := filesMap[v.ExternalID]; ok {
 			if val.UpdatedAt.Unix() > v.UpdatedAt.Unix() {
 				continue
 			}
 		}
 
-		dFile, errDF := a.HTTPService.DownloadFile(v.Path)
-		if errDF != nil {
-			logger.Error("SyncFiles - downloadFile: ", v.Filename)
-			continue
-		}
+		downloads = append(downloads, v)
+		total += v.Size
+	}
 
-		ext := filepath.Ext(v.Filename)
+	progress := a.syncFilesProgress(total)
+	var done int64
 
-		filePath, errFP := a.FileService.SaveFile(dFile, ext)
-		if errFP != nil {
-			logger.Error("SyncFiles - fileService.SaveFile: ", errFP)
+	// создаем записи в бд клиента
+	for _, v := range downloads {
+		val, ok := filesMap[v.ExternalID]
+
+		filePath, errDF := a.downloadFile(accessToken, v, func(n int64) { progress(done + n) })
+		done += v.Size
+		if errDF != nil {
+			logger.Error("SyncFiles - downloadFile: ", v.Filename, errDF)
 			continue
 		}
 
-		dFile.Close()
-
 		updateFile := v
+		updateFile.FolderID, updateFile.TagIDs = refs.toLocal(v.FolderID, v.TagIDs)
 		if ok {
 			updateFile.LocalID = val.LocalID
 		}
@@ -1910,6 +2216,8 @@ func (a *App) SyncFiles(accessToken string) (err error) {
 			Meta:      v.Meta,
 			UpdatedAt: v.UpdatedAt,
 		}
+		reqBody.FolderID, reqBody.TagIDs = refs.toExternal(v.FolderID, v.TagIDs)
+		reqBody.Fields = toServerFields(v.Fields)
 
 		id, err := a.HTTPService.AddFile(c.AccessToken, reqBody)
 		if err != nil {
@@ -12,6 +12,7 @@ func TestNew(t *testing.T) {
 	if err != nil {
 		log.Fatal(err)
 	}
+	cfg.ClientFolder = t.TempDir()
 
 	t.Run("new app", func(t *testing.T) {
 		got := New(cfg)
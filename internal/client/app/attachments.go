@@ -0,0 +1,468 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// itemTypes типы записей сервера по типам записей клиента.
+var itemTypes = map[ui.DataType]string{
+	ui.TypeCard:     smodel.ItemTypeCard,
+	ui.TypeCred:     smodel.ItemTypeCred,
+	ui.TypeText:     smodel.ItemTypeText,
+	ui.TypeFile:     smodel.ItemTypeFile,
+	ui.TypeSSH:      smodel.ItemTypeSSH,
+	ui.TypeIdentity: smodel.ItemTypeIdentity,
+	ui.TypeCustom:   smodel.ItemTypeCustom,
+}
+
+// AddAttachment прикрепляет к записи файл: содержимое и имя шифруются ключом пользователя,
+// на сервер вложение попадает при синхронизации.
+func (a *App) AddAttachment(dataType ui.DataType, itemID int, filename string, src io.Reader) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+	sKey := crypt.DecodeBase64(c.SignKey)
+
+	content, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	encContent, err := crypt.Encrypt(content, sKey)
+	if err != nil {
+		return err
+	}
+
+	encName, err := crypt.Encrypt([]byte(filename), sKey)
+	if err != nil {
+		return err
+	}
+
+	filePath, err := a.FileService.SaveFile(io.NopCloser(bytes.NewReader(encContent)), "")
+	if err != nil {
+		return err
+	}
+
+	att := model.Attachment{
+		ItemType:  itemTypes[dataType],
+		ItemID:    itemID,
+		Filename:  crypt.EncodeBase64(encName),
+		Path:      filePath,
+		UpdatedAt: time.Now(),
+	}
+
+	return a.db.AddAttachment(&att)
+}
+
+// GetAttachments вложения записи с расшифрованными именами файлов.
+func (a *App) GetAttachments(dataType ui.DataType, itemID int) (atts []model.Attachment, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return atts, err
+	}
+	sKey := crypt.DecodeBase64(c.SignKey)
+
+	attsEnc, err := a.db.GetAllAttachments()
+	if err != nil {
+		return atts, err
+	}
+
+	for _, v := range attsEnc {
+		if v.ItemType != itemTypes[dataType] || v.ItemID != itemID {
+			continue
+		}
+
+		name, err := crypt.Decrypt(crypt.DecodeBase64(v.Filename), sKey)
+		if err != nil {
+			return atts, err
+		}
+		v.Filename = string(name)
+
+		atts = append(atts, v)
+	}
+
+	return atts, nil
+}
+
+// ReadAttachment расшифрованное содержимое вложения.
+func (a *App) ReadAttachment(att model.Attachment) (content []byte, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return content, err
+	}
+
+	r, err := a.FileService.GetFile(att.Path)
+	if err != nil {
+		return content, err
+	}
+	defer r.Close()
+
+	enc, err := io.ReadAll(r)
+	if err != nil {
+		return content, err
+	}
+
+	return crypt.Decrypt(enc, crypt.DecodeBase64(c.SignKey))
+}
+
+func (a *App) DeleteAttachment(att model.Attachment) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	err = a.db.DeleteAttachment(att.LocalID)
+	if err != nil {
+		return err
+	}
+
+	err = a.FileService.DeleteFile(att.Path)
+	if err != nil {
+		return err
+	}
+
+	if att.ExternalID == 0 {
+		return nil
+	}
+
+	go func() {
+		err := a.HTTPService.DeleteAttachment(c.AccessToken, att.ExternalID)
+		if err != nil {
+			logger.Error("goroutine delete:", err)
+		}
+	}()
+
+	return nil
+}
+
+// deleteItemAttachments удаляет локальные вложения удаленной записи; на сервере они удаляются вместе с записью.
+func (a *App) deleteItemAttachments(itemType string, itemID int) (err error) {
+	atts, err := a.db.GetAllAttachments()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range atts {
+		if v.ItemType != itemType || v.ItemID != itemID {
+			continue
+		}
+
+		if err = a.db.DeleteAttachment(v.LocalID); err != nil {
+			return err
+		}
+
+		if err = a.FileService.DeleteFile(v.Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteItem удаляет запись типа dataType вместе с её вложениями.
+func (a *App) DeleteItem(dataType ui.DataType, localID int) (err error) {
+	switch dataType {
+	case ui.TypeCard:
+		err = a.DeleteCard(localID)
+	case ui.TypeCred:
+		err = a.DeleteCred(localID)
+	case ui.TypeText:
+		err = a.DeleteText(localID)
+	case ui.TypeFile:
+		err = a.DeleteFile(localID)
+	case ui.TypeSSH:
+		err = a.DeleteSSHKey(localID)
+	case ui.TypeIdentity:
+		err = a.DeleteIdentity(localID)
+	case ui.TypeCustom:
+		err = a.DeleteCustomItem(localID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return a.deleteItemAttachments(itemTypes[dataType], localID)
+}
+
+// itemExternalIDs идентификаторы записей на сервере по типам записей и локальным идентификаторам.
+func (a *App) itemExternalIDs() (ids map[string]map[int]int, err error) {
+	ids = make(map[string]map[int]int, len(itemTypes))
+	for _, t := range itemTypes {
+		ids[t] = make(map[int]int)
+	}
+
+	cards, err := a.db.GetAllCards()
+	if err != nil {
+		return ids, err
+	}
+	for _, v := range cards {
+		ids[smodel.ItemTypeCard][v.LocalID] = v.ExternalID
+	}
+
+	creds, err := a.db.GetAllCreds()
+	if err != nil {
+		return ids, err
+	}
+	for _, v := range creds {
+		ids[smodel.ItemTypeCred][v.LocalID] = v.ExternalID
+	}
+
+	texts, err := a.db.GetAllTexts()
+	if err != nil {
+		return ids, err
+	}
+	for _, v := range texts {
+		ids[smodel.ItemTypeText][v.LocalID] = v.ExternalID
+	}
+
+	files, err := a.db.GetAllFiles()
+	if err != nil {
+		return ids, err
+	}
+	for _, v := range files {
+		ids[smodel.ItemTypeFile][v.LocalID] = v.ExternalID
+	}
+
+	keys, err := a.db.GetAllSSHKeys()
+	if err != nil {
+		return ids, err
+	}
+	for _, v := range keys {
+		ids[smodel.ItemTypeSSH][v.LocalID] = v.ExternalID
+	}
+
+	docs, err := a.db.GetAllIdentities()
+	if err != nil {
+		return ids, err
+	}
+	for _, v := range docs {
+		ids[smodel.ItemTypeIdentity][v.LocalID] = v.ExternalID
+	}
+
+	items, err := a.db.GetAllCustomItems()
+	if err != nil {
+		return ids, err
+	}
+	for _, v := range items {
+		ids[smodel.ItemTypeCustom][v.LocalID] = v.ExternalID
+	}
+
+	return ids, nil
+}
+
+// SyncAttachments синхронизирует вложения после записей, к которым они прикреплены.
+// Вложения не изменяются: новые загружаются на сервер и с сервера, удаленные на сервере
+// (например, вместе с записью на другом устройстве) удаляются локально.
+func (a *App) SyncAttachments(accessToken string) (err error) {
+	atts, err := a.db.GetAllAttachments()
+	if err != nil {
+		return err
+	}
+
+	getAtts, err := a.HTTPService.GetAttachmentList(accessToken)
+	if err != nil {
+		return err
+	}
+
+	toExternal, err := a.itemExternalIDs()
+	if err != nil {
+		return err
+	}
+
+	toLocal := make(map[string]map[int]int, len(toExternal))
+	for t, m := range toExternal {
+		toLocal[t] = make(map[int]int, len(m))
+		for localID, extID := range m {
+			if extID != 0 {
+				toLocal[t][extID] = localID
+			}
+		}
+	}
+
+	localByExt := make(map[int]bool)
+	for _, v := range atts {
+		if v.ExternalID != 0 {
+			localByExt[v.ExternalID] = true
+		}
+	}
+
+	getAttsMap := make(map[int]bool)
+
+	// создаем записи в бд клиента
+	for _, v := range getAtts {
+		getAttsMap[v.ExternalID] = true
+
+		if localByExt[v.ExternalID] {
+			continue
+		}
+
+		itemID, ok := toLocal[v.ItemType][v.ItemID]
+		if !ok {
+			logger.Error("SyncAttachments - unknown item: ", v.ItemType, v.ItemID)
+			continue
+		}
+
+		dFile, errDF := a.HTTPService.DownloadFile(accessToken, v.Path)
+		if errDF != nil {
+			logger.Error("SyncAttachments - downloadFile: ", errDF)
+			continue
+		}
+
+		filePath, errFP := a.FileService.SaveFile(dFile, "")
+		dFile.Close()
+		if errFP != nil {
+			logger.Error("SyncAttachments - fileService.SaveFile: ", errFP)
+			continue
+		}
+
+		att := *v
+		att.ItemID = itemID
+		att.Path = filePath
+
+		if errAdd := a.db.AddAttachment(&att); errAdd != nil {
+			logger.Error("SyncAttachments - save local: ", errAdd)
+		}
+	}
+
+	// создаем записи в бд сервера
+	for _, v := range atts {
+		if v.ExternalID != 0 {
+			if getAttsMap[v.ExternalID] {
+				continue
+			}
+
+			if err = a.db.DeleteAttachment(v.LocalID); err != nil {
+				return err
+			}
+
+			if err = a.FileService.DeleteFile(v.Path); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		itemID := toExternal[v.ItemType][v.ItemID]
+		if itemID == 0 {
+			continue
+		}
+
+		reqBody := smodel.Attachment{
+			ItemType:  v.ItemType,
+			ItemID:    itemID,
+			Filename:  v.Filename,
+			Path:      v.Path,
+			UpdatedAt: v.UpdatedAt,
+		}
+
+		id, err := a.HTTPService.AddAttachment(accessToken, reqBody)
+		if err != nil {
+			logger.Error("SyncAttachments - add: ", err)
+			continue
+		}
+
+		v.ExternalID = id
+		err = a.db.AddAttachment(&v)
+		if err != nil {
+			logger.Error("SyncAttachments - save: ", err)
+		}
+	}
+
+	return nil
+}
+
+// attachmentsBox вложения записи на странице редактирования: сохранение на диск, удаление и добавление.
+func (a *App) attachmentsBox(dataType ui.DataType, localID int) *fyne.Container {
+	atts, err := a.GetAttachments(dataType, localID)
+	if err != nil {
+		logger.Error(err)
+	}
+
+	box := container.NewVBox(canvas.NewLine(color.Black), widget.NewLabel("Вложения"))
+
+	for _, att := range atts {
+		att := att
+
+		saveBtn := widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), func() {
+			dialog.ShowFileSave(func(w fyne.URIWriteCloser, err error) {
+				if w == nil {
+					return
+				}
+				defer w.Close()
+
+				content, err := a.ReadAttachment(att)
+				if err != nil {
+					logger.Error("read attachment:", err)
+					dialog.ShowError(errors.New("ошибка чтения вложения"), a.window)
+
+					return
+				}
+
+				if _, err = w.Write(content); err != nil {
+					logger.Error("write attachment:", err)
+					dialog.ShowError(errors.New("ошибка сохранения файла"), a.window)
+				}
+			}, a.window)
+		})
+
+		deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			dialog.ShowConfirm("Удалить", fmt.Sprintf("Удалить вложение %s?", att.Filename), func(b bool) {
+				if !b {
+					return
+				}
+
+				if err := a.DeleteAttachment(att); err != nil {
+					logger.Error("delete attachment:", err)
+					dialog.ShowError(errors.New("ошибка при удалении вложения"), a.window)
+
+					return
+				}
+
+				a.pageEdit(localID, dataType)
+			}, a.window)
+		})
+
+		box.Add(container.NewHBox(widget.NewLabel(att.Filename), layout.NewSpacer(), saveBtn, deleteBtn))
+	}
+
+	addBtn := widget.NewButtonWithIcon("Прикрепить файл", theme.ContentAddIcon(), func() {
+		dialog.ShowFileOpen(func(r fyne.URIReadCloser, err error) {
+			if r == nil {
+				return
+			}
+			defer r.Close()
+
+			if err := a.AddAttachment(dataType, localID, r.URI().Name(), r); err != nil {
+				logger.Error("add attachment:", err)
+				dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+				return
+			}
+
+			a.pageEdit(localID, dataType)
+		}, a.window)
+	})
+	box.Add(addBtn)
+
+	return box
+}
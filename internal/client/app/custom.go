@@ -0,0 +1,557 @@
+package app
+
+import (
+	"errors"
+	"image/color"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/itemtemplate"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+func (a *App) AddCustomItem(item *model.DataCustom, encrypted bool) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	if !encrypted {
+		sKey := crypt.DecodeBase64(c.SignKey)
+		for _, v := range []*string{&item.Values, &item.Meta} {
+			enc, err := crypt.Encrypt([]byte(*v), sKey)
+			if err != nil {
+				return err
+			}
+			*v = crypt.EncodeBase64(enc)
+		}
+
+		item.Fields, err = encryptFields(item.Fields, sKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	return a.db.AddCustomItem(item)
+}
+
+// decryptCustomItem расшифровывает запись по шаблону из локальной БД.
+func decryptCustomItem(item model.DataCustom, sKey []byte) (model.DataCustom, error) {
+	for _, v := range []*string{&item.Values, &item.Meta} {
+		dec, err := crypt.Decrypt(crypt.DecodeBase64(*v), sKey)
+		if err != nil {
+			return item, err
+		}
+		*v = string(dec)
+	}
+
+	var err error
+	item.Fields, err = decryptFields(item.Fields, sKey)
+
+	return item, err
+}
+
+func (a *App) GetCustomItem(localID int) (item model.DataCustom, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return item, err
+	}
+
+	item, err = a.db.GetCustomItem(localID)
+	if err != nil {
+		return item, err
+	}
+
+	return decryptCustomItem(item, crypt.DecodeBase64(c.SignKey))
+}
+
+func (a *App) GetAllCustomItems() (items []model.DataCustom, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return items, err
+	}
+	sKey := crypt.DecodeBase64(c.SignKey)
+
+	itemsEnc, err := a.db.GetAllCustomItems()
+	if err != nil {
+		return items, err
+	}
+
+	for _, v := range itemsEnc {
+		v, err = decryptCustomItem(v, sKey)
+		if err != nil {
+			return items, err
+		}
+
+		items = append(items, v)
+	}
+
+	return items, nil
+}
+
+func (a *App) DeleteCustomItem(localID int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	item, err := a.db.GetCustomItem(localID)
+	if err != nil {
+		return err
+	}
+
+	err = a.db.DeleteCustomItem(localID)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err := a.HTTPService.DeleteCustomItem(c.AccessToken, item.ExternalID)
+		if err != nil {
+			logger.Error("goroutine delete:", err)
+		}
+	}()
+
+	return nil
+}
+
+// SyncCustomItems синхронизирует записи по шаблонам после SyncTemplates: ссылки на шаблоны
+// переводятся между локальными и серверными идентификаторами, записи без шаблона пропускаются.
+func (a *App) SyncCustomItems(accessToken string) (err error) {
+	itemsMap := make(map[int]*model.DataCustom)
+	getItemsMap := make(map[int]*model.DataCustom)
+
+	items, err := a.db.GetAllCustomItems()
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		itemsMap[items[i].ExternalID] = &items[i]
+	}
+
+	getItems, err := a.HTTPService.GetCustomItemList(accessToken)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range getItems {
+		getItemsMap[v.ExternalID] = v
+	}
+
+	refs, err := a.loadItemRefs()
+	if err != nil {
+		return err
+	}
+
+	templates, err := a.db.GetAllTemplates()
+	if err != nil {
+		return err
+	}
+
+	templateToLocal := make(map[int]int, len(templates))
+	templateToExternal := make(map[int]int, len(templates))
+	for _, v := range templates {
+		if v.ExternalID != 0 {
+			templateToLocal[v.ExternalID] = v.LocalID
+			templateToExternal[v.LocalID] = v.ExternalID
+		}
+	}
+
+	// создаем записи в бд клиента
+	for _, v := range getItems {
+		updateItem := *v
+		updateItem.LocalID = 0
+		if val, ok := itemsMap[v.ExternalID]; ok {
+			// если дата на сервере новее обновим локальные данные
+			if val.UpdatedAt.Unix() >= v.UpdatedAt.Unix() {
+				continue
+			}
+			updateItem.LocalID = val.LocalID
+		}
+
+		tplID, ok := templateToLocal[v.TemplateID]
+		if !ok {
+			logger.Error("SyncCustomItems - template not found: ", v.TemplateID)
+			continue
+		}
+
+		updateItem.TemplateID = tplID
+		updateItem.FolderID, updateItem.TagIDs = refs.toLocal(v.FolderID, v.TagIDs)
+		errAdd := a.AddCustomItem(&updateItem, true)
+		if errAdd != nil {
+			logger.Error(errAdd)
+		}
+	}
+
+	// создаем записи в бд сервера
+	for _, v := range items {
+		if val, ok := getItemsMap[v.ExternalID]; ok {
+			if val.UpdatedAt.Unix() > v.UpdatedAt.Unix() {
+				continue
+			}
+		}
+
+		tplID, ok := templateToExternal[v.TemplateID]
+		if !ok {
+			logger.Error("SyncCustomItems - template not synced: ", v.TemplateID)
+			continue
+		}
+
+		// отправим на сервер
+		reqBody := smodel.DataCustom{
+			ID:         v.ExternalID,
+			TemplateID: tplID,
+			Title:      v.Title,
+			Values:     v.Values,
+			Meta:       v.Meta,
+			UpdatedAt:  v.UpdatedAt,
+		}
+		reqBody.FolderID, reqBody.TagIDs = refs.toExternal(v.FolderID, v.TagIDs)
+		reqBody.Fields = toServerFields(v.Fields)
+
+		id, err := a.HTTPService.AddCustomItem(accessToken, reqBody)
+		if err != nil {
+			logger.Error(err)
+
+			continue
+		}
+
+		v := v
+		v.ExternalID = id
+		err = a.db.AddCustomItem(&v)
+		if err != nil {
+			logger.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// templateSelect выбор шаблона по названию; onChanged получает локальный идентификатор шаблона.
+// Без выбранного шаблона (selected = 0) выбирается первый.
+func templateSelect(templates []model.Template, selected int, onChanged func(localID int)) *widget.Select {
+	options := make([]string, 0, len(templates))
+	for _, v := range templates {
+		options = append(options, v.Name)
+	}
+
+	sel := widget.NewSelect(options, nil)
+	for i, v := range templates {
+		if v.LocalID == selected {
+			sel.SetSelectedIndex(i)
+		}
+	}
+
+	if sel.SelectedIndex() < 0 && len(templates) > 0 {
+		sel.SetSelectedIndex(0)
+	}
+
+	sel.OnChanged = func(string) {
+		onChanged(templates[sel.SelectedIndex()].LocalID)
+	}
+
+	return sel
+}
+
+// customList записи выбранного шаблона таблицей: заголовок и поля шаблона с признаком list.
+func (a *App) customList() *fyne.Container {
+	templates, err := a.GetAllTemplates()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	if len(templates) == 0 {
+		return container.NewCenter(canvas.NewText("Нет шаблонов: создайте шаблон кнопкой \"Шаблоны\"", color.Black))
+	}
+
+	sel := templateSelect(templates, a.customTemplate, func(localID int) {
+		a.customTemplate = localID
+		a.pageMain(ui.TypeCustom)
+	})
+	a.customTemplate = templates[sel.SelectedIndex()].LocalID
+
+	var columns []itemtemplate.Field
+	schema, err := itemtemplate.Parse(templates[sel.SelectedIndex()].Schema)
+	if err != nil {
+		logger.Error(err)
+	} else {
+		columns = schema.ListFields()
+	}
+
+	type row struct {
+		localID int
+		cells   []string
+	}
+
+	var rows []row
+
+	allItems, err := a.GetAllCustomItems()
+	if err != nil {
+		logger.Error(err)
+	}
+	for _, v := range allItems {
+		if v.TemplateID != a.customTemplate || !a.filter.match(v.FolderID, v.TagIDs) {
+			continue
+		}
+
+		values, err := itemtemplate.ParseValues(v.Values)
+		if err != nil {
+			logger.Error(err)
+		}
+
+		cells := []string{v.Title}
+		for _, f := range columns {
+			cells = append(cells, values[f.Name])
+		}
+		rows = append(rows, row{localID: v.LocalID, cells: cells})
+	}
+
+	header := []string{"Заголовок"}
+	for _, f := range columns {
+		header = append(header, f.Label)
+	}
+
+	noItems := container.NewCenter(canvas.NewText("Нет записей", color.Black))
+
+	// первая строка таблицы - заголовки колонок
+	table := widget.NewTable(
+		func() (int, int) {
+			return len(rows) + 1, len(header)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Default")
+		},
+		func(id widget.TableCellID, co fyne.CanvasObject) {
+			label := co.(*widget.Label)
+			if id.Row == 0 {
+				label.TextStyle = fyne.TextStyle{Bold: true}
+				label.SetText(header[id.Col])
+
+				return
+			}
+
+			label.TextStyle = fyne.TextStyle{}
+			label.SetText(rows[id.Row-1].cells[id.Col])
+		},
+	)
+	for i := range header {
+		table.SetColumnWidth(i, 150)
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row == 0 {
+			table.UnselectAll()
+			return
+		}
+
+		a.pageEdit(rows[id.Row-1].localID, ui.TypeCustom)
+	}
+
+	scroll := container.NewScroll(table)
+	scroll.SetMinSize(fyne.NewSize(100, 650))
+
+	if len(rows) != 0 {
+		noItems.Hide()
+	}
+
+	return container.NewBorder(sel, nil, nil, nil, container.New(layout.NewPaddedLayout(), scroll, noItems))
+}
+
+// customValueEntry поле формы для значения поля шаблона; get возвращает введенное значение.
+func customValueEntry(f itemtemplate.Field, value string) (obj fyne.CanvasObject, get func() string) {
+	if f.Type == itemtemplate.TypeSelect {
+		options := f.Options
+		if !f.Required {
+			options = append([]string{""}, options...)
+		}
+
+		sel := widget.NewSelect(options, nil)
+		if value != "" {
+			sel.SetSelected(value)
+		}
+
+		return sel, func() string { return sel.Selected }
+	}
+
+	var entry *widget.Entry
+	switch f.Type {
+	case itemtemplate.TypeSecret:
+		entry = widget.NewPasswordEntry()
+	case itemtemplate.TypeMultiline:
+		entry = widget.NewMultiLineEntry()
+	default:
+		entry = widget.NewEntry()
+	}
+
+	switch f.Type {
+	case itemtemplate.TypeURL:
+		entry.SetPlaceHolder("https://")
+	case itemtemplate.TypeEmail:
+		entry.SetPlaceHolder("user@example.com")
+	case itemtemplate.TypeDate:
+		entry.SetPlaceHolder("ГГГГ-ММ-ДД")
+	}
+
+	entry.SetText(value)
+	entry.Validator = func(s string) error {
+		return f.ValidateValue(s)
+	}
+
+	return entry, func() string { return strings.TrimSpace(entry.Text) }
+}
+
+func (a *App) addCustomForm(localID int) *fyne.Container {
+	var err error
+	var item model.DataCustom
+
+	templates, err := a.GetAllTemplates()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	if len(templates) == 0 {
+		return container.NewCenter(canvas.NewText("Нет шаблонов: создайте шаблон кнопкой \"Шаблоны\"", color.Black))
+	}
+
+	tplID := a.customTemplate
+	if localID > 0 {
+		item, err = a.GetCustomItem(localID)
+		if err != nil {
+			logger.Error(err)
+		}
+		tplID = item.TemplateID
+	}
+
+	tpl, schema, err := a.getTemplateSchema(tplID)
+	if err != nil && localID == 0 {
+		tpl, schema, err = a.getTemplateSchema(templates[0].LocalID)
+	}
+	if err != nil {
+		logger.Error(err)
+		return container.NewCenter(canvas.NewText("Шаблон записи не найден или поврежден", color.Black))
+	}
+
+	values, err := itemtemplate.ParseValues(item.Values)
+	if err != nil {
+		logger.Error(err)
+	}
+
+	title := widget.NewEntry()
+	title.Text = item.Title
+	title.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
+	meta := widget.NewMultiLineEntry()
+	meta.Text = item.Meta
+
+	addForm := widget.NewForm(widget.NewFormItem("Заголовок", title))
+
+	// шаблон новой записи можно сменить, у сохраненной он фиксирован
+	if localID == 0 {
+		sel := templateSelect(templates, tpl.LocalID, func(id int) {
+			a.customTemplate = id
+			a.pageAdd(0, ui.TypeCustom)
+		})
+		addForm.Append("Шаблон", sel)
+	} else {
+		addForm.Append("Шаблон", widget.NewLabel(tpl.Name))
+	}
+
+	getters := make(map[string]func() string, len(schema.Fields))
+	for _, f := range schema.Fields {
+		value, ok := values[f.Name]
+		if !ok && localID == 0 {
+			value = f.Default
+		}
+
+		obj, get := customValueEntry(f, value)
+		getters[f.Name] = get
+
+		formItem := widget.NewFormItem(f.Label, obj)
+		if f.Required {
+			formItem.HintText = "обязательное поле"
+		}
+		addForm.AppendItem(formItem)
+	}
+
+	addForm.Append("Дополнительно", meta)
+
+	fieldsBox, getFields := fieldsEditor(item.Fields)
+	addForm.Append("Поля", fieldsBox)
+
+	refsFormItems, getRefs := a.itemRefsFormItems(item.FolderID, item.TagIDs)
+	for _, v := range refsFormItems {
+		addForm.AppendItem(v)
+	}
+
+	addForm.CancelText = "Отмена"
+	addForm.OnCancel = func() {
+		a.pageMain(ui.TypeCustom)
+	}
+	addForm.SubmitText = "Сохранить"
+	addForm.OnSubmit = func() {
+		var err error
+
+		newValues := make(map[string]string, len(getters))
+		for name, get := range getters {
+			if v := get(); v != "" {
+				newValues[name] = v
+			}
+		}
+
+		err = schema.ValidateValues(newValues)
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
+		itemData := model.DataCustom{
+			TemplateID: tpl.LocalID,
+			Title:      title.Text,
+			Meta:       meta.Text,
+			UpdatedAt:  time.Now(),
+		}
+
+		itemData.Values, err = itemtemplate.MarshalValues(newValues)
+		if err != nil {
+			logger.Error(err)
+			dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+			return
+		}
+
+		itemData.FolderID, itemData.TagIDs = getRefs()
+
+		itemData.Fields, err = getFields()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
+		if localID > 0 {
+			itemData.LocalID = item.LocalID
+			itemData.ExternalID = item.ExternalID
+		}
+
+		err = a.AddCustomItem(&itemData, false)
+		if err != nil {
+			dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+			return
+		}
+
+		a.customTemplate = tpl.LocalID
+		a.pageMain(ui.TypeCustom)
+	}
+
+	return container.NewVBox(append(a.totpViews("", item.Fields), addForm)...)
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/service"
+)
+
+// Доля полосы синхронизации, которую занимает скачивание файлов.
+const (
+	syncFilesProgressFrom = 0.75
+	syncFilesProgressTo   = 1.0
+)
+
+// downloadFile скачивает файл записи во временный файл, продолжая прерванное скачивание,
+// проверяет SHA-256 и переносит файл в хранилище клиента.
+func (a *App) downloadFile(accessToken string, file *model.DataFile, progress func(n int64)) (filePath string, err error) {
+	// записи, сохраненные до появления хешей на сервере, докачиваются по ключу от пути
+	key := file.SHA256
+	if key == "" {
+		sum := sha256.Sum256([]byte(file.Path))
+		key = hex.EncodeToString(sum[:])
+	}
+
+	f, err := a.FileService.OpenPartial(key)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.HTTPService.DownloadFileTo(accessToken, file.Path, f, file.SHA256, progress)
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+
+	if errors.Is(err, service.ErrStatusChecksum) {
+		// поврежденный файл не докачиваем, при следующей синхронизации он скачается заново
+		_ = a.FileService.DeleteFile(f.Name())
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return a.FileService.MoveFile(f.Name(), filepath.Ext(file.Filename))
+}
+
+// syncFilesProgress возвращает функцию, которая по числу скачанных байт из total обновляет полосу синхронизации.
+// Значение отправляется, только если изменилось хотя бы на процент, и без ожидания, чтобы не тормозить скачивание.
+func (a *App) syncFilesProgress(total int64) func(done int64) {
+	last := int64(-1)
+
+	return func(done int64) {
+		if total <= 0 {
+			return
+		}
+
+		percent := done * 100 / total
+		if percent > 100 {
+			percent = 100
+		}
+
+		if percent == last {
+			return
+		}
+		last = percent
+
+		select {
+		case a.Channels.SyncProgressBar <- syncFilesProgressFrom + (syncFilesProgressTo-syncFilesProgressFrom)*float64(percent)/100:
+		default:
+		}
+	}
+}
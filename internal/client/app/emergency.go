@@ -0,0 +1,483 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/service"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// dateTimeLayout формат даты и времени на страницах клиента.
+const dateTimeLayout = "2006-01-02 15:04"
+
+// emergencyDefaultWaitHours период ожидания, предлагаемый при назначении доверенного лица.
+const emergencyDefaultWaitHours = 48
+
+// emergencyStatusLabels подписи состояний экстренного доступа.
+var emergencyStatusLabels = map[string]string{
+	smodel.EmergencyStatusIdle:      "не запрошен",
+	smodel.EmergencyStatusRequested: "запрошен",
+	smodel.EmergencyStatusRejected:  "отклонен",
+	smodel.EmergencyStatusGranted:   "открыт",
+}
+
+var errEmergencyLocked = errors.New("доступ к хранилищу еще не открыт")
+
+// emergencyItem запись хранилища владельца, полученная по экстренному доступу.
+type emergencyItem struct {
+	title  string
+	values map[string]any
+}
+
+// emergencyStatus состояние экстренного доступа для отображения; для запрошенного доступа - время открытия.
+func emergencyStatus(access smodel.EmergencyAccess) string {
+	if access.Status == smodel.EmergencyStatusRequested {
+		return fmt.Sprintf("запрошен, откроется %s", access.AvailableAt.Local().Format(dateTimeLayout))
+	}
+
+	return emergencyStatusLabels[access.Status]
+}
+
+// SaveEmergencyContact назначает пользователя login доверенным лицом (id == 0) или меняет период ожидания.
+// Ключ хранилища шифруется открытым ключом доверенного лица; сервер выдаст его только после открытия доступа.
+func (a *App) SaveEmergencyContact(id int, login string, waitHours int) (int, error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return id, err
+	}
+
+	pub, err := a.HTTPService.GetPublicKey(c.AccessToken, login)
+	if errors.Is(err, service.ErrStatusNotFound) {
+		return id, errShareNoTarget
+	}
+
+	if err != nil {
+		return id, err
+	}
+
+	sealed, err := crypt.SealX25519(crypt.DecodeBase64(c.SignKey), crypt.DecodeBase64(pub.PublicKey))
+	if err != nil {
+		return id, err
+	}
+
+	return a.HTTPService.SaveEmergencyAccess(c.AccessToken, smodel.EmergencyAccess{
+		ID:        id,
+		Grantee:   login,
+		WaitHours: waitHours,
+		Key:       crypt.EncodeBase64(sealed),
+	})
+}
+
+func (a *App) DeleteEmergencyContact(id int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	return a.HTTPService.DeleteEmergencyAccess(c.AccessToken, id)
+}
+
+// GetEmergencyContacts доверенные лица пользователя с состоянием доступа.
+func (a *App) GetEmergencyContacts() (list []smodel.EmergencyAccess, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return list, err
+	}
+
+	return a.HTTPService.GetEmergencyAccessList(c.AccessToken)
+}
+
+// GetIncomingEmergencyAccess пользователи, назначившие пользователя доверенным лицом. Ключи пользователя
+// создаются заранее, чтобы его можно было назначить доверенным лицом.
+func (a *App) GetIncomingEmergencyAccess() (list []smodel.EmergencyAccess, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return list, err
+	}
+
+	if _, _, err = a.userKeys(c.AccessToken); err != nil {
+		return list, err
+	}
+
+	return a.HTTPService.GetIncomingEmergencyAccessList(c.AccessToken)
+}
+
+func (a *App) RequestEmergencyAccess(id int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	return a.HTTPService.RequestEmergencyAccess(c.AccessToken, id)
+}
+
+func (a *App) RejectEmergencyAccess(id int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	return a.HTTPService.RejectEmergencyAccess(c.AccessToken, id)
+}
+
+// GetEmergencyVault расшифрованные записи владельца по открытому экстренному доступу: ключ хранилища
+// владельца расшифровывается закрытым ключом пользователя.
+func (a *App) GetEmergencyVault(access smodel.EmergencyAccess) (vault model.EmergencyVault, err error) {
+	if access.Status != smodel.EmergencyStatusGranted || access.Key == "" {
+		return vault, errEmergencyLocked
+	}
+
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return vault, err
+	}
+
+	_, private, err := a.userKeys(c.AccessToken)
+	if err != nil {
+		return vault, err
+	}
+
+	sKey, err := crypt.OpenX25519(crypt.DecodeBase64(access.Key), private)
+	if err != nil {
+		return vault, err
+	}
+
+	vault, err = a.HTTPService.GetEmergencyVault(c.AccessToken, access.ID)
+	if err != nil {
+		return vault, err
+	}
+
+	for i := range vault.Cards {
+		if vault.Cards[i], err = decryptCard(vault.Cards[i], sKey); err != nil {
+			return vault, err
+		}
+	}
+
+	for i := range vault.Creds {
+		if vault.Creds[i], err = decryptCred(vault.Creds[i], sKey); err != nil {
+			return vault, err
+		}
+	}
+
+	for i := range vault.Texts {
+		if vault.Texts[i], err = decryptText(vault.Texts[i], sKey); err != nil {
+			return vault, err
+		}
+	}
+
+	for i := range vault.Files {
+		meta, err := crypt.Decrypt(crypt.DecodeBase64(vault.Files[i].Meta), sKey)
+		if err != nil {
+			return vault, err
+		}
+		vault.Files[i].Meta = string(meta)
+
+		if vault.Files[i].Fields, err = decryptFields(vault.Files[i].Fields, sKey); err != nil {
+			return vault, err
+		}
+	}
+
+	for i := range vault.SSHKeys {
+		if vault.SSHKeys[i], err = decryptSSHKey(vault.SSHKeys[i], sKey); err != nil {
+			return vault, err
+		}
+	}
+
+	for i := range vault.Identities {
+		if vault.Identities[i], err = decryptIdentity(vault.Identities[i], sKey); err != nil {
+			return vault, err
+		}
+	}
+
+	for i := range vault.CustomItems {
+		if vault.CustomItems[i], err = decryptCustomItem(vault.CustomItems[i], sKey); err != nil {
+			return vault, err
+		}
+	}
+
+	for i := range vault.Templates {
+		for _, v := range []*string{&vault.Templates[i].Name, &vault.Templates[i].Schema} {
+			dec, err := crypt.Decrypt(crypt.DecodeBase64(*v), sKey)
+			if err != nil {
+				return vault, err
+			}
+			*v = string(dec)
+		}
+	}
+
+	for i := range vault.Attachments {
+		name, err := crypt.Decrypt(crypt.DecodeBase64(vault.Attachments[i].Filename), sKey)
+		if err != nil {
+			return vault, err
+		}
+		vault.Attachments[i].Filename = string(name)
+	}
+
+	return vault, nil
+}
+
+// emergencyItems записи хранилища владельца для отображения, с типом в названии.
+func emergencyItems(vault model.EmergencyVault) (items []emergencyItem, err error) {
+	add := func(kind, title string, item any) error {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+
+		data, err = cleanPayload(data)
+		if err != nil {
+			return err
+		}
+
+		var values map[string]any
+		if err = json.Unmarshal(data, &values); err != nil {
+			return err
+		}
+
+		items = append(items, emergencyItem{title: fmt.Sprintf("%s: %s", kind, title), values: values})
+
+		return nil
+	}
+
+	for _, v := range vault.Cards {
+		if err = add(ui.TabCard.String(), v.Title, v); err != nil {
+			return items, err
+		}
+	}
+
+	for _, v := range vault.Creds {
+		if err = add(ui.TabCred.String(), v.Title, v); err != nil {
+			return items, err
+		}
+	}
+
+	for _, v := range vault.Texts {
+		if err = add(ui.TabText.String(), v.Title, v); err != nil {
+			return items, err
+		}
+	}
+
+	for _, v := range vault.Files {
+		if err = add(ui.TabFile.String(), v.Title, v); err != nil {
+			return items, err
+		}
+	}
+
+	for _, v := range vault.SSHKeys {
+		if err = add(ui.TabSSH.String(), v.Title, v); err != nil {
+			return items, err
+		}
+	}
+
+	for _, v := range vault.Identities {
+		if err = add(ui.TabIdentity.String(), v.Title, v); err != nil {
+			return items, err
+		}
+	}
+
+	templates := make(map[int]string, len(vault.Templates))
+	for _, v := range vault.Templates {
+		templates[v.ExternalID] = v.Name
+	}
+
+	for _, v := range vault.CustomItems {
+		title := v.Title
+		if name, ok := templates[v.TemplateID]; ok {
+			title = fmt.Sprintf("%s (%s)", v.Title, name)
+		}
+
+		if err = add(ui.TabCustom.String(), title, v); err != nil {
+			return items, err
+		}
+	}
+
+	for _, v := range vault.Attachments {
+		if err = add("Вложение", v.Filename, v); err != nil {
+			return items, err
+		}
+	}
+
+	return items, nil
+}
+
+// pageEmergency доверенные лица пользователя и хранилища, в которых пользователь сам назначен доверенным лицом.
+func (a *App) pageEmergency(dataType ui.DataType) {
+	showErr := func(msg string, err error) bool {
+		if err == nil {
+			return false
+		}
+
+		logger.Error(msg, err)
+		if errors.Is(err, errShareNoTarget) {
+			dialog.ShowError(err, a.window)
+		} else {
+			dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+		}
+
+		return true
+	}
+
+	contacts, err := a.GetEmergencyContacts()
+	if err != nil {
+		logger.Error(err)
+		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+	}
+
+	incoming, err := a.GetIncomingEmergencyAccess()
+	if err != nil {
+		logger.Error(err)
+		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+	}
+
+	login := widget.NewEntry()
+	login.SetPlaceHolder("Логин доверенного лица")
+	wait := widget.NewEntry()
+	wait.SetText(strconv.Itoa(emergencyDefaultWaitHours))
+	addBtn := widget.NewButtonWithIcon("Назначить", theme.ContentAddIcon(), func() {
+		hours, err := strconv.Atoi(wait.Text)
+		if login.Text == "" || err != nil || hours < 0 || hours > smodel.MaxEmergencyWaitHours {
+			dialog.ShowError(fmt.Errorf("укажите логин и период ожидания от 0 до %d часов", smodel.MaxEmergencyWaitHours), a.window)
+
+			return
+		}
+
+		if _, err = a.SaveEmergencyContact(0, login.Text, hours); showErr("save emergency contact:", err) {
+			return
+		}
+
+		a.pageEmergency(dataType)
+	})
+
+	own := container.NewVBox()
+	for _, access := range contacts {
+		access := access
+		row := container.NewHBox(widget.NewLabel(fmt.Sprintf("%s, ожидание %d ч: %s", access.Grantee, access.WaitHours, emergencyStatus(access))), layout.NewSpacer())
+
+		if access.Status == smodel.EmergencyStatusRequested || access.Status == smodel.EmergencyStatusGranted {
+			row.Add(widget.NewButtonWithIcon("Отклонить", theme.CancelIcon(), func() {
+				if showErr("reject emergency access:", a.RejectEmergencyAccess(access.ID)) {
+					return
+				}
+
+				a.pageEmergency(dataType)
+			}))
+		}
+
+		row.Add(widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			dialog.ShowConfirm("Удаление", fmt.Sprintf("Удалить доверенное лицо %s?", access.Grantee), func(ok bool) {
+				if !ok || showErr("delete emergency contact:", a.DeleteEmergencyContact(access.ID)) {
+					return
+				}
+
+				a.pageEmergency(dataType)
+			}, a.window)
+		}))
+		own.Add(row)
+	}
+
+	trusted := container.NewVBox()
+	for _, access := range incoming {
+		access := access
+		row := container.NewHBox(widget.NewLabel(fmt.Sprintf("%s: %s", access.Grantor, emergencyStatus(access))), layout.NewSpacer())
+
+		switch access.Status {
+		case smodel.EmergencyStatusIdle, smodel.EmergencyStatusRejected:
+			row.Add(widget.NewButtonWithIcon("Запросить доступ", theme.MailSendIcon(), func() {
+				dialog.ShowConfirm("Экстренный доступ", fmt.Sprintf("Запросить доступ к хранилищу %s? Доступ откроется через %d ч, если владелец не отклонит запрос.", access.Grantor, access.WaitHours), func(ok bool) {
+					if !ok || showErr("request emergency access:", a.RequestEmergencyAccess(access.ID)) {
+						return
+					}
+
+					a.pageEmergency(dataType)
+				}, a.window)
+			}))
+		case smodel.EmergencyStatusGranted:
+			row.Add(widget.NewButtonWithIcon("Открыть", theme.NavigateNextIcon(), func() {
+				a.pageEmergencyVault(access, dataType)
+			}))
+		}
+		trusted.Add(row)
+	}
+
+	a.window.SetContent(container.NewVScroll(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageMain(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText("Экстренный доступ", color.Black),
+		),
+		canvas.NewLine(color.Black),
+		widget.NewLabel("Мои доверенные лица"),
+		container.NewBorder(nil, nil, nil, container.NewHBox(widget.NewLabel("часов:"), wait, addBtn), login),
+		own,
+		canvas.NewLine(color.Black),
+		widget.NewLabel("Мне доверили"),
+		trusted,
+	)))
+}
+
+// pageEmergencyVault записи хранилища владельца по открытому экстренному доступу, только для чтения.
+func (a *App) pageEmergencyVault(access smodel.EmergencyAccess, dataType ui.DataType) {
+	var items []emergencyItem
+
+	vault, err := a.GetEmergencyVault(access)
+	if err == nil {
+		items, err = emergencyItems(vault)
+	}
+
+	if err != nil {
+		logger.Error("emergency vault:", err)
+		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+	}
+
+	form := container.NewVBox()
+
+	list := widget.NewList(
+		func() int {
+			return len(items)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Запись")
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			co.(*widget.Label).SetText(items[lii].title)
+		},
+	)
+	list.OnSelected = func(lii widget.ListItemID) {
+		item := sharedItem{share: smodel.Share{Owner: access.Grantor, Access: smodel.ShareAccessRead}, values: items[lii].values}
+		form.Objects = []fyne.CanvasObject{a.sharedItemForm(item, dataType)}
+		form.Refresh()
+	}
+
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(100, 150))
+
+	a.window.SetContent(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageEmergency(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText(fmt.Sprintf("Хранилище %s", access.Grantor), color.Black),
+		),
+		canvas.NewLine(color.Black),
+		scroll,
+		form,
+	))
+}
@@ -0,0 +1,225 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+)
+
+// fieldDateLayout формат значений полей типа "дата".
+const fieldDateLayout = "2006-01-02"
+
+// fieldTypeNames подписи типов пользовательских полей в порядке списка выбора.
+var fieldTypeNames = []struct {
+	fieldType string
+	name      string
+}{
+	{smodel.FieldTypeText, "Текст"},
+	{smodel.FieldTypeHidden, "Скрытое"},
+	{smodel.FieldTypeURL, "Ссылка"},
+	{smodel.FieldTypeEmail, "Email"},
+	{smodel.FieldTypeDate, "Дата"},
+	{smodel.FieldTypeTOTP, "TOTP"},
+}
+
+// encryptFields шифрует подписи и значения полей, тип остается открытым для проверки на сервере.
+func encryptFields(fields []model.Field, sKey []byte) ([]model.Field, error) {
+	res := make([]model.Field, 0, len(fields))
+	for _, f := range fields {
+		encLabel, err := crypt.Encrypt([]byte(f.Label), sKey)
+		if err != nil {
+			return nil, err
+		}
+
+		encValue, err := crypt.Encrypt([]byte(f.Value), sKey)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, model.Field{Label: crypt.EncodeBase64(encLabel), Type: f.Type, Value: crypt.EncodeBase64(encValue)})
+	}
+
+	return res, nil
+}
+
+func decryptFields(fields []model.Field, sKey []byte) ([]model.Field, error) {
+	res := make([]model.Field, 0, len(fields))
+	for _, f := range fields {
+		decLabel, err := crypt.Decrypt(crypt.DecodeBase64(f.Label), sKey)
+		if err != nil {
+			return nil, err
+		}
+
+		decValue, err := crypt.Decrypt(crypt.DecodeBase64(f.Value), sKey)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, model.Field{Label: string(decLabel), Type: f.Type, Value: string(decValue)})
+	}
+
+	return res, nil
+}
+
+// toServerFields поля записи для отправки на сервер.
+func toServerFields(fields []model.Field) smodel.Fields {
+	res := make(smodel.Fields, 0, len(fields))
+	for _, f := range fields {
+		res = append(res, smodel.Field{Label: f.Label, Type: f.Type, Value: f.Value})
+	}
+
+	return res
+}
+
+// validateField проверяет расшифрованное поле перед сохранением; пустое значение допустимо.
+func validateField(f model.Field) error {
+	if strings.TrimSpace(f.Label) == "" {
+		return errors.New("не заполнено название поля")
+	}
+
+	if f.Value == "" {
+		return nil
+	}
+
+	switch f.Type {
+	case smodel.FieldTypeURL:
+		u, err := url.ParseRequestURI(f.Value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("поле %q: некорректная ссылка", f.Label)
+		}
+	case smodel.FieldTypeEmail:
+		if _, err := mail.ParseAddress(f.Value); err != nil {
+			return fmt.Errorf("поле %q: некорректный email", f.Label)
+		}
+	case smodel.FieldTypeDate:
+		if _, err := time.Parse(fieldDateLayout, f.Value); err != nil {
+			return fmt.Errorf("поле %q: дата в формате ГГГГ-ММ-ДД", f.Label)
+		}
+	}
+
+	return nil
+}
+
+// isMaskedField поля, значения которых скрыты в форме до нажатия на кнопку просмотра.
+func isMaskedField(fieldType string) bool {
+	return fieldType == smodel.FieldTypeHidden || fieldType == smodel.FieldTypeTOTP
+}
+
+type fieldRow struct {
+	label     *widget.Entry
+	fieldType *widget.Select
+	value     *widget.Entry
+}
+
+func newFieldValueEntry(fieldType, text string) *widget.Entry {
+	var entry *widget.Entry
+	if isMaskedField(fieldType) {
+		entry = widget.NewPasswordEntry()
+	} else {
+		entry = widget.NewEntry()
+	}
+
+	switch fieldType {
+	case smodel.FieldTypeURL:
+		entry.SetPlaceHolder("https://")
+	case smodel.FieldTypeEmail:
+		entry.SetPlaceHolder("user@example.com")
+	case smodel.FieldTypeDate:
+		entry.SetPlaceHolder("ГГГГ-ММ-ДД")
+	}
+	entry.SetText(text)
+
+	return entry
+}
+
+// fieldsEditor редактор пользовательских полей записи для форм добавления и изменения;
+// get возвращает заполненные поля или ошибку проверки значений.
+func fieldsEditor(fields []model.Field) (editor fyne.CanvasObject, get func() ([]model.Field, error)) {
+	typeByName := make(map[string]string, len(fieldTypeNames))
+	nameByType := make(map[string]string, len(fieldTypeNames))
+	typeOptions := make([]string, 0, len(fieldTypeNames))
+	for _, v := range fieldTypeNames {
+		typeByName[v.name] = v.fieldType
+		nameByType[v.fieldType] = v.name
+		typeOptions = append(typeOptions, v.name)
+	}
+
+	var rows []*fieldRow
+	rowsBox := container.NewVBox()
+
+	var render func()
+	render = func() {
+		rowsBox.Objects = nil
+		for _, row := range rows {
+			row := row
+			removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				for i, v := range rows {
+					if v == row {
+						rows = append(rows[:i], rows[i+1:]...)
+						break
+					}
+				}
+				render()
+			})
+			rowsBox.Add(container.NewBorder(nil, nil, nil, removeBtn, container.NewGridWithColumns(3, row.label, row.fieldType, row.value)))
+		}
+		rowsBox.Refresh()
+	}
+
+	addRow := func(f model.Field) {
+		row := &fieldRow{label: widget.NewEntry(), value: newFieldValueEntry(f.Type, f.Value)}
+		row.label.SetPlaceHolder("Название")
+		row.label.SetText(f.Label)
+
+		row.fieldType = widget.NewSelect(typeOptions, nil)
+		row.fieldType.SetSelected(nameByType[f.Type])
+		row.fieldType.OnChanged = func(name string) {
+			// скрытые и обычные значения отображаются разными виджетами
+			row.value = newFieldValueEntry(typeByName[name], row.value.Text)
+			render()
+		}
+
+		rows = append(rows, row)
+	}
+
+	for _, f := range fields {
+		addRow(f)
+	}
+	render()
+
+	addBtn := widget.NewButtonWithIcon("Добавить поле", theme.ContentAddIcon(), func() {
+		addRow(model.Field{Type: smodel.FieldTypeText})
+		render()
+	})
+
+	get = func() ([]model.Field, error) {
+		res := make([]model.Field, 0, len(rows))
+		for _, row := range rows {
+			f := model.Field{
+				Label: strings.TrimSpace(row.label.Text),
+				Type:  typeByName[row.fieldType.Selected],
+				Value: row.value.Text,
+			}
+
+			if err := validateField(f); err != nil {
+				return nil, err
+			}
+			res = append(res, f)
+		}
+
+		return res, nil
+	}
+
+	return container.NewVBox(rowsBox, addBtn), get
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/client/model"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validateField(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   model.Field
+		wantErr bool
+	}{
+		{name: "text", field: model.Field{Label: "question", Type: smodel.FieldTypeText, Value: "answer"}},
+		{name: "empty value", field: model.Field{Label: "site", Type: smodel.FieldTypeURL}},
+		{name: "empty label", field: model.Field{Label: " ", Type: smodel.FieldTypeText, Value: "value"}, wantErr: true},
+		{name: "url", field: model.Field{Label: "site", Type: smodel.FieldTypeURL, Value: "https://example.com/login"}},
+		{name: "bad url", field: model.Field{Label: "site", Type: smodel.FieldTypeURL, Value: "example"}, wantErr: true},
+		{name: "email", field: model.Field{Label: "recovery", Type: smodel.FieldTypeEmail, Value: "user@example.com"}},
+		{name: "bad email", field: model.Field{Label: "recovery", Type: smodel.FieldTypeEmail, Value: "user"}, wantErr: true},
+		{name: "date", field: model.Field{Label: "expires", Type: smodel.FieldTypeDate, Value: "2030-12-31"}},
+		{name: "bad date", field: model.Field{Label: "expires", Type: smodel.FieldTypeDate, Value: "31.12.2030"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateField(tt.field)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_encryptFields(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	fields := []model.Field{{Label: "pin", Type: smodel.FieldTypeHidden, Value: "1234"}}
+
+	enc, err := encryptFields(fields, key)
+	require.NoError(t, err)
+	require.Len(t, enc, 1)
+	assert.Equal(t, smodel.FieldTypeHidden, enc[0].Type)
+	assert.NotEqual(t, "pin", enc[0].Label)
+	assert.NotEqual(t, "1234", enc[0].Value)
+
+	dec, err := decryptFields(enc, key)
+	require.NoError(t, err)
+	assert.Equal(t, fields, dec)
+
+	_, err = decryptFields([]model.Field{{Label: crypt.EncodeBase64([]byte("broken")), Type: smodel.FieldTypeText}}, key)
+	assert.Error(t, err)
+}
@@ -0,0 +1,1005 @@
+package app
+
+import (
+	"errors"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+const (
+	noFolderOption = "Без папки"
+	noParentOption = "Корневая папка"
+)
+
+// itemFilter фильтр списков записей по папке и метке (локальные идентификаторы, 0 - без ограничения).
+type itemFilter struct {
+	folderID int
+	tagID    int
+}
+
+func (f itemFilter) match(folderID int, tagIDs []int) bool {
+	if f.folderID != 0 && f.folderID != folderID {
+		return false
+	}
+
+	if f.tagID == 0 {
+		return true
+	}
+
+	for _, id := range tagIDs {
+		if id == f.tagID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *App) AddFolder(folder *model.Folder, encrypted bool) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	if !encrypted {
+		encName, err := crypt.Encrypt([]byte(folder.Name), crypt.DecodeBase64(c.SignKey))
+		if err != nil {
+			return err
+		}
+
+		folder.Name = crypt.EncodeBase64(encName)
+	}
+
+	return a.db.AddFolder(folder)
+}
+
+func (a *App) GetAllFolders() (folders []model.Folder, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return folders, err
+	}
+	sKey := crypt.DecodeBase64(c.SignKey)
+
+	foldersEnc, err := a.db.GetAllFolders()
+
+	for _, v := range foldersEnc {
+		decName, err := crypt.Decrypt(crypt.DecodeBase64(v.Name), sKey)
+		if err != nil {
+			return folders, err
+		}
+
+		v.Name = string(decName)
+		folders = append(folders, v)
+	}
+
+	return folders, err
+}
+
+// DeleteFolder удаляет папку вместе с вложенными, записи из них остаются без папки.
+// На сервере вложенные папки удаляются каскадно, поэтому запрос отправляется только для самой папки.
+func (a *App) DeleteFolder(localID int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	folder, err := a.db.GetFolder(localID)
+	if err != nil {
+		return err
+	}
+
+	folders, err := a.db.GetAllFolders()
+	if err != nil {
+		return err
+	}
+
+	deleted := map[int]bool{localID: true}
+	for changed := true; changed; {
+		changed = false
+		for _, v := range folders {
+			if !deleted[v.LocalID] && deleted[v.ParentID] {
+				deleted[v.LocalID] = true
+				changed = true
+			}
+		}
+	}
+
+	for id := range deleted {
+		err = a.db.DeleteFolder(id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = a.updateItemRefs(func(folderID int, tagIDs []int) (int, []int) {
+		if deleted[folderID] {
+			folderID = 0
+		}
+
+		return folderID, tagIDs
+	})
+	if err != nil {
+		return err
+	}
+
+	if folder.ExternalID == 0 {
+		return nil
+	}
+
+	go func() {
+		err := a.HTTPService.DeleteFolder(c.AccessToken, folder.ExternalID)
+		if err != nil {
+			logger.Error("goroutine delete:", err)
+		}
+	}()
+
+	return nil
+}
+
+func (a *App) AddTag(tag *model.Tag, encrypted bool) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	if !encrypted {
+		encName, err := crypt.Encrypt([]byte(tag.Name), crypt.DecodeBase64(c.SignKey))
+		if err != nil {
+			return err
+		}
+
+		tag.Name = crypt.EncodeBase64(encName)
+	}
+
+	return a.db.AddTag(tag)
+}
+
+func (a *App) GetAllTags() (tags []model.Tag, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return tags, err
+	}
+	sKey := crypt.DecodeBase64(c.SignKey)
+
+	tagsEnc, err := a.db.GetAllTags()
+
+	for _, v := range tagsEnc {
+		decName, err := crypt.Decrypt(crypt.DecodeBase64(v.Name), sKey)
+		if err != nil {
+			return tags, err
+		}
+
+		v.Name = string(decName)
+		tags = append(tags, v)
+	}
+
+	return tags, err
+}
+
+// DeleteTag удаляет метку и снимает её с записей.
+func (a *App) DeleteTag(localID int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	tags, err := a.db.GetAllTags()
+	if err != nil {
+		return err
+	}
+
+	var tag model.Tag
+	for _, v := range tags {
+		if v.LocalID == localID {
+			tag = v
+		}
+	}
+
+	err = a.db.DeleteTag(localID)
+	if err != nil {
+		return err
+	}
+
+	err = a.updateItemRefs(func(folderID int, tagIDs []int) (int, []int) {
+		res := make([]int, 0, len(tagIDs))
+		for _, id := range tagIDs {
+			if id != localID {
+				res = append(res, id)
+			}
+		}
+
+		return folderID, res
+	})
+	if err != nil {
+		return err
+	}
+
+	if tag.ExternalID == 0 {
+		return nil
+	}
+
+	go func() {
+		err := a.HTTPService.DeleteTag(c.AccessToken, tag.ExternalID)
+		if err != nil {
+			logger.Error("goroutine delete:", err)
+		}
+	}()
+
+	return nil
+}
+
+// updateItemRefs меняет папку и метки всех локальных записей; записи остаются зашифрованными.
+func (a *App) updateItemRefs(fn func(folderID int, tagIDs []int) (int, []int)) error {
+	cards, err := a.db.GetAllCards()
+	if err != nil {
+		return err
+	}
+	for _, v := range cards {
+		v := v
+		v.FolderID, v.TagIDs = fn(v.FolderID, v.TagIDs)
+		if err = a.db.AddCard(&v); err != nil {
+			return err
+		}
+	}
+
+	creds, err := a.db.GetAllCreds()
+	if err != nil {
+		return err
+	}
+	for _, v := range creds {
+		v := v
+		v.FolderID, v.TagIDs = fn(v.FolderID, v.TagIDs)
+		if err = a.db.AddCred(&v); err != nil {
+			return err
+		}
+	}
+
+	texts, err := a.db.GetAllTexts()
+	if err != nil {
+		return err
+	}
+	for _, v := range texts {
+		v := v
+		v.FolderID, v.TagIDs = fn(v.FolderID, v.TagIDs)
+		if err = a.db.AddText(&v); err != nil {
+			return err
+		}
+	}
+
+	files, err := a.db.GetAllFiles()
+	if err != nil {
+		return err
+	}
+	for _, v := range files {
+		v := v
+		v.FolderID, v.TagIDs = fn(v.FolderID, v.TagIDs)
+		if err = a.db.AddFile(&v); err != nil {
+			return err
+		}
+	}
+
+	keys, err := a.db.GetAllSSHKeys()
+	if err != nil {
+		return err
+	}
+	for _, v := range keys {
+		v := v
+		v.FolderID, v.TagIDs = fn(v.FolderID, v.TagIDs)
+		if err = a.db.AddSSHKey(&v); err != nil {
+			return err
+		}
+	}
+
+	docs, err := a.db.GetAllIdentities()
+	if err != nil {
+		return err
+	}
+	for _, v := range docs {
+		v := v
+		v.FolderID, v.TagIDs = fn(v.FolderID, v.TagIDs)
+		if err = a.db.AddIdentity(&v); err != nil {
+			return err
+		}
+	}
+
+	items, err := a.db.GetAllCustomItems()
+	if err != nil {
+		return err
+	}
+	for _, v := range items {
+		v := v
+		v.FolderID, v.TagIDs = fn(v.FolderID, v.TagIDs)
+		if err = a.db.AddCustomItem(&v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SyncFolders синхронизирует папки до записей, чтобы записям было куда ссылаться.
+func (a *App) SyncFolders(accessToken string) (err error) {
+	folders, err := a.db.GetAllFolders()
+	if err != nil {
+		return err
+	}
+
+	getFolders, err := a.HTTPService.GetFolderList(accessToken)
+	if err != nil {
+		return err
+	}
+
+	localByExt := make(map[int]model.Folder)
+	for _, v := range folders {
+		if v.ExternalID != 0 {
+			localByExt[v.ExternalID] = v
+		}
+	}
+
+	getFoldersMap := make(map[int]*model.Folder)
+	for _, v := range getFolders {
+		getFoldersMap[v.ExternalID] = v
+	}
+
+	// создаем записи в бд клиента; родителей проставляем вторым проходом,
+	// когда у всех папок с сервера уже есть локальные идентификаторы
+	var pulled []int
+	for _, v := range getFolders {
+		val, ok := localByExt[v.ExternalID]
+		if ok && val.UpdatedAt.Unix() >= v.UpdatedAt.Unix() {
+			continue
+		}
+
+		updateFolder := *v
+		updateFolder.LocalID = val.LocalID
+		updateFolder.ParentID = val.ParentID
+
+		errUpdate := a.AddFolder(&updateFolder, true)
+		if errUpdate != nil {
+			logger.Error("SyncFolders - errUpdate local: ", errUpdate)
+			continue
+		}
+
+		localByExt[v.ExternalID] = updateFolder
+		pulled = append(pulled, v.ExternalID)
+	}
+
+	for _, extID := range pulled {
+		folder := localByExt[extID]
+		folder.ParentID = localByExt[getFoldersMap[extID].ParentID].LocalID
+
+		errUpdate := a.AddFolder(&folder, true)
+		if errUpdate != nil {
+			logger.Error("SyncFolders - errUpdate parent: ", errUpdate)
+		}
+	}
+
+	// создаем записи в бд сервера: сначала родительские папки, чтобы знать их идентификаторы
+	folders, err = a.db.GetAllFolders()
+	if err != nil {
+		return err
+	}
+
+	byLocal := make(map[int]model.Folder)
+	var pending []model.Folder
+	for _, v := range folders {
+		byLocal[v.LocalID] = v
+
+		if val, ok := getFoldersMap[v.ExternalID]; ok && val.UpdatedAt.Unix() >= v.UpdatedAt.Unix() {
+			continue
+		}
+		pending = append(pending, v)
+	}
+
+	for len(pending) > 0 {
+		var rest []model.Folder
+		for _, v := range pending {
+			parent, hasParent := byLocal[v.ParentID]
+			if hasParent && parent.ExternalID == 0 {
+				rest = append(rest, v)
+				continue
+			}
+
+			reqBody := smodel.Folder{
+				ID:        v.ExternalID,
+				ParentID:  parent.ExternalID,
+				Name:      v.Name,
+				UpdatedAt: v.UpdatedAt,
+			}
+
+			id, err := a.HTTPService.AddFolder(accessToken, reqBody)
+			if err != nil {
+				logger.Error("SyncFolders - add: ", err)
+				continue
+			}
+
+			v.ExternalID = id
+			err = a.db.AddFolder(&v)
+			if err != nil {
+				logger.Error("SyncFolders - save: ", err)
+				continue
+			}
+			byLocal[v.LocalID] = v
+		}
+
+		// родитель не сохранился на сервере - вложенные папки ждут следующей синхронизации
+		if len(rest) == len(pending) {
+			break
+		}
+		pending = rest
+	}
+
+	return nil
+}
+
+// SyncTags синхронизирует метки до записей.
+func (a *App) SyncTags(accessToken string) (err error) {
+	tags, err := a.db.GetAllTags()
+	if err != nil {
+		return err
+	}
+
+	getTags, err := a.HTTPService.GetTagList(accessToken)
+	if err != nil {
+		return err
+	}
+
+	localByExt := make(map[int]model.Tag)
+	for _, v := range tags {
+		if v.ExternalID != 0 {
+			localByExt[v.ExternalID] = v
+		}
+	}
+
+	getTagsMap := make(map[int]*model.Tag)
+
+	// создаем записи в бд клиента
+	for _, v := range getTags {
+		getTagsMap[v.ExternalID] = v
+
+		val, ok := localByExt[v.ExternalID]
+		if ok && val.UpdatedAt.Unix() >= v.UpdatedAt.Unix() {
+			continue
+		}
+
+		updateTag := *v
+		updateTag.LocalID = val.LocalID
+
+		errUpdate := a.AddTag(&updateTag, true)
+		if errUpdate != nil {
+			logger.Error("SyncTags - errUpdate local: ", errUpdate)
+		}
+	}
+
+	// создаем записи в бд сервера
+	for _, v := range tags {
+		if val, ok := getTagsMap[v.ExternalID]; ok && val.UpdatedAt.Unix() >= v.UpdatedAt.Unix() {
+			continue
+		}
+
+		reqBody := smodel.Tag{
+			ID:        v.ExternalID,
+			Name:      v.Name,
+			UpdatedAt: v.UpdatedAt,
+		}
+
+		id, err := a.HTTPService.AddTag(accessToken, reqBody)
+		if err != nil {
+			logger.Error("SyncTags - add: ", err)
+			continue
+		}
+
+		v.ExternalID = id
+		err = a.db.AddTag(&v)
+		if err != nil {
+			logger.Error("SyncTags - save: ", err)
+		}
+	}
+
+	return nil
+}
+
+// itemRefs соответствие локальных и серверных идентификаторов папок и меток
+// для перевода ссылок записей при синхронизации.
+type itemRefs struct {
+	folderToLocal    map[int]int
+	folderToExternal map[int]int
+	tagToLocal       map[int]int
+	tagToExternal    map[int]int
+}
+
+func (a *App) loadItemRefs() (refs itemRefs, err error) {
+	refs = itemRefs{
+		folderToLocal:    make(map[int]int),
+		folderToExternal: make(map[int]int),
+		tagToLocal:       make(map[int]int),
+		tagToExternal:    make(map[int]int),
+	}
+
+	folders, err := a.db.GetAllFolders()
+	if err != nil {
+		return refs, err
+	}
+
+	for _, v := range folders {
+		if v.ExternalID != 0 {
+			refs.folderToLocal[v.ExternalID] = v.LocalID
+			refs.folderToExternal[v.LocalID] = v.ExternalID
+		}
+	}
+
+	tags, err := a.db.GetAllTags()
+	if err != nil {
+		return refs, err
+	}
+
+	for _, v := range tags {
+		if v.ExternalID != 0 {
+			refs.tagToLocal[v.ExternalID] = v.LocalID
+			refs.tagToExternal[v.LocalID] = v.ExternalID
+		}
+	}
+
+	return refs, nil
+}
+
+// mapRefs переводит идентификаторы по таблицам соответствия, неизвестные отбрасываются.
+func mapRefs(folders, tags map[int]int, folderID int, tagIDs []int) (int, []int) {
+	res := make([]int, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		if mapped, ok := tags[id]; ok {
+			res = append(res, mapped)
+		}
+	}
+
+	return folders[folderID], res
+}
+
+func (r itemRefs) toLocal(folderID int, tagIDs []int) (int, []int) {
+	return mapRefs(r.folderToLocal, r.tagToLocal, folderID, tagIDs)
+}
+
+func (r itemRefs) toExternal(folderID int, tagIDs []int) (int, []int) {
+	return mapRefs(r.folderToExternal, r.tagToExternal, folderID, tagIDs)
+}
+
+// folderPaths полные пути папок вида "Родитель / Папка" по локальным идентификаторам.
+func folderPaths(folders []model.Folder) map[int]string {
+	byID := make(map[int]model.Folder, len(folders))
+	for _, f := range folders {
+		byID[f.LocalID] = f
+	}
+
+	paths := make(map[int]string, len(folders))
+	for _, f := range folders {
+		names := []string{f.Name}
+		// ограничение глубины защищает от зацикленных данных
+		for p, depth := f.ParentID, 0; p != 0 && depth < len(folders); depth++ {
+			parent, ok := byID[p]
+			if !ok {
+				break
+			}
+			names = append([]string{parent.Name}, names...)
+			p = parent.ParentID
+		}
+		paths[f.LocalID] = strings.Join(names, " / ")
+	}
+
+	return paths
+}
+
+// sortedFolderIDs идентификаторы папок в порядке путей, для списков выбора.
+func sortedFolderIDs(paths map[int]string) []int {
+	ids := make([]int, 0, len(paths))
+	for id := range paths {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return paths[ids[i]] < paths[ids[j]] })
+
+	return ids
+}
+
+// tagLabels подписи меток; одинаковые названия различаются номером.
+func tagLabels(tags []model.Tag) map[int]string {
+	count := make(map[string]int)
+	for _, t := range tags {
+		count[t.Name]++
+	}
+
+	labels := make(map[int]string, len(tags))
+	for _, t := range tags {
+		labels[t.LocalID] = t.Name
+		if count[t.Name] > 1 {
+			labels[t.LocalID] = t.Name + " #" + strconv.Itoa(t.LocalID)
+		}
+	}
+
+	return labels
+}
+
+// itemRefsFormItems поля выбора папки и меток для форм записей; get возвращает выбранные значения.
+func (a *App) itemRefsFormItems(folderID int, tagIDs []int) (items []*widget.FormItem, get func() (int, []int)) {
+	folders, err := a.GetAllFolders()
+	if err != nil {
+		logger.Error(err)
+	}
+	tags, err := a.GetAllTags()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	paths := folderPaths(folders)
+	folderByPath := make(map[string]int, len(paths))
+	folderOptions := []string{noFolderOption}
+	for _, id := range sortedFolderIDs(paths) {
+		folderByPath[paths[id]] = id
+		folderOptions = append(folderOptions, paths[id])
+	}
+
+	folderSelect := widget.NewSelect(folderOptions, nil)
+	folderSelect.SetSelected(noFolderOption)
+	if path, ok := paths[folderID]; ok {
+		folderSelect.SetSelected(path)
+	}
+
+	labels := tagLabels(tags)
+	tagByLabel := make(map[string]int, len(labels))
+	tagOptions := make([]string, 0, len(tags))
+	for _, t := range tags {
+		tagByLabel[labels[t.LocalID]] = t.LocalID
+		tagOptions = append(tagOptions, labels[t.LocalID])
+	}
+
+	tagGroup := widget.NewCheckGroup(tagOptions, nil)
+	tagGroup.Horizontal = true
+	var selected []string
+	for _, id := range tagIDs {
+		if label, ok := labels[id]; ok {
+			selected = append(selected, label)
+		}
+	}
+	tagGroup.SetSelected(selected)
+
+	items = []*widget.FormItem{widget.NewFormItem("Папка", folderSelect)}
+	if len(tagOptions) > 0 {
+		items = append(items, widget.NewFormItem("Метки", tagGroup))
+	}
+
+	get = func() (int, []int) {
+		ids := make([]int, 0, len(tagGroup.Selected))
+		for _, label := range tagGroup.Selected {
+			ids = append(ids, tagByLabel[label])
+		}
+
+		return folderByPath[folderSelect.Selected], ids
+	}
+
+	return items, get
+}
+
+// filterBar дерево папок и метки-фильтры над списками записей.
+func (a *App) filterBar(currentType func() ui.DataType) *widget.Accordion {
+	folders, err := a.GetAllFolders()
+	if err != nil {
+		logger.Error(err)
+	}
+	tags, err := a.GetAllTags()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	children := make(map[string][]string)
+	names := make(map[string]string)
+	for _, f := range folders {
+		id := strconv.Itoa(f.LocalID)
+		parent := ""
+		if f.ParentID != 0 {
+			parent = strconv.Itoa(f.ParentID)
+		}
+		children[parent] = append(children[parent], id)
+		names[id] = f.Name
+	}
+
+	tree := widget.NewTree(
+		func(uid widget.TreeNodeID) []widget.TreeNodeID {
+			return children[uid]
+		},
+		func(uid widget.TreeNodeID) bool {
+			return uid == "" || len(children[uid]) > 0
+		},
+		func(branch bool) fyne.CanvasObject {
+			return widget.NewLabel("Папка")
+		},
+		func(uid widget.TreeNodeID, branch bool, co fyne.CanvasObject) {
+			co.(*widget.Label).SetText(names[uid])
+		},
+	)
+	tree.OpenAllBranches()
+	if a.filter.folderID != 0 {
+		tree.Select(strconv.Itoa(a.filter.folderID))
+	}
+	tree.OnSelected = func(uid widget.TreeNodeID) {
+		id, _ := strconv.Atoi(uid)
+		if id == a.filter.folderID {
+			return
+		}
+		a.filter.folderID = id
+		a.pageMain(currentType())
+	}
+
+	allBtn := widget.NewButtonWithIcon("Все папки", theme.HomeIcon(), func() {
+		a.filter.folderID = 0
+		a.pageMain(currentType())
+	})
+
+	chips := container.NewHBox()
+	for _, t := range tags {
+		tagID := t.LocalID
+		chip := widget.NewButton(t.Name, func() {
+			if a.filter.tagID == tagID {
+				a.filter.tagID = 0
+			} else {
+				a.filter.tagID = tagID
+			}
+			a.pageMain(currentType())
+		})
+		if a.filter.tagID == tagID {
+			chip.Importance = widget.HighImportance
+		}
+		chips.Add(chip)
+	}
+
+	treeScroll := container.NewVScroll(tree)
+	treeScroll.SetMinSize(fyne.NewSize(100, 120))
+
+	content := container.NewVBox(allBtn, treeScroll)
+	if len(tags) > 0 {
+		content.Add(container.NewHScroll(chips))
+	}
+
+	title := "Папки и метки"
+	if a.filter.folderID != 0 || a.filter.tagID != 0 {
+		title += " (фильтр включен)"
+	}
+
+	accordion := widget.NewAccordion(widget.NewAccordionItem(title, content))
+	if a.filter.folderID != 0 || a.filter.tagID != 0 {
+		accordion.Open(0)
+	}
+
+	return accordion
+}
+
+// pageFolders управление папками: создание, переименование, перенос и удаление.
+func (a *App) pageFolders(dataType ui.DataType) {
+	folders, err := a.GetAllFolders()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	paths := folderPaths(folders)
+	ids := sortedFolderIDs(paths)
+	byID := make(map[int]model.Folder, len(folders))
+	for _, f := range folders {
+		byID[f.LocalID] = f
+	}
+
+	var editID int
+
+	name := widget.NewEntry()
+	name.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
+
+	parentByPath := make(map[string]int, len(paths))
+	parentOptions := []string{noParentOption}
+	for _, id := range ids {
+		parentByPath[paths[id]] = id
+		parentOptions = append(parentOptions, paths[id])
+	}
+	parent := widget.NewSelect(parentOptions, nil)
+	parent.SetSelected(noParentOption)
+
+	form := widget.NewForm(
+		widget.NewFormItem("Название", name),
+		widget.NewFormItem("Родитель", parent),
+	)
+	form.SubmitText = "Сохранить"
+	form.OnSubmit = func() {
+		folder := model.Folder{
+			LocalID:   editID,
+			Name:      name.Text,
+			ParentID:  parentByPath[parent.Selected],
+			UpdatedAt: time.Now(),
+		}
+
+		if editID != 0 {
+			folder.ExternalID = byID[editID].ExternalID
+
+			// папку нельзя перенести в саму себя или во вложенную папку
+			for p := folder.ParentID; p != 0; p = byID[p].ParentID {
+				if p == editID {
+					dialog.ShowError(errors.New("нельзя перенести папку во вложенную"), a.window)
+					return
+				}
+			}
+		}
+
+		err := a.AddFolder(&folder, false)
+		if err != nil {
+			logger.Error(err)
+			dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+			return
+		}
+
+		a.pageFolders(dataType)
+	}
+	form.CancelText = "Новая"
+	form.OnCancel = func() {
+		a.pageFolders(dataType)
+	}
+
+	list := widget.NewList(
+		func() int {
+			return len(ids)
+		},
+		func() fyne.CanvasObject {
+			return container.NewHBox(widget.NewLabel("Папка"), layout.NewSpacer(), widget.NewButtonWithIcon("", theme.DeleteIcon(), nil))
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			id := ids[lii]
+			row := co.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(paths[id])
+			row.Objects[2].(*widget.Button).OnTapped = func() {
+				dialog.ShowConfirm("Удалить", "Удалить папку вместе с вложенными? Записи останутся без папки.", func(b bool) {
+					if !b {
+						return
+					}
+
+					if err := a.DeleteFolder(id); err != nil {
+						logger.Error("delete folder:", err)
+						dialog.ShowError(errors.New("ошибка при удалении папки"), a.window)
+
+						return
+					}
+
+					if a.filter.folderID == id {
+						a.filter.folderID = 0
+					}
+					a.pageFolders(dataType)
+				}, a.window)
+			}
+		},
+	)
+	list.OnSelected = func(lii widget.ListItemID) {
+		editID = ids[lii]
+		name.SetText(byID[editID].Name)
+		parent.SetSelected(noParentOption)
+		if p, ok := paths[byID[editID].ParentID]; ok {
+			parent.SetSelected(p)
+		}
+	}
+
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(100, 400))
+
+	a.window.SetContent(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageMain(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText("Папки", color.Black),
+		),
+		canvas.NewLine(color.Black),
+		form,
+		scroll,
+	))
+}
+
+// pageTags управление метками: создание, переименование и удаление.
+func (a *App) pageTags(dataType ui.DataType) {
+	tags, err := a.GetAllTags()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	var edit model.Tag
+
+	name := widget.NewEntry()
+	name.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
+
+	form := widget.NewForm(widget.NewFormItem("Название", name))
+	form.SubmitText = "Сохранить"
+	form.OnSubmit = func() {
+		tag := model.Tag{
+			LocalID:    edit.LocalID,
+			ExternalID: edit.ExternalID,
+			Name:       name.Text,
+			UpdatedAt:  time.Now(),
+		}
+
+		err := a.AddTag(&tag, false)
+		if err != nil {
+			logger.Error(err)
+			dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+			return
+		}
+
+		a.pageTags(dataType)
+	}
+	form.CancelText = "Новая"
+	form.OnCancel = func() {
+		a.pageTags(dataType)
+	}
+
+	list := widget.NewList(
+		func() int {
+			return len(tags)
+		},
+		func() fyne.CanvasObject {
+			return container.NewHBox(widget.NewLabel("Метка"), layout.NewSpacer(), widget.NewButtonWithIcon("", theme.DeleteIcon(), nil))
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			tag := tags[lii]
+			row := co.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(tag.Name)
+			row.Objects[2].(*widget.Button).OnTapped = func() {
+				dialog.ShowConfirm("Удалить", "Удалить метку? Она будет снята со всех записей.", func(b bool) {
+					if !b {
+						return
+					}
+
+					if err := a.DeleteTag(tag.LocalID); err != nil {
+						logger.Error("delete tag:", err)
+						dialog.ShowError(errors.New("ошибка при удалении метки"), a.window)
+
+						return
+					}
+
+					if a.filter.tagID == tag.LocalID {
+						a.filter.tagID = 0
+					}
+					a.pageTags(dataType)
+				}, a.window)
+			}
+		},
+	)
+	list.OnSelected = func(lii widget.ListItemID) {
+		edit = tags[lii]
+		name.SetText(edit.Name)
+	}
+
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(100, 400))
+
+	a.window.SetContent(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageMain(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText("Метки", color.Black),
+		),
+		canvas.NewLine(color.Black),
+		form,
+		scroll,
+	))
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_itemFilter_match(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   itemFilter
+		folderID int
+		tagIDs   []int
+		want     bool
+	}{
+		{name: "empty filter", filter: itemFilter{}, folderID: 1, want: true},
+		{name: "folder match", filter: itemFilter{folderID: 1}, folderID: 1, want: true},
+		{name: "folder mismatch", filter: itemFilter{folderID: 1}, folderID: 2, want: false},
+		{name: "tag match", filter: itemFilter{tagID: 3}, tagIDs: []int{2, 3}, want: true},
+		{name: "tag mismatch", filter: itemFilter{tagID: 3}, tagIDs: []int{2}, want: false},
+		{name: "folder and tag", filter: itemFilter{folderID: 1, tagID: 3}, folderID: 1, tagIDs: []int{3}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.match(tt.folderID, tt.tagIDs))
+		})
+	}
+}
+
+func Test_folderPaths(t *testing.T) {
+	folders := []model.Folder{
+		{LocalID: 1, Name: "work"},
+		{LocalID: 2, ParentID: 1, Name: "bank"},
+		{LocalID: 3, ParentID: 2, Name: "cards"},
+		{LocalID: 4, ParentID: 9, Name: "orphan"},
+	}
+
+	assert.Equal(t, map[int]string{
+		1: "work",
+		2: "work / bank",
+		3: "work / bank / cards",
+		4: "orphan",
+	}, folderPaths(folders))
+}
+
+func Test_itemRefs(t *testing.T) {
+	refs := itemRefs{
+		folderToLocal:    map[int]int{10: 1},
+		folderToExternal: map[int]int{1: 10},
+		tagToLocal:       map[int]int{20: 2},
+		tagToExternal:    map[int]int{2: 20},
+	}
+
+	folderID, tagIDs := refs.toExternal(1, []int{2, 3})
+	assert.Equal(t, 10, folderID)
+	assert.Equal(t, []int{20}, tagIDs)
+
+	folderID, tagIDs = refs.toLocal(11, []int{20})
+	assert.Zero(t, folderID)
+	assert.Equal(t, []int{2}, tagIDs)
+}
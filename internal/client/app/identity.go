@@ -0,0 +1,441 @@
+package app
+
+import (
+	"errors"
+	"image/color"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// identityKindNames подписи видов документов в порядке списка выбора.
+var identityKindNames = []struct {
+	kind string
+	name string
+}{
+	{smodel.IdentityKindPassport, "Паспорт"},
+	{smodel.IdentityKindDriverLicense, "Водительское удостоверение"},
+	{smodel.IdentityKindIDCard, "ID-карта"},
+}
+
+func identityKindName(kind string) string {
+	for _, v := range identityKindNames {
+		if v.kind == kind {
+			return v.name
+		}
+	}
+
+	return kind
+}
+
+// identitySecrets поля документа, которые шифруются на клиенте, как все данные карты.
+func identitySecrets(doc *model.DataIdentity) []*string {
+	return []*string{&doc.FullName, &doc.Number, &doc.Country, &doc.IssueDate, &doc.ExpiryDate, &doc.Address, &doc.Meta}
+}
+
+// validateIdentity проверяет документ перед сохранением: обязательные поля и даты в формате ГГГГ-ММ-ДД,
+// дата окончания действия не раньше даты выдачи.
+func validateIdentity(doc model.DataIdentity) error {
+	if strings.TrimSpace(doc.Title) == "" {
+		return errors.New("не заполнен заголовок")
+	}
+
+	if strings.TrimSpace(doc.FullName) == "" {
+		return errors.New("не заполнено ФИО")
+	}
+
+	if strings.TrimSpace(doc.Number) == "" {
+		return errors.New("не заполнен номер документа")
+	}
+
+	var issue, expiry time.Time
+	var err error
+
+	if doc.IssueDate != "" {
+		issue, err = time.Parse(fieldDateLayout, doc.IssueDate)
+		if err != nil {
+			return errors.New("дата выдачи в формате ГГГГ-ММ-ДД")
+		}
+	}
+
+	if doc.ExpiryDate != "" {
+		expiry, err = time.Parse(fieldDateLayout, doc.ExpiryDate)
+		if err != nil {
+			return errors.New("дата окончания действия в формате ГГГГ-ММ-ДД")
+		}
+	}
+
+	if !issue.IsZero() && !expiry.IsZero() && expiry.Before(issue) {
+		return errors.New("дата окончания действия раньше даты выдачи")
+	}
+
+	return nil
+}
+
+func (a *App) AddIdentity(doc *model.DataIdentity, encrypted bool) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	if !encrypted {
+		sKey := crypt.DecodeBase64(c.SignKey)
+		for _, v := range identitySecrets(doc) {
+			enc, err := crypt.Encrypt([]byte(*v), sKey)
+			if err != nil {
+				return err
+			}
+			*v = crypt.EncodeBase64(enc)
+		}
+
+		doc.Fields, err = encryptFields(doc.Fields, sKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	return a.db.AddIdentity(doc)
+}
+
+// decryptIdentity расшифровывает документ из локальной БД.
+func decryptIdentity(doc model.DataIdentity, sKey []byte) (model.DataIdentity, error) {
+	for _, v := range identitySecrets(&doc) {
+		dec, err := crypt.Decrypt(crypt.DecodeBase64(*v), sKey)
+		if err != nil {
+			return doc, err
+		}
+		*v = string(dec)
+	}
+
+	var err error
+	doc.Fields, err = decryptFields(doc.Fields, sKey)
+
+	return doc, err
+}
+
+func (a *App) GetIdentity(localID int) (doc model.DataIdentity, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return doc, err
+	}
+
+	doc, err = a.db.GetIdentity(localID)
+	if err != nil {
+		return doc, err
+	}
+
+	return decryptIdentity(doc, crypt.DecodeBase64(c.SignKey))
+}
+
+func (a *App) GetAllIdentities() (docs []model.DataIdentity, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return docs, err
+	}
+	sKey := crypt.DecodeBase64(c.SignKey)
+
+	docsEnc, err := a.db.GetAllIdentities()
+	if err != nil {
+		return docs, err
+	}
+
+	for _, v := range docsEnc {
+		v, err = decryptIdentity(v, sKey)
+		if err != nil {
+			return docs, err
+		}
+
+		docs = append(docs, v)
+	}
+
+	return docs, nil
+}
+
+func (a *App) DeleteIdentity(localID int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	item, err := a.db.GetIdentity(localID)
+	if err != nil {
+		return err
+	}
+
+	err = a.db.DeleteIdentity(localID)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err := a.HTTPService.DeleteIdentity(c.AccessToken, item.ExternalID)
+		if err != nil {
+			logger.Error("goroutine delete:", err)
+		}
+	}()
+
+	return nil
+}
+
+func (a *App) SyncIdentities(accessToken string) (err error) {
+	docsMap := make(map[int]*model.DataIdentity)
+	getDocsMap := make(map[int]*model.DataIdentity)
+
+	docs, err := a.db.GetAllIdentities()
+	if err != nil {
+		return err
+	}
+
+	for i := range docs {
+		docsMap[docs[i].ExternalID] = &docs[i]
+	}
+
+	getDocs, err := a.HTTPService.GetIdentityList(accessToken)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range getDocs {
+		getDocsMap[v.ExternalID] = v
+	}
+
+	refs, err := a.loadItemRefs()
+	if err != nil {
+		return err
+	}
+
+	// создаем записи в бд клиента
+	for _, v := range getDocs {
+		updateDoc := *v
+		updateDoc.LocalID = 0
+		if val, ok := docsMap[v.ExternalID]; ok {
+			// если дата на сервере новее обновим локальные данные
+			if val.UpdatedAt.Unix() >= v.UpdatedAt.Unix() {
+				continue
+			}
+			updateDoc.LocalID = val.LocalID
+		}
+
+		updateDoc.FolderID, updateDoc.TagIDs = refs.toLocal(v.FolderID, v.TagIDs)
+		errAdd := a.AddIdentity(&updateDoc, true)
+		if errAdd != nil {
+			logger.Error(errAdd)
+		}
+	}
+
+	// создаем записи в бд сервера
+	for _, v := range docs {
+		if val, ok := getDocsMap[v.ExternalID]; ok {
+			if val.UpdatedAt.Unix() > v.UpdatedAt.Unix() {
+				continue
+			}
+		}
+
+		// отправим на сервер
+		reqBody := smodel.DataIdentity{
+			ID:         v.ExternalID,
+			Title:      v.Title,
+			Kind:       v.Kind,
+			FullName:   v.FullName,
+			Number:     v.Number,
+			Country:    v.Country,
+			IssueDate:  v.IssueDate,
+			ExpiryDate: v.ExpiryDate,
+			Address:    v.Address,
+			Meta:       v.Meta,
+			UpdatedAt:  v.UpdatedAt,
+		}
+		reqBody.FolderID, reqBody.TagIDs = refs.toExternal(v.FolderID, v.TagIDs)
+		reqBody.Fields = toServerFields(v.Fields)
+
+		id, err := a.HTTPService.AddIdentity(accessToken, reqBody)
+		if err != nil {
+			logger.Error(err)
+
+			continue
+		}
+
+		v := v
+		v.ExternalID = id
+		err = a.db.AddIdentity(&v)
+		if err != nil {
+			logger.Error(err)
+		}
+	}
+
+	return nil
+}
+
+func (a *App) identityList() *fyne.Container {
+	var docs []model.DataIdentity
+
+	noItems := container.NewCenter(canvas.NewText("Нет записей", color.Black))
+
+	allDocs, err := a.GetAllIdentities()
+	if err != nil {
+		logger.Error(err)
+	}
+	for _, v := range allDocs {
+		if a.filter.match(v.FolderID, v.TagIDs) {
+			docs = append(docs, v)
+		}
+	}
+
+	docsList := widget.NewList(
+		func() int {
+			return len(docs)
+		},
+
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Default")
+		},
+
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			text := docs[lii].Title + " (" + identityKindName(docs[lii].Kind) + ")"
+			if docs[lii].ExpiryDate != "" {
+				text += "  до " + docs[lii].ExpiryDate
+			}
+			co.(*widget.Label).SetText(text)
+		},
+	)
+
+	docsList.OnSelected = func(id widget.ListItemID) {
+		a.pageEdit(docs[id].LocalID, ui.TypeIdentity)
+	}
+
+	scroll := container.NewScroll(docsList)
+	scroll.SetMinSize(fyne.NewSize(100, 700))
+
+	if len(docs) != 0 {
+		noItems.Hide()
+	}
+
+	return container.New(layout.NewPaddedLayout(), scroll, noItems)
+}
+
+func (a *App) addIdentityForm(localID int) *fyne.Container {
+	var err error
+	var item model.DataIdentity
+
+	title := widget.NewEntry()
+	fullName := widget.NewEntry()
+	number := widget.NewEntry()
+	country := widget.NewEntry()
+	issueDate := widget.NewEntry()
+	issueDate.SetPlaceHolder("ГГГГ-ММ-ДД")
+	expiryDate := widget.NewEntry()
+	expiryDate.SetPlaceHolder("ГГГГ-ММ-ДД")
+	address := widget.NewMultiLineEntry()
+	meta := widget.NewMultiLineEntry()
+
+	kindOptions := make([]string, 0, len(identityKindNames))
+	for _, v := range identityKindNames {
+		kindOptions = append(kindOptions, v.name)
+	}
+	kind := widget.NewSelect(kindOptions, nil)
+	kind.SetSelectedIndex(0)
+
+	if localID > 0 {
+		item, err = a.GetIdentity(localID)
+		if err != nil {
+			logger.Error(err)
+		}
+		title.Text = item.Title
+		kind.SetSelected(identityKindName(item.Kind))
+		fullName.Text = item.FullName
+		number.Text = item.Number
+		country.Text = item.Country
+		issueDate.Text = item.IssueDate
+		expiryDate.Text = item.ExpiryDate
+		address.Text = item.Address
+		meta.Text = item.Meta
+	}
+
+	title.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
+	fullName.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
+	number.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
+
+	addForm := widget.NewForm(
+		widget.NewFormItem("Заголовок", title),
+		widget.NewFormItem("Вид документа", kind),
+		widget.NewFormItem("ФИО", fullName),
+		widget.NewFormItem("Номер", number),
+		widget.NewFormItem("Страна выдачи", country),
+		widget.NewFormItem("Дата выдачи", issueDate),
+		widget.NewFormItem("Действует до", expiryDate),
+		widget.NewFormItem("Адрес", address),
+		widget.NewFormItem("Дополнительно", meta),
+	)
+
+	fieldsBox, getFields := fieldsEditor(item.Fields)
+	addForm.Append("Поля", fieldsBox)
+
+	refsFormItems, getRefs := a.itemRefsFormItems(item.FolderID, item.TagIDs)
+	for _, v := range refsFormItems {
+		addForm.AppendItem(v)
+	}
+
+	addForm.CancelText = "Отмена"
+	addForm.OnCancel = func() {
+		a.pageMain(ui.TypeIdentity)
+	}
+	addForm.SubmitText = "Сохранить"
+	addForm.OnSubmit = func() {
+		var err error
+
+		docData := model.DataIdentity{
+			Title:      title.Text,
+			Kind:       identityKindNames[kind.SelectedIndex()].kind,
+			FullName:   fullName.Text,
+			Number:     number.Text,
+			Country:    country.Text,
+			IssueDate:  strings.TrimSpace(issueDate.Text),
+			ExpiryDate: strings.TrimSpace(expiryDate.Text),
+			Address:    address.Text,
+			Meta:       meta.Text,
+			UpdatedAt:  time.Now(),
+		}
+
+		err = validateIdentity(docData)
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
+		docData.FolderID, docData.TagIDs = getRefs()
+
+		docData.Fields, err = getFields()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
+		if localID > 0 {
+			docData.LocalID = item.LocalID
+			docData.ExternalID = item.ExternalID
+		}
+
+		err = a.AddIdentity(&docData, false)
+		if err != nil {
+			dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+			return
+		}
+
+		a.pageMain(ui.TypeIdentity)
+	}
+
+	return container.NewVBox(append(a.totpViews("", item.Fields), addForm)...)
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateIdentity(t *testing.T) {
+	valid := model.DataIdentity{Title: "паспорт", FullName: "Иванов Иван", Number: "4500 123456"}
+
+	tests := []struct {
+		name    string
+		modify  func(d *model.DataIdentity)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(d *model.DataIdentity) {}},
+		{name: "dates", modify: func(d *model.DataIdentity) { d.IssueDate, d.ExpiryDate = "2020-01-15", "2030-01-15" }},
+		{name: "only expiry", modify: func(d *model.DataIdentity) { d.ExpiryDate = "2030-01-15" }},
+		{name: "empty title", modify: func(d *model.DataIdentity) { d.Title = " " }, wantErr: true},
+		{name: "empty name", modify: func(d *model.DataIdentity) { d.FullName = "" }, wantErr: true},
+		{name: "empty number", modify: func(d *model.DataIdentity) { d.Number = "" }, wantErr: true},
+		{name: "bad issue date", modify: func(d *model.DataIdentity) { d.IssueDate = "15.01.2020" }, wantErr: true},
+		{name: "bad expiry date", modify: func(d *model.DataIdentity) { d.ExpiryDate = "2030-13-01" }, wantErr: true},
+		{name: "expiry before issue", modify: func(d *model.DataIdentity) { d.IssueDate, d.ExpiryDate = "2020-01-15", "2019-01-15" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := valid
+			tt.modify(&d)
+			err := validateIdentity(d)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func Test_identityReminders(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	docs := []model.DataIdentity{
+		{LocalID: 1, Title: "паспорт", ExpiryDate: "2026-11-10"},
+		{LocalID: 2, Title: "права", ExpiryDate: "2026-10-01"},
+		{LocalID: 3, Title: "ID-карта", ExpiryDate: "2027-10-01"},
+		{LocalID: 4, Title: "без срока"},
+		{LocalID: 5, Title: "сегодня", ExpiryDate: "2026-10-19"},
+		{LocalID: 6, Title: "ошибка", ExpiryDate: "скоро"},
+	}
+
+	got := identityReminders(docs, now)
+
+	var ids []int
+	for _, r := range got {
+		ids = append(ids, r.localID)
+	}
+	assert.Equal(t, []int{2, 5, 1}, ids)
+
+	assert.Equal(t, "права: срок действия истек 2026-10-01", got[0].String(now))
+	assert.Equal(t, "сегодня: действует до 2026-10-19", got[1].String(now))
+	assert.Equal(t, "паспорт: действует до 2026-11-10", got[2].String(now))
+}
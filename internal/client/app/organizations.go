@@ -0,0 +1,756 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/service"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// personalVault пункт переключателя коллекций, соответствующий личному хранилищу.
+const personalVault = "Личное хранилище"
+
+// collectionFields поля записей коллекций по типам записей, в порядке отображения в форме.
+var collectionFields = map[string][]string{
+	smodel.ItemTypeCard: {"title", "number", "date", "cvv", "meta"},
+	smodel.ItemTypeCred: {"title", "username", "password", "meta"},
+	smodel.ItemTypeText: {"title", "text", "meta"},
+}
+
+// collectionTypeLabels подписи типов записей коллекций.
+var collectionTypeLabels = map[string]string{
+	smodel.ItemTypeCard: ui.TabCard.String(),
+	smodel.ItemTypeCred: ui.TabCred.String(),
+	smodel.ItemTypeText: ui.TabText.String(),
+}
+
+// roleLabels подписи ролей участников организации.
+var roleLabels = map[string]string{
+	smodel.RoleOwner:    "Владелец",
+	smodel.RoleAdmin:    "Администратор",
+	smodel.RoleMember:   "Участник",
+	smodel.RoleReadOnly: "Только чтение",
+}
+
+var errCollectionKey = errors.New("ключ коллекции недоступен")
+
+// collectionItem запись коллекции с расшифрованными полями.
+type collectionItem struct {
+	item   smodel.CollectionItem
+	values map[string]any
+}
+
+// title название записи коллекции с типом.
+func (c collectionItem) title() string {
+	title, _ := c.values["title"].(string)
+
+	return fmt.Sprintf("%s: %s", collectionTypeLabels[c.item.ItemType], title)
+}
+
+// CreateOrganization создает организацию, владельцем которой становится пользователь. Ключи пользователя
+// создаются заранее, чтобы ему можно было зашифровать ключи коллекций.
+func (a *App) CreateOrganization(name string) (id int, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return id, err
+	}
+
+	if _, _, err = a.userKeys(c.AccessToken); err != nil {
+		return id, err
+	}
+
+	return a.HTTPService.SaveOrganization(c.AccessToken, smodel.Organization{Name: name})
+}
+
+func (a *App) DeleteOrganization(orgID int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	return a.HTTPService.DeleteOrganization(c.AccessToken, orgID)
+}
+
+// GetOrganizations организации пользователя, включая непринятые приглашения.
+func (a *App) GetOrganizations() (orgs []smodel.Organization, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return orgs, err
+	}
+
+	return a.HTTPService.GetOrganizationList(c.AccessToken)
+}
+
+func (a *App) GetMembers(orgID int) (members []smodel.Member, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return members, err
+	}
+
+	return a.HTTPService.GetMemberList(c.AccessToken, orgID)
+}
+
+// InviteMember приглашает пользователя login с ролью role или меняет его роль. Ключи коллекций
+// организации, доступные пользователю, шифруются открытым ключом приглашенного.
+func (a *App) InviteMember(orgID int, login, role string) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	_, private, err := a.userKeys(c.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	pub, err := a.HTTPService.GetPublicKey(c.AccessToken, login)
+	if errors.Is(err, service.ErrStatusNotFound) {
+		return errShareNoTarget
+	}
+
+	if err != nil {
+		return err
+	}
+
+	cols, err := a.GetCollections()
+	if err != nil {
+		return err
+	}
+
+	member := smodel.Member{OrgID: orgID, Login: login, Role: role}
+	for _, col := range cols {
+		if col.OrgID != orgID {
+			continue
+		}
+
+		key, err := crypt.OpenX25519(crypt.DecodeBase64(col.Key), private)
+		if err != nil {
+			logger.Error("InviteMember - open key: ", err, col.ID)
+			continue
+		}
+
+		sealed, err := crypt.SealX25519(key, crypt.DecodeBase64(pub.PublicKey))
+		if err != nil {
+			return err
+		}
+
+		member.Keys = append(member.Keys, smodel.CollectionKey{CollectionID: col.ID, Login: login, Key: crypt.EncodeBase64(sealed)})
+	}
+
+	return a.HTTPService.InviteMember(c.AccessToken, member)
+}
+
+func (a *App) AcceptInvite(orgID int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	return a.HTTPService.AcceptInvite(c.AccessToken, orgID)
+}
+
+// RemoveMember исключает участника login; с логином пользователя - отказ от приглашения или выход из организации.
+func (a *App) RemoveMember(orgID int, login string) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	return a.HTTPService.RemoveMember(c.AccessToken, orgID, login)
+}
+
+// CreateCollection создает коллекцию со случайным ключом, зашифрованным для каждого участника
+// организации, у которого есть ключи. Участникам без ключей доступ дает повторное приглашение.
+func (a *App) CreateCollection(orgID int, name string) (id int, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return id, err
+	}
+
+	if _, _, err = a.userKeys(c.AccessToken); err != nil {
+		return id, err
+	}
+
+	members, err := a.HTTPService.GetMemberList(c.AccessToken, orgID)
+	if err != nil {
+		return id, err
+	}
+
+	key := make([]byte, shareKeySize)
+	if _, err = rand.Read(key); err != nil {
+		return id, err
+	}
+
+	col := smodel.Collection{OrgID: orgID, Name: name}
+	for _, m := range members {
+		if m.PublicKey == "" {
+			continue
+		}
+
+		sealed, err := crypt.SealX25519(key, crypt.DecodeBase64(m.PublicKey))
+		if err != nil {
+			return id, err
+		}
+
+		col.Keys = append(col.Keys, smodel.CollectionKey{Login: m.Login, Key: crypt.EncodeBase64(sealed)})
+	}
+
+	return a.HTTPService.SaveCollection(c.AccessToken, col)
+}
+
+func (a *App) DeleteCollection(colID int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	return a.HTTPService.DeleteCollection(c.AccessToken, colID)
+}
+
+// GetCollections коллекции организаций, в которых пользователь участвует.
+func (a *App) GetCollections() (cols []smodel.Collection, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return cols, err
+	}
+
+	return a.HTTPService.GetCollectionList(c.AccessToken)
+}
+
+// collectionKey расшифрованный ключ коллекции.
+func (a *App) collectionKey(accessToken string, col smodel.Collection) ([]byte, error) {
+	_, private, err := a.userKeys(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := crypt.OpenX25519(crypt.DecodeBase64(col.Key), private)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errCollectionKey, err)
+	}
+
+	return key, nil
+}
+
+// GetCollectionItems записи коллекции с расшифрованными полями.
+func (a *App) GetCollectionItems(col smodel.Collection) (items []collectionItem, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return items, err
+	}
+
+	key, err := a.collectionKey(c.AccessToken, col)
+	if err != nil {
+		return items, err
+	}
+
+	list, err := a.HTTPService.GetCollectionItemList(c.AccessToken, col.ID)
+	if err != nil {
+		return items, err
+	}
+
+	for _, v := range list {
+		data, err := crypt.Decrypt(crypt.DecodeBase64(v.Data), key)
+		if err != nil {
+			logger.Error("GetCollectionItems - decrypt: ", err, v.ID)
+			continue
+		}
+
+		values := make(map[string]any)
+		if err = json.Unmarshal(data, &values); err != nil {
+			logger.Error("GetCollectionItems - decode: ", err, v.ID)
+			continue
+		}
+
+		items = append(items, collectionItem{item: v, values: values})
+	}
+
+	return items, nil
+}
+
+// SaveCollectionItem шифрует поля записи ключом коллекции и сохраняет запись; item.item.ID == 0 - новая запись.
+func (a *App) SaveCollectionItem(col smodel.Collection, item collectionItem) (id int, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return id, err
+	}
+
+	key, err := a.collectionKey(c.AccessToken, col)
+	if err != nil {
+		return id, err
+	}
+
+	payload, err := json.Marshal(item.values)
+	if err != nil {
+		return id, err
+	}
+
+	data, err := crypt.Encrypt(payload, key)
+	if err != nil {
+		return id, err
+	}
+
+	item.item.CollectionID = col.ID
+	item.item.Data = crypt.EncodeBase64(data)
+
+	return a.HTTPService.SaveCollectionItem(c.AccessToken, item.item)
+}
+
+func (a *App) DeleteCollectionItem(colID, itemID int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	return a.HTTPService.DeleteCollectionItem(c.AccessToken, itemID, colID)
+}
+
+// collectionBar переключатель между личным хранилищем и коллекциями организаций. Коллекции загружаются
+// с сервера в фоне, чтобы главная страница открывалась и без связи с сервером.
+func (a *App) collectionBar(currentType func() ui.DataType) fyne.CanvasObject {
+	var cols []smodel.Collection
+
+	sel := widget.NewSelect([]string{personalVault}, nil)
+	sel.SetSelected(personalVault)
+	sel.OnChanged = func(s string) {
+		for _, col := range cols {
+			if col.Name == s {
+				a.pageCollection(col, currentType())
+			}
+		}
+	}
+
+	go func() {
+		list, err := a.GetCollections()
+		if err != nil {
+			logger.Error("collectionBar:", err)
+			return
+		}
+
+		cols = list
+		options := []string{personalVault}
+		for _, col := range cols {
+			options = append(options, col.Name)
+		}
+		sel.Options = options
+		sel.Refresh()
+	}()
+
+	return container.NewHBox(widget.NewLabel("Хранилище"), sel)
+}
+
+// pageOrganizations организации пользователя: создание, приглашения и переход к организации.
+func (a *App) pageOrganizations(dataType ui.DataType) {
+	orgs, err := a.GetOrganizations()
+	if err != nil {
+		logger.Error(err)
+		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+	}
+
+	name := widget.NewEntry()
+	name.SetPlaceHolder("Название организации")
+	createBtn := widget.NewButtonWithIcon("Создать", theme.ContentAddIcon(), func() {
+		if name.Text == "" {
+			return
+		}
+
+		if _, err := a.CreateOrganization(name.Text); err != nil {
+			logger.Error("create organization:", err)
+			dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+			return
+		}
+
+		a.pageOrganizations(dataType)
+	})
+
+	c, err := a.GetUserConfig()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	list := container.NewVBox()
+	for _, org := range orgs {
+		org := org
+		label := widget.NewLabel(fmt.Sprintf("%s (%s)", org.Name, roleLabels[org.Role]))
+
+		if org.Status == smodel.MemberStatusInvited {
+			acceptBtn := widget.NewButtonWithIcon("Принять", theme.ConfirmIcon(), func() {
+				if err := a.AcceptInvite(org.ID); err != nil {
+					logger.Error("accept invite:", err)
+					dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+					return
+				}
+
+				a.pageOrganizations(dataType)
+			})
+			declineBtn := widget.NewButtonWithIcon("Отклонить", theme.CancelIcon(), func() {
+				if err := a.RemoveMember(org.ID, c.Login); err != nil {
+					logger.Error("decline invite:", err)
+					dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+					return
+				}
+
+				a.pageOrganizations(dataType)
+			})
+			list.Add(container.NewHBox(label, widget.NewLabel("приглашение"), layout.NewSpacer(), acceptBtn, declineBtn))
+
+			continue
+		}
+
+		openBtn := widget.NewButtonWithIcon("Открыть", theme.NavigateNextIcon(), func() {
+			a.pageOrganization(org, dataType)
+		})
+		list.Add(container.NewHBox(label, layout.NewSpacer(), openBtn))
+	}
+
+	a.window.SetContent(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageMain(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText("Организации", color.Black),
+		),
+		canvas.NewLine(color.Black),
+		container.NewBorder(nil, nil, nil, createBtn, name),
+		list,
+	))
+}
+
+// pageOrganization участники и коллекции организации. Приглашать и исключать участников, создавать
+// и удалять коллекции может администратор; окончательную проверку прав выполняет сервер.
+func (a *App) pageOrganization(org smodel.Organization, dataType ui.DataType) {
+	admin := smodel.RoleAtLeast(org.Role, smodel.RoleAdmin)
+
+	c, err := a.GetUserConfig()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	members, err := a.GetMembers(org.ID)
+	if err != nil {
+		logger.Error(err)
+		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+	}
+
+	cols, err := a.GetCollections()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	showErr := func(msg string, err error) bool {
+		if err == nil {
+			return false
+		}
+
+		logger.Error(msg, err)
+		if errors.Is(err, service.ErrStatusForbidden) {
+			dialog.ShowError(errors.New("недостаточно прав"), a.window)
+		} else {
+			dialog.ShowError(err, a.window)
+		}
+
+		return true
+	}
+
+	membersBox := container.NewVBox(widget.NewLabel("Участники"))
+	for _, m := range members {
+		m := m
+		row := container.NewHBox(widget.NewLabel(fmt.Sprintf("%s (%s)", m.Login, roleLabels[m.Role])))
+		if m.Status == smodel.MemberStatusInvited {
+			row.Add(widget.NewLabel("приглашен"))
+		}
+		row.Add(layout.NewSpacer())
+
+		if admin && m.Role != smodel.RoleOwner && m.Login != c.Login {
+			row.Add(widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				dialog.ShowConfirm("Исключить участника", fmt.Sprintf("Исключить %s из организации?", m.Login), func(b bool) {
+					if !b || showErr("remove member:", a.RemoveMember(org.ID, m.Login)) {
+						return
+					}
+
+					a.pageOrganization(org, dataType)
+				}, a.window)
+			}))
+		}
+		membersBox.Add(row)
+	}
+
+	if admin {
+		membersBox.Add(container.NewHBox(widget.NewButtonWithIcon("Пригласить", theme.MailSendIcon(), func() {
+			login := widget.NewEntry()
+			roles := []string{roleLabels[smodel.RoleReadOnly], roleLabels[smodel.RoleMember], roleLabels[smodel.RoleAdmin]}
+			role := widget.NewSelect(roles, nil)
+			role.SetSelected(roleLabels[smodel.RoleMember])
+
+			items := []*widget.FormItem{
+				widget.NewFormItem("Логин", login),
+				widget.NewFormItem("Роль", role),
+			}
+
+			dialog.ShowForm("Пригласить участника", "Пригласить", "Отмена", items, func(b bool) {
+				if !b || login.Text == "" {
+					return
+				}
+
+				for k, v := range roleLabels {
+					if v == role.Selected && showErr("invite member:", a.InviteMember(org.ID, login.Text, k)) {
+						return
+					}
+				}
+
+				a.pageOrganization(org, dataType)
+			}, a.window)
+		})))
+	}
+
+	colsBox := container.NewVBox(canvas.NewLine(color.Black), widget.NewLabel("Коллекции"))
+	for _, col := range cols {
+		if col.OrgID != org.ID {
+			continue
+		}
+
+		col := col
+		row := container.NewHBox(widget.NewLabel(col.Name), layout.NewSpacer(),
+			widget.NewButtonWithIcon("Открыть", theme.NavigateNextIcon(), func() {
+				a.pageCollection(col, dataType)
+			}))
+
+		if admin {
+			row.Add(widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				dialog.ShowConfirm("Удалить коллекцию", fmt.Sprintf("Удалить коллекцию %s со всеми записями?", col.Name), func(b bool) {
+					if !b || showErr("delete collection:", a.DeleteCollection(col.ID)) {
+						return
+					}
+
+					a.pageOrganization(org, dataType)
+				}, a.window)
+			}))
+		}
+		colsBox.Add(row)
+	}
+
+	if admin {
+		colName := widget.NewEntry()
+		colName.SetPlaceHolder("Название коллекции")
+		colsBox.Add(container.NewBorder(nil, nil, nil, widget.NewButtonWithIcon("Создать", theme.ContentAddIcon(), func() {
+			if colName.Text == "" {
+				return
+			}
+
+			if _, err := a.CreateCollection(org.ID, colName.Text); showErr("create collection:", err) {
+				return
+			}
+
+			a.pageOrganization(org, dataType)
+		}), colName))
+	}
+
+	var leaveBtn *widget.Button
+	if org.Role == smodel.RoleOwner {
+		leaveBtn = widget.NewButtonWithIcon("Удалить организацию", theme.DeleteIcon(), func() {
+			dialog.ShowConfirm("Удалить организацию", fmt.Sprintf("Удалить организацию %s со всеми коллекциями?", org.Name), func(b bool) {
+				if !b || showErr("delete organization:", a.DeleteOrganization(org.ID)) {
+					return
+				}
+
+				a.pageOrganizations(dataType)
+			}, a.window)
+		})
+	} else {
+		leaveBtn = widget.NewButtonWithIcon("Покинуть организацию", theme.LogoutIcon(), func() {
+			dialog.ShowConfirm("Покинуть организацию", fmt.Sprintf("Покинуть организацию %s?", org.Name), func(b bool) {
+				if !b || showErr("leave organization:", a.RemoveMember(org.ID, c.Login)) {
+					return
+				}
+
+				a.pageOrganizations(dataType)
+			}, a.window)
+		})
+	}
+
+	a.window.SetContent(container.NewVScroll(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageOrganizations(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText(fmt.Sprintf("%s (%s)", org.Name, roleLabels[org.Role]), color.Black),
+		),
+		canvas.NewLine(color.Black),
+		membersBox,
+		colsBox,
+		canvas.NewLine(color.Black),
+		container.NewHBox(leaveBtn),
+	)))
+}
+
+// pageCollection записи коллекции организации. С ролью read-only записи доступны только для чтения.
+func (a *App) pageCollection(col smodel.Collection, dataType ui.DataType) {
+	writable := smodel.RoleAtLeast(col.Role, smodel.RoleMember)
+
+	items, err := a.GetCollectionItems(col)
+	if err != nil {
+		logger.Error(err)
+		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+	}
+
+	form := container.NewVBox()
+
+	list := widget.NewList(
+		func() int {
+			return len(items)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Запись")
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			co.(*widget.Label).SetText(items[lii].title())
+		},
+	)
+	list.OnSelected = func(lii widget.ListItemID) {
+		form.Objects = []fyne.CanvasObject{a.collectionItemForm(col, items[lii], writable, dataType)}
+		form.Refresh()
+	}
+
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(100, 150))
+
+	addBox := container.NewHBox()
+	if writable {
+		itemTypes := make([]string, 0, len(collectionFields))
+		for t := range collectionFields {
+			itemTypes = append(itemTypes, t)
+		}
+		sort.Strings(itemTypes)
+
+		for _, t := range itemTypes {
+			t := t
+			addBox.Add(widget.NewButtonWithIcon(collectionTypeLabels[t], theme.ContentAddIcon(), func() {
+				item := collectionItem{item: smodel.CollectionItem{ItemType: t}, values: make(map[string]any)}
+				form.Objects = []fyne.CanvasObject{a.collectionItemForm(col, item, writable, dataType)}
+				form.Refresh()
+			}))
+		}
+	}
+
+	a.window.SetContent(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageMain(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText(fmt.Sprintf("%s (%s)", col.Name, roleLabels[col.Role]), color.Black),
+		),
+		canvas.NewLine(color.Black),
+		addBox,
+		scroll,
+		form,
+	))
+}
+
+// collectionItemForm поля записи коллекции: поля типа записи из collectionFields и прочие строковые поля.
+func (a *App) collectionItemForm(col smodel.Collection, item collectionItem, writable bool, dataType ui.DataType) fyne.CanvasObject {
+	keys := append([]string{}, collectionFields[item.item.ItemType]...)
+	known := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		known[k] = true
+	}
+
+	var extra []string
+	for k, v := range item.values {
+		if _, ok := v.(string); ok && !known[k] {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+	keys = append(keys, extra...)
+
+	form := widget.NewForm()
+	entries := make(map[string]*widget.Entry, len(keys))
+	for _, k := range keys {
+		var entry *widget.Entry
+		switch {
+		case shareSecretKeys[k]:
+			entry = widget.NewPasswordEntry()
+		case k == "text" || k == "meta":
+			entry = widget.NewMultiLineEntry()
+		default:
+			entry = widget.NewEntry()
+		}
+
+		v, _ := item.values[k].(string)
+		entry.SetText(v)
+		if !writable {
+			entry.Disable()
+		}
+		entries[k] = entry
+
+		label, ok := shareLabels[k]
+		if !ok {
+			label = k
+		}
+		form.Append(label, entry)
+	}
+
+	if !writable {
+		return form
+	}
+
+	form.SubmitText = "Сохранить"
+	form.OnSubmit = func() {
+		for k, e := range entries {
+			item.values[k] = e.Text
+		}
+
+		if _, err := a.SaveCollectionItem(col, item); err != nil {
+			logger.Error("save collection item:", err)
+			dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+			return
+		}
+
+		a.pageCollection(col, dataType)
+	}
+
+	if item.item.ID == 0 {
+		return form
+	}
+
+	deleteBtn := widget.NewButtonWithIcon("Удалить", theme.DeleteIcon(), func() {
+		dialog.ShowConfirm("Удалить запись", "Удалить запись из коллекции?", func(b bool) {
+			if !b {
+				return
+			}
+
+			if err := a.DeleteCollectionItem(col.ID, item.item.ID); err != nil {
+				logger.Error("delete collection item:", err)
+				dialog.ShowError(errors.New("ошибка при удалении записи"), a.window)
+
+				return
+			}
+
+			a.pageCollection(col, dataType)
+		}, a.window)
+	})
+
+	return container.NewVBox(form, container.NewHBox(deleteBtn))
+}
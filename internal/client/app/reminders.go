@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// reminderPeriod за сколько до окончания срока действия документа показывается напоминание.
+const reminderPeriod = 30 * 24 * time.Hour
+
+// reminder напоминание о записи, срок которой истек или скоро истекает.
+type reminder struct {
+	localID  int
+	dataType ui.DataType
+	title    string
+	due      time.Time
+}
+
+// expired срок истек: запись действует до конца дня due.
+func (r reminder) expired(now time.Time) bool {
+	return !now.Before(r.due.AddDate(0, 0, 1))
+}
+
+func (r reminder) String(now time.Time) string {
+	if r.expired(now) {
+		return fmt.Sprintf("%s: срок действия истек %s", r.title, r.due.Format(fieldDateLayout))
+	}
+
+	return fmt.Sprintf("%s: действует до %s", r.title, r.due.Format(fieldDateLayout))
+}
+
+// identityReminders напоминания о документах, срок действия которых истек или истекает в течение reminderPeriod.
+// Напоминания упорядочены по сроку.
+func identityReminders(docs []model.DataIdentity, now time.Time) []reminder {
+	var res []reminder
+	for _, v := range docs {
+		if v.ExpiryDate == "" {
+			continue
+		}
+
+		due, err := time.ParseInLocation(fieldDateLayout, v.ExpiryDate, now.Location())
+		if err != nil {
+			continue
+		}
+
+		if due.Sub(now) > reminderPeriod {
+			continue
+		}
+
+		res = append(res, reminder{localID: v.LocalID, dataType: ui.TypeIdentity, title: v.Title, due: due})
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].due.Before(res[j].due)
+	})
+
+	return res
+}
+
+// reminderBar напоминания на главной странице; переход по нажатию открывает запись.
+func (a *App) reminderBar() fyne.CanvasObject {
+	docs, err := a.GetAllIdentities()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	now := time.Now()
+	reminders := identityReminders(docs, now)
+
+	box := container.NewVBox()
+	for _, r := range reminders {
+		r := r
+		btn := widget.NewButtonWithIcon(r.String(now), theme.WarningIcon(), func() {
+			a.pageEdit(r.localID, r.dataType)
+		})
+		btn.Alignment = widget.ButtonAlignLeading
+		box.Add(btn)
+	}
+
+	if len(reminders) == 0 {
+		box.Hide()
+	}
+
+	return box
+}
@@ -0,0 +1,222 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/color"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/service"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// secretTTLs сроки действия одноразовой ссылки, предлагаемые в форме, в порядке отображения.
+var secretTTLs = []struct {
+	label string
+	ttl   time.Duration
+}{
+	{label: "1 час", ttl: time.Hour},
+	{label: "1 день", ttl: 24 * time.Hour},
+	{label: "7 дней", ttl: smodel.MaxSecretTTL},
+}
+
+var (
+	errSecretLink     = errors.New("некорректная ссылка на секрет")
+	errSecretNotFound = errors.New("секрет не найден: он уже просмотрен или истек срок его действия")
+)
+
+// CreateSecretLink создает одноразовую ссылку на text, доступную maxViews просмотров в течение ttl.
+// Текст шифруется случайным ключом, который передается только во фрагменте ссылки; сервер ключа не видит.
+func (a *App) CreateSecretLink(text string, maxViews int, ttl time.Duration) (link string, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return link, err
+	}
+
+	key := make([]byte, shareKeySize)
+	if _, err = rand.Read(key); err != nil {
+		return link, err
+	}
+
+	data, err := crypt.Encrypt([]byte(text), key)
+	if err != nil {
+		return link, err
+	}
+
+	secret, err := a.HTTPService.CreateSecret(c.AccessToken, smodel.Secret{
+		Data:      crypt.EncodeBase64(data),
+		MaxViews:  maxViews,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return link, err
+	}
+
+	return a.HTTPService.SecretLink(secret.ID, base64.RawURLEncoding.EncodeToString(key)), nil
+}
+
+// OpenSecretLink открывает одноразовую ссылку и расшифровывает секрет; просмотр засчитывается.
+func (a *App) OpenSecretLink(link string) (text string, err error) {
+	u, err := url.Parse(link)
+	if err != nil || u.Fragment == "" || path.Dir(u.Path) != "/s" {
+		return text, errSecretLink
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(u.Fragment)
+	if err != nil {
+		return text, errSecretLink
+	}
+
+	secret, err := a.HTTPService.OpenSecret(path.Base(u.Path))
+	if errors.Is(err, service.ErrStatusNotFound) {
+		return text, errSecretNotFound
+	}
+
+	if err != nil {
+		return text, err
+	}
+
+	data, err := crypt.Decrypt(crypt.DecodeBase64(secret.Data), key)
+	if err != nil {
+		return text, errSecretLink
+	}
+
+	return string(data), nil
+}
+
+// GetSecrets действующие одноразовые секреты пользователя.
+func (a *App) GetSecrets() (secrets []smodel.Secret, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return secrets, err
+	}
+
+	return a.HTTPService.GetSecretList(c.AccessToken)
+}
+
+func (a *App) DeleteSecret(secretID string) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	return a.HTTPService.DeleteSecret(c.AccessToken, secretID)
+}
+
+// pageSecrets одноразовые ссылки: создание, список действующих ссылок и просмотр полученной ссылки.
+func (a *App) pageSecrets(dataType ui.DataType) {
+	secrets, err := a.GetSecrets()
+	if err != nil {
+		logger.Error(err)
+		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+	}
+
+	text := widget.NewMultiLineEntry()
+	text.SetPlaceHolder("Текст секрета, например пароль")
+
+	views := make([]string, 0, smodel.MaxSecretViews)
+	for i := 1; i <= smodel.MaxSecretViews; i++ {
+		views = append(views, strconv.Itoa(i))
+	}
+	viewsSelect := widget.NewSelect(views, nil)
+	viewsSelect.SetSelectedIndex(0)
+
+	ttls := make([]string, 0, len(secretTTLs))
+	for _, v := range secretTTLs {
+		ttls = append(ttls, v.label)
+	}
+	ttlSelect := widget.NewSelect(ttls, nil)
+	ttlSelect.SetSelectedIndex(1)
+
+	createBtn := widget.NewButtonWithIcon("Создать ссылку", theme.MailSendIcon(), func() {
+		if text.Text == "" {
+			return
+		}
+
+		maxViews, _ := strconv.Atoi(viewsSelect.Selected)
+		link, err := a.CreateSecretLink(text.Text, maxViews, secretTTLs[ttlSelect.SelectedIndex()].ttl)
+		if err != nil {
+			logger.Error("create secret:", err)
+			dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+			return
+		}
+
+		a.pageSecrets(dataType)
+
+		linkEntry := widget.NewEntry()
+		linkEntry.SetText(link)
+		copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+			a.window.Clipboard().SetContent(link)
+		})
+		dialog.ShowCustom("Ссылка создана", "Закрыть", container.NewVBox(
+			widget.NewLabel("Ссылка показывается один раз: ключ расшифровки не сохраняется."),
+			container.NewBorder(nil, nil, nil, copyBtn, linkEntry),
+		), a.window)
+	})
+
+	linkEntry := widget.NewEntry()
+	linkEntry.SetPlaceHolder("Полученная ссылка")
+	openBtn := widget.NewButtonWithIcon("Открыть", theme.VisibilityIcon(), func() {
+		plain, err := a.OpenSecretLink(linkEntry.Text)
+		if err != nil {
+			logger.Error("open secret:", err)
+			dialog.ShowError(err, a.window)
+
+			return
+		}
+
+		out := widget.NewMultiLineEntry()
+		out.SetText(plain)
+		dialog.ShowCustom("Секрет", "Закрыть", out, a.window)
+		linkEntry.SetText("")
+	})
+
+	list := container.NewVBox()
+	for _, secret := range secrets {
+		secret := secret
+		label := widget.NewLabel(fmt.Sprintf("%s…: просмотров %d из %d, до %s", secret.ID[:6], secret.Views, secret.MaxViews,
+			secret.ExpiresAt.Local().Format(dateTimeLayout)))
+		deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			if err := a.DeleteSecret(secret.ID); err != nil {
+				logger.Error("delete secret:", err)
+				dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+				return
+			}
+
+			a.pageSecrets(dataType)
+		})
+		list.Add(container.NewHBox(label, layout.NewSpacer(), deleteBtn))
+	}
+
+	a.window.SetContent(container.NewVScroll(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageMain(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText("Одноразовые ссылки", color.Black),
+		),
+		canvas.NewLine(color.Black),
+		text,
+		container.NewHBox(widget.NewLabel("Просмотров:"), viewsSelect, widget.NewLabel("Срок:"), ttlSelect, layout.NewSpacer(), createBtn),
+		list,
+		canvas.NewLine(color.Black),
+		container.NewBorder(nil, nil, nil, openBtn, linkEntry),
+	)))
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// byteUnits единицы размера для formatBytes.
+var byteUnits = []string{"Б", "КБ", "МБ", "ГБ", "ТБ"}
+
+// GetUsage использование хранилища на сервере и квоты пользователя.
+func (a *App) GetUsage() (usage smodel.Usage, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return usage, err
+	}
+
+	return a.HTTPService.GetUsage(c.AccessToken)
+}
+
+// formatBytes размер в байтах в удобном для чтения виде: 512 Б, 1.5 МБ.
+func formatBytes(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d %s", n, byteUnits[0])
+	}
+
+	size := float64(n)
+	unit := 0
+	for size >= 1024 && unit < len(byteUnits)-1 {
+		size /= 1024
+		unit++
+	}
+
+	return fmt.Sprintf("%.1f %s", size, byteUnits[unit])
+}
+
+// usageBar полоса использования квоты с подписью "used из limit"; без ограничения - только подпись.
+func usageBar(used, limit float64, format func(float64) string) fyne.CanvasObject {
+	if limit <= 0 {
+		return widget.NewLabel(format(used) + ", без ограничения")
+	}
+
+	bar := widget.NewProgressBar()
+	bar.Max = limit
+	bar.SetValue(used)
+	bar.TextFormatter = func() string {
+		return format(used) + " из " + format(limit)
+	}
+
+	return bar
+}
+
+// pageSettings настройки: учетная запись, сервер и использование хранилища с квотами.
+func (a *App) pageSettings(dataType ui.DataType) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	account := widget.NewForm(
+		widget.NewFormItem("Логин", widget.NewLabel(c.Login)),
+		widget.NewFormItem("Сервер", widget.NewLabel(a.cfg.ServerProtocol+"://"+a.cfg.ServerAddress)),
+	)
+
+	quotas := container.NewVBox()
+	usage, err := a.GetUsage()
+	if err != nil {
+		logger.Error("get usage:", err)
+		dialog.ShowError(errors.New("ошибка запроса данных с сервера"), a.window)
+	} else {
+		bytes := func(v float64) string { return formatBytes(int64(v)) }
+		items := func(v float64) string { return strconv.Itoa(int(v)) }
+
+		fileSize := "без ограничения"
+		if usage.MaxFileSize > 0 {
+			fileSize = "не более " + formatBytes(usage.MaxFileSize)
+		}
+
+		quotas.Add(widget.NewForm(
+			widget.NewFormItem("Объем", usageBar(float64(usage.Bytes), float64(usage.MaxBytes), bytes)),
+			widget.NewFormItem("Записи", usageBar(float64(usage.Items), float64(usage.MaxItems), items)),
+			widget.NewFormItem("Размер файла", widget.NewLabel(fileSize)),
+		))
+	}
+
+	a.window.SetContent(container.NewVScroll(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageMain(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText("Настройки", color.Black),
+		),
+		canvas.NewLine(color.Black),
+		account,
+		widget.NewLabelWithStyle("Хранилище на сервере", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		quotas,
+	)))
+}
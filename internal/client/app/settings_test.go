@@ -0,0 +1,26 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_formatBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int64
+		want string
+	}{
+		{name: "zero", n: 0, want: "0 Б"},
+		{name: "bytes", n: 1023, want: "1023 Б"},
+		{name: "kilobytes", n: 1536, want: "1.5 КБ"},
+		{name: "megabytes", n: 100 << 20, want: "100.0 МБ"},
+		{name: "gigabytes", n: 1 << 30, want: "1.0 ГБ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatBytes(tt.n))
+		})
+	}
+}
@@ -0,0 +1,692 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"sort"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/service"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// shareKeySize размер ключа, которым шифруется открытая запись.
+const shareKeySize = 32
+
+// shareItemTypes типы записей, которые можно открыть другим пользователям. Файлы и записи по шаблонам
+// не передаются: содержимое файла и схема шаблона остаются у владельца.
+var shareItemTypes = map[ui.DataType]bool{
+	ui.TypeCard:     true,
+	ui.TypeCred:     true,
+	ui.TypeText:     true,
+	ui.TypeSSH:      true,
+	ui.TypeIdentity: true,
+}
+
+// shareHiddenKeys поля записи, которые не передаются получателю и не принимаются от него:
+// идентификаторы, папка и метки владельца. Время изменения передается в самом доступе.
+var shareHiddenKeys = []string{"LocalID", "id", "folder_id", "tag_ids", "updated_at"}
+
+// shareLabels подписи полей открытой записи.
+var shareLabels = map[string]string{
+	"title":       "Название",
+	"number":      "Номер",
+	"date":        "Срок",
+	"cvv":         "CVV",
+	"username":    "Логин",
+	"password":    "Пароль",
+	"totp":        "TOTP",
+	"text":        "Текст",
+	"meta":        "Описание",
+	"private_key": "Закрытый ключ",
+	"public_key":  "Открытый ключ",
+	"fingerprint": "Отпечаток",
+	"passphrase":  "Пароль ключа",
+	"kind":        "Вид",
+	"full_name":   "ФИО",
+	"country":     "Страна",
+	"issue_date":  "Дата выдачи",
+	"expiry_date": "Действует до",
+	"address":     "Адрес",
+}
+
+// shareSecretKeys поля открытой записи, значения которых скрыты в форме.
+var shareSecretKeys = map[string]bool{
+	"cvv":         true,
+	"password":    true,
+	"totp":        true,
+	"private_key": true,
+	"passphrase":  true,
+}
+
+var (
+	errShareType     = errors.New("записи этого типа нельзя открыть другим пользователям")
+	errShareNotSync  = errors.New("запись еще не синхронизирована с сервером")
+	errShareNoTarget = errors.New("пользователь не найден или еще не синхронизировал хранилище")
+)
+
+// sharedItem запись, открытая пользователю: расшифрованные поля и ключ записи для сохранения изменений.
+type sharedItem struct {
+	share  smodel.Share
+	key    []byte
+	values map[string]any
+}
+
+// title название открытой записи со владельцем.
+func (s sharedItem) title() string {
+	title, _ := s.values["title"].(string)
+
+	return fmt.Sprintf("%s: %s", s.share.Owner, title)
+}
+
+// dataTypeByItemType тип записи клиента по типу записи сервера.
+func dataTypeByItemType(itemType string) (ui.DataType, bool) {
+	for t, v := range itemTypes {
+		if v == itemType {
+			return t, true
+		}
+	}
+
+	return 0, false
+}
+
+// cleanPayload убирает из записи поля shareHiddenKeys.
+func cleanPayload(data []byte) ([]byte, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	for _, k := range shareHiddenKeys {
+		delete(m, k)
+	}
+
+	return json.Marshal(m)
+}
+
+// userKeys пара ключей пользователя для обмена записями. При первом обращении ключи создаются;
+// закрытый ключ хранится на сервере зашифрованным ключом хранилища.
+func (a *App) userKeys(accessToken string) (public, private []byte, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	sKey := crypt.DecodeBase64(c.SignKey)
+
+	keys, err := a.HTTPService.GetUserKeys(accessToken)
+	if errors.Is(err, service.ErrStatusNotFound) {
+		public, private, err = crypt.GenerateX25519()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		encPrivate, err := crypt.Encrypt(private, sKey)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = a.HTTPService.SaveUserKeys(accessToken, smodel.UserKeys{
+			PublicKey:  crypt.EncodeBase64(public),
+			PrivateKey: crypt.EncodeBase64(encPrivate),
+			UpdatedAt:  time.Now(),
+		})
+
+		return public, private, err
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	private, err = crypt.Decrypt(crypt.DecodeBase64(keys.PrivateKey), sKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return crypt.DecodeBase64(keys.PublicKey), private, nil
+}
+
+// itemPayload расшифрованная запись для передачи по доступу, её идентификатор на сервере и время изменения.
+func (a *App) itemPayload(dataType ui.DataType, localID int) (payload []byte, extID int, updatedAt time.Time, err error) {
+	var item any
+	switch dataType {
+	case ui.TypeCard:
+		item, err = a.GetCard(localID)
+	case ui.TypeCred:
+		item, err = a.GetCred(localID)
+	case ui.TypeText:
+		item, err = a.GetText(localID)
+	case ui.TypeSSH:
+		item, err = a.GetSSHKey(localID)
+	case ui.TypeIdentity:
+		item, err = a.GetIdentity(localID)
+	default:
+		err = errShareType
+	}
+
+	if err != nil {
+		return nil, 0, updatedAt, err
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, 0, updatedAt, err
+	}
+
+	var meta struct {
+		ID        int       `json:"id"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	if err = json.Unmarshal(data, &meta); err != nil {
+		return nil, 0, updatedAt, err
+	}
+
+	payload, err = cleanPayload(data)
+
+	return payload, meta.ID, meta.UpdatedAt, err
+}
+
+// applyPayload заменяет поля записи полями из доступа, измененного получателем.
+func applyPayload[T any](localID int, payload []byte, updatedAt time.Time, get func(int) (T, error), set func(*T, time.Time), add func(*T, bool) error) error {
+	item, err := get(localID)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(payload, &item); err != nil {
+		return err
+	}
+	set(&item, updatedAt)
+
+	return add(&item, false)
+}
+
+// applySharedChanges применяет к записи владельца изменения получателя.
+func (a *App) applySharedChanges(dataType ui.DataType, localID int, payload []byte, updatedAt time.Time) (err error) {
+	payload, err = cleanPayload(payload)
+	if err != nil {
+		return err
+	}
+
+	switch dataType {
+	case ui.TypeCard:
+		return applyPayload(localID, payload, updatedAt, a.GetCard, func(v *model.DataCard, t time.Time) { v.UpdatedAt = t }, a.AddCard)
+	case ui.TypeCred:
+		return applyPayload(localID, payload, updatedAt, a.GetCred, func(v *model.DataCred, t time.Time) { v.UpdatedAt = t }, a.AddCred)
+	case ui.TypeText:
+		return applyPayload(localID, payload, updatedAt, a.GetText, func(v *model.DataText, t time.Time) { v.UpdatedAt = t }, a.AddText)
+	case ui.TypeSSH:
+		return applyPayload(localID, payload, updatedAt, a.GetSSHKey, func(v *model.DataSSHKey, t time.Time) { v.UpdatedAt = t }, a.AddSSHKey)
+	case ui.TypeIdentity:
+		return applyPayload(localID, payload, updatedAt, a.GetIdentity, func(v *model.DataIdentity, t time.Time) { v.UpdatedAt = t }, a.AddIdentity)
+	}
+
+	return errShareType
+}
+
+// ShareItem открывает запись пользователю login с доступом access (smodel.ShareAccess*) или меняет доступ.
+// Запись шифруется случайным ключом, ключ - открытыми ключами получателя и владельца; сервер данных не видит.
+func (a *App) ShareItem(dataType ui.DataType, localID int, login, access string) (err error) {
+	if !shareItemTypes[dataType] {
+		return errShareType
+	}
+
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	payload, extID, updatedAt, err := a.itemPayload(dataType, localID)
+	if err != nil {
+		return err
+	}
+
+	if extID == 0 {
+		return errShareNotSync
+	}
+
+	ownerPublic, _, err := a.userKeys(c.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	recipient, err := a.HTTPService.GetPublicKey(c.AccessToken, login)
+	if errors.Is(err, service.ErrStatusNotFound) {
+		return errShareNoTarget
+	}
+
+	if err != nil {
+		return err
+	}
+
+	itemKey := make([]byte, shareKeySize)
+	if _, err = rand.Read(itemKey); err != nil {
+		return err
+	}
+
+	data, err := crypt.Encrypt(payload, itemKey)
+	if err != nil {
+		return err
+	}
+
+	recipientKey, err := crypt.SealX25519(itemKey, crypt.DecodeBase64(recipient.PublicKey))
+	if err != nil {
+		return err
+	}
+
+	ownerKey, err := crypt.SealX25519(itemKey, ownerPublic)
+	if err != nil {
+		return err
+	}
+
+	share := smodel.Share{
+		Recipient: login,
+		ItemType:  itemTypes[dataType],
+		ItemID:    extID,
+		Access:    access,
+		ItemKey:   crypt.EncodeBase64(recipientKey),
+		OwnerKey:  crypt.EncodeBase64(ownerKey),
+		Data:      crypt.EncodeBase64(data),
+		UpdatedAt: updatedAt,
+	}
+
+	// повторное открытие тому же получателю меняет существующий доступ
+	shares, err := a.GetItemShares(dataType, localID)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range shares {
+		if v.Recipient == login {
+			share.ID = v.ID
+		}
+	}
+
+	_, err = a.HTTPService.AddShare(c.AccessToken, share)
+
+	return err
+}
+
+// GetItemShares доступы других пользователей к записи.
+func (a *App) GetItemShares(dataType ui.DataType, localID int) (shares []smodel.Share, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return shares, err
+	}
+
+	ids, err := a.itemExternalIDs()
+	if err != nil {
+		return shares, err
+	}
+
+	extID := ids[itemTypes[dataType]][localID]
+	if extID == 0 {
+		return shares, nil
+	}
+
+	all, err := a.HTTPService.GetShareList(c.AccessToken)
+	if err != nil {
+		return shares, err
+	}
+
+	for _, v := range all {
+		if v.ItemType == itemTypes[dataType] && v.ItemID == extID {
+			shares = append(shares, v)
+		}
+	}
+
+	return shares, nil
+}
+
+// RevokeShare закрывает доступ к записи.
+func (a *App) RevokeShare(shareID int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	return a.HTTPService.DeleteShare(c.AccessToken, shareID)
+}
+
+// GetIncomingShares записи, открытые пользователю, с расшифрованными полями.
+func (a *App) GetIncomingShares() (items []sharedItem, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return items, err
+	}
+
+	_, private, err := a.userKeys(c.AccessToken)
+	if err != nil {
+		return items, err
+	}
+
+	shares, err := a.HTTPService.GetIncomingShareList(c.AccessToken)
+	if err != nil {
+		return items, err
+	}
+
+	for _, v := range shares {
+		key, err := crypt.OpenX25519(crypt.DecodeBase64(v.ItemKey), private)
+		if err != nil {
+			logger.Error("GetIncomingShares - open key: ", err, v.ID)
+			continue
+		}
+
+		data, err := crypt.Decrypt(crypt.DecodeBase64(v.Data), key)
+		if err != nil {
+			logger.Error("GetIncomingShares - decrypt: ", err, v.ID)
+			continue
+		}
+
+		values := make(map[string]any)
+		if err = json.Unmarshal(data, &values); err != nil {
+			logger.Error("GetIncomingShares - decode: ", err, v.ID)
+			continue
+		}
+
+		items = append(items, sharedItem{share: v, key: key, values: values})
+	}
+
+	return items, nil
+}
+
+// UpdateIncomingShare сохраняет изменения открытой пользователю записи; владелец получит их при синхронизации.
+func (a *App) UpdateIncomingShare(item sharedItem) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(item.values)
+	if err != nil {
+		return err
+	}
+
+	payload, err = cleanPayload(payload)
+	if err != nil {
+		return err
+	}
+
+	data, err := crypt.Encrypt(payload, item.key)
+	if err != nil {
+		return err
+	}
+
+	return a.HTTPService.UpdateIncomingShare(c.AccessToken, smodel.Share{
+		ID:        item.share.ID,
+		Data:      crypt.EncodeBase64(data),
+		UpdatedAt: time.Now(),
+	})
+}
+
+// SyncShares синхронизирует записи, открытые пользователем, после самих записей: изменения получателя
+// применяются к записи владельца, изменения владельца заново шифруются в доступ.
+// При первой синхронизации создаются ключи пользователя, чтобы ему могли открывать записи.
+func (a *App) SyncShares(accessToken string) (err error) {
+	_, private, err := a.userKeys(accessToken)
+	if err != nil {
+		return err
+	}
+
+	shares, err := a.HTTPService.GetShareList(accessToken)
+	if err != nil {
+		return err
+	}
+
+	toExternal, err := a.itemExternalIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range shares {
+		dataType, ok := dataTypeByItemType(v.ItemType)
+		if !ok {
+			continue
+		}
+
+		localID := 0
+		for l, extID := range toExternal[v.ItemType] {
+			if extID == v.ItemID {
+				localID = l
+			}
+		}
+
+		if localID == 0 {
+			continue
+		}
+
+		itemKey, err := crypt.OpenX25519(crypt.DecodeBase64(v.OwnerKey), private)
+		if err != nil {
+			logger.Error("SyncShares - open key: ", err, v.ID)
+			continue
+		}
+
+		payload, _, updatedAt, err := a.itemPayload(dataType, localID)
+		if err != nil {
+			logger.Error("SyncShares - item: ", err, v.ID)
+			continue
+		}
+
+		switch {
+		case v.UpdatedAt.Unix() > updatedAt.Unix():
+			data, err := crypt.Decrypt(crypt.DecodeBase64(v.Data), itemKey)
+			if err != nil {
+				logger.Error("SyncShares - decrypt: ", err, v.ID)
+				continue
+			}
+
+			if err = a.applySharedChanges(dataType, localID, data, v.UpdatedAt); err != nil {
+				logger.Error("SyncShares - apply: ", err, v.ID)
+			}
+		case updatedAt.Unix() > v.UpdatedAt.Unix():
+			data, err := crypt.Encrypt(payload, itemKey)
+			if err != nil {
+				logger.Error("SyncShares - encrypt: ", err, v.ID)
+				continue
+			}
+
+			v.Data = crypt.EncodeBase64(data)
+			v.UpdatedAt = updatedAt
+
+			if _, err = a.HTTPService.AddShare(accessToken, v); err != nil {
+				logger.Error("SyncShares - update: ", err, v.ID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// accessLabels подписи уровней доступа.
+var accessLabels = map[string]string{
+	smodel.ShareAccessRead:  "Чтение",
+	smodel.ShareAccessWrite: "Чтение и запись",
+}
+
+// sharesBox доступы других пользователей к записи на странице редактирования: список, отзыв и открытие.
+func (a *App) sharesBox(dataType ui.DataType, localID int) *fyne.Container {
+	box := container.NewVBox(canvas.NewLine(color.Black), widget.NewLabel("Доступ других пользователей"))
+
+	shares, err := a.GetItemShares(dataType, localID)
+	if err != nil {
+		logger.Error(err)
+		box.Add(widget.NewLabel("Список доступов недоступен без связи с сервером"))
+
+		return box
+	}
+
+	for _, v := range shares {
+		v := v
+
+		revokeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			dialog.ShowConfirm("Закрыть доступ", fmt.Sprintf("Закрыть доступ пользователю %s?", v.Recipient), func(b bool) {
+				if !b {
+					return
+				}
+
+				if err := a.RevokeShare(v.ID); err != nil {
+					logger.Error("revoke share:", err)
+					dialog.ShowError(errors.New("ошибка при закрытии доступа"), a.window)
+
+					return
+				}
+
+				a.pageEdit(localID, dataType)
+			}, a.window)
+		})
+
+		box.Add(container.NewHBox(widget.NewLabel(fmt.Sprintf("%s (%s)", v.Recipient, accessLabels[v.Access])), layout.NewSpacer(), revokeBtn))
+	}
+
+	shareBtn := widget.NewButtonWithIcon("Поделиться", theme.MailForwardIcon(), func() {
+		login := widget.NewEntry()
+		access := widget.NewRadioGroup([]string{accessLabels[smodel.ShareAccessRead], accessLabels[smodel.ShareAccessWrite]}, nil)
+		access.SetSelected(accessLabels[smodel.ShareAccessRead])
+
+		items := []*widget.FormItem{
+			widget.NewFormItem("Логин", login),
+			widget.NewFormItem("Доступ", access),
+		}
+
+		dialog.ShowForm("Поделиться записью", "Открыть", "Отмена", items, func(b bool) {
+			if !b || login.Text == "" {
+				return
+			}
+
+			level := smodel.ShareAccessRead
+			if access.Selected == accessLabels[smodel.ShareAccessWrite] {
+				level = smodel.ShareAccessWrite
+			}
+
+			if err := a.ShareItem(dataType, localID, login.Text, level); err != nil {
+				logger.Error("share item:", err)
+				dialog.ShowError(err, a.window)
+
+				return
+			}
+
+			a.pageEdit(localID, dataType)
+		}, a.window)
+	})
+
+	box.Add(container.NewHBox(shareBtn))
+
+	return box
+}
+
+// pageIncomingShares записи, открытые пользователю другими пользователями. Поля доступны для изменения,
+// если владелец открыл запись на запись.
+func (a *App) pageIncomingShares(dataType ui.DataType) {
+	items, err := a.GetIncomingShares()
+	if err != nil {
+		logger.Error(err)
+		dialog.ShowError(errors.New("ошибка запроса списка с сервера"), a.window)
+	}
+
+	form := container.NewVBox()
+
+	list := widget.NewList(
+		func() int {
+			return len(items)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Запись")
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			co.(*widget.Label).SetText(items[lii].title())
+		},
+	)
+	list.OnSelected = func(lii widget.ListItemID) {
+		form.Objects = []fyne.CanvasObject{a.sharedItemForm(items[lii], dataType)}
+		form.Refresh()
+	}
+
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(100, 150))
+
+	a.window.SetContent(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageMain(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText("Доступные мне", color.Black),
+		),
+		canvas.NewLine(color.Black),
+		scroll,
+		form,
+	))
+}
+
+// sharedItemForm поля открытой записи; поля, не являющиеся строками (доп. поля, адреса сайтов),
+// в форме не показываются, но сохраняются без изменений.
+func (a *App) sharedItemForm(item sharedItem, dataType ui.DataType) fyne.CanvasObject {
+	writable := item.share.Access == smodel.ShareAccessWrite
+
+	keys := make([]string, 0, len(item.values))
+	for k, v := range item.values {
+		if _, ok := v.(string); ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	form := widget.NewForm()
+	entries := make(map[string]*widget.Entry, len(keys))
+	for _, k := range keys {
+		var entry *widget.Entry
+		if shareSecretKeys[k] {
+			entry = widget.NewPasswordEntry()
+		} else {
+			entry = widget.NewEntry()
+		}
+		entry.SetText(item.values[k].(string))
+		if !writable {
+			entry.Disable()
+		}
+		entries[k] = entry
+
+		label, ok := shareLabels[k]
+		if !ok {
+			label = k
+		}
+		form.Append(label, entry)
+	}
+
+	if writable {
+		form.SubmitText = "Сохранить"
+		form.OnSubmit = func() {
+			for k, e := range entries {
+				item.values[k] = e.Text
+			}
+
+			if err := a.UpdateIncomingShare(item); err != nil {
+				logger.Error("update share:", err)
+				dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+				return
+			}
+
+			a.pageIncomingShares(dataType)
+		}
+	}
+
+	return container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Владелец: %s, доступ: %s", item.share.Owner, accessLabels[item.share.Access])),
+		form,
+	)
+}
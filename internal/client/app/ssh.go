@@ -0,0 +1,540 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/sshagent"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// sshConfirmTimeout время ожидания ответа на запрос подписи, после него подпись отклоняется.
+const sshConfirmTimeout = time.Minute
+
+func (a *App) AddSSHKey(key *model.DataSSHKey, encrypted bool) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	if !encrypted {
+		sKey := crypt.DecodeBase64(c.SignKey)
+		encPrivateKey, err := crypt.Encrypt([]byte(key.PrivateKey), sKey)
+		if err != nil {
+			return err
+		}
+
+		encMeta, err := crypt.Encrypt([]byte(key.Meta), sKey)
+		if err != nil {
+			return err
+		}
+
+		key.PrivateKey = crypt.EncodeBase64(encPrivateKey)
+		key.Meta = crypt.EncodeBase64(encMeta)
+
+		key.Passphrase, err = encryptOptional(key.Passphrase, sKey)
+		if err != nil {
+			return err
+		}
+
+		key.Fields, err = encryptFields(key.Fields, sKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	return a.db.AddSSHKey(key)
+}
+
+// decryptSSHKey расшифровывает запись SSH-ключа из локальной БД.
+func decryptSSHKey(key model.DataSSHKey, sKey []byte) (model.DataSSHKey, error) {
+	decPrivateKey, err := crypt.Decrypt(crypt.DecodeBase64(key.PrivateKey), sKey)
+	if err != nil {
+		return key, err
+	}
+
+	decMeta, err := crypt.Decrypt(crypt.DecodeBase64(key.Meta), sKey)
+	if err != nil {
+		return key, err
+	}
+
+	key.PrivateKey = string(decPrivateKey)
+	key.Meta = string(decMeta)
+
+	key.Passphrase, err = decryptOptional(key.Passphrase, sKey)
+	if err != nil {
+		return key, err
+	}
+
+	key.Fields, err = decryptFields(key.Fields, sKey)
+
+	return key, err
+}
+
+func (a *App) GetSSHKey(localID int) (key model.DataSSHKey, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return key, err
+	}
+
+	key, err = a.db.GetSSHKey(localID)
+	if err != nil {
+		return key, err
+	}
+
+	return decryptSSHKey(key, crypt.DecodeBase64(c.SignKey))
+}
+
+func (a *App) GetAllSSHKeys() (keys []model.DataSSHKey, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return keys, err
+	}
+	sKey := crypt.DecodeBase64(c.SignKey)
+
+	keysEnc, err := a.db.GetAllSSHKeys()
+	if err != nil {
+		return keys, err
+	}
+
+	for _, v := range keysEnc {
+		v, err = decryptSSHKey(v, sKey)
+		if err != nil {
+			return keys, err
+		}
+
+		keys = append(keys, v)
+	}
+
+	return keys, nil
+}
+
+func (a *App) DeleteSSHKey(localID int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	item, err := a.db.GetSSHKey(localID)
+	if err != nil {
+		return err
+	}
+
+	err = a.db.DeleteSSHKey(localID)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err := a.HTTPService.DeleteSSHKey(c.AccessToken, item.ExternalID)
+		if err != nil {
+			logger.Error("goroutine delete:", err)
+		}
+	}()
+
+	return nil
+}
+
+func (a *App) SyncSSHKeys(accessToken string) (err error) {
+	keysMap := make(map[int]*model.DataSSHKey)
+	getKeysMap := make(map[int]*model.DataSSHKey)
+
+	keys, err := a.db.GetAllSSHKeys()
+	if err != nil {
+		return err
+	}
+
+	for i := range keys {
+		keysMap[keys[i].ExternalID] = &keys[i]
+	}
+
+	getKeys, err := a.HTTPService.GetSSHKeyList(accessToken)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range getKeys {
+		getKeysMap[v.ExternalID] = v
+	}
+
+	refs, err := a.loadItemRefs()
+	if err != nil {
+		return err
+	}
+
+	// создаем записи в бд клиента
+	for _, v := range getKeys {
+		updateKey := *v
+		updateKey.LocalID = 0
+		if val, ok := keysMap[v.ExternalID]; ok {
+			// если дата на сервере новее обновим локальные данные
+			if val.UpdatedAt.Unix() >= v.UpdatedAt.Unix() {
+				continue
+			}
+			updateKey.LocalID = val.LocalID
+		}
+
+		updateKey.FolderID, updateKey.TagIDs = refs.toLocal(v.FolderID, v.TagIDs)
+		errAdd := a.AddSSHKey(&updateKey, true)
+		if errAdd != nil {
+			logger.Error(errAdd)
+		}
+	}
+
+	// создаем записи в бд сервера
+	for _, v := range keys {
+		if val, ok := getKeysMap[v.ExternalID]; ok {
+			if val.UpdatedAt.Unix() > v.UpdatedAt.Unix() {
+				continue
+			}
+		}
+
+		// отправим на сервер
+		reqBody := smodel.DataSSHKey{
+			ID:          v.ExternalID,
+			Title:       v.Title,
+			PrivateKey:  v.PrivateKey,
+			PublicKey:   v.PublicKey,
+			Fingerprint: v.Fingerprint,
+			Passphrase:  v.Passphrase,
+			Meta:        v.Meta,
+			UpdatedAt:   v.UpdatedAt,
+		}
+		reqBody.FolderID, reqBody.TagIDs = refs.toExternal(v.FolderID, v.TagIDs)
+		reqBody.Fields = toServerFields(v.Fields)
+
+		id, err := a.HTTPService.AddSSHKey(accessToken, reqBody)
+		if err != nil {
+			logger.Error(err)
+
+			continue
+		}
+
+		v := v
+		v.ExternalID = id
+		err = a.db.AddSSHKey(&v)
+		if err != nil {
+			logger.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// sshAgentKeys ключи для ssh-агента: все SSH-ключи хранилища, которые удалось разобрать.
+func (a *App) sshAgentKeys() ([]sshagent.Key, error) {
+	items, err := a.GetAllSSHKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]sshagent.Key, 0, len(items))
+	for _, v := range items {
+		signer, err := sshagent.ParseSigner(v.PrivateKey, v.Passphrase)
+		if err != nil {
+			logger.Error("ssh agent: ", v.Title, ": ", err)
+
+			continue
+		}
+		keys = append(keys, sshagent.Key{Comment: v.Title, Signer: signer})
+	}
+
+	return keys, nil
+}
+
+// confirmSSHKey спрашивает у пользователя разрешение на каждую подпись ключом.
+func (a *App) confirmSSHKey(key sshagent.Key) bool {
+	res := make(chan bool, 1)
+
+	msg := fmt.Sprintf("Разрешить подпись ключом «%s»?", key.Comment)
+	dialog.ShowConfirm("SSH-агент", msg, func(b bool) {
+		res <- b
+	}, a.window)
+	a.window.RequestFocus()
+
+	select {
+	case ok := <-res:
+		return ok
+	case <-time.After(sshConfirmTimeout):
+		return false
+	}
+}
+
+// sshAgentSocket путь к сокету агента для SSH_AUTH_SOCK.
+func (a *App) sshAgentSocket() string {
+	path, err := filepath.Abs(filepath.Join(a.cfg.ClientFolder, "agent.sock"))
+	if err != nil {
+		return filepath.Join(a.cfg.ClientFolder, "agent.sock")
+	}
+
+	return path
+}
+
+func (a *App) startSSHAgent() error {
+	if a.sshAgent != nil {
+		return nil
+	}
+
+	l, err := sshagent.Listen(a.sshAgentSocket())
+	if err != nil {
+		return err
+	}
+	a.sshAgent = l
+
+	agent := &sshagent.Agent{Keys: a.sshAgentKeys, Confirm: a.confirmSSHKey}
+	go func() {
+		err := agent.Serve(l)
+		if err != nil {
+			logger.Error("ssh agent: ", err)
+		}
+	}()
+
+	return nil
+}
+
+// stopSSHAgent останавливает агент, например при выходе из хранилища.
+func (a *App) stopSSHAgent() {
+	if a.sshAgent == nil {
+		return
+	}
+
+	err := a.sshAgent.Close()
+	if err != nil {
+		logger.Error("ssh agent: ", err)
+	}
+	a.sshAgent = nil
+}
+
+// sshAgentBar управление ssh-агентом над списком ключей.
+func (a *App) sshAgentBar() fyne.CanvasObject {
+	status := widget.NewLabel("")
+	var toggle *widget.Button
+
+	update := func() {
+		if a.sshAgent != nil {
+			status.SetText("SSH_AUTH_SOCK=" + a.sshAgentSocket())
+			toggle.SetText("Остановить ssh-агент")
+			toggle.SetIcon(theme.MediaStopIcon())
+		} else {
+			status.SetText("ssh-агент остановлен")
+			toggle.SetText("Запустить ssh-агент")
+			toggle.SetIcon(theme.MediaPlayIcon())
+		}
+	}
+
+	toggle = widget.NewButton("", func() {
+		if a.sshAgent != nil {
+			a.stopSSHAgent()
+		} else if err := a.startSSHAgent(); err != nil {
+			logger.Error(err)
+			dialog.ShowError(errors.New("не удалось запустить ssh-агент"), a.window)
+		}
+		update()
+	})
+	update()
+
+	copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		a.window.Clipboard().SetContent("export SSH_AUTH_SOCK=" + a.sshAgentSocket())
+	})
+
+	return container.NewBorder(nil, nil, toggle, copyBtn, status)
+}
+
+func (a *App) sshKeyList() *fyne.Container {
+	var keys []model.DataSSHKey
+
+	noItems := container.NewCenter(canvas.NewText("Нет записей", color.Black))
+
+	allKeys, err := a.GetAllSSHKeys()
+	if err != nil {
+		logger.Error(err)
+	}
+	for _, v := range allKeys {
+		if a.filter.match(v.FolderID, v.TagIDs) {
+			keys = append(keys, v)
+		}
+	}
+
+	keysList := widget.NewList(
+		func() int {
+			return len(keys)
+		},
+
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Default")
+		},
+
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			co.(*widget.Label).SetText(keys[lii].Title + "  " + keys[lii].Fingerprint)
+		},
+	)
+
+	keysList.OnSelected = func(id widget.ListItemID) {
+		a.pageEdit(keys[id].LocalID, ui.TypeSSH)
+	}
+
+	scroll := container.NewScroll(keysList)
+	scroll.SetMinSize(fyne.NewSize(100, 650))
+
+	if len(keys) != 0 {
+		noItems.Hide()
+	}
+
+	return container.NewBorder(a.sshAgentBar(), nil, nil, nil,
+		container.New(layout.NewPaddedLayout(), scroll, noItems))
+}
+
+func (a *App) addSSHKeyForm(localID int) *fyne.Container {
+	var err error
+	var item model.DataSSHKey
+
+	title := widget.NewEntry()
+	privateKey := widget.NewMultiLineEntry()
+	privateKey.TextStyle = fyne.TextStyle{Monospace: true}
+	passphrase := widget.NewPasswordEntry()
+	meta := widget.NewMultiLineEntry()
+
+	publicKey := widget.NewLabel("")
+	publicKey.Wrapping = fyne.TextWrapBreak
+	fingerprint := widget.NewLabel("")
+
+	if localID > 0 {
+		item, err = a.GetSSHKey(localID)
+		if err != nil {
+			logger.Error(err)
+		}
+		title.Text = item.Title
+		privateKey.Text = item.PrivateKey
+		passphrase.Text = item.Passphrase
+		meta.Text = item.Meta
+		publicKey.Text = item.PublicKey
+		fingerprint.Text = item.Fingerprint
+	}
+
+	// открытый ключ и отпечаток пересчитываются при изменении закрытого ключа или фразы
+	preview := func(string) {
+		pair, err := sshagent.Inspect(privateKey.Text, passphrase.Text, title.Text)
+		if err != nil {
+			publicKey.SetText("")
+			fingerprint.SetText("")
+
+			return
+		}
+		publicKey.SetText(pair.PublicKey)
+		fingerprint.SetText(pair.Fingerprint)
+	}
+	privateKey.OnChanged = preview
+	passphrase.OnChanged = preview
+
+	title.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
+	privateKey.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
+
+	generate := func(keyType string) func() {
+		return func() {
+			pair, err := sshagent.Generate(keyType, "")
+			if err != nil {
+				logger.Error(err)
+				dialog.ShowError(errors.New("ошибка создания ключа"), a.window)
+
+				return
+			}
+			passphrase.SetText("")
+			privateKey.SetText(pair.PrivateKey)
+		}
+	}
+
+	copyPublicKey := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		a.window.Clipboard().SetContent(publicKey.Text)
+	})
+
+	addForm := widget.NewForm(
+		widget.NewFormItem("Заголовок", title),
+		widget.NewFormItem("Создать", container.NewHBox(
+			widget.NewButton("Ed25519", generate(sshagent.KeyTypeEd25519)),
+			widget.NewButton("RSA", generate(sshagent.KeyTypeRSA)),
+		)),
+		widget.NewFormItem("Закрытый ключ", privateKey),
+		widget.NewFormItem("Парольная фраза", passphrase),
+		widget.NewFormItem("Открытый ключ", container.NewBorder(nil, nil, nil, copyPublicKey, publicKey)),
+		widget.NewFormItem("Отпечаток", fingerprint),
+		widget.NewFormItem("Дополнительно", meta),
+	)
+
+	fieldsBox, getFields := fieldsEditor(item.Fields)
+	addForm.Append("Поля", fieldsBox)
+
+	refsFormItems, getRefs := a.itemRefsFormItems(item.FolderID, item.TagIDs)
+	for _, v := range refsFormItems {
+		addForm.AppendItem(v)
+	}
+
+	addForm.CancelText = "Отмена"
+	addForm.OnCancel = func() {
+		a.pageMain(ui.TypeSSH)
+	}
+	addForm.SubmitText = "Сохранить"
+	addForm.OnSubmit = func() {
+		var err error
+
+		pair, err := sshagent.Inspect(privateKey.Text, passphrase.Text, title.Text)
+		if errors.Is(err, sshagent.ErrPassphraseMissing) {
+			dialog.ShowError(errors.New("ключ зашифрован, укажите парольную фразу"), a.window)
+
+			return
+		}
+		if err != nil {
+			dialog.ShowError(errors.New("не удалось прочитать закрытый ключ"), a.window)
+
+			return
+		}
+
+		keyData := model.DataSSHKey{
+			Title:       title.Text,
+			PrivateKey:  pair.PrivateKey,
+			PublicKey:   pair.PublicKey,
+			Fingerprint: pair.Fingerprint,
+			Passphrase:  passphrase.Text,
+			Meta:        meta.Text,
+			UpdatedAt:   time.Now(),
+		}
+
+		keyData.FolderID, keyData.TagIDs = getRefs()
+
+		keyData.Fields, err = getFields()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
+		if localID > 0 {
+			keyData.LocalID = item.LocalID
+			keyData.ExternalID = item.ExternalID
+		}
+
+		err = a.AddSSHKey(&keyData, false)
+		if err != nil {
+			dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+			return
+		}
+
+		a.pageMain(ui.TypeSSH)
+	}
+
+	return container.NewVBox(append(a.totpViews("", item.Fields), addForm)...)
+}
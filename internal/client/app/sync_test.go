@@ -0,0 +1,751 @@
+package app
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/client/config"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/service"
+	"github.com/rainset/gophkeeper/internal/client/sshagent"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	"github.com/rainset/gophkeeper/internal/client/urlmatch"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/testserver"
+	"github.com/rainset/gophkeeper/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testLogin    = "user"
+	testPassword = "password"
+)
+
+// newTestApp создает клиента с отдельной локальной БД, подключенного к серверу srv,
+// и авторизует в нем пользователя testLogin (регистрирует при signUp).
+func newTestApp(t *testing.T, srv *testserver.Server, signUp bool) *App {
+	t.Helper()
+
+	return newTestAppUser(t, srv, testLogin, signUp)
+}
+
+// newTestAppUser то же, что newTestApp, для пользователя login.
+func newTestAppUser(t *testing.T, srv *testserver.Server, login string, signUp bool) *App {
+	t.Helper()
+
+	a := New(&config.Config{ServerAddress: srv.Address(), ServerProtocol: "https", ClientFolder: t.TempDir()})
+	t.Cleanup(func() { a.db.Close() })
+
+	user := model.User{Login: login, Password: testPassword}
+
+	var (
+		tokens model.Tokens
+		err    error
+	)
+
+	if signUp {
+		tokens, err = a.HTTPService.SignUp(user)
+	} else {
+		tokens, err = a.HTTPService.SignIn(user)
+	}
+	require.NoError(t, err)
+
+	signKey, err := a.HTTPService.GetSignKey(tokens.AccessToken, login, testPassword)
+	require.NoError(t, err)
+
+	a.SetUser(login)
+	require.NoError(t, a.SetUserConfig(model.UserConfig{
+		Login:        login,
+		Password:     hash.Sha256(testPassword),
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		SignKey:      signKey,
+	}))
+
+	return a
+}
+
+func accessToken(t *testing.T, a *App) string {
+	t.Helper()
+
+	c, err := a.GetUserConfig()
+	require.NoError(t, err)
+
+	return c.AccessToken
+}
+
+func TestApp_SyncCards(t *testing.T) {
+	srv := testserver.New(t)
+	first := newTestApp(t, srv, true)
+
+	for _, title := range []string{"first", "second"} {
+		require.NoError(t, first.AddCard(&model.DataCard{Title: title, Number: title + " number", Date: "12/30", Cvv: "123", Meta: "meta", UpdatedAt: time.Now()}, false))
+	}
+
+	require.NoError(t, first.SyncCards(accessToken(t, first)))
+
+	// повторная синхронизация не создает дублей
+	require.NoError(t, first.SyncCards(accessToken(t, first)))
+
+	items, err := first.HTTPService.GetCardList(accessToken(t, first))
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	local, err := first.GetAllCards()
+	require.NoError(t, err)
+	assert.Len(t, local, 2)
+
+	second := newTestApp(t, srv, false)
+	require.NoError(t, second.SyncCards(accessToken(t, second)))
+
+	got, err := second.GetAllCards()
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	numbers := map[string]string{}
+	for _, v := range got {
+		numbers[v.Title] = v.Number
+	}
+	assert.Equal(t, map[string]string{"first": "first number", "second": "second number"}, numbers)
+}
+
+func TestApp_SyncCreds(t *testing.T) {
+	srv := testserver.New(t)
+	first := newTestApp(t, srv, true)
+
+	for _, title := range []string{"first", "second"} {
+		fields := []model.Field{{Label: "pin", Type: "hidden", Value: title + " pin"}}
+		require.NoError(t, first.AddCred(&model.DataCred{Title: title, Username: title + " username", Password: "password", Meta: "meta", Fields: fields, URLs: []model.CredURL{{URL: title + ".example.com", Match: "host"}}, TOTP: "otpauth://totp/" + title + "?secret=GEZDGNBVGY3TQOJQ", UpdatedAt: time.Now()}, false))
+	}
+
+	require.NoError(t, first.SyncCreds(accessToken(t, first)))
+	require.NoError(t, first.SyncCreds(accessToken(t, first)))
+
+	items, err := first.HTTPService.GetCredList(accessToken(t, first))
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	second := newTestApp(t, srv, false)
+	require.NoError(t, second.SyncCreds(accessToken(t, second)))
+
+	got, err := second.GetAllCreds()
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	usernames := map[string]string{}
+	pins := map[string]string{}
+	for _, v := range got {
+		usernames[v.Title] = v.Username
+		require.Len(t, v.Fields, 1)
+		pins[v.Title] = v.Fields[0].Value
+		assert.Equal(t, "otpauth://totp/"+v.Title+"?secret=GEZDGNBVGY3TQOJQ", v.TOTP)
+	}
+	assert.Equal(t, map[string]string{"first": "first username", "second": "second username"}, usernames)
+	assert.Equal(t, map[string]string{"first": "first pin", "second": "second pin"}, pins)
+
+	// адреса приходят расшифрованными и участвуют в поиске по сайту
+	found, err := urlmatch.Rank(got, "https://second.example.com/login")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "second", found[0].Cred.Title)
+}
+
+func TestApp_SyncTexts(t *testing.T) {
+	srv := testserver.New(t)
+	first := newTestApp(t, srv, true)
+
+	for _, title := range []string{"first", "second"} {
+		require.NoError(t, first.AddText(&model.DataText{Title: title, Text: title + " text", Meta: "meta", UpdatedAt: time.Now()}, false))
+	}
+
+	require.NoError(t, first.SyncTexts(accessToken(t, first)))
+	require.NoError(t, first.SyncTexts(accessToken(t, first)))
+
+	items, err := first.HTTPService.GetTextList(accessToken(t, first))
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	second := newTestApp(t, srv, false)
+	require.NoError(t, second.SyncTexts(accessToken(t, second)))
+
+	got, err := second.GetAllTexts()
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	texts := map[string]string{}
+	for _, v := range got {
+		texts[v.Title] = v.Text
+	}
+	assert.Equal(t, map[string]string{"first": "first text", "second": "second text"}, texts)
+}
+
+func TestApp_SyncSSHKeys(t *testing.T) {
+	srv := testserver.New(t)
+	first := newTestApp(t, srv, true)
+
+	pair, err := sshagent.Generate(sshagent.KeyTypeEd25519, "github")
+	require.NoError(t, err)
+
+	require.NoError(t, first.AddSSHKey(&model.DataSSHKey{
+		Title:       "github",
+		PrivateKey:  pair.PrivateKey,
+		PublicKey:   pair.PublicKey,
+		Fingerprint: pair.Fingerprint,
+		Passphrase:  "secret",
+		Meta:        "meta",
+		UpdatedAt:   time.Now(),
+	}, false))
+
+	require.NoError(t, first.SyncSSHKeys(accessToken(t, first)))
+	require.NoError(t, first.SyncSSHKeys(accessToken(t, first)))
+
+	items, err := first.HTTPService.GetSSHKeyList(accessToken(t, first))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, pair.PublicKey, items[0].PublicKey)
+	assert.NotEqual(t, pair.PrivateKey, items[0].PrivateKey)
+	assert.NotEqual(t, "secret", items[0].Passphrase)
+
+	second := newTestApp(t, srv, false)
+	require.NoError(t, second.SyncSSHKeys(accessToken(t, second)))
+
+	got, err := second.GetAllSSHKeys()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, pair.PrivateKey, got[0].PrivateKey)
+	assert.Equal(t, pair.Fingerprint, got[0].Fingerprint)
+	assert.Equal(t, "secret", got[0].Passphrase)
+
+	keys, err := second.sshAgentKeys()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "github", keys[0].Comment)
+}
+
+func TestApp_SyncIdentities(t *testing.T) {
+	srv := testserver.New(t)
+	first := newTestApp(t, srv, true)
+
+	require.NoError(t, first.AddIdentity(&model.DataIdentity{
+		Title:      "паспорт",
+		Kind:       smodel.IdentityKindPassport,
+		FullName:   "Иванов Иван",
+		Number:     "4500 123456",
+		Country:    "RU",
+		IssueDate:  "2020-01-15",
+		ExpiryDate: "2030-01-15",
+		UpdatedAt:  time.Now(),
+	}, false))
+
+	require.NoError(t, first.SyncIdentities(accessToken(t, first)))
+	require.NoError(t, first.SyncIdentities(accessToken(t, first)))
+
+	items, err := first.HTTPService.GetIdentityList(accessToken(t, first))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, smodel.IdentityKindPassport, items[0].Kind)
+	assert.NotEqual(t, "4500 123456", items[0].Number)
+
+	second := newTestApp(t, srv, false)
+	require.NoError(t, second.SyncIdentities(accessToken(t, second)))
+
+	got, err := second.GetAllIdentities()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "4500 123456", got[0].Number)
+	assert.Equal(t, "2030-01-15", got[0].ExpiryDate)
+}
+
+func TestApp_SyncCustomItems(t *testing.T) {
+	srv := testserver.New(t)
+	first := newTestApp(t, srv, true)
+
+	tpl := model.Template{Name: "БД", Schema: `{"fields": [{"name": "host", "label": "Хост", "type": "text", "list": true}, {"name": "password", "label": "Пароль", "type": "secret"}]}`, UpdatedAt: time.Now()}
+	require.NoError(t, first.AddTemplate(&tpl, false))
+	require.NoError(t, first.AddCustomItem(&model.DataCustom{TemplateID: tpl.LocalID, Title: "prod", Values: `{"host":"db.local","password":"secret"}`, UpdatedAt: time.Now()}, false))
+
+	token := accessToken(t, first)
+	require.NoError(t, first.SyncTemplates(token))
+	require.NoError(t, first.SyncCustomItems(token))
+
+	// повторная синхронизация не создает дублей
+	require.NoError(t, first.SyncTemplates(token))
+	require.NoError(t, first.SyncCustomItems(token))
+
+	items, err := first.HTTPService.GetCustomItemList(token)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.NotContains(t, items[0].Values, "secret")
+
+	second := newTestApp(t, srv, false)
+	token = accessToken(t, second)
+	require.NoError(t, second.SyncTemplates(token))
+	require.NoError(t, second.SyncCustomItems(token))
+
+	templates, err := second.GetAllTemplates()
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	assert.Equal(t, "БД", templates[0].Name)
+
+	got, err := second.GetAllCustomItems()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, templates[0].LocalID, got[0].TemplateID)
+	assert.Equal(t, `{"host":"db.local","password":"secret"}`, got[0].Values)
+
+	// удаление шаблона удаляет и записи по нему
+	require.NoError(t, second.DeleteTemplate(templates[0].LocalID))
+	got, err = second.GetAllCustomItems()
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestApp_SyncFiles(t *testing.T) {
+	srv := testserver.New(t)
+	first := newTestApp(t, srv, true)
+
+	src := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(src, []byte("Hello, world!"), 0600))
+	require.NoError(t, first.AddFile(&model.DataFile{Title: "file", Filename: "file.txt", Path: src, Meta: "meta", Fields: []model.Field{{Label: "site", Type: "url", Value: "https://example.com"}}, UpdatedAt: time.Now()}, false))
+
+	require.NoError(t, first.SyncFiles(accessToken(t, first)))
+
+	items, err := first.HTTPService.GetFileList(accessToken(t, first))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	second := newTestApp(t, srv, false)
+	second.Channels.SyncProgressBar = make(chan float64, 101)
+
+	// прерванное ранее скачивание продолжается с уже скачанной части
+	partial, err := second.FileService.OpenPartial(items[0].SHA256)
+	require.NoError(t, err)
+	_, err = partial.WriteString("Hello")
+	require.NoError(t, err)
+	require.NoError(t, partial.Close())
+
+	require.NoError(t, second.SyncFiles(accessToken(t, second)))
+
+	close(second.Channels.SyncProgressBar)
+	var progress []float64
+	for v := range second.Channels.SyncProgressBar {
+		progress = append(progress, v)
+	}
+	require.NotEmpty(t, progress)
+	assert.InDelta(t, 1.0, progress[len(progress)-1], 1e-9)
+	_, err = os.Stat(partial.Name())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	got, err := second.GetAllFiles()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "file.txt", got[0].Filename)
+	assert.Equal(t, items[0].SHA256, got[0].SHA256)
+	assert.Equal(t, "meta", got[0].Meta)
+	assert.Equal(t, []model.Field{{Label: "site", Type: "url", Value: "https://example.com"}}, got[0].Fields)
+
+	content, err := os.ReadFile(got[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, world!", string(content))
+}
+
+func TestApp_SyncFolders(t *testing.T) {
+	srv := testserver.New(t)
+	first := newTestApp(t, srv, true)
+
+	parent := model.Folder{Name: "work", UpdatedAt: time.Now()}
+	require.NoError(t, first.AddFolder(&parent, false))
+	child := model.Folder{Name: "bank", ParentID: parent.LocalID, UpdatedAt: time.Now()}
+	require.NoError(t, first.AddFolder(&child, false))
+	tag := model.Tag{Name: "important", UpdatedAt: time.Now()}
+	require.NoError(t, first.AddTag(&tag, false))
+
+	require.NoError(t, first.AddCard(&model.DataCard{Title: "card", Number: "number", Date: "12/30", Cvv: "123", FolderID: child.LocalID, TagIDs: []int{tag.LocalID}, UpdatedAt: time.Now()}, false))
+
+	token := accessToken(t, first)
+	require.NoError(t, first.SyncFolders(token))
+	require.NoError(t, first.SyncTags(token))
+	require.NoError(t, first.SyncCards(token))
+
+	// повторная синхронизация не создает дублей
+	require.NoError(t, first.SyncFolders(token))
+	require.NoError(t, first.SyncTags(token))
+
+	folders, err := first.HTTPService.GetFolderList(token)
+	require.NoError(t, err)
+	assert.Len(t, folders, 2)
+
+	second := newTestApp(t, srv, false)
+	token = accessToken(t, second)
+	require.NoError(t, second.SyncFolders(token))
+	require.NoError(t, second.SyncTags(token))
+	require.NoError(t, second.SyncCards(token))
+
+	gotFolders, err := second.GetAllFolders()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"work", "work / bank"}, pathValues(folderPaths(gotFolders)))
+
+	gotTags, err := second.GetAllTags()
+	require.NoError(t, err)
+	require.Len(t, gotTags, 1)
+	assert.Equal(t, "important", gotTags[0].Name)
+
+	cards, err := second.GetAllCards()
+	require.NoError(t, err)
+	require.Len(t, cards, 1)
+	assert.Equal(t, "work / bank", folderPaths(gotFolders)[cards[0].FolderID])
+	assert.Equal(t, []int{gotTags[0].LocalID}, cards[0].TagIDs)
+
+	// удаление папки снимает её с записей
+	require.NoError(t, second.DeleteFolder(cards[0].FolderID))
+	cards, err = second.GetAllCards()
+	require.NoError(t, err)
+	assert.Zero(t, cards[0].FolderID)
+}
+
+func pathValues(paths map[int]string) []string {
+	res := make([]string, 0, len(paths))
+	for _, v := range paths {
+		res = append(res, v)
+	}
+
+	return res
+}
+
+func TestApp_SyncAttachments(t *testing.T) {
+	srv := testserver.New(t)
+	first := newTestApp(t, srv, true)
+
+	card := model.DataCard{Title: "card", Number: "1234", Date: "12/30", Cvv: "123", UpdatedAt: time.Now()}
+	require.NoError(t, first.AddCard(&card, false))
+	require.NoError(t, first.AddAttachment(ui.TypeCard, card.LocalID, "scan.png", strings.NewReader("scan content")))
+
+	token := accessToken(t, first)
+
+	// вложение записи, еще не отправленной на сервер, ждет следующей синхронизации
+	require.NoError(t, first.SyncAttachments(token))
+	items, err := first.HTTPService.GetAttachmentList(token)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	require.NoError(t, first.SyncCards(token))
+	require.NoError(t, first.SyncAttachments(token))
+	require.NoError(t, first.SyncAttachments(token))
+
+	items, err = first.HTTPService.GetAttachmentList(token)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, smodel.ItemTypeCard, items[0].ItemType)
+	assert.NotEqual(t, "scan.png", items[0].Filename)
+
+	r, err := first.HTTPService.DownloadFile(token, items[0].Path)
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "scan content")
+
+	second := newTestApp(t, srv, false)
+	token = accessToken(t, second)
+	require.NoError(t, second.SyncCards(token))
+	require.NoError(t, second.SyncAttachments(token))
+
+	cards, err := second.GetAllCards()
+	require.NoError(t, err)
+	require.Len(t, cards, 1)
+
+	atts, err := second.GetAttachments(ui.TypeCard, cards[0].LocalID)
+	require.NoError(t, err)
+	require.Len(t, atts, 1)
+	assert.Equal(t, "scan.png", atts[0].Filename)
+
+	content, err = second.ReadAttachment(atts[0])
+	require.NoError(t, err)
+	assert.Equal(t, "scan content", string(content))
+
+	// вложения удаляются вместе с записью
+	localPath := atts[0].Path
+	require.FileExists(t, localPath)
+	require.NoError(t, second.DeleteItem(ui.TypeCard, cards[0].LocalID))
+	atts, err = second.GetAttachments(ui.TypeCard, cards[0].LocalID)
+	require.NoError(t, err)
+	assert.Empty(t, atts)
+	assert.NoFileExists(t, localPath)
+}
+
+func TestApp_SyncShares(t *testing.T) {
+	srv := testserver.New(t)
+	owner := newTestApp(t, srv, true)
+	recipient := newTestAppUser(t, srv, "recipient", true)
+
+	cred := model.DataCred{Title: "db", Username: "admin", Password: "secret", UpdatedAt: time.Now().Add(-time.Hour)}
+	require.NoError(t, owner.AddCred(&cred, false))
+
+	ownerToken := accessToken(t, owner)
+	assert.ErrorIs(t, owner.ShareItem(ui.TypeCred, cred.LocalID, "recipient", smodel.ShareAccessWrite), errShareNotSync)
+	require.NoError(t, owner.SyncCreds(ownerToken))
+
+	// получатель еще ни разу не синхронизировался, и ключей у него нет
+	assert.ErrorIs(t, owner.ShareItem(ui.TypeCred, cred.LocalID, "recipient", smodel.ShareAccessWrite), errShareNoTarget)
+	require.NoError(t, recipient.SyncShares(accessToken(t, recipient)))
+	require.NoError(t, owner.ShareItem(ui.TypeCred, cred.LocalID, "recipient", smodel.ShareAccessWrite))
+
+	shares, err := owner.HTTPService.GetShareList(ownerToken)
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	assert.NotContains(t, shares[0].Data, "secret")
+
+	items, err := recipient.GetIncomingShares()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "secret", items[0].values["password"])
+	assert.NotContains(t, items[0].values, "LocalID")
+	assert.NotContains(t, items[0].values, "folder_id")
+
+	// изменения получателя применяются к записи владельца при синхронизации
+	items[0].values["password"] = "changed"
+	items[0].values["LocalID"] = 100
+	require.NoError(t, recipient.UpdateIncomingShare(items[0]))
+	require.NoError(t, owner.SyncShares(ownerToken))
+
+	got, err := owner.GetCred(cred.LocalID)
+	require.NoError(t, err)
+	assert.Equal(t, "changed", got.Password)
+	assert.Equal(t, cred.LocalID, got.LocalID)
+
+	// изменения владельца попадают в доступ
+	got.Username = "root"
+	got.UpdatedAt = time.Now().Add(time.Hour)
+	require.NoError(t, owner.AddCred(&got, false))
+	require.NoError(t, owner.SyncShares(ownerToken))
+
+	items, err = recipient.GetIncomingShares()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "root", items[0].values["username"])
+
+	// повторное открытие меняет доступ, а не создает новый
+	require.NoError(t, owner.ShareItem(ui.TypeCred, cred.LocalID, "recipient", smodel.ShareAccessRead))
+	shares, err = owner.GetItemShares(ui.TypeCred, cred.LocalID)
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	assert.Equal(t, smodel.ShareAccessRead, shares[0].Access)
+
+	items, err = recipient.GetIncomingShares()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Error(t, recipient.UpdateIncomingShare(items[0]))
+
+	require.NoError(t, owner.RevokeShare(shares[0].ID))
+	items, err = recipient.GetIncomingShares()
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestApp_Organizations(t *testing.T) {
+	srv := testserver.New(t)
+	owner := newTestApp(t, srv, true)
+	reader := newTestAppUser(t, srv, "reader", true)
+
+	orgID, err := owner.CreateOrganization("team")
+	require.NoError(t, err)
+	colID, err := owner.CreateCollection(orgID, "prod")
+	require.NoError(t, err)
+
+	cols, err := owner.GetCollections()
+	require.NoError(t, err)
+	require.Len(t, cols, 1)
+
+	item := collectionItem{item: smodel.CollectionItem{ItemType: smodel.ItemTypeCred}, values: map[string]any{"title": "db", "password": "secret"}}
+	_, err = owner.SaveCollectionItem(cols[0], item)
+	require.NoError(t, err)
+
+	// пользователь без ключей не может быть приглашен
+	assert.ErrorIs(t, owner.InviteMember(orgID, "reader", smodel.RoleReadOnly), errShareNoTarget)
+	require.NoError(t, reader.SyncShares(accessToken(t, reader)))
+	require.NoError(t, owner.InviteMember(orgID, "reader", smodel.RoleReadOnly))
+
+	// коллекции доступны только после принятия приглашения
+	cols, err = reader.GetCollections()
+	require.NoError(t, err)
+	assert.Empty(t, cols)
+	require.NoError(t, reader.AcceptInvite(orgID))
+
+	cols, err = reader.GetCollections()
+	require.NoError(t, err)
+	require.Len(t, cols, 1)
+	assert.Equal(t, colID, cols[0].ID)
+
+	items, err := reader.GetCollectionItems(cols[0])
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "secret", items[0].values["password"])
+
+	raw, err := reader.HTTPService.GetCollectionItemList(accessToken(t, reader), colID)
+	require.NoError(t, err)
+	require.Len(t, raw, 1)
+	assert.NotContains(t, raw[0].Data, "secret")
+
+	items[0].values["password"] = "changed"
+	_, err = reader.SaveCollectionItem(cols[0], items[0])
+	assert.ErrorIs(t, err, service.ErrStatusForbidden)
+
+	require.NoError(t, reader.RemoveMember(orgID, "reader"))
+	cols, err = reader.GetCollections()
+	require.NoError(t, err)
+	assert.Empty(t, cols)
+}
+
+func TestApp_EmergencyAccess(t *testing.T) {
+	srv := testserver.New(t)
+	owner := newTestApp(t, srv, true)
+	contact := newTestAppUser(t, srv, "contact", true)
+
+	cred := model.DataCred{Title: "bank", Username: "owner", Password: "secret", Meta: "meta", UpdatedAt: time.Now()}
+	require.NoError(t, owner.AddCred(&cred, false))
+	require.NoError(t, owner.AddAttachment(ui.TypeCred, cred.LocalID, "contract.pdf", strings.NewReader("contract")))
+
+	src := filepath.Join(t.TempDir(), "will.txt")
+	require.NoError(t, os.WriteFile(src, []byte("will"), 0600))
+	require.NoError(t, owner.AddFile(&model.DataFile{Title: "will", Filename: "will.txt", Path: src, Meta: "file meta", UpdatedAt: time.Now()}, false))
+
+	tpl := model.Template{Name: "БД", Schema: `{"fields": [{"name": "host", "label": "Хост", "type": "text"}]}`, UpdatedAt: time.Now()}
+	require.NoError(t, owner.AddTemplate(&tpl, false))
+	require.NoError(t, owner.AddCustomItem(&model.DataCustom{TemplateID: tpl.LocalID, Title: "prod", Values: `{"host":"db.local"}`, UpdatedAt: time.Now()}, false))
+
+	token := accessToken(t, owner)
+	require.NoError(t, owner.SyncCreds(token))
+	require.NoError(t, owner.SyncFiles(token))
+	require.NoError(t, owner.SyncTemplates(token))
+	require.NoError(t, owner.SyncCustomItems(token))
+	require.NoError(t, owner.SyncAttachments(token))
+
+	// пользователь без ключей не может быть назначен доверенным лицом
+	_, err := owner.SaveEmergencyContact(0, "contact", 0)
+	assert.ErrorIs(t, err, errShareNoTarget)
+
+	incoming, err := contact.GetIncomingEmergencyAccess()
+	require.NoError(t, err)
+	assert.Empty(t, incoming)
+
+	_, err = owner.SaveEmergencyContact(0, "contact", 0)
+	require.NoError(t, err)
+
+	incoming, err = contact.GetIncomingEmergencyAccess()
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+	assert.Equal(t, smodel.EmergencyStatusIdle, incoming[0].Status)
+
+	_, err = contact.GetEmergencyVault(incoming[0])
+	assert.ErrorIs(t, err, errEmergencyLocked)
+
+	require.NoError(t, contact.RequestEmergencyAccess(incoming[0].ID))
+
+	incoming, err = contact.GetIncomingEmergencyAccess()
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+	assert.Equal(t, smodel.EmergencyStatusGranted, incoming[0].Status)
+
+	vault, err := contact.GetEmergencyVault(incoming[0])
+	require.NoError(t, err)
+	require.Len(t, vault.Creds, 1)
+	assert.Equal(t, "secret", vault.Creds[0].Password)
+	require.Len(t, vault.Files, 1)
+	assert.Equal(t, "file meta", vault.Files[0].Meta)
+	require.Len(t, vault.CustomItems, 1)
+	assert.Equal(t, `{"host":"db.local"}`, vault.CustomItems[0].Values)
+	require.Len(t, vault.Templates, 1)
+	assert.Equal(t, "БД", vault.Templates[0].Name)
+	require.Len(t, vault.Attachments, 1)
+	assert.Equal(t, "contract.pdf", vault.Attachments[0].Filename)
+
+	items, err := emergencyItems(vault)
+	require.NoError(t, err)
+	titles := make([]string, 0, len(items))
+	for _, item := range items {
+		titles = append(titles, item.title)
+	}
+	assert.Equal(t, []string{"Логин/пароль: bank", "Файлы: will", "По шаблонам: prod (БД)", "Вложение: contract.pdf"}, titles)
+	assert.Equal(t, "owner", items[0].values["username"])
+
+	contacts, err := owner.GetEmergencyContacts()
+	require.NoError(t, err)
+	require.Len(t, contacts, 1)
+	assert.Equal(t, smodel.EmergencyStatusGranted, contacts[0].Status)
+
+	require.NoError(t, owner.RejectEmergencyAccess(contacts[0].ID))
+
+	incoming, err = contact.GetIncomingEmergencyAccess()
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+	assert.Equal(t, smodel.EmergencyStatusRejected, incoming[0].Status)
+	assert.Empty(t, incoming[0].Key)
+
+	require.NoError(t, owner.DeleteEmergencyContact(contacts[0].ID))
+}
+
+func TestApp_SecretLinks(t *testing.T) {
+	srv := testserver.New(t)
+	a := newTestApp(t, srv, true)
+
+	link, err := a.CreateSecretLink("contractor password", 2, time.Hour)
+	require.NoError(t, err)
+
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	require.NotEmpty(t, u.Fragment)
+
+	secrets, err := a.GetSecrets()
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+
+	for i := 0; i < 2; i++ {
+		text, err := a.OpenSecretLink(link)
+		require.NoError(t, err)
+		assert.Equal(t, "contractor password", text)
+	}
+
+	_, err = a.OpenSecretLink(link)
+	assert.ErrorIs(t, err, errSecretNotFound)
+
+	// без ключа во фрагменте ссылка не открывается и просмотр не засчитывается
+	_, err = a.OpenSecretLink(strings.Split(link, "#")[0])
+	assert.ErrorIs(t, err, errSecretLink)
+
+	link, err = a.CreateSecretLink("text", 1, time.Hour)
+	require.NoError(t, err)
+
+	secrets, err = a.GetSecrets()
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	require.NoError(t, a.DeleteSecret(secrets[0].ID))
+
+	_, err = a.OpenSecretLink(link)
+	assert.ErrorIs(t, err, errSecretNotFound)
+}
+
+func TestApp_Usage(t *testing.T) {
+	srv := testserver.New(t)
+	srv.Cfg.QuotaBytes = 1 << 20
+	srv.Cfg.QuotaItems = 5
+	a := newTestApp(t, srv, true)
+
+	usage, err := a.GetUsage()
+	require.NoError(t, err)
+	assert.Equal(t, smodel.Usage{MaxBytes: 1 << 20, MaxItems: 5}, usage)
+
+	text := smodel.DataText{Title: "title", Text: "text", UpdatedAt: time.Now()}
+	_, err = a.HTTPService.AddText(accessToken(t, a), text)
+	require.NoError(t, err)
+
+	usage, err = a.GetUsage()
+	require.NoError(t, err)
+	assert.Equal(t, text.PayloadSize(), usage.Bytes)
+	assert.Equal(t, 1, usage.Items)
+}
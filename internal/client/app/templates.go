@@ -0,0 +1,325 @@
+package app
+
+import (
+	"errors"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/itemtemplate"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// templateSchemaExample схема, подставляемая в форму нового шаблона.
+const templateSchemaExample = `{"fields": [
+  {"name": "host", "label": "Хост", "type": "text", "required": true, "list": true},
+  {"name": "port", "label": "Порт", "type": "number", "min": 1, "max": 65535, "default": "5432"},
+  {"name": "user", "label": "Пользователь", "type": "text", "list": true},
+  {"name": "password", "label": "Пароль", "type": "secret"},
+  {"name": "sslmode", "label": "SSL", "type": "select", "options": ["disable", "require", "verify-full"]}
+]}`
+
+func (a *App) AddTemplate(tpl *model.Template, encrypted bool) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	if !encrypted {
+		sKey := crypt.DecodeBase64(c.SignKey)
+		for _, v := range []*string{&tpl.Name, &tpl.Schema} {
+			enc, err := crypt.Encrypt([]byte(*v), sKey)
+			if err != nil {
+				return err
+			}
+			*v = crypt.EncodeBase64(enc)
+		}
+	}
+
+	return a.db.AddTemplate(tpl)
+}
+
+func (a *App) GetAllTemplates() (templates []model.Template, err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return templates, err
+	}
+	sKey := crypt.DecodeBase64(c.SignKey)
+
+	templatesEnc, err := a.db.GetAllTemplates()
+	if err != nil {
+		return templates, err
+	}
+
+	for _, v := range templatesEnc {
+		for _, s := range []*string{&v.Name, &v.Schema} {
+			dec, err := crypt.Decrypt(crypt.DecodeBase64(*s), sKey)
+			if err != nil {
+				return templates, err
+			}
+			*s = string(dec)
+		}
+
+		templates = append(templates, v)
+	}
+
+	return templates, nil
+}
+
+// getTemplateSchema шаблон с локальным идентификатором localID и его разобранная схема.
+func (a *App) getTemplateSchema(localID int) (tpl model.Template, schema itemtemplate.Schema, err error) {
+	templates, err := a.GetAllTemplates()
+	if err != nil {
+		return tpl, schema, err
+	}
+
+	for _, v := range templates {
+		if v.LocalID == localID {
+			schema, err = itemtemplate.Parse(v.Schema)
+			return v, schema, err
+		}
+	}
+
+	return tpl, schema, errors.New("шаблон не найден")
+}
+
+// DeleteTemplate удаляет шаблон вместе с записями по нему и их вложениями; на сервере записи удаляются каскадно.
+func (a *App) DeleteTemplate(localID int) (err error) {
+	c, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	templates, err := a.db.GetAllTemplates()
+	if err != nil {
+		return err
+	}
+
+	var tpl model.Template
+	for _, v := range templates {
+		if v.LocalID == localID {
+			tpl = v
+		}
+	}
+
+	items, err := a.db.GetAllCustomItems()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range items {
+		if v.TemplateID != localID {
+			continue
+		}
+
+		if err = a.db.DeleteCustomItem(v.LocalID); err != nil {
+			return err
+		}
+
+		if err = a.deleteItemAttachments(smodel.ItemTypeCustom, v.LocalID); err != nil {
+			return err
+		}
+	}
+
+	err = a.db.DeleteTemplate(localID)
+	if err != nil {
+		return err
+	}
+
+	if tpl.ExternalID == 0 {
+		return nil
+	}
+
+	go func() {
+		err := a.HTTPService.DeleteTemplate(c.AccessToken, tpl.ExternalID)
+		if err != nil {
+			logger.Error("goroutine delete:", err)
+		}
+	}()
+
+	return nil
+}
+
+// SyncTemplates синхронизирует шаблоны до записей по ним.
+func (a *App) SyncTemplates(accessToken string) (err error) {
+	templates, err := a.db.GetAllTemplates()
+	if err != nil {
+		return err
+	}
+
+	getTemplates, err := a.HTTPService.GetTemplateList(accessToken)
+	if err != nil {
+		return err
+	}
+
+	localByExt := make(map[int]model.Template)
+	for _, v := range templates {
+		if v.ExternalID != 0 {
+			localByExt[v.ExternalID] = v
+		}
+	}
+
+	getTemplatesMap := make(map[int]*model.Template)
+
+	// создаем записи в бд клиента
+	for _, v := range getTemplates {
+		getTemplatesMap[v.ExternalID] = v
+
+		val, ok := localByExt[v.ExternalID]
+		if ok && val.UpdatedAt.Unix() >= v.UpdatedAt.Unix() {
+			continue
+		}
+
+		updateTemplate := *v
+		updateTemplate.LocalID = val.LocalID
+
+		errUpdate := a.AddTemplate(&updateTemplate, true)
+		if errUpdate != nil {
+			logger.Error("SyncTemplates - errUpdate local: ", errUpdate)
+		}
+	}
+
+	// создаем записи в бд сервера
+	for _, v := range templates {
+		if val, ok := getTemplatesMap[v.ExternalID]; ok && val.UpdatedAt.Unix() >= v.UpdatedAt.Unix() {
+			continue
+		}
+
+		reqBody := smodel.Template{
+			ID:        v.ExternalID,
+			Name:      v.Name,
+			Schema:    v.Schema,
+			UpdatedAt: v.UpdatedAt,
+		}
+
+		id, err := a.HTTPService.AddTemplate(accessToken, reqBody)
+		if err != nil {
+			logger.Error("SyncTemplates - add: ", err)
+			continue
+		}
+
+		v.ExternalID = id
+		err = a.db.AddTemplate(&v)
+		if err != nil {
+			logger.Error("SyncTemplates - save: ", err)
+		}
+	}
+
+	return nil
+}
+
+// pageTemplates управление шаблонами записей: создание, изменение схемы и удаление.
+func (a *App) pageTemplates(dataType ui.DataType) {
+	templates, err := a.GetAllTemplates()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	var edit model.Template
+
+	name := widget.NewEntry()
+	name.Validator = validation.NewRegexp("^.{1,}", "обязательное поле")
+
+	schema := widget.NewMultiLineEntry()
+	schema.SetText(templateSchemaExample)
+	schema.SetMinRowsVisible(10)
+	schema.Validator = func(s string) error {
+		_, err := itemtemplate.Parse(s)
+		return err
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem("Название", name),
+		widget.NewFormItem("Поля (JSON)", schema),
+	)
+	form.Items[1].HintText = "типы: text, multiline, secret, number, url, email, date, select"
+	form.SubmitText = "Сохранить"
+	form.OnSubmit = func() {
+		tpl := model.Template{
+			LocalID:    edit.LocalID,
+			ExternalID: edit.ExternalID,
+			Name:       name.Text,
+			Schema:     schema.Text,
+			UpdatedAt:  time.Now(),
+		}
+
+		err := a.AddTemplate(&tpl, false)
+		if err != nil {
+			logger.Error(err)
+			dialog.ShowError(errors.New("ошибка сохранения данных"), a.window)
+
+			return
+		}
+
+		a.pageTemplates(dataType)
+	}
+	form.CancelText = "Новый"
+	form.OnCancel = func() {
+		a.pageTemplates(dataType)
+	}
+
+	list := widget.NewList(
+		func() int {
+			return len(templates)
+		},
+		func() fyne.CanvasObject {
+			return container.NewHBox(widget.NewLabel("Шаблон"), layout.NewSpacer(), widget.NewButtonWithIcon("", theme.DeleteIcon(), nil))
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			tpl := templates[lii]
+			row := co.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(tpl.Name)
+			row.Objects[2].(*widget.Button).OnTapped = func() {
+				dialog.ShowConfirm("Удалить", "Удалить шаблон? Все записи по нему будут удалены.", func(b bool) {
+					if !b {
+						return
+					}
+
+					if err := a.DeleteTemplate(tpl.LocalID); err != nil {
+						logger.Error("delete template:", err)
+						dialog.ShowError(errors.New("ошибка при удалении шаблона"), a.window)
+
+						return
+					}
+
+					if a.customTemplate == tpl.LocalID {
+						a.customTemplate = 0
+					}
+					a.pageTemplates(dataType)
+				}, a.window)
+			}
+		},
+	)
+	list.OnSelected = func(lii widget.ListItemID) {
+		edit = templates[lii]
+		name.SetText(edit.Name)
+		schema.SetText(edit.Schema)
+	}
+
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(100, 200))
+
+	a.window.SetContent(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageMain(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText("Шаблоны", color.Black),
+		),
+		canvas.NewLine(color.Black),
+		form,
+		scroll,
+	))
+}
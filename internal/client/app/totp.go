@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/totp"
+)
+
+// encryptOptional шифрует необязательное поле; пустое значение остается пустым,
+// чтобы записи, созданные до появления поля, читались без ошибок.
+func encryptOptional(value string, sKey []byte) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	enc, err := crypt.Encrypt([]byte(value), sKey)
+	if err != nil {
+		return "", err
+	}
+
+	return crypt.EncodeBase64(enc), nil
+}
+
+func decryptOptional(value string, sKey []byte) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	dec, err := crypt.Decrypt(crypt.DecodeBase64(value), sKey)
+	if err != nil {
+		return "", err
+	}
+
+	return string(dec), nil
+}
+
+// validateTOTP проверка поля TOTP в форме: пусто, otpauth:// URI или base32-секрет.
+func validateTOTP(s string) error {
+	if s == "" {
+		return nil
+	}
+
+	_, err := totp.Parse(s)
+
+	return err
+}
+
+// addTicker регистрирует обновление виджета раз в секунду для страницы, которая сейчас строится.
+func (a *App) addTicker(fn func()) {
+	a.tickers = append(a.tickers, fn)
+}
+
+// runTickers запускает зарегистрированные обновления, пока в окне показана страница page.
+func (a *App) runTickers(page fyne.CanvasObject) {
+	tickers := a.tickers
+	a.tickers = nil
+
+	if len(tickers) == 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if a.window.Content() != page {
+				return
+			}
+
+			for _, fn := range tickers {
+				fn()
+			}
+		}
+	}()
+}
+
+// totpView текущий код с обратным отсчетом и кнопкой копирования.
+func (a *App) totpView(title, secret string) fyne.CanvasObject {
+	key, err := totp.Parse(secret)
+	if err != nil {
+		return widget.NewLabel(title + ": некорректный секрет TOTP")
+	}
+
+	code := canvas.NewText("", theme.ForegroundColor())
+	code.TextSize = 24
+	code.TextStyle = fyne.TextStyle{Monospace: true, Bold: true}
+
+	countdown := widget.NewProgressBar()
+	countdown.Max = float64(key.Period)
+	countdown.TextFormatter = func() string {
+		return fmt.Sprintf("%.0f с", countdown.Value)
+	}
+
+	update := func() {
+		now := time.Now()
+		value, err := totp.Generate(key, now)
+		if err != nil {
+			value = "------"
+		}
+
+		code.Text = value
+		code.Refresh()
+		countdown.SetValue(totp.Remaining(key, now).Round(time.Second).Seconds())
+	}
+	update()
+	a.addTicker(update)
+
+	copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		a.window.Clipboard().SetContent(code.Text)
+	})
+
+	return container.NewBorder(nil, countdown, widget.NewLabel(title), copyBtn, container.NewCenter(code))
+}
+
+// totpViews коды TOTP записи: основной секрет учетной записи и пользовательские поля типа totp.
+func (a *App) totpViews(secret string, fields []model.Field) []fyne.CanvasObject {
+	var views []fyne.CanvasObject
+	if secret != "" {
+		views = append(views, a.totpView("TOTP", secret))
+	}
+
+	for _, f := range fields {
+		if f.Type == smodel.FieldTypeTOTP && f.Value != "" {
+			views = append(views, a.totpView(f.Label, f.Value))
+		}
+	}
+
+	return views
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_decryptOptional(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	enc, err := encryptOptional("otpauth://totp/john?secret=GEZDGNBVGY3TQOJQ", key)
+	require.NoError(t, err)
+	assert.NotEmpty(t, enc)
+
+	dec, err := decryptOptional(enc, key)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth://totp/john?secret=GEZDGNBVGY3TQOJQ", dec)
+
+	// записи без значения, сохраненные до появления поля
+	enc, err = encryptOptional("", key)
+	require.NoError(t, err)
+	assert.Empty(t, enc)
+
+	dec, err = decryptOptional("", key)
+	require.NoError(t, err)
+	assert.Empty(t, dec)
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	"github.com/rainset/gophkeeper/internal/client/ui"
+	"github.com/rainset/gophkeeper/internal/client/urlmatch"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// urlMatchNames подписи правил сопоставления адресов в порядке списка выбора.
+var urlMatchNames = []struct {
+	match string
+	name  string
+}{
+	{smodel.URLMatchDomain, "Домен"},
+	{smodel.URLMatchHost, "Точный хост"},
+	{smodel.URLMatchRegex, "Рег. выражение"},
+}
+
+// encryptURLs шифрует адреса учетной записи, правило сопоставления остается открытым.
+func encryptURLs(urls []model.CredURL, sKey []byte) ([]model.CredURL, error) {
+	res := make([]model.CredURL, 0, len(urls))
+	for _, u := range urls {
+		encURL, err := crypt.Encrypt([]byte(u.URL), sKey)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, model.CredURL{URL: crypt.EncodeBase64(encURL), Match: u.Match})
+	}
+
+	return res, nil
+}
+
+func decryptURLs(urls []model.CredURL, sKey []byte) ([]model.CredURL, error) {
+	res := make([]model.CredURL, 0, len(urls))
+	for _, u := range urls {
+		decURL, err := crypt.Decrypt(crypt.DecodeBase64(u.URL), sKey)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, model.CredURL{URL: string(decURL), Match: u.Match})
+	}
+
+	return res, nil
+}
+
+// toServerURLs адреса учетной записи для отправки на сервер.
+func toServerURLs(urls []model.CredURL) smodel.CredURLs {
+	res := make(smodel.CredURLs, 0, len(urls))
+	for _, u := range urls {
+		res = append(res, smodel.CredURL{URL: u.URL, Match: u.Match})
+	}
+
+	return res
+}
+
+// credURLsEditor редактор адресов сайтов учетной записи; пустые строки пропускаются.
+func credURLsEditor(urls []model.CredURL) (editor fyne.CanvasObject, get func() ([]model.CredURL, error)) {
+	matchByName := make(map[string]string, len(urlMatchNames))
+	nameByMatch := make(map[string]string, len(urlMatchNames))
+	matchOptions := make([]string, 0, len(urlMatchNames))
+	for _, v := range urlMatchNames {
+		matchByName[v.name] = v.match
+		nameByMatch[v.match] = v.name
+		matchOptions = append(matchOptions, v.name)
+	}
+
+	type urlRow struct {
+		url   *widget.Entry
+		match *widget.Select
+	}
+
+	var rows []*urlRow
+	rowsBox := container.NewVBox()
+
+	var render func()
+	render = func() {
+		rowsBox.Objects = nil
+		for _, row := range rows {
+			row := row
+			removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				for i, v := range rows {
+					if v == row {
+						rows = append(rows[:i], rows[i+1:]...)
+						break
+					}
+				}
+				render()
+			})
+			rowsBox.Add(container.NewBorder(nil, nil, nil, container.NewHBox(row.match, removeBtn), row.url))
+		}
+		rowsBox.Refresh()
+	}
+
+	addRow := func(u model.CredURL) {
+		row := &urlRow{url: widget.NewEntry(), match: widget.NewSelect(matchOptions, nil)}
+		row.url.SetPlaceHolder("https://example.com")
+		row.url.SetText(u.URL)
+		row.match.SetSelected(nameByMatch[u.Match])
+		rows = append(rows, row)
+	}
+
+	for _, u := range urls {
+		addRow(u)
+	}
+	render()
+
+	addBtn := widget.NewButtonWithIcon("Добавить адрес", theme.ContentAddIcon(), func() {
+		addRow(model.CredURL{Match: smodel.URLMatchDomain})
+		render()
+	})
+
+	get = func() ([]model.CredURL, error) {
+		res := make([]model.CredURL, 0, len(rows))
+		for _, row := range rows {
+			u := model.CredURL{URL: row.url.Text, Match: matchByName[row.match.Selected]}
+			if u.URL == "" {
+				continue
+			}
+
+			if err := urlmatch.Validate(u); err != nil {
+				return nil, fmt.Errorf("адрес %q: %w", u.URL, err)
+			}
+			res = append(res, u)
+		}
+
+		return res, nil
+	}
+
+	return container.NewVBox(rowsBox, addBtn), get
+}
+
+// urlSearchBar поиск учетных записей по адресу сайта на главной странице.
+func (a *App) urlSearchBar(currentType func() ui.DataType) fyne.CanvasObject {
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Логины для сайта: gitlab.example.com")
+	search.OnSubmitted = func(s string) {
+		a.pageURLSearch(s, currentType())
+	}
+
+	searchBtn := widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+		a.pageURLSearch(search.Text, currentType())
+	})
+
+	return container.NewBorder(nil, nil, nil, searchBtn, search)
+}
+
+// pageURLSearch учетные записи для адреса сайта, от самых точных совпадений.
+func (a *App) pageURLSearch(rawURL string, dataType ui.DataType) {
+	creds, err := a.GetAllCreds()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	results, err := urlmatch.Rank(creds, rawURL)
+	if err != nil {
+		logger.Error("url search:", err)
+	}
+
+	scoreNames := map[int]string{
+		urlmatch.ScoreHost:   "точное совпадение",
+		urlmatch.ScoreDomain: "совпадение по домену",
+		urlmatch.ScoreRegex:  "рег. выражение",
+	}
+
+	list := widget.NewList(
+		func() int {
+			return len(results)
+		},
+		func() fyne.CanvasObject {
+			return container.NewHBox(widget.NewLabel("Запись"), layout.NewSpacer(), widget.NewLabel("Совпадение"))
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			row := co.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(results[lii].Cred.Title + " (" + results[lii].Cred.Username + ")")
+			row.Objects[2].(*widget.Label).SetText(scoreNames[results[lii].Score])
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		a.pageEdit(results[id].Cred.LocalID, ui.TypeCred)
+	}
+
+	status := fmt.Sprintf("Найдено записей: %d", len(results))
+	if errors.Is(err, urlmatch.ErrURLInvalid) {
+		status = urlmatch.ErrURLInvalid.Error()
+	}
+
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(100, 500))
+
+	a.window.SetContent(container.NewVBox(
+		container.NewHBox(
+			widget.NewButtonWithIcon("Назад", theme.NavigateBackIcon(), func() {
+				a.pageMain(dataType)
+			}),
+			layout.NewSpacer(),
+			canvas.NewText(rawURL, color.Black),
+		),
+		canvas.NewLine(color.Black),
+		widget.NewLabel(status),
+		scroll,
+	))
+}
@@ -0,0 +1,291 @@
+// Package itemtemplate пользовательские шаблоны записей: описание полей в JSON и проверка значений.
+//
+// Пример схемы шаблона "Подключение к БД":
+//
+//	{"fields": [
+//	  {"name": "host", "label": "Хост", "type": "text", "required": true, "list": true},
+//	  {"name": "port", "label": "Порт", "type": "number", "min": 1, "max": 65535},
+//	  {"name": "user", "label": "Пользователь", "type": "text", "list": true},
+//	  {"name": "password", "label": "Пароль", "type": "secret"},
+//	  {"name": "sslmode", "label": "SSL", "type": "select", "options": ["disable", "require", "verify-full"]}
+//	]}
+package itemtemplate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Типы полей шаблона.
+const (
+	TypeText      = "text"
+	TypeMultiline = "multiline"
+	TypeSecret    = "secret"
+	TypeNumber    = "number"
+	TypeURL       = "url"
+	TypeEmail     = "email"
+	TypeDate      = "date"
+	TypeSelect    = "select"
+)
+
+// DateLayout формат значений полей типа date.
+const DateLayout = "2006-01-02"
+
+var types = map[string]bool{
+	TypeText:      true,
+	TypeMultiline: true,
+	TypeSecret:    true,
+	TypeNumber:    true,
+	TypeURL:       true,
+	TypeEmail:     true,
+	TypeDate:      true,
+	TypeSelect:    true,
+}
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var (
+	ErrSchemaInvalid = errors.New("некорректная схема шаблона")
+	ErrValueInvalid  = errors.New("некорректное значение поля")
+)
+
+// Field поле шаблона. Name - ключ значения в записи, Label - подпись в форме.
+// Pattern, Min/Max и Options - правила проверки значения; List выводит поле в колонку списка записей.
+type Field struct {
+	Name     string   `json:"name"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Options  []string `json:"options,omitempty"`
+	Default  string   `json:"default,omitempty"`
+	List     bool     `json:"list,omitempty"`
+}
+
+// Schema описание полей шаблона в порядке формы.
+type Schema struct {
+	Fields []Field `json:"fields"`
+}
+
+// Parse разбирает и проверяет схему шаблона; неизвестные ключи считаются ошибкой,
+// чтобы опечатка в правиле не отключала проверку молча.
+func Parse(data string) (schema Schema, err error) {
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err = dec.Decode(&schema); err != nil {
+		return schema, fmt.Errorf("%w: %s", ErrSchemaInvalid, err)
+	}
+
+	if err = schema.Validate(); err != nil {
+		return schema, err
+	}
+
+	return schema, nil
+}
+
+// Marshal схема в JSON для хранения.
+func (s Schema) Marshal() (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("itemtemplate.Marshal: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// Validate проверяет описание полей: уникальные имена, известные типы и корректные правила.
+func (s Schema) Validate() error {
+	if len(s.Fields) == 0 {
+		return fmt.Errorf("%w: нет полей", ErrSchemaInvalid)
+	}
+
+	seen := make(map[string]bool, len(s.Fields))
+	for _, f := range s.Fields {
+		if !namePattern.MatchString(f.Name) {
+			return fmt.Errorf("%w: имя поля %q: латинские буквы в нижнем регистре, цифры и _", ErrSchemaInvalid, f.Name)
+		}
+
+		if seen[f.Name] {
+			return fmt.Errorf("%w: повторяется поле %q", ErrSchemaInvalid, f.Name)
+		}
+		seen[f.Name] = true
+
+		if strings.TrimSpace(f.Label) == "" {
+			return fmt.Errorf("%w: поле %q без подписи", ErrSchemaInvalid, f.Name)
+		}
+
+		if !types[f.Type] {
+			return fmt.Errorf("%w: поле %q: неизвестный тип %q", ErrSchemaInvalid, f.Name, f.Type)
+		}
+
+		if f.Pattern != "" {
+			if _, err := regexp.Compile(f.Pattern); err != nil {
+				return fmt.Errorf("%w: поле %q: %s", ErrSchemaInvalid, f.Name, err)
+			}
+		}
+
+		if (f.Min != nil || f.Max != nil) && f.Type != TypeNumber {
+			return fmt.Errorf("%w: поле %q: min и max только для чисел", ErrSchemaInvalid, f.Name)
+		}
+
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return fmt.Errorf("%w: поле %q: min больше max", ErrSchemaInvalid, f.Name)
+		}
+
+		if f.Type == TypeSelect && len(f.Options) == 0 {
+			return fmt.Errorf("%w: поле %q: нет вариантов выбора", ErrSchemaInvalid, f.Name)
+		}
+
+		if f.Type != TypeSelect && len(f.Options) != 0 {
+			return fmt.Errorf("%w: поле %q: варианты только для выбора", ErrSchemaInvalid, f.Name)
+		}
+
+		if f.Default != "" {
+			if err := f.ValidateValue(f.Default); err != nil {
+				return fmt.Errorf("%w: значение по умолчанию: %s", ErrSchemaInvalid, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateValue проверяет значение поля по его типу и правилам; пустое значение допустимо,
+// если поле не обязательное.
+func (f Field) ValidateValue(value string) error {
+	if strings.TrimSpace(value) == "" {
+		if f.Required {
+			return fmt.Errorf("%w: поле %q обязательно", ErrValueInvalid, f.Label)
+		}
+
+		return nil
+	}
+
+	switch f.Type {
+	case TypeNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%w: поле %q: нужно число", ErrValueInvalid, f.Label)
+		}
+
+		if f.Min != nil && n < *f.Min {
+			return fmt.Errorf("%w: поле %q: не меньше %v", ErrValueInvalid, f.Label, *f.Min)
+		}
+
+		if f.Max != nil && n > *f.Max {
+			return fmt.Errorf("%w: поле %q: не больше %v", ErrValueInvalid, f.Label, *f.Max)
+		}
+	case TypeURL:
+		u, err := url.ParseRequestURI(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%w: поле %q: некорректная ссылка", ErrValueInvalid, f.Label)
+		}
+	case TypeEmail:
+		if _, err := mail.ParseAddress(value); err != nil {
+			return fmt.Errorf("%w: поле %q: некорректный email", ErrValueInvalid, f.Label)
+		}
+	case TypeDate:
+		if _, err := time.Parse(DateLayout, value); err != nil {
+			return fmt.Errorf("%w: поле %q: дата в формате ГГГГ-ММ-ДД", ErrValueInvalid, f.Label)
+		}
+	case TypeSelect:
+		found := false
+		for _, o := range f.Options {
+			if o == value {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("%w: поле %q: значение не из списка", ErrValueInvalid, f.Label)
+		}
+	}
+
+	if f.Pattern != "" {
+		// якоря добавляются, чтобы правило описывало значение целиком
+		re, err := regexp.Compile("^(?:" + f.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("%w: поле %q: %s", ErrSchemaInvalid, f.Label, err)
+		}
+
+		if !re.MatchString(value) {
+			return fmt.Errorf("%w: поле %q: не соответствует шаблону %s", ErrValueInvalid, f.Label, f.Pattern)
+		}
+	}
+
+	return nil
+}
+
+// ValidateValues проверяет значения записи; значения полей, которых нет в схеме, не допускаются.
+func (s Schema) ValidateValues(values map[string]string) error {
+	known := make(map[string]bool, len(s.Fields))
+	for _, f := range s.Fields {
+		known[f.Name] = true
+
+		if err := f.ValidateValue(values[f.Name]); err != nil {
+			return err
+		}
+	}
+
+	for name := range values {
+		if !known[name] {
+			return fmt.Errorf("%w: поле %q нет в шаблоне", ErrValueInvalid, name)
+		}
+	}
+
+	return nil
+}
+
+// ListFields поля, которые выводятся колонками списка записей; секретные поля в список не попадают.
+func (s Schema) ListFields() []Field {
+	var res []Field
+	for _, f := range s.Fields {
+		if f.List && f.Type != TypeSecret {
+			res = append(res, f)
+		}
+	}
+
+	return res
+}
+
+// ParseValues разбирает значения записи, сохраненные MarshalValues.
+func ParseValues(data string) (map[string]string, error) {
+	values := make(map[string]string)
+	if data == "" {
+		return values, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), &values); err != nil {
+		return nil, fmt.Errorf("itemtemplate.ParseValues: %w", err)
+	}
+
+	return values, nil
+}
+
+// MarshalValues значения записи в JSON; пустые значения не сохраняются.
+func MarshalValues(values map[string]string) (string, error) {
+	res := make(map[string]string, len(values))
+	for k, v := range values {
+		if v != "" {
+			res[k] = v
+		}
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return "", fmt.Errorf("itemtemplate.MarshalValues: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,136 @@
+package itemtemplate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dbSchema = `{"fields": [
+	{"name": "host", "label": "Хост", "type": "text", "required": true, "list": true},
+	{"name": "port", "label": "Порт", "type": "number", "min": 1, "max": 65535, "default": "5432"},
+	{"name": "user", "label": "Пользователь", "type": "text", "pattern": "[a-z_]+", "list": true},
+	{"name": "password", "label": "Пароль", "type": "secret", "list": true},
+	{"name": "sslmode", "label": "SSL", "type": "select", "options": ["disable", "require"]}
+]}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "valid", data: dbSchema},
+		{name: "not json", data: "host, port", wantErr: true},
+		{name: "unknown key", data: `{"fields": [{"name": "a", "label": "A", "type": "text", "requred": true}]}`, wantErr: true},
+		{name: "no fields", data: `{"fields": []}`, wantErr: true},
+		{name: "bad name", data: `{"fields": [{"name": "Host", "label": "A", "type": "text"}]}`, wantErr: true},
+		{name: "duplicate name", data: `{"fields": [{"name": "a", "label": "A", "type": "text"}, {"name": "a", "label": "B", "type": "text"}]}`, wantErr: true},
+		{name: "empty label", data: `{"fields": [{"name": "a", "label": " ", "type": "text"}]}`, wantErr: true},
+		{name: "unknown type", data: `{"fields": [{"name": "a", "label": "A", "type": "color"}]}`, wantErr: true},
+		{name: "bad pattern", data: `{"fields": [{"name": "a", "label": "A", "type": "text", "pattern": "("}]}`, wantErr: true},
+		{name: "min for text", data: `{"fields": [{"name": "a", "label": "A", "type": "text", "min": 1}]}`, wantErr: true},
+		{name: "min over max", data: `{"fields": [{"name": "a", "label": "A", "type": "number", "min": 2, "max": 1}]}`, wantErr: true},
+		{name: "select without options", data: `{"fields": [{"name": "a", "label": "A", "type": "select"}]}`, wantErr: true},
+		{name: "options for text", data: `{"fields": [{"name": "a", "label": "A", "type": "text", "options": ["x"]}]}`, wantErr: true},
+		{name: "invalid default", data: `{"fields": [{"name": "a", "label": "A", "type": "number", "default": "x"}]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.data)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrSchemaInvalid)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSchema_ValidateValues(t *testing.T) {
+	schema, err := Parse(dbSchema)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		values  map[string]string
+		wantErr bool
+	}{
+		{name: "valid", values: map[string]string{"host": "db.local", "port": "5432", "user": "app_user", "password": "secret", "sslmode": "require"}},
+		{name: "only required", values: map[string]string{"host": "db.local"}},
+		{name: "missing required", values: map[string]string{"port": "5432"}, wantErr: true},
+		{name: "not a number", values: map[string]string{"host": "db.local", "port": "pg"}, wantErr: true},
+		{name: "below min", values: map[string]string{"host": "db.local", "port": "0"}, wantErr: true},
+		{name: "above max", values: map[string]string{"host": "db.local", "port": "70000"}, wantErr: true},
+		{name: "pattern mismatch", values: map[string]string{"host": "db.local", "user": "App"}, wantErr: true},
+		{name: "pattern matches part only", values: map[string]string{"host": "db.local", "user": "app-user"}, wantErr: true},
+		{name: "unknown option", values: map[string]string{"host": "db.local", "sslmode": "allow"}, wantErr: true},
+		{name: "unknown field", values: map[string]string{"host": "db.local", "dbname": "app"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.ValidateValues(tt.values)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrValueInvalid)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestField_ValidateValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		value   string
+		wantErr bool
+	}{
+		{name: "url", field: Field{Label: "A", Type: TypeURL}, value: "https://example.com"},
+		{name: "bad url", field: Field{Label: "A", Type: TypeURL}, value: "example.com", wantErr: true},
+		{name: "email", field: Field{Label: "A", Type: TypeEmail}, value: "user@example.com"},
+		{name: "bad email", field: Field{Label: "A", Type: TypeEmail}, value: "user", wantErr: true},
+		{name: "date", field: Field{Label: "A", Type: TypeDate}, value: "2030-01-31"},
+		{name: "bad date", field: Field{Label: "A", Type: TypeDate}, value: "31.01.2030", wantErr: true},
+		{name: "empty optional", field: Field{Label: "A", Type: TypeDate}, value: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.field.ValidateValue(tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrValueInvalid)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSchema_ListFields(t *testing.T) {
+	schema, err := Parse(dbSchema)
+	require.NoError(t, err)
+
+	var names []string
+	for _, f := range schema.ListFields() {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"host", "user"}, names)
+}
+
+func TestValues(t *testing.T) {
+	data, err := MarshalValues(map[string]string{"host": "db.local", "port": ""})
+	require.NoError(t, err)
+	assert.Equal(t, `{"host":"db.local"}`, data)
+
+	values, err := ParseValues(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "db.local"}, values)
+
+	values, err = ParseValues("")
+	require.NoError(t, err)
+	assert.Empty(t, values)
+
+	_, err = ParseValues("{")
+	assert.Error(t, err)
+}
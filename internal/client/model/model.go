@@ -21,6 +21,19 @@ type UserConfig struct {
 	SignKey      string
 }
 
+// Field пользовательское поле записи, Label и Value хранятся зашифрованными.
+type Field struct {
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// CredURL адрес сайта учетной записи и правило сопоставления (domain, host, regex); URL хранится зашифрованным.
+type CredURL struct {
+	URL   string `json:"url"`
+	Match string `json:"match"`
+}
+
 type DataCard struct {
 	LocalID    int       `storm:"id,increment"`
 	ExternalID int       `json:"id" storm:"unique"`
@@ -29,6 +42,9 @@ type DataCard struct {
 	Date       string    `json:"date"`
 	Cvv        string    `json:"cvv"`
 	Meta       string    `json:"meta"`
+	FolderID   int       `json:"folder_id"`
+	TagIDs     []int     `json:"tag_ids"`
+	Fields     []Field   `json:"fields"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
@@ -39,6 +55,11 @@ type DataCred struct {
 	Username   string    `json:"username"`
 	Password   string    `json:"password"`
 	Meta       string    `json:"meta"`
+	FolderID   int       `json:"folder_id"`
+	TagIDs     []int     `json:"tag_ids"`
+	Fields     []Field   `json:"fields"`
+	URLs       []CredURL `json:"urls"`
+	TOTP       string    `json:"totp"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
@@ -48,6 +69,9 @@ type DataText struct {
 	Title      string    `json:"title"`
 	Text       string    `json:"text"`
 	Meta       string    `json:"meta"`
+	FolderID   int       `json:"folder_id"`
+	TagIDs     []int     `json:"tag_ids"`
+	Fields     []Field   `json:"fields"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
@@ -57,7 +81,115 @@ type DataFile struct {
 	Title      string    `json:"title"`
 	Filename   string    `json:"filename"`
 	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	SHA256     string    `json:"sha256"`
 	Ext        string    `json:"-"`
 	Meta       string    `json:"meta"`
+	FolderID   int       `json:"folder_id"`
+	TagIDs     []int     `json:"tag_ids"`
+	Fields     []Field   `json:"fields"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
+
+// DataSSHKey SSH-ключ. PrivateKey, Passphrase и Meta хранятся зашифрованными,
+// PublicKey и Fingerprint - открыто.
+type DataSSHKey struct {
+	LocalID     int       `storm:"id,increment"`
+	ExternalID  int       `json:"id" storm:"unique"`
+	Title       string    `json:"title"`
+	PrivateKey  string    `json:"private_key"`
+	PublicKey   string    `json:"public_key"`
+	Fingerprint string    `json:"fingerprint"`
+	Passphrase  string    `json:"passphrase"`
+	Meta        string    `json:"meta"`
+	FolderID    int       `json:"folder_id"`
+	TagIDs      []int     `json:"tag_ids"`
+	Fields      []Field   `json:"fields"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// DataIdentity удостоверение личности. Kind (passport, driver_license, id_card) хранится открыто,
+// остальные поля - зашифрованными; даты в формате ГГГГ-ММ-ДД.
+type DataIdentity struct {
+	LocalID    int       `storm:"id,increment"`
+	ExternalID int       `json:"id" storm:"unique"`
+	Title      string    `json:"title"`
+	Kind       string    `json:"kind"`
+	FullName   string    `json:"full_name"`
+	Number     string    `json:"number"`
+	Country    string    `json:"country"`
+	IssueDate  string    `json:"issue_date"`
+	ExpiryDate string    `json:"expiry_date"`
+	Address    string    `json:"address"`
+	Meta       string    `json:"meta"`
+	FolderID   int       `json:"folder_id"`
+	TagIDs     []int     `json:"tag_ids"`
+	Fields     []Field   `json:"fields"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// DataCustom запись по пользовательскому шаблону. TemplateID - локальный идентификатор шаблона;
+// Values - значения полей шаблона в JSON, хранятся зашифрованными целиком.
+type DataCustom struct {
+	LocalID    int       `storm:"id,increment"`
+	ExternalID int       `json:"id" storm:"unique"`
+	TemplateID int       `json:"template_id"`
+	Title      string    `json:"title"`
+	Values     string    `json:"values"`
+	Meta       string    `json:"meta"`
+	FolderID   int       `json:"folder_id"`
+	TagIDs     []int     `json:"tag_ids"`
+	Fields     []Field   `json:"fields"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Folder папка записей. ParentID, как и FolderID/TagIDs записей, хранит локальные
+// идентификаторы (LocalID); при синхронизации они переводятся в идентификаторы сервера и обратно.
+type Folder struct {
+	LocalID    int       `storm:"id,increment"`
+	ExternalID int       `json:"id" storm:"unique"`
+	ParentID   int       `json:"parent_id"`
+	Name       string    `json:"name"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type Tag struct {
+	LocalID    int       `storm:"id,increment"`
+	ExternalID int       `json:"id" storm:"unique"`
+	Name       string    `json:"name"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Template пользовательский шаблон записи; Name и Schema (описание полей в JSON) хранятся зашифрованными.
+type Template struct {
+	LocalID    int       `storm:"id,increment"`
+	ExternalID int       `json:"id" storm:"unique"`
+	Name       string    `json:"name"`
+	Schema     string    `json:"schema"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Attachment файл, прикрепленный к записи. ItemType - тип записи (smodel.ItemType*), ItemID - её локальный
+// идентификатор. Содержимое файла по пути Path и имя Filename хранятся зашифрованными.
+type Attachment struct {
+	LocalID    int       `storm:"id,increment"`
+	ExternalID int       `json:"id" storm:"unique"`
+	ItemType   string    `json:"item_type"`
+	ItemID     int       `json:"item_id"`
+	Filename   string    `json:"filename"`
+	Path       string    `json:"path"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// EmergencyVault записи владельца, полученные по экстренному доступу; зашифрованы ключом хранилища владельца.
+type EmergencyVault struct {
+	Cards       []DataCard     `json:"cards"`
+	Creds       []DataCred     `json:"creds"`
+	Texts       []DataText     `json:"texts"`
+	Files       []DataFile     `json:"files"`
+	SSHKeys     []DataSSHKey   `json:"ssh_keys"`
+	Identities  []DataIdentity `json:"identities"`
+	CustomItems []DataCustom   `json:"custom_items"`
+	Templates   []Template     `json:"templates"`
+	Attachments []Attachment   `json:"attachments"`
+}
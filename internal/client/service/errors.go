@@ -1,9 +1,130 @@
 package service
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+)
 
 var (
 	ErrStatusLoginExists  = errors.New("ошибка такой логин уже занят")
 	ErrStatusUnauthorized = errors.New("ошибка авторизации")
+	ErrStatusForbidden    = errors.New("нет доступа к записи")
+	ErrStatusNotFound     = errors.New("запись не найдена на сервере")
+	ErrStatusConflict     = errors.New("запись уже существует на сервере")
+	ErrStatusValidation   = errors.New("ошибка валидации данных")
+	ErrStatusBadRequest   = errors.New("некорректный запрос к серверу")
+	ErrStatusQuota        = errors.New("превышена квота хранилища")
+	ErrStatusTooLarge     = errors.New("слишком большой запрос к серверу")
+	ErrStatusUploadOffset = errors.New("смещение загрузки расходится с сервером")
+	ErrStatusIncomplete   = errors.New("файл загружен на сервер не полностью")
+	ErrStatusChecksum     = errors.New("контрольная сумма файла не совпадает")
 	ErrServer             = errors.New("ошибка соединения с сервером")
 )
+
+// fieldCodeMessages описания кодов ошибок полей для пользователя.
+var fieldCodeMessages = map[string]string{ //nolint:gochecknoglobals
+	smodel.FieldCodeRequired: "обязательное поле",
+	smodel.FieldCodeInvalid:  "некорректное значение",
+}
+
+// ResponseError ошибка, которую вернул сервер в формате application/problem+json.
+type ResponseError struct {
+	StatusCode int
+	Problem    smodel.Problem
+}
+
+func (e *ResponseError) Error() string {
+	msg := e.Unwrap().Error()
+
+	if len(e.Problem.Errors) == 0 {
+		return msg
+	}
+
+	fields := make([]string, 0, len(e.Problem.Errors))
+	for _, f := range e.Problem.Errors {
+		text, ok := fieldCodeMessages[f.Code]
+		if !ok {
+			text = f.Message
+		}
+
+		fields = append(fields, fmt.Sprintf("%s — %s", f.Field, text))
+	}
+
+	return msg + ": " + strings.Join(fields, ", ")
+}
+
+// Unwrap сопоставляет код ошибки сервера с ошибкой клиента, что позволяет проверять ее через errors.Is.
+func (e *ResponseError) Unwrap() error {
+	switch e.Problem.Code {
+	case smodel.ProblemCodeUnauthorized, smodel.ProblemCodeInvalidCredentials:
+		return ErrStatusUnauthorized
+	case smodel.ProblemCodeLoginExists:
+		return ErrStatusLoginExists
+	case smodel.ProblemCodeForbidden:
+		return ErrStatusForbidden
+	case smodel.ProblemCodeNotFound:
+		return ErrStatusNotFound
+	case smodel.ProblemCodeAlreadyExists:
+		return ErrStatusConflict
+	case smodel.ProblemCodeValidationFailed:
+		return ErrStatusValidation
+	case smodel.ProblemCodeInvalidRequest:
+		return ErrStatusBadRequest
+	case smodel.ProblemCodeQuotaExceeded:
+		return ErrStatusQuota
+	case smodel.ProblemCodeBodyTooLarge:
+		return ErrStatusTooLarge
+	case smodel.ProblemCodeUploadOffset:
+		return ErrStatusUploadOffset
+	case smodel.ProblemCodeUploadIncomplete:
+		return ErrStatusIncomplete
+	case smodel.ProblemCodeChecksumMismatch:
+		return ErrStatusChecksum
+	}
+
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrStatusUnauthorized
+	case http.StatusForbidden:
+		return ErrStatusForbidden
+	case http.StatusNotFound:
+		return ErrStatusNotFound
+	case http.StatusConflict:
+		return ErrStatusConflict
+	case http.StatusBadRequest:
+		return ErrStatusBadRequest
+	case http.StatusRequestEntityTooLarge:
+		return ErrStatusQuota
+	default:
+		return ErrServer
+	}
+}
+
+// decodeRawError возвращает типизированную ошибку по коду и телу ответа, прочитанного без разбора.
+func decodeRawError(statusCode int, body io.Reader) error {
+	respErr := &ResponseError{StatusCode: statusCode}
+	_ = json.NewDecoder(body).Decode(&respErr.Problem)
+
+	return respErr
+}
+
+// decodeError возвращает типизированную ошибку по ответу сервера.
+func decodeError(res *resty.Response, err error) error {
+	if res == nil || !res.IsError() {
+		return err
+	}
+
+	respErr := &ResponseError{StatusCode: res.StatusCode()}
+	if problem, ok := res.Error().(*smodel.Problem); ok && problem != nil {
+		respErr.Problem = *problem
+	}
+
+	return respErr
+}
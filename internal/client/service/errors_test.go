@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_decodeError(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    error
+		wantFields int
+	}{
+		{
+			name:   "ok",
+			status: http.StatusOK,
+			body:   `{"id":1}`,
+		},
+		{
+			name:       "validation",
+			status:     http.StatusBadRequest,
+			body:       `{"type":"urn:gophkeeper:problem:validation_failed","status":400,"code":"validation_failed","errors":[{"field":"cvv","code":"required","message":"cvv empty"}]}`,
+			wantErr:    ErrStatusValidation,
+			wantFields: 1,
+		},
+		{
+			name:    "not found",
+			status:  http.StatusNotFound,
+			body:    `{"status":404,"code":"not_found"}`,
+			wantErr: ErrStatusNotFound,
+		},
+		{
+			name:    "forbidden",
+			status:  http.StatusForbidden,
+			body:    `{"status":403,"code":"forbidden"}`,
+			wantErr: ErrStatusForbidden,
+		},
+		{
+			name:    "invalid credentials",
+			status:  http.StatusUnauthorized,
+			body:    `{"status":401,"code":"invalid_credentials"}`,
+			wantErr: ErrStatusUnauthorized,
+		},
+		{
+			name:    "login exists",
+			status:  http.StatusConflict,
+			body:    `{"status":409,"code":"login_exists"}`,
+			wantErr: ErrStatusLoginExists,
+		},
+		{
+			name:    "upload offset",
+			status:  http.StatusConflict,
+			body:    `{"status":409,"code":"upload_offset_mismatch"}`,
+			wantErr: ErrStatusUploadOffset,
+		},
+		{
+			name:    "empty body",
+			status:  http.StatusInternalServerError,
+			wantErr: ErrServer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", smodel.ProblemContentType)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			res, err := resty.New().R().SetError(&smodel.Problem{}).Get(srv.URL)
+			err = decodeError(res, err)
+
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, tt.wantErr))
+
+			var respErr *ResponseError
+			assert.True(t, errors.As(err, &respErr))
+			assert.Equal(t, tt.status, respErr.StatusCode)
+			assert.Len(t, respErr.Problem.Errors, tt.wantFields)
+		})
+	}
+}
+
+func TestResponseError_Error(t *testing.T) {
+	err := &ResponseError{
+		StatusCode: http.StatusBadRequest,
+		Problem: smodel.Problem{
+			Code:   smodel.ProblemCodeValidationFailed,
+			Errors: []smodel.FieldError{{Field: "cvv", Code: smodel.FieldCodeRequired, Message: "cvv empty"}},
+		},
+	}
+
+	assert.Equal(t, "ошибка валидации данных: cvv — обязательное поле", err.Error())
+}
@@ -66,6 +66,45 @@ func (repo FileService) SaveFile(src io.ReadCloser, ext string) (filePath string
 	return filePathAbs, err
 }
 
+// OpenPartial - открывает недокачанный файл по ключу key, создавая его при необходимости;
+// запись продолжается с конца уже скачанной части
+
+func (repo FileService) OpenPartial(key string) (f *os.File, err error) {
+	dir := filepath.Join(repo.path, "partial")
+
+	err = os.MkdirAll(dir, 0750)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.OpenFile(filepath.Join(dir, filepath.Base(key)), os.O_RDWR|os.O_CREATE, 0600)
+}
+
+// MoveFile - переносит скачанный файл src в хранилище и возвращает его новый путь
+
+func (repo FileService) MoveFile(src string, ext string) (filePath string, err error) {
+	filePath, err = repo.getPath()
+	if err != nil {
+		logger.Error("getPath() ", err)
+
+		return "", err
+	}
+
+	filePath, err = filepath.Abs(filePath + ext)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Rename(src, filePath)
+	if err != nil {
+		logger.Error("os.Rename ", err)
+
+		return "", err
+	}
+
+	return filePath, nil
+}
+
 // DeleteFile - удаляет файл пользователя
 
 func (repo FileService) DeleteFile(filePath string) (err error) {
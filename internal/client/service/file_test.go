@@ -17,7 +17,7 @@ func TestFileService_Close(t *testing.T) {
 	}{
 		{
 			name:    "close",
-			fields:  fields{path: "gophkeeper_files"},
+			fields:  fields{path: t.TempDir()},
 			wantErr: false,
 		},
 	}
@@ -48,7 +48,7 @@ func TestFileService_DeleteFile(t *testing.T) {
 	}{
 		{
 			name:    "delete",
-			fields:  fields{path: "gophkeeper_files"},
+			fields:  fields{path: t.TempDir()},
 			args:    args{filePath: "test_file.txt"},
 			wantErr: false,
 		},
@@ -80,7 +80,7 @@ func TestFileService_GetFile(t *testing.T) {
 	}{
 		{
 			name:    "get file",
-			fields:  fields{path: "gophkeeper_files"},
+			fields:  fields{path: t.TempDir()},
 			args:    args{filePath: "no_file.txt"},
 			wantErr: true,
 		},
@@ -119,7 +119,7 @@ func TestFileService_SaveFile(t *testing.T) {
 	}{
 		{
 			name:    "save file",
-			fields:  fields{path: "gophkeeper_files"},
+			fields:  fields{path: t.TempDir()},
 			args:    args{src: r, ext: ".png"},
 			wantErr: false,
 		},
@@ -150,7 +150,7 @@ func TestFileService_getPath(t *testing.T) {
 	}{
 		{
 			name:    "save file",
-			fields:  fields{path: "gophkeeper_files"},
+			fields:  fields{path: t.TempDir()},
 			wantErr: false,
 		},
 	}
@@ -179,7 +179,7 @@ func TestNew(t *testing.T) {
 	}{
 		{
 			name:    "new repo",
-			args:    args{path: "gophkeeper_files"},
+			args:    args{path: t.TempDir()},
 			wantErr: false,
 		},
 	}
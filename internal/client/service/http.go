@@ -1,11 +1,18 @@
 package service
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -20,8 +27,9 @@ type ResponseID struct {
 }
 
 type HTTPService struct {
-	cfg    *config.Config
-	client *resty.Client
+	cfg     *config.Config
+	client  *resty.Client
+	uploads UploadStore
 }
 
 func NewHTTPService(cfg *config.Config) *HTTPService {
@@ -29,53 +37,50 @@ func NewHTTPService(cfg *config.Config) *HTTPService {
 	client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
 
 	return &HTTPService{
-		cfg:    cfg,
-		client: client,
+		cfg:     cfg,
+		client:  client,
+		uploads: newMemoryUploads(),
 	}
 }
 
+// SetUploadStore задает хранилище незавершенных загрузок; по умолчанию они хранятся в памяти
+// и после перезапуска клиента начинаются заново.
+func (s *HTTPService) SetUploadStore(store UploadStore) {
+	s.uploads = store
+}
+
+// newRequest создает запрос, ошибки которого декодируются в smodel.Problem.
+func (s *HTTPService) newRequest() *resty.Request {
+	return s.client.R().SetError(&smodel.Problem{})
+}
+
 func (s *HTTPService) SignIn(user model.User) (tokens model.Tokens, err error) {
-	res, err := s.client.R().
+	res, err := s.newRequest().
 		SetBody(user).
 		SetResult(&tokens).
 		Post(s.cfg.ServerProtocol + "://" + s.cfg.ServerAddress + "/sign-in")
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return tokens, ErrStatusUnauthorized
-	default:
-		return tokens, err
-	}
+	return tokens, decodeError(res, err)
 }
 
 func (s *HTTPService) SignUp(user model.User) (tokens model.Tokens, err error) {
-	res, err := s.client.R().
+	res, err := s.newRequest().
 		SetBody(user).
 		SetResult(&tokens).
 		Post(s.cfg.ServerProtocol + "://" + s.cfg.ServerAddress + "/sign-up")
 
-	switch res.StatusCode() {
-	case http.StatusConflict:
-		return tokens, ErrStatusLoginExists
-	default:
-		return tokens, err
-	}
+	return tokens, decodeError(res, err)
 }
 
 func (s *HTTPService) PostRefreshToken(refreshToken string) (tokens model.Tokens, err error) {
 	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/refresh-token")
 	rt := smodel.Tokens{RefreshToken: refreshToken}
-	res, err := s.client.R().
+	res, err := s.newRequest().
 		SetBody(rt).
 		SetResult(&tokens).
 		Post(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return tokens, ErrStatusUnauthorized
-	default:
-		return tokens, err
-	}
+	return tokens, decodeError(res, err)
 }
 
 func (s *HTTPService) GetSignKey(accessToken string, login, password string) (signKey string, err error) {
@@ -91,35 +96,25 @@ func (s *HTTPService) GetSignKey(accessToken string, login, password string) (si
 		SignKey string `json:"sign_key"`
 	}
 	s.client.SetAuthToken(accessToken)
-	res, err := s.client.R().
+	res, err := s.newRequest().
 		SetBody(user).
 		SetResult(&resp).
 		Post(url)
 
 	signKey = resp.SignKey
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return signKey, ErrStatusUnauthorized
-	default:
-		return signKey, err
-	}
+	return signKey, decodeError(res, err)
 }
 
 func (s *HTTPService) GetCardList(accessToken string) (items []*model.DataCard, err error) {
 	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/card/list")
 
 	s.client.SetAuthToken(accessToken)
-	res, err := s.client.R().
+	res, err := s.newRequest().
 		SetResult(&items).
 		Get(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return items, ErrStatusUnauthorized
-	default:
-		return items, err
-	}
+	return items, decodeError(res, err)
 }
 
 func (s *HTTPService) GetCredList(accessToken string) (items []*model.DataCred, err error) {
@@ -127,16 +122,11 @@ func (s *HTTPService) GetCredList(accessToken string) (items []*model.DataCred,
 
 	s.client.SetAuthToken(accessToken)
 
-	res, err := s.client.R().
+	res, err := s.newRequest().
 		SetResult(&items).
 		Get(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return items, ErrStatusUnauthorized
-	default:
-		return items, err
-	}
+	return items, decodeError(res, err)
 }
 
 func (s *HTTPService) GetTextList(accessToken string) (items []*model.DataText, err error) {
@@ -144,16 +134,11 @@ func (s *HTTPService) GetTextList(accessToken string) (items []*model.DataText,
 
 	s.client.SetAuthToken(accessToken)
 
-	res, err := s.client.R().
+	res, err := s.newRequest().
 		SetResult(&items).
 		Get(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return items, ErrStatusUnauthorized
-	default:
-		return items, err
-	}
+	return items, decodeError(res, err)
 }
 
 func (s *HTTPService) GetFileList(accessToken string) (items []*model.DataFile, err error) {
@@ -161,16 +146,47 @@ func (s *HTTPService) GetFileList(accessToken string) (items []*model.DataFile,
 
 	s.client.SetAuthToken(accessToken)
 
-	res, err := s.client.R().
+	res, err := s.newRequest().
 		SetResult(&items).
 		Get(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return items, ErrStatusUnauthorized
-	default:
-		return items, err
-	}
+	return items, decodeError(res, err)
+}
+
+func (s *HTTPService) GetSSHKeyList(accessToken string) (items []*model.DataSSHKey, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/ssh/list")
+
+	s.client.SetAuthToken(accessToken)
+
+	res, err := s.newRequest().
+		SetResult(&items).
+		Get(url)
+
+	return items, decodeError(res, err)
+}
+
+func (s *HTTPService) GetIdentityList(accessToken string) (items []*model.DataIdentity, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/identity/list")
+
+	s.client.SetAuthToken(accessToken)
+
+	res, err := s.newRequest().
+		SetResult(&items).
+		Get(url)
+
+	return items, decodeError(res, err)
+}
+
+func (s *HTTPService) GetCustomItemList(accessToken string) (items []*model.DataCustom, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/custom/list")
+
+	s.client.SetAuthToken(accessToken)
+
+	res, err := s.newRequest().
+		SetResult(&items).
+		Get(url)
+
+	return items, decodeError(res, err)
 }
 
 func (s *HTTPService) DeleteCard(accessToken string, extID int) (err error) {
@@ -179,14 +195,9 @@ func (s *HTTPService) DeleteCard(accessToken string, extID int) (err error) {
 	card := smodel.DataCard{ID: extID}
 	s.client.SetAuthToken(accessToken)
 
-	res, err := s.client.R().SetBody(card).Delete(url)
+	res, err := s.newRequest().SetBody(card).Delete(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return ErrStatusUnauthorized
-	default:
-		return err
-	}
+	return decodeError(res, err)
 }
 
 func (s *HTTPService) DeleteCred(accessToken string, extID int) (err error) {
@@ -195,14 +206,9 @@ func (s *HTTPService) DeleteCred(accessToken string, extID int) (err error) {
 	cred := smodel.DataCred{ID: extID}
 
 	s.client.SetAuthToken(accessToken)
-	res, err := s.client.R().SetBody(cred).Delete(url)
+	res, err := s.newRequest().SetBody(cred).Delete(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return ErrStatusUnauthorized
-	default:
-		return err
-	}
+	return decodeError(res, err)
 }
 
 func (s *HTTPService) DeleteText(accessToken string, extID int) (err error) {
@@ -211,14 +217,9 @@ func (s *HTTPService) DeleteText(accessToken string, extID int) (err error) {
 	text := smodel.DataCred{ID: extID}
 
 	s.client.SetAuthToken(accessToken)
-	res, err := s.client.R().SetBody(text).Delete(url)
+	res, err := s.newRequest().SetBody(text).Delete(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return ErrStatusUnauthorized
-	default:
-		return err
-	}
+	return decodeError(res, err)
 }
 
 func (s *HTTPService) DeleteFile(accessToken string, extID int) (err error) {
@@ -227,42 +228,180 @@ func (s *HTTPService) DeleteFile(accessToken string, extID int) (err error) {
 	file := smodel.DataFile{ID: extID}
 
 	s.client.SetAuthToken(accessToken)
-	res, err := s.client.R().SetBody(file).Delete(url)
+	res, err := s.newRequest().SetBody(file).Delete(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return ErrStatusUnauthorized
-	default:
-		return err
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteSSHKey(accessToken string, extID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/ssh")
+
+	key := smodel.DataSSHKey{ID: extID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(key).Delete(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteIdentity(accessToken string, extID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/identity")
+
+	doc := smodel.DataIdentity{ID: extID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(doc).Delete(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteCustomItem(accessToken string, extID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/custom")
+
+	item := smodel.DataCustom{ID: extID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(item).Delete(url)
+
+	return decodeError(res, err)
+}
+
+// DownloadFile открывает содержимое файла filePath; сервер отдает только содержимое записей пользователя.
+func (s *HTTPService) DownloadFile(accessToken, filePath string) (r io.ReadCloser, err error) {
+	url := fmt.Sprintf("%s://%s/%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, filePath)
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.client.R().SetDoNotParseResponse(true).Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	body := res.RawBody()
+	if res.StatusCode() != http.StatusOK {
+		defer body.Close()
+
+		return nil, decodeRawError(res.StatusCode(), body)
 	}
+
+	return body, nil
 }
 
-func (s *HTTPService) DownloadFile(filePath string) (r io.ReadCloser, err error) {
+// DownloadFileTo скачивает файл filePath в dst, продолжая с уже скачанной части dst.
+// hash - SHA-256 содержимого с сервера: докачка идет только если файл на сервере не изменился (If-Range),
+// а скачанный файл сверяется с хешем. Без hash файл скачивается заново целиком и не проверяется.
+// progress получает число байт файла, скачанных к текущему моменту.
+func (s *HTTPService) DownloadFileTo(accessToken, filePath string, dst *os.File, hash string, progress func(n int64)) error {
 	url := fmt.Sprintf("%s://%s/%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, filePath)
 
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	var offset int64
 
-	resp, err := http.Get(url)
+	if hash != "" {
+		info, err := dst.Stat()
+		if err != nil {
+			return err
+		}
 
+		offset = info.Size()
+	}
+
+	s.client.SetAuthToken(accessToken)
+	req := s.client.R().SetDoNotParseResponse(true)
+	if offset > 0 {
+		req.SetHeader("Range", fmt.Sprintf("bytes=%d-", offset)).
+			SetHeader("If-Range", strconv.Quote(hash))
+	}
+
+	res, err := req.Get(url)
 	if err != nil {
-		logger.Error("http.Get: ", err)
+		return err
+	}
+
+	body := res.RawBody()
+	defer body.Close()
+
+	switch res.StatusCode() {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// сервер отдал файл целиком: файл изменился или не поддерживает докачку
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		size, errSize := strconv.ParseInt(strings.TrimPrefix(res.Header().Get("Content-Range"), "bytes */"), 10, 64)
+		if errSize != nil || size != offset {
+			// скачанная часть длиннее файла на сервере: начинаем заново
+			if err = dst.Truncate(0); err != nil {
+				return err
+			}
+
+			return s.DownloadFileTo(accessToken, filePath, dst, hash, progress)
+		}
+
+		// файл был скачан целиком, но не перенесен в хранилище
+		body = http.NoBody
+	default:
+		return decodeRawError(res.StatusCode(), body)
+	}
+
+	if err = dst.Truncate(offset); err != nil {
+		return err
+	}
+
+	if _, err = dst.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	if progress != nil {
+		progress(offset)
+	}
+
+	if _, err = io.Copy(dst, &progressReader{r: body, n: offset, progress: progress}); err != nil {
+		return err
+	}
+
+	if hash == "" {
+		return nil
+	}
+
+	if _, err = dst.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	sum := sha256.New()
+	if _, err = io.Copy(sum, dst); err != nil {
+		return err
+	}
+
+	if hex.EncodeToString(sum.Sum(nil)) != hash {
+		return ErrStatusChecksum
 	}
 
-	return resp.Body, err
+	return nil
+}
+
+// progressReader сообщает progress число прочитанных байт с учетом начального смещения n.
+type progressReader struct {
+	r        io.Reader
+	n        int64
+	progress func(n int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.n += int64(n)
+
+	if p.progress != nil && n > 0 {
+		p.progress(p.n)
+	}
+
+	return n, err
 }
 
 func (s *HTTPService) AddCard(accessToken string, card smodel.DataCard) (id int, err error) {
 	var rb ResponseID
 	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/card")
 	s.client.SetAuthToken(accessToken)
-	res, err := s.client.R().SetBody(card).SetResult(&rb).Post(url)
+	res, err := s.newRequest().SetBody(card).SetResult(&rb).Post(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return rb.ID, ErrStatusUnauthorized
-	default:
-		return rb.ID, err
-	}
+	return rb.ID, decodeError(res, err)
 }
 
 func (s *HTTPService) AddCred(accessToken string, cred smodel.DataCred) (id int, err error) {
@@ -270,14 +409,9 @@ func (s *HTTPService) AddCred(accessToken string, cred smodel.DataCred) (id int,
 	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/cred")
 
 	s.client.SetAuthToken(accessToken)
-	res, err := s.client.R().SetBody(cred).SetResult(&rb).Post(url)
+	res, err := s.newRequest().SetBody(cred).SetResult(&rb).Post(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return rb.ID, ErrStatusUnauthorized
-	default:
-		return rb.ID, err
-	}
+	return rb.ID, decodeError(res, err)
 }
 
 func (s *HTTPService) AddText(accessToken string, text smodel.DataText) (id int, err error) {
@@ -285,38 +419,733 @@ func (s *HTTPService) AddText(accessToken string, text smodel.DataText) (id int,
 	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/text")
 
 	s.client.SetAuthToken(accessToken)
-	res, err := s.client.R().SetBody(text).SetResult(&rb).Post(url)
+	res, err := s.newRequest().SetBody(text).SetResult(&rb).Post(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return rb.ID, ErrStatusUnauthorized
-	default:
-		return rb.ID, err
-	}
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) AddSSHKey(accessToken string, key smodel.DataSSHKey) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/ssh")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(key).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) AddIdentity(accessToken string, doc smodel.DataIdentity) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/identity")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(doc).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) AddCustomItem(accessToken string, item smodel.DataCustom) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/custom")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(item).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
 }
 
 func (s *HTTPService) AddFile(accessToken string, file smodel.DataFile) (id int, err error) {
 	var rb ResponseID
 	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/file")
 
+	fields, err := json.Marshal(file.Fields)
+	if err != nil {
+		return id, err
+	}
+
+	form := map[string]string{
+		"id":         strconv.Itoa(file.ID),
+		"title":      file.Title,
+		"meta":       file.Meta,
+		"folder_id":  strconv.Itoa(file.FolderID),
+		"tag_ids":    joinIDs(file.TagIDs),
+		"fields":     string(fields),
+		"updated_at": file.UpdatedAt.Format(time.RFC3339),
+	}
+
+	hash, err := fileSHA256(file.Path)
+	if err != nil {
+		return id, err
+	}
+
+	// содержимое, которое уже есть на сервере, не загружаем повторно
+	_, err = s.FindContent(accessToken, hash)
+	if errors.Is(err, ErrStatusNotFound) {
+		err = s.uploadContent(accessToken, file.Path, hash)
+	}
+
+	if err != nil {
+		return id, err
+	}
+
+	form["sha256"] = hash
+	form["filename"] = filepath.Base(file.Path)
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetMultipartFormData(form).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
+}
+
+// FindContent сведения о содержимом файла на сервере по хешу SHA-256; ErrStatusNotFound, если его нет.
+func (s *HTTPService) FindContent(accessToken string, hash string) (content smodel.Content, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/content/"+hash)
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetResult(&content).Get(url)
+
+	return content, decodeError(res, err)
+}
+
+// CreateUpload открывает на сервере возобновляемую загрузку файла размером size.
+func (s *HTTPService) CreateUpload(accessToken string, size int64) (upload smodel.Upload, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/upload")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(smodel.Upload{Size: size}).SetResult(&upload).Post(url)
+
+	return upload, decodeError(res, err)
+}
+
+// FindUpload загрузка с текущим смещением на сервере.
+func (s *HTTPService) FindUpload(accessToken string, uploadID string) (upload smodel.Upload, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/upload/"+uploadID)
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetResult(&upload).Get(url)
+
+	return upload, decodeError(res, err)
+}
+
+// PutUploadChunk передает часть файла со смещения offset и возвращает загрузку с новым смещением.
+func (s *HTTPService) PutUploadChunk(accessToken string, uploadID string, offset int64, chunk []byte) (upload smodel.Upload, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/upload/"+uploadID)
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().
+		SetHeader("Content-Type", "application/octet-stream").
+		SetQueryParam("offset", strconv.FormatInt(offset, 10)).
+		SetBody(chunk).
+		SetResult(&upload).
+		Put(url)
+
+	return upload, decodeError(res, err)
+}
+
+// CompleteUpload завершает загрузку, сервер сверяет хеш SHA-256 содержимого.
+func (s *HTTPService) CompleteUpload(accessToken string, uploadID string, hash string) (content smodel.Content, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/upload/"+uploadID+"/complete")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(smodel.Content{SHA256: hash}).SetResult(&content).Post(url)
+
+	return content, decodeError(res, err)
+}
+
+// DeleteUpload прерывает загрузку на сервере.
+func (s *HTTPService) DeleteUpload(accessToken string, uploadID string) error {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/upload/"+uploadID)
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().Delete(url)
+
+	return decodeError(res, err)
+}
+
+// uploadContent загружает содержимое файла частями. Идентификатор загрузки сохраняется в UploadStore,
+// поэтому прерванная загрузка того же содержимого продолжается с принятого сервером смещения.
+func (s *HTTPService) uploadContent(accessToken string, path string, hash string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	upload, err := s.resumeUpload(accessToken, hash, info.Size())
+	if err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 0, upload.ChunkSize)
+	for upload.Offset < upload.Size {
+		chunk = chunk[:upload.NextChunk()]
+		if _, err = f.ReadAt(chunk, upload.Offset); err != nil {
+			return err
+		}
+
+		next, err := s.PutUploadChunk(accessToken, upload.ID, upload.Offset, chunk)
+		if errors.Is(err, ErrStatusUploadOffset) {
+			// часть уже принята, например ответ на прошлый запрос не дошел: продолжаем со смещения сервера
+			next, err = s.FindUpload(accessToken, upload.ID)
+			if err == nil && next.Offset == upload.Offset {
+				err = ErrStatusUploadOffset
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+		upload = next
+	}
+
+	_, err = s.CompleteUpload(accessToken, upload.ID, hash)
+	if err == nil || errors.Is(err, ErrStatusChecksum) {
+		// после завершения или несовпадения хеша загрузки на сервере больше нет
+		if errDel := s.uploads.DeleteUploadID(hash); errDel != nil {
+			logger.Error("HTTPService.uploadContent: ", errDel)
+		}
+	}
+
+	return err
+}
+
+// resumeUpload продолжает сохраненную загрузку содержимого с хешем hash или открывает новую.
+func (s *HTTPService) resumeUpload(accessToken string, hash string, size int64) (upload smodel.Upload, err error) {
+	uploadID, err := s.uploads.GetUploadID(hash)
+	if err != nil {
+		return upload, err
+	}
+
+	if uploadID != "" {
+		upload, err = s.FindUpload(accessToken, uploadID)
+		if err == nil && upload.Size == size {
+			return upload, nil
+		}
+
+		// загрузка истекла на сервере или начата для файла другого размера
+		if err != nil && !errors.Is(err, ErrStatusNotFound) {
+			return upload, err
+		}
+	}
+
+	upload, err = s.CreateUpload(accessToken, size)
+	if err != nil {
+		return upload, err
+	}
+
+	return upload, s.uploads.SetUploadID(hash, upload.ID)
+}
+
+// fileSHA256 хеш SHA-256 содержимого файла в шестнадцатеричном виде.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// joinIDs идентификаторы через запятую для полей multipart-формы.
+func joinIDs(ids []int) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (s *HTTPService) GetFolderList(accessToken string) (items []*model.Folder, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/folder/list")
+
+	s.client.SetAuthToken(accessToken)
+
+	res, err := s.newRequest().
+		SetResult(&items).
+		Get(url)
+
+	return items, decodeError(res, err)
+}
+
+func (s *HTTPService) AddFolder(accessToken string, folder smodel.Folder) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/folder")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(folder).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteFolder(accessToken string, extID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/folder")
+
+	folder := smodel.Folder{ID: extID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(folder).Delete(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) GetTagList(accessToken string) (items []*model.Tag, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/tag/list")
+
+	s.client.SetAuthToken(accessToken)
+
+	res, err := s.newRequest().
+		SetResult(&items).
+		Get(url)
+
+	return items, decodeError(res, err)
+}
+
+func (s *HTTPService) AddTag(accessToken string, tag smodel.Tag) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/tag")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(tag).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteTag(accessToken string, extID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/tag")
+
+	tag := smodel.Tag{ID: extID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(tag).Delete(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) GetTemplateList(accessToken string) (items []*model.Template, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/template/list")
+
+	s.client.SetAuthToken(accessToken)
+
+	res, err := s.newRequest().
+		SetResult(&items).
+		Get(url)
+
+	return items, decodeError(res, err)
+}
+
+func (s *HTTPService) AddTemplate(accessToken string, tpl smodel.Template) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/template")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(tpl).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteTemplate(accessToken string, extID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/template")
+
+	tpl := smodel.Template{ID: extID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(tpl).Delete(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) GetAttachmentList(accessToken string) (items []*model.Attachment, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/attachment/list")
+
+	s.client.SetAuthToken(accessToken)
+
+	res, err := s.newRequest().
+		SetResult(&items).
+		Get(url)
+
+	return items, decodeError(res, err)
+}
+
+// AddAttachment прикрепляет к записи файл; att.Path - путь к зашифрованному содержимому на диске клиента.
+func (s *HTTPService) AddAttachment(accessToken string, att smodel.Attachment) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/attachment")
+
 	s.client.SetAuthToken(accessToken)
 
-	// Multipart of form fields and files
-	res, err := s.client.R().
+	res, err := s.newRequest().
 		SetFiles(map[string]string{
-			"file": file.Path,
+			"file": att.Path,
 		}).
 		SetFormData(map[string]string{
-			"id":         strconv.Itoa(file.ID),
-			"title":      file.Title,
-			"meta":       file.Meta,
-			"updated_at": file.UpdatedAt.Format(time.RFC3339),
+			"item_type":  att.ItemType,
+			"item_id":    strconv.Itoa(att.ItemID),
+			"filename":   att.Filename,
+			"updated_at": att.UpdatedAt.Format(time.RFC3339),
 		}).SetResult(&rb).Post(url)
 
-	switch res.StatusCode() {
-	case http.StatusUnauthorized:
-		return rb.ID, ErrStatusUnauthorized
-	default:
-		return rb.ID, err
-	}
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteAttachment(accessToken string, extID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/attachment")
+
+	att := smodel.Attachment{ID: extID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(att).Delete(url)
+
+	return decodeError(res, err)
+}
+
+// GetUserKeys пара ключей пользователя для обмена записями; ErrStatusNotFound, если ключи еще не созданы.
+func (s *HTTPService) GetUserKeys(accessToken string) (keys smodel.UserKeys, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/account/keys")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetResult(&keys).Get(url)
+
+	return keys, decodeError(res, err)
+}
+
+func (s *HTTPService) SaveUserKeys(accessToken string, keys smodel.UserKeys) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/account/keys")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(keys).Post(url)
+
+	return decodeError(res, err)
+}
+
+// GetUsage использование хранилища пользователем и его квоты.
+func (s *HTTPService) GetUsage(accessToken string) (usage smodel.Usage, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/account/usage")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetResult(&usage).Get(url)
+
+	return usage, decodeError(res, err)
+}
+
+// GetPublicKey открытый ключ пользователя по логину.
+func (s *HTTPService) GetPublicKey(accessToken string, login string) (key smodel.PublicKey, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/account/keys/public")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetQueryParam("login", login).SetResult(&key).Get(url)
+
+	return key, decodeError(res, err)
+}
+
+func (s *HTTPService) GetShareList(accessToken string) (items []smodel.Share, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/share/list")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetResult(&items).Get(url)
+
+	return items, decodeError(res, err)
+}
+
+func (s *HTTPService) GetIncomingShareList(accessToken string) (items []smodel.Share, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/share/incoming")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetResult(&items).Get(url)
+
+	return items, decodeError(res, err)
+}
+
+func (s *HTTPService) AddShare(accessToken string, share smodel.Share) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/share")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(share).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteShare(accessToken string, extID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/share")
+
+	share := smodel.Share{ID: extID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(share).Delete(url)
+
+	return decodeError(res, err)
+}
+
+// UpdateIncomingShare сохраняет открытую пользователю запись; ErrStatusForbidden при доступе только на чтение.
+func (s *HTTPService) UpdateIncomingShare(accessToken string, share smodel.Share) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/store/share/incoming")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(share).Post(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) GetOrganizationList(accessToken string) (items []smodel.Organization, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org/list")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetResult(&items).Get(url)
+
+	return items, decodeError(res, err)
+}
+
+// SaveOrganization создает организацию (org.ID == 0) или переименовывает ее.
+func (s *HTTPService) SaveOrganization(accessToken string, org smodel.Organization) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(org).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteOrganization(accessToken string, orgID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org")
+
+	org := smodel.Organization{ID: orgID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(org).Delete(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) AcceptInvite(accessToken string, orgID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org/accept")
+
+	org := smodel.Organization{ID: orgID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(org).Post(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) GetMemberList(accessToken string, orgID int) (items []smodel.Member, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org/member/list")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetQueryParam("org_id", strconv.Itoa(orgID)).SetResult(&items).Get(url)
+
+	return items, decodeError(res, err)
+}
+
+// InviteMember приглашает пользователя в организацию или меняет роль участника.
+func (s *HTTPService) InviteMember(accessToken string, member smodel.Member) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org/member")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(member).Post(url)
+
+	return decodeError(res, err)
+}
+
+// RemoveMember исключает участника; с собственным логином - отказ от приглашения или выход из организации.
+func (s *HTTPService) RemoveMember(accessToken string, orgID int, login string) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org/member")
+
+	member := smodel.Member{OrgID: orgID, Login: login}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(member).Delete(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) GetCollectionList(accessToken string) (items []smodel.Collection, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org/collection/list")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetResult(&items).Get(url)
+
+	return items, decodeError(res, err)
+}
+
+func (s *HTTPService) SaveCollection(accessToken string, col smodel.Collection) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org/collection")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(col).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteCollection(accessToken string, colID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org/collection")
+
+	col := smodel.Collection{ID: colID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(col).Delete(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) GetCollectionItemList(accessToken string, colID int) (items []smodel.CollectionItem, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org/collection/item/list")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetQueryParam("collection_id", strconv.Itoa(colID)).SetResult(&items).Get(url)
+
+	return items, decodeError(res, err)
+}
+
+// SaveCollectionItem сохраняет запись коллекции; ErrStatusForbidden при роли read-only.
+func (s *HTTPService) SaveCollectionItem(accessToken string, item smodel.CollectionItem) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org/collection/item")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(item).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteCollectionItem(accessToken string, itemID, colID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/org/collection/item")
+
+	item := smodel.CollectionItem{ID: itemID, CollectionID: colID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(item).Delete(url)
+
+	return decodeError(res, err)
+}
+
+// GetEmergencyAccessList доверенные лица пользователя с состоянием экстренного доступа.
+func (s *HTTPService) GetEmergencyAccessList(accessToken string) (items []smodel.EmergencyAccess, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/emergency/list")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetResult(&items).Get(url)
+
+	return items, decodeError(res, err)
+}
+
+// GetIncomingEmergencyAccessList пользователи, назначившие пользователя доверенным лицом.
+func (s *HTTPService) GetIncomingEmergencyAccessList(accessToken string) (items []smodel.EmergencyAccess, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/emergency/incoming")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetResult(&items).Get(url)
+
+	return items, decodeError(res, err)
+}
+
+// SaveEmergencyAccess назначает доверенное лицо или меняет период ожидания.
+func (s *HTTPService) SaveEmergencyAccess(accessToken string, access smodel.EmergencyAccess) (id int, err error) {
+	var rb ResponseID
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/emergency")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(access).SetResult(&rb).Post(url)
+
+	return rb.ID, decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteEmergencyAccess(accessToken string, accessID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/emergency")
+
+	access := smodel.EmergencyAccess{ID: accessID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(access).Delete(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) RequestEmergencyAccess(accessToken string, accessID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/emergency/request")
+
+	access := smodel.EmergencyAccess{ID: accessID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(access).Post(url)
+
+	return decodeError(res, err)
+}
+
+func (s *HTTPService) RejectEmergencyAccess(accessToken string, accessID int) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/emergency/reject")
+
+	access := smodel.EmergencyAccess{ID: accessID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(access).Post(url)
+
+	return decodeError(res, err)
+}
+
+// GetEmergencyVault зашифрованные записи владельца по открытому экстренному доступу.
+func (s *HTTPService) GetEmergencyVault(accessToken string, accessID int) (vault model.EmergencyVault, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/emergency/vault")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetQueryParam("id", strconv.Itoa(accessID)).SetResult(&vault).Get(url)
+
+	return vault, decodeError(res, err)
+}
+
+// CreateSecret создает одноразовый секрет; возвращает секрет с идентификатором для ссылки.
+func (s *HTTPService) CreateSecret(accessToken string, secret smodel.Secret) (created smodel.Secret, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/secret")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(secret).SetResult(&created).Post(url)
+
+	return created, decodeError(res, err)
+}
+
+func (s *HTTPService) GetSecretList(accessToken string) (items []smodel.Secret, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/secret/list")
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetResult(&items).Get(url)
+
+	return items, decodeError(res, err)
+}
+
+func (s *HTTPService) DeleteSecret(accessToken string, secretID string) (err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/secret")
+
+	secret := smodel.Secret{ID: secretID}
+
+	s.client.SetAuthToken(accessToken)
+	res, err := s.newRequest().SetBody(secret).Delete(url)
+
+	return decodeError(res, err)
+}
+
+// OpenSecret просматривает одноразовый секрет по идентификатору из ссылки; авторизация не требуется.
+func (s *HTTPService) OpenSecret(secretID string) (secret smodel.Secret, err error) {
+	url := fmt.Sprintf("%s://%s%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, "/s/"+secretID)
+
+	res, err := s.newRequest().SetResult(&secret).Post(url)
+
+	return secret, decodeError(res, err)
+}
+
+// SecretLink ссылка на страницу секрета; key передается во фрагменте и на сервер не отправляется.
+func (s *HTTPService) SecretLink(secretID, key string) string {
+	return fmt.Sprintf("%s://%s/s/%s#%s", s.cfg.ServerProtocol, s.cfg.ServerAddress, secretID, key)
 }
@@ -1,77 +1,875 @@
 package service
 
 import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/client/config"
+	"github.com/rainset/gophkeeper/internal/client/model"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/testserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestHTTPService_AddCard(t *testing.T) {
-	t.Skipped()
+// newTestHTTPService возвращает клиента, подключенного к серверу testserver.
+func newTestHTTPService(t *testing.T) *HTTPService {
+	t.Helper()
+
+	srv := testserver.New(t)
+
+	return NewHTTPService(&config.Config{ServerAddress: srv.Address(), ServerProtocol: "https"})
 }
 
-func TestHTTPService_AddCred(t *testing.T) {
-	t.Skipped()
+// signUp регистрирует пользователя и возвращает токены.
+func signUp(t *testing.T, s *HTTPService) model.Tokens {
+	t.Helper()
+
+	tokens, err := s.SignUp(model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	return tokens
 }
 
-func TestHTTPService_AddFile(t *testing.T) {
-	t.Skipped()
+func TestNewHTTPService(t *testing.T) {
+	cfg := &config.Config{ServerAddress: "localhost:8080", ServerProtocol: "https"}
+	s := NewHTTPService(cfg)
+	assert.Equal(t, cfg, s.cfg)
+	assert.NotNil(t, s.client)
 }
 
-func TestHTTPService_AddText(t *testing.T) {
-	t.Skipped()
+func TestHTTPService_SignUp(t *testing.T) {
+	s := newTestHTTPService(t)
+
+	tokens := signUp(t, s)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	tests := []struct {
+		name    string
+		user    model.User
+		wantErr error
+	}{
+		{name: "login exists", user: model.User{Login: "user", Password: "password"}, wantErr: ErrStatusLoginExists},
+		{name: "empty password", user: model.User{Login: "other"}, wantErr: ErrStatusValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.SignUp(tt.user)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestHTTPService_SignIn(t *testing.T) {
+	s := newTestHTTPService(t)
+	signUp(t, s)
+
+	tests := []struct {
+		name    string
+		user    model.User
+		wantErr error
+	}{
+		{name: "ok", user: model.User{Login: "user", Password: "password"}},
+		{name: "wrong password", user: model.User{Login: "user", Password: "wrong"}, wantErr: ErrStatusUnauthorized},
+		{name: "unknown login", user: model.User{Login: "unknown", Password: "password"}, wantErr: ErrStatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := s.SignIn(tt.user)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, tokens.AccessToken)
+		})
+	}
+}
+
+func TestHTTPService_PostRefreshToken(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	newTokens, err := s.PostRefreshToken(tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEmpty(t, newTokens.AccessToken)
+	assert.NotEmpty(t, newTokens.RefreshToken)
+
+	_, err = s.PostRefreshToken("wrong")
+	assert.ErrorIs(t, err, ErrStatusUnauthorized)
+}
+
+func TestHTTPService_GetSignKey(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	signKey, err := s.GetSignKey(tokens.AccessToken, "user", "password")
+	require.NoError(t, err)
+	assert.NotEmpty(t, signKey)
+
+	_, err = s.GetSignKey(tokens.AccessToken, "user", "wrong")
+	assert.ErrorIs(t, err, ErrStatusUnauthorized)
+}
+
+func TestHTTPService_AddCard(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	card := smodel.DataCard{Title: "title", Number: "number", Date: "date", Cvv: "cvv", Meta: "meta", UpdatedAt: time.Now()}
+
+	id, err := s.AddCard(tokens.AccessToken, card)
+	require.NoError(t, err)
+	assert.NotZero(t, id)
+
+	card.ID, card.Title = id, "new title"
+	updatedID, err := s.AddCard(tokens.AccessToken, card)
+	require.NoError(t, err)
+	assert.Equal(t, id, updatedID)
+
+	_, err = s.AddCard(tokens.AccessToken, smodel.DataCard{Title: "title", UpdatedAt: time.Now()})
+	assert.ErrorIs(t, err, ErrStatusValidation)
+
+	_, err = s.AddCard("wrong", card)
+	assert.ErrorIs(t, err, ErrStatusUnauthorized)
+}
+
+func TestHTTPService_GetCardList(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	items, err := s.GetCardList(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	id, err := s.AddCard(tokens.AccessToken, smodel.DataCard{Title: "title", Number: "number", Date: "date", Cvv: "cvv", Meta: "meta", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	items, err = s.GetCardList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, id, items[0].ExternalID)
+	assert.Equal(t, "number", items[0].Number)
 }
 
 func TestHTTPService_DeleteCard(t *testing.T) {
-	t.Skipped()
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	id, err := s.AddCard(tokens.AccessToken, smodel.DataCard{Title: "title", Number: "number", Date: "date", Cvv: "cvv", Meta: "meta", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteCard(tokens.AccessToken, id))
+	assert.ErrorIs(t, s.DeleteCard(tokens.AccessToken, id), ErrStatusNotFound)
+}
+
+func TestHTTPService_AddCred(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	id, err := s.AddCred(tokens.AccessToken, smodel.DataCred{Title: "title", Username: "username", Password: "password", Meta: "meta", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+	assert.NotZero(t, id)
+}
+
+func TestHTTPService_GetCredList(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	id, err := s.AddCred(tokens.AccessToken, smodel.DataCred{Title: "title", Username: "username", Password: "password", Meta: "meta", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	items, err := s.GetCredList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, id, items[0].ExternalID)
+	assert.Equal(t, "username", items[0].Username)
 }
 
 func TestHTTPService_DeleteCred(t *testing.T) {
-	t.Skipped()
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	id, err := s.AddCred(tokens.AccessToken, smodel.DataCred{Title: "title", Username: "username", Password: "password", Meta: "meta", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteCred(tokens.AccessToken, id))
+	assert.ErrorIs(t, s.DeleteCred(tokens.AccessToken, id), ErrStatusNotFound)
 }
 
-func TestHTTPService_DeleteFile(t *testing.T) {
-	t.Skipped()
+func TestHTTPService_AddText(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	id, err := s.AddText(tokens.AccessToken, smodel.DataText{Title: "title", Text: "text", Meta: "meta", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+	assert.NotZero(t, id)
+}
+
+func TestHTTPService_GetTextList(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	id, err := s.AddText(tokens.AccessToken, smodel.DataText{Title: "title", Text: "text", Meta: "meta", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	items, err := s.GetTextList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, id, items[0].ExternalID)
+	assert.Equal(t, "text", items[0].Text)
 }
 
 func TestHTTPService_DeleteText(t *testing.T) {
-	t.Skipped()
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	id, err := s.AddText(tokens.AccessToken, smodel.DataText{Title: "title", Text: "text", Meta: "meta", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteText(tokens.AccessToken, id))
+	assert.ErrorIs(t, s.DeleteText(tokens.AccessToken, id), ErrStatusNotFound)
 }
 
-func TestHTTPService_DownloadFile(t *testing.T) {
-	t.Skipped()
+func TestHTTPService_SSHKeys(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	key := smodel.DataSSHKey{
+		Title:       "title",
+		PrivateKey:  "private",
+		PublicKey:   "ssh-ed25519 AAAA",
+		Fingerprint: "SHA256:abc",
+		UpdatedAt:   time.Now(),
+	}
+	id, err := s.AddSSHKey(tokens.AccessToken, key)
+	require.NoError(t, err)
+
+	items, err := s.GetSSHKeyList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, id, items[0].ExternalID)
+	assert.Equal(t, "ssh-ed25519 AAAA", items[0].PublicKey)
+	assert.Equal(t, "SHA256:abc", items[0].Fingerprint)
+
+	require.NoError(t, s.DeleteSSHKey(tokens.AccessToken, id))
+	assert.ErrorIs(t, s.DeleteSSHKey(tokens.AccessToken, id), ErrStatusNotFound)
 }
 
-func TestHTTPService_GetCardList(t *testing.T) {
-	t.Skipped()
+// addTestFile загружает на сервер файл с содержимым content.
+func addTestFile(t *testing.T, s *HTTPService, accessToken, content string) int {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	id, err := s.AddFile(accessToken, smodel.DataFile{Title: "title", Path: path, Meta: "meta", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	return id
 }
 
-func TestHTTPService_GetCredList(t *testing.T) {
-	t.Skipped()
+func TestHTTPService_Identities(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	doc := smodel.DataIdentity{
+		Title:     "title",
+		Kind:      smodel.IdentityKindPassport,
+		FullName:  "name",
+		Number:    "number",
+		UpdatedAt: time.Now(),
+	}
+	id, err := s.AddIdentity(tokens.AccessToken, doc)
+	require.NoError(t, err)
+
+	items, err := s.GetIdentityList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, id, items[0].ExternalID)
+	assert.Equal(t, smodel.IdentityKindPassport, items[0].Kind)
+
+	_, err = s.AddIdentity(tokens.AccessToken, smodel.DataIdentity{Title: "title", Kind: smodel.IdentityKindIDCard, FullName: "name"})
+	assert.ErrorIs(t, err, ErrStatusValidation)
+
+	require.NoError(t, s.DeleteIdentity(tokens.AccessToken, id))
+	assert.ErrorIs(t, s.DeleteIdentity(tokens.AccessToken, id), ErrStatusNotFound)
+}
+
+func TestHTTPService_CustomItems(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	tplID, err := s.AddTemplate(tokens.AccessToken, smodel.Template{Name: "name", Schema: "schema", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	templates, err := s.GetTemplateList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	assert.Equal(t, tplID, templates[0].ExternalID)
+
+	id, err := s.AddCustomItem(tokens.AccessToken, smodel.DataCustom{TemplateID: tplID, Title: "title", Values: "values", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	items, err := s.GetCustomItemList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, id, items[0].ExternalID)
+	assert.Equal(t, tplID, items[0].TemplateID)
+
+	_, err = s.AddCustomItem(tokens.AccessToken, smodel.DataCustom{TemplateID: tplID + 1, Title: "title", Values: "values"})
+	assert.ErrorIs(t, err, ErrStatusValidation)
+
+	require.NoError(t, s.DeleteCustomItem(tokens.AccessToken, id))
+	assert.ErrorIs(t, s.DeleteCustomItem(tokens.AccessToken, id), ErrStatusNotFound)
+
+	// удаление шаблона удаляет записи по нему
+	_, err = s.AddCustomItem(tokens.AccessToken, smodel.DataCustom{TemplateID: tplID, Title: "title", Values: "values", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteTemplate(tokens.AccessToken, tplID))
+
+	items, err = s.GetCustomItemList(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestHTTPService_AddFile(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	id := addTestFile(t, s, tokens.AccessToken, "Hello, world!")
+	assert.NotZero(t, id)
 }
 
 func TestHTTPService_GetFileList(t *testing.T) {
-	t.Skipped()
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	id := addTestFile(t, s, tokens.AccessToken, "Hello, world!")
+
+	items, err := s.GetFileList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, id, items[0].ExternalID)
+	assert.Equal(t, "file.txt", items[0].Filename)
+	assert.NotEmpty(t, items[0].Path)
 }
 
-func TestHTTPService_GetSignKey(t *testing.T) {
-	t.Skipped()
+func TestHTTPService_DownloadFile(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	addTestFile(t, s, tokens.AccessToken, "Hello, world!")
+
+	items, err := s.GetFileList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	r, err := s.DownloadFile(tokens.AccessToken, items[0].Path)
+	require.NoError(t, err)
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, world!", string(content))
 }
 
-func TestHTTPService_GetTextList(t *testing.T) {
-	t.Skipped()
+func TestHTTPService_DownloadFileTo(t *testing.T) {
+	const content = "Hello, world!"
+
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	addTestFile(t, s, tokens.AccessToken, content)
+
+	items, err := s.GetFileList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, int64(len(content)), items[0].Size)
+	require.NotEmpty(t, items[0].SHA256)
+
+	tests := []struct {
+		name    string
+		partial string
+		hash    string
+		want    string
+		wantErr error
+	}{
+		{name: "full", hash: items[0].SHA256, want: content},
+		{name: "resume", partial: "Hello", hash: items[0].SHA256, want: content},
+		{name: "already downloaded", partial: content, hash: items[0].SHA256, want: content},
+		{name: "partial longer than file", partial: content + "!!!", hash: items[0].SHA256, want: content},
+		{name: "without hash", partial: "garbage", want: content},
+		{name: "checksum mismatch", partial: "Hello", hash: strings.Repeat("0", 64), wantErr: ErrStatusChecksum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "partial")
+			require.NoError(t, os.WriteFile(path, []byte(tt.partial), 0600))
+
+			f, err := os.OpenFile(path, os.O_RDWR, 0600)
+			require.NoError(t, err)
+			defer f.Close()
+
+			var last int64
+			err = s.DownloadFileTo(tokens.AccessToken, items[0].Path, f, tt.hash, func(n int64) { last = n })
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+			require.NoError(t, err)
+
+			got, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, int64(len(content)), last)
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		f, err := os.Create(filepath.Join(t.TempDir(), "partial"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		err = s.DownloadFileTo(tokens.AccessToken, "_file_storage/missing", f, "", nil)
+		assert.ErrorIs(t, err, ErrStatusNotFound)
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		f, err := os.Create(filepath.Join(t.TempDir(), "partial"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		other, err := s.SignUp(model.User{Login: "other", Password: "password"})
+		require.NoError(t, err)
+
+		err = s.DownloadFileTo(other.AccessToken, items[0].Path, f, items[0].SHA256, nil)
+		assert.ErrorIs(t, err, ErrStatusNotFound)
+	})
 }
 
-func TestHTTPService_PostRefreshToken(t *testing.T) {
-	t.Skipped()
+func TestHTTPService_DeleteFile(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	id := addTestFile(t, s, tokens.AccessToken, "Hello, world!")
+
+	require.NoError(t, s.DeleteFile(tokens.AccessToken, id))
+	assert.ErrorIs(t, s.DeleteFile(tokens.AccessToken, id), ErrStatusNotFound)
 }
 
-func TestHTTPService_SignIn(t *testing.T) {
-	t.Skipped()
+func TestHTTPService_FileDedup(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	// sha256("Hello, world!")
+	const hash = "315f5bdb76d078c43b8ac0064e4a0164612b1fce77c869345bfc94c75894edd3"
+
+	_, err := s.FindContent(tokens.AccessToken, hash)
+	assert.ErrorIs(t, err, ErrStatusNotFound)
+	_, err = s.FindContent(tokens.AccessToken, "not-a-hash")
+	assert.ErrorIs(t, err, ErrStatusBadRequest)
+
+	first := addTestFile(t, s, tokens.AccessToken, "Hello, world!")
+
+	content, err := s.FindContent(tokens.AccessToken, hash)
+	require.NoError(t, err)
+	assert.Equal(t, smodel.Content{SHA256: hash, Size: 13}, content)
+
+	// повторная загрузка того же содержимого передает только хеш
+	addTestFile(t, s, tokens.AccessToken, "Hello, world!")
+
+	items, err := s.GetFileList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, items[0].Path, items[1].Path)
+	assert.Equal(t, "file.txt", items[1].Filename)
+
+	// содержимое удаляется вместе с последней ссылкающейся на него записью
+	require.NoError(t, s.DeleteFile(tokens.AccessToken, first))
+	_, err = s.FindContent(tokens.AccessToken, hash)
+	require.NoError(t, err)
+
+	for _, v := range items {
+		if v.ExternalID != first {
+			require.NoError(t, s.DeleteFile(tokens.AccessToken, v.ExternalID))
+		}
+	}
+	_, err = s.FindContent(tokens.AccessToken, hash)
+	assert.ErrorIs(t, err, ErrStatusNotFound)
 }
 
-func TestHTTPService_SignUp(t *testing.T) {
-	t.Skipped()
+func TestHTTPService_ResumeUpload(t *testing.T) {
+	srv := testserver.New(t)
+	srv.Cfg.UploadChunkSize = 4
+	cfg := &config.Config{ServerAddress: srv.Address(), ServerProtocol: "https"}
+	uploads := newMemoryUploads()
+
+	s := NewHTTPService(cfg)
+	s.SetUploadStore(uploads)
+	tokens := signUp(t, s)
+
+	const content = "Hello, world!"
+	// sha256("Hello, world!")
+	const hash = "315f5bdb76d078c43b8ac0064e4a0164612b1fce77c869345bfc94c75894edd3"
+
+	// загрузка прервалась после первой части
+	upload, err := s.resumeUpload(tokens.AccessToken, hash, int64(len(content)))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), upload.ChunkSize)
+	upload, err = s.PutUploadChunk(tokens.AccessToken, upload.ID, 0, []byte(content[:4]))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), upload.Offset)
+
+	_, err = s.PutUploadChunk(tokens.AccessToken, upload.ID, 0, []byte(content[:4]))
+	assert.ErrorIs(t, err, ErrStatusUploadOffset)
+	_, err = s.CompleteUpload(tokens.AccessToken, upload.ID, hash)
+	assert.ErrorIs(t, err, ErrStatusIncomplete)
+
+	// после перезапуска клиент продолжает ту же загрузку
+	s = NewHTTPService(cfg)
+	s.SetUploadStore(uploads)
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	_, err = s.AddFile(tokens.AccessToken, smodel.DataFile{Title: "file", Path: path, UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	upload, err = s.FindUpload(tokens.AccessToken, upload.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hash, upload.SHA256)
+	uploadID, err := uploads.GetUploadID(hash)
+	require.NoError(t, err)
+	assert.Empty(t, uploadID)
+
+	items, err := s.GetFileList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	r, err := s.DownloadFile(tokens.AccessToken, items[0].Path)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
 }
 
-func TestNewHTTPService(t *testing.T) {
-	t.Skipped()
+func TestHTTPService_CompleteUpload_Checksum(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	upload, err := s.CreateUpload(tokens.AccessToken, 4)
+	require.NoError(t, err)
+	_, err = s.PutUploadChunk(tokens.AccessToken, upload.ID, 0, []byte("data"))
+	require.NoError(t, err)
+
+	_, err = s.CompleteUpload(tokens.AccessToken, upload.ID, strings.Repeat("0", 64))
+	assert.ErrorIs(t, err, ErrStatusChecksum)
+	assert.ErrorIs(t, s.DeleteUpload(tokens.AccessToken, upload.ID), ErrStatusNotFound)
+}
+
+func TestHTTPService_Usage(t *testing.T) {
+	srv := testserver.New(t)
+	srv.Cfg.QuotaFileSize = 16
+	srv.Cfg.QuotaItems = 2
+	s := NewHTTPService(&config.Config{ServerAddress: srv.Address(), ServerProtocol: "https"})
+	tokens := signUp(t, s)
+
+	addTestFile(t, s, tokens.AccessToken, "Hello, world!")
+
+	path := filepath.Join(t.TempDir(), "big.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("a", 17)), 0600))
+	_, err := s.AddFile(tokens.AccessToken, smodel.DataFile{Title: "big", Path: path, UpdatedAt: time.Now()})
+	assert.ErrorIs(t, err, ErrStatusQuota)
+
+	text := smodel.DataText{Title: "title", Text: "text", UpdatedAt: time.Now()}
+	_, err = s.AddText(tokens.AccessToken, text)
+	require.NoError(t, err)
+	_, err = s.AddText(tokens.AccessToken, text)
+	assert.ErrorIs(t, err, ErrStatusQuota)
+
+	files, err := s.GetFileList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	usage, err := s.GetUsage(tokens.AccessToken)
+	require.NoError(t, err)
+	file := smodel.DataFile{Title: files[0].Title, Filename: files[0].Filename, Meta: files[0].Meta, Size: files[0].Size}
+	assert.Equal(t, smodel.Usage{Bytes: file.Size + file.PayloadSize() + text.PayloadSize(), Items: 2, MaxFileSize: 16, MaxItems: 2}, usage)
+}
+
+func TestHTTPService_Attachments(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	cardID, err := s.AddCard(tokens.AccessToken, smodel.DataCard{Title: "title", Number: "1234", Date: "12/30", Cvv: "123", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "scan")
+	require.NoError(t, os.WriteFile(path, []byte("encrypted"), 0600))
+
+	att := smodel.Attachment{ItemType: smodel.ItemTypeCard, ItemID: cardID, Filename: "scan.png", Path: path, UpdatedAt: time.Now()}
+	id, err := s.AddAttachment(tokens.AccessToken, att)
+	require.NoError(t, err)
+
+	items, err := s.GetAttachmentList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, id, items[0].ExternalID)
+	assert.Equal(t, smodel.ItemTypeCard, items[0].ItemType)
+	assert.Equal(t, cardID, items[0].ItemID)
+	assert.Equal(t, "scan.png", items[0].Filename)
+
+	r, err := s.DownloadFile(tokens.AccessToken, items[0].Path)
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "encrypted", string(content))
+
+	att.ItemID = cardID + 1
+	_, err = s.AddAttachment(tokens.AccessToken, att)
+	assert.ErrorIs(t, err, ErrStatusValidation)
+
+	require.NoError(t, s.DeleteAttachment(tokens.AccessToken, id))
+	assert.ErrorIs(t, s.DeleteAttachment(tokens.AccessToken, id), ErrStatusNotFound)
+
+	// вложения удаляются вместе с записью
+	att.ItemID = cardID
+	_, err = s.AddAttachment(tokens.AccessToken, att)
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteCard(tokens.AccessToken, cardID))
+
+	items, err = s.GetAttachmentList(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestHTTPService_Shares(t *testing.T) {
+	s := newTestHTTPService(t)
+	owner := signUp(t, s)
+	recipient, err := s.SignUp(model.User{Login: "recipient", Password: "password"})
+	require.NoError(t, err)
+
+	_, err = s.GetUserKeys(owner.AccessToken)
+	assert.ErrorIs(t, err, ErrStatusNotFound)
+
+	for _, tokens := range []model.Tokens{owner, recipient} {
+		require.NoError(t, s.SaveUserKeys(tokens.AccessToken, smodel.UserKeys{PublicKey: "public", PrivateKey: "private", UpdatedAt: time.Now()}))
+	}
+
+	keys, err := s.GetUserKeys(owner.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "private", keys.PrivateKey)
+
+	pub, err := s.GetPublicKey(owner.AccessToken, "recipient")
+	require.NoError(t, err)
+	assert.Equal(t, "public", pub.PublicKey)
+
+	_, err = s.GetPublicKey(owner.AccessToken, "unknown")
+	assert.ErrorIs(t, err, ErrStatusNotFound)
+
+	credID, err := s.AddCred(owner.AccessToken, smodel.DataCred{Title: "title", Username: "user", Password: "pass", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	share := smodel.Share{Recipient: "recipient", ItemType: smodel.ItemTypeCred, ItemID: credID, Access: smodel.ShareAccessRead,
+		ItemKey: "item key", OwnerKey: "owner key", Data: "data", UpdatedAt: time.Now()}
+	id, err := s.AddShare(owner.AccessToken, share)
+	require.NoError(t, err)
+
+	items, err := s.GetIncomingShareList(recipient.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "user", items[0].Owner)
+	assert.Equal(t, "item key", items[0].ItemKey)
+
+	err = s.UpdateIncomingShare(recipient.AccessToken, smodel.Share{ID: id, Data: "changed", UpdatedAt: time.Now()})
+	assert.ErrorIs(t, err, ErrStatusForbidden)
+
+	share.ID = id
+	share.Access = smodel.ShareAccessWrite
+	_, err = s.AddShare(owner.AccessToken, share)
+	require.NoError(t, err)
+	require.NoError(t, s.UpdateIncomingShare(recipient.AccessToken, smodel.Share{ID: id, Data: "changed", UpdatedAt: time.Now()}))
+
+	items, err = s.GetShareList(owner.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "recipient", items[0].Recipient)
+	assert.Equal(t, "changed", items[0].Data)
+
+	require.NoError(t, s.DeleteShare(owner.AccessToken, id))
+
+	items, err = s.GetIncomingShareList(recipient.AccessToken)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestHTTPService_Organizations(t *testing.T) {
+	s := newTestHTTPService(t)
+	owner := signUp(t, s)
+	reader, err := s.SignUp(model.User{Login: "reader", Password: "password"})
+	require.NoError(t, err)
+
+	for _, tokens := range []model.Tokens{owner, reader} {
+		require.NoError(t, s.SaveUserKeys(tokens.AccessToken, smodel.UserKeys{PublicKey: "public", PrivateKey: "private", UpdatedAt: time.Now()}))
+	}
+
+	orgID, err := s.SaveOrganization(owner.AccessToken, smodel.Organization{Name: "team"})
+	require.NoError(t, err)
+
+	colID, err := s.SaveCollection(owner.AccessToken, smodel.Collection{OrgID: orgID, Name: "prod",
+		Keys: []smodel.CollectionKey{{Login: "user", Key: "owner key"}}})
+	require.NoError(t, err)
+
+	require.NoError(t, s.InviteMember(owner.AccessToken, smodel.Member{OrgID: orgID, Login: "reader", Role: smodel.RoleReadOnly,
+		Keys: []smodel.CollectionKey{{CollectionID: colID, Login: "reader", Key: "reader key"}}}))
+
+	orgs, err := s.GetOrganizationList(reader.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, smodel.MemberStatusInvited, orgs[0].Status)
+
+	_, err = s.GetCollectionItemList(reader.AccessToken, colID)
+	assert.ErrorIs(t, err, ErrStatusForbidden)
+
+	require.NoError(t, s.AcceptInvite(reader.AccessToken, orgID))
+
+	members, err := s.GetMemberList(reader.AccessToken, orgID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "public", members[0].PublicKey)
+
+	cols, err := s.GetCollectionList(reader.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, cols, 1)
+	assert.Equal(t, "reader key", cols[0].Key)
+	assert.Equal(t, smodel.RoleReadOnly, cols[0].Role)
+
+	itemID, err := s.SaveCollectionItem(owner.AccessToken, smodel.CollectionItem{CollectionID: colID, ItemType: smodel.ItemTypeCred, Data: "data"})
+	require.NoError(t, err)
+
+	_, err = s.SaveCollectionItem(reader.AccessToken, smodel.CollectionItem{CollectionID: colID, ItemType: smodel.ItemTypeCred, Data: "data"})
+	assert.ErrorIs(t, err, ErrStatusForbidden)
+	assert.ErrorIs(t, s.DeleteCollectionItem(reader.AccessToken, itemID, colID), ErrStatusForbidden)
+
+	items, err := s.GetCollectionItemList(reader.AccessToken, colID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "data", items[0].Data)
+
+	require.NoError(t, s.DeleteCollectionItem(owner.AccessToken, itemID, colID))
+	require.NoError(t, s.RemoveMember(reader.AccessToken, orgID, "reader"))
+
+	cols, err = s.GetCollectionList(reader.AccessToken)
+	require.NoError(t, err)
+	assert.Empty(t, cols)
+
+	require.NoError(t, s.DeleteCollection(owner.AccessToken, colID))
+	require.NoError(t, s.DeleteOrganization(owner.AccessToken, orgID))
+}
+
+func TestHTTPService_EmergencyAccess(t *testing.T) {
+	s := newTestHTTPService(t)
+	owner := signUp(t, s)
+	contact, err := s.SignUp(model.User{Login: "contact", Password: "password"})
+	require.NoError(t, err)
+
+	for _, tokens := range []model.Tokens{owner, contact} {
+		require.NoError(t, s.SaveUserKeys(tokens.AccessToken, smodel.UserKeys{PublicKey: "public", PrivateKey: "private", UpdatedAt: time.Now()}))
+	}
+
+	_, err = s.SaveEmergencyAccess(owner.AccessToken, smodel.EmergencyAccess{Grantee: "user", WaitHours: 1, Key: "key"})
+	assert.ErrorIs(t, err, ErrStatusValidation)
+
+	id, err := s.SaveEmergencyAccess(owner.AccessToken, smodel.EmergencyAccess{Grantee: "contact", WaitHours: 0, Key: "key"})
+	require.NoError(t, err)
+
+	incoming, err := s.GetIncomingEmergencyAccessList(contact.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+	assert.Equal(t, smodel.EmergencyStatusIdle, incoming[0].Status)
+	assert.Empty(t, incoming[0].Key)
+
+	_, err = s.GetEmergencyVault(contact.AccessToken, id)
+	assert.ErrorIs(t, err, ErrStatusForbidden)
+
+	// без периода ожидания доступ открывается сразу после запроса
+	require.NoError(t, s.RequestEmergencyAccess(contact.AccessToken, id))
+
+	incoming, err = s.GetIncomingEmergencyAccessList(contact.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+	assert.Equal(t, smodel.EmergencyStatusGranted, incoming[0].Status)
+	assert.Equal(t, "key", incoming[0].Key)
+
+	_, err = s.AddText(owner.AccessToken, smodel.DataText{Title: "text", Text: "text", Meta: "meta", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	vault, err := s.GetEmergencyVault(contact.AccessToken, id)
+	require.NoError(t, err)
+	require.Len(t, vault.Texts, 1)
+	assert.Equal(t, "text", vault.Texts[0].Title)
+
+	assert.ErrorIs(t, s.RejectEmergencyAccess(contact.AccessToken, id), ErrStatusNotFound)
+	require.NoError(t, s.RejectEmergencyAccess(owner.AccessToken, id))
+
+	list, err := s.GetEmergencyAccessList(owner.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, smodel.EmergencyStatusRejected, list[0].Status)
+
+	_, err = s.GetEmergencyVault(contact.AccessToken, id)
+	assert.ErrorIs(t, err, ErrStatusForbidden)
+
+	require.NoError(t, s.DeleteEmergencyAccess(owner.AccessToken, id))
+}
+
+func TestHTTPService_Secrets(t *testing.T) {
+	s := newTestHTTPService(t)
+	tokens := signUp(t, s)
+
+	_, err := s.CreateSecret(tokens.AccessToken, smodel.Secret{Data: "data", MaxViews: 1, ExpiresAt: time.Now().Add(smodel.MaxSecretTTL + time.Hour)})
+	assert.ErrorIs(t, err, ErrStatusValidation)
+
+	secret, err := s.CreateSecret(tokens.AccessToken, smodel.Secret{Data: "data", MaxViews: 1, ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	require.NotEmpty(t, secret.ID)
+
+	list, err := s.GetSecretList(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Empty(t, list[0].Data)
+
+	// страница секрета просмотр не засчитывает
+	link := s.SecretLink(secret.ID, "key")
+	res, err := s.newRequest().Get(strings.TrimSuffix(link, "#key"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode())
+	assert.Contains(t, res.Header().Get("Content-Type"), "text/html")
+	assert.Equal(t, "no-store", res.Header().Get("Cache-Control"))
+
+	opened, err := s.OpenSecret(secret.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "data", opened.Data)
+
+	_, err = s.OpenSecret(secret.ID)
+	assert.ErrorIs(t, err, ErrStatusNotFound)
+
+	list, err = s.GetSecretList(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	secret, err = s.CreateSecret(tokens.AccessToken, smodel.Secret{Data: "data", MaxViews: 2, ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteSecret(tokens.AccessToken, secret.ID))
+	assert.ErrorIs(t, s.DeleteSecret(tokens.AccessToken, secret.ID), ErrStatusNotFound)
 }
@@ -0,0 +1,46 @@
+package service
+
+import "sync"
+
+// UploadStore хранит идентификаторы незавершенных загрузок по хешу содержимого файла,
+// чтобы продолжить загрузку после перезапуска клиента.
+type UploadStore interface {
+	GetUploadID(hash string) (uploadID string, err error)
+	SetUploadID(hash, uploadID string) error
+	DeleteUploadID(hash string) error
+}
+
+// memoryUploads хранилище незавершенных загрузок в памяти процесса.
+type memoryUploads struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+func newMemoryUploads() *memoryUploads {
+	return &memoryUploads{ids: make(map[string]string)}
+}
+
+func (m *memoryUploads) GetUploadID(hash string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.ids[hash], nil
+}
+
+func (m *memoryUploads) SetUploadID(hash, uploadID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ids[hash] = uploadID
+
+	return nil
+}
+
+func (m *memoryUploads) DeleteUploadID(hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.ids, hash)
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package sshagent
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var (
+	ErrKeyNotFound = errors.New("key not found")
+	ErrDenied      = errors.New("key use denied")
+	ErrReadOnly    = errors.New("agent is read-only")
+	ErrNotSocket   = errors.New("path exists and is not a socket")
+)
+
+// Key ключ хранилища, который отдает агент. Comment - заголовок записи.
+type Key struct {
+	Comment string
+	Signer  ssh.Signer
+}
+
+// Agent ssh-agent только для чтения: ключи берутся из хранилища при каждом запросе,
+// добавить, удалить или заблокировать их через протокол агента нельзя.
+type Agent struct {
+	// Keys возвращает ключи открытого хранилища.
+	Keys func() ([]Key, error)
+	// Confirm запрашивает у пользователя разрешение на подпись ключом key.
+	// Без Confirm агент отказывает во всех подписях.
+	Confirm func(key Key) bool
+}
+
+var _ agent.ExtendedAgent = (*Agent)(nil)
+
+func (a *Agent) List() ([]*agent.Key, error) {
+	keys, err := a.Keys()
+	if err != nil {
+		return nil, fmt.Errorf("sshagent.List: %w", err)
+	}
+
+	res := make([]*agent.Key, 0, len(keys))
+	for _, k := range keys {
+		pub := k.Signer.PublicKey()
+		res = append(res, &agent.Key{Format: pub.Type(), Blob: pub.Marshal(), Comment: k.Comment})
+	}
+
+	return res, nil
+}
+
+func (a *Agent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+// SignWithFlags подписывает data ключом key после подтверждения пользователем.
+// Флаги SignatureFlagRsaSha256/512 выбирают алгоритм подписи RSA-ключом.
+func (a *Agent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	keys, err := a.Keys()
+	if err != nil {
+		return nil, fmt.Errorf("sshagent.Sign: %w", err)
+	}
+
+	blob := key.Marshal()
+	for _, k := range keys {
+		if !bytes.Equal(k.Signer.PublicKey().Marshal(), blob) {
+			continue
+		}
+
+		if a.Confirm == nil || !a.Confirm(k) {
+			return nil, ErrDenied
+		}
+
+		algorithm := ""
+		switch {
+		case flags&agent.SignatureFlagRsaSha256 != 0:
+			algorithm = ssh.KeyAlgoRSASHA256
+		case flags&agent.SignatureFlagRsaSha512 != 0:
+			algorithm = ssh.KeyAlgoRSASHA512
+		}
+
+		if s, ok := k.Signer.(ssh.AlgorithmSigner); ok && algorithm != "" && key.Type() == ssh.KeyAlgoRSA {
+			return s.SignWithAlgorithm(rand.Reader, data, algorithm)
+		}
+
+		return k.Signer.Sign(rand.Reader, data)
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+func (a *Agent) Add(agent.AddedKey) error {
+	return ErrReadOnly
+}
+
+func (a *Agent) Remove(ssh.PublicKey) error {
+	return ErrReadOnly
+}
+
+func (a *Agent) RemoveAll() error {
+	return ErrReadOnly
+}
+
+func (a *Agent) Lock([]byte) error {
+	return ErrReadOnly
+}
+
+func (a *Agent) Unlock([]byte) error {
+	return ErrReadOnly
+}
+
+// Signers не отдает ключи: каждая подпись проходит через SignWithFlags и подтверждение.
+func (a *Agent) Signers() ([]ssh.Signer, error) {
+	return nil, ErrReadOnly
+}
+
+func (a *Agent) Extension(string, []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
+
+// Listen открывает unix-сокет агента, доступный только владельцу. Сокет создается в новом каталоге
+// с правами 0700 рядом с path и только после смены прав на 0600 переносится на место path, так что другие
+// пользователи не могут подключиться к нему раньше. Сокет, оставшийся от предыдущего запуска, заменяется;
+// если path занят не сокетом, возвращается ErrNotSocket.
+func Listen(path string) (net.Listener, error) {
+	fi, err := os.Lstat(path)
+	if err == nil && fi.Mode()&os.ModeSocket == 0 {
+		return nil, fmt.Errorf("sshagent.Listen: %s: %w", path, ErrNotSocket)
+	}
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("sshagent.Listen: %w", err)
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sock")
+	if err != nil {
+		return nil, fmt.Errorf("sshagent.Listen: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+
+	l, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, fmt.Errorf("sshagent.Listen: %w", err)
+	}
+
+	ul := l.(*net.UnixListener)
+	// сокет удаляется по итоговому пути, см. listener.Close
+	ul.SetUnlinkOnClose(false)
+
+	if err = os.Chmod(tmp, 0600); err == nil {
+		err = os.Rename(tmp, path)
+	}
+
+	if err != nil {
+		ul.Close()
+
+		return nil, fmt.Errorf("sshagent.Listen: %w", err)
+	}
+
+	return &listener{UnixListener: ul, path: path}, nil
+}
+
+// listener сокет агента, удаляющий файл сокета при закрытии.
+type listener struct {
+	*net.UnixListener
+	path string
+}
+
+func (l *listener) Close() error {
+	err := l.UnixListener.Close()
+	if errRemove := os.Remove(l.path); err == nil && !errors.Is(errRemove, os.ErrNotExist) {
+		err = errRemove
+	}
+
+	return err
+}
+
+// Serve обслуживает подключения к агенту до закрытия l.
+func (a *Agent) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("sshagent.Serve: %w", err)
+		}
+
+		go func() {
+			defer conn.Close()
+			_ = agent.ServeAgent(a, conn)
+		}()
+	}
+}
@@ -0,0 +1,175 @@
+package sshagent
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// newTestAgent агент с ключом типа keyType и клиент, подключенный к нему через net.Pipe.
+func newTestAgent(t *testing.T, keyType string, confirm func(Key) bool) (agent.ExtendedAgent, ssh.PublicKey) {
+	t.Helper()
+
+	pair, err := Generate(keyType, "")
+	require.NoError(t, err)
+	signer, err := ParseSigner(pair.PrivateKey, "")
+	require.NoError(t, err)
+
+	a := &Agent{
+		Keys:    func() ([]Key, error) { return []Key{{Comment: "github", Signer: signer}}, nil },
+		Confirm: confirm,
+	}
+
+	c1, c2 := net.Pipe()
+	t.Cleanup(func() { c1.Close() })
+	go func() {
+		defer c2.Close()
+		_ = agent.ServeAgent(a, c2)
+	}()
+
+	return agent.NewClient(c1), signer.PublicKey()
+}
+
+func TestAgent_Sign(t *testing.T) {
+	var asked []string
+	client, pub := newTestAgent(t, KeyTypeEd25519, func(k Key) bool {
+		asked = append(asked, k.Comment)
+
+		return true
+	})
+
+	keys, err := client.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "github", keys[0].Comment)
+	assert.Equal(t, pub.Marshal(), keys[0].Blob)
+
+	data := []byte("challenge")
+	sig, err := client.Sign(pub, data)
+	require.NoError(t, err)
+	assert.NoError(t, pub.Verify(data, sig))
+
+	_, err = client.Sign(pub, data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github", "github"}, asked)
+
+	assert.Error(t, client.Add(agent.AddedKey{}))
+	assert.Error(t, client.RemoveAll())
+	assert.Error(t, client.Lock([]byte("x")))
+}
+
+func TestAgent_SignRSAFlags(t *testing.T) {
+	client, pub := newTestAgent(t, KeyTypeRSA, func(Key) bool { return true })
+
+	data := []byte("challenge")
+	sig, err := client.SignWithFlags(pub, data, agent.SignatureFlagRsaSha256)
+	require.NoError(t, err)
+	assert.Equal(t, ssh.KeyAlgoRSASHA256, sig.Format)
+	assert.NoError(t, pub.Verify(data, sig))
+}
+
+func TestAgent_SignDenied(t *testing.T) {
+	client, pub := newTestAgent(t, KeyTypeEd25519, func(Key) bool { return false })
+
+	_, err := client.Sign(pub, []byte("challenge"))
+	assert.Error(t, err)
+
+	other, err := Generate(KeyTypeEd25519, "")
+	require.NoError(t, err)
+	otherPub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(other.PublicKey))
+	require.NoError(t, err)
+
+	_, err = client.Sign(otherPub, []byte("challenge"))
+	assert.Error(t, err)
+}
+
+func TestListen(t *testing.T) {
+	pair, err := Generate(KeyTypeEd25519, "")
+	require.NoError(t, err)
+	signer, err := ParseSigner(pair.PrivateKey, "")
+	require.NoError(t, err)
+
+	a := &Agent{Keys: func() ([]Key, error) { return []Key{{Signer: signer}}, nil }}
+
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := Listen(path)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- a.Serve(l) }()
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	keys, err := agent.NewClient(conn).List()
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	fi, err := os.Lstat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// временный каталог сокета удален
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, l.Close())
+	assert.NoError(t, <-done)
+
+	_, err = os.Lstat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestListen_Existing(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, path string)
+		wantErr error
+	}{
+		{name: "no file", prepare: func(t *testing.T, path string) {}},
+		{name: "stale socket", prepare: func(t *testing.T, path string) {
+			// сокет от прошлого запуска, оставшийся после аварийного завершения
+			l, err := net.Listen("unix", path)
+			require.NoError(t, err)
+			l.(*net.UnixListener).SetUnlinkOnClose(false)
+			require.NoError(t, l.Close())
+		}},
+		{name: "regular file", prepare: func(t *testing.T, path string) {
+			require.NoError(t, os.WriteFile(path, []byte("data"), 0600))
+		}, wantErr: ErrNotSocket},
+		{name: "directory", prepare: func(t *testing.T, path string) {
+			require.NoError(t, os.Mkdir(path, 0700))
+		}, wantErr: ErrNotSocket},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "agent.sock")
+			tt.prepare(t, path)
+
+			l, err := Listen(path)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				// занятый путь не тронут
+				_, err = os.Lstat(path)
+				assert.NoError(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			defer l.Close()
+
+			conn, err := net.Dial("unix", path)
+			require.NoError(t, err)
+			conn.Close()
+		})
+	}
+}
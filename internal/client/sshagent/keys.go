@@ -0,0 +1,115 @@
+// Package sshagent генерация SSH-ключей и ssh-agent, отдающий ключи из открытого хранилища.
+package sshagent
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Типы генерируемых ключей.
+const (
+	KeyTypeEd25519 = "ed25519"
+	KeyTypeRSA     = "rsa"
+)
+
+// RSABits размер генерируемого RSA-ключа.
+const RSABits = 4096
+
+var (
+	ErrKeyTypeInvalid    = errors.New("unknown key type")
+	ErrPassphraseMissing = errors.New("private key is encrypted, passphrase required")
+)
+
+// KeyPair закрытый ключ в формате PEM, открытый ключ в формате authorized_keys и его отпечаток SHA256.
+type KeyPair struct {
+	PrivateKey  string
+	PublicKey   string
+	Fingerprint string
+}
+
+// Generate создает новый ключ типа keyType (KeyTypeEd25519 или KeyTypeRSA).
+// comment добавляется в конец строки открытого ключа.
+func Generate(keyType, comment string) (pair KeyPair, err error) {
+	var block *pem.Block
+
+	switch keyType {
+	case KeyTypeEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return pair, fmt.Errorf("sshagent.Generate: %w", err)
+		}
+
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return pair, fmt.Errorf("sshagent.Generate: %w", err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	case KeyTypeRSA:
+		key, err := rsa.GenerateKey(rand.Reader, RSABits)
+		if err != nil {
+			return pair, fmt.Errorf("sshagent.Generate: %w", err)
+		}
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	default:
+		return pair, fmt.Errorf("sshagent.Generate: %w: %q", ErrKeyTypeInvalid, keyType)
+	}
+
+	pair, err = Inspect(string(pem.EncodeToMemory(block)), "", comment)
+	if err != nil {
+		return pair, fmt.Errorf("sshagent.Generate: %w", err)
+	}
+
+	return pair, nil
+}
+
+// Inspect разбирает закрытый ключ (PEM, PKCS#1/PKCS#8/OpenSSH) и вычисляет открытый ключ и отпечаток.
+// passphrase нужна только для зашифрованных ключей.
+func Inspect(privateKey, passphrase, comment string) (pair KeyPair, err error) {
+	signer, err := ParseSigner(privateKey, passphrase)
+	if err != nil {
+		return pair, err
+	}
+
+	pub := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	if comment = strings.TrimSpace(comment); comment != "" {
+		pub += " " + comment
+	}
+
+	return KeyPair{
+		PrivateKey:  privateKey,
+		PublicKey:   pub,
+		Fingerprint: ssh.FingerprintSHA256(signer.PublicKey()),
+	}, nil
+}
+
+// ParseSigner разбирает закрытый ключ для подписи. Парольная фраза незашифрованного ключа игнорируется.
+func ParseSigner(privateKey, passphrase string) (ssh.Signer, error) {
+	raw, err := ssh.ParseRawPrivateKey([]byte(privateKey))
+
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if passphrase == "" {
+			return nil, fmt.Errorf("sshagent.ParseSigner: %w", ErrPassphraseMissing)
+		}
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("sshagent.ParseSigner: %w", err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("sshagent.ParseSigner: %w", err)
+	}
+
+	return signer, nil
+}
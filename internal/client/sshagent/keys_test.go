@@ -0,0 +1,77 @@
+package sshagent
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType string
+		prefix  string
+		wantErr error
+	}{
+		{name: "ed25519", keyType: KeyTypeEd25519, prefix: "ssh-ed25519 "},
+		{name: "rsa", keyType: KeyTypeRSA, prefix: "ssh-rsa "},
+		{name: "unknown", keyType: "dsa", wantErr: ErrKeyTypeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair, err := Generate(tt.keyType, "user@host")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+			require.NoError(t, err)
+
+			assert.True(t, strings.HasPrefix(pair.PublicKey, tt.prefix))
+			assert.True(t, strings.HasSuffix(pair.PublicKey, " user@host"))
+			assert.True(t, strings.HasPrefix(pair.Fingerprint, "SHA256:"))
+
+			pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pair.PublicKey))
+			require.NoError(t, err)
+			assert.Equal(t, pair.Fingerprint, ssh.FingerprintSHA256(pub))
+
+			got, err := Inspect(pair.PrivateKey, "", "user@host")
+			require.NoError(t, err)
+			assert.Equal(t, pair, got)
+
+			got, err = Inspect(pair.PrivateKey, "unused", "user@host")
+			require.NoError(t, err)
+			assert.Equal(t, pair, got)
+		})
+	}
+}
+
+func TestInspect_Passphrase(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	//nolint:staticcheck // устаревшее шифрование PEM встречается в импортируемых ключах
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key), []byte("secret"), x509.PEMCipherAES256)
+	require.NoError(t, err)
+	private := string(pem.EncodeToMemory(block))
+
+	_, err = Inspect(private, "", "")
+	assert.ErrorIs(t, err, ErrPassphraseMissing)
+
+	_, err = Inspect(private, "wrong", "")
+	assert.Error(t, err)
+
+	pair, err := Inspect(private, "secret", "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(pair.PublicKey, "ssh-rsa "))
+
+	_, err = Inspect("not a key", "", "")
+	assert.Error(t, err)
+}
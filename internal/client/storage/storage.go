@@ -66,6 +66,41 @@ func (b *Base) GetUserConfig() (c model.UserConfig, err error) {
 	return c, err
 }
 
+// GetUploadID идентификатор незавершенной загрузки содержимого с хешем hash; пусто, если загрузки нет.
+func (b *Base) GetUploadID(hash string) (uploadID string, err error) {
+	if b.user == "" {
+		return "", ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).Get("uploads", hash, &uploadID)
+	if errors.Is(err, storm.ErrNotFound) {
+		return "", nil
+	}
+
+	return uploadID, err
+}
+
+func (b *Base) SetUploadID(hash, uploadID string) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	return b.db.From(b.user).Set("uploads", hash, uploadID)
+}
+
+func (b *Base) DeleteUploadID(hash string) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).Delete("uploads", hash)
+	if errors.Is(err, storm.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
 func (b *Base) AddCard(card *model.DataCard) (err error) {
 	if b.user == "" {
 		return ErrUserNotInitialized
@@ -209,6 +244,132 @@ func (b *Base) DeleteText(localID int) (err error) {
 	return err
 }
 
+func (b *Base) AddSSHKey(key *model.DataSSHKey) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).Save(key)
+
+	return err
+}
+
+func (b *Base) GetSSHKey(localID int) (key model.DataSSHKey, err error) {
+	if b.user == "" {
+		return key, ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).One("LocalID", localID, &key)
+
+	return key, err
+}
+
+func (b *Base) GetAllSSHKeys() (keys []model.DataSSHKey, err error) {
+	if b.user == "" {
+		return keys, ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).All(&keys, storm.Reverse())
+
+	return keys, err
+}
+
+func (b *Base) DeleteSSHKey(localID int) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	var key model.DataSSHKey
+	key.LocalID = localID
+	err = b.db.From(b.user).DeleteStruct(&key)
+
+	return err
+}
+
+func (b *Base) AddIdentity(doc *model.DataIdentity) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).Save(doc)
+
+	return err
+}
+
+func (b *Base) GetIdentity(localID int) (doc model.DataIdentity, err error) {
+	if b.user == "" {
+		return doc, ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).One("LocalID", localID, &doc)
+
+	return doc, err
+}
+
+func (b *Base) GetAllIdentities() (docs []model.DataIdentity, err error) {
+	if b.user == "" {
+		return docs, ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).All(&docs, storm.Reverse())
+
+	return docs, err
+}
+
+func (b *Base) DeleteIdentity(localID int) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	var doc model.DataIdentity
+	doc.LocalID = localID
+	err = b.db.From(b.user).DeleteStruct(&doc)
+
+	return err
+}
+
+func (b *Base) AddCustomItem(item *model.DataCustom) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).Save(item)
+
+	return err
+}
+
+func (b *Base) GetCustomItem(localID int) (item model.DataCustom, err error) {
+	if b.user == "" {
+		return item, ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).One("LocalID", localID, &item)
+
+	return item, err
+}
+
+func (b *Base) GetAllCustomItems() (items []model.DataCustom, err error) {
+	if b.user == "" {
+		return items, ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).All(&items, storm.Reverse())
+
+	return items, err
+}
+
+func (b *Base) DeleteCustomItem(localID int) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	var item model.DataCustom
+	item.LocalID = localID
+	err = b.db.From(b.user).DeleteStruct(&item)
+
+	return err
+}
+
 func (b *Base) AddFile(file *model.DataFile) (err error) {
 	if b.user == "" {
 		return ErrUserNotInitialized
@@ -257,3 +418,141 @@ func (b *Base) DeleteFile(localID int) (err error) {
 
 	return err
 }
+
+func (b *Base) AddFolder(folder *model.Folder) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).Save(folder)
+
+	return err
+}
+
+func (b *Base) GetFolder(localID int) (folder model.Folder, err error) {
+	if b.user == "" {
+		return folder, ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).One("LocalID", localID, &folder)
+
+	return folder, err
+}
+
+func (b *Base) GetAllFolders() (folders []model.Folder, err error) {
+	if b.user == "" {
+		return folders, ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).All(&folders)
+
+	return folders, err
+}
+
+func (b *Base) DeleteFolder(localID int) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	var folder model.Folder
+	folder.LocalID = localID
+	err = b.db.From(b.user).DeleteStruct(&folder)
+
+	return err
+}
+
+func (b *Base) AddTag(tag *model.Tag) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).Save(tag)
+
+	return err
+}
+
+func (b *Base) GetAllTags() (tags []model.Tag, err error) {
+	if b.user == "" {
+		return tags, ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).All(&tags)
+
+	return tags, err
+}
+
+func (b *Base) DeleteTag(localID int) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	var tag model.Tag
+	tag.LocalID = localID
+	err = b.db.From(b.user).DeleteStruct(&tag)
+
+	return err
+}
+
+func (b *Base) AddTemplate(tpl *model.Template) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).Save(tpl)
+
+	return err
+}
+
+func (b *Base) GetAllTemplates() (templates []model.Template, err error) {
+	if b.user == "" {
+		return templates, ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).All(&templates)
+
+	return templates, err
+}
+
+func (b *Base) DeleteTemplate(localID int) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	var tpl model.Template
+	tpl.LocalID = localID
+	err = b.db.From(b.user).DeleteStruct(&tpl)
+
+	return err
+}
+
+func (b *Base) AddAttachment(att *model.Attachment) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).Save(att)
+
+	return err
+}
+
+func (b *Base) GetAllAttachments() (atts []model.Attachment, err error) {
+	if b.user == "" {
+		return atts, ErrUserNotInitialized
+	}
+
+	err = b.db.From(b.user).All(&atts)
+
+	return atts, err
+}
+
+func (b *Base) DeleteAttachment(localID int) (err error) {
+	if b.user == "" {
+		return ErrUserNotInitialized
+	}
+
+	var att model.Attachment
+	att.LocalID = localID
+	err = b.db.From(b.user).DeleteStruct(&att)
+
+	return err
+}
@@ -7,10 +7,13 @@ const (
 	TypeCred
 	TypeText
 	TypeFile
+	TypeSSH
+	TypeIdentity
+	TypeCustom
 )
 
 func (t DataType) String() string {
-	return [...]string{"Карта", "Логин/пароль", "Текстовые данные", "Файл"}[t]
+	return [...]string{"Карта", "Логин/пароль", "Текстовые данные", "Файл", "SSH-ключ", "Документ", "Запись по шаблону"}[t]
 }
 
 type TabName int
@@ -20,8 +23,11 @@ const (
 	TabCred
 	TabText
 	TabFile
+	TabSSH
+	TabIdentity
+	TabCustom
 )
 
 func (t TabName) String() string {
-	return [...]string{"Карты", "Логин/пароль", "Текстовые данные", "Файлы"}[t]
+	return [...]string{"Карты", "Логин/пароль", "Текстовые данные", "Файлы", "SSH-ключи", "Документы", "По шаблонам"}[t]
 }
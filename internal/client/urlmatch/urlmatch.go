@@ -0,0 +1,149 @@
+// Package urlmatch подбирает учетные записи для адреса сайта по правилам их URL.
+package urlmatch
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rainset/gophkeeper/internal/client/model"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Оценки совпадения: чем точнее правило, тем выше учетная запись в выдаче.
+const (
+	ScoreNone   = 0
+	ScoreRegex  = 1
+	ScoreDomain = 2
+	ScoreHost   = 3
+)
+
+var ErrURLInvalid = errors.New("некорректный адрес сайта")
+
+// Result учетная запись и лучшая оценка совпадения среди её адресов.
+type Result struct {
+	Cred  model.DataCred
+	Score int
+}
+
+// Parse разбирает адрес сайта; адрес без схемы считается https.
+func Parse(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return nil, ErrURLInvalid
+	}
+
+	return u, nil
+}
+
+// Validate проверяет адрес учетной записи перед сохранением.
+func Validate(u model.CredURL) error {
+	if u.Match == smodel.URLMatchRegex {
+		_, err := regexp.Compile(u.URL)
+		return err
+	}
+
+	_, err := Parse(u.URL)
+
+	return err
+}
+
+// Score оценивает совпадение адреса учетной записи с адресом target.
+func Score(u model.CredURL, target *url.URL) int {
+	switch u.Match {
+	case smodel.URLMatchRegex:
+		re, err := regexp.Compile(u.URL)
+		if err != nil || !re.MatchString(target.String()) {
+			return ScoreNone
+		}
+
+		return ScoreRegex
+	case smodel.URLMatchHost:
+		stored, err := Parse(u.URL)
+		if err != nil || !strings.EqualFold(stored.Host, target.Host) {
+			return ScoreNone
+		}
+
+		return ScoreHost
+	case smodel.URLMatchDomain:
+		stored, err := Parse(u.URL)
+		if err != nil {
+			return ScoreNone
+		}
+
+		if strings.EqualFold(stored.Hostname(), target.Hostname()) {
+			return ScoreHost
+		}
+
+		storedDomain, ok := baseDomain(stored.Hostname())
+		if !ok {
+			return ScoreNone
+		}
+
+		targetDomain, ok := baseDomain(target.Hostname())
+		if !ok || storedDomain != targetDomain {
+			return ScoreNone
+		}
+
+		return ScoreDomain
+	}
+
+	return ScoreNone
+}
+
+// baseDomain домен второго уровня с учетом публичных суффиксов (example.co.uk);
+// для IP-адресов и одиночных имен вроде localhost базового домена нет.
+func baseDomain(host string) (string, bool) {
+	if net.ParseIP(host) != nil {
+		return "", false
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(host))
+	if err != nil {
+		return "", false
+	}
+
+	return domain, true
+}
+
+// Rank возвращает учетные записи, подходящие для адреса rawURL, от самых точных совпадений;
+// при равной оценке записи упорядочены по заголовку.
+func Rank(creds []model.DataCred, rawURL string) ([]Result, error) {
+	target, err := Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Result
+	for _, cred := range creds {
+		best := ScoreNone
+		for _, u := range cred.URLs {
+			if s := Score(u, target); s > best {
+				best = s
+			}
+		}
+
+		if best != ScoreNone {
+			res = append(res, Result{Cred: cred, Score: best})
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+
+		return res[i].Cred.Title < res[j].Cred.Title
+	})
+
+	return res, nil
+}
@@ -0,0 +1,67 @@
+package urlmatch
+
+import (
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/client/model"
+	smodel "github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    model.CredURL
+		target string
+		want   int
+	}{
+		{name: "host exact", url: model.CredURL{URL: "https://gitlab.example.com", Match: smodel.URLMatchHost}, target: "https://gitlab.example.com/users/sign_in", want: ScoreHost},
+		{name: "host other subdomain", url: model.CredURL{URL: "gitlab.example.com", Match: smodel.URLMatchHost}, target: "https://example.com", want: ScoreNone},
+		{name: "host port differs", url: model.CredURL{URL: "https://example.com:8443", Match: smodel.URLMatchHost}, target: "https://example.com", want: ScoreNone},
+		{name: "domain same host", url: model.CredURL{URL: "example.com", Match: smodel.URLMatchDomain}, target: "EXAMPLE.com/login", want: ScoreHost},
+		{name: "domain subdomain", url: model.CredURL{URL: "https://example.com", Match: smodel.URLMatchDomain}, target: "https://gitlab.example.com", want: ScoreDomain},
+		{name: "domain public suffix", url: model.CredURL{URL: "shop.example.co.uk", Match: smodel.URLMatchDomain}, target: "https://login.example.co.uk", want: ScoreDomain},
+		{name: "domain other owner", url: model.CredURL{URL: "one.co.uk", Match: smodel.URLMatchDomain}, target: "https://two.co.uk", want: ScoreNone},
+		{name: "domain ip", url: model.CredURL{URL: "http://10.0.0.1", Match: smodel.URLMatchDomain}, target: "http://10.0.0.2", want: ScoreNone},
+		{name: "regex", url: model.CredURL{URL: `^https://git\w*\.example\.com/`, Match: smodel.URLMatchRegex}, target: "https://gitlab.example.com/", want: ScoreRegex},
+		{name: "regex no match", url: model.CredURL{URL: `^http://`, Match: smodel.URLMatchRegex}, target: "https://example.com", want: ScoreNone},
+		{name: "regex invalid", url: model.CredURL{URL: `(`, Match: smodel.URLMatchRegex}, target: "https://example.com", want: ScoreNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := Parse(tt.target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Score(tt.url, target))
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	creds := []model.DataCred{
+		{Title: "other", URLs: []model.CredURL{{URL: "https://other.com", Match: smodel.URLMatchDomain}}},
+		{Title: "regex", URLs: []model.CredURL{{URL: `example\.com`, Match: smodel.URLMatchRegex}}},
+		{Title: "domain", URLs: []model.CredURL{{URL: "https://example.com", Match: smodel.URLMatchDomain}}},
+		{Title: "host", URLs: []model.CredURL{{URL: "https://other.com", Match: smodel.URLMatchDomain}, {URL: "gitlab.example.com", Match: smodel.URLMatchHost}}},
+		{Title: "no urls"},
+	}
+
+	res, err := Rank(creds, "gitlab.example.com/users/sign_in")
+	require.NoError(t, err)
+
+	var titles []string
+	for _, v := range res {
+		titles = append(titles, v.Cred.Title)
+	}
+	assert.Equal(t, []string{"host", "domain", "regex"}, titles)
+
+	_, err = Rank(creds, "")
+	assert.ErrorIs(t, err, ErrURLInvalid)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(model.CredURL{URL: "example.com", Match: smodel.URLMatchDomain}))
+	assert.NoError(t, Validate(model.CredURL{URL: `^https://`, Match: smodel.URLMatchRegex}))
+	assert.Error(t, Validate(model.CredURL{URL: `(`, Match: smodel.URLMatchRegex}))
+	assert.Error(t, Validate(model.CredURL{URL: "https://", Match: smodel.URLMatchHost}))
+}
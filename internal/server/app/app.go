@@ -46,8 +46,21 @@ func (s *Server) Stop(ctx context.Context) error {
 func Run(cfg *config.Config) {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	store, err := storage.Open(ctx, cfg.DatabaseDsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = migrateOnStart(ctx, cfg, store); err != nil {
+		log.Fatal(err)
+	}
+
+	storeFile, err := file.Open(ctx, cfg.FileStorage)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	gcInterval, gcGrace, err := parseGCConfig(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -61,20 +74,37 @@ func Run(cfg *config.Config) {
 	var wg sync.WaitGroup
 	wg.Add(1) // добавляем одну горутину в группу
 
-	// удаление по времени
+	// удаление по времени: просроченные refresh-токены и одноразовые секреты
 	go func() {
 		defer wg.Done()
+
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
 		for {
-			err := newService.ClearExpiredRefreshTokens(ctx)
-			if err != nil {
+			// ошибка одной очистки (например, временная недоступность БД) не останавливает остальные:
+			// повторим на следующем тике
+			if err := newService.ClearExpiredRefreshTokens(ctx); err != nil {
+				logger.Error(err)
+			}
+			if err := newService.ClearExpiredSecrets(ctx); err != nil {
 				logger.Error(err)
+			}
+			if err := newService.ClearExpiredUploads(ctx); err != nil {
+				logger.Error(err)
+			}
+
+			select {
+			case <-ctx.Done():
 				return
+			case <-ticker.C:
 			}
-			time.Sleep(60 * time.Second)
 		}
-
 	}()
 
+	// сборка мусора в хранилище файлов
+	go runGC(ctx, newService, gcInterval, gcGrace, cfg.GCDelete)
+
 	go func() {
 		if err := srv.Run(); !errors.Is(err, http.ErrServerClosed) {
 			logger.Errorf("error occurred while running http server: %s\n", err.Error())
@@ -87,16 +117,19 @@ func Run(cfg *config.Config) {
 	<-quit
 	logger.Info("Shutting down server...")
 
+	// останавливаем фоновые задачи и ждем их завершения
+	cancel()
 	wg.Wait()
-	store.Close()
 
 	// The context is used to inform the server it has 5 seconds to finish
 	// the request it is currently handling
-	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := srv.Stop(ctx); err != nil {
 		log.Fatal("Server forced to shutdown: ", err)
 	}
+
+	store.Close()
 	logger.Info("Server exiting")
 }
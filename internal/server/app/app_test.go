@@ -20,7 +20,7 @@ func TestNewServer(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rainset/gophkeeper/internal/server/backup"
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+)
+
+var (
+	ErrBackupUsage  = errors.New("usage: server [flags] backup [-base=previous.tar] archive.tar")
+	ErrRestoreUsage = errors.New("usage: server [flags] restore full.tar [incremental.tar...]")
+)
+
+// Backup выполняет команду server backup: пишет в новый файл архив с согласованным снимком БД и содержимым
+// файлов, на которое ссылаются записи; с -base в архив попадает только содержимое, которого нет в цепочке base.
+func Backup(cfg *config.Config, args []string) error {
+	return createBackup(context.Background(), cfg, args, os.Stdout)
+}
+
+func createBackup(ctx context.Context, cfg *config.Config, args []string, w io.Writer) (err error) {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	basePath := flags.String("base", "", "previous archive: back up only content added since it")
+
+	if err = flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %s", ErrBackupUsage, err)
+	}
+
+	if flags.NArg() != 1 {
+		return ErrBackupUsage
+	}
+
+	var base *backup.Manifest
+
+	if *basePath != "" {
+		m, err := backup.ReadManifest(*basePath)
+		if err != nil {
+			return err
+		}
+
+		base = &m
+	}
+
+	store, err := storage.Open(ctx, cfg.DatabaseDsn)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	storeFile, err := file.Open(ctx, cfg.FileStorage)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(flags.Arg(0), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if errClose := out.Close(); err == nil {
+			err = errClose
+		}
+
+		// недописанный архив не оставляем, чтобы его не приняли за резервную копию
+		if err != nil {
+			os.Remove(out.Name())
+		}
+	}()
+
+	m, err := backup.Create(ctx, out, store, storeFile, base)
+	if err != nil {
+		return err
+	}
+
+	if err = out.Sync(); err != nil {
+		return err
+	}
+
+	for _, p := range m.Missing {
+		fmt.Fprintf(w, "%-8s %s\n", "missing", p)
+	}
+
+	fmt.Fprintf(w, "backup %s: %s\n", m.ID, backupSummary(m))
+
+	return nil
+}
+
+// Restore выполняет команду server restore: проверяет цепочку архивов (полный, затем инкрементные по порядку)
+// и загружает ее в пустой экземпляр.
+func Restore(cfg *config.Config, args []string) error {
+	return restoreBackup(context.Background(), cfg, args, os.Stdout)
+}
+
+func restoreBackup(ctx context.Context, cfg *config.Config, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %s", ErrRestoreUsage, err)
+	}
+
+	if flags.NArg() == 0 {
+		return ErrRestoreUsage
+	}
+
+	store, err := storage.Open(ctx, cfg.DatabaseDsn)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	storeFile, err := file.Open(ctx, cfg.FileStorage)
+	if err != nil {
+		return err
+	}
+
+	m, err := backup.Restore(ctx, flags.Args(), store, storeFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "restored %s: %s\n", m.ID, backupSummary(m))
+
+	return nil
+}
+
+// backupSummary итог резервной копии одной строкой.
+func backupSummary(m backup.Manifest) string {
+	var rows, blobs int
+
+	var size int64
+
+	for _, t := range m.Tables {
+		rows += t.Rows
+	}
+
+	for _, b := range m.Blobs {
+		if b.Archive == m.ID {
+			blobs++
+			size += b.Size
+		}
+	}
+
+	return fmt.Sprintf("tables: %d, rows: %d, blobs: %d (%d bytes), from base: %d, missing: %d",
+		len(m.Tables), rows, blobs, size, len(m.Blobs)-blobs, len(m.Missing))
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cfg := &config.Config{
+		DatabaseDsn: "sqlite://" + filepath.Join(t.TempDir(), "gophkeeper.db"),
+		FileStorage: filepath.Join(t.TempDir(), "files"),
+	}
+	require.NoError(t, Migrate(cfg, []string{"up"}))
+
+	store, err := storage.Open(ctx, cfg.DatabaseDsn)
+	require.NoError(t, err)
+	defer store.Close()
+
+	storeFile, err := file.New(cfg.FileStorage)
+	require.NoError(t, err)
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	path, err := storeFile.SaveFile(ctx, strings.NewReader("content"), -1)
+	require.NoError(t, err)
+	_, err = store.SaveFile(ctx, model.DataFile{UserID: userID, Title: "file", Path: path})
+	require.NoError(t, err)
+
+	full := filepath.Join(dir, "full.tar")
+	incr := filepath.Join(dir, "incr.tar")
+	restored := &config.Config{
+		DatabaseDsn: "sqlite://" + filepath.Join(t.TempDir(), "restored.db"),
+		FileStorage: filepath.Join(t.TempDir(), "files"),
+	}
+
+	tests := []struct {
+		name    string
+		run     func(ctx context.Context, cfg *config.Config, args []string, w io.Writer) error
+		cfg     *config.Config
+		args    []string
+		want    string
+		wantErr error
+	}{
+		{name: "backup no archive", run: createBackup, cfg: cfg, wantErr: ErrBackupUsage},
+		{name: "backup unknown flag", run: createBackup, cfg: cfg, args: []string{"-full", full}, wantErr: ErrBackupUsage},
+		{name: "backup full", run: createBackup, cfg: cfg, args: []string{full}, want: "tables: 23, rows: 2, blobs: 1 (7 bytes), from base: 0, missing: 0"},
+		{name: "backup existing archive", run: createBackup, cfg: cfg, args: []string{full}, wantErr: fs.ErrExist},
+		{name: "backup incremental", run: createBackup, cfg: cfg, args: []string{"-base=" + full, incr}, want: "blobs: 0 (0 bytes), from base: 1"},
+		{name: "restore no archive", run: restoreBackup, cfg: restored, wantErr: ErrRestoreUsage},
+		{name: "restore", run: restoreBackup, cfg: restored, args: []string{full, incr}, want: "rows: 2"},
+		{name: "restore not empty", run: restoreBackup, cfg: restored, args: []string{full}, wantErr: storage.ErrorRestoreNotEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			err := tt.run(ctx, tt.cfg, tt.args, &out)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, out.String(), tt.want)
+		})
+	}
+
+	restoredFiles, err := file.New(restored.FileStorage)
+	require.NoError(t, err)
+
+	info, err := restoredFiles.StatFile(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("content")), info.Size)
+}
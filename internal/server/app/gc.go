@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/service"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+var ErrGCUsage = errors.New("usage: server [flags] gc [-delete] [-grace=24h]")
+
+// parseGCConfig период сборки мусора и срок ожидания неиспользуемого содержимого из настроек.
+func parseGCConfig(cfg *config.Config) (interval, grace time.Duration, err error) {
+	interval, err = time.ParseDuration(cfg.GCInterval)
+	if err != nil {
+		return 0, 0, fmt.Errorf("GC_INTERVAL: %w", err)
+	}
+
+	grace, err = time.ParseDuration(cfg.GCGracePeriod)
+	if err != nil {
+		return 0, 0, fmt.Errorf("GC_GRACE_PERIOD: %w", err)
+	}
+
+	return interval, grace, nil
+}
+
+// runGC запускает сборку мусора в хранилище файлов каждые interval, пока не отменен ctx.
+// Отчет пишется в журнал, неиспользуемое содержимое удаляется только с GC_DELETE.
+func runGC(ctx context.Context, svc *service.Service, interval, grace time.Duration, remove bool) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := svc.CollectGarbage(ctx, grace, remove)
+		if err != nil {
+			logger.Error(err)
+
+			continue
+		}
+
+		if len(report.Orphans) > 0 || len(report.Missing) > 0 {
+			logger.Warnf("file storage gc: %s", gcSummary(report))
+		}
+
+		for _, v := range report.Missing {
+			logger.Warnf("file storage gc: %s %d of user %d: blob %s is missing", v.Table, v.ID, v.UserID, v.Path)
+		}
+	}
+}
+
+// GC выполняет команду server gc: сверяет хранилище файлов с БД и печатает отчет;
+// с -delete неиспользуемое содержимое, не менявшееся дольше -grace, удаляется.
+func GC(cfg *config.Config, args []string) error {
+	return gc(context.Background(), cfg, args, os.Stdout)
+}
+
+func gc(ctx context.Context, cfg *config.Config, args []string, w io.Writer) error {
+	grace, err := time.ParseDuration(cfg.GCGracePeriod)
+	if err != nil {
+		return fmt.Errorf("GC_GRACE_PERIOD: %w", err)
+	}
+
+	flags := flag.NewFlagSet("gc", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	remove := flags.Bool("delete", false, "delete unreferenced blobs")
+	flags.DurationVar(&grace, "grace", grace, "skip unreferenced blobs modified within this period")
+
+	if err = flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %s", ErrGCUsage, err)
+	}
+
+	if flags.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrGCUsage, flags.Arg(0))
+	}
+
+	store, err := storage.Open(ctx, cfg.DatabaseDsn)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	storeFile, err := file.Open(ctx, cfg.FileStorage)
+	if err != nil {
+		return err
+	}
+
+	report, err := service.New(store, storeFile, cfg).CollectGarbage(ctx, grace, *remove)
+	if err != nil {
+		return err
+	}
+
+	printGCReport(w, report)
+
+	return nil
+}
+
+// printGCReport печатает отчет сборки мусора: по строке на неиспользуемое и отсутствующее содержимое и итог.
+func printGCReport(w io.Writer, report model.GCReport) {
+	for _, v := range report.Orphans {
+		status := "orphan"
+		if v.Deleted {
+			status = "deleted"
+		}
+
+		fmt.Fprintf(w, "%-8s %s\t%d bytes\t%s\n", status, v.Path, v.Size, v.ModTime.UTC().Format(time.RFC3339))
+	}
+
+	for _, v := range report.Missing {
+		fmt.Fprintf(w, "%-8s %s\t%s %d of user %d\n", "missing", v.Path, v.Table, v.ID, v.UserID)
+	}
+
+	fmt.Fprintln(w, gcSummary(report))
+}
+
+// gcSummary итог отчета сборки мусора одной строкой.
+func gcSummary(report model.GCReport) string {
+	var size int64
+	for _, v := range report.Orphans {
+		size += v.Size
+	}
+
+	return fmt.Sprintf("blobs: %d, orphaned: %d (%d bytes), freed: %d bytes, missing: %d",
+		report.Blobs, len(report.Orphans), size, report.Freed, len(report.Missing))
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGC(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cfg := &config.Config{
+		DatabaseDsn:   "sqlite://" + filepath.Join(t.TempDir(), "gophkeeper.db"),
+		FileStorage:   dir,
+		GCGracePeriod: "24h",
+	}
+	require.NoError(t, Migrate(cfg, []string{"up"}))
+
+	storeFile, err := file.New(dir)
+	require.NoError(t, err)
+	orphan, err := storeFile.SaveFile(ctx, strings.NewReader("orphan"), -1)
+	require.NoError(t, err)
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, strings.TrimPrefix(orphan, file.URLPrefix+"/")), old, old))
+
+	tests := []struct {
+		name     string
+		args     []string
+		want     []string
+		wantErr  error
+		wantGone bool
+	}{
+		{name: "unknown flag", args: []string{"-force"}, wantErr: ErrGCUsage},
+		{name: "extra argument", args: []string{"now"}, wantErr: ErrGCUsage},
+		{name: "within grace", args: []string{"-grace=72h"}, want: []string{"blobs: 1, orphaned: 0"}},
+		{name: "report", want: []string{"orphan   " + orphan, "blobs: 1, orphaned: 1 (6 bytes), freed: 0 bytes"}},
+		{name: "delete", args: []string{"-delete"}, want: []string{"deleted  " + orphan, "freed: 6 bytes"}, wantGone: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			err := gc(ctx, cfg, tt.args, &out)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, want := range tt.want {
+				assert.Contains(t, out.String(), want)
+			}
+
+			_, err = storeFile.StatFile(ctx, orphan)
+			if tt.wantGone {
+				assert.ErrorIs(t, err, file.ErrNotFound)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.Error(t, gc(ctx, &config.Config{GCGracePeriod: "day"}, nil, &bytes.Buffer{}))
+}
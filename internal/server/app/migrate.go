@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+)
+
+var ErrMigrateUsage = errors.New("usage: server [flags] migrate up|down|status")
+
+// migrateOnStart применяет новые миграции при запуске сервера (если не отключено флагом)
+// и проверяет, что схема БД не новее, чем известно этой версии сервера.
+func migrateOnStart(ctx context.Context, cfg *config.Config, store storage.Interface) error {
+	m, err := storage.NewMigrator(store)
+	if errors.Is(err, storage.ErrorMigrationsUnsupported) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if !cfg.SkipMigrations {
+		if err = m.Up(ctx); err != nil {
+			return err
+		}
+	}
+
+	return m.Check(ctx)
+}
+
+// Migrate выполняет команду server migrate up|down|status для БД из cfg.DatabaseDsn.
+func Migrate(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return ErrMigrateUsage
+	}
+
+	ctx := context.Background()
+
+	store, err := storage.Open(ctx, cfg.DatabaseDsn)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	m, err := storage.NewMigrator(store)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "status":
+		return m.Status(ctx, os.Stdout)
+	default:
+		return fmt.Errorf("%w: unknown command %q", ErrMigrateUsage, args[0])
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	cfg := &config.Config{DatabaseDsn: "sqlite://" + filepath.Join(t.TempDir(), "gophkeeper.db")}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{name: "up", args: []string{"up"}},
+		{name: "status", args: []string{"status"}},
+		{name: "down", args: []string{"down"}},
+		{name: "no command", args: nil, wantErr: ErrMigrateUsage},
+		{name: "unknown command", args: []string{"redo"}, wantErr: ErrMigrateUsage},
+		{name: "too many args", args: []string{"up", "down"}, wantErr: ErrMigrateUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Migrate(cfg, tt.args)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	memCfg := &config.Config{DatabaseDsn: "memory:"}
+	assert.ErrorIs(t, Migrate(memCfg, []string{"up"}), storage.ErrorMigrationsUnsupported)
+}
+
+func Test_migrateOnStart(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		dsn            string
+		skipMigrations bool
+		wantTables     bool
+	}{
+		{name: "sqlite", dsn: "sqlite::memory:", wantTables: true},
+		{name: "sqlite skip migrations", dsn: "sqlite::memory:", skipMigrations: true},
+		{name: "memory", dsn: "memory:", wantTables: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := storage.Open(ctx, tt.dsn)
+			require.NoError(t, err)
+			defer store.Close()
+
+			cfg := &config.Config{DatabaseDsn: tt.dsn, SkipMigrations: tt.skipMigrations}
+			require.NoError(t, migrateOnStart(ctx, cfg, store))
+
+			_, err = store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+			if tt.wantTables {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
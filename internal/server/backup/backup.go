@@ -0,0 +1,275 @@
+// Package backup резервное копирование сервера: снимок таблиц БД и содержимое файлов, на которое ссылаются
+// записи снимка, в одном архиве tar с манифестом и контрольными суммами SHA-256.
+//
+// Структура архива:
+//
+//	db/<table>.jsonl  строки таблицы в формате storage.Dump
+//	blobs/<key>       содержимое файла по ключу хранилища (путь без префикса _file_storage/)
+//	manifest.json     манифест, последний элемент архива
+//
+// Инкрементная копия содержит полный снимок БД, но только содержимое, которого нет в предыдущих архивах
+// цепочки; восстанавливается вместе со всеми архивами цепочки, начиная с полного.
+package backup
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+)
+
+// FormatVersion версия формата архива.
+const FormatVersion = 1
+
+const (
+	manifestName = "manifest.json"
+	tablePrefix  = "db/"
+	tableExt     = ".jsonl"
+	blobPrefix   = "blobs/"
+)
+
+var (
+	ErrInvalidArchive = errors.New("invalid backup archive")
+	ErrChecksum       = errors.New("backup checksum mismatch")
+	ErrChain          = errors.New("backup archives do not form a chain")
+)
+
+// Manifest описание архива резервной копии.
+type Manifest struct {
+	Format    int       `json:"format"`
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	// Base идентификаторы предыдущих архивов цепочки, начиная с полного; пусто у полной копии
+	Base []string `json:"base,omitempty"`
+	// Dialect и SchemaVersion диалект и версия схемы БД снимка
+	Dialect       string  `json:"dialect"`
+	SchemaVersion int64   `json:"schema_version"`
+	Tables        []Table `json:"tables"`
+	// Blobs все содержимое, на которое ссылается снимок, в том числе сохраненное в предыдущих архивах цепочки
+	Blobs []Blob `json:"blobs"`
+	// Missing пути файлов, на которые ссылается снимок, но содержимого которых не было в хранилище
+	Missing []string `json:"missing,omitempty"`
+}
+
+// Table таблица снимка БД в архиве.
+type Table struct {
+	Name   string `json:"name"`
+	Rows   int    `json:"rows"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Blob содержимое файла в архиве.
+type Blob struct {
+	// Path путь файла, как он записан в БД
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Archive идентификатор архива цепочки, в котором лежит содержимое
+	Archive string `json:"archive"`
+}
+
+// Chain идентификаторы архивов, нужных для восстановления, начиная с полного.
+func (m Manifest) Chain() []string {
+	return append(append([]string{}, m.Base...), m.ID)
+}
+
+// Create пишет в w архив резервной копии: снимок БД из store, содержимое файлов из files, на которое ссылаются
+// записи снимка, и манифест. С base создается инкрементная копия: содержимое, уже сохраненное в архивах
+// цепочки base, не копируется. Содержимое, которого нет в хранилище, перечисляется в Manifest.Missing.
+func Create(ctx context.Context, w io.Writer, store storage.Interface, files *file.StorageFiles, base *Manifest) (m Manifest, err error) {
+	m = Manifest{Format: FormatVersion, ID: newID(), CreatedAt: time.Now().UTC()}
+
+	baseBlobs := make(map[string]Blob)
+	if base != nil {
+		m.Base = base.Chain()
+
+		for _, b := range base.Blobs {
+			baseBlobs[b.Path] = b
+		}
+	}
+
+	tw := tar.NewWriter(w)
+
+	info, err := storage.Dump(ctx, store, func(table string) (io.WriteCloser, error) {
+		return newTableWriter(tw, table, &m)
+	})
+	if err != nil {
+		return m, fmt.Errorf("backup.Create: %w", err)
+	}
+
+	m.Dialect, m.SchemaVersion = info.Dialect, info.SchemaVersion
+
+	for _, t := range info.Tables {
+		for i := range m.Tables {
+			if m.Tables[i].Name == t.Name {
+				m.Tables[i].Rows = t.Rows
+			}
+		}
+	}
+
+	for _, p := range info.Paths {
+		if b, ok := baseBlobs[p]; ok {
+			m.Blobs = append(m.Blobs, b)
+			continue
+		}
+
+		b, err := writeBlob(ctx, tw, files, p)
+		if errors.Is(err, file.ErrNotFound) {
+			m.Missing = append(m.Missing, p)
+			continue
+		}
+
+		if err != nil {
+			return m, fmt.Errorf("backup.Create %s: %w", p, err)
+		}
+
+		b.Archive = m.ID
+		m.Blobs = append(m.Blobs, b)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return m, fmt.Errorf("backup.Create: %w", err)
+	}
+
+	if err = writeEntry(tw, manifestName, int64(len(data)), strings.NewReader(string(data))); err != nil {
+		return m, fmt.Errorf("backup.Create: %w", err)
+	}
+
+	if err = tw.Close(); err != nil {
+		return m, fmt.Errorf("backup.Create: %w", err)
+	}
+
+	return m, nil
+}
+
+// writeBlob копирует содержимое файла filePath в архив. Содержимое, адресованное хешем, сверяется с ним:
+// поврежденное содержимое в резервную копию не попадает.
+func writeBlob(ctx context.Context, tw *tar.Writer, files *file.StorageFiles, filePath string) (b Blob, err error) {
+	info, err := files.StatFile(ctx, filePath)
+	if err != nil {
+		return b, err
+	}
+
+	src, err := files.GetFile(ctx, filePath)
+	if err != nil {
+		return b, err
+	}
+	defer src.Close()
+
+	h := sha256.New()
+
+	err = writeEntry(tw, blobEntry(filePath), info.Size, io.TeeReader(io.LimitReader(src, info.Size), h))
+	if err != nil {
+		return b, err
+	}
+
+	b = Blob{Path: filePath, Size: info.Size, SHA256: hex.EncodeToString(h.Sum(nil))}
+
+	if want := file.ContentHash(filePath); want != "" && want != b.SHA256 {
+		return b, fmt.Errorf("%w: stored content does not match its hash", ErrChecksum)
+	}
+
+	return b, nil
+}
+
+// tableWriter принимает строки таблицы во временный файл: размер элемента tar нужно знать до записи.
+// При закрытии файл переносится в архив, таблица добавляется в манифест.
+type tableWriter struct {
+	tw   *tar.Writer
+	m    *Manifest
+	name string
+	f    *os.File
+	hash hash.Hash
+	w    io.Writer
+}
+
+func newTableWriter(tw *tar.Writer, table string, m *Manifest) (*tableWriter, error) {
+	f, err := os.CreateTemp("", "gophkeeper_backup")
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+
+	return &tableWriter{tw: tw, m: m, name: table, f: f, hash: h, w: io.MultiWriter(f, h)}, nil
+}
+
+func (t *tableWriter) Write(p []byte) (int, error) {
+	return t.w.Write(p)
+}
+
+func (t *tableWriter) Close() error {
+	defer func() {
+		t.f.Close()
+		os.Remove(t.f.Name())
+	}()
+
+	size, err := t.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	if _, err = t.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err = writeEntry(t.tw, tablePrefix+t.name+tableExt, size, t.f); err != nil {
+		return err
+	}
+
+	t.m.Tables = append(t.m.Tables, Table{Name: t.name, Size: size, SHA256: hex.EncodeToString(t.hash.Sum(nil))})
+
+	return nil
+}
+
+// writeEntry добавляет в архив файл name размером size.
+func writeEntry(tw *tar.Writer, name string, size int64, src io.Reader) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o600,
+		Size:     size,
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(tw, src)
+	if err != nil {
+		return err
+	}
+
+	if n != size {
+		return fmt.Errorf("%s: %w", name, io.ErrUnexpectedEOF)
+	}
+
+	return nil
+}
+
+// blobEntry имя элемента архива с содержимым файла filePath.
+func blobEntry(filePath string) string {
+	return blobPrefix + strings.TrimPrefix(file.CleanPath(filePath), file.URLPrefix+"/")
+}
+
+// newID идентификатор архива: время создания и случайный суффикс.
+func newID() string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,215 @@
+package backup_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/backup"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// instance БД SQLite со схемой и хранилище файлов во временном каталоге.
+func instance(t *testing.T, migrate bool) (storage.Interface, *file.StorageFiles) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	store, err := storage.Open(ctx, "sqlite://"+filepath.Join(t.TempDir(), "gophkeeper.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	if migrate {
+		m, err := storage.NewMigrator(store)
+		require.NoError(t, err)
+		defer m.Close()
+
+		require.NoError(t, m.Up(ctx))
+	}
+
+	files, err := file.New(t.TempDir())
+	require.NoError(t, err)
+
+	return store, files
+}
+
+// saveFile сохраняет файл пользователя с содержимым content.
+func saveFile(t *testing.T, store storage.Interface, files *file.StorageFiles, userID int, content string) string {
+	t.Helper()
+
+	ctx := context.Background()
+
+	path, err := files.SaveFile(ctx, strings.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+
+	_, err = store.SaveFile(ctx, model.DataFile{UserID: userID, Title: "file", Filename: "f.txt", Path: path, Size: int64(len(content))})
+	require.NoError(t, err)
+
+	return path
+}
+
+// create пишет резервную копию в файл во временном каталоге.
+func create(t *testing.T, store storage.Interface, files *file.StorageFiles, base *backup.Manifest) (string, backup.Manifest) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "backup.tar")
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	m, err := backup.Create(context.Background(), f, store, files, base)
+	require.NoError(t, err)
+
+	return path, m
+}
+
+func readFile(t *testing.T, files *file.StorageFiles, path string) string {
+	t.Helper()
+
+	r, err := files.GetFile(context.Background(), path)
+	require.NoError(t, err)
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestCreateRestore(t *testing.T) {
+	ctx := context.Background()
+
+	src, srcFiles := instance(t, true)
+
+	userID, err := src.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	first := saveFile(t, src, srcFiles, userID, "first file")
+	gone := saveFile(t, src, srcFiles, userID, "deleted content")
+	require.NoError(t, srcFiles.DeleteFile(ctx, gone))
+
+	full, fullManifest := create(t, src, srcFiles, nil)
+	assert.Empty(t, fullManifest.Base)
+	assert.Equal(t, []string{gone}, fullManifest.Missing)
+	require.Len(t, fullManifest.Blobs, 1)
+	assert.Equal(t, fullManifest.ID, fullManifest.Blobs[0].Archive)
+
+	second := saveFile(t, src, srcFiles, userID, "second file")
+
+	incr, incrManifest := create(t, src, srcFiles, &fullManifest)
+	assert.Equal(t, []string{fullManifest.ID}, incrManifest.Base)
+	require.Len(t, incrManifest.Blobs, 2)
+
+	archives := map[string]string{}
+	for _, b := range incrManifest.Blobs {
+		archives[b.Path] = b.Archive
+	}
+	assert.Equal(t, map[string]string{first: fullManifest.ID, second: incrManifest.ID}, archives)
+
+	tests := []struct {
+		name      string
+		archives  []string
+		wantFiles []string
+		wantErr   error
+	}{
+		{name: "full", archives: []string{full}, wantFiles: []string{first}},
+		{name: "incremental", archives: []string{full, incr}, wantFiles: []string{first, second}},
+		{name: "incremental without base", archives: []string{incr}, wantErr: backup.ErrChain},
+		{name: "wrong order", archives: []string{incr, full}, wantErr: backup.ErrChain},
+		{name: "no archives", wantErr: backup.ErrInvalidArchive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst, dstFiles := instance(t, false)
+
+			m, err := backup.Restore(ctx, tt.archives, dst, dstFiles)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, m.Chain(), len(tt.archives))
+
+			id, err := dst.GetUserIDByCredentials(ctx, "user", "password")
+			require.NoError(t, err)
+			assert.Equal(t, userID, id)
+
+			got, err := dst.FindAllFiles(ctx, userID, model.ItemFilter{})
+			require.NoError(t, err)
+			assert.Len(t, got, len(tt.wantFiles)+1)
+
+			for _, path := range tt.wantFiles {
+				want := readFile(t, srcFiles, path)
+				assert.Equal(t, want, readFile(t, dstFiles, path))
+			}
+
+			// восстановление поверх восстановленного
+			_, err = backup.Restore(ctx, tt.archives, dst, dstFiles)
+			assert.ErrorIs(t, err, storage.ErrorRestoreNotEmpty)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+
+	src, srcFiles := instance(t, true)
+
+	userID, err := src.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	saveFile(t, src, srcFiles, userID, "precious content")
+
+	path, _ := create(t, src, srcFiles, nil)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	corrupt := func(t *testing.T, old, new string) string {
+		t.Helper()
+
+		i := bytes.Index(data, []byte(old))
+		require.GreaterOrEqual(t, i, 0)
+
+		out := append(append(append([]byte{}, data[:i]...), new...), data[i+len(old):]...)
+		p := filepath.Join(t.TempDir(), "corrupt.tar")
+		require.NoError(t, os.WriteFile(p, out, 0o600))
+
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		path    func(t *testing.T) string
+		wantErr error
+	}{
+		{name: "valid", path: func(t *testing.T) string { return path }},
+		{name: "blob changed", path: func(t *testing.T) string { return corrupt(t, "precious", "PRECIOUS") }, wantErr: backup.ErrChecksum},
+		{name: "table changed", path: func(t *testing.T) string { return corrupt(t, `"login":"user"`, `"login":"evil"`) }, wantErr: backup.ErrChecksum},
+		{name: "truncated", path: func(t *testing.T) string {
+			p := filepath.Join(t.TempDir(), "truncated.tar")
+			require.NoError(t, os.WriteFile(p, data[:len(data)/2], 0o600))
+			return p
+		}, wantErr: backup.ErrInvalidArchive},
+		{name: "not found", path: func(t *testing.T) string { return filepath.Join(t.TempDir(), "none.tar") }, wantErr: os.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := backup.Verify([]string{tt.path(t)})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
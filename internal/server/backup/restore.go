@@ -0,0 +1,283 @@
+package backup
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+)
+
+// entry размер и хеш элемента архива.
+type entry struct {
+	size   int64
+	sha256 string
+}
+
+// ReadManifest читает манифест архива без проверки содержимого, например чтобы взять его базой инкрементной копии.
+func ReadManifest(path string) (m Manifest, err error) {
+	found := false
+
+	err = readArchive(path, func(hdr *tar.Header, r io.Reader) error {
+		if hdr.Name != manifestName {
+			return nil
+		}
+
+		found = true
+
+		return decodeManifest(r, &m)
+	})
+	if err == nil && !found {
+		err = fmt.Errorf("%w: no %s", ErrInvalidArchive, manifestName)
+	}
+
+	if err != nil {
+		return m, fmt.Errorf("backup.ReadManifest: %w", err)
+	}
+
+	return m, nil
+}
+
+// Verify проверяет цепочку архивов paths, начиная с полного: контрольные суммы всех элементов по манифестам,
+// порядок и полноту цепочки и наличие в ней всего содержимого, на которое ссылается последний архив.
+// Возвращает манифест последнего архива.
+func Verify(paths []string) (Manifest, error) {
+	m, _, err := verifyChain(paths)
+	if err != nil {
+		return m, fmt.Errorf("backup.Verify: %w", err)
+	}
+
+	return m, nil
+}
+
+// Restore восстанавливает цепочку архивов paths (начиная с полного) в пустой экземпляр: проверяет архивы,
+// записывает содержимое файлов в files и загружает снимок БД последнего архива в store одной транзакцией.
+// Если БД не пуста, ничего не записывается, ошибка storage.ErrorRestoreNotEmpty.
+func Restore(ctx context.Context, paths []string, store storage.Interface, files *file.StorageFiles) (Manifest, error) {
+	m, blobs, err := verifyChain(paths)
+	if err != nil {
+		return m, fmt.Errorf("backup.Restore: %w", err)
+	}
+
+	r, err := storage.NewRestorer(ctx, store, storage.DumpInfo{Dialect: m.Dialect, SchemaVersion: m.SchemaVersion})
+	if err != nil {
+		return m, fmt.Errorf("backup.Restore: %w", err)
+	}
+	defer r.Close()
+
+	rows := make(map[string]int, len(m.Tables))
+	for _, t := range m.Tables {
+		rows[t.Name] = t.Rows
+	}
+
+	chain := m.Chain()
+	for i, path := range paths {
+		last := i == len(paths)-1
+
+		err = readArchive(path, func(hdr *tar.Header, src io.Reader) error {
+			if b, ok := blobs[chain[i]][hdr.Name]; ok {
+				return files.RestoreFile(ctx, b.Path, src, b.Size)
+			}
+
+			if !last || !strings.HasPrefix(hdr.Name, tablePrefix) {
+				return nil
+			}
+
+			table := strings.TrimSuffix(strings.TrimPrefix(hdr.Name, tablePrefix), tableExt)
+			if _, ok := rows[table]; !ok {
+				// не описана в манифесте, значит не проверена
+				return nil
+			}
+
+			n, err := r.LoadTable(ctx, table, src)
+			if err == nil && n != rows[table] {
+				err = fmt.Errorf("%w: table %s has %d rows, manifest %d", ErrInvalidArchive, table, n, rows[table])
+			}
+
+			return err
+		})
+		if err != nil {
+			return m, fmt.Errorf("backup.Restore %s: %w", path, err)
+		}
+	}
+
+	if err = r.Commit(ctx); err != nil {
+		return m, fmt.Errorf("backup.Restore: %w", err)
+	}
+
+	return m, nil
+}
+
+// verifyChain проверяет цепочку архивов и возвращает манифест последнего и содержимое для восстановления:
+// по идентификатору архива - элементы с содержимым и их описание.
+func verifyChain(paths []string) (m Manifest, blobs map[string]map[string]Blob, err error) {
+	if len(paths) == 0 {
+		return m, nil, fmt.Errorf("%w: no archives", ErrInvalidArchive)
+	}
+
+	entries := make(map[string]map[string]entry, len(paths))
+	ids := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		var archived map[string]entry
+
+		m, archived, err = verifyArchive(path)
+		if err != nil {
+			return m, nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		if _, ok := entries[m.ID]; ok {
+			return m, nil, fmt.Errorf("%w: archive %s given twice", ErrChain, m.ID)
+		}
+
+		entries[m.ID] = archived
+		ids = append(ids, m.ID)
+	}
+
+	// m - манифест последнего архива, он перечисляет всю цепочку
+	chain := m.Chain()
+	if strings.Join(chain, ",") != strings.Join(ids, ",") {
+		return m, nil, fmt.Errorf("%w: %s needs archives %s, given %s", ErrChain, paths[len(paths)-1],
+			strings.Join(chain, ", "), strings.Join(ids, ", "))
+	}
+
+	blobs = make(map[string]map[string]Blob, len(chain))
+	for _, b := range m.Blobs {
+		name := blobEntry(b.Path)
+
+		e, ok := entries[b.Archive][name]
+		if !ok {
+			return m, nil, fmt.Errorf("%w: content of %s is not in archive %q", ErrChain, b.Path, b.Archive)
+		}
+
+		if e.size != b.Size || e.sha256 != b.SHA256 {
+			return m, nil, fmt.Errorf("%w: %s in archive %s", ErrChecksum, b.Path, b.Archive)
+		}
+
+		if blobs[b.Archive] == nil {
+			blobs[b.Archive] = make(map[string]Blob)
+		}
+		blobs[b.Archive][name] = b
+	}
+
+	return m, blobs, nil
+}
+
+// verifyArchive проверяет один архив: элементы, перечисленные в манифесте, есть в архиве
+// и совпадают по размеру и хешу. Возвращает манифест и размеры и хеши всех элементов.
+func verifyArchive(path string) (m Manifest, entries map[string]entry, err error) {
+	entries = make(map[string]entry)
+	found := false
+
+	err = readArchive(path, func(hdr *tar.Header, r io.Reader) error {
+		if hdr.Name == manifestName {
+			found = true
+			return decodeManifest(r, &m)
+		}
+
+		if _, ok := entries[hdr.Name]; ok {
+			return fmt.Errorf("%w: duplicate %s", ErrInvalidArchive, hdr.Name)
+		}
+
+		h := sha256.New()
+
+		n, err := io.Copy(h, r)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrInvalidArchive, hdr.Name, err)
+		}
+
+		entries[hdr.Name] = entry{size: n, sha256: hex.EncodeToString(h.Sum(nil))}
+
+		return nil
+	})
+	if err != nil {
+		return m, nil, err
+	}
+
+	if !found {
+		return m, nil, fmt.Errorf("%w: no %s", ErrInvalidArchive, manifestName)
+	}
+
+	for _, t := range m.Tables {
+		e, ok := entries[tablePrefix+t.Name+tableExt]
+		if !ok {
+			return m, nil, fmt.Errorf("%w: no table %s", ErrInvalidArchive, t.Name)
+		}
+
+		if e.size != t.Size || e.sha256 != t.SHA256 {
+			return m, nil, fmt.Errorf("%w: table %s", ErrChecksum, t.Name)
+		}
+	}
+
+	for _, b := range m.Blobs {
+		if b.Archive != m.ID {
+			continue
+		}
+
+		e, ok := entries[blobEntry(b.Path)]
+		if !ok {
+			return m, nil, fmt.Errorf("%w: no content of %s", ErrInvalidArchive, b.Path)
+		}
+
+		if e.size != b.Size || e.sha256 != b.SHA256 {
+			return m, nil, fmt.Errorf("%w: %s", ErrChecksum, b.Path)
+		}
+	}
+
+	return m, entries, nil
+}
+
+// decodeManifest читает манифест и проверяет версию формата.
+func decodeManifest(r io.Reader, m *Manifest) error {
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidArchive, manifestName, err)
+	}
+
+	if m.Format != FormatVersion {
+		return fmt.Errorf("%w: format %d, supported %d", ErrInvalidArchive, m.Format, FormatVersion)
+	}
+
+	if m.ID == "" {
+		return fmt.Errorf("%w: %s has no id", ErrInvalidArchive, manifestName)
+	}
+
+	return nil
+}
+
+// readArchive вызывает fn для каждого файла архива path.
+func readArchive(path string, fn func(hdr *tar.Header, r io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err = fn(hdr, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -15,6 +15,21 @@ type Config struct {
 	JWTAccessTokenTTL  string `env:"JWT_ACCESS_TOKEN_TTL" envDefault:"10h" json:"jwtAccessTokenTTL"`
 	JWTRefreshTokenTTL string `env:"JWT_REFRESH_TOKEN_TTL" envDefault:"720h" json:"jwtRefreshTokenTTL"`
 	EnableTLS          bool   `env:"ENABLE_TLS" envDefault:"false" json:"enableTLS"`
+	DevMode            bool   `env:"DEV_MODE" envDefault:"false" json:"devMode"`
+	SkipMigrations     bool   `env:"SKIP_MIGRATIONS" envDefault:"false" json:"skipMigrations"`
+	// Квоты пользователя, 0 - без ограничения; по умолчанию не заданы.
+	QuotaBytes    int64 `env:"QUOTA_BYTES" envDefault:"0" json:"quotaBytes"`
+	QuotaFileSize int64 `env:"QUOTA_FILE_SIZE" envDefault:"0" json:"quotaFileSize"`
+	QuotaItems    int   `env:"QUOTA_ITEMS" envDefault:"0" json:"quotaItems"`
+	// Размер тела запроса, кроме содержимого файлов и частей загрузки, 0 - без ограничения.
+	MaxBodySize int64 `env:"MAX_BODY_SIZE" envDefault:"4194304" json:"maxBodySize"`
+	// Размер части возобновляемой загрузки файла, 0 - model.DefaultUploadChunkSize.
+	UploadChunkSize int64 `env:"UPLOAD_CHUNK_SIZE" envDefault:"8388608" json:"uploadChunkSize"`
+	// Сборка мусора в хранилище файлов: период проверки (0 - отключена), срок, после которого неиспользуемое
+	// содержимое считается брошенным, и удаление найденного (иначе только отчет в журнале).
+	GCInterval    string `env:"GC_INTERVAL" envDefault:"24h" json:"gcInterval"`
+	GCGracePeriod string `env:"GC_GRACE_PERIOD" envDefault:"24h" json:"gcGracePeriod"`
+	GCDelete      bool   `env:"GC_DELETE" envDefault:"false" json:"gcDelete"`
 }
 
 var once sync.Once //nolint:gochecknoglobals
@@ -23,8 +38,11 @@ func (c *Config) readCommandLineArgs() {
 	once.Do(func() {
 		flag.StringVar(&c.ServerAddress, "a", c.ServerAddress, "server and port to listen on")
 		flag.StringVar(&c.DatabaseDsn, "d", c.DatabaseDsn, "database dsn")
+		flag.StringVar(&c.FileStorage, "f", c.FileStorage, "file storage: directory or s3://access_key:secret_key@host/bucket")
 		flag.StringVar(&c.JWTSecretKey, "j", c.JWTSecretKey, "jwt secret key")
 		flag.BoolVar(&c.EnableTLS, "s", c.EnableTLS, "enable secure mode")
+		flag.BoolVar(&c.DevMode, "dev", c.DevMode, "development mode, validate requests against openapi spec")
+		flag.BoolVar(&c.SkipMigrations, "skip-migrations", c.SkipMigrations, "do not apply database migrations on startup")
 		flag.Parse()
 	})
 }
@@ -22,6 +22,13 @@ func TestReadConfig(t *testing.T) {
 				JWTAccessTokenTTL:  "10h",
 				JWTRefreshTokenTTL: "720h",
 				EnableTLS:          false,
+				DevMode:            false,
+				SkipMigrations:     false,
+				MaxBodySize:        4 << 20,
+				UploadChunkSize:    8 << 20,
+				GCInterval:         "24h",
+				GCGracePeriod:      "24h",
+				GCDelete:           false,
 			},
 		},
 	}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// SaveAttachment прикрепляет к записи файл из multipart-формы: file - содержимое,
+// item_type и item_id - запись, filename - имя файла, зашифрованное клиентом.
+func (h *Handler) SaveAttachment(c *gin.Context) {
+	var att model.Attachment
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveAttachment Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	h.limitUpload(c)
+
+	formFile, err := c.FormFile("file")
+	if err != nil {
+		logger.Error("SaveAttachment Handler: ", err)
+		abortWithFormFileError(c, err)
+
+		return
+	}
+
+	t, err := time.Parse(time.RFC3339, c.PostForm("updated_at"))
+	if err != nil {
+		logger.Error("SaveAttachment Handler updated_at format RFC3339 error: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	itemID, err := strconv.Atoi(c.PostForm("item_id"))
+	if err != nil {
+		logger.Error("SaveAttachment Handler parse item_id error: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	src, err := formFile.Open()
+	if err != nil {
+		logger.Error("SaveAttachment Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	filePath, err := h.service.StoreFiles.SaveFile(c, src, formFile.Size)
+	if err != nil {
+		logger.Error("SaveAttachment Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	att.UserID = userID
+	att.ItemType = c.PostForm("item_type")
+	att.ItemID = itemID
+	att.Filename = c.PostForm("filename")
+	att.Path = filePath
+	att.Size = formFile.Size
+	att.UpdatedAt = t
+
+	id, err := h.service.SaveAttachment(c, att)
+	if err != nil {
+		logger.Error("SaveAttachment Handler: ", err, att)
+		if errDel := h.service.ReleaseFile(c, filePath); errDel != nil {
+			logger.Error("SaveAttachment Handler delete file error: ", errDel)
+		}
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *Handler) DeleteAttachment(c *gin.Context) {
+	var err error
+	var rb model.Attachment
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteAttachment Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteAttachment Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteAttachment(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("DeleteAttachment Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) FindAllAttachments(c *gin.Context) {
+	var err error
+	var filter model.AttachmentFilter
+
+	err = c.ShouldBindQuery(&filter)
+	if err != nil {
+		logger.Error("FindAllAttachments Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllAttachments Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	atts, err := h.service.FindAllAttachments(c, userID, filter)
+	if err != nil {
+		logger.Error("FindAllAttachments Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(atts) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, atts)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// FindContent проверяет, есть ли у пользователя содержимое с хешем SHA-256: такое содержимое клиент
+// не загружает повторно, а передает в SaveFile только хеш. Содержимое других пользователей не находится.
+func (h *Handler) FindContent(c *gin.Context) {
+	hash := c.Param("sha256")
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindContent Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	_, info, err := h.service.FindUserContent(c, userID, hash)
+	if err != nil {
+		logger.Error("FindContent Handler: ", err, hash)
+		abortWithContentError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Content{SHA256: hash, Size: info.Size})
+}
+
+// receiveFile сохраняет содержимое файла из поля file формы и возвращает его путь, имя и размер.
+// Если файла в форме нет, используется доступное пользователю userID содержимое с хешем из поля sha256
+// (см. service.FindUserContent), имя файла берется из поля filename. При ошибке отвечает клиенту
+// и возвращает ok=false.
+func (h *Handler) receiveFile(c *gin.Context, userID int) (filePath, filename string, size int64, ok bool) {
+	formFile, err := c.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) {
+		if hash := c.PostForm("sha256"); hash != "" {
+			filePath, info, err := h.service.FindUserContent(c, userID, hash)
+			if err != nil {
+				logger.Error("receiveFile: ", err, hash)
+				abortWithContentError(c, err)
+
+				return "", "", 0, false
+			}
+
+			return filePath, c.PostForm("filename"), info.Size, true
+		}
+	}
+
+	if err != nil {
+		logger.Error("receiveFile: ", err)
+		abortWithFormFileError(c, err)
+
+		return "", "", 0, false
+	}
+
+	src, err := formFile.Open()
+	if err != nil {
+		logger.Error("receiveFile: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return "", "", 0, false
+	}
+	defer src.Close()
+
+	filePath, err = h.service.StoreFiles.SaveFile(c, src, formFile.Size)
+	if err != nil {
+		logger.Error("receiveFile: ", err)
+		abortWithError(c, err)
+
+		return "", "", 0, false
+	}
+
+	return filePath, formFile.Filename, formFile.Size, true
+}
+
+// abortWithContentError отвечает на ошибку поиска содержимого по хешу.
+func abortWithContentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, file.ErrInvalidHash):
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+	case errors.Is(err, file.ErrNotFound):
+		abortWithProblem(c, http.StatusNotFound, model.ProblemCodeNotFound, "content not found")
+	default:
+		abortWithError(c, err)
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/service"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_FindContent(t *testing.T) {
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := service.New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key", JWTAccessTokenTTL: "10m", JWTRefreshTokenTTL: "1h"})
+	r := NewHandler(s).Init()
+
+	// содержимое файла владельца
+	filePath, err := storeFiles.SaveFile(ctx, strings.NewReader("Hello, world!"), -1)
+	require.NoError(t, err)
+	hash := file.ContentHash(filePath)
+
+	ownerID, err := store.CreateUser(ctx, model.User{Login: "owner", Password: "password"})
+	require.NoError(t, err)
+	_, err = store.SaveFile(ctx, model.DataFile{UserID: ownerID, Title: "file", Filename: "file.txt", Path: filePath})
+	require.NoError(t, err)
+	owner, err := s.CreateSession(ctx, ownerID)
+	require.NoError(t, err)
+
+	// пользователь, который сам загрузил то же содержимое, но еще не сохранил файл
+	uploaderID, err := store.CreateUser(ctx, model.User{Login: "uploader", Password: "password"})
+	require.NoError(t, err)
+	upload, err := s.CreateUpload(ctx, model.Upload{UserID: uploaderID, Size: 13})
+	require.NoError(t, err)
+	_, err = s.WriteUploadChunk(ctx, upload.ID, uploaderID, 0, strings.NewReader("Hello, world!"), 13)
+	require.NoError(t, err)
+	_, err = s.CompleteUpload(ctx, upload.ID, uploaderID, hash)
+	require.NoError(t, err)
+	uploader, err := s.CreateSession(ctx, uploaderID)
+	require.NoError(t, err)
+
+	otherID, err := store.CreateUser(ctx, model.User{Login: "other", Password: "password"})
+	require.NoError(t, err)
+	other, err := s.CreateSession(ctx, otherID)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		token      string
+		hash       string
+		wantStatus int
+	}{
+		{name: "owner", token: owner.AccessToken, hash: hash, wantStatus: http.StatusOK},
+		{name: "uploader", token: uploader.AccessToken, hash: hash, wantStatus: http.StatusOK},
+		{name: "other user", token: other.AccessToken, hash: hash, wantStatus: http.StatusNotFound},
+		{name: "missing", token: owner.AccessToken, hash: strings.Repeat("0", 64), wantStatus: http.StatusNotFound},
+		{name: "invalid hash", token: owner.AccessToken, hash: "bad", wantStatus: http.StatusBadRequest},
+		{name: "anonymous", hash: hash, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/store/content/"+tt.hash, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"size":13`)
+			}
+		})
+	}
+
+	// сохранить файл по хешу может только тот, кому содержимое доступно
+	saves := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "save other user", token: other.AccessToken, wantStatus: http.StatusNotFound},
+		{name: "save owner", token: owner.AccessToken, wantStatus: http.StatusCreated},
+		{name: "save uploader", token: uploader.AccessToken, wantStatus: http.StatusCreated},
+	}
+	for _, tt := range saves {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			fields := map[string]string{
+				"id":         "0",
+				"title":      "file",
+				"sha256":     hash,
+				"filename":   "file.txt",
+				"updated_at": time.Now().Format(time.RFC3339),
+			}
+			for k, v := range fields {
+				require.NoError(t, form.WriteField(k, v))
+			}
+			require.NoError(t, form.Close())
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/store/file", &body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+
+	files, err := store.FindAllFiles(ctx, otherID, model.ItemFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
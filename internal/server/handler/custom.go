@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+func (h *Handler) SaveCustomItem(c *gin.Context) {
+	var err error
+	var rb model.DataCustom
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("SaveCustomItem Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveCustomItem Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	rb.UserID = userID
+
+	id, err := h.service.SaveCustomItem(c, rb)
+	if err != nil {
+		logger.Error("SaveCustomItem Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *Handler) DeleteCustomItem(c *gin.Context) {
+	var err error
+	var rb model.DataCustom
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteCustomItem Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteCustomItem Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteCustomItem(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("DeleteCustomItem Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) FindCustomItem(c *gin.Context) {
+	var err error
+	var rb model.DataCustom
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("FindCustomItem Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindCustomItem Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	item, err := h.service.FindCustomItem(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("FindCustomItem Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *Handler) FindAllCustomItems(c *gin.Context) {
+	var err error
+	var filter model.ItemFilter
+
+	err = c.ShouldBindQuery(&filter)
+	if err != nil {
+		logger.Error("FindAllCustomItems Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllCustomItems Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	items, err := h.service.FindAllCustomItems(c, userID, filter)
+	if err != nil {
+		logger.Error("FindAllCustomItems Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(items) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+var (
+	errRangeInvalid        = errors.New("invalid range")
+	errRangeNotSatisfiable = errors.New("range not satisfiable")
+)
+
+// DownloadFile отдает содержимое файла потоком из хранилища файлов (диск или S3), если на него ссылается
+// файл или вложение пользователя; иначе 404, как и для отсутствующего содержимого.
+// Поддерживается один диапазон Range (в том числе с If-Range) для продолжения прерванной загрузки
+// и If-None-Match; ETag содержимого, адресуемого хешем, - его SHA-256. Заголовки содержимого и ответы
+// на условные запросы отдаются только после проверки доступа, чтобы по ним нельзя было проверить хеш.
+func (h *Handler) DownloadFile(c *gin.Context) {
+	filePath := file.URLPrefix + c.Param("filepath")
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DownloadFile Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	info, err := h.service.StatUserFile(c, userID, filePath)
+	if errors.Is(err, file.ErrNotFound) {
+		abortWithProblem(c, http.StatusNotFound, model.ProblemCodeNotFound, "file not found")
+
+		return
+	}
+
+	if err != nil {
+		logger.Error("DownloadFile Handler: ", err, filePath)
+		abortWithError(c, err)
+
+		return
+	}
+
+	etag := fileETag(filePath, info)
+	c.Header("ETag", etag)
+	c.Header("Accept-Ranges", "bytes")
+
+	if etagMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+
+		return
+	}
+
+	status, offset, length := http.StatusOK, int64(0), info.Size
+
+	// диапазон учитывается, только если файл не изменился с прошлой загрузки (If-Range)
+	if rangeHeader := c.GetHeader("Range"); rangeHeader != "" && ifRange(c.GetHeader("If-Range"), etag) {
+		offset, length, err = parseRange(rangeHeader, info.Size)
+		switch {
+		case errors.Is(err, errRangeNotSatisfiable):
+			c.Header("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+			abortWithProblem(c, http.StatusRequestedRangeNotSatisfiable, model.ProblemCodeInvalidRequest, err.Error())
+
+			return
+		case err != nil:
+			// некорректный или составной диапазон игнорируется, файл отдается целиком
+			offset, length = 0, info.Size
+		default:
+			status = http.StatusPartialContent
+			c.Header("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, info.Size))
+		}
+	}
+
+	var src io.ReadCloser
+	if status == http.StatusPartialContent {
+		src, err = h.service.StoreFiles.GetFileRange(c, filePath, offset, length)
+	} else {
+		src, err = h.service.StoreFiles.GetFile(c, filePath)
+	}
+
+	if err != nil {
+		logger.Error("DownloadFile Handler: ", err, filePath)
+		abortWithError(c, err)
+
+		return
+	}
+	defer src.Close()
+
+	c.DataFromReader(status, length, "application/octet-stream", src, nil)
+}
+
+// fileETag сильный ETag файла: хеш SHA-256 содержимого, для путей без хеша - размер и время изменения.
+func fileETag(filePath string, info file.BlobInfo) string {
+	if hash := file.ContentHash(filePath); hash != "" {
+		return `"` + hash + `"`
+	}
+
+	return fmt.Sprintf(`"%x-%x"`, info.Size, info.ModTime.UnixNano())
+}
+
+// etagMatch проверяет заголовок If-None-Match: "*" или список ETag через запятую.
+func etagMatch(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ifRange проверяет условие If-Range: диапазон отдается, если заголовка нет или ETag совпадает.
+// Условие по дате не поддерживается, в этом случае файл отдается целиком.
+func ifRange(header, etag string) bool {
+	return header == "" || header == etag
+}
+
+// parseRange разбирает заголовок Range с одним диапазоном байт (bytes=a-b, bytes=a-, bytes=-n)
+// и возвращает смещение и длину диапазона в файле размером size.
+func parseRange(header string, size int64) (offset, length int64, err error) {
+	if !strings.HasPrefix(header, "bytes=") || strings.Contains(header, ",") {
+		return 0, 0, errRangeInvalid
+	}
+
+	first, last, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(header, "bytes=")), "-")
+	if !ok {
+		return 0, 0, errRangeInvalid
+	}
+
+	if first == "" {
+		// последние n байт
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, errRangeInvalid
+		}
+
+		if n == 0 || size == 0 {
+			return 0, 0, errRangeNotSatisfiable
+		}
+
+		if n > size {
+			n = size
+		}
+
+		return size - n, n, nil
+	}
+
+	offset, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || offset < 0 {
+		return 0, 0, errRangeInvalid
+	}
+
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < offset {
+			return 0, 0, errRangeInvalid
+		}
+	}
+
+	if offset >= size {
+		return 0, 0, errRangeNotSatisfiable
+	}
+
+	if end >= size {
+		end = size - 1
+	}
+
+	return offset, end - offset + 1, nil
+}
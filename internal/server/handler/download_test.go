@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/service"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_DownloadFile(t *testing.T) {
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := service.New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key", JWTAccessTokenTTL: "10m", JWTRefreshTokenTTL: "1h"})
+	r := NewHandler(s).Init()
+
+	filePath, err := storeFiles.SaveFile(ctx, strings.NewReader("Hello, world!"), -1)
+	require.NoError(t, err)
+	etag := `"` + file.ContentHash(filePath) + `"`
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+	_, err = store.SaveFile(ctx, model.DataFile{UserID: userID, Title: "file", Filename: "file.txt", Path: filePath})
+	require.NoError(t, err)
+	tokens, err := s.CreateSession(ctx, userID)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		header     map[string]string
+		wantStatus int
+		wantBody   string
+		wantRange  string
+	}{
+		{name: "full", wantStatus: http.StatusOK, wantBody: "Hello, world!"},
+		{name: "range", header: map[string]string{"Range": "bytes=7-"}, wantStatus: http.StatusPartialContent, wantBody: "world!", wantRange: "bytes 7-12/13"},
+		{name: "closed range", header: map[string]string{"Range": "bytes=0-4"}, wantStatus: http.StatusPartialContent, wantBody: "Hello", wantRange: "bytes 0-4/13"},
+		{name: "suffix", header: map[string]string{"Range": "bytes=-6"}, wantStatus: http.StatusPartialContent, wantBody: "world!", wantRange: "bytes 7-12/13"},
+		{name: "if-range match", header: map[string]string{"Range": "bytes=7-", "If-Range": etag}, wantStatus: http.StatusPartialContent, wantBody: "world!", wantRange: "bytes 7-12/13"},
+		{name: "if-range changed", header: map[string]string{"Range": "bytes=7-", "If-Range": `"other"`}, wantStatus: http.StatusOK, wantBody: "Hello, world!"},
+		{name: "multiple ranges", header: map[string]string{"Range": "bytes=0-1,3-4"}, wantStatus: http.StatusOK, wantBody: "Hello, world!"},
+		{name: "not satisfiable", header: map[string]string{"Range": "bytes=13-"}, wantStatus: http.StatusRequestedRangeNotSatisfiable, wantRange: "bytes */13"},
+		{name: "not modified", header: map[string]string{"If-None-Match": etag}, wantStatus: http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/"+filePath, nil)
+			req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Equal(t, tt.wantRange, w.Header().Get("Content-Range"))
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+				assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+				assert.Equal(t, strconv.Itoa(len(tt.wantBody)), w.Header().Get("Content-Length"))
+			}
+		})
+	}
+
+}
+
+func TestHandler_DownloadFile_Access(t *testing.T) {
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := service.New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key", JWTAccessTokenTTL: "10m", JWTRefreshTokenTTL: "1h"})
+	r := NewHandler(s).Init()
+
+	filePath, err := storeFiles.SaveFile(ctx, strings.NewReader("Hello, world!"), -1)
+	require.NoError(t, err)
+
+	user := func(login string) (int, string) {
+		userID, err := store.CreateUser(ctx, model.User{Login: login, Password: "password"})
+		require.NoError(t, err)
+		tokens, err := s.CreateSession(ctx, userID)
+		require.NoError(t, err)
+
+		return userID, tokens.AccessToken
+	}
+
+	ownerID, owner := user("owner")
+	_, other := user("other")
+	attachmentID, attachment := user("attachment")
+
+	fileID, err := store.SaveFile(ctx, model.DataFile{UserID: ownerID, Title: "file", Filename: "file.txt", Path: filePath})
+	require.NoError(t, err)
+	_, err = store.SaveAttachment(ctx, model.Attachment{UserID: attachmentID, ItemType: model.ItemTypeFile, ItemID: fileID, Filename: "a", Path: filePath})
+	require.NoError(t, err)
+
+	etag := `"` + file.ContentHash(filePath) + `"`
+	// по условным запросам и диапазонам нельзя узнать, что содержимое с этим хешем есть на сервере
+	resume := map[string]string{"Range": "bytes=7-", "If-Range": etag}
+	cached := map[string]string{"If-None-Match": etag}
+
+	tests := []struct {
+		name       string
+		token      string
+		path       string
+		header     map[string]string
+		wantStatus int
+	}{
+		{name: "owner", token: owner, path: filePath, wantStatus: http.StatusOK},
+		{name: "owner resume", token: owner, path: filePath, header: resume, wantStatus: http.StatusPartialContent},
+		{name: "owner cached", token: owner, path: filePath, header: cached, wantStatus: http.StatusNotModified},
+		{name: "attachment", token: attachment, path: filePath, wantStatus: http.StatusOK},
+		{name: "anonymous", path: filePath, wantStatus: http.StatusUnauthorized},
+		{name: "anonymous resume", path: filePath, header: resume, wantStatus: http.StatusUnauthorized},
+		{name: "anonymous cached", path: filePath, header: cached, wantStatus: http.StatusUnauthorized},
+		{name: "other user", token: other, path: filePath, wantStatus: http.StatusNotFound},
+		{name: "other user resume", token: other, path: filePath, header: resume, wantStatus: http.StatusNotFound},
+		{name: "other user cached", token: other, path: filePath, header: cached, wantStatus: http.StatusNotFound},
+		{name: "missing", token: owner, path: file.URLPrefix + "/sha256/00/00/missing", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if w.Code >= http.StatusBadRequest {
+				assert.NotContains(t, w.Body.String(), "Hello")
+				assert.Empty(t, w.Header().Get("ETag"))
+				assert.Empty(t, w.Header().Get("Content-Range"))
+				assert.Empty(t, w.Header().Get("Accept-Ranges"))
+			} else {
+				assert.Equal(t, etag, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func Test_parseRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		size       int64
+		wantOffset int64
+		wantLength int64
+		wantErr    error
+	}{
+		{name: "closed", header: "bytes=0-4", size: 13, wantOffset: 0, wantLength: 5},
+		{name: "open", header: "bytes=7-", size: 13, wantOffset: 7, wantLength: 6},
+		{name: "end beyond size", header: "bytes=7-100", size: 13, wantOffset: 7, wantLength: 6},
+		{name: "suffix", header: "bytes=-6", size: 13, wantOffset: 7, wantLength: 6},
+		{name: "suffix beyond size", header: "bytes=-100", size: 13, wantOffset: 0, wantLength: 13},
+		{name: "start beyond size", header: "bytes=13-", size: 13, wantErr: errRangeNotSatisfiable},
+		{name: "empty file", header: "bytes=0-", size: 0, wantErr: errRangeNotSatisfiable},
+		{name: "zero suffix", header: "bytes=-0", size: 13, wantErr: errRangeNotSatisfiable},
+		{name: "other unit", header: "items=0-1", size: 13, wantErr: errRangeInvalid},
+		{name: "multiple", header: "bytes=0-1,3-4", size: 13, wantErr: errRangeInvalid},
+		{name: "reversed", header: "bytes=4-1", size: 13, wantErr: errRangeInvalid},
+		{name: "garbage", header: "bytes=a-b", size: 13, wantErr: errRangeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, length, err := parseRange(tt.header, tt.size)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOffset, offset)
+			assert.Equal(t, tt.wantLength, length)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// SaveEmergencyAccess назначает доверенное лицо или меняет период ожидания.
+func (h *Handler) SaveEmergencyAccess(c *gin.Context) {
+	var err error
+	var rb model.EmergencyAccess
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("SaveEmergencyAccess Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveEmergencyAccess Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	rb.UserID = userID
+
+	id, err := h.service.SaveEmergencyAccess(c, rb)
+	if err != nil {
+		logger.Error("SaveEmergencyAccess Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *Handler) DeleteEmergencyAccess(c *gin.Context) {
+	var err error
+	var rb model.EmergencyAccess
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteEmergencyAccess Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteEmergencyAccess Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteEmergencyAccess(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("DeleteEmergencyAccess Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// FindAllEmergencyAccess доверенные лица пользователя.
+func (h *Handler) FindAllEmergencyAccess(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllEmergencyAccess Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	list, err := h.service.FindAllEmergencyAccess(c, userID)
+	if err != nil {
+		logger.Error("FindAllEmergencyAccess Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(list) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// FindAllIncomingEmergencyAccess пользователи, назначившие пользователя доверенным лицом.
+func (h *Handler) FindAllIncomingEmergencyAccess(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllIncomingEmergencyAccess Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	list, err := h.service.FindAllIncomingEmergencyAccess(c, userID)
+	if err != nil {
+		logger.Error("FindAllIncomingEmergencyAccess Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(list) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// RequestEmergencyAccess запрос доверенного лица на доступ к хранилищу владельца.
+func (h *Handler) RequestEmergencyAccess(c *gin.Context) {
+	var err error
+	var rb model.EmergencyAccess
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("RequestEmergencyAccess Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("RequestEmergencyAccess Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.RequestEmergencyAccess(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("RequestEmergencyAccess Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RejectEmergencyAccess отклонение владельцем запроса экстренного доступа.
+func (h *Handler) RejectEmergencyAccess(c *gin.Context) {
+	var err error
+	var rb model.EmergencyAccess
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("RejectEmergencyAccess Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("RejectEmergencyAccess Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.RejectEmergencyAccess(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("RejectEmergencyAccess Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// FindEmergencyVault записи владельца по открытому экстренному доступу из параметра id.
+func (h *Handler) FindEmergencyVault(c *gin.Context) {
+	id, err := queryID(c, "id")
+	if err != nil {
+		logger.Error("FindEmergencyVault Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindEmergencyVault Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	vault, err := h.service.FindEmergencyVault(c, id, userID)
+	if err != nil {
+		logger.Error("FindEmergencyVault Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, vault)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/service"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// abortWithProblem прерывает обработку запроса и отвечает ошибкой в формате application/problem+json.
+func abortWithProblem(c *gin.Context, status int, code, detail string, fields ...model.FieldError) {
+	problem := model.Problem{
+		Type:     model.ProblemType(code),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Code:     code,
+		Errors:   fields,
+	}
+
+	c.Header("Content-Type", model.ProblemContentType)
+	c.AbortWithStatusJSON(status, problem)
+}
+
+// abortWithError сопоставляет ошибку сервиса или хранилища со статусом и кодом ответа.
+func abortWithError(c *gin.Context, err error) {
+	var fieldErr *model.FieldError
+
+	switch {
+	case errors.As(err, &fieldErr):
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeValidationFailed, "request validation failed", *fieldErr)
+	case errors.Is(err, storage.ErrorNotFound):
+		abortWithProblem(c, http.StatusNotFound, model.ProblemCodeNotFound, "item not found")
+	case errors.Is(err, storage.ErrorUserAlreadyExists):
+		abortWithProblem(c, http.StatusConflict, model.ProblemCodeLoginExists, "login already exists")
+	case errors.Is(err, storage.ErrorRowAlreadyExists):
+		abortWithProblem(c, http.StatusConflict, model.ProblemCodeAlreadyExists, "item already exists")
+	case errors.Is(err, storage.ErrorUserCredentials):
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeInvalidCredentials, "wrong pair login/password")
+	case errors.Is(err, service.ErrAccessDenied):
+		abortWithProblem(c, http.StatusForbidden, model.ProblemCodeForbidden, "access denied")
+	case errors.Is(err, service.ErrQuotaExceeded):
+		abortWithProblem(c, http.StatusRequestEntityTooLarge, model.ProblemCodeQuotaExceeded, quotaDetail(err))
+	case errors.Is(err, service.ErrUploadOffset):
+		abortWithProblem(c, http.StatusConflict, model.ProblemCodeUploadOffset, "upload offset mismatch")
+	case errors.Is(err, service.ErrUploadIncomplete):
+		abortWithProblem(c, http.StatusConflict, model.ProblemCodeUploadIncomplete, "upload is incomplete")
+	case errors.Is(err, service.ErrUploadChecksum):
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeChecksumMismatch, "sha256 checksum mismatch")
+	case errors.Is(err, file.ErrNotFound):
+		abortWithProblem(c, http.StatusNotFound, model.ProblemCodeNotFound, "content not found")
+	case errors.Is(err, file.ErrSizeMismatch):
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, "file size does not match content")
+	case errors.Is(err, service.ErrRefreshTokenInvalid):
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, "refresh token is invalid")
+	default:
+		logger.Error("internal error: ", err)
+		abortWithProblem(c, http.StatusInternalServerError, model.ProblemCodeInternal, "internal server error")
+	}
+}
+
+// quotaDetail описание превышенной квоты для ответа.
+func quotaDetail(err error) string {
+	for _, quotaErr := range []error{service.ErrQuotaBytes, service.ErrQuotaFileSize, service.ErrQuotaItems} {
+		if errors.Is(err, quotaErr) {
+			return quotaErr.Error()
+		}
+	}
+
+	return service.ErrQuotaExceeded.Error()
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/service"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_abortWithError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantField  string
+	}{
+		{
+			name:       "validation",
+			err:        fmt.Errorf("service.SaveCard: %w", model.ErrDataCardCvvEmpty),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   model.ProblemCodeValidationFailed,
+			wantField:  "cvv",
+		},
+		{
+			name:       "not found",
+			err:        fmt.Errorf("db.FindCard: %w", storage.ErrorNotFound),
+			wantStatus: http.StatusNotFound,
+			wantCode:   model.ProblemCodeNotFound,
+		},
+		{
+			name:       "login exists",
+			err:        storage.ErrorUserAlreadyExists,
+			wantStatus: http.StatusConflict,
+			wantCode:   model.ProblemCodeLoginExists,
+		},
+		{
+			name:       "row exists",
+			err:        storage.ErrorRowAlreadyExists,
+			wantStatus: http.StatusConflict,
+			wantCode:   model.ProblemCodeAlreadyExists,
+		},
+		{
+			name:       "wrong credentials",
+			err:        storage.ErrorUserCredentials,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   model.ProblemCodeInvalidCredentials,
+		},
+		{
+			name:       "access denied",
+			err:        fmt.Errorf("service.UpdateIncomingShare: %w", service.ErrAccessDenied),
+			wantStatus: http.StatusForbidden,
+			wantCode:   model.ProblemCodeForbidden,
+		},
+		{
+			name:       "quota exceeded",
+			err:        fmt.Errorf("service.SaveFile: %w", service.ErrQuotaFileSize),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   model.ProblemCodeQuotaExceeded,
+		},
+		{
+			name:       "upload offset",
+			err:        fmt.Errorf("service.WriteUploadChunk: %w", service.ErrUploadOffset),
+			wantStatus: http.StatusConflict,
+			wantCode:   model.ProblemCodeUploadOffset,
+		},
+		{
+			name:       "upload checksum",
+			err:        fmt.Errorf("service.CompleteUpload: %w", service.ErrUploadChecksum),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   model.ProblemCodeChecksumMismatch,
+		},
+		{
+			name:       "refresh token",
+			err:        service.ErrRefreshTokenInvalid,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   model.ProblemCodeUnauthorized,
+		},
+		{
+			name:       "internal",
+			err:        errors.New("connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   model.ProblemCodeInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/store/card", nil)
+
+			abortWithError(c, tt.err)
+
+			var problem model.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, model.ProblemContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantCode, problem.Code)
+			assert.Equal(t, model.ProblemType(tt.wantCode), problem.Type)
+			assert.Equal(t, "/store/card", problem.Instance)
+			assert.True(t, c.IsAborted())
+
+			if tt.wantField != "" {
+				assert.Len(t, problem.Errors, 1)
+				assert.Equal(t, tt.wantField, problem.Errors[0].Field)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+)
+
+// parseFieldsForm читает необязательное поле multipart-формы fields - JSON-массив пользовательских полей.
+func parseFieldsForm(c *gin.Context) (fields model.Fields, err error) {
+	v := c.PostForm("fields")
+	if v == "" {
+		return fields, nil
+	}
+
+	err = json.Unmarshal([]byte(v), &fields)
+
+	return fields, err
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/service"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_SaveFile(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+
+	cfg := &config.Config{JWTSecretKey: "test_secret_key", JWTAccessTokenTTL: "10m", JWTRefreshTokenTTL: "1h"}
+	s := service.New(store, storeFiles, cfg)
+	r := NewHandler(s).Init()
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+	tokens, err := s.CreateSession(ctx, userID)
+	require.NoError(t, err)
+
+	const content = "Hello, world!"
+	updatedAt := time.Now().UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name       string
+		fields     map[string]string
+		wantStatus int
+	}{
+		{name: "saved", fields: map[string]string{"id": "0", "title": "file", "updated_at": updatedAt}, wantStatus: http.StatusCreated},
+		{name: "invalid form", fields: map[string]string{"id": "0", "title": "file", "updated_at": "yesterday"}, wantStatus: http.StatusBadRequest},
+		{name: "rejected by service", fields: map[string]string{"id": "0", "title": " ", "updated_at": updatedAt}, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			for k, v := range tt.fields {
+				require.NoError(t, form.WriteField(k, v))
+			}
+			part, err := form.CreateFormFile("file", "file.txt")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(content))
+			require.NoError(t, err)
+			require.NoError(t, form.Close())
+
+			// каждый случай начинается с пустого хранилища
+			files, err := store.FindAllFiles(ctx, userID, model.ItemFilter{})
+			require.NoError(t, err)
+			for _, v := range files {
+				require.NoError(t, s.DeleteFile(ctx, v.ID, userID))
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/store/file", &body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+			r.ServeHTTP(w, req)
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+
+			blobs := 0
+			require.NoError(t, storeFiles.WalkFiles(ctx, func(string, file.BlobInfo) error {
+				blobs++
+
+				return nil
+			}))
+
+			if tt.wantStatus != http.StatusCreated {
+				assert.Zero(t, blobs, "содержимое без записи удаляется")
+
+				return
+			}
+
+			assert.Equal(t, 1, blobs)
+			files, err = store.FindAllFiles(ctx, userID, model.ItemFilter{})
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+			assert.Equal(t, int64(len(content)), files[0].Size)
+			assert.Equal(t, "315f5bdb76d078c43b8ac0064e4a0164612b1fce77c869345bfc94c75894edd3", files[0].SHA256)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// parseItemRefsForm читает необязательные поля multipart-формы folder_id и tag_ids (через запятую).
+func parseItemRefsForm(c *gin.Context) (folderID int, tagIDs []int, err error) {
+	if v := c.PostForm("folder_id"); v != "" {
+		folderID, err = strconv.Atoi(v)
+		if err != nil {
+			return folderID, tagIDs, err
+		}
+	}
+
+	for _, v := range strings.Split(c.PostForm("tag_ids"), ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return folderID, tagIDs, err
+		}
+		tagIDs = append(tagIDs, id)
+	}
+
+	return folderID, tagIDs, nil
+}
+
+func (h *Handler) SaveFolder(c *gin.Context) {
+	var err error
+	var rb model.Folder
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("SaveFolder Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveFolder Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	rb.UserID = userID
+
+	id, err := h.service.SaveFolder(c, rb)
+	if err != nil {
+		logger.Error("SaveFolder Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *Handler) DeleteFolder(c *gin.Context) {
+	var err error
+	var rb model.Folder
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteFolder Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteFolder Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteFolder(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("DeleteFolder Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) FindAllFolders(c *gin.Context) {
+	var err error
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllFolders Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	folders, err := h.service.FindAllFolders(c, userID)
+	if err != nil {
+		logger.Error("FindAllFolders Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(folders) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, folders)
+}
+
+func (h *Handler) SaveTag(c *gin.Context) {
+	var err error
+	var rb model.Tag
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("SaveTag Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveTag Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	rb.UserID = userID
+
+	id, err := h.service.SaveTag(c, rb)
+	if err != nil {
+		logger.Error("SaveTag Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *Handler) DeleteTag(c *gin.Context) {
+	var err error
+	var rb model.Tag
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteTag Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteTag Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteTag(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("DeleteTag Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) FindAllTags(c *gin.Context) {
+	var err error
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllTags Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	tags, err := h.service.FindAllTags(c, userID)
+	if err != nil {
+		logger.Error("FindAllTags Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(tags) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
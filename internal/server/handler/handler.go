@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"errors"
+	"context"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,7 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rainset/gophkeeper/internal/server/model"
 	"github.com/rainset/gophkeeper/internal/server/service"
-	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
 	"github.com/rainset/gophkeeper/pkg/logger"
 )
 
@@ -26,17 +26,32 @@ func NewHandler(service *service.Service) *Handler {
 func (h *Handler) Init() *gin.Engine {
 	r := gin.Default()
 
-	r.StaticFS("/_file_storage", gin.Dir(h.service.Cfg.FileStorage, false))
+	if h.service.Cfg.DevMode {
+		doc, err := LoadOpenAPI(context.Background())
+		if err != nil {
+			logger.Error("openapi: load spec: ", err)
+		} else {
+			r.Use(openAPIMiddleware(doc))
+		}
+	}
+
+	r.Use(h.limitBody)
+
+	r.GET("/"+file.URLPrefix+"/*filepath", h.authMiddleware, h.DownloadFile)
 
 	r.MaxMultipartMemory = 16 << 20 // 16 MiB
 
 	r.GET("/ping", h.Ping)
+	r.GET("/openapi.json", h.OpenAPI)
 	r.POST("/sign-up", h.SignUp)
 	r.POST("/sign-in", h.SignIn)
 
 	r.POST("/refresh-token", h.RefreshToken)
 	r.POST("/sign-key", h.SignKey)
 
+	r.GET("/s/:id", h.SecretPage)
+	r.POST("/s/:id", h.OpenSecret)
+
 	store := r.Group("/store", h.authMiddleware)
 	{
 		store.POST("/card", h.SaveCard)
@@ -58,6 +73,96 @@ func (h *Handler) Init() *gin.Engine {
 		store.DELETE("/file", h.DeleteFile)
 		store.GET("/file", h.FindFile)
 		store.GET("/file/list", h.FindAllFiles)
+		store.GET("/content/:sha256", h.FindContent)
+
+		store.POST("/upload", h.CreateUpload)
+		store.GET("/upload/:id", h.FindUpload)
+		store.PUT("/upload/:id", h.WriteUploadChunk)
+		store.POST("/upload/:id/complete", h.CompleteUpload)
+		store.DELETE("/upload/:id", h.DeleteUpload)
+
+		store.POST("/ssh", h.SaveSSHKey)
+		store.DELETE("/ssh", h.DeleteSSHKey)
+		store.GET("/ssh", h.FindSSHKey)
+		store.GET("/ssh/list", h.FindAllSSHKeys)
+
+		store.POST("/identity", h.SaveIdentity)
+		store.DELETE("/identity", h.DeleteIdentity)
+		store.GET("/identity", h.FindIdentity)
+		store.GET("/identity/list", h.FindAllIdentities)
+
+		store.POST("/custom", h.SaveCustomItem)
+		store.DELETE("/custom", h.DeleteCustomItem)
+		store.GET("/custom", h.FindCustomItem)
+		store.GET("/custom/list", h.FindAllCustomItems)
+
+		store.POST("/folder", h.SaveFolder)
+		store.DELETE("/folder", h.DeleteFolder)
+		store.GET("/folder/list", h.FindAllFolders)
+
+		store.POST("/tag", h.SaveTag)
+		store.DELETE("/tag", h.DeleteTag)
+		store.GET("/tag/list", h.FindAllTags)
+
+		store.POST("/template", h.SaveTemplate)
+		store.DELETE("/template", h.DeleteTemplate)
+		store.GET("/template/list", h.FindAllTemplates)
+
+		store.POST("/attachment", h.SaveAttachment)
+		store.DELETE("/attachment", h.DeleteAttachment)
+		store.GET("/attachment/list", h.FindAllAttachments)
+
+		store.POST("/share", h.SaveShare)
+		store.DELETE("/share", h.DeleteShare)
+		store.GET("/share/list", h.FindAllShares)
+		store.GET("/share/incoming", h.FindAllIncomingShares)
+		store.POST("/share/incoming", h.UpdateIncomingShare)
+	}
+
+	account := r.Group("/account", h.authMiddleware)
+	{
+		account.POST("/keys", h.SaveUserKeys)
+		account.GET("/keys", h.FindUserKeys)
+		account.GET("/keys/public", h.FindPublicKey)
+		account.GET("/usage", h.FindUsage)
+	}
+
+	org := r.Group("/org", h.authMiddleware)
+	{
+		org.POST("", h.SaveOrganization)
+		org.DELETE("", h.DeleteOrganization)
+		org.GET("/list", h.FindAllOrganizations)
+		org.POST("/accept", h.AcceptInvite)
+
+		org.POST("/member", h.InviteMember)
+		org.DELETE("/member", h.RemoveMember)
+		org.GET("/member/list", h.FindAllMembers)
+
+		org.POST("/collection", h.SaveCollection)
+		org.DELETE("/collection", h.DeleteCollection)
+		org.GET("/collection/list", h.FindAllCollections)
+
+		org.POST("/collection/item", h.SaveCollectionItem)
+		org.DELETE("/collection/item", h.DeleteCollectionItem)
+		org.GET("/collection/item/list", h.FindAllCollectionItems)
+	}
+
+	emergency := r.Group("/emergency", h.authMiddleware)
+	{
+		emergency.POST("", h.SaveEmergencyAccess)
+		emergency.DELETE("", h.DeleteEmergencyAccess)
+		emergency.GET("/list", h.FindAllEmergencyAccess)
+		emergency.GET("/incoming", h.FindAllIncomingEmergencyAccess)
+		emergency.POST("/request", h.RequestEmergencyAccess)
+		emergency.POST("/reject", h.RejectEmergencyAccess)
+		emergency.GET("/vault", h.FindEmergencyVault)
+	}
+
+	secret := r.Group("/secret", h.authMiddleware)
+	{
+		secret.POST("", h.CreateSecret)
+		secret.DELETE("", h.DeleteSecret)
+		secret.GET("/list", h.FindAllSecrets)
 	}
 
 	return r
@@ -69,11 +174,11 @@ func (h *Handler) Ping(c *gin.Context) {
 
 func (h *Handler) SignIn(c *gin.Context) {
 	var rb model.User
-	err := c.BindJSON(&rb)
+	err := c.ShouldBindJSON(&rb)
 
 	if err != nil {
 		logger.Error("SignIn Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -81,7 +186,7 @@ func (h *Handler) SignIn(c *gin.Context) {
 	token, err := h.service.SignIn(c, rb)
 	if err != nil {
 		logger.Error("SignIn Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusUnauthorized)
+		abortWithError(c, err)
 
 		return
 	}
@@ -91,24 +196,18 @@ func (h *Handler) SignIn(c *gin.Context) {
 
 func (h *Handler) SignUp(c *gin.Context) {
 	var rb model.User
-	err := c.BindJSON(&rb)
+	err := c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("SignUp Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
 
 	token, err := h.service.SignUp(c, rb)
 	if err != nil {
-		if errors.Is(err, storage.ErrorUserAlreadyExists) {
-			c.AbortWithStatus(http.StatusConflict)
-
-			return
-		}
-
 		logger.Error("SignUp Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -118,10 +217,10 @@ func (h *Handler) SignUp(c *gin.Context) {
 
 func (h *Handler) RefreshToken(c *gin.Context) {
 	var rb model.RefreshToken
-	err := c.BindJSON(&rb)
+	err := c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("RefreshToken Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -129,7 +228,7 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 	tokens, err := h.service.GetRefreshToken(c, rb.Token)
 	if err != nil {
 		logger.Error("RefreshToken Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -139,11 +238,11 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 
 func (h *Handler) SignKey(c *gin.Context) {
 	var rb model.User
-	err := c.BindJSON(&rb)
+	err := c.ShouldBindJSON(&rb)
 
 	if err != nil {
 		logger.Error("SignKey Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -151,7 +250,7 @@ func (h *Handler) SignKey(c *gin.Context) {
 	signKey, err := h.service.GetSignKey(c, rb.Login, rb.Password)
 	if err != nil {
 		logger.Error("SignKey Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusUnauthorized)
+		abortWithError(c, err)
 
 		return
 	}
@@ -163,10 +262,10 @@ func (h *Handler) SaveCard(c *gin.Context) {
 	var err error
 	var rb model.DataCard
 
-	err = c.BindJSON(&rb)
+	err = c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("SaveCard Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -174,7 +273,7 @@ func (h *Handler) SaveCard(c *gin.Context) {
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("SaveCard Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
@@ -183,15 +282,9 @@ func (h *Handler) SaveCard(c *gin.Context) {
 
 	id, err := h.service.SaveCard(c, rb)
 	if err != nil {
-		if errors.Is(err, storage.ErrorRowAlreadyExists) {
-			c.AbortWithStatus(http.StatusConflict)
-
-			return
-		}
-
 		logger.Error("SaveCard Handler: ", err, rb)
 
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -203,10 +296,10 @@ func (h *Handler) DeleteCard(c *gin.Context) {
 	var err error
 	var rb model.DataCard
 
-	err = c.BindJSON(&rb)
+	err = c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("DeleteCard Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -214,7 +307,7 @@ func (h *Handler) DeleteCard(c *gin.Context) {
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("DeleteCard Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
@@ -222,7 +315,7 @@ func (h *Handler) DeleteCard(c *gin.Context) {
 	err = h.service.DeleteCard(c, rb.ID, userID)
 	if err != nil {
 		logger.Error("DeleteCard Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -233,10 +326,10 @@ func (h *Handler) DeleteCard(c *gin.Context) {
 func (h *Handler) FindCard(c *gin.Context) {
 	var err error
 	var rb model.DataCard
-	err = c.BindJSON(&rb)
+	err = c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("FindCard Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -244,7 +337,7 @@ func (h *Handler) FindCard(c *gin.Context) {
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error(err)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
@@ -252,7 +345,7 @@ func (h *Handler) FindCard(c *gin.Context) {
 	card, err := h.service.FindCard(c, rb.ID, userID)
 	if err != nil {
 		logger.Error("FindCard Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -262,19 +355,28 @@ func (h *Handler) FindCard(c *gin.Context) {
 
 func (h *Handler) FindAllCards(c *gin.Context) {
 	var err error
+	var filter model.ItemFilter
+
+	err = c.ShouldBindQuery(&filter)
+	if err != nil {
+		logger.Error("FindAllCards Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
 
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("FindAllCards Handler: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
 
-	cards, err := h.service.FindAllCards(c, userID)
+	cards, err := h.service.FindAllCards(c, userID, filter)
 	if err != nil {
 		logger.Error("FindAllCards Handler: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -291,10 +393,10 @@ func (h *Handler) FindAllCards(c *gin.Context) {
 func (h *Handler) SaveCred(c *gin.Context) {
 	var err error
 	var rb model.DataCred
-	err = c.BindJSON(&rb)
+	err = c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("SaveCred Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -302,7 +404,7 @@ func (h *Handler) SaveCred(c *gin.Context) {
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error(err)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
@@ -311,14 +413,8 @@ func (h *Handler) SaveCred(c *gin.Context) {
 
 	id, err := h.service.SaveCred(c, rb)
 	if err != nil {
-		if errors.Is(err, storage.ErrorRowAlreadyExists) {
-			c.AbortWithStatus(http.StatusConflict)
-
-			return
-		}
-
 		logger.Error("SaveCred Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -330,10 +426,10 @@ func (h *Handler) SaveCred(c *gin.Context) {
 func (h *Handler) DeleteCred(c *gin.Context) {
 	var err error
 	var rb model.DataCred
-	err = c.BindJSON(&rb)
+	err = c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("DeleteCred Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -341,7 +437,7 @@ func (h *Handler) DeleteCred(c *gin.Context) {
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("DeleteCred Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
@@ -349,7 +445,7 @@ func (h *Handler) DeleteCred(c *gin.Context) {
 	err = h.service.DeleteCred(c, rb.ID, userID)
 	if err != nil {
 		logger.Error("DeleteCred Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -360,10 +456,10 @@ func (h *Handler) DeleteCred(c *gin.Context) {
 func (h *Handler) FindCred(c *gin.Context) {
 	var err error
 	var rb model.DataCred
-	err = c.BindJSON(&rb)
+	err = c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("FindCred Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -371,7 +467,7 @@ func (h *Handler) FindCred(c *gin.Context) {
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("FindCred Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
@@ -379,7 +475,7 @@ func (h *Handler) FindCred(c *gin.Context) {
 	cred, err := h.service.FindCred(c, rb.ID, userID)
 	if err != nil {
 		logger.Error("FindCred Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -389,19 +485,28 @@ func (h *Handler) FindCred(c *gin.Context) {
 
 func (h *Handler) FindAllCreds(c *gin.Context) {
 	var err error
+	var filter model.ItemFilter
+
+	err = c.ShouldBindQuery(&filter)
+	if err != nil {
+		logger.Error("FindAllCreds Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
 
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("FindAllCreds Handler: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
 
-	creds, err := h.service.FindAllCreds(c, userID)
+	creds, err := h.service.FindAllCreds(c, userID, filter)
 	if err != nil {
 		logger.Error("FindAllCreds Handler: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -418,10 +523,10 @@ func (h *Handler) FindAllCreds(c *gin.Context) {
 func (h *Handler) SaveText(c *gin.Context) {
 	var err error
 	var rb model.DataText
-	err = c.BindJSON(&rb)
+	err = c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("SaveText Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -429,7 +534,7 @@ func (h *Handler) SaveText(c *gin.Context) {
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("SaveText Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
@@ -438,14 +543,8 @@ func (h *Handler) SaveText(c *gin.Context) {
 
 	id, err := h.service.SaveText(c, rb)
 	if err != nil {
-		if errors.Is(err, storage.ErrorRowAlreadyExists) {
-			c.AbortWithStatus(http.StatusConflict)
-
-			return
-		}
-
 		logger.Error("SaveText Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -456,10 +555,10 @@ func (h *Handler) SaveText(c *gin.Context) {
 func (h *Handler) DeleteText(c *gin.Context) {
 	var err error
 	var rb model.DataText
-	err = c.BindJSON(&rb)
+	err = c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("DeleteText Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -467,7 +566,7 @@ func (h *Handler) DeleteText(c *gin.Context) {
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("DeleteText Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
@@ -475,7 +574,7 @@ func (h *Handler) DeleteText(c *gin.Context) {
 	err = h.service.DeleteText(c, rb.ID, userID)
 	if err != nil {
 		logger.Error("DeleteText Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -486,10 +585,10 @@ func (h *Handler) DeleteText(c *gin.Context) {
 func (h *Handler) FindText(c *gin.Context) {
 	var err error
 	var rb model.DataText
-	err = c.BindJSON(&rb)
+	err = c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("FindText Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -497,7 +596,7 @@ func (h *Handler) FindText(c *gin.Context) {
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("FindText Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
@@ -505,7 +604,7 @@ func (h *Handler) FindText(c *gin.Context) {
 	card, err := h.service.FindText(c, rb.ID, userID)
 	if err != nil {
 		logger.Error("FindText Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -515,19 +614,28 @@ func (h *Handler) FindText(c *gin.Context) {
 
 func (h *Handler) FindAllTexts(c *gin.Context) {
 	var err error
+	var filter model.ItemFilter
+
+	err = c.ShouldBindQuery(&filter)
+	if err != nil {
+		logger.Error("FindAllTexts Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
 
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("FindAllTexts Handler: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
 
-	texts, err := h.service.FindAllTexts(c, userID)
+	texts, err := h.service.FindAllTexts(c, userID, filter)
 	if err != nil {
 		logger.Error("FindAllTexts Handler: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -547,70 +655,66 @@ func (h *Handler) SaveFile(c *gin.Context) {
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("SaveFile Handler: ", err, file)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
 
-	formFile, err := c.FormFile("file")
-	if err != nil {
-		logger.Error("SaveFile Handler: ", err, file)
-		c.AbortWithStatus(http.StatusBadRequest)
+	h.limitUpload(c)
 
+	t, err := time.Parse(time.RFC3339, c.PostForm("updated_at"))
+	if err != nil {
+		logger.Error("SaveFile Handler updated_at format RFC3339 error: ", err, file)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 		return
 	}
 
-	src, err := formFile.Open()
+	id, err := strconv.Atoi(c.PostForm("id"))
 	if err != nil {
-		logger.Error("SaveFile Handler: ", err, file)
-		c.AbortWithStatus(http.StatusBadRequest)
-
+		logger.Error("SaveFile Handler parse id error: ", err, file)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 		return
 	}
 
-	filePath, err := h.service.StoreFiles.SaveFile(src)
-	if err != nil {
-		if errors.Is(err, storage.ErrorRowAlreadyExists) {
-			c.AbortWithStatus(http.StatusConflict)
-
-			return
-		}
-
-		logger.Error("SaveFile Handler: ", err, file)
-		c.AbortWithStatus(http.StatusBadRequest)
-
-		return
+	if id > 0 {
+		file.ID = id
 	}
 
-	t, err := time.Parse(time.RFC3339, c.PostForm("updated_at"))
+	file.FolderID, file.TagIDs, err = parseItemRefsForm(c)
 	if err != nil {
-		logger.Error("SaveFile Handler updated_at format RFC3339 error: ", err, file)
-		c.AbortWithStatus(http.StatusBadRequest)
+		logger.Error("SaveFile Handler parse folder_id/tag_ids error: ", err, file)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 		return
 	}
 
-	id, err := strconv.Atoi(c.PostForm("id"))
+	file.Fields, err = parseFieldsForm(c)
 	if err != nil {
-		logger.Error("SaveFile Handler parse id error: ", err, file)
-		c.AbortWithStatus(http.StatusBadRequest)
+		logger.Error("SaveFile Handler parse fields error: ", err, file)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 		return
 	}
 
-	if id > 0 {
-		file.ID = id
+	// содержимое сохраняется после разбора формы, чтобы ошибки в полях не оставляли его без записи
+	filePath, filename, size, ok := h.receiveFile(c, userID)
+	if !ok {
+		return
 	}
 
 	file.UserID = userID
 	file.Title = c.PostForm("title")
 	file.Meta = c.PostForm("meta")
 	file.Path = filePath
+	file.Size = size
 	file.UpdatedAt = t
-	file.Filename = formFile.Filename
+	file.Filename = filename
 
 	fileID, err := h.service.SaveFile(c, file)
 	if err != nil {
 		logger.Error("SaveFile Handler open upload file error: ", err, file)
-		c.AbortWithStatus(http.StatusBadRequest)
+		if errDel := h.service.ReleaseFile(c, filePath); errDel != nil {
+			logger.Error("SaveFile Handler delete file error: ", errDel)
+		}
+		abortWithError(c, err)
 		return
 	}
 
@@ -620,17 +724,17 @@ func (h *Handler) SaveFile(c *gin.Context) {
 func (h *Handler) DeleteFile(c *gin.Context) {
 	var err error
 	var rb model.DataFile
-	err = c.BindJSON(&rb)
+	err = c.ShouldBindJSON(&rb)
 	if err != nil {
 		logger.Error("DeleteFile Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 		return
 	}
 
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("DeleteFile Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
@@ -638,7 +742,7 @@ func (h *Handler) DeleteFile(c *gin.Context) {
 	err = h.service.DeleteFile(c, rb.ID, userID)
 	if err != nil {
 		logger.Error("DeleteFile Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -649,9 +753,9 @@ func (h *Handler) DeleteFile(c *gin.Context) {
 func (h *Handler) FindFile(c *gin.Context) {
 	var err error
 	var rb model.DataFile
-	err = c.BindJSON(&rb)
+	err = c.ShouldBindJSON(&rb)
 	if err != nil {
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
 
 		return
 	}
@@ -659,7 +763,7 @@ func (h *Handler) FindFile(c *gin.Context) {
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("FindFile Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
@@ -667,7 +771,7 @@ func (h *Handler) FindFile(c *gin.Context) {
 	file, err := h.service.FindFile(c, rb.ID, userID)
 	if err != nil {
 		logger.Error("FindFile Handler: ", err, rb)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -677,19 +781,28 @@ func (h *Handler) FindFile(c *gin.Context) {
 
 func (h *Handler) FindAllFiles(c *gin.Context) {
 	var err error
+	var filter model.ItemFilter
+
+	err = c.ShouldBindQuery(&filter)
+	if err != nil {
+		logger.Error("FindAllFiles Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
 
 	userID, err := h.getUserIDFromRequest(c)
 	if err != nil {
 		logger.Error("FindAllFiles Handler: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 
 		return
 	}
 
-	files, err := h.service.FindAllFiles(c, userID)
+	files, err := h.service.FindAllFiles(c, userID, filter)
 	if err != nil {
 		logger.Error("FindAllFiles Handler: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
+		abortWithError(c, err)
 
 		return
 	}
@@ -23,10 +23,11 @@ func testUser() (tokens model.Tokens, err error) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.NewTemp()
 	if err != nil {
 		return tokens, err
 	}
+	defer storeFile.Close()
 	newService := service.New(store, storeFile, cfg)
 
 	user := model.User{
@@ -50,7 +51,7 @@ func TestHandler_DeleteCard(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -78,7 +79,7 @@ func TestHandler_DeleteCred(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -105,7 +106,7 @@ func TestHandler_DeleteFile(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -132,7 +133,7 @@ func TestHandler_DeleteText(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -160,7 +161,7 @@ func TestHandler_FindAllCards(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -203,7 +204,7 @@ func TestHandler_FindAllCreds(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -246,7 +247,7 @@ func TestHandler_FindAllFiles(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -289,7 +290,7 @@ func TestHandler_FindAllTexts(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -332,7 +333,7 @@ func TestHandler_FindCard(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -373,7 +374,7 @@ func TestHandler_FindCred(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -414,7 +415,7 @@ func TestHandler_FindFile(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -455,7 +456,7 @@ func TestHandler_FindText(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -496,7 +497,7 @@ func TestHandler_Ping(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -525,7 +526,7 @@ func TestHandler_RefreshToken(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -565,7 +566,7 @@ func TestHandler_SaveCard(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -614,7 +615,7 @@ func TestHandler_SaveCred(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -662,7 +663,7 @@ func TestHandler_SaveText(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -709,7 +710,7 @@ func TestHandler_SignIn(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -753,7 +754,7 @@ func TestHandler_SignKey(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -797,7 +798,7 @@ func TestHandler_SignUp(t *testing.T) {
 	}
 	ctx := context.Background()
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFile, err := file.New(cfg.FileStorage)
+	storeFile, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 		return
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+func (h *Handler) SaveIdentity(c *gin.Context) {
+	var err error
+	var rb model.DataIdentity
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("SaveIdentity Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveIdentity Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	rb.UserID = userID
+
+	id, err := h.service.SaveIdentity(c, rb)
+	if err != nil {
+		logger.Error("SaveIdentity Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *Handler) DeleteIdentity(c *gin.Context) {
+	var err error
+	var rb model.DataIdentity
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteIdentity Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteIdentity Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteIdentity(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("DeleteIdentity Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) FindIdentity(c *gin.Context) {
+	var err error
+	var rb model.DataIdentity
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("FindIdentity Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindIdentity Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	doc, err := h.service.FindIdentity(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("FindIdentity Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+func (h *Handler) FindAllIdentities(c *gin.Context) {
+	var err error
+	var filter model.ItemFilter
+
+	err = c.ShouldBindQuery(&filter)
+	if err != nil {
+		logger.Error("FindAllIdentities Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllIdentities Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	docs, err := h.service.FindAllIdentities(c, userID, filter)
+	if err != nil {
+		logger.Error("FindAllIdentities Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(docs) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, docs)
+}
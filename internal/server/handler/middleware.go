@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
 	"github.com/rainset/gophkeeper/pkg/logger"
 )
 
@@ -32,13 +33,37 @@ func (h *Handler) authMiddleware(c *gin.Context) {
 	id, err := h.parseAuthHeader(c)
 	if err != nil {
 		logger.Info("authMiddleware:", err)
-		c.AbortWithStatus(http.StatusUnauthorized)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
 		return
 	}
 
 	c.Set("user_id", id)
 }
 
+// fileRoutes маршруты, в теле которых передается содержимое файла: его ограничивают квота на размер файла
+// и размер части загрузки, а не MaxBodySize.
+var fileRoutes = map[string]bool{ //nolint:gochecknoglobals
+	"/store/file":       true,
+	"/store/attachment": true,
+	"/store/upload/:id": true,
+}
+
+// limitBody ограничивает тело остальных запросов размером MaxBodySize. Запрос с известной длиной больше
+// ограничения отклоняется сразу с кодом 413, иначе чтение тела прерывается на превышении.
+func (h *Handler) limitBody(c *gin.Context) {
+	size := h.service.Cfg.MaxBodySize
+	if size <= 0 || fileRoutes[c.FullPath()] {
+		return
+	}
+
+	if c.Request.ContentLength > size {
+		abortWithProblem(c, http.StatusRequestEntityTooLarge, model.ProblemCodeBodyTooLarge, "request body too large")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, size)
+}
+
 func (h *Handler) getUserIDFromRequest(c *gin.Context) (userID int, err error) {
 	ctxUserID, ex := c.Get("user_id")
 
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/service"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_LimitBody(t *testing.T) {
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := service.New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key", JWTAccessTokenTTL: "10m",
+		JWTRefreshTokenTTL: "1h", MaxBodySize: 256})
+	r := NewHandler(s).Init()
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "owner", Password: "password"})
+	require.NoError(t, err)
+	tokens, err := s.CreateSession(ctx, userID)
+	require.NoError(t, err)
+
+	text := `{"title":"text","text":"` + strings.Repeat("a", 300) + `"}`
+
+	tests := []struct {
+		name       string
+		path       string
+		body       io.Reader
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "small body", path: "/store/text", body: strings.NewReader(`{"title":"text","text":"a"}`), wantStatus: http.StatusCreated},
+		{name: "content length over limit", path: "/store/text", body: strings.NewReader(text),
+			wantStatus: http.StatusRequestEntityTooLarge, wantCode: model.ProblemCodeBodyTooLarge},
+		// без Content-Length тело обрезается при чтении
+		{name: "chunked body over limit", path: "/store/text", body: io.MultiReader(strings.NewReader(text)),
+			wantStatus: http.StatusBadRequest, wantCode: model.ProblemCodeInvalidRequest},
+		{name: "sign in over limit", path: "/sign-in", body: strings.NewReader(text),
+			wantStatus: http.StatusRequestEntityTooLarge, wantCode: model.ProblemCodeBodyTooLarge},
+		// содержимое файлов ограничивает квота на размер файла
+		{name: "file route", path: "/store/file", body: strings.NewReader(text), wantStatus: http.StatusBadRequest,
+			wantCode: model.ProblemCodeInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, tt.body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantCode != "" {
+				assert.Contains(t, w.Body.String(), `"code":"`+tt.wantCode+`"`)
+			}
+		})
+	}
+}
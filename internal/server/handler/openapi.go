@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// LoadOpenAPI загружает и проверяет встроенную спецификацию API.
+func LoadOpenAPI(ctx context.Context) (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = doc.Validate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// OpenAPI отдает спецификацию API.
+func (h *Handler) OpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+}
+
+// specPath переводит путь маршрута gin (/a/:id, /b/*path) в путь спецификации (/a/{id}, /b/{path}).
+func specPath(fullPath string) string {
+	parts := strings.Split(fullPath, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// openAPIRoute находит операцию спецификации для текущего маршрута gin.
+func openAPIRoute(doc *openapi3.T, c *gin.Context) (*routers.Route, map[string]string, bool) {
+	path := specPath(c.FullPath())
+
+	pathItem := doc.Paths.Find(path)
+	if pathItem == nil {
+		return nil, nil, false
+	}
+
+	operation := pathItem.GetOperation(c.Request.Method)
+	if operation == nil {
+		return nil, nil, false
+	}
+
+	params := make(map[string]string, len(c.Params))
+	for _, p := range c.Params {
+		params[p.Key] = strings.TrimPrefix(p.Value, "/")
+	}
+
+	route := &routers.Route{
+		Spec:      doc,
+		Path:      path,
+		PathItem:  pathItem,
+		Method:    c.Request.Method,
+		Operation: operation,
+	}
+
+	return route, params, true
+}
+
+// responseRecorder сохраняет копию ответа для проверки по спецификации.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// openAPIMiddleware проверяет запросы и ответы по спецификации API.
+// Запросы, не соответствующие спецификации, отклоняются, расхождения в ответах попадают в лог.
+func openAPIMiddleware(doc *openapi3.T) gin.HandlerFunc {
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(c *gin.Context) {
+		route, params, ok := openAPIRoute(doc, c)
+		if !ok {
+			logger.Warnf("openapi: route %s %s is not described in spec", c.Request.Method, c.Request.URL.Path)
+			c.Next()
+
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: params,
+			Route:      route,
+			Options:    options,
+		}
+
+		err := openapi3filter.ValidateRequest(c.Request.Context(), input)
+		if err != nil {
+			logger.Error("openapi: request validation: ", err)
+			abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		err = openapi3filter.ValidateResponse(c.Request.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 recorder.Status(),
+			Header:                 recorder.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(recorder.body.Bytes())),
+			Options:                options,
+		})
+		if err != nil {
+			logger.Warn("openapi: response validation: ", err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEngine(t *testing.T, devMode bool) *gin.Engine {
+	t.Helper()
+
+	cfg, err := config.ReadConfig()
+	require.NoError(t, err)
+
+	devCfg := *cfg
+	devCfg.DevMode = devMode
+	devCfg.FileStorage = t.TempDir()
+
+	return NewHandler(service.New(nil, nil, &devCfg)).Init()
+}
+
+func TestLoadOpenAPI(t *testing.T) {
+	doc, err := LoadOpenAPI(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "GophKeeper API", doc.Info.Title)
+}
+
+// TestOpenAPI_Contract проверяет, что маршруты сервера и спецификация совпадают.
+func TestOpenAPI_Contract(t *testing.T) {
+	doc, err := LoadOpenAPI(context.Background())
+	require.NoError(t, err)
+
+	routes := make(map[string]bool)
+	for _, r := range testEngine(t, false).Routes() {
+		if r.Method == http.MethodHead {
+			continue
+		}
+
+		key := r.Method + " " + specPath(r.Path)
+		routes[key] = true
+
+		pathItem := doc.Paths.Find(specPath(r.Path))
+		if assert.NotNil(t, pathItem, "route %s is not described in spec", key) {
+			assert.NotNil(t, pathItem.GetOperation(r.Method), "route %s is not described in spec", key)
+		}
+	}
+
+	for path, pathItem := range doc.Paths {
+		for method := range pathItem.Operations() {
+			key := method + " " + path
+			assert.True(t, routes[key], "spec operation %s has no handler", key)
+		}
+	}
+}
+
+func TestHandler_OpenAPI(t *testing.T) {
+	r := testEngine(t, false)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, json.Valid(w.Body.Bytes()))
+}
+
+func Test_openAPIMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "valid request",
+			method:     http.MethodGet,
+			target:     "/ping",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing required field",
+			method:     http.MethodPost,
+			target:     "/sign-up",
+			body:       `{"login":"user"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   model.ProblemCodeInvalidRequest,
+		},
+		{
+			name:       "wrong field type",
+			method:     http.MethodPost,
+			target:     "/refresh-token",
+			body:       `{"refresh_token":1}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   model.ProblemCodeInvalidRequest,
+		},
+	}
+
+	r := testEngine(t, true)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantCode != "" {
+				var problem model.Problem
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				assert.Equal(t, tt.wantCode, problem.Code)
+			}
+		})
+	}
+}
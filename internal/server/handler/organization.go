@@ -0,0 +1,428 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// queryID обязательный числовой параметр запроса name.
+func queryID(c *gin.Context, name string) (int, error) {
+	id, err := strconv.Atoi(c.Query(name))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%s is required", name)
+	}
+
+	return id, nil
+}
+
+// SaveOrganization создает организацию, владельцем которой становится пользователь, или переименовывает ее.
+func (h *Handler) SaveOrganization(c *gin.Context) {
+	var err error
+	var rb model.Organization
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("SaveOrganization Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveOrganization Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	id, err := h.service.SaveOrganization(c, rb, userID)
+	if err != nil {
+		logger.Error("SaveOrganization Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *Handler) DeleteOrganization(c *gin.Context) {
+	var err error
+	var rb model.Organization
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteOrganization Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteOrganization Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteOrganization(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("DeleteOrganization Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// FindAllOrganizations организации пользователя, включая приглашения, с его ролью.
+func (h *Handler) FindAllOrganizations(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllOrganizations Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	list, err := h.service.FindAllOrganizations(c, userID)
+	if err != nil {
+		logger.Error("FindAllOrganizations Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(list) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// InviteMember приглашает пользователя в организацию или меняет роль участника.
+func (h *Handler) InviteMember(c *gin.Context) {
+	var err error
+	var rb model.Member
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("InviteMember Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("InviteMember Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.InviteMember(c, rb, userID)
+	if err != nil {
+		logger.Error("InviteMember Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RemoveMember исключает участника, отклоняет приглашение или выводит пользователя из организации.
+func (h *Handler) RemoveMember(c *gin.Context) {
+	var err error
+	var rb model.Member
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("RemoveMember Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("RemoveMember Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.RemoveMember(c, rb.OrgID, rb.Login, userID)
+	if err != nil {
+		logger.Error("RemoveMember Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// FindAllMembers участники организации из параметра org_id.
+func (h *Handler) FindAllMembers(c *gin.Context) {
+	orgID, err := queryID(c, "org_id")
+	if err != nil {
+		logger.Error("FindAllMembers Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllMembers Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	list, err := h.service.FindAllMembers(c, orgID, userID)
+	if err != nil {
+		logger.Error("FindAllMembers Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(list) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// AcceptInvite принимает приглашение в организацию.
+func (h *Handler) AcceptInvite(c *gin.Context) {
+	var err error
+	var rb model.Organization
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("AcceptInvite Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("AcceptInvite Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.AcceptInvite(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("AcceptInvite Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) SaveCollection(c *gin.Context) {
+	var err error
+	var rb model.Collection
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("SaveCollection Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveCollection Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	id, err := h.service.SaveCollection(c, rb, userID)
+	if err != nil {
+		logger.Error("SaveCollection Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *Handler) DeleteCollection(c *gin.Context) {
+	var err error
+	var rb model.Collection
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteCollection Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteCollection Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteCollection(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("DeleteCollection Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// FindAllCollections коллекции организаций пользователя с ключом коллекции, зашифрованным для него.
+func (h *Handler) FindAllCollections(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllCollections Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	list, err := h.service.FindAllCollections(c, userID)
+	if err != nil {
+		logger.Error("FindAllCollections Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(list) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *Handler) SaveCollectionItem(c *gin.Context) {
+	var err error
+	var rb model.CollectionItem
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("SaveCollectionItem Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveCollectionItem Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	id, err := h.service.SaveCollectionItem(c, rb, userID)
+	if err != nil {
+		logger.Error("SaveCollectionItem Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *Handler) DeleteCollectionItem(c *gin.Context) {
+	var err error
+	var rb model.CollectionItem
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteCollectionItem Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteCollectionItem Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteCollectionItem(c, rb.ID, rb.CollectionID, userID)
+	if err != nil {
+		logger.Error("DeleteCollectionItem Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// FindAllCollectionItems записи коллекции из параметра collection_id.
+func (h *Handler) FindAllCollectionItems(c *gin.Context) {
+	colID, err := queryID(c, "collection_id")
+	if err != nil {
+		logger.Error("FindAllCollectionItems Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllCollectionItems Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	list, err := h.service.FindAllCollectionItems(c, colID, userID)
+	if err != nil {
+		logger.Error("FindAllCollectionItems Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(list) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/service"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// uploadFormOverhead запас на поля multipart-формы сверх квоты на размер файла.
+const uploadFormOverhead = 1 << 20 // 1 MiB
+
+// FindUsage использование хранилища пользователем и его квоты.
+func (h *Handler) FindUsage(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindUsage Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	usage, err := h.service.GetUsage(c, userID)
+	if err != nil {
+		logger.Error("FindUsage Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// limitUpload ограничивает тело запроса загрузки квотой на размер файла, чтобы слишком большой файл
+// отклонялся при чтении формы, а не после записи на диск.
+func (h *Handler) limitUpload(c *gin.Context) {
+	if size := h.service.Cfg.QuotaFileSize; size > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, size+uploadFormOverhead)
+	}
+}
+
+// abortWithFormFileError отвечает на ошибку чтения файла из формы: превышение limitUpload - 413.
+func abortWithFormFileError(c *gin.Context, err error) {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		abortWithError(c, service.ErrQuotaFileSize)
+
+		return
+	}
+
+	abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+}
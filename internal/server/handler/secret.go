@@ -0,0 +1,135 @@
+package handler
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// secretPage страница одноразового секрета: запрашивает секрет и расшифровывает его в браузере ключом
+// из фрагмента ссылки.
+//
+//go:embed secret.html
+var secretPage []byte
+
+// secretPageCSP разрешает странице секрета только встроенные скрипт и стили и запросы к своему серверу.
+const secretPageCSP = "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'"
+
+// CreateSecret создает одноразовый секрет; содержимое зашифровано клиентом.
+func (h *Handler) CreateSecret(c *gin.Context) {
+	var err error
+	var rb model.Secret
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("CreateSecret Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("CreateSecret Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	rb.UserID = userID
+
+	secret, err := h.service.CreateSecret(c, rb)
+	if err != nil {
+		logger.Error("CreateSecret Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, secret)
+}
+
+func (h *Handler) DeleteSecret(c *gin.Context) {
+	var err error
+	var rb model.Secret
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteSecret Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteSecret Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteSecret(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("DeleteSecret Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// FindAllSecrets действующие секреты пользователя без содержимого.
+func (h *Handler) FindAllSecrets(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllSecrets Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	list, err := h.service.FindAllSecrets(c, userID)
+	if err != nil {
+		logger.Error("FindAllSecrets Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(list) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// SecretPage отдает страницу секрета. Просмотр не засчитывается: секрет запрашивается со страницы
+// по нажатию кнопки, поэтому предпросмотр ссылки в мессенджерах его не расходует.
+func (h *Handler) SecretPage(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+	c.Header("Content-Security-Policy", secretPageCSP)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", secretPage)
+}
+
+// OpenSecret засчитывает просмотр и возвращает зашифрованный секрет; авторизация не требуется.
+func (h *Handler) OpenSecret(c *gin.Context) {
+	secret, err := h.service.OpenSecret(c, c.Param("id"))
+	if err != nil {
+		logger.Error("OpenSecret Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, secret)
+}
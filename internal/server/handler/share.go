@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+func (h *Handler) SaveUserKeys(c *gin.Context) {
+	var err error
+	var rb model.UserKeys
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("SaveUserKeys Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveUserKeys Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	rb.UserID = userID
+
+	err = h.service.SaveUserKeys(c, rb)
+	if err != nil {
+		logger.Error("SaveUserKeys Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) FindUserKeys(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindUserKeys Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	keys, err := h.service.FindUserKeys(c, userID)
+	if err != nil {
+		logger.Error("FindUserKeys Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// FindPublicKey открытый ключ пользователя по логину из параметра login.
+func (h *Handler) FindPublicKey(c *gin.Context) {
+	login := c.Query("login")
+	if login == "" {
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, "login is required")
+
+		return
+	}
+
+	key, err := h.service.FindPublicKey(c, login)
+	if err != nil {
+		logger.Error("FindPublicKey Handler: ", err, login)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+func (h *Handler) SaveShare(c *gin.Context) {
+	var err error
+	var rb model.Share
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("SaveShare Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveShare Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	rb.UserID = userID
+
+	id, err := h.service.SaveShare(c, rb)
+	if err != nil {
+		logger.Error("SaveShare Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *Handler) DeleteShare(c *gin.Context) {
+	var err error
+	var rb model.Share
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteShare Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteShare Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteShare(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("DeleteShare Handler: ", err, rb.ID)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// FindAllShares записи пользователя, открытые другим пользователям.
+func (h *Handler) FindAllShares(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllShares Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	shares, err := h.service.FindAllShares(c, userID)
+	if err != nil {
+		logger.Error("FindAllShares Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(shares) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, shares)
+}
+
+// FindAllIncomingShares записи других пользователей, открытые пользователю.
+func (h *Handler) FindAllIncomingShares(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllIncomingShares Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	shares, err := h.service.FindAllIncomingShares(c, userID)
+	if err != nil {
+		logger.Error("FindAllIncomingShares Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(shares) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, shares)
+}
+
+// UpdateIncomingShare сохраняет открытую пользователю запись, измененную им; нужен доступ на запись.
+func (h *Handler) UpdateIncomingShare(c *gin.Context) {
+	var err error
+	var rb model.Share
+
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("UpdateIncomingShare Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("UpdateIncomingShare Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	rb.RecipientID = userID
+
+	err = h.service.UpdateIncomingShare(c, rb)
+	if err != nil {
+		logger.Error("UpdateIncomingShare Handler: ", err, rb.ID)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
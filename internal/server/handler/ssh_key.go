@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+func (h *Handler) SaveSSHKey(c *gin.Context) {
+	var err error
+	var rb model.DataSSHKey
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("SaveSSHKey Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("SaveSSHKey Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	rb.UserID = userID
+
+	id, err := h.service.SaveSSHKey(c, rb)
+	if err != nil {
+		logger.Error("SaveSSHKey Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *Handler) DeleteSSHKey(c *gin.Context) {
+	var err error
+	var rb model.DataSSHKey
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("DeleteSSHKey Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteSSHKey Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteSSHKey(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("DeleteSSHKey Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) FindSSHKey(c *gin.Context) {
+	var err error
+	var rb model.DataSSHKey
+	err = c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("FindSSHKey Handler: ", err, rb)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindSSHKey Handler: ", err, rb)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	key, err := h.service.FindSSHKey(c, rb.ID, userID)
+	if err != nil {
+		logger.Error("FindSSHKey Handler: ", err, rb)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+func (h *Handler) FindAllSSHKeys(c *gin.Context) {
+	var err error
+	var filter model.ItemFilter
+
+	err = c.ShouldBindQuery(&filter)
+	if err != nil {
+		logger.Error("FindAllSSHKeys Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindAllSSHKeys Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	keys, err := h.service.FindAllSSHKeys(c, userID, filter)
+	if err != nil {
+		logger.Error("FindAllSSHKeys Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	if len(keys) == 0 {
+		c.Status(http.StatusNoContent)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/logger"
+)
+
+// CreateUpload открывает возобновляемую загрузку файла: клиент передает размер файла
+// и получает идентификатор загрузки и размер части.
+func (h *Handler) CreateUpload(c *gin.Context) {
+	var rb model.Upload
+
+	err := c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("CreateUpload Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("CreateUpload Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	upload, err := h.service.CreateUpload(c, model.Upload{UserID: userID, Size: rb.Size})
+	if err != nil {
+		logger.Error("CreateUpload Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, upload)
+}
+
+// FindUpload возвращает загрузку с текущим смещением, с которого клиент продолжает после обрыва.
+func (h *Handler) FindUpload(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("FindUpload Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	upload, err := h.service.FindUpload(c, c.Param("id"), userID)
+	if err != nil {
+		logger.Error("FindUpload Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, upload)
+}
+
+// WriteUploadChunk принимает часть загрузки: тело запроса - содержимое части, параметр offset - ее смещение
+// в файле. Часть пишется в хранилище по мере чтения тела, размер берется из Content-Length.
+func (h *Handler) WriteUploadChunk(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("WriteUploadChunk Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.Query("offset"), 10, 64)
+	if err != nil {
+		logger.Error("WriteUploadChunk Handler parse offset error: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	upload, err := h.service.WriteUploadChunk(c, c.Param("id"), userID, offset, c.Request.Body, c.Request.ContentLength)
+	if err != nil {
+		logger.Error("WriteUploadChunk Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, upload)
+}
+
+// CompleteUpload завершает загрузку: сервер собирает части и сверяет SHA-256 содержимого.
+// Затем файл сохраняется через SaveFile по хешу, без повторной передачи содержимого.
+func (h *Handler) CompleteUpload(c *gin.Context) {
+	var rb model.Content
+
+	err := c.ShouldBindJSON(&rb)
+	if err != nil {
+		logger.Error("CompleteUpload Handler: ", err)
+		abortWithProblem(c, http.StatusBadRequest, model.ProblemCodeInvalidRequest, err.Error())
+
+		return
+	}
+
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("CompleteUpload Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	content, err := h.service.CompleteUpload(c, c.Param("id"), userID, rb.SHA256)
+	if err != nil {
+		logger.Error("CompleteUpload Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, content)
+}
+
+// DeleteUpload прерывает загрузку.
+func (h *Handler) DeleteUpload(c *gin.Context) {
+	userID, err := h.getUserIDFromRequest(c)
+	if err != nil {
+		logger.Error("DeleteUpload Handler: ", err)
+		abortWithProblem(c, http.StatusUnauthorized, model.ProblemCodeUnauthorized, err.Error())
+
+		return
+	}
+
+	err = h.service.DeleteUpload(c, c.Param("id"), userID)
+	if err != nil {
+		logger.Error("DeleteUpload Handler: ", err)
+		abortWithError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Attachment файл, прикрепленный к записи любого типа. Содержимое и имя файла шифруются на клиенте,
+// Path - путь к содержимому в файловом хранилище сервера.
+type Attachment struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-"`
+	ItemType  string    `json:"item_type" db:"item_type"`
+	ItemID    int       `json:"item_id" db:"item_id"`
+	Filename  string    `json:"filename"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AttachmentFilter фильтр списка вложений, нулевые поля не ограничивают выборку.
+type AttachmentFilter struct {
+	ItemType string `form:"item_type"`
+	ItemID   int    `form:"item_id"`
+}
+
+var (
+	ErrAttachmentItemTypeInvalid = newFieldError("item_type", FieldCodeInvalid, "item type unknown")
+	ErrAttachmentItemInvalid     = newFieldError("item_id", FieldCodeInvalid, "item not found")
+	ErrAttachmentFilenameEmpty   = newFieldError("filename", FieldCodeRequired, "filename empty")
+	ErrAttachmentPathEmpty       = errors.New("attachment path empty")
+	ErrAttachmentUserIDEmpty     = errors.New("user id empty")
+)
+
+func (a *Attachment) Validate() error {
+	if !IsItemType(a.ItemType) {
+		return ErrAttachmentItemTypeInvalid
+	}
+
+	if a.ItemID <= 0 {
+		return ErrAttachmentItemInvalid
+	}
+
+	if strings.TrimSpace(a.Filename) == "" {
+		return ErrAttachmentFilenameEmpty
+	}
+
+	if strings.TrimSpace(a.Path) == "" {
+		return ErrAttachmentPathEmpty
+	}
+
+	if a.UserID == 0 {
+		return ErrAttachmentUserIDEmpty
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachment_Validate(t *testing.T) {
+	valid := Attachment{
+		UserID:   1,
+		ItemType: ItemTypeCard,
+		ItemID:   1,
+		Filename: "encrypted",
+		Path:     "_file_storage/aa/bb/cc/scan",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(a *Attachment)
+		wantErr error
+	}{
+		{name: "valid", modify: func(a *Attachment) {}},
+		{name: "custom item", modify: func(a *Attachment) { a.ItemType = ItemTypeCustom }},
+		{name: "unknown item type", modify: func(a *Attachment) { a.ItemType = "note" }, wantErr: ErrAttachmentItemTypeInvalid},
+		{name: "empty item type", modify: func(a *Attachment) { a.ItemType = "" }, wantErr: ErrAttachmentItemTypeInvalid},
+		{name: "empty item", modify: func(a *Attachment) { a.ItemID = 0 }, wantErr: ErrAttachmentItemInvalid},
+		{name: "empty filename", modify: func(a *Attachment) { a.Filename = " " }, wantErr: ErrAttachmentFilenameEmpty},
+		{name: "empty path", modify: func(a *Attachment) { a.Path = "" }, wantErr: ErrAttachmentPathEmpty},
+		{name: "empty user", modify: func(a *Attachment) { a.UserID = 0 }, wantErr: ErrAttachmentUserIDEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := valid
+			tt.modify(&a)
+			assert.Equal(t, tt.wantErr, a.Validate())
+		})
+	}
+}
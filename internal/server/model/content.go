@@ -0,0 +1,7 @@
+package model
+
+// Content содержимое файла в хранилище сервера, адресуемое хешем SHA-256.
+type Content struct {
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
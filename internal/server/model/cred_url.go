@@ -0,0 +1,72 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Правила сопоставления адреса сайта учетной записи с открытой страницей.
+const (
+	// URLMatchDomain совпадение по базовому домену: login.example.com подходит для example.com.
+	URLMatchDomain = "domain"
+	// URLMatchHost точное совпадение хоста и порта.
+	URLMatchHost = "host"
+	// URLMatchRegex регулярное выражение по полному адресу.
+	URLMatchRegex = "regex"
+)
+
+var urlMatchRules = map[string]bool{
+	URLMatchDomain: true,
+	URLMatchHost:   true,
+	URLMatchRegex:  true,
+}
+
+var (
+	ErrCredURLEmpty        = newFieldError("urls", FieldCodeRequired, "url empty")
+	ErrCredURLMatchInvalid = newFieldError("urls", FieldCodeInvalid, "url match rule unknown")
+)
+
+// CredURL адрес сайта учетной записи. URL шифруется на клиенте, правило хранится открыто.
+type CredURL struct {
+	URL   string `json:"url"`
+	Match string `json:"match"`
+}
+
+// CredURLs список адресов учетной записи, в БД хранится одним JSON-столбцом.
+type CredURLs []CredURL
+
+func (u CredURLs) Validate() error {
+	for _, v := range u {
+		if strings.TrimSpace(v.URL) == "" {
+			return ErrCredURLEmpty
+		}
+
+		if !urlMatchRules[v.Match] {
+			return ErrCredURLMatchInvalid
+		}
+	}
+
+	return nil
+}
+
+// Value сохраняет адреса в JSON, пустой список записывается как [].
+func (u CredURLs) Value() (driver.Value, error) {
+	if u == nil {
+		return "[]", nil
+	}
+
+	return jsonValue(u)
+}
+
+func (u *CredURLs) Scan(src any) error {
+	res := CredURLs{}
+	err := scanJSON(src, &res)
+	if err != nil {
+		return fmt.Errorf("model.CredURLs.Scan: %w", err)
+	}
+
+	*u = res
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredURLs_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		urls    CredURLs
+		wantErr error
+	}{
+		{name: "empty", urls: nil},
+		{name: "valid", urls: CredURLs{{URL: "https://example.com", Match: URLMatchDomain}, {URL: "^https://git", Match: URLMatchRegex}}},
+		{name: "empty url", urls: CredURLs{{URL: "", Match: URLMatchHost}}, wantErr: ErrCredURLEmpty},
+		{name: "unknown match", urls: CredURLs{{URL: "https://example.com", Match: "path"}}, wantErr: ErrCredURLMatchInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, tt.urls.Validate())
+		})
+	}
+}
+
+func TestCredURLs_ValueScan(t *testing.T) {
+	urls := CredURLs{{URL: "https://example.com", Match: URLMatchHost}}
+
+	v, err := urls.Value()
+	require.NoError(t, err)
+
+	var got CredURLs
+	require.NoError(t, got.Scan(v))
+	assert.Equal(t, urls, got)
+
+	require.NoError(t, got.Scan(nil))
+	assert.Equal(t, CredURLs{}, got)
+}
@@ -14,15 +14,18 @@ type DataCard struct {
 	Date      string    `json:"date"`
 	Cvv       string    `json:"cvv"`
 	Meta      string    `json:"meta"`
+	FolderID  int       `json:"folder_id" db:"folder_id"`
+	TagIDs    []int     `json:"tag_ids" db:"tag_ids"`
+	Fields    Fields    `json:"fields"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
 var (
-	ErrDataCardTitleEmpty  = errors.New("title empty")
+	ErrDataCardTitleEmpty  = newFieldError("title", FieldCodeRequired, "title empty")
 	ErrDataCardUserIDEmpty = errors.New("user id empty")
-	ErrDataCardNumberEmpty = errors.New("number empty")
-	ErrDataCardDateEmpty   = errors.New("date empty")
-	ErrDataCardCvvEmpty    = errors.New("cvv empty")
+	ErrDataCardNumberEmpty = newFieldError("number", FieldCodeRequired, "number empty")
+	ErrDataCardDateEmpty   = newFieldError("date", FieldCodeRequired, "date empty")
+	ErrDataCardCvvEmpty    = newFieldError("cvv", FieldCodeRequired, "cvv empty")
 )
 
 func (d *DataCard) Validate() error {
@@ -30,6 +33,10 @@ func (d *DataCard) Validate() error {
 		return ErrDataCardTitleEmpty
 	}
 
+	if err := d.Fields.Validate(); err != nil {
+		return err
+	}
+
 	if d.UserID == 0 {
 		return ErrDataCardUserIDEmpty
 	}
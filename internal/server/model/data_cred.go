@@ -13,14 +13,19 @@ type DataCred struct {
 	Username  string    `json:"username"`
 	Password  string    `json:"password"`
 	Meta      string    `json:"meta"`
+	FolderID  int       `json:"folder_id" db:"folder_id"`
+	TagIDs    []int     `json:"tag_ids" db:"tag_ids"`
+	Fields    Fields    `json:"fields"`
+	URLs      CredURLs  `json:"urls"`
+	TOTP      string    `json:"totp" db:"totp"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
 var (
-	ErrDataCredTitleEmpty    = errors.New("title empty")
-	ErrDataCredUsernameEmpty = errors.New("username empty")
-	ErrDataCredPasswordEmpty = errors.New("password empty")
-	ErrDataCredUserIDEmpty   = errors.New("user_id empty")
+	ErrDataCredTitleEmpty    = newFieldError("title", FieldCodeRequired, "title empty")
+	ErrDataCredUsernameEmpty = newFieldError("username", FieldCodeRequired, "username empty")
+	ErrDataCredPasswordEmpty = newFieldError("password", FieldCodeRequired, "password empty")
+	ErrDataCredUserIDEmpty   = errors.New("user id empty")
 )
 
 func (d *DataCred) Validate() error {
@@ -36,8 +41,16 @@ func (d *DataCred) Validate() error {
 		return ErrDataCredPasswordEmpty
 	}
 
+	if err := d.Fields.Validate(); err != nil {
+		return err
+	}
+
+	if err := d.URLs.Validate(); err != nil {
+		return err
+	}
+
 	if d.UserID == 0 {
-		return ErrDataCardUserIDEmpty
+		return ErrDataCredUserIDEmpty
 	}
 
 	return nil
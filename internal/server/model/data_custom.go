@@ -0,0 +1,53 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// DataCustom запись по пользовательскому шаблону TemplateID. Values - зашифрованные на клиенте
+// значения полей шаблона; удаление шаблона удаляет и записи по нему.
+type DataCustom struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"-"`
+	TemplateID int       `json:"template_id" db:"template_id"`
+	Title      string    `json:"title"`
+	Values     string    `json:"values"`
+	Meta       string    `json:"meta"`
+	FolderID   int       `json:"folder_id" db:"folder_id"`
+	TagIDs     []int     `json:"tag_ids" db:"tag_ids"`
+	Fields     Fields    `json:"fields"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+var (
+	ErrDataCustomTitleEmpty    = newFieldError("title", FieldCodeRequired, "title empty")
+	ErrDataCustomTemplateEmpty = newFieldError("template_id", FieldCodeRequired, "template empty")
+	ErrDataCustomValuesEmpty   = newFieldError("values", FieldCodeRequired, "values empty")
+	ErrDataCustomUserIDEmpty   = errors.New("user id empty")
+)
+
+func (d *DataCustom) Validate() error {
+	if strings.TrimSpace(d.Title) == "" {
+		return ErrDataCustomTitleEmpty
+	}
+
+	if d.TemplateID <= 0 {
+		return ErrDataCustomTemplateEmpty
+	}
+
+	if strings.TrimSpace(d.Values) == "" {
+		return ErrDataCustomValuesEmpty
+	}
+
+	if err := d.Fields.Validate(); err != nil {
+		return err
+	}
+
+	if d.UserID == 0 {
+		return ErrDataCustomUserIDEmpty
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataCustom_Validate(t *testing.T) {
+	valid := DataCustom{
+		UserID:     1,
+		TemplateID: 1,
+		Title:      "db prod",
+		Values:     "encrypted",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(d *DataCustom)
+		wantErr error
+	}{
+		{name: "valid", modify: func(d *DataCustom) {}},
+		{name: "empty title", modify: func(d *DataCustom) { d.Title = "" }, wantErr: ErrDataCustomTitleEmpty},
+		{name: "empty template", modify: func(d *DataCustom) { d.TemplateID = 0 }, wantErr: ErrDataCustomTemplateEmpty},
+		{name: "negative template", modify: func(d *DataCustom) { d.TemplateID = -1 }, wantErr: ErrDataCustomTemplateEmpty},
+		{name: "empty values", modify: func(d *DataCustom) { d.Values = " " }, wantErr: ErrDataCustomValuesEmpty},
+		{name: "invalid field", modify: func(d *DataCustom) { d.Fields = Fields{{Label: "", Type: FieldTypeText}} }, wantErr: ErrFieldLabelEmpty},
+		{name: "empty user", modify: func(d *DataCustom) { d.UserID = 0 }, wantErr: ErrDataCustomUserIDEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := valid
+			tt.modify(&d)
+			assert.Equal(t, tt.wantErr, d.Validate())
+		})
+	}
+}
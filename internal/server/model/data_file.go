@@ -12,12 +12,17 @@ type DataFile struct {
 	Title     string    `json:"title"`
 	Filename  string    `json:"filename"`
 	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256" db:"sha256"`
 	Meta      string    `json:"meta"`
+	FolderID  int       `json:"folder_id" db:"folder_id"`
+	TagIDs    []int     `json:"tag_ids" db:"tag_ids"`
+	Fields    Fields    `json:"fields"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
 var (
-	ErrDataFileTitleEmpty  = errors.New("title empty")
+	ErrDataFileTitleEmpty  = newFieldError("title", FieldCodeRequired, "title empty")
 	ErrDataFilePathEmpty   = errors.New("file path empty")
 	ErrDataFileUserIDEmpty = errors.New("user id empty")
 )
@@ -31,6 +36,10 @@ func (d *DataFile) Validate() error {
 		return ErrDataFileTitleEmpty
 	}
 
+	if err := d.Fields.Validate(); err != nil {
+		return err
+	}
+
 	if d.UserID == 0 {
 		return ErrDataFileUserIDEmpty
 	}
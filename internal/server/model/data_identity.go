@@ -0,0 +1,75 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Виды документов; вид передается открыто, остальные поля шифруются на клиенте, как у карт.
+const (
+	IdentityKindPassport      = "passport"
+	IdentityKindDriverLicense = "driver_license"
+	IdentityKindIDCard        = "id_card"
+)
+
+var identityKinds = map[string]bool{
+	IdentityKindPassport:      true,
+	IdentityKindDriverLicense: true,
+	IdentityKindIDCard:        true,
+}
+
+// DataIdentity удостоверение личности: паспорт, водительское удостоверение, ID-карта.
+type DataIdentity struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"-"`
+	Title      string    `json:"title"`
+	Kind       string    `json:"kind"`
+	FullName   string    `json:"full_name" db:"full_name"`
+	Number     string    `json:"number"`
+	Country    string    `json:"country"`
+	IssueDate  string    `json:"issue_date" db:"issue_date"`
+	ExpiryDate string    `json:"expiry_date" db:"expiry_date"`
+	Address    string    `json:"address"`
+	Meta       string    `json:"meta"`
+	FolderID   int       `json:"folder_id" db:"folder_id"`
+	TagIDs     []int     `json:"tag_ids" db:"tag_ids"`
+	Fields     Fields    `json:"fields"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+var (
+	ErrDataIdentityTitleEmpty    = newFieldError("title", FieldCodeRequired, "title empty")
+	ErrDataIdentityKindInvalid   = newFieldError("kind", FieldCodeInvalid, "document kind unknown")
+	ErrDataIdentityFullNameEmpty = newFieldError("full_name", FieldCodeRequired, "full name empty")
+	ErrDataIdentityNumberEmpty   = newFieldError("number", FieldCodeRequired, "number empty")
+	ErrDataIdentityUserIDEmpty   = errors.New("user id empty")
+)
+
+func (d *DataIdentity) Validate() error {
+	if strings.TrimSpace(d.Title) == "" {
+		return ErrDataIdentityTitleEmpty
+	}
+
+	if !identityKinds[d.Kind] {
+		return ErrDataIdentityKindInvalid
+	}
+
+	if strings.TrimSpace(d.FullName) == "" {
+		return ErrDataIdentityFullNameEmpty
+	}
+
+	if strings.TrimSpace(d.Number) == "" {
+		return ErrDataIdentityNumberEmpty
+	}
+
+	if err := d.Fields.Validate(); err != nil {
+		return err
+	}
+
+	if d.UserID == 0 {
+		return ErrDataIdentityUserIDEmpty
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataIdentity_Validate(t *testing.T) {
+	valid := DataIdentity{
+		UserID:   1,
+		Title:    "паспорт",
+		Kind:     IdentityKindPassport,
+		FullName: "encrypted",
+		Number:   "encrypted",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(d *DataIdentity)
+		wantErr error
+	}{
+		{name: "valid", modify: func(d *DataIdentity) {}},
+		{name: "driver license", modify: func(d *DataIdentity) { d.Kind = IdentityKindDriverLicense }},
+		{name: "empty title", modify: func(d *DataIdentity) { d.Title = "" }, wantErr: ErrDataIdentityTitleEmpty},
+		{name: "empty kind", modify: func(d *DataIdentity) { d.Kind = "" }, wantErr: ErrDataIdentityKindInvalid},
+		{name: "unknown kind", modify: func(d *DataIdentity) { d.Kind = "visa" }, wantErr: ErrDataIdentityKindInvalid},
+		{name: "empty name", modify: func(d *DataIdentity) { d.FullName = " " }, wantErr: ErrDataIdentityFullNameEmpty},
+		{name: "empty number", modify: func(d *DataIdentity) { d.Number = "" }, wantErr: ErrDataIdentityNumberEmpty},
+		{name: "invalid field", modify: func(d *DataIdentity) { d.Fields = Fields{{Label: "", Type: FieldTypeText}} }, wantErr: ErrFieldLabelEmpty},
+		{name: "empty user", modify: func(d *DataIdentity) { d.UserID = 0 }, wantErr: ErrDataIdentityUserIDEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := valid
+			tt.modify(&d)
+			assert.Equal(t, tt.wantErr, d.Validate())
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// DataSSHKey SSH-ключ. Закрытый ключ, парольная фраза и заметка шифруются на клиенте,
+// открытый ключ и отпечаток хранятся открыто, чтобы их можно было показать без расшифровки.
+type DataSSHKey struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"-"`
+	Title       string    `json:"title"`
+	PrivateKey  string    `json:"private_key" db:"private_key"`
+	PublicKey   string    `json:"public_key" db:"public_key"`
+	Fingerprint string    `json:"fingerprint"`
+	Passphrase  string    `json:"passphrase"`
+	Meta        string    `json:"meta"`
+	FolderID    int       `json:"folder_id" db:"folder_id"`
+	TagIDs      []int     `json:"tag_ids" db:"tag_ids"`
+	Fields      Fields    `json:"fields"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+var (
+	ErrDataSSHKeyTitleEmpty      = newFieldError("title", FieldCodeRequired, "title empty")
+	ErrDataSSHKeyPrivateKeyEmpty = newFieldError("private_key", FieldCodeRequired, "private key empty")
+	ErrDataSSHKeyPublicKeyEmpty  = newFieldError("public_key", FieldCodeRequired, "public key empty")
+	ErrDataSSHKeyUserIDEmpty     = errors.New("user id empty")
+)
+
+func (d *DataSSHKey) Validate() error {
+	if strings.TrimSpace(d.Title) == "" {
+		return ErrDataSSHKeyTitleEmpty
+	}
+
+	if strings.TrimSpace(d.PrivateKey) == "" {
+		return ErrDataSSHKeyPrivateKeyEmpty
+	}
+
+	if strings.TrimSpace(d.PublicKey) == "" {
+		return ErrDataSSHKeyPublicKeyEmpty
+	}
+
+	if err := d.Fields.Validate(); err != nil {
+		return err
+	}
+
+	if d.UserID == 0 {
+		return ErrDataSSHKeyUserIDEmpty
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataSSHKey_Validate(t *testing.T) {
+	valid := DataSSHKey{
+		UserID:     1,
+		Title:      "github",
+		PrivateKey: "encrypted",
+		PublicKey:  "ssh-ed25519 AAAA",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(d *DataSSHKey)
+		wantErr error
+	}{
+		{name: "valid", modify: func(d *DataSSHKey) {}},
+		{name: "empty title", modify: func(d *DataSSHKey) { d.Title = " " }, wantErr: ErrDataSSHKeyTitleEmpty},
+		{name: "empty private key", modify: func(d *DataSSHKey) { d.PrivateKey = "" }, wantErr: ErrDataSSHKeyPrivateKeyEmpty},
+		{name: "empty public key", modify: func(d *DataSSHKey) { d.PublicKey = "" }, wantErr: ErrDataSSHKeyPublicKeyEmpty},
+		{name: "invalid field", modify: func(d *DataSSHKey) { d.Fields = Fields{{Label: "a", Type: "bad"}} }, wantErr: ErrFieldTypeInvalid},
+		{name: "empty user", modify: func(d *DataSSHKey) { d.UserID = 0 }, wantErr: ErrDataSSHKeyUserIDEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := valid
+			tt.modify(&d)
+			assert.Equal(t, tt.wantErr, d.Validate())
+		})
+	}
+}
@@ -12,12 +12,15 @@ type DataText struct {
 	Title     string    `json:"title"`
 	Text      string    `json:"text"`
 	Meta      string    `json:"meta"`
+	FolderID  int       `json:"folder_id" db:"folder_id"`
+	TagIDs    []int     `json:"tag_ids" db:"tag_ids"`
+	Fields    Fields    `json:"fields"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
 var (
-	ErrDataTextTitleEmpty  = errors.New("title empty")
-	ErrDataTextEmpty       = errors.New("text empty")
+	ErrDataTextTitleEmpty  = newFieldError("title", FieldCodeRequired, "title empty")
+	ErrDataTextEmpty       = newFieldError("text", FieldCodeRequired, "text empty")
 	ErrDataTextUserIDEmpty = errors.New("user id empty")
 )
 
@@ -30,6 +33,10 @@ func (d *DataText) Validate() error {
 		return ErrDataTextEmpty
 	}
 
+	if err := d.Fields.Validate(); err != nil {
+		return err
+	}
+
 	if d.UserID == 0 {
 		return ErrDataTextUserIDEmpty
 	}
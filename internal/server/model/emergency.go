@@ -0,0 +1,98 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Состояния экстренного доступа.
+const (
+	// EmergencyStatusIdle доверенное лицо назначено, доступ не запрошен.
+	EmergencyStatusIdle = "idle"
+	// EmergencyStatusRequested доступ запрошен, идет период ожидания.
+	EmergencyStatusRequested = "requested"
+	// EmergencyStatusRejected владелец отклонил запрос.
+	EmergencyStatusRejected = "rejected"
+	// EmergencyStatusGranted период ожидания истек, доступ открыт.
+	EmergencyStatusGranted = "granted"
+)
+
+// MaxEmergencyWaitHours наибольший период ожидания экстренного доступа - 90 дней.
+const MaxEmergencyWaitHours = 90 * 24
+
+// EmergencyAccess экстренный доступ доверенного лица (Grantee) к хранилищу владельца (Grantor).
+// Key - ключ хранилища владельца, зашифрованный открытым ключом доверенного лица; доверенному лицу
+// он выдается только после открытия доступа. Доступ открывается через WaitHours часов после запроса,
+// если владелец не отклонил запрос; AvailableAt - время открытия запрошенного доступа.
+type EmergencyAccess struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"-" db:"user_id"`
+	Grantor     string    `json:"grantor"`
+	GranteeID   int       `json:"-" db:"grantee_id"`
+	Grantee     string    `json:"grantee"`
+	WaitHours   int       `json:"wait_hours" db:"wait_hours"`
+	Key         string    `json:"key,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	RequestedAt time.Time `json:"requested_at" db:"requested_at"`
+	AvailableAt time.Time `json:"available_at" db:"-"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// EmergencyVault записи владельца, доступные доверенному лицу после открытия экстренного доступа.
+// Записи зашифрованы ключом хранилища владельца.
+type EmergencyVault struct {
+	Access      EmergencyAccess `json:"access"`
+	Cards       []DataCard      `json:"cards"`
+	Creds       []DataCred      `json:"creds"`
+	Texts       []DataText      `json:"texts"`
+	Files       []DataFile      `json:"files"`
+	SSHKeys     []DataSSHKey    `json:"ssh_keys"`
+	Identities  []DataIdentity  `json:"identities"`
+	CustomItems []DataCustom    `json:"custom_items"`
+	Templates   []Template      `json:"templates"`
+	Attachments []Attachment    `json:"attachments"`
+}
+
+var (
+	ErrEmergencyGranteeEmpty   = newFieldError("grantee", FieldCodeRequired, "grantee empty")
+	ErrEmergencyGranteeInvalid = newFieldError("grantee", FieldCodeInvalid, "grantee not found or has no keys")
+	ErrEmergencyWaitInvalid    = newFieldError("wait_hours", FieldCodeInvalid, "wait hours must be between 0 and 2160")
+	ErrEmergencyKeyEmpty       = newFieldError("key", FieldCodeRequired, "key empty")
+	ErrEmergencyUserIDEmpty    = errors.New("user id empty")
+)
+
+func (e *EmergencyAccess) Validate() error {
+	if strings.TrimSpace(e.Grantee) == "" {
+		return ErrEmergencyGranteeEmpty
+	}
+
+	if e.WaitHours < 0 || e.WaitHours > MaxEmergencyWaitHours {
+		return ErrEmergencyWaitInvalid
+	}
+
+	if strings.TrimSpace(e.Key) == "" {
+		return ErrEmergencyKeyEmpty
+	}
+
+	if e.UserID == 0 {
+		return ErrEmergencyUserIDEmpty
+	}
+
+	return nil
+}
+
+// Resolve переводит запрошенный доступ в открытый, если к моменту now истек период ожидания,
+// и заполняет AvailableAt.
+func (e *EmergencyAccess) Resolve(now time.Time) {
+	if e.Status != EmergencyStatusRequested && e.Status != EmergencyStatusGranted {
+		e.AvailableAt = time.Time{}
+
+		return
+	}
+
+	e.AvailableAt = e.RequestedAt.Add(time.Duration(e.WaitHours) * time.Hour)
+	if e.Status == EmergencyStatusRequested && !now.Before(e.AvailableAt) {
+		e.Status = EmergencyStatusGranted
+	}
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmergencyAccess_Validate(t *testing.T) {
+	valid := EmergencyAccess{UserID: 1, Grantee: "contact", WaitHours: 48, Key: "key"}
+
+	tests := []struct {
+		name    string
+		modify  func(e *EmergencyAccess)
+		wantErr error
+	}{
+		{name: "ok", modify: func(e *EmergencyAccess) {}},
+		{name: "no wait", modify: func(e *EmergencyAccess) { e.WaitHours = 0 }},
+		{name: "grantee empty", modify: func(e *EmergencyAccess) { e.Grantee = " " }, wantErr: ErrEmergencyGranteeEmpty},
+		{name: "negative wait", modify: func(e *EmergencyAccess) { e.WaitHours = -1 }, wantErr: ErrEmergencyWaitInvalid},
+		{name: "wait too long", modify: func(e *EmergencyAccess) { e.WaitHours = MaxEmergencyWaitHours + 1 }, wantErr: ErrEmergencyWaitInvalid},
+		{name: "key empty", modify: func(e *EmergencyAccess) { e.Key = "" }, wantErr: ErrEmergencyKeyEmpty},
+		{name: "user empty", modify: func(e *EmergencyAccess) { e.UserID = 0 }, wantErr: ErrEmergencyUserIDEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := valid
+			tt.modify(&e)
+			err := e.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestEmergencyAccess_Resolve(t *testing.T) {
+	requestedAt := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		status     string
+		now        time.Time
+		wantStatus string
+		wantAt     time.Time
+	}{
+		{name: "idle", status: EmergencyStatusIdle, now: requestedAt.Add(100 * time.Hour), wantStatus: EmergencyStatusIdle},
+		{name: "waiting", status: EmergencyStatusRequested, now: requestedAt.Add(47 * time.Hour), wantStatus: EmergencyStatusRequested, wantAt: requestedAt.Add(48 * time.Hour)},
+		{name: "wait elapsed", status: EmergencyStatusRequested, now: requestedAt.Add(48 * time.Hour), wantStatus: EmergencyStatusGranted, wantAt: requestedAt.Add(48 * time.Hour)},
+		{name: "rejected", status: EmergencyStatusRejected, now: requestedAt.Add(100 * time.Hour), wantStatus: EmergencyStatusRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := EmergencyAccess{Status: tt.status, WaitHours: 48, RequestedAt: requestedAt}
+			e.Resolve(tt.now)
+			assert.Equal(t, tt.wantStatus, e.Status)
+			assert.True(t, tt.wantAt.Equal(e.AvailableAt))
+		})
+	}
+}
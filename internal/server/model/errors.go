@@ -0,0 +1,22 @@
+package model
+
+// Коды ошибок валидации полей.
+const (
+	FieldCodeRequired = "required"
+	FieldCodeInvalid  = "invalid"
+)
+
+// FieldError ошибка валидации отдельного поля модели.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+func newFieldError(field, code, message string) *FieldError {
+	return &FieldError{Field: field, Code: code, Message: message}
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantField string
+		wantCode  string
+	}{
+		{
+			name:      "card cvv",
+			err:       ErrDataCardCvvEmpty,
+			wantField: "cvv",
+			wantCode:  FieldCodeRequired,
+		},
+		{
+			name:      "user login",
+			err:       ErrUserLoginEmpty,
+			wantField: "login",
+			wantCode:  FieldCodeRequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fieldErr *FieldError
+			assert.True(t, errors.As(tt.err, &fieldErr))
+			assert.Equal(t, tt.wantField, fieldErr.Field)
+			assert.Equal(t, tt.wantCode, fieldErr.Code)
+			assert.Equal(t, tt.err.Error(), fieldErr.Message)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Типы пользовательских полей записи.
+const (
+	FieldTypeText   = "text"
+	FieldTypeHidden = "hidden"
+	FieldTypeURL    = "url"
+	FieldTypeEmail  = "email"
+	FieldTypeDate   = "date"
+	FieldTypeTOTP   = "totp"
+)
+
+var fieldTypes = map[string]bool{
+	FieldTypeText:   true,
+	FieldTypeHidden: true,
+	FieldTypeURL:    true,
+	FieldTypeEmail:  true,
+	FieldTypeDate:   true,
+	FieldTypeTOTP:   true,
+}
+
+var (
+	ErrFieldLabelEmpty  = newFieldError("fields", FieldCodeRequired, "field label empty")
+	ErrFieldTypeInvalid = newFieldError("fields", FieldCodeInvalid, "field type unknown")
+)
+
+// Field пользовательское поле записи. Label и Value шифруются на клиенте,
+// Type хранится открыто, чтобы сервер мог его проверить.
+type Field struct {
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Fields список пользовательских полей, в БД хранится одним JSON-столбцом.
+type Fields []Field
+
+func (f Fields) Validate() error {
+	for _, v := range f {
+		if strings.TrimSpace(v.Label) == "" {
+			return ErrFieldLabelEmpty
+		}
+
+		if !fieldTypes[v.Type] {
+			return ErrFieldTypeInvalid
+		}
+	}
+
+	return nil
+}
+
+// Value сохраняет поля в JSON, пустой список записывается как [].
+func (f Fields) Value() (driver.Value, error) {
+	if f == nil {
+		return "[]", nil
+	}
+
+	return jsonValue(f)
+}
+
+func (f *Fields) Scan(src any) error {
+	res := Fields{}
+	err := scanJSON(src, &res)
+	if err != nil {
+		return fmt.Errorf("model.Fields.Scan: %w", err)
+	}
+
+	*f = res
+
+	return nil
+}
+
+// jsonValue значение JSON-столбца БД.
+func jsonValue(v any) (driver.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+// scanJSON читает JSON-столбец БД в dst; NULL оставляет dst без изменений.
+func scanJSON(src any, dst any) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), dst)
+	case []byte:
+		return json.Unmarshal(v, dst)
+	default:
+		return fmt.Errorf("unsupported type %T", src)
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFields_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  Fields
+		wantErr error
+	}{
+		{name: "empty", fields: nil},
+		{name: "valid", fields: Fields{{Label: "pin", Type: FieldTypeHidden, Value: "1234"}, {Label: "site", Type: FieldTypeURL}}},
+		{name: "empty label", fields: Fields{{Label: " ", Type: FieldTypeText}}, wantErr: ErrFieldLabelEmpty},
+		{name: "unknown type", fields: Fields{{Label: "pin", Type: "number"}}, wantErr: ErrFieldTypeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, tt.fields.Validate())
+		})
+	}
+}
+
+func TestFields_ValueScan(t *testing.T) {
+	fields := Fields{{Label: "pin", Type: FieldTypeHidden, Value: "1234"}}
+
+	v, err := fields.Value()
+	require.NoError(t, err)
+
+	var got Fields
+	require.NoError(t, got.Scan(v))
+	assert.Equal(t, fields, got)
+
+	v, err = Fields(nil).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "[]", v)
+
+	require.NoError(t, got.Scan([]byte("[]")))
+	assert.Equal(t, Fields{}, got)
+
+	require.NoError(t, got.Scan(nil))
+	assert.Equal(t, Fields{}, got)
+
+	assert.Error(t, got.Scan(1))
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Folder папка для группировки записей; папки вкладываются через ParentID (0 - корень).
+// Name шифруется на клиенте, как и остальные поля записей.
+type Folder struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-"`
+	ParentID  int       `json:"parent_id"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+var (
+	ErrFolderNameEmpty     = newFieldError("name", FieldCodeRequired, "name empty")
+	ErrFolderParentInvalid = newFieldError("parent_id", FieldCodeInvalid, "parent folder not found or creates a cycle")
+	ErrFolderUserIDEmpty   = errors.New("user id empty")
+)
+
+func (f *Folder) Validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return ErrFolderNameEmpty
+	}
+
+	if f.UserID == 0 {
+		return ErrFolderUserIDEmpty
+	}
+
+	if f.ParentID < 0 || (f.ID != 0 && f.ParentID == f.ID) {
+		return ErrFolderParentInvalid
+	}
+
+	return nil
+}
+
+// Tag метка записи, Name шифруется на клиенте.
+type Tag struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+var (
+	ErrTagNameEmpty   = newFieldError("name", FieldCodeRequired, "name empty")
+	ErrTagUserIDEmpty = errors.New("user id empty")
+)
+
+func (t *Tag) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return ErrTagNameEmpty
+	}
+
+	if t.UserID == 0 {
+		return ErrTagUserIDEmpty
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Таблицы записей, ссылающихся на содержимое в хранилище файлов.
+const (
+	FileRefTableFiles       = "data_files"
+	FileRefTableAttachments = "attachments"
+)
+
+// FileRef запись (файл или вложение), ссылающаяся на содержимое в хранилище файлов по пути Path.
+type FileRef struct {
+	Table  string `json:"table"`
+	ID     int    `json:"id"`
+	UserID int    `json:"user_id" db:"user_id"`
+	Path   string `json:"path"`
+}
+
+// OrphanBlob содержимое в хранилище файлов, на которое не ссылается ни одна запись.
+type OrphanBlob struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Deleted bool      `json:"deleted"`
+}
+
+// GCReport результат сверки хранилища файлов с БД: неиспользуемое содержимое старше срока ожидания
+// и записи, содержимого которых нет в хранилище.
+type GCReport struct {
+	Blobs   int          `json:"blobs"`
+	Orphans []OrphanBlob `json:"orphans"`
+	Missing []FileRef    `json:"missing"`
+	Freed   int64        `json:"freed"`
+}
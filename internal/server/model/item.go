@@ -0,0 +1,34 @@
+package model
+
+// Типы записей хранилища, используются в связях записей с метками.
+const (
+	ItemTypeCard     = "card"
+	ItemTypeCred     = "cred"
+	ItemTypeText     = "text"
+	ItemTypeFile     = "file"
+	ItemTypeSSH      = "ssh"
+	ItemTypeIdentity = "identity"
+	ItemTypeCustom   = "custom"
+)
+
+var (
+	ErrItemFolderInvalid   = newFieldError("folder_id", FieldCodeInvalid, "folder not found")
+	ErrItemTagsInvalid     = newFieldError("tag_ids", FieldCodeInvalid, "tag not found")
+	ErrItemTemplateInvalid = newFieldError("template_id", FieldCodeInvalid, "template not found")
+)
+
+// IsItemType проверяет, что t - известный тип записи.
+func IsItemType(t string) bool {
+	switch t {
+	case ItemTypeCard, ItemTypeCred, ItemTypeText, ItemTypeFile, ItemTypeSSH, ItemTypeIdentity, ItemTypeCustom:
+		return true
+	}
+
+	return false
+}
+
+// ItemFilter фильтр списка записей, нулевые поля не ограничивают выборку.
+type ItemFilter struct {
+	FolderID int `form:"folder_id"`
+	TagID    int `form:"tag_id"`
+}
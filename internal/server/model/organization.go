@@ -0,0 +1,157 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Роли участников организации, по возрастанию прав.
+const (
+	RoleReadOnly = "read-only"
+	RoleMember   = "member"
+	RoleAdmin    = "admin"
+	RoleOwner    = "owner"
+)
+
+// Состояния участия в организации: приглашенный не видит коллекции, пока не примет приглашение.
+const (
+	MemberStatusInvited = "invited"
+	MemberStatusActive  = "active"
+)
+
+var roleRanks = map[string]int{
+	RoleReadOnly: 1,
+	RoleMember:   2,
+	RoleAdmin:    3,
+	RoleOwner:    4,
+}
+
+// RoleAtLeast роль role дает права не меньше, чем роль min.
+func RoleAtLeast(role, min string) bool {
+	return roleRanks[role] >= roleRanks[min] && roleRanks[role] > 0
+}
+
+// Organization организация с общими коллекциями. Role и Status - участие в ней текущего пользователя.
+type Organization struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Member участник организации. PublicKey - открытый ключ X25519 участника, которым администратор
+// шифрует для него ключи коллекций; Keys - ключи коллекций, передаваемые при приглашении.
+type Member struct {
+	OrgID     int             `json:"org_id" db:"org_id"`
+	UserID    int             `json:"-" db:"user_id"`
+	Login     string          `json:"login"`
+	Role      string          `json:"role,omitempty"`
+	Status    string          `json:"status,omitempty"`
+	PublicKey string          `json:"public_key,omitempty" db:"public_key"`
+	Keys      []CollectionKey `json:"keys,omitempty" db:"-"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Collection общая коллекция записей организации. Key - ключ коллекции, зашифрованный открытым ключом
+// текущего пользователя, Role - его роль в организации; Keys - ключи для участников при создании коллекции.
+type Collection struct {
+	ID        int             `json:"id"`
+	OrgID     int             `json:"org_id" db:"org_id"`
+	Name      string          `json:"name"`
+	Key       string          `json:"key,omitempty"`
+	Role      string          `json:"role,omitempty"`
+	Keys      []CollectionKey `json:"keys,omitempty" db:"-"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// CollectionKey ключ коллекции, зашифрованный открытым ключом участника Login.
+type CollectionKey struct {
+	CollectionID int    `json:"collection_id" db:"collection_id"`
+	UserID       int    `json:"-" db:"user_id"`
+	Login        string `json:"login"`
+	Key          string `json:"key"`
+}
+
+// CollectionItem запись коллекции; Data - запись типа ItemType, зашифрованная ключом коллекции целиком.
+// Записи коллекций хранятся отдельно от личных записей и доступны только через методы коллекций
+// с проверкой роли. UserID - участник, последним сохранивший запись: она учитывается в его квоте.
+type CollectionItem struct {
+	ID           int       `json:"id"`
+	CollectionID int       `json:"collection_id" db:"collection_id"`
+	UserID       int       `json:"-" db:"user_id"`
+	ItemType     string    `json:"item_type,omitempty" db:"item_type"`
+	Data         string    `json:"data,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+var (
+	ErrOrganizationNameEmpty = newFieldError("name", FieldCodeRequired, "name empty")
+
+	ErrMemberLoginEmpty   = newFieldError("login", FieldCodeRequired, "login empty")
+	ErrMemberLoginInvalid = newFieldError("login", FieldCodeInvalid, "user not found or has no keys")
+	ErrMemberRoleInvalid  = newFieldError("role", FieldCodeInvalid, "role must be admin, member or read-only")
+	ErrMemberOrgIDEmpty   = newFieldError("org_id", FieldCodeRequired, "organization id empty")
+
+	ErrCollectionNameEmpty  = newFieldError("name", FieldCodeRequired, "name empty")
+	ErrCollectionOrgIDEmpty = newFieldError("org_id", FieldCodeRequired, "organization id empty")
+	ErrCollectionKeyEmpty   = newFieldError("keys", FieldCodeRequired, "collection key for current user empty")
+	ErrCollectionKeyInvalid = newFieldError("keys", FieldCodeInvalid, "key for user who is not a member")
+
+	ErrCollectionItemTypeInvalid = newFieldError("item_type", FieldCodeInvalid, "item type unknown")
+	ErrCollectionItemDataEmpty   = newFieldError("data", FieldCodeRequired, "data empty")
+	ErrCollectionItemIDEmpty     = newFieldError("collection_id", FieldCodeRequired, "collection id empty")
+)
+
+func (o *Organization) Validate() error {
+	if strings.TrimSpace(o.Name) == "" {
+		return ErrOrganizationNameEmpty
+	}
+
+	return nil
+}
+
+// Validate проверяет приглашение участника; роль владельца не назначается, она есть только у создателя.
+func (m *Member) Validate() error {
+	if m.OrgID == 0 {
+		return ErrMemberOrgIDEmpty
+	}
+
+	if strings.TrimSpace(m.Login) == "" {
+		return ErrMemberLoginEmpty
+	}
+
+	if m.Role == RoleOwner || !RoleAtLeast(m.Role, RoleReadOnly) {
+		return ErrMemberRoleInvalid
+	}
+
+	return nil
+}
+
+func (c *Collection) Validate() error {
+	if c.OrgID == 0 {
+		return ErrCollectionOrgIDEmpty
+	}
+
+	if strings.TrimSpace(c.Name) == "" {
+		return ErrCollectionNameEmpty
+	}
+
+	return nil
+}
+
+func (i *CollectionItem) Validate() error {
+	if i.CollectionID == 0 {
+		return ErrCollectionItemIDEmpty
+	}
+
+	if !IsItemType(i.ItemType) {
+		return ErrCollectionItemTypeInvalid
+	}
+
+	if strings.TrimSpace(i.Data) == "" {
+		return ErrCollectionItemDataEmpty
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleAtLeast(t *testing.T) {
+	tests := []struct {
+		role string
+		min  string
+		want bool
+	}{
+		{role: RoleOwner, min: RoleAdmin, want: true},
+		{role: RoleAdmin, min: RoleAdmin, want: true},
+		{role: RoleMember, min: RoleAdmin, want: false},
+		{role: RoleMember, min: RoleMember, want: true},
+		{role: RoleReadOnly, min: RoleMember, want: false},
+		{role: RoleReadOnly, min: RoleReadOnly, want: true},
+		{role: "guest", min: RoleReadOnly, want: false},
+		{role: "", min: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role+">="+tt.min, func(t *testing.T) {
+			assert.Equal(t, tt.want, RoleAtLeast(tt.role, tt.min))
+		})
+	}
+}
+
+func TestMember_Validate(t *testing.T) {
+	valid := Member{OrgID: 1, Login: "colleague", Role: RoleMember}
+
+	tests := []struct {
+		name    string
+		modify  func(m *Member)
+		wantErr error
+	}{
+		{name: "valid", modify: func(m *Member) {}},
+		{name: "admin", modify: func(m *Member) { m.Role = RoleAdmin }},
+		{name: "read-only", modify: func(m *Member) { m.Role = RoleReadOnly }},
+		{name: "owner", modify: func(m *Member) { m.Role = RoleOwner }, wantErr: ErrMemberRoleInvalid},
+		{name: "unknown role", modify: func(m *Member) { m.Role = "guest" }, wantErr: ErrMemberRoleInvalid},
+		{name: "empty login", modify: func(m *Member) { m.Login = " " }, wantErr: ErrMemberLoginEmpty},
+		{name: "empty organization", modify: func(m *Member) { m.OrgID = 0 }, wantErr: ErrMemberOrgIDEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid
+			tt.modify(&m)
+			assert.Equal(t, tt.wantErr, m.Validate())
+		})
+	}
+}
+
+func TestCollectionItem_Validate(t *testing.T) {
+	valid := CollectionItem{CollectionID: 1, ItemType: ItemTypeCred, Data: "encrypted"}
+
+	tests := []struct {
+		name    string
+		modify  func(i *CollectionItem)
+		wantErr error
+	}{
+		{name: "valid", modify: func(i *CollectionItem) {}},
+		{name: "empty collection", modify: func(i *CollectionItem) { i.CollectionID = 0 }, wantErr: ErrCollectionItemIDEmpty},
+		{name: "unknown type", modify: func(i *CollectionItem) { i.ItemType = "note" }, wantErr: ErrCollectionItemTypeInvalid},
+		{name: "empty data", modify: func(i *CollectionItem) { i.Data = "" }, wantErr: ErrCollectionItemDataEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := valid
+			tt.modify(&i)
+			assert.Equal(t, tt.wantErr, i.Validate())
+		})
+	}
+}
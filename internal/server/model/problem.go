@@ -0,0 +1,38 @@
+package model
+
+// Коды ошибок API, передаются в поле code ответа application/problem+json.
+const (
+	ProblemCodeInvalidRequest     = "invalid_request"
+	ProblemCodeValidationFailed   = "validation_failed"
+	ProblemCodeUnauthorized       = "unauthorized"
+	ProblemCodeInvalidCredentials = "invalid_credentials"
+	ProblemCodeNotFound           = "not_found"
+	ProblemCodeForbidden          = "forbidden"
+	ProblemCodeAlreadyExists      = "already_exists"
+	ProblemCodeLoginExists        = "login_exists"
+	ProblemCodeQuotaExceeded      = "quota_exceeded"
+	ProblemCodeBodyTooLarge       = "body_too_large"
+	ProblemCodeUploadOffset       = "upload_offset_mismatch"
+	ProblemCodeUploadIncomplete   = "upload_incomplete"
+	ProblemCodeChecksumMismatch   = "checksum_mismatch"
+	ProblemCodeInternal           = "internal_error"
+)
+
+// ProblemContentType тип содержимого ответа с ошибкой (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// Problem описание ошибки API в формате RFC 7807.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// ProblemType возвращает URI типа ошибки для кода.
+func ProblemType(code string) string {
+	return "urn:gophkeeper:problem:" + code
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxSecretViews наибольшее число просмотров одноразового секрета.
+const MaxSecretViews = 10
+
+// MaxSecretTTL наибольший срок жизни одноразового секрета - 7 дней.
+const MaxSecretTTL = 7 * 24 * time.Hour
+
+// MaxSecretSize наибольший размер зашифрованного секрета в base64.
+const MaxSecretSize = 64 << 10
+
+// Secret одноразовый секрет для передачи по ссылке. Data шифруется клиентом ключом, который передается
+// только во фрагменте ссылки (#...) и на сервер не попадает. Секрет удаляется после MaxViews просмотров
+// или по истечении ExpiresAt.
+type Secret struct {
+	ID        string    `json:"id"`
+	UserID    int       `json:"-" db:"user_id"`
+	Data      string    `json:"data,omitempty"`
+	MaxViews  int       `json:"max_views" db:"max_views"`
+	Views     int       `json:"views"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+var (
+	ErrSecretDataEmpty      = newFieldError("data", FieldCodeRequired, "data empty")
+	ErrSecretDataTooLarge   = newFieldError("data", FieldCodeInvalid, "data is too large")
+	ErrSecretViewsInvalid   = newFieldError("max_views", FieldCodeInvalid, "max views must be between 1 and 10")
+	ErrSecretExpiresInvalid = newFieldError("expires_at", FieldCodeInvalid, "expiry must be within 7 days")
+	ErrSecretUserIDEmpty    = errors.New("user id empty")
+)
+
+// Validate проверяет секрет перед созданием; срок жизни отсчитывается от now.
+func (s *Secret) Validate(now time.Time) error {
+	if s.Data == "" {
+		return ErrSecretDataEmpty
+	}
+
+	if len(s.Data) > MaxSecretSize {
+		return ErrSecretDataTooLarge
+	}
+
+	if s.MaxViews < 1 || s.MaxViews > MaxSecretViews {
+		return ErrSecretViewsInvalid
+	}
+
+	if !s.ExpiresAt.After(now) || s.ExpiresAt.After(now.Add(MaxSecretTTL)) {
+		return ErrSecretExpiresInvalid
+	}
+
+	if s.UserID == 0 {
+		return ErrSecretUserIDEmpty
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecret_Validate(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	valid := Secret{UserID: 1, Data: "data", MaxViews: 1, ExpiresAt: now.Add(time.Hour)}
+
+	tests := []struct {
+		name    string
+		modify  func(s *Secret)
+		wantErr error
+	}{
+		{name: "ok", modify: func(s *Secret) {}},
+		{name: "max ttl", modify: func(s *Secret) { s.ExpiresAt = now.Add(MaxSecretTTL) }},
+		{name: "data empty", modify: func(s *Secret) { s.Data = "" }, wantErr: ErrSecretDataEmpty},
+		{name: "data too large", modify: func(s *Secret) { s.Data = strings.Repeat("a", MaxSecretSize+1) }, wantErr: ErrSecretDataTooLarge},
+		{name: "no views", modify: func(s *Secret) { s.MaxViews = 0 }, wantErr: ErrSecretViewsInvalid},
+		{name: "too many views", modify: func(s *Secret) { s.MaxViews = MaxSecretViews + 1 }, wantErr: ErrSecretViewsInvalid},
+		{name: "expired", modify: func(s *Secret) { s.ExpiresAt = now }, wantErr: ErrSecretExpiresInvalid},
+		{name: "ttl too long", modify: func(s *Secret) { s.ExpiresAt = now.Add(MaxSecretTTL + time.Second) }, wantErr: ErrSecretExpiresInvalid},
+		{name: "user empty", modify: func(s *Secret) { s.UserID = 0 }, wantErr: ErrSecretUserIDEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid
+			tt.modify(&s)
+			err := s.Validate(now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
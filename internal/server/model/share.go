@@ -0,0 +1,115 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Уровни доступа получателя к открытой ему записи.
+const (
+	ShareAccessRead  = "read"
+	ShareAccessWrite = "write"
+)
+
+// UserKeys пара ключей X25519 пользователя для обмена записями. Открытый ключ хранится открыто,
+// закрытый - зашифрованным на клиенте ключом хранилища пользователя.
+type UserKeys struct {
+	UserID     int       `json:"-"`
+	PublicKey  string    `json:"public_key" db:"public_key"`
+	PrivateKey string    `json:"private_key" db:"private_key"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// PublicKey открытый ключ пользователя, которому открывают запись.
+type PublicKey struct {
+	UserID    int    `json:"-"`
+	Login     string `json:"login"`
+	PublicKey string `json:"public_key" db:"public_key"`
+}
+
+// Share запись, открытая владельцем другому пользователю. Data - запись, зашифрованная ключом записи;
+// ItemKey - ключ записи, зашифрованный открытым ключом получателя, OwnerKey - открытым ключом владельца.
+// Owner и Recipient - логины владельца и получателя.
+type Share struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"-"`
+	Owner       string    `json:"owner"`
+	RecipientID int       `json:"-" db:"recipient_id"`
+	Recipient   string    `json:"recipient"`
+	ItemType    string    `json:"item_type" db:"item_type"`
+	ItemID      int       `json:"item_id" db:"item_id"`
+	Access      string    `json:"access"`
+	ItemKey     string    `json:"item_key" db:"item_key"`
+	OwnerKey    string    `json:"owner_key" db:"owner_key"`
+	Data        string    `json:"data"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+var (
+	ErrUserKeysPublicEmpty  = newFieldError("public_key", FieldCodeRequired, "public key empty")
+	ErrUserKeysPrivateEmpty = newFieldError("private_key", FieldCodeRequired, "private key empty")
+	ErrUserKeysUserIDEmpty  = errors.New("user id empty")
+
+	ErrShareRecipientEmpty   = newFieldError("recipient", FieldCodeRequired, "recipient empty")
+	ErrShareRecipientInvalid = newFieldError("recipient", FieldCodeInvalid, "recipient not found or has no keys")
+	ErrShareItemTypeInvalid  = newFieldError("item_type", FieldCodeInvalid, "item type unknown")
+	ErrShareItemInvalid      = newFieldError("item_id", FieldCodeInvalid, "item not found")
+	ErrShareAccessInvalid    = newFieldError("access", FieldCodeInvalid, "access must be read or write")
+	ErrShareItemKeyEmpty     = newFieldError("item_key", FieldCodeRequired, "item key empty")
+	ErrShareOwnerKeyEmpty    = newFieldError("owner_key", FieldCodeRequired, "owner key empty")
+	ErrShareDataEmpty        = newFieldError("data", FieldCodeRequired, "data empty")
+	ErrShareUserIDEmpty      = errors.New("user id empty")
+)
+
+func (k *UserKeys) Validate() error {
+	if strings.TrimSpace(k.PublicKey) == "" {
+		return ErrUserKeysPublicEmpty
+	}
+
+	if strings.TrimSpace(k.PrivateKey) == "" {
+		return ErrUserKeysPrivateEmpty
+	}
+
+	if k.UserID == 0 {
+		return ErrUserKeysUserIDEmpty
+	}
+
+	return nil
+}
+
+func (s *Share) Validate() error {
+	if strings.TrimSpace(s.Recipient) == "" {
+		return ErrShareRecipientEmpty
+	}
+
+	if !IsItemType(s.ItemType) {
+		return ErrShareItemTypeInvalid
+	}
+
+	if s.ItemID <= 0 {
+		return ErrShareItemInvalid
+	}
+
+	if s.Access != ShareAccessRead && s.Access != ShareAccessWrite {
+		return ErrShareAccessInvalid
+	}
+
+	if strings.TrimSpace(s.ItemKey) == "" {
+		return ErrShareItemKeyEmpty
+	}
+
+	if strings.TrimSpace(s.OwnerKey) == "" {
+		return ErrShareOwnerKeyEmpty
+	}
+
+	if strings.TrimSpace(s.Data) == "" {
+		return ErrShareDataEmpty
+	}
+
+	if s.UserID == 0 {
+		return ErrShareUserIDEmpty
+	}
+
+	return nil
+}
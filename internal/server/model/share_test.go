@@ -0,0 +1,66 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserKeys_Validate(t *testing.T) {
+	valid := UserKeys{UserID: 1, PublicKey: "public", PrivateKey: "encrypted"}
+
+	tests := []struct {
+		name    string
+		modify  func(k *UserKeys)
+		wantErr error
+	}{
+		{name: "valid", modify: func(k *UserKeys) {}},
+		{name: "empty public key", modify: func(k *UserKeys) { k.PublicKey = "" }, wantErr: ErrUserKeysPublicEmpty},
+		{name: "empty private key", modify: func(k *UserKeys) { k.PrivateKey = " " }, wantErr: ErrUserKeysPrivateEmpty},
+		{name: "empty user", modify: func(k *UserKeys) { k.UserID = 0 }, wantErr: ErrUserKeysUserIDEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := valid
+			tt.modify(&k)
+			assert.Equal(t, tt.wantErr, k.Validate())
+		})
+	}
+}
+
+func TestShare_Validate(t *testing.T) {
+	valid := Share{
+		UserID:    1,
+		Recipient: "colleague",
+		ItemType:  ItemTypeCred,
+		ItemID:    1,
+		Access:    ShareAccessRead,
+		ItemKey:   "sealed",
+		OwnerKey:  "sealed",
+		Data:      "encrypted",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(s *Share)
+		wantErr error
+	}{
+		{name: "valid", modify: func(s *Share) {}},
+		{name: "write access", modify: func(s *Share) { s.Access = ShareAccessWrite }},
+		{name: "empty recipient", modify: func(s *Share) { s.Recipient = " " }, wantErr: ErrShareRecipientEmpty},
+		{name: "unknown item type", modify: func(s *Share) { s.ItemType = "note" }, wantErr: ErrShareItemTypeInvalid},
+		{name: "empty item", modify: func(s *Share) { s.ItemID = 0 }, wantErr: ErrShareItemInvalid},
+		{name: "unknown access", modify: func(s *Share) { s.Access = "admin" }, wantErr: ErrShareAccessInvalid},
+		{name: "empty item key", modify: func(s *Share) { s.ItemKey = "" }, wantErr: ErrShareItemKeyEmpty},
+		{name: "empty owner key", modify: func(s *Share) { s.OwnerKey = "" }, wantErr: ErrShareOwnerKeyEmpty},
+		{name: "empty data", modify: func(s *Share) { s.Data = "" }, wantErr: ErrShareDataEmpty},
+		{name: "empty user", modify: func(s *Share) { s.UserID = 0 }, wantErr: ErrShareUserIDEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid
+			tt.modify(&s)
+			assert.Equal(t, tt.wantErr, s.Validate())
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Template пользовательский шаблон записи: Schema описывает поля и правила их проверки.
+// Name и Schema шифруются на клиенте, поэтому сервер проверяет только их наличие.
+type Template struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-"`
+	Name      string    `json:"name"`
+	Schema    string    `json:"schema"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+var (
+	ErrTemplateNameEmpty   = newFieldError("name", FieldCodeRequired, "name empty")
+	ErrTemplateSchemaEmpty = newFieldError("schema", FieldCodeRequired, "schema empty")
+	ErrTemplateUserIDEmpty = errors.New("user id empty")
+)
+
+func (t *Template) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return ErrTemplateNameEmpty
+	}
+
+	if strings.TrimSpace(t.Schema) == "" {
+		return ErrTemplateSchemaEmpty
+	}
+
+	if t.UserID == 0 {
+		return ErrTemplateUserIDEmpty
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultUploadChunkSize размер части загрузки по умолчанию - 8 МиБ.
+const DefaultUploadChunkSize = 8 << 20
+
+// UploadTTL срок жизни незавершенной загрузки, продлевается с каждой принятой частью.
+const UploadTTL = 24 * time.Hour
+
+// Upload сессия возобновляемой загрузки файла. Клиент отправляет части по ChunkSize байт
+// начиная с Offset, затем завершает загрузку, передав SHA-256 всего содержимого.
+// Завершенная загрузка хранит проверенный хеш SHA256 до истечения срока: пока она есть,
+// пользователь может сохранить файл с этим содержимым, передав только хеш.
+type Upload struct {
+	ID        string    `json:"id"`
+	UserID    int       `json:"-" db:"user_id"`
+	Size      int64     `json:"size"`
+	ChunkSize int64     `json:"chunk_size" db:"chunk_size"`
+	Offset    int64     `json:"offset" db:"upload_offset"`
+	SHA256    string    `json:"sha256,omitempty" db:"sha256"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+var (
+	ErrUploadSizeInvalid   = newFieldError("size", FieldCodeInvalid, "size must not be negative")
+	ErrUploadChunkInvalid  = newFieldError("chunk", FieldCodeInvalid, "chunk size does not match upload")
+	ErrUploadOffsetInvalid = newFieldError("offset", FieldCodeInvalid, "offset must not be negative")
+	ErrUploadHashInvalid   = newFieldError("sha256", FieldCodeInvalid, "sha256 must be 64 lowercase hex characters")
+	ErrUploadUserIDEmpty   = errors.New("user id empty")
+)
+
+// Validate проверяет сессию загрузки перед созданием.
+func (u *Upload) Validate() error {
+	if u.Size < 0 {
+		return ErrUploadSizeInvalid
+	}
+
+	if u.UserID == 0 {
+		return ErrUploadUserIDEmpty
+	}
+
+	return nil
+}
+
+// NextChunk размер части, которую сервер ожидает по текущему смещению.
+func (u *Upload) NextChunk() int64 {
+	if rest := u.Size - u.Offset; rest < u.ChunkSize {
+		return rest
+	}
+
+	return u.ChunkSize
+}
+
+// Parts число уже принятых частей.
+func (u *Upload) Parts() int {
+	if u.ChunkSize <= 0 {
+		return 0
+	}
+
+	return int((u.Offset + u.ChunkSize - 1) / u.ChunkSize)
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpload_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		upload  Upload
+		wantErr error
+	}{
+		{name: "ok", upload: Upload{UserID: 1, Size: 10}},
+		{name: "empty file", upload: Upload{UserID: 1}},
+		{name: "negative size", upload: Upload{UserID: 1, Size: -1}, wantErr: ErrUploadSizeInvalid},
+		{name: "user empty", upload: Upload{Size: 10}, wantErr: ErrUploadUserIDEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.upload.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestUpload_NextChunk(t *testing.T) {
+	tests := []struct {
+		name      string
+		upload    Upload
+		wantChunk int64
+		wantParts int
+	}{
+		{name: "first", upload: Upload{Size: 10, ChunkSize: 4}, wantChunk: 4},
+		{name: "middle", upload: Upload{Size: 10, ChunkSize: 4, Offset: 4}, wantChunk: 4, wantParts: 1},
+		{name: "last", upload: Upload{Size: 10, ChunkSize: 4, Offset: 8}, wantChunk: 2, wantParts: 2},
+		{name: "done", upload: Upload{Size: 10, ChunkSize: 4, Offset: 10}, wantChunk: 0, wantParts: 3},
+		{name: "empty file", upload: Upload{ChunkSize: 4}, wantChunk: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantChunk, tt.upload.NextChunk())
+			assert.Equal(t, tt.wantParts, tt.upload.Parts())
+		})
+	}
+}
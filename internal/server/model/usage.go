@@ -0,0 +1,98 @@
+package model
+
+import "database/sql/driver"
+
+// Usage использование хранилища пользователем и его квоты, нулевая квота - без ограничения.
+// Bytes - объем данных всех записей (PayloadSize) и содержимого файлов и вложений; Items - число всех строк
+// пользователя: записей, вложений, папок, меток, шаблонов, доступов, секретов и записей коллекций.
+type Usage struct {
+	Bytes       int64 `json:"bytes"`
+	Items       int   `json:"items"`
+	MaxBytes    int64 `json:"max_bytes"`
+	MaxFileSize int64 `json:"max_file_size"`
+	MaxItems    int   `json:"max_items"`
+}
+
+// Размер данных для квоты объема: текстовые поля (зашифрованные клиентом данные, названия) и JSON-поля
+// в том виде, в котором они хранятся в БД. Служебные поля (идентификаторы, путь и хеш содержимого, время)
+// не учитываются, содержимое файлов и вложений учитывается отдельно по Size. Столбцы хранилища,
+// по которым считается Usage, совпадают с этими полями.
+
+func (d *DataCard) PayloadSize() int64 {
+	return textSize(d.Title, d.Number, d.Date, d.Cvv, d.Meta) + jsonSize(d.Fields)
+}
+
+func (d *DataCred) PayloadSize() int64 {
+	return textSize(d.Title, d.Username, d.Password, d.Meta, d.TOTP) + jsonSize(d.Fields) + jsonSize(d.URLs)
+}
+
+func (d *DataText) PayloadSize() int64 {
+	return textSize(d.Title, d.Text, d.Meta) + jsonSize(d.Fields)
+}
+
+func (d *DataFile) PayloadSize() int64 {
+	return textSize(d.Title, d.Filename, d.Meta) + jsonSize(d.Fields)
+}
+
+func (d *DataSSHKey) PayloadSize() int64 {
+	return textSize(d.Title, d.PrivateKey, d.PublicKey, d.Fingerprint, d.Passphrase, d.Meta) + jsonSize(d.Fields)
+}
+
+func (d *DataIdentity) PayloadSize() int64 {
+	return textSize(d.Title, d.Kind, d.FullName, d.Number, d.Country, d.IssueDate, d.ExpiryDate, d.Address, d.Meta) +
+		jsonSize(d.Fields)
+}
+
+func (d *DataCustom) PayloadSize() int64 {
+	return textSize(d.Title, d.Values, d.Meta) + jsonSize(d.Fields)
+}
+
+func (a *Attachment) PayloadSize() int64 {
+	return textSize(a.Filename)
+}
+
+func (f *Folder) PayloadSize() int64 {
+	return textSize(f.Name)
+}
+
+func (t *Tag) PayloadSize() int64 {
+	return textSize(t.Name)
+}
+
+func (t *Template) PayloadSize() int64 {
+	return textSize(t.Name, t.Schema)
+}
+
+func (s *Share) PayloadSize() int64 {
+	return textSize(s.ItemKey, s.OwnerKey, s.Data)
+}
+
+func (s *Secret) PayloadSize() int64 {
+	return textSize(s.Data)
+}
+
+func (c *CollectionItem) PayloadSize() int64 {
+	return textSize(c.Data)
+}
+
+// textSize суммарный размер строк в байтах.
+func textSize(values ...string) int64 {
+	var size int64
+	for _, v := range values {
+		size += int64(len(v))
+	}
+
+	return size
+}
+
+// jsonSize размер JSON-столбца в байтах, как его записывает v.Value.
+func jsonSize(v driver.Valuer) int64 {
+	value, err := v.Value()
+	if err != nil {
+		return 0
+	}
+
+	s, _ := value.(string)
+
+	return int64(len(s))
+}
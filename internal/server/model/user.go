@@ -1,9 +1,6 @@
 package model
 
-import (
-	"errors"
-	"strings"
-)
+import "strings"
 
 type User struct {
 	ID       int    `json:"-"`
@@ -12,8 +9,8 @@ type User struct {
 }
 
 var (
-	ErrUserLoginEmpty    = errors.New("login empty")
-	ErrUserPasswordEmpty = errors.New("password empty")
+	ErrUserLoginEmpty    = newFieldError("login", FieldCodeRequired, "login empty")
+	ErrUserPasswordEmpty = newFieldError("password", FieldCodeRequired, "password empty")
 )
 
 func (u *User) Validate() error {
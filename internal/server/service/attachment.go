@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+)
+
+func (s *Service) SaveAttachment(ctx context.Context, att model.Attachment) (id int, err error) {
+	err = att.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveAttachment: %w", err)
+	}
+
+	err = s.findItem(ctx, att.UserID, att.ItemType, att.ItemID)
+	if errors.Is(err, storage.ErrorNotFound) {
+		err = model.ErrAttachmentItemInvalid
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("service.SaveAttachment: %w", err)
+	}
+
+	err = s.checkFileSize(att.Size)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveAttachment: %w", err)
+	}
+
+	err = s.checkQuota(ctx, att.UserID, 1, att.Size+att.PayloadSize())
+	if err != nil {
+		return id, fmt.Errorf("service.SaveAttachment: %w", err)
+	}
+
+	err = s.referenceFile(ctx, att.Path, func() (err error) {
+		id, err = s.Store.SaveAttachment(ctx, att)
+		return err
+	})
+	if err != nil {
+		return id, fmt.Errorf("service.SaveAttachment: %w", err)
+	}
+
+	return id, nil
+}
+
+// findItem проверяет, что запись типа itemType принадлежит пользователю; иначе возвращает storage.ErrorNotFound.
+func (s *Service) findItem(ctx context.Context, userID int, itemType string, itemID int) (err error) {
+	switch itemType {
+	case model.ItemTypeCard:
+		_, err = s.Store.FindCard(ctx, itemID, userID)
+	case model.ItemTypeCred:
+		_, err = s.Store.FindCred(ctx, itemID, userID)
+	case model.ItemTypeText:
+		_, err = s.Store.FindText(ctx, itemID, userID)
+	case model.ItemTypeFile:
+		_, err = s.Store.FindFile(ctx, itemID, userID)
+	case model.ItemTypeSSH:
+		_, err = s.Store.FindSSHKey(ctx, itemID, userID)
+	case model.ItemTypeIdentity:
+		_, err = s.Store.FindIdentity(ctx, itemID, userID)
+	case model.ItemTypeCustom:
+		_, err = s.Store.FindCustomItem(ctx, itemID, userID)
+	default:
+		return storage.ErrorNotFound
+	}
+
+	return err
+}
+
+func (s *Service) DeleteAttachment(ctx context.Context, attID, userID int) (err error) {
+	att, err := s.Store.FindAttachment(ctx, attID, userID)
+	if err != nil {
+		return fmt.Errorf("service.DeleteAttachment: %w", err)
+	}
+
+	err = s.Store.DeleteAttachment(ctx, attID, userID)
+	if err != nil {
+		return fmt.Errorf("service.DeleteAttachment: %w", err)
+	}
+
+	return s.ReleaseFile(ctx, att.Path)
+}
+
+func (s *Service) FindAllAttachments(ctx context.Context, userID int, filter model.AttachmentFilter) (atts []model.Attachment, err error) {
+	return s.Store.FindAllAttachments(ctx, userID, filter)
+}
+
+// deleteItem удаляет запись функцией del; вложения записи удаляются хранилищем вместе с ней,
+// их файлы - из файлового хранилища после успешного удаления.
+func (s *Service) deleteItem(ctx context.Context, itemType string, itemID, userID int, del func(ctx context.Context, itemID, userID int) error) error {
+	atts, err := s.Store.FindAllAttachments(ctx, userID, model.AttachmentFilter{ItemType: itemType, ItemID: itemID})
+	if err != nil {
+		return err
+	}
+
+	err = del(ctx, itemID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteAttachmentFiles(ctx, atts)
+}
+
+// deleteAttachmentFiles освобождает файлы вложений; ошибка удаления одного файла не останавливает удаление остальных.
+func (s *Service) deleteAttachmentFiles(ctx context.Context, atts []model.Attachment) (err error) {
+	for _, v := range atts {
+		if errDel := s.ReleaseFile(ctx, v.Path); errDel != nil {
+			err = fmt.Errorf("service.deleteAttachmentFiles: %w", errDel)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SaveAttachment(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+	s := New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	p, err := storeFiles.SaveFile(ctx, strings.NewReader("encrypted"), -1)
+	require.NoError(t, err)
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+	otherID, err := store.CreateUser(ctx, model.User{Login: "other", Password: "password"})
+	require.NoError(t, err)
+
+	cardID, err := s.SaveCard(ctx, model.DataCard{UserID: userID, Title: "card", Number: "1234", Date: "12/30", Cvv: "123"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		att     model.Attachment
+		wantErr error
+	}{
+		{name: "own item", att: model.Attachment{UserID: userID, ItemType: model.ItemTypeCard, ItemID: cardID, Filename: "f", Path: p}},
+		{name: "foreign item", att: model.Attachment{UserID: otherID, ItemType: model.ItemTypeCard, ItemID: cardID, Filename: "f", Path: p}, wantErr: model.ErrAttachmentItemInvalid},
+		{name: "other item type", att: model.Attachment{UserID: userID, ItemType: model.ItemTypeCred, ItemID: cardID, Filename: "f", Path: p}, wantErr: model.ErrAttachmentItemInvalid},
+		{name: "unknown item type", att: model.Attachment{UserID: userID, ItemType: "note", ItemID: cardID, Filename: "f", Path: p}, wantErr: model.ErrAttachmentItemTypeInvalid},
+		{name: "missing content", att: model.Attachment{UserID: userID, ItemType: model.ItemTypeCard, ItemID: cardID, Filename: "f", Path: file.URLPrefix + "/missing"}, wantErr: file.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.SaveAttachment(ctx, tt.att)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_DeleteItemAttachments(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+	s := New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	attach := func(itemType string, itemID int) string {
+		path, err := storeFiles.SaveFile(ctx, strings.NewReader("encrypted"), -1)
+		require.NoError(t, err)
+		_, err = s.SaveAttachment(ctx, model.Attachment{UserID: userID, ItemType: itemType, ItemID: itemID, Filename: "f", Path: path})
+		require.NoError(t, err)
+
+		return filepath.Join(storeFiles.Path(), filepath.FromSlash(strings.TrimPrefix(path, file.URLPrefix+"/")))
+	}
+
+	// файл вложения удаляется вместе с записью
+	cardID, err := s.SaveCard(ctx, model.DataCard{UserID: userID, Title: "card", Number: "1234", Date: "12/30", Cvv: "123"})
+	require.NoError(t, err)
+	cardFile := attach(model.ItemTypeCard, cardID)
+	require.FileExists(t, cardFile)
+
+	require.NoError(t, s.DeleteCard(ctx, cardID, userID))
+	assert.NoFileExists(t, cardFile)
+
+	// и вместе с шаблоном записи
+	tplID, err := s.SaveTemplate(ctx, model.Template{UserID: userID, Name: "db", Schema: "schema"})
+	require.NoError(t, err)
+	itemID, err := s.SaveCustomItem(ctx, model.DataCustom{UserID: userID, TemplateID: tplID, Title: "db", Values: "v"})
+	require.NoError(t, err)
+	customFile := attach(model.ItemTypeCustom, itemID)
+
+	require.NoError(t, s.DeleteTemplate(ctx, tplID, userID))
+	assert.NoFileExists(t, customFile)
+
+	atts, err := s.FindAllAttachments(ctx, userID, model.AttachmentFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, atts)
+}
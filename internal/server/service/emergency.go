@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+)
+
+// SaveEmergencyAccess назначает доверенное лицо по логину или меняет период ожидания и ключ хранилища.
+// У доверенного лица должны быть ключи; назначить доверенным лицом себя нельзя.
+func (s *Service) SaveEmergencyAccess(ctx context.Context, access model.EmergencyAccess) (id int, err error) {
+	err = access.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveEmergencyAccess: %w", err)
+	}
+
+	pub, err := s.Store.FindPublicKey(ctx, access.Grantee)
+	if errors.Is(err, storage.ErrorNotFound) || (err == nil && pub.UserID == access.UserID) {
+		err = model.ErrEmergencyGranteeInvalid
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("service.SaveEmergencyAccess: %w", err)
+	}
+
+	access.GranteeID = pub.UserID
+	access.Status = model.EmergencyStatusIdle
+	access.UpdatedAt = time.Now()
+
+	return s.Store.SaveEmergencyAccess(ctx, access)
+}
+
+func (s *Service) DeleteEmergencyAccess(ctx context.Context, accessID, userID int) error {
+	return s.Store.DeleteEmergencyAccess(ctx, accessID, userID)
+}
+
+// FindAllEmergencyAccess доверенные лица пользователя с текущим состоянием доступа.
+func (s *Service) FindAllEmergencyAccess(ctx context.Context, userID int) (list []model.EmergencyAccess, err error) {
+	list, err = s.Store.FindAllEmergencyAccess(ctx, userID)
+	if err != nil {
+		return list, fmt.Errorf("service.FindAllEmergencyAccess: %w", err)
+	}
+
+	now := time.Now()
+	for i := range list {
+		list[i].Resolve(now)
+	}
+
+	return list, nil
+}
+
+// FindAllIncomingEmergencyAccess пользователи, назначившие пользователя доверенным лицом. Ключ хранилища
+// выдается только по открытому доступу.
+func (s *Service) FindAllIncomingEmergencyAccess(ctx context.Context, granteeID int) (list []model.EmergencyAccess, err error) {
+	list, err = s.Store.FindAllIncomingEmergencyAccess(ctx, granteeID)
+	if err != nil {
+		return list, fmt.Errorf("service.FindAllIncomingEmergencyAccess: %w", err)
+	}
+
+	now := time.Now()
+	for i := range list {
+		list[i].Resolve(now)
+		if list[i].Status != model.EmergencyStatusGranted {
+			list[i].Key = ""
+		}
+	}
+
+	return list, nil
+}
+
+// findEmergencyAccess экстренный доступ с текущим состоянием; для постороннего пользователя ErrorNotFound.
+func (s *Service) findEmergencyAccess(ctx context.Context, accessID, userID int, grantee bool) (access model.EmergencyAccess, err error) {
+	access, err = s.Store.FindEmergencyAccess(ctx, accessID)
+	if err != nil {
+		return access, err
+	}
+
+	if (grantee && access.GranteeID != userID) || (!grantee && access.UserID != userID) {
+		return access, storage.ErrorNotFound
+	}
+
+	access.Resolve(time.Now())
+
+	return access, nil
+}
+
+// RequestEmergencyAccess запрос доверенного лица на доступ к хранилищу; отсчет периода ожидания
+// начинается с запроса. Повторный запрос, пока идет ожидание или доступ открыт, ничего не меняет.
+func (s *Service) RequestEmergencyAccess(ctx context.Context, accessID, granteeID int) error {
+	access, err := s.findEmergencyAccess(ctx, accessID, granteeID, true)
+	if err != nil {
+		return fmt.Errorf("service.RequestEmergencyAccess: %w", err)
+	}
+
+	if access.Status == model.EmergencyStatusRequested || access.Status == model.EmergencyStatusGranted {
+		return nil
+	}
+
+	access.Status = model.EmergencyStatusRequested
+	access.RequestedAt = time.Now()
+	access.UpdatedAt = access.RequestedAt
+
+	return s.Store.UpdateEmergencyStatus(ctx, access)
+}
+
+// RejectEmergencyAccess отклонение владельцем запроса доступа; закрывает и уже открытый доступ.
+func (s *Service) RejectEmergencyAccess(ctx context.Context, accessID, userID int) error {
+	access, err := s.findEmergencyAccess(ctx, accessID, userID, false)
+	if err != nil {
+		return fmt.Errorf("service.RejectEmergencyAccess: %w", err)
+	}
+
+	if access.Status == model.EmergencyStatusIdle || access.Status == model.EmergencyStatusRejected {
+		return nil
+	}
+
+	access.Status = model.EmergencyStatusRejected
+	access.UpdatedAt = time.Now()
+
+	return s.Store.UpdateEmergencyStatus(ctx, access)
+}
+
+// FindEmergencyVault записи владельца для доверенного лица по открытому доступу, иначе ErrAccessDenied.
+func (s *Service) FindEmergencyVault(ctx context.Context, accessID, granteeID int) (vault model.EmergencyVault, err error) {
+	access, err := s.findEmergencyAccess(ctx, accessID, granteeID, true)
+	if err != nil {
+		return vault, fmt.Errorf("service.FindEmergencyVault: %w", err)
+	}
+
+	if access.Status != model.EmergencyStatusGranted {
+		return vault, fmt.Errorf("service.FindEmergencyVault: %w", ErrAccessDenied)
+	}
+
+	ownerID := access.UserID
+	vault.Access = access
+
+	if vault.Cards, err = s.Store.FindAllCards(ctx, ownerID, model.ItemFilter{}); err != nil {
+		return vault, fmt.Errorf("service.FindEmergencyVault: %w", err)
+	}
+
+	if vault.Creds, err = s.Store.FindAllCreds(ctx, ownerID, model.ItemFilter{}); err != nil {
+		return vault, fmt.Errorf("service.FindEmergencyVault: %w", err)
+	}
+
+	if vault.Texts, err = s.Store.FindAllTexts(ctx, ownerID, model.ItemFilter{}); err != nil {
+		return vault, fmt.Errorf("service.FindEmergencyVault: %w", err)
+	}
+
+	if vault.Files, err = s.Store.FindAllFiles(ctx, ownerID, model.ItemFilter{}); err != nil {
+		return vault, fmt.Errorf("service.FindEmergencyVault: %w", err)
+	}
+
+	if vault.SSHKeys, err = s.Store.FindAllSSHKeys(ctx, ownerID, model.ItemFilter{}); err != nil {
+		return vault, fmt.Errorf("service.FindEmergencyVault: %w", err)
+	}
+
+	if vault.Identities, err = s.Store.FindAllIdentities(ctx, ownerID, model.ItemFilter{}); err != nil {
+		return vault, fmt.Errorf("service.FindEmergencyVault: %w", err)
+	}
+
+	if vault.CustomItems, err = s.Store.FindAllCustomItems(ctx, ownerID, model.ItemFilter{}); err != nil {
+		return vault, fmt.Errorf("service.FindEmergencyVault: %w", err)
+	}
+
+	// шаблоны нужны, чтобы разобрать значения записей по шаблонам
+	if vault.Templates, err = s.Store.FindAllTemplates(ctx, ownerID); err != nil {
+		return vault, fmt.Errorf("service.FindEmergencyVault: %w", err)
+	}
+
+	if vault.Attachments, err = s.Store.FindAllAttachments(ctx, ownerID, model.AttachmentFilter{}); err != nil {
+		return vault, fmt.Errorf("service.FindEmergencyVault: %w", err)
+	}
+
+	return vault, nil
+}
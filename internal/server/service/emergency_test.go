@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SaveEmergencyAccess(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := New(store, nil, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	ownerID, err := store.CreateUser(ctx, model.User{Login: "owner", Password: "password"})
+	require.NoError(t, err)
+	contactID, err := store.CreateUser(ctx, model.User{Login: "contact", Password: "password"})
+	require.NoError(t, err)
+	_, err = store.CreateUser(ctx, model.User{Login: "nokeys", Password: "password"})
+	require.NoError(t, err)
+
+	for _, id := range []int{ownerID, contactID} {
+		require.NoError(t, s.SaveUserKeys(ctx, model.UserKeys{UserID: id, PublicKey: "public", PrivateKey: "private"}))
+	}
+
+	tests := []struct {
+		name    string
+		grantee string
+		wantErr error
+	}{
+		{name: "ok", grantee: "contact"},
+		{name: "duplicate", grantee: "contact", wantErr: storage.ErrorRowAlreadyExists},
+		{name: "unknown", grantee: "unknown", wantErr: model.ErrEmergencyGranteeInvalid},
+		{name: "without keys", grantee: "nokeys", wantErr: model.ErrEmergencyGranteeInvalid},
+		{name: "self", grantee: "owner", wantErr: model.ErrEmergencyGranteeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.SaveEmergencyAccess(ctx, model.EmergencyAccess{UserID: ownerID, Grantee: tt.grantee, WaitHours: 24, Key: "key"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_EmergencyAccessFlow(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := New(store, nil, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	ownerID, err := store.CreateUser(ctx, model.User{Login: "owner", Password: "password"})
+	require.NoError(t, err)
+	contactID, err := store.CreateUser(ctx, model.User{Login: "contact", Password: "password"})
+	require.NoError(t, err)
+	require.NoError(t, s.SaveUserKeys(ctx, model.UserKeys{UserID: contactID, PublicKey: "public", PrivateKey: "private"}))
+
+	_, err = s.SaveCred(ctx, model.DataCred{UserID: ownerID, Title: "cred", Username: "user", Password: "pass"})
+	require.NoError(t, err)
+
+	id, err := s.SaveEmergencyAccess(ctx, model.EmergencyAccess{UserID: ownerID, Grantee: "contact", WaitHours: 24, Key: "key"})
+	require.NoError(t, err)
+
+	incoming := func() model.EmergencyAccess {
+		list, err := s.FindAllIncomingEmergencyAccess(ctx, contactID)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+
+		return list[0]
+	}
+
+	assert.Equal(t, model.EmergencyStatusIdle, incoming().Status)
+	assert.Empty(t, incoming().Key)
+
+	_, err = s.FindEmergencyVault(ctx, id, contactID)
+	assert.ErrorIs(t, err, ErrAccessDenied)
+	assert.ErrorIs(t, s.RequestEmergencyAccess(ctx, id, ownerID), storage.ErrorNotFound)
+
+	// пока идет ожидание, ключ и записи недоступны
+	require.NoError(t, s.RequestEmergencyAccess(ctx, id, contactID))
+	assert.Equal(t, model.EmergencyStatusRequested, incoming().Status)
+	assert.Empty(t, incoming().Key)
+	_, err = s.FindEmergencyVault(ctx, id, contactID)
+	assert.ErrorIs(t, err, ErrAccessDenied)
+
+	// владелец отклоняет запрос
+	assert.ErrorIs(t, s.RejectEmergencyAccess(ctx, id, contactID), storage.ErrorNotFound)
+	require.NoError(t, s.RejectEmergencyAccess(ctx, id, ownerID))
+	assert.Equal(t, model.EmergencyStatusRejected, incoming().Status)
+
+	// повторный запрос; период ожидания истек
+	require.NoError(t, s.RequestEmergencyAccess(ctx, id, contactID))
+	access, err := store.FindEmergencyAccess(ctx, id)
+	require.NoError(t, err)
+	access.RequestedAt = time.Now().Add(-25 * time.Hour)
+	require.NoError(t, store.UpdateEmergencyStatus(ctx, access))
+
+	assert.Equal(t, model.EmergencyStatusGranted, incoming().Status)
+	assert.Equal(t, "key", incoming().Key)
+
+	own, err := s.FindAllEmergencyAccess(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, own, 1)
+	assert.Equal(t, model.EmergencyStatusGranted, own[0].Status)
+
+	vault, err := s.FindEmergencyVault(ctx, id, contactID)
+	require.NoError(t, err)
+	require.Len(t, vault.Creds, 1)
+	assert.Equal(t, "cred", vault.Creds[0].Title)
+	assert.Equal(t, "owner", vault.Access.Grantor)
+}
+
+func TestService_FindEmergencyVault(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := New(store, nil, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	ownerID, err := store.CreateUser(ctx, model.User{Login: "owner", Password: "password"})
+	require.NoError(t, err)
+	contactID, err := store.CreateUser(ctx, model.User{Login: "contact", Password: "password"})
+	require.NoError(t, err)
+	require.NoError(t, s.SaveUserKeys(ctx, model.UserKeys{UserID: contactID, PublicKey: "public", PrivateKey: "private"}))
+
+	_, err = store.SaveCard(ctx, model.DataCard{UserID: ownerID, Title: "card"})
+	require.NoError(t, err)
+	credID, err := store.SaveCred(ctx, model.DataCred{UserID: ownerID, Title: "cred"})
+	require.NoError(t, err)
+	_, err = store.SaveText(ctx, model.DataText{UserID: ownerID, Title: "text"})
+	require.NoError(t, err)
+	_, err = store.SaveFile(ctx, model.DataFile{UserID: ownerID, Title: "file", Filename: "file.txt"})
+	require.NoError(t, err)
+	_, err = store.SaveSSHKey(ctx, model.DataSSHKey{UserID: ownerID, Title: "ssh"})
+	require.NoError(t, err)
+	_, err = store.SaveIdentity(ctx, model.DataIdentity{UserID: ownerID, Title: "identity"})
+	require.NoError(t, err)
+	tplID, err := store.SaveTemplate(ctx, model.Template{UserID: ownerID, Name: "template", Schema: "schema"})
+	require.NoError(t, err)
+	_, err = store.SaveCustomItem(ctx, model.DataCustom{UserID: ownerID, TemplateID: tplID, Title: "custom"})
+	require.NoError(t, err)
+	_, err = store.SaveAttachment(ctx, model.Attachment{UserID: ownerID, ItemType: model.ItemTypeCred, ItemID: credID, Filename: "attachment.txt"})
+	require.NoError(t, err)
+
+	// записи другого пользователя в хранилище владельца не попадают
+	_, err = store.SaveCred(ctx, model.DataCred{UserID: contactID, Title: "contact cred"})
+	require.NoError(t, err)
+
+	id, err := s.SaveEmergencyAccess(ctx, model.EmergencyAccess{UserID: ownerID, Grantee: "contact", WaitHours: 0, Key: "key"})
+	require.NoError(t, err)
+	require.NoError(t, s.RequestEmergencyAccess(ctx, id, contactID))
+
+	vault, err := s.FindEmergencyVault(ctx, id, contactID)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		items func() []string
+		want  string
+	}{
+		{name: "cards", items: func() (v []string) {
+			for _, item := range vault.Cards {
+				v = append(v, item.Title)
+			}
+			return v
+		}, want: "card"},
+		{name: "creds", items: func() (v []string) {
+			for _, item := range vault.Creds {
+				v = append(v, item.Title)
+			}
+			return v
+		}, want: "cred"},
+		{name: "texts", items: func() (v []string) {
+			for _, item := range vault.Texts {
+				v = append(v, item.Title)
+			}
+			return v
+		}, want: "text"},
+		{name: "files", items: func() (v []string) {
+			for _, item := range vault.Files {
+				v = append(v, item.Title)
+			}
+			return v
+		}, want: "file"},
+		{name: "ssh keys", items: func() (v []string) {
+			for _, item := range vault.SSHKeys {
+				v = append(v, item.Title)
+			}
+			return v
+		}, want: "ssh"},
+		{name: "identities", items: func() (v []string) {
+			for _, item := range vault.Identities {
+				v = append(v, item.Title)
+			}
+			return v
+		}, want: "identity"},
+		{name: "custom items", items: func() (v []string) {
+			for _, item := range vault.CustomItems {
+				v = append(v, item.Title)
+			}
+			return v
+		}, want: "custom"},
+		{name: "templates", items: func() (v []string) {
+			for _, item := range vault.Templates {
+				v = append(v, item.Name)
+			}
+			return v
+		}, want: "template"},
+		{name: "attachments", items: func() (v []string) {
+			for _, item := range vault.Attachments {
+				v = append(v, item.Filename)
+			}
+			return v
+		}, want: "attachment.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, []string{tt.want}, tt.items())
+		})
+	}
+}
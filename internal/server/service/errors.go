@@ -0,0 +1,26 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	ErrAccessDenied        = errors.New("access denied")
+	ErrQuotaExceeded       = errors.New("quota exceeded")
+)
+
+// Ошибки возобновляемой загрузки файла.
+var (
+	ErrUploadOffset     = errors.New("upload offset mismatch")
+	ErrUploadIncomplete = errors.New("upload is incomplete")
+	ErrUploadChecksum   = errors.New("sha256 checksum mismatch")
+)
+
+// Превышенные квоты пользователя, каждая ошибка оборачивает ErrQuotaExceeded.
+var (
+	ErrQuotaBytes    = fmt.Errorf("%w: storage size limit reached", ErrQuotaExceeded)
+	ErrQuotaFileSize = fmt.Errorf("%w: file is too large", ErrQuotaExceeded)
+	ErrQuotaItems    = fmt.Errorf("%w: items limit reached", ErrQuotaExceeded)
+)
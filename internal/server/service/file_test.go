@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ReleaseFile(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+	s := New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	save := func(content string) string {
+		path, err := storeFiles.SaveFile(ctx, strings.NewReader(content), -1)
+		require.NoError(t, err)
+
+		return path
+	}
+	exists := func(path string) bool {
+		_, err := storeFiles.StatFile(ctx, path)
+		if err != nil {
+			require.ErrorIs(t, err, file.ErrNotFound)
+		}
+
+		return err == nil
+	}
+
+	// одинаковое содержимое двух файлов хранится один раз
+	shared := save("encrypted")
+	first, err := s.SaveFile(ctx, model.DataFile{UserID: userID, Title: "first", Filename: "f", Path: shared, UpdatedAt: time.Now()})
+	require.NoError(t, err)
+	second, err := s.SaveFile(ctx, model.DataFile{UserID: userID, Title: "second", Filename: "f", Path: save("encrypted"), UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteFile(ctx, first, userID))
+	assert.True(t, exists(shared), "содержимое нужно второму файлу")
+
+	// при обновлении прежнее содержимое освобождается
+	updated := save("updated")
+	_, err = s.SaveFile(ctx, model.DataFile{ID: second, UserID: userID, Title: "second", Filename: "f", Path: updated, UpdatedAt: time.Now()})
+	require.NoError(t, err)
+	assert.False(t, exists(shared))
+	assert.True(t, exists(updated))
+
+	require.NoError(t, s.DeleteFile(ctx, second, userID))
+	assert.False(t, exists(updated))
+
+	// содержимое освобождено, пока новая запись на него ссылалась только по хешу: запись не сохраняется
+	_, err = s.SaveFile(ctx, model.DataFile{UserID: userID, Title: "late", Filename: "f", Path: updated, UpdatedAt: time.Now()})
+	assert.ErrorIs(t, err, file.ErrNotFound)
+	files, err := store.FindAllFiles(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestFileLocks(t *testing.T) {
+	var locks fileLocks
+
+	unlock := locks.lock("_file_storage/sha256/aa/bb/hash")
+
+	locked := make(chan struct{})
+	go func() {
+		// тот же путь в другом виде ждет снятия первой блокировки
+		locks.lock("sha256/aa/bb/hash")()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("lock acquired twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// другое содержимое не блокируется
+	locks.lock("_file_storage/sha256/cc/dd/other")()
+
+	unlock()
+	<-locked
+
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	assert.Empty(t, locks.locks)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+)
+
+// fileLocks блокировки содержимого по пути. Подсчет ссылок с удалением содержимого и добавление новой ссылки
+// на него выполняются по очереди: иначе общее содержимое, найденное по хешу, можно удалить между проверкой
+// его наличия и сохранением записи, которая на него ссылается. Блокировки действуют в пределах процесса,
+// между процессами содержимое блокирует хранилище, см. lockFile.
+type fileLocks struct {
+	mu    sync.Mutex
+	locks map[string]*fileLock
+}
+
+type fileLock struct {
+	mu sync.Mutex
+	// waiters число владельцев и ожидающих блокировку; при нуле блокировка удаляется из карты
+	waiters int
+}
+
+// lock блокирует содержимое filePath и возвращает функцию снятия блокировки.
+func (l *fileLocks) lock(filePath string) (unlock func()) {
+	key := file.CleanPath(filePath)
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*fileLock)
+	}
+
+	fl, ok := l.locks[key]
+	if !ok {
+		fl = &fileLock{}
+		l.locks[key] = fl
+	}
+	fl.waiters++
+	l.mu.Unlock()
+
+	fl.mu.Lock()
+
+	return func() {
+		fl.mu.Unlock()
+
+		l.mu.Lock()
+		fl.waiters--
+		if fl.waiters == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// lockFile выполняет fn под блокировкой содержимого filePath: сначала в пределах процесса, затем в БД,
+// общей для реплик сервера и server gc. Блокировка процесса впереди, чтобы одно содержимое не занимало
+// несколько соединений с БД.
+func (s *Service) lockFile(ctx context.Context, filePath string, fn func() error) error {
+	unlock := s.fileLocks.lock(filePath)
+	defer unlock()
+
+	return s.Store.LockFile(ctx, file.CleanPath(filePath), fn)
+}
+
+// referenceFile сохраняет запись, ссылающуюся на содержимое filePath, под блокировкой содержимого.
+// Перед сохранением проверяется, что содержимое есть в хранилище (его могли удалить, пока оно загружалось
+// или искалось по хешу), и обновляется время его изменения для сборки мусора. Если содержимого нет,
+// возвращается file.ErrNotFound.
+func (s *Service) referenceFile(ctx context.Context, filePath string, save func() error) error {
+	if filePath == "" {
+		return save()
+	}
+
+	return s.lockFile(ctx, filePath, func() error {
+		if err := s.StoreFiles.TouchFile(ctx, filePath); err != nil {
+			return fmt.Errorf("content %s: %w", filePath, err)
+		}
+
+		return save()
+	})
+}
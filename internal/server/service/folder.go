@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+)
+
+func (s *Service) SaveFolder(ctx context.Context, folder model.Folder) (id int, err error) {
+	err = folder.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveFolder: %w", err)
+	}
+
+	if folder.ParentID != 0 {
+		folders, err := s.Store.FindAllFolders(ctx, folder.UserID)
+		if err != nil {
+			return id, fmt.Errorf("service.SaveFolder: %w", err)
+		}
+
+		if !validParent(folders, folder.ID, folder.ParentID) {
+			return id, fmt.Errorf("service.SaveFolder: %w", model.ErrFolderParentInvalid)
+		}
+	}
+
+	bytes, err := payloadGrowth(ctx, &folder, folder.ID, folder.UserID, s.findFolder)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveFolder: %w", err)
+	}
+
+	err = s.checkQuota(ctx, folder.UserID, newItems(folder.ID), bytes)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveFolder: %w", err)
+	}
+
+	return s.Store.SaveFolder(ctx, folder)
+}
+
+// findFolder папка пользователя по идентификатору; иначе storage.ErrorNotFound.
+func (s *Service) findFolder(ctx context.Context, folderID, userID int) (folder model.Folder, err error) {
+	folders, err := s.Store.FindAllFolders(ctx, userID)
+	if err != nil {
+		return folder, err
+	}
+
+	for _, v := range folders {
+		if v.ID == folderID {
+			return v, nil
+		}
+	}
+
+	return folder, storage.ErrorNotFound
+}
+
+// validParent проверяет, что родительская папка принадлежит пользователю и не вложена в саму папку folderID.
+func validParent(folders []model.Folder, folderID, parentID int) bool {
+	parents := make(map[int]int, len(folders))
+	for _, f := range folders {
+		parents[f.ID] = f.ParentID
+	}
+
+	if _, ok := parents[parentID]; !ok {
+		return false
+	}
+
+	// поднимаемся от нового родителя к корню; встреча с самой папкой означает цикл
+	for id, steps := parentID, 0; id != 0 && steps <= len(folders); id, steps = parents[id], steps+1 {
+		if id == folderID {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Service) DeleteFolder(ctx context.Context, folderID, userID int) (err error) {
+	return s.Store.DeleteFolder(ctx, folderID, userID)
+}
+
+func (s *Service) FindAllFolders(ctx context.Context, userID int) (folders []model.Folder, err error) {
+	return s.Store.FindAllFolders(ctx, userID)
+}
+
+func (s *Service) SaveTag(ctx context.Context, tag model.Tag) (id int, err error) {
+	err = tag.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveTag: %w", err)
+	}
+
+	bytes, err := payloadGrowth(ctx, &tag, tag.ID, tag.UserID, s.findTag)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveTag: %w", err)
+	}
+
+	err = s.checkQuota(ctx, tag.UserID, newItems(tag.ID), bytes)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveTag: %w", err)
+	}
+
+	return s.Store.SaveTag(ctx, tag)
+}
+
+// findTag метка пользователя по идентификатору; иначе storage.ErrorNotFound.
+func (s *Service) findTag(ctx context.Context, tagID, userID int) (tag model.Tag, err error) {
+	tags, err := s.Store.FindAllTags(ctx, userID)
+	if err != nil {
+		return tag, err
+	}
+
+	for _, v := range tags {
+		if v.ID == tagID {
+			return v, nil
+		}
+	}
+
+	return tag, storage.ErrorNotFound
+}
+
+func (s *Service) DeleteTag(ctx context.Context, tagID, userID int) (err error) {
+	return s.Store.DeleteTag(ctx, tagID, userID)
+}
+
+func (s *Service) FindAllTags(ctx context.Context, userID int) (tags []model.Tag, err error) {
+	return s.Store.FindAllTags(ctx, userID)
+}
+
+// checkItemRefs проверяет, что папка и метки записи принадлежат её владельцу.
+func (s *Service) checkItemRefs(ctx context.Context, userID, folderID int, tagIDs []int) error {
+	if folderID != 0 {
+		folders, err := s.Store.FindAllFolders(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, f := range folders {
+			if f.ID == folderID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return model.ErrItemFolderInvalid
+		}
+	}
+
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	tags, err := s.Store.FindAllTags(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	own := make(map[int]bool, len(tags))
+	for _, t := range tags {
+		own[t.ID] = true
+	}
+
+	for _, id := range tagIDs {
+		if !own[id] {
+			return model.ErrItemTagsInvalid
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validParent(t *testing.T) {
+	// 1 -> 2 -> 3, 4 в корне
+	folders := []model.Folder{{ID: 1}, {ID: 2, ParentID: 1}, {ID: 3, ParentID: 2}, {ID: 4}}
+
+	tests := []struct {
+		name     string
+		folderID int
+		parentID int
+		want     bool
+	}{
+		{name: "new folder", folderID: 0, parentID: 3, want: true},
+		{name: "move to sibling", folderID: 3, parentID: 4, want: true},
+		{name: "move up", folderID: 3, parentID: 1, want: true},
+		{name: "unknown parent", folderID: 0, parentID: 5, want: false},
+		{name: "into itself", folderID: 2, parentID: 2, want: false},
+		{name: "into descendant", folderID: 1, parentID: 3, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, validParent(folders, tt.folderID, tt.parentID))
+		})
+	}
+}
+
+func TestService_checkItemRefs(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := New(store, nil, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+	otherID, err := store.CreateUser(ctx, model.User{Login: "other", Password: "password"})
+	require.NoError(t, err)
+
+	folderID, err := store.SaveFolder(ctx, model.Folder{UserID: userID, Name: "folder"})
+	require.NoError(t, err)
+	tagID, err := store.SaveTag(ctx, model.Tag{UserID: userID, Name: "tag"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		userID   int
+		folderID int
+		tagIDs   []int
+		wantErr  error
+	}{
+		{name: "no refs", userID: userID},
+		{name: "own refs", userID: userID, folderID: folderID, tagIDs: []int{tagID}},
+		{name: "foreign folder", userID: otherID, folderID: folderID, wantErr: model.ErrItemFolderInvalid},
+		{name: "foreign tag", userID: otherID, tagIDs: []int{tagID}, wantErr: model.ErrItemTagsInvalid},
+		{name: "unknown tag", userID: userID, tagIDs: []int{tagID, tagID + 1}, wantErr: model.ErrItemTagsInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.checkItemRefs(ctx, tt.userID, tt.folderID, tt.tagIDs)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
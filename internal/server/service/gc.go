@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+)
+
+// CollectGarbage сверяет хранилище файлов с БД. Содержимое, на которое не ссылается ни файл, ни вложение,
+// ни незавершенная загрузка, попадает в отчет, если не менялось дольше grace, а при remove удаляется.
+// Время изменения содержимого обновляется при каждой новой ссылке на него, так что grace считается
+// от последнего использования, а не от первой загрузки.
+// Файлы и вложения, содержимого которых нет в хранилище, попадают в отчет как отсутствующие.
+func (s *Service) CollectGarbage(ctx context.Context, grace time.Duration, remove bool) (report model.GCReport, err error) {
+	// ссылки читаются до обхода хранилища: содержимое, сохраненное позже, моложе grace и в отчет не попадет
+	refs, err := s.Store.FindFileRefs(ctx)
+	if err != nil {
+		return report, fmt.Errorf("service.CollectGarbage: %w", err)
+	}
+
+	uploadIDs, err := s.Store.FindUploadIDs(ctx)
+	if err != nil {
+		return report, fmt.Errorf("service.CollectGarbage: %w", err)
+	}
+
+	referenced := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		referenced[file.CleanPath(ref.Path)] = false
+	}
+
+	uploads := make(map[string]bool, len(uploadIDs))
+	for _, id := range uploadIDs {
+		uploads[id] = true
+	}
+
+	deadline := time.Now().Add(-grace)
+
+	err = s.StoreFiles.WalkFiles(ctx, func(filePath string, info file.BlobInfo) error {
+		report.Blobs++
+
+		if _, ok := referenced[filePath]; ok {
+			referenced[filePath] = true
+
+			return nil
+		}
+
+		if uploads[file.UploadID(filePath)] || info.ModTime.After(deadline) {
+			return nil
+		}
+
+		report.Orphans = append(report.Orphans, model.OrphanBlob{Path: filePath, Size: info.Size, ModTime: info.ModTime})
+
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("service.CollectGarbage: %w", err)
+	}
+
+	for _, ref := range refs {
+		if !referenced[file.CleanPath(ref.Path)] {
+			report.Missing = append(report.Missing, ref)
+		}
+	}
+
+	if !remove {
+		return report, nil
+	}
+
+	for i, orphan := range report.Orphans {
+		deleted, err := s.removeOrphan(ctx, orphan.Path, deadline)
+		if err != nil {
+			return report, fmt.Errorf("service.CollectGarbage: %w", err)
+		}
+
+		if deleted {
+			report.Orphans[i].Deleted = true
+			report.Freed += orphan.Size
+		}
+	}
+
+	return report, nil
+}
+
+// removeOrphan удаляет содержимое filePath, если на него по-прежнему нет ссылок и оно не использовалось
+// после deadline. Проверка и удаление выполняются под той же блокировкой, что и добавление ссылки (см. lockFile):
+// то же содержимое могло быть повторно загружено и сохранено в новой записи после чтения ссылок,
+// в том числе другим процессом.
+func (s *Service) removeOrphan(ctx context.Context, filePath string, deadline time.Time) (deleted bool, err error) {
+	err = s.lockFile(ctx, filePath, func() error {
+		info, err := s.StoreFiles.StatFile(ctx, filePath)
+		if errors.Is(err, file.ErrNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if info.ModTime.After(deadline) {
+			return nil
+		}
+
+		n, err := s.Store.CountFileRefs(ctx, filePath)
+		if err != nil || n > 0 {
+			return err
+		}
+
+		if err = s.StoreFiles.DeleteFile(ctx, filePath); err != nil {
+			return err
+		}
+
+		deleted = true
+
+		return nil
+	})
+
+	return deleted, err
+}
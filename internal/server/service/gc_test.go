@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CollectGarbage(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := storage.NewMemory()
+	storeFiles, err := file.New(dir)
+	require.NoError(t, err)
+	s := New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	old := time.Now().Add(-48 * time.Hour)
+	save := func(content string, modTime time.Time) string {
+		filePath, err := storeFiles.SaveFile(ctx, strings.NewReader(content), -1)
+		require.NoError(t, err)
+		diskPath := filepath.Join(dir, strings.TrimPrefix(filePath, file.URLPrefix+"/"))
+		require.NoError(t, os.Chtimes(diskPath, modTime, modTime))
+
+		return filePath
+	}
+
+	kept := save("kept", old)
+	orphan := save("orphan", old)
+	save("fresh", time.Now())
+
+	fileID, err := store.SaveFile(ctx, model.DataFile{UserID: userID, Title: "kept", Filename: "kept", Path: kept, UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	// содержимое записи удалено из хранилища в обход сервиса
+	missing := save("missing", old)
+	missingID, err := store.SaveFile(ctx, model.DataFile{UserID: userID, Title: "missing", Filename: "missing", Path: missing, UpdatedAt: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, storeFiles.DeleteFile(ctx, missing))
+
+	// части незавершенной загрузки нужны, пока загрузка есть в БД
+	require.NoError(t, store.SaveUpload(ctx, model.Upload{ID: "live", UserID: userID, Size: 4, ChunkSize: 4, ExpiresAt: time.Now().Add(time.Hour)}))
+	for _, id := range []string{"live", "gone"} {
+		require.NoError(t, storeFiles.SaveUploadPart(ctx, id, 0, strings.NewReader("part"), 4))
+		diskPath := filepath.Join(dir, "uploads", id, "0")
+		require.NoError(t, os.Chtimes(diskPath, old, old))
+	}
+	gone := file.URLPrefix + "/uploads/gone/0"
+
+	report, err := s.CollectGarbage(ctx, 24*time.Hour, false)
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Blobs)
+	require.Len(t, report.Orphans, 2)
+	assert.ElementsMatch(t, []string{orphan, gone}, []string{report.Orphans[0].Path, report.Orphans[1].Path})
+	assert.False(t, report.Orphans[0].Deleted)
+	assert.Zero(t, report.Freed)
+	assert.Equal(t, []model.FileRef{{Table: model.FileRefTableFiles, ID: missingID, UserID: userID, Path: missing}}, report.Missing)
+
+	report, err = s.CollectGarbage(ctx, 24*time.Hour, true)
+	require.NoError(t, err)
+	require.Len(t, report.Orphans, 2)
+	assert.True(t, report.Orphans[0].Deleted)
+	assert.True(t, report.Orphans[1].Deleted)
+	assert.Equal(t, int64(len("orphan")+len("part")), report.Freed)
+
+	_, err = storeFiles.StatFile(ctx, orphan)
+	assert.ErrorIs(t, err, file.ErrNotFound)
+	_, err = storeFiles.StatFile(ctx, kept)
+	assert.NoError(t, err)
+
+	report, err = s.CollectGarbage(ctx, 24*time.Hour, true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Blobs)
+	assert.Empty(t, report.Orphans)
+
+	// без срока ожидания в отчет попадает и только что сохраненное содержимое
+	report, err = s.CollectGarbage(ctx, 0, false)
+	require.NoError(t, err)
+	require.Len(t, report.Orphans, 1)
+	assert.Equal(t, int64(len("fresh")), report.Orphans[0].Size)
+
+	require.NoError(t, store.DeleteFile(ctx, fileID, userID))
+	report, err = s.CollectGarbage(ctx, 24*time.Hour, false)
+	require.NoError(t, err)
+	require.Len(t, report.Orphans, 1)
+	assert.Equal(t, kept, report.Orphans[0].Path)
+}
+
+func TestService_removeOrphan(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := storage.NewMemory()
+	storeFiles, err := file.New(dir)
+	require.NoError(t, err)
+	s := New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	old := time.Now().Add(-48 * time.Hour)
+	deadline := time.Now().Add(-24 * time.Hour)
+	save := func(t *testing.T, content string) string {
+		filePath, err := storeFiles.SaveFile(ctx, strings.NewReader(content), -1)
+		require.NoError(t, err)
+		diskPath := filepath.Join(dir, strings.TrimPrefix(filePath, file.URLPrefix+"/"))
+		require.NoError(t, os.Chtimes(diskPath, old, old))
+
+		return filePath
+	}
+
+	tests := []struct {
+		name        string
+		prepare     func(t *testing.T) string
+		wantDeleted bool
+		wantStat    error
+	}{
+		{name: "orphan", prepare: func(t *testing.T) string { return save(t, "orphan") }, wantDeleted: true, wantStat: file.ErrNotFound},
+		{name: "reused after walk", prepare: func(t *testing.T) string {
+			filePath := save(t, "reused")
+			// та же загрузка сохранена повторно: содержимое найдено по хешу, время изменения обновлено
+			id, err := s.SaveFile(ctx, model.DataFile{UserID: userID, Title: "reused", Filename: "reused", Path: filePath})
+			require.NoError(t, err)
+			require.NoError(t, store.DeleteFile(ctx, id, userID))
+
+			return filePath
+		}},
+		{name: "touched", prepare: func(t *testing.T) string {
+			filePath := save(t, "touched")
+			require.NoError(t, storeFiles.TouchFile(ctx, filePath))
+
+			return filePath
+		}},
+		{name: "referenced", prepare: func(t *testing.T) string {
+			filePath := save(t, "referenced")
+			_, err := store.SaveFile(ctx, model.DataFile{UserID: userID, Title: "referenced", Filename: "referenced", Path: filePath})
+			require.NoError(t, err)
+
+			return filePath
+		}},
+		{name: "already deleted", prepare: func(t *testing.T) string {
+			filePath := save(t, "deleted")
+			require.NoError(t, storeFiles.DeleteFile(ctx, filePath))
+
+			return filePath
+		}, wantStat: file.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := tt.prepare(t)
+
+			deleted, err := s.removeOrphan(ctx, filePath, deadline)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDeleted, deleted)
+
+			_, err = storeFiles.StatFile(ctx, filePath)
+			if tt.wantStat != nil {
+				assert.ErrorIs(t, err, tt.wantStat)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+)
+
+func (s *Service) SaveIdentity(ctx context.Context, doc model.DataIdentity) (id int, err error) {
+	err = doc.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveIdentity: %w", err)
+	}
+
+	err = s.checkItemRefs(ctx, doc.UserID, doc.FolderID, doc.TagIDs)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveIdentity: %w", err)
+	}
+
+	bytes, err := payloadGrowth(ctx, &doc, doc.ID, doc.UserID, s.Store.FindIdentity)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveIdentity: %w", err)
+	}
+
+	err = s.checkQuota(ctx, doc.UserID, newItems(doc.ID), bytes)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveIdentity: %w", err)
+	}
+
+	return s.Store.SaveIdentity(ctx, doc)
+}
+
+func (s *Service) DeleteIdentity(ctx context.Context, docID, userID int) (err error) {
+	return s.deleteItem(ctx, model.ItemTypeIdentity, docID, userID, s.Store.DeleteIdentity)
+}
+
+func (s *Service) FindIdentity(ctx context.Context, docID, userID int) (doc model.DataIdentity, err error) {
+	return s.Store.FindIdentity(ctx, docID, userID)
+}
+
+func (s *Service) FindAllIdentities(ctx context.Context, userID int, filter model.ItemFilter) (docs []model.DataIdentity, err error) {
+	return s.Store.FindAllIdentities(ctx, userID, filter)
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+)
+
+// checkRole участие пользователя в организации с ролью не ниже minRole. Для не участника возвращает
+// storage.ErrorNotFound, чтобы не раскрывать существование организации; приглашенному, не принявшему
+// приглашение, и участнику с недостаточной ролью - ErrAccessDenied.
+func (s *Service) checkRole(ctx context.Context, orgID, userID int, minRole string) (member model.Member, err error) {
+	member, err = s.Store.FindMember(ctx, orgID, userID)
+	if err != nil {
+		return member, err
+	}
+
+	if member.Status != model.MemberStatusActive || !model.RoleAtLeast(member.Role, minRole) {
+		return member, ErrAccessDenied
+	}
+
+	return member, nil
+}
+
+// checkCollection проверяет роль пользователя в организации, которой принадлежит коллекция.
+func (s *Service) checkCollection(ctx context.Context, colID, userID int, minRole string) error {
+	col, err := s.Store.FindCollection(ctx, colID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.checkRole(ctx, col.OrgID, userID, minRole)
+
+	return err
+}
+
+// SaveOrganization создает организацию, владельцем которой становится userID, или переименовывает ее;
+// переименовать организацию может администратор.
+func (s *Service) SaveOrganization(ctx context.Context, org model.Organization, userID int) (id int, err error) {
+	err = org.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveOrganization: %w", err)
+	}
+
+	if org.ID != 0 {
+		if _, err = s.checkRole(ctx, org.ID, userID, model.RoleAdmin); err != nil {
+			return id, fmt.Errorf("service.SaveOrganization: %w", err)
+		}
+	}
+
+	org.UpdatedAt = time.Now()
+
+	return s.Store.SaveOrganization(ctx, org, userID)
+}
+
+// DeleteOrganization удаляет организацию со всеми коллекциями; доступно только владельцу.
+func (s *Service) DeleteOrganization(ctx context.Context, orgID, userID int) error {
+	if _, err := s.checkRole(ctx, orgID, userID, model.RoleOwner); err != nil {
+		return fmt.Errorf("service.DeleteOrganization: %w", err)
+	}
+
+	return s.Store.DeleteOrganization(ctx, orgID)
+}
+
+func (s *Service) FindAllOrganizations(ctx context.Context, userID int) (orgs []model.Organization, err error) {
+	return s.Store.FindAllOrganizations(ctx, userID)
+}
+
+// InviteMember приглашает пользователя по логину или меняет роль участника. Приглашает администратор,
+// назначать и менять администраторов может только владелец, роль владельца не меняется.
+// Keys - ключи коллекций организации, зашифрованные открытым ключом приглашенного.
+func (s *Service) InviteMember(ctx context.Context, member model.Member, userID int) error {
+	err := member.Validate()
+	if err != nil {
+		return fmt.Errorf("service.InviteMember: %w", err)
+	}
+
+	actor, err := s.checkRole(ctx, member.OrgID, userID, model.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("service.InviteMember: %w", err)
+	}
+
+	pub, err := s.Store.FindPublicKey(ctx, member.Login)
+	if errors.Is(err, storage.ErrorNotFound) || (err == nil && pub.UserID == userID) {
+		err = model.ErrMemberLoginInvalid
+	}
+
+	if err != nil {
+		return fmt.Errorf("service.InviteMember: %w", err)
+	}
+
+	member.UserID = pub.UserID
+	member.Status = model.MemberStatusInvited
+
+	existing, err := s.Store.FindMember(ctx, member.OrgID, member.UserID)
+	switch {
+	case err == nil:
+		member.Status = existing.Status
+		if existing.Role == model.RoleOwner || (existing.Role == model.RoleAdmin && actor.Role != model.RoleOwner) {
+			return fmt.Errorf("service.InviteMember: %w", ErrAccessDenied)
+		}
+	case !errors.Is(err, storage.ErrorNotFound):
+		return fmt.Errorf("service.InviteMember: %w", err)
+	}
+
+	if member.Role == model.RoleAdmin && actor.Role != model.RoleOwner {
+		return fmt.Errorf("service.InviteMember: %w", ErrAccessDenied)
+	}
+
+	for i, k := range member.Keys {
+		col, err := s.Store.FindCollection(ctx, k.CollectionID)
+		if errors.Is(err, storage.ErrorNotFound) || (err == nil && col.OrgID != member.OrgID) {
+			err = model.ErrCollectionKeyInvalid
+		}
+
+		if err != nil {
+			return fmt.Errorf("service.InviteMember: %w", err)
+		}
+
+		member.Keys[i].UserID = member.UserID
+	}
+	member.UpdatedAt = time.Now()
+
+	return s.Store.SaveMember(ctx, member)
+}
+
+// AcceptInvite принимает приглашение пользователя в организацию.
+func (s *Service) AcceptInvite(ctx context.Context, orgID, userID int) error {
+	member, err := s.Store.FindMember(ctx, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("service.AcceptInvite: %w", err)
+	}
+
+	if member.Status == model.MemberStatusActive {
+		return nil
+	}
+
+	member.Status = model.MemberStatusActive
+	member.UpdatedAt = time.Now()
+
+	return s.Store.SaveMember(ctx, member)
+}
+
+// RemoveMember исключает участника по логину. Покинуть организацию или отклонить приглашение может сам
+// участник, исключить другого - администратор; администратора исключает только владелец.
+// Владелец организацию не покидает - он ее удаляет.
+func (s *Service) RemoveMember(ctx context.Context, orgID int, login string, userID int) error {
+	members, err := s.Store.FindAllMembers(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("service.RemoveMember: %w", err)
+	}
+
+	var target, actor *model.Member
+	for i := range members {
+		if members[i].Login == login {
+			target = &members[i]
+		}
+
+		if members[i].UserID == userID {
+			actor = &members[i]
+		}
+	}
+
+	if target == nil || actor == nil {
+		return fmt.Errorf("service.RemoveMember: %w", storage.ErrorNotFound)
+	}
+
+	if target.Role == model.RoleOwner {
+		return fmt.Errorf("service.RemoveMember: %w", ErrAccessDenied)
+	}
+
+	if target.UserID != userID {
+		minRole := model.RoleAdmin
+		if target.Role == model.RoleAdmin {
+			minRole = model.RoleOwner
+		}
+
+		if _, err = s.checkRole(ctx, orgID, userID, minRole); err != nil {
+			return fmt.Errorf("service.RemoveMember: %w", err)
+		}
+	}
+
+	return s.Store.DeleteMember(ctx, orgID, target.UserID)
+}
+
+// FindAllMembers участники организации с открытыми ключами; доступно участникам организации.
+func (s *Service) FindAllMembers(ctx context.Context, orgID, userID int) (members []model.Member, err error) {
+	if _, err = s.checkRole(ctx, orgID, userID, model.RoleReadOnly); err != nil {
+		return members, fmt.Errorf("service.FindAllMembers: %w", err)
+	}
+
+	return s.Store.FindAllMembers(ctx, orgID)
+}
+
+// SaveCollection создает или переименовывает коллекцию организации; доступно администратору.
+// При создании Keys должны содержать ключ коллекции для самого пользователя; ключи принимаются
+// только для участников организации.
+func (s *Service) SaveCollection(ctx context.Context, col model.Collection, userID int) (id int, err error) {
+	err = col.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCollection: %w", err)
+	}
+
+	if _, err = s.checkRole(ctx, col.OrgID, userID, model.RoleAdmin); err != nil {
+		return id, fmt.Errorf("service.SaveCollection: %w", err)
+	}
+
+	members, err := s.Store.FindAllMembers(ctx, col.OrgID)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCollection: %w", err)
+	}
+
+	userIDs := make(map[string]int, len(members))
+	for _, v := range members {
+		userIDs[v.Login] = v.UserID
+	}
+
+	own := false
+	for i, k := range col.Keys {
+		memberID, ok := userIDs[k.Login]
+		if !ok || k.Key == "" {
+			return id, fmt.Errorf("service.SaveCollection: %w", model.ErrCollectionKeyInvalid)
+		}
+
+		col.Keys[i].UserID = memberID
+		own = own || memberID == userID
+	}
+
+	if col.ID == 0 && !own {
+		return id, fmt.Errorf("service.SaveCollection: %w", model.ErrCollectionKeyEmpty)
+	}
+
+	col.UpdatedAt = time.Now()
+
+	return s.Store.SaveCollection(ctx, col)
+}
+
+// DeleteCollection удаляет коллекцию со всеми записями; доступно администратору.
+func (s *Service) DeleteCollection(ctx context.Context, colID, userID int) error {
+	if err := s.checkCollection(ctx, colID, userID, model.RoleAdmin); err != nil {
+		return fmt.Errorf("service.DeleteCollection: %w", err)
+	}
+
+	return s.Store.DeleteCollection(ctx, colID)
+}
+
+func (s *Service) FindAllCollections(ctx context.Context, userID int) (cols []model.Collection, err error) {
+	return s.Store.FindAllCollections(ctx, userID)
+}
+
+// SaveCollectionItem сохраняет запись коллекции; доступно участнику с ролью member и выше.
+func (s *Service) SaveCollectionItem(ctx context.Context, item model.CollectionItem, userID int) (id int, err error) {
+	err = item.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCollectionItem: %w", err)
+	}
+
+	if err = s.checkCollection(ctx, item.CollectionID, userID, model.RoleMember); err != nil {
+		return id, fmt.Errorf("service.SaveCollectionItem: %w", err)
+	}
+
+	// запись учитывается в квоте участника, который сохранил ее последним
+	items, bytes := 1, item.PayloadSize()
+	if item.ID != 0 {
+		old, err := s.findCollectionItem(ctx, item.ID, item.CollectionID)
+		if err != nil {
+			return id, fmt.Errorf("service.SaveCollectionItem: %w", err)
+		}
+
+		if old.UserID == userID {
+			items, bytes = 0, bytes-old.PayloadSize()
+		}
+	}
+
+	if err = s.checkQuota(ctx, userID, items, bytes); err != nil {
+		return id, fmt.Errorf("service.SaveCollectionItem: %w", err)
+	}
+
+	item.UserID = userID
+	item.UpdatedAt = time.Now()
+
+	return s.Store.SaveCollectionItem(ctx, item)
+}
+
+// findCollectionItem запись коллекции по идентификатору; иначе storage.ErrorNotFound.
+func (s *Service) findCollectionItem(ctx context.Context, itemID, colID int) (item model.CollectionItem, err error) {
+	items, err := s.Store.FindAllCollectionItems(ctx, colID)
+	if err != nil {
+		return item, err
+	}
+
+	for _, v := range items {
+		if v.ID == itemID {
+			return v, nil
+		}
+	}
+
+	return item, storage.ErrorNotFound
+}
+
+// DeleteCollectionItem удаляет запись коллекции; доступно участнику с ролью member и выше.
+func (s *Service) DeleteCollectionItem(ctx context.Context, itemID, colID, userID int) error {
+	if err := s.checkCollection(ctx, colID, userID, model.RoleMember); err != nil {
+		return fmt.Errorf("service.DeleteCollectionItem: %w", err)
+	}
+
+	return s.Store.DeleteCollectionItem(ctx, itemID, colID)
+}
+
+// FindAllCollectionItems записи коллекции; доступно всем участникам организации.
+func (s *Service) FindAllCollectionItems(ctx context.Context, colID, userID int) (items []model.CollectionItem, err error) {
+	if err = s.checkCollection(ctx, colID, userID, model.RoleReadOnly); err != nil {
+		return items, fmt.Errorf("service.FindAllCollectionItems: %w", err)
+	}
+
+	return s.Store.FindAllCollectionItems(ctx, colID)
+}
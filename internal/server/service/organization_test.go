@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_OrganizationRoles(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := New(store, nil, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	users := make(map[string]int)
+	for _, login := range []string{"owner", "admin", "member", "reader", "invited", "outsider"} {
+		id, err := store.CreateUser(ctx, model.User{Login: login, Password: "password"})
+		require.NoError(t, err)
+		require.NoError(t, s.SaveUserKeys(ctx, model.UserKeys{UserID: id, PublicKey: "public", PrivateKey: "private"}))
+		users[login] = id
+	}
+
+	orgID, err := s.SaveOrganization(ctx, model.Organization{Name: "team"}, users["owner"])
+	require.NoError(t, err)
+
+	for login, role := range map[string]string{"admin": model.RoleAdmin, "member": model.RoleMember, "reader": model.RoleReadOnly, "invited": model.RoleMember} {
+		require.NoError(t, s.InviteMember(ctx, model.Member{OrgID: orgID, Login: login, Role: role}, users["owner"]))
+		if login != "invited" {
+			require.NoError(t, s.AcceptInvite(ctx, orgID, users[login]))
+		}
+	}
+
+	colID, err := s.SaveCollection(ctx, model.Collection{OrgID: orgID, Name: "prod",
+		Keys: []model.CollectionKey{{Login: "admin", Key: "key"}, {Login: "reader", Key: "key"}}}, users["admin"])
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		do      func() error
+		wantErr error
+	}{
+		{name: "member saves item", do: func() error {
+			_, err := s.SaveCollectionItem(ctx, model.CollectionItem{CollectionID: colID, ItemType: model.ItemTypeCred, Data: "data"}, users["member"])
+			return err
+		}},
+		{name: "reader saves item", wantErr: ErrAccessDenied, do: func() error {
+			_, err := s.SaveCollectionItem(ctx, model.CollectionItem{CollectionID: colID, ItemType: model.ItemTypeCred, Data: "data"}, users["reader"])
+			return err
+		}},
+		{name: "reader lists items", do: func() error {
+			_, err := s.FindAllCollectionItems(ctx, colID, users["reader"])
+			return err
+		}},
+		{name: "invited lists items", wantErr: ErrAccessDenied, do: func() error {
+			_, err := s.FindAllCollectionItems(ctx, colID, users["invited"])
+			return err
+		}},
+		{name: "outsider lists items", wantErr: storage.ErrorNotFound, do: func() error {
+			_, err := s.FindAllCollectionItems(ctx, colID, users["outsider"])
+			return err
+		}},
+		{name: "reader deletes item", wantErr: ErrAccessDenied, do: func() error {
+			return s.DeleteCollectionItem(ctx, 1, colID, users["reader"])
+		}},
+		{name: "member creates collection", wantErr: ErrAccessDenied, do: func() error {
+			_, err := s.SaveCollection(ctx, model.Collection{OrgID: orgID, Name: "x", Keys: []model.CollectionKey{{Login: "member", Key: "key"}}}, users["member"])
+			return err
+		}},
+		{name: "collection without own key", wantErr: model.ErrCollectionKeyEmpty, do: func() error {
+			_, err := s.SaveCollection(ctx, model.Collection{OrgID: orgID, Name: "x", Keys: []model.CollectionKey{{Login: "member", Key: "key"}}}, users["admin"])
+			return err
+		}},
+		{name: "collection key for outsider", wantErr: model.ErrCollectionKeyInvalid, do: func() error {
+			_, err := s.SaveCollection(ctx, model.Collection{OrgID: orgID, Name: "x", Keys: []model.CollectionKey{{Login: "outsider", Key: "key"}}}, users["admin"])
+			return err
+		}},
+		{name: "admin invites admin", wantErr: ErrAccessDenied, do: func() error {
+			return s.InviteMember(ctx, model.Member{OrgID: orgID, Login: "outsider", Role: model.RoleAdmin}, users["admin"])
+		}},
+		{name: "member invites", wantErr: ErrAccessDenied, do: func() error {
+			return s.InviteMember(ctx, model.Member{OrgID: orgID, Login: "outsider", Role: model.RoleReadOnly}, users["member"])
+		}},
+		{name: "admin changes owner role", wantErr: ErrAccessDenied, do: func() error {
+			return s.InviteMember(ctx, model.Member{OrgID: orgID, Login: "owner", Role: model.RoleMember}, users["admin"])
+		}},
+		{name: "unknown login", wantErr: model.ErrMemberLoginInvalid, do: func() error {
+			return s.InviteMember(ctx, model.Member{OrgID: orgID, Login: "unknown", Role: model.RoleMember}, users["admin"])
+		}},
+		{name: "member removes reader", wantErr: ErrAccessDenied, do: func() error {
+			return s.RemoveMember(ctx, orgID, "reader", users["member"])
+		}},
+		{name: "owner leaves", wantErr: ErrAccessDenied, do: func() error {
+			return s.RemoveMember(ctx, orgID, "owner", users["owner"])
+		}},
+		{name: "admin deletes organization", wantErr: ErrAccessDenied, do: func() error {
+			return s.DeleteOrganization(ctx, orgID, users["admin"])
+		}},
+		{name: "invited declines", do: func() error {
+			return s.RemoveMember(ctx, orgID, "invited", users["invited"])
+		}},
+		{name: "admin removes member", do: func() error {
+			return s.RemoveMember(ctx, orgID, "member", users["admin"])
+		}},
+		{name: "removed member saves item", wantErr: storage.ErrorNotFound, do: func() error {
+			_, err := s.SaveCollectionItem(ctx, model.CollectionItem{CollectionID: colID, ItemType: model.ItemTypeCred, Data: "data"}, users["member"])
+			return err
+		}},
+		{name: "owner deletes organization", do: func() error {
+			return s.DeleteOrganization(ctx, orgID, users["owner"])
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.do()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+)
+
+// GetUsage использование хранилища пользователем вместе с квотами из конфигурации.
+func (s *Service) GetUsage(ctx context.Context, userID int) (usage model.Usage, err error) {
+	usage, err = s.Store.GetUsage(ctx, userID)
+	if err != nil {
+		return usage, fmt.Errorf("service.GetUsage: %w", err)
+	}
+
+	usage.MaxBytes = s.Cfg.QuotaBytes
+	usage.MaxFileSize = s.Cfg.QuotaFileSize
+	usage.MaxItems = s.Cfg.QuotaItems
+
+	return usage, nil
+}
+
+// checkQuota проверяет квоты пользователя перед сохранением: items - число новых записей,
+// bytes - прирост объема данных (при замене - разница размеров, может быть отрицательной).
+func (s *Service) checkQuota(ctx context.Context, userID, items int, bytes int64) error {
+	checkItems := s.Cfg.QuotaItems > 0 && items > 0
+	checkBytes := s.Cfg.QuotaBytes > 0 && bytes > 0
+
+	if !checkItems && !checkBytes {
+		return nil
+	}
+
+	usage, err := s.Store.GetUsage(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service.checkQuota: %w", err)
+	}
+
+	if checkItems && usage.Items+items > s.Cfg.QuotaItems {
+		return ErrQuotaItems
+	}
+
+	if checkBytes && usage.Bytes+bytes > s.Cfg.QuotaBytes {
+		return ErrQuotaBytes
+	}
+
+	return nil
+}
+
+// checkFileSize проверяет ограничение размера одного файла.
+func (s *Service) checkFileSize(size int64) error {
+	if s.Cfg.QuotaFileSize > 0 && size > s.Cfg.QuotaFileSize {
+		return ErrQuotaFileSize
+	}
+
+	return nil
+}
+
+// payloadGrowth прирост объема данных при сохранении записи: размер новой записи за вычетом
+// прежней, которую find находит по идентификатору; для новой записи - ее размер.
+func payloadGrowth[T any, P interface {
+	*T
+	PayloadSize() int64
+}](ctx context.Context, item P, id, userID int, find func(ctx context.Context, id, userID int) (T, error)) (int64, error) {
+	bytes := item.PayloadSize()
+	if id == 0 {
+		return bytes, nil
+	}
+
+	old, err := find(ctx, id, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return bytes - P(&old).PayloadSize(), nil
+}
+
+// newItems число создаваемых записей: запись без идентификатора новая.
+func newItems(id int) int {
+	if id == 0 {
+		return 1
+	}
+
+	return 0
+}
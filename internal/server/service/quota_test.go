@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Quota(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+	s := New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key", QuotaBytes: 200, QuotaFileSize: 60, QuotaItems: 3})
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "owner", Password: "password"})
+	require.NoError(t, err)
+
+	path, err := storeFiles.SaveFile(ctx, strings.NewReader("encrypted"), -1)
+	require.NoError(t, err)
+
+	file := model.DataFile{UserID: userID, Title: "file", Path: path, Size: 61, UpdatedAt: time.Now()}
+	_, err = s.SaveFile(ctx, file)
+	assert.ErrorIs(t, err, ErrQuotaFileSize)
+
+	file.Size = 60
+	file.ID, err = s.SaveFile(ctx, file)
+	require.NoError(t, err)
+
+	// объем записи - все ее данные, а не только текст: текст дополняет использование до 200 байт
+	text := model.DataText{UserID: userID, Title: "text", UpdatedAt: time.Now()}
+	free := 200 - file.Size - file.PayloadSize() - text.PayloadSize()
+	text.Text = strings.Repeat("a", int(free)+1)
+	_, err = s.SaveText(ctx, text)
+	assert.ErrorIs(t, err, ErrQuotaBytes)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+	text.Text = strings.Repeat("a", int(free))
+	text.ID, err = s.SaveText(ctx, text)
+	require.NoError(t, err)
+
+	// замена данных учитывает только разницу размеров
+	file.Size = 50
+	_, err = s.SaveFile(ctx, file)
+	require.NoError(t, err)
+	text.Text = strings.Repeat("b", int(free)+10)
+	_, err = s.SaveText(ctx, text)
+	require.NoError(t, err)
+
+	card := model.DataCard{UserID: userID, Title: "card", Number: "4242424242424242", Date: "12/30", Cvv: "123", UpdatedAt: time.Now()}
+	_, err = s.SaveCard(ctx, card)
+	assert.ErrorIs(t, err, ErrQuotaBytes)
+
+	s.Cfg.QuotaBytes = 0
+	_, err = s.SaveCard(ctx, card)
+	require.NoError(t, err)
+
+	// папки, метки и другие строки пользователя тоже считаются записями
+	_, err = s.SaveFolder(ctx, model.Folder{UserID: userID, Name: "folder", UpdatedAt: time.Now()})
+	assert.ErrorIs(t, err, ErrQuotaItems)
+
+	usage, err := s.GetUsage(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, model.Usage{Bytes: 200 + card.PayloadSize(), Items: 3, MaxFileSize: 60, MaxItems: 3}, usage)
+
+	s.Cfg.QuotaItems = 0
+	_, err = s.SaveFolder(ctx, model.Folder{UserID: userID, Name: "folder", UpdatedAt: time.Now()})
+	assert.NoError(t, err)
+}
+
+func TestService_QuotaCollectionItem(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := New(store, nil, &config.Config{JWTSecretKey: "test_secret_key", QuotaItems: 1})
+
+	users := make(map[string]int)
+	for _, login := range []string{"owner", "member"} {
+		id, err := store.CreateUser(ctx, model.User{Login: login, Password: "password"})
+		require.NoError(t, err)
+		require.NoError(t, s.SaveUserKeys(ctx, model.UserKeys{UserID: id, PublicKey: "public", PrivateKey: "private"}))
+		users[login] = id
+	}
+
+	orgID, err := s.SaveOrganization(ctx, model.Organization{Name: "team"}, users["owner"])
+	require.NoError(t, err)
+	require.NoError(t, s.InviteMember(ctx, model.Member{OrgID: orgID, Login: "member", Role: model.RoleMember}, users["owner"]))
+	require.NoError(t, s.AcceptInvite(ctx, orgID, users["member"]))
+	colID, err := s.SaveCollection(ctx, model.Collection{OrgID: orgID, Name: "prod",
+		Keys: []model.CollectionKey{{Login: "owner", Key: "key"}, {Login: "member", Key: "key"}}}, users["owner"])
+	require.NoError(t, err)
+
+	item := model.CollectionItem{CollectionID: colID, ItemType: model.ItemTypeCred, Data: "data"}
+	item.ID, err = s.SaveCollectionItem(ctx, item, users["owner"])
+	require.NoError(t, err)
+
+	// изменение своей записи не создает новую
+	item.Data = "changed"
+	_, err = s.SaveCollectionItem(ctx, item, users["owner"])
+	require.NoError(t, err)
+
+	_, err = s.SaveCollectionItem(ctx, model.CollectionItem{CollectionID: colID, ItemType: model.ItemTypeCred, Data: "data"}, users["owner"])
+	assert.ErrorIs(t, err, ErrQuotaItems)
+
+	// запись переходит в квоту участника, который сохранил ее последним
+	_, err = s.SaveCollectionItem(ctx, item, users["member"])
+	require.NoError(t, err)
+
+	for login, want := range map[string]model.Usage{
+		"owner":  {MaxItems: 1},
+		"member": {Bytes: item.PayloadSize(), Items: 1, MaxItems: 1},
+	} {
+		usage, err := s.GetUsage(ctx, users[login])
+		require.NoError(t, err)
+		assert.Equal(t, want, usage, login)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/hash"
+)
+
+// secretIDSize размер случайного идентификатора секрета в байтах; кратен трем, чтобы base64 был без дополнения.
+const secretIDSize = 18
+
+// CreateSecret сохраняет одноразовый секрет со случайным идентификатором, который становится частью ссылки.
+// Возвращает секрет без содержимого.
+func (s *Service) CreateSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	now := time.Now()
+
+	err := secret.Validate(now)
+	if err != nil {
+		return secret, fmt.Errorf("service.CreateSecret: %w", err)
+	}
+
+	secret.ID, err = hash.GenerateRandomString(secretIDSize)
+	if err != nil {
+		return secret, fmt.Errorf("service.CreateSecret: %w", err)
+	}
+
+	secret.Views = 0
+	secret.CreatedAt = now
+
+	if err = s.checkQuota(ctx, secret.UserID, 1, secret.PayloadSize()); err != nil {
+		return secret, fmt.Errorf("service.CreateSecret: %w", err)
+	}
+
+	if err = s.Store.SaveSecret(ctx, secret); err != nil {
+		return secret, fmt.Errorf("service.CreateSecret: %w", err)
+	}
+	secret.Data = ""
+
+	return secret, nil
+}
+
+// OpenSecret засчитывает просмотр секрета и возвращает его содержимое; после последнего просмотра
+// секрет удаляется.
+func (s *Service) OpenSecret(ctx context.Context, secretID string) (model.Secret, error) {
+	return s.Store.OpenSecret(ctx, secretID, time.Now())
+}
+
+func (s *Service) DeleteSecret(ctx context.Context, secretID string, userID int) error {
+	return s.Store.DeleteSecret(ctx, secretID, userID)
+}
+
+// FindAllSecrets действующие секреты пользователя без содержимого.
+func (s *Service) FindAllSecrets(ctx context.Context, userID int) (secrets []model.Secret, err error) {
+	list, err := s.Store.FindAllSecrets(ctx, userID)
+	if err != nil {
+		return secrets, fmt.Errorf("service.FindAllSecrets: %w", err)
+	}
+
+	now := time.Now()
+	for _, v := range list {
+		if v.ExpiresAt.After(now) {
+			secrets = append(secrets, v)
+		}
+	}
+
+	return secrets, nil
+}
+
+func (s *Service) ClearExpiredSecrets(ctx context.Context) error {
+	err := s.Store.ClearExpiredSecrets(ctx)
+	if err != nil {
+		return fmt.Errorf("service.ClearExpiredSecrets: %w", err)
+	}
+
+	return nil
+}
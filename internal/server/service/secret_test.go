@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Secrets(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := New(store, nil, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "owner", Password: "password"})
+	require.NoError(t, err)
+
+	_, err = s.CreateSecret(ctx, model.Secret{UserID: userID, Data: "data", MaxViews: 1, ExpiresAt: time.Now().Add(-time.Minute)})
+	assert.ErrorIs(t, err, model.ErrSecretExpiresInvalid)
+
+	secret, err := s.CreateSecret(ctx, model.Secret{UserID: userID, Data: "data", MaxViews: 2, ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Len(t, secret.ID, 24)
+	assert.Empty(t, secret.Data)
+
+	for views := 1; views <= 2; views++ {
+		opened, err := s.OpenSecret(ctx, secret.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "data", opened.Data)
+		assert.Equal(t, views, opened.Views)
+	}
+
+	_, err = s.OpenSecret(ctx, secret.ID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	// истекший секрет не открывается и не попадает в список, а фоновая очистка его удаляет
+	expired, err := s.CreateSecret(ctx, model.Secret{UserID: userID, Data: "data", MaxViews: 1, ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, store.SaveSecret(ctx, model.Secret{ID: "old", UserID: userID, Data: "data", MaxViews: 1, ExpiresAt: time.Now().Add(-time.Hour)}))
+
+	_, err = s.OpenSecret(ctx, "old")
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	list, err := s.FindAllSecrets(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, expired.ID, list[0].ID)
+
+	require.NoError(t, s.ClearExpiredSecrets(ctx))
+	list, err = store.FindAllSecrets(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	assert.ErrorIs(t, s.DeleteSecret(ctx, expired.ID, userID+1), storage.ErrorNotFound)
+	require.NoError(t, s.DeleteSecret(ctx, expired.ID, userID))
+}
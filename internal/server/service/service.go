@@ -20,6 +20,8 @@ type Service struct {
 	StoreFiles   *file.StorageFiles
 	Cfg          *config.Config
 	TokenManager auth.TokenManager
+
+	fileLocks fileLocks
 }
 
 func New(store storage.Interface, storeFiles *file.StorageFiles, cfg *config.Config) *Service {
@@ -46,7 +48,7 @@ func (s *Service) ClearExpiredRefreshTokens(ctx context.Context) error {
 		return fmt.Errorf("service.ClearExpiredRefreshTokens: %w", err)
 	}
 
-	return fmt.Errorf("service.ClearExpiredRefreshTokens: %w", err)
+	return nil
 }
 
 func (s *Service) CreateSession(ctx context.Context, userID int) (model.Tokens, error) {
@@ -76,14 +78,22 @@ func (s *Service) CreateSession(ctx context.Context, userID int) (model.Tokens,
 	}
 
 	err = s.Store.SetRefreshToken(ctx, model.RefreshToken{UserID: userID, Token: res.RefreshToken, ExpiredAt: time.Now().Add(refreshTokenTTL)})
+	if err != nil {
+		return res, fmt.Errorf("service.CreateSession: %w", err)
+	}
 
-	return res, fmt.Errorf("service.CreateUser: %w", err)
+	return res, nil
 }
 
 func (s *Service) SignUp(ctx context.Context, user model.User) (tokens model.Tokens, err error) {
+	err = user.Validate()
+	if err != nil {
+		return tokens, fmt.Errorf("service.SignUp: %w", err)
+	}
+
 	userID, err := s.Store.CreateUser(ctx, user)
 	if err != nil {
-		return tokens, fmt.Errorf("service.CreateSession: %w", err)
+		return tokens, fmt.Errorf("service.SignUp: %w", err)
 	}
 
 	return s.CreateSession(ctx, userID)
@@ -101,11 +111,15 @@ func (s *Service) SignIn(ctx context.Context, user model.User) (tokens model.Tok
 func (s *Service) GetRefreshToken(ctx context.Context, token string) (tokens model.Tokens, err error) {
 	userID, err := s.Store.GetRefreshTokenUserID(ctx, token)
 	if err != nil {
+		if errors.Is(err, storage.ErrorNotFound) {
+			return tokens, fmt.Errorf("service.GetRefreshToken: %w", ErrRefreshTokenInvalid)
+		}
+
 		return tokens, fmt.Errorf("service.GetRefreshToken: %w", err)
 	}
 
 	if userID == 0 {
-		return tokens, fmt.Errorf("service.GetRefreshToken: %w", errors.New("refresh token is invalid"))
+		return tokens, fmt.Errorf("service.GetRefreshToken: %w", ErrRefreshTokenInvalid)
 	}
 
 	return s.CreateSession(ctx, userID)
@@ -116,19 +130,35 @@ func (s *Service) SaveCard(ctx context.Context, card model.DataCard) (id int, er
 	if err != nil {
 		return id, fmt.Errorf("service.SaveCard: %w", err)
 	}
+
+	err = s.checkItemRefs(ctx, card.UserID, card.FolderID, card.TagIDs)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCard: %w", err)
+	}
+
+	bytes, err := payloadGrowth(ctx, &card, card.ID, card.UserID, s.Store.FindCard)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCard: %w", err)
+	}
+
+	err = s.checkQuota(ctx, card.UserID, newItems(card.ID), bytes)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCard: %w", err)
+	}
+
 	return s.Store.SaveCard(ctx, card)
 }
 
 func (s *Service) DeleteCard(ctx context.Context, cardID, userID int) (err error) {
-	return s.Store.DeleteCard(ctx, cardID, userID)
+	return s.deleteItem(ctx, model.ItemTypeCard, cardID, userID, s.Store.DeleteCard)
 }
 
 func (s *Service) FindCard(ctx context.Context, cardID, userID int) (card model.DataCard, err error) {
 	return s.Store.FindCard(ctx, cardID, userID)
 }
 
-func (s *Service) FindAllCards(ctx context.Context, userID int) (cards []model.DataCard, err error) {
-	return s.Store.FindAllCards(ctx, userID)
+func (s *Service) FindAllCards(ctx context.Context, userID int, filter model.ItemFilter) (cards []model.DataCard, err error) {
+	return s.Store.FindAllCards(ctx, userID, filter)
 }
 
 func (s *Service) SaveFile(ctx context.Context, file model.DataFile) (id int, err error) {
@@ -137,7 +167,49 @@ func (s *Service) SaveFile(ctx context.Context, file model.DataFile) (id int, er
 		return id, fmt.Errorf("service.SaveFile: %w", err)
 	}
 
-	return s.Store.SaveFile(ctx, file)
+	err = s.checkItemRefs(ctx, file.UserID, file.FolderID, file.TagIDs)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveFile: %w", err)
+	}
+
+	err = s.checkFileSize(file.Size)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveFile: %w", err)
+	}
+
+	var old model.DataFile
+	bytes := file.Size + file.PayloadSize()
+	if file.ID != 0 {
+		old, err = s.Store.FindFile(ctx, file.ID, file.UserID)
+		if err != nil {
+			return id, fmt.Errorf("service.SaveFile: %w", err)
+		}
+		bytes -= old.Size + old.PayloadSize()
+	}
+
+	err = s.checkQuota(ctx, file.UserID, newItems(file.ID), bytes)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveFile: %w", err)
+	}
+
+	file.SHA256 = contentHash(file.Path)
+
+	err = s.referenceFile(ctx, file.Path, func() (err error) {
+		id, err = s.Store.SaveFile(ctx, file)
+		return err
+	})
+	if err != nil {
+		return id, fmt.Errorf("service.SaveFile: %w", err)
+	}
+
+	// прежнее содержимое больше не нужно, если на него не ссылаются другие записи
+	if old.Path != "" && old.Path != file.Path {
+		if errRel := s.ReleaseFile(ctx, old.Path); errRel != nil {
+			logger.Error("service.SaveFile release old content: ", errRel)
+		}
+	}
+
+	return id, nil
 }
 
 func (s *Service) DeleteFile(ctx context.Context, fileID, userID int) (err error) {
@@ -147,25 +219,98 @@ func (s *Service) DeleteFile(ctx context.Context, fileID, userID int) (err error
 	}
 
 	if file.ID == 0 {
-		return errors.New("service.DeleteFile: file to delete not found")
+		return fmt.Errorf("service.DeleteFile: %w", storage.ErrorNotFound)
 	}
 
-	err = s.Store.DeleteFile(ctx, fileID, userID)
+	err = s.deleteItem(ctx, model.ItemTypeFile, fileID, userID, s.Store.DeleteFile)
 	if err != nil {
 		return fmt.Errorf("service.DeleteFile: %w", err)
 	}
 
-	err = s.StoreFiles.DeleteFile(file.Path)
+	err = s.ReleaseFile(ctx, file.Path)
 
 	return err
 }
 
+// ReleaseFile удаляет содержимое файла из хранилища, если на него больше не ссылается ни одна запись:
+// содержимое адресуется хешем и может быть общим для нескольких файлов и вложений.
+func (s *Service) ReleaseFile(ctx context.Context, filePath string) error {
+	return s.lockFile(ctx, filePath, func() error {
+		refs, err := s.Store.CountFileRefs(ctx, filePath)
+		if err != nil {
+			return fmt.Errorf("service.ReleaseFile: %w", err)
+		}
+
+		if refs > 0 {
+			return nil
+		}
+
+		return s.StoreFiles.DeleteFile(ctx, filePath)
+	})
+}
+
+// StatUserFile описание содержимого filePath, на которое ссылается файл или вложение пользователя userID.
+// Содержимое, на которое у пользователя нет ссылок, не отличается от отсутствующего: file.ErrNotFound.
+func (s *Service) StatUserFile(ctx context.Context, userID int, filePath string) (info file.BlobInfo, err error) {
+	refs, err := s.Store.CountUserFileRefs(ctx, userID, filePath)
+	if err != nil {
+		return info, fmt.Errorf("service.StatUserFile: %w", err)
+	}
+
+	if refs == 0 {
+		return info, fmt.Errorf("service.StatUserFile: %w", file.ErrNotFound)
+	}
+
+	info, err = s.StoreFiles.StatFile(ctx, filePath)
+	if err != nil {
+		return info, fmt.Errorf("service.StatUserFile: %w", err)
+	}
+
+	return info, nil
+}
+
+// FindUserContent путь и описание содержимого с хешем hash, доступного пользователю userID: на него ссылается
+// файл или вложение пользователя, либо пользователь сам загрузил его и загрузка еще не истекла.
+// Содержимое других пользователей не отличается от отсутствующего: file.ErrNotFound, так по хешу нельзя
+// узнать, хранит ли кто-то файл, и сослаться на чужое содержимое, не передав его.
+func (s *Service) FindUserContent(ctx context.Context, userID int, hash string) (filePath string, info file.BlobInfo, err error) {
+	filePath, info, err = s.StoreFiles.StatContent(ctx, hash)
+	if err != nil {
+		return "", info, fmt.Errorf("service.FindUserContent: %w", err)
+	}
+
+	refs, err := s.Store.CountUserFileRefs(ctx, userID, filePath)
+	if err != nil {
+		return "", info, fmt.Errorf("service.FindUserContent: %w", err)
+	}
+
+	if refs > 0 {
+		return filePath, info, nil
+	}
+
+	_, err = s.Store.FindCompletedUpload(ctx, userID, hash, time.Now())
+	if errors.Is(err, storage.ErrorNotFound) {
+		return "", file.BlobInfo{}, fmt.Errorf("service.FindUserContent: %w", file.ErrNotFound)
+	}
+
+	if err != nil {
+		return "", info, fmt.Errorf("service.FindUserContent: %w", err)
+	}
+
+	return filePath, info, nil
+}
+
+// contentHash хеш SHA-256 содержимого файла по его пути, клиент сверяет с ним скачанный файл.
+func contentHash(filePath string) string {
+	return file.ContentHash(filePath)
+}
+
 func (s *Service) FindFile(ctx context.Context, fileID, userID int) (file model.DataFile, err error) {
 	return s.Store.FindFile(ctx, fileID, userID)
 }
 
-func (s *Service) FindAllFiles(ctx context.Context, userID int) (files []model.DataFile, err error) {
-	return s.Store.FindAllFiles(ctx, userID)
+func (s *Service) FindAllFiles(ctx context.Context, userID int, filter model.ItemFilter) (files []model.DataFile, err error) {
+	return s.Store.FindAllFiles(ctx, userID, filter)
 }
 
 func (s *Service) SaveCred(ctx context.Context, cred model.DataCred) (id int, err error) {
@@ -174,19 +319,34 @@ func (s *Service) SaveCred(ctx context.Context, cred model.DataCred) (id int, er
 		return id, fmt.Errorf("service.SaveCred: %w", err)
 	}
 
+	err = s.checkItemRefs(ctx, cred.UserID, cred.FolderID, cred.TagIDs)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCred: %w", err)
+	}
+
+	bytes, err := payloadGrowth(ctx, &cred, cred.ID, cred.UserID, s.Store.FindCred)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCred: %w", err)
+	}
+
+	err = s.checkQuota(ctx, cred.UserID, newItems(cred.ID), bytes)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCred: %w", err)
+	}
+
 	return s.Store.SaveCred(ctx, cred)
 }
 
 func (s *Service) DeleteCred(ctx context.Context, credID, userID int) (err error) {
-	return s.Store.DeleteCred(ctx, credID, userID)
+	return s.deleteItem(ctx, model.ItemTypeCred, credID, userID, s.Store.DeleteCred)
 }
 
 func (s *Service) FindCred(ctx context.Context, credID, userID int) (cred model.DataCred, err error) {
 	return s.Store.FindCred(ctx, credID, userID)
 }
 
-func (s *Service) FindAllCreds(ctx context.Context, userID int) (creds []model.DataCred, err error) {
-	return s.Store.FindAllCreds(ctx, userID)
+func (s *Service) FindAllCreds(ctx context.Context, userID int, filter model.ItemFilter) (creds []model.DataCred, err error) {
+	return s.Store.FindAllCreds(ctx, userID, filter)
 }
 
 func (s *Service) SaveText(ctx context.Context, text model.DataText) (id int, err error) {
@@ -195,17 +355,32 @@ func (s *Service) SaveText(ctx context.Context, text model.DataText) (id int, er
 		return id, fmt.Errorf("service.SaveText: %w", err)
 	}
 
+	err = s.checkItemRefs(ctx, text.UserID, text.FolderID, text.TagIDs)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveText: %w", err)
+	}
+
+	bytes, err := payloadGrowth(ctx, &text, text.ID, text.UserID, s.Store.FindText)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveText: %w", err)
+	}
+
+	err = s.checkQuota(ctx, text.UserID, newItems(text.ID), bytes)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveText: %w", err)
+	}
+
 	return s.Store.SaveText(ctx, text)
 }
 
 func (s *Service) DeleteText(ctx context.Context, textID, userID int) (err error) {
-	return s.Store.DeleteText(ctx, textID, userID)
+	return s.deleteItem(ctx, model.ItemTypeText, textID, userID, s.Store.DeleteText)
 }
 
 func (s *Service) FindText(ctx context.Context, textID, userID int) (text model.DataText, err error) {
 	return s.Store.FindText(ctx, textID, userID)
 }
 
-func (s *Service) FindAllTexts(ctx context.Context, userID int) (texts []model.DataText, err error) {
-	return s.Store.FindAllTexts(ctx, userID)
+func (s *Service) FindAllTexts(ctx context.Context, userID int, filter model.ItemFilter) (texts []model.DataText, err error) {
+	return s.Store.FindAllTexts(ctx, userID, filter)
 }
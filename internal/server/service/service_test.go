@@ -22,7 +22,7 @@ func TestNew(t *testing.T) {
 	ctx := context.Background()
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -66,7 +66,7 @@ func TestService_ClearExpiredRefreshTokens(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -126,7 +126,7 @@ func TestService_CreateSession(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -187,7 +187,7 @@ func TestService_DeleteCard(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -248,7 +248,7 @@ func TestService_DeleteCred(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -309,7 +309,7 @@ func TestService_DeleteFile(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -377,7 +377,7 @@ func TestService_DeleteText(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -441,7 +441,7 @@ func TestService_FindAllCards(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -485,7 +485,7 @@ func TestService_FindAllCards(t *testing.T) {
 				Cfg:          tt.fields.Cfg,
 				TokenManager: tt.fields.TokenManager,
 			}
-			gotCards, err := s.FindAllCards(tt.args.ctx, tt.args.userID)
+			gotCards, err := s.FindAllCards(tt.args.ctx, tt.args.userID, model.ItemFilter{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("FindAllCards() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -509,7 +509,7 @@ func TestService_FindAllCreds(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -553,7 +553,7 @@ func TestService_FindAllCreds(t *testing.T) {
 				Cfg:          tt.fields.Cfg,
 				TokenManager: tt.fields.TokenManager,
 			}
-			gotCreds, err := s.FindAllCreds(tt.args.ctx, tt.args.userID)
+			gotCreds, err := s.FindAllCreds(tt.args.ctx, tt.args.userID, model.ItemFilter{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("FindAllCreds() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -577,7 +577,7 @@ func TestService_FindAllFiles(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -621,7 +621,7 @@ func TestService_FindAllFiles(t *testing.T) {
 				Cfg:          tt.fields.Cfg,
 				TokenManager: tt.fields.TokenManager,
 			}
-			gotFiles, err := s.FindAllFiles(tt.args.ctx, tt.args.userID)
+			gotFiles, err := s.FindAllFiles(tt.args.ctx, tt.args.userID, model.ItemFilter{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("FindAllFiles() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -645,7 +645,7 @@ func TestService_FindAllTexts(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -689,7 +689,7 @@ func TestService_FindAllTexts(t *testing.T) {
 				Cfg:          tt.fields.Cfg,
 				TokenManager: tt.fields.TokenManager,
 			}
-			gotTexts, err := s.FindAllTexts(tt.args.ctx, tt.args.userID)
+			gotTexts, err := s.FindAllTexts(tt.args.ctx, tt.args.userID, model.ItemFilter{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("FindAllTexts() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -713,7 +713,7 @@ func TestService_FindCard(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -781,7 +781,7 @@ func TestService_FindCred(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -849,7 +849,7 @@ func TestService_FindFile(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -920,7 +920,7 @@ func TestService_FindText(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -991,7 +991,7 @@ func TestService_GetRefreshToken(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -1053,7 +1053,7 @@ func TestService_GetSignKey(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -1124,7 +1124,7 @@ func TestService_SaveCard(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -1194,7 +1194,7 @@ func TestService_SaveCred(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -1264,7 +1264,7 @@ func TestService_SaveFile(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -1334,7 +1334,7 @@ func TestService_SaveText(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -1404,7 +1404,7 @@ func TestService_SignIn(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
@@ -1479,7 +1479,7 @@ func TestService_SignUp(t *testing.T) {
 	}
 
 	store := storage.New(ctx, cfg.DatabaseDsn)
-	storeFiles, err := file.New(cfg.FileStorage)
+	storeFiles, err := file.New(t.TempDir())
 	if err != nil {
 		t.Error(err)
 	}
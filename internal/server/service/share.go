@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+)
+
+func (s *Service) SaveUserKeys(ctx context.Context, keys model.UserKeys) error {
+	err := keys.Validate()
+	if err != nil {
+		return fmt.Errorf("service.SaveUserKeys: %w", err)
+	}
+
+	return s.Store.SaveUserKeys(ctx, keys)
+}
+
+func (s *Service) FindUserKeys(ctx context.Context, userID int) (keys model.UserKeys, err error) {
+	return s.Store.FindUserKeys(ctx, userID)
+}
+
+func (s *Service) FindPublicKey(ctx context.Context, login string) (key model.PublicKey, err error) {
+	return s.Store.FindPublicKey(ctx, login)
+}
+
+// SaveShare открывает запись владельца получателю по логину; у получателя должны быть ключи.
+// Открыть запись самому себе нельзя.
+func (s *Service) SaveShare(ctx context.Context, share model.Share) (id int, err error) {
+	err = share.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveShare: %w", err)
+	}
+
+	pub, err := s.Store.FindPublicKey(ctx, share.Recipient)
+	if errors.Is(err, storage.ErrorNotFound) || (err == nil && pub.UserID == share.UserID) {
+		err = model.ErrShareRecipientInvalid
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("service.SaveShare: %w", err)
+	}
+	share.RecipientID = pub.UserID
+
+	err = s.findItem(ctx, share.UserID, share.ItemType, share.ItemID)
+	if errors.Is(err, storage.ErrorNotFound) {
+		err = model.ErrShareItemInvalid
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("service.SaveShare: %w", err)
+	}
+
+	bytes, err := payloadGrowth(ctx, &share, share.ID, share.UserID, s.findShare)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveShare: %w", err)
+	}
+
+	err = s.checkQuota(ctx, share.UserID, newItems(share.ID), bytes)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveShare: %w", err)
+	}
+
+	share.UpdatedAt = time.Now()
+
+	return s.Store.SaveShare(ctx, share)
+}
+
+// findShare доступ, открытый пользователем, по идентификатору; иначе storage.ErrorNotFound.
+func (s *Service) findShare(ctx context.Context, shareID, userID int) (share model.Share, err error) {
+	shares, err := s.Store.FindAllShares(ctx, userID)
+	if err != nil {
+		return share, err
+	}
+
+	for _, v := range shares {
+		if v.ID == shareID {
+			return v, nil
+		}
+	}
+
+	return share, storage.ErrorNotFound
+}
+
+func (s *Service) DeleteShare(ctx context.Context, shareID, userID int) error {
+	return s.Store.DeleteShare(ctx, shareID, userID)
+}
+
+func (s *Service) FindAllShares(ctx context.Context, userID int) (shares []model.Share, err error) {
+	return s.Store.FindAllShares(ctx, userID)
+}
+
+func (s *Service) FindAllIncomingShares(ctx context.Context, recipientID int) (shares []model.Share, err error) {
+	return s.Store.FindAllIncomingShares(ctx, recipientID)
+}
+
+// UpdateIncomingShare сохраняет запись, измененную получателем; доступно только с правом на запись.
+// Владелец применяет изменения к своей записи при синхронизации.
+func (s *Service) UpdateIncomingShare(ctx context.Context, share model.Share) error {
+	if share.Data == "" {
+		return fmt.Errorf("service.UpdateIncomingShare: %w", model.ErrShareDataEmpty)
+	}
+
+	shares, err := s.Store.FindAllIncomingShares(ctx, share.RecipientID)
+	if err != nil {
+		return fmt.Errorf("service.UpdateIncomingShare: %w", err)
+	}
+
+	for _, v := range shares {
+		if v.ID != share.ID {
+			continue
+		}
+
+		if v.Access != model.ShareAccessWrite {
+			return fmt.Errorf("service.UpdateIncomingShare: %w", ErrAccessDenied)
+		}
+
+		// данные записи хранятся у владельца и учитываются в его квоте
+		err = s.checkQuota(ctx, v.UserID, 0, int64(len(share.Data)-len(v.Data)))
+		if err != nil {
+			return fmt.Errorf("service.UpdateIncomingShare: %w", err)
+		}
+
+		share.UpdatedAt = time.Now()
+
+		return s.Store.UpdateShareData(ctx, share)
+	}
+
+	return fmt.Errorf("service.UpdateIncomingShare: %w", storage.ErrorNotFound)
+}
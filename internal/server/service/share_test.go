@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SaveShare(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := New(store, nil, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	ownerID, err := store.CreateUser(ctx, model.User{Login: "owner", Password: "password"})
+	require.NoError(t, err)
+	recipientID, err := store.CreateUser(ctx, model.User{Login: "recipient", Password: "password"})
+	require.NoError(t, err)
+	_, err = store.CreateUser(ctx, model.User{Login: "nokeys", Password: "password"})
+	require.NoError(t, err)
+
+	for _, id := range []int{ownerID, recipientID} {
+		require.NoError(t, s.SaveUserKeys(ctx, model.UserKeys{UserID: id, PublicKey: "public", PrivateKey: "private"}))
+	}
+
+	cardID, err := s.SaveCard(ctx, model.DataCard{UserID: ownerID, Title: "card", Number: "1234", Date: "12/30", Cvv: "123"})
+	require.NoError(t, err)
+
+	share := func(recipient string, itemID int) model.Share {
+		return model.Share{UserID: ownerID, Recipient: recipient, ItemType: model.ItemTypeCard, ItemID: itemID,
+			Access: model.ShareAccessRead, ItemKey: "key", OwnerKey: "key", Data: "data", UpdatedAt: time.Now().AddDate(1, 0, 0)}
+	}
+
+	tests := []struct {
+		name    string
+		share   model.Share
+		wantErr error
+	}{
+		{name: "ok", share: share("recipient", cardID)},
+		{name: "unknown recipient", share: share("unknown", cardID), wantErr: model.ErrShareRecipientInvalid},
+		{name: "recipient without keys", share: share("nokeys", cardID), wantErr: model.ErrShareRecipientInvalid},
+		{name: "self", share: share("owner", cardID), wantErr: model.ErrShareRecipientInvalid},
+		{name: "foreign item", share: share("recipient", cardID+1), wantErr: model.ErrShareItemInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.SaveShare(ctx, tt.share)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	// время изменения задает сервер, а не клиент
+	shares, err := s.FindAllShares(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	assert.WithinDuration(t, time.Now(), shares[0].UpdatedAt, time.Minute)
+}
+
+func TestService_UpdateIncomingShare(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := New(store, nil, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	ownerID, err := store.CreateUser(ctx, model.User{Login: "owner", Password: "password"})
+	require.NoError(t, err)
+	recipientID, err := store.CreateUser(ctx, model.User{Login: "recipient", Password: "password"})
+	require.NoError(t, err)
+	require.NoError(t, s.SaveUserKeys(ctx, model.UserKeys{UserID: recipientID, PublicKey: "public", PrivateKey: "private"}))
+
+	cardID, err := s.SaveCard(ctx, model.DataCard{UserID: ownerID, Title: "card", Number: "1234", Date: "12/30", Cvv: "123"})
+	require.NoError(t, err)
+	credID, err := s.SaveCred(ctx, model.DataCred{UserID: ownerID, Title: "cred", Username: "user", Password: "pass"})
+	require.NoError(t, err)
+
+	readID, err := s.SaveShare(ctx, model.Share{UserID: ownerID, Recipient: "recipient", ItemType: model.ItemTypeCard, ItemID: cardID,
+		Access: model.ShareAccessRead, ItemKey: "key", OwnerKey: "key", Data: "data"})
+	require.NoError(t, err)
+	writeID, err := s.SaveShare(ctx, model.Share{UserID: ownerID, Recipient: "recipient", ItemType: model.ItemTypeCred, ItemID: credID,
+		Access: model.ShareAccessWrite, ItemKey: "key", OwnerKey: "key", Data: "data"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		share   model.Share
+		wantErr error
+	}{
+		{name: "write access", share: model.Share{ID: writeID, RecipientID: recipientID, Data: "changed", UpdatedAt: time.Now().AddDate(1, 0, 0)}},
+		{name: "read access", share: model.Share{ID: readID, RecipientID: recipientID, Data: "changed"}, wantErr: ErrAccessDenied},
+		{name: "owner", share: model.Share{ID: writeID, RecipientID: ownerID, Data: "changed"}, wantErr: storage.ErrorNotFound},
+		{name: "empty data", share: model.Share{ID: writeID, RecipientID: recipientID}, wantErr: model.ErrShareDataEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.UpdateIncomingShare(ctx, tt.share)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	shares, err := s.FindAllShares(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, shares, 2)
+	assert.Equal(t, "data", shares[0].Data)
+	assert.Equal(t, "changed", shares[1].Data)
+	assert.WithinDuration(t, time.Now(), shares[1].UpdatedAt, time.Minute)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+)
+
+func (s *Service) SaveSSHKey(ctx context.Context, key model.DataSSHKey) (id int, err error) {
+	err = key.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveSSHKey: %w", err)
+	}
+
+	err = s.checkItemRefs(ctx, key.UserID, key.FolderID, key.TagIDs)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveSSHKey: %w", err)
+	}
+
+	bytes, err := payloadGrowth(ctx, &key, key.ID, key.UserID, s.Store.FindSSHKey)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveSSHKey: %w", err)
+	}
+
+	err = s.checkQuota(ctx, key.UserID, newItems(key.ID), bytes)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveSSHKey: %w", err)
+	}
+
+	return s.Store.SaveSSHKey(ctx, key)
+}
+
+func (s *Service) DeleteSSHKey(ctx context.Context, keyID, userID int) (err error) {
+	return s.deleteItem(ctx, model.ItemTypeSSH, keyID, userID, s.Store.DeleteSSHKey)
+}
+
+func (s *Service) FindSSHKey(ctx context.Context, keyID, userID int) (key model.DataSSHKey, err error) {
+	return s.Store.FindSSHKey(ctx, keyID, userID)
+}
+
+func (s *Service) FindAllSSHKeys(ctx context.Context, userID int, filter model.ItemFilter) (keys []model.DataSSHKey, err error) {
+	return s.Store.FindAllSSHKeys(ctx, userID, filter)
+}
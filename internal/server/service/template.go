@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+)
+
+func (s *Service) SaveTemplate(ctx context.Context, tpl model.Template) (id int, err error) {
+	err = tpl.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveTemplate: %w", err)
+	}
+
+	bytes, err := payloadGrowth(ctx, &tpl, tpl.ID, tpl.UserID, s.findTemplate)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveTemplate: %w", err)
+	}
+
+	err = s.checkQuota(ctx, tpl.UserID, newItems(tpl.ID), bytes)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveTemplate: %w", err)
+	}
+
+	return s.Store.SaveTemplate(ctx, tpl)
+}
+
+// findTemplate шаблон пользователя по идентификатору; иначе storage.ErrorNotFound.
+func (s *Service) findTemplate(ctx context.Context, tplID, userID int) (tpl model.Template, err error) {
+	templates, err := s.Store.FindAllTemplates(ctx, userID)
+	if err != nil {
+		return tpl, err
+	}
+
+	for _, v := range templates {
+		if v.ID == tplID {
+			return v, nil
+		}
+	}
+
+	return tpl, storage.ErrorNotFound
+}
+
+// DeleteTemplate удаляет шаблон; записи по нему и их вложения удаляются хранилищем, файлы вложений - здесь.
+func (s *Service) DeleteTemplate(ctx context.Context, tplID, userID int) (err error) {
+	items, err := s.Store.FindAllCustomItems(ctx, userID, model.ItemFilter{})
+	if err != nil {
+		return fmt.Errorf("service.DeleteTemplate: %w", err)
+	}
+
+	itemIDs := make(map[int]bool)
+	for _, v := range items {
+		if v.TemplateID == tplID {
+			itemIDs[v.ID] = true
+		}
+	}
+
+	atts, err := s.Store.FindAllAttachments(ctx, userID, model.AttachmentFilter{ItemType: model.ItemTypeCustom})
+	if err != nil {
+		return fmt.Errorf("service.DeleteTemplate: %w", err)
+	}
+
+	var tplAtts []model.Attachment
+	for _, v := range atts {
+		if itemIDs[v.ItemID] {
+			tplAtts = append(tplAtts, v)
+		}
+	}
+
+	err = s.Store.DeleteTemplate(ctx, tplID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteAttachmentFiles(ctx, tplAtts)
+}
+
+func (s *Service) FindAllTemplates(ctx context.Context, userID int) (templates []model.Template, err error) {
+	return s.Store.FindAllTemplates(ctx, userID)
+}
+
+func (s *Service) SaveCustomItem(ctx context.Context, item model.DataCustom) (id int, err error) {
+	err = item.Validate()
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCustomItem: %w", err)
+	}
+
+	err = s.checkTemplate(ctx, item.UserID, item.TemplateID)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCustomItem: %w", err)
+	}
+
+	err = s.checkItemRefs(ctx, item.UserID, item.FolderID, item.TagIDs)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCustomItem: %w", err)
+	}
+
+	bytes, err := payloadGrowth(ctx, &item, item.ID, item.UserID, s.Store.FindCustomItem)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCustomItem: %w", err)
+	}
+
+	err = s.checkQuota(ctx, item.UserID, newItems(item.ID), bytes)
+	if err != nil {
+		return id, fmt.Errorf("service.SaveCustomItem: %w", err)
+	}
+
+	return s.Store.SaveCustomItem(ctx, item)
+}
+
+// checkTemplate проверяет, что шаблон записи принадлежит её владельцу.
+func (s *Service) checkTemplate(ctx context.Context, userID, tplID int) error {
+	templates, err := s.Store.FindAllTemplates(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range templates {
+		if t.ID == tplID {
+			return nil
+		}
+	}
+
+	return model.ErrItemTemplateInvalid
+}
+
+func (s *Service) DeleteCustomItem(ctx context.Context, itemID, userID int) (err error) {
+	return s.deleteItem(ctx, model.ItemTypeCustom, itemID, userID, s.Store.DeleteCustomItem)
+}
+
+func (s *Service) FindCustomItem(ctx context.Context, itemID, userID int) (item model.DataCustom, err error) {
+	return s.Store.FindCustomItem(ctx, itemID, userID)
+}
+
+func (s *Service) FindAllCustomItems(ctx context.Context, userID int, filter model.ItemFilter) (items []model.DataCustom, err error) {
+	return s.Store.FindAllCustomItems(ctx, userID, filter)
+}
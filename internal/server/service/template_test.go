@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SaveCustomItem(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	s := New(store, nil, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+	otherID, err := store.CreateUser(ctx, model.User{Login: "other", Password: "password"})
+	require.NoError(t, err)
+
+	tplID, err := s.SaveTemplate(ctx, model.Template{UserID: userID, Name: "db", Schema: "schema"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		item    model.DataCustom
+		wantErr error
+	}{
+		{name: "own template", item: model.DataCustom{UserID: userID, TemplateID: tplID, Title: "db", Values: "v"}},
+		{name: "foreign template", item: model.DataCustom{UserID: otherID, TemplateID: tplID, Title: "db", Values: "v"}, wantErr: model.ErrItemTemplateInvalid},
+		{name: "unknown template", item: model.DataCustom{UserID: userID, TemplateID: tplID + 1, Title: "db", Values: "v"}, wantErr: model.ErrItemTemplateInvalid},
+		{name: "invalid item", item: model.DataCustom{UserID: userID, TemplateID: tplID, Title: "db"}, wantErr: model.ErrDataCustomValuesEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.SaveCustomItem(ctx, tt.item)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
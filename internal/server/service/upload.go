@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/rainset/gophkeeper/pkg/hash"
+)
+
+// uploadIDSize размер случайного идентификатора загрузки в байтах.
+const uploadIDSize = 18
+
+// CreateUpload открывает сессию возобновляемой загрузки файла размером upload.Size.
+// Размер файла и квота проверяются сразу, чтобы не принимать части, которые потом не удастся сохранить.
+func (s *Service) CreateUpload(ctx context.Context, upload model.Upload) (model.Upload, error) {
+	err := upload.Validate()
+	if err != nil {
+		return upload, fmt.Errorf("service.CreateUpload: %w", err)
+	}
+
+	if err = s.checkFileSize(upload.Size); err != nil {
+		return upload, fmt.Errorf("service.CreateUpload: %w", err)
+	}
+
+	if err = s.checkQuota(ctx, upload.UserID, 0, upload.Size); err != nil {
+		return upload, fmt.Errorf("service.CreateUpload: %w", err)
+	}
+
+	upload.ID, err = hash.GenerateRandomString(uploadIDSize)
+	if err != nil {
+		return upload, fmt.Errorf("service.CreateUpload: %w", err)
+	}
+
+	now := time.Now()
+	upload.ChunkSize = s.uploadChunkSize()
+	upload.Offset = 0
+	upload.ExpiresAt = now.Add(model.UploadTTL)
+	upload.CreatedAt = now
+
+	if err = s.Store.SaveUpload(ctx, upload); err != nil {
+		return upload, fmt.Errorf("service.CreateUpload: %w", err)
+	}
+
+	return upload, nil
+}
+
+func (s *Service) FindUpload(ctx context.Context, uploadID string, userID int) (model.Upload, error) {
+	return s.Store.FindUpload(ctx, uploadID, userID)
+}
+
+// WriteUploadChunk принимает очередную часть загрузки, начинающуюся со смещения offset, и возвращает
+// загрузку с новым смещением. Части принимаются строго по порядку: если offset не совпадает с текущим
+// смещением - ErrUploadOffset, клиент должен запросить загрузку и продолжить с ее смещения.
+func (s *Service) WriteUploadChunk(ctx context.Context, uploadID string, userID int, offset int64, src io.Reader, size int64) (model.Upload, error) {
+	upload, err := s.Store.FindUpload(ctx, uploadID, userID)
+	if err != nil {
+		return upload, fmt.Errorf("service.WriteUploadChunk: %w", err)
+	}
+
+	if offset < 0 {
+		return upload, fmt.Errorf("service.WriteUploadChunk: %w", model.ErrUploadOffsetInvalid)
+	}
+
+	if offset != upload.Offset {
+		return upload, fmt.Errorf("service.WriteUploadChunk: %w", ErrUploadOffset)
+	}
+
+	if size <= 0 || size != upload.NextChunk() {
+		return upload, fmt.Errorf("service.WriteUploadChunk: %w", model.ErrUploadChunkInvalid)
+	}
+
+	err = s.StoreFiles.SaveUploadPart(ctx, upload.ID, upload.Parts(), src, size)
+	if errors.Is(err, file.ErrChunkSize) {
+		return upload, fmt.Errorf("service.WriteUploadChunk: %w", model.ErrUploadChunkInvalid)
+	}
+
+	if err != nil {
+		return upload, fmt.Errorf("service.WriteUploadChunk: %w", err)
+	}
+
+	next := upload
+	next.Offset += size
+	next.ExpiresAt = time.Now().Add(model.UploadTTL)
+
+	err = s.Store.UpdateUploadOffset(ctx, next, upload.Offset)
+	if errors.Is(err, storage.ErrorNotFound) {
+		// ту же часть параллельно записал другой запрос
+		return upload, fmt.Errorf("service.WriteUploadChunk: %w", ErrUploadOffset)
+	}
+
+	if err != nil {
+		return upload, fmt.Errorf("service.WriteUploadChunk: %w", err)
+	}
+
+	return next, nil
+}
+
+// CompleteUpload собирает принятые части в файл и сверяет SHA-256 содержимого с sha256.
+// При несовпадении хеша загрузка удаляется. При успехе удаляются только части, а загрузка остается
+// завершенной с проверенным хешем до истечения срока: сохраненное содержимое клиент затем указывает
+// в SaveFile по хешу, см. FindUserContent. Повторное завершение с тем же хешем не ошибка.
+func (s *Service) CompleteUpload(ctx context.Context, uploadID string, userID int, sha256 string) (model.Content, error) {
+	upload, err := s.Store.FindUpload(ctx, uploadID, userID)
+	if err != nil {
+		return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", err)
+	}
+
+	if upload.SHA256 != "" {
+		return s.completedUpload(ctx, upload, sha256)
+	}
+
+	if upload.Offset != upload.Size {
+		return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", ErrUploadIncomplete)
+	}
+
+	_, err = s.StoreFiles.CompleteUpload(ctx, upload.ID, upload.Parts(), upload.Size, sha256)
+	switch {
+	case errors.Is(err, file.ErrInvalidHash):
+		return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", model.ErrUploadHashInvalid)
+	case errors.Is(err, file.ErrChecksumMismatch):
+		if errDel := s.removeUpload(ctx, upload); errDel != nil {
+			return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", errDel)
+		}
+
+		return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", ErrUploadChecksum)
+	case err != nil:
+		return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", err)
+	}
+
+	if err = s.StoreFiles.DeleteUpload(ctx, upload.ID, upload.Parts()+1); err != nil {
+		return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", err)
+	}
+
+	upload.SHA256 = sha256
+	upload.ExpiresAt = time.Now().Add(model.UploadTTL)
+
+	// параллельный запрос уже завершил ту же загрузку: части собраны и хеш проверен дважды
+	err = s.Store.CompleteUpload(ctx, upload)
+	if err != nil && !errors.Is(err, storage.ErrorNotFound) {
+		return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", err)
+	}
+
+	return model.Content{SHA256: sha256, Size: upload.Size}, nil
+}
+
+// completedUpload отвечает на повторное завершение загрузки. Если содержимое уже удалено сборкой мусора,
+// удаляется и загрузка: клиент получит storage.ErrorNotFound и начнет загрузку заново.
+func (s *Service) completedUpload(ctx context.Context, upload model.Upload, sha256 string) (model.Content, error) {
+	if upload.SHA256 != sha256 {
+		return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", ErrUploadChecksum)
+	}
+
+	_, _, err := s.StoreFiles.StatContent(ctx, sha256)
+	if errors.Is(err, file.ErrNotFound) {
+		if err = s.removeUpload(ctx, upload); err != nil {
+			return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", err)
+		}
+
+		return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", storage.ErrorNotFound)
+	}
+
+	if err != nil {
+		return model.Content{}, fmt.Errorf("service.CompleteUpload: %w", err)
+	}
+
+	return model.Content{SHA256: sha256, Size: upload.Size}, nil
+}
+
+// DeleteUpload прерывает загрузку и удаляет принятые части.
+func (s *Service) DeleteUpload(ctx context.Context, uploadID string, userID int) error {
+	upload, err := s.Store.FindUpload(ctx, uploadID, userID)
+	if err != nil {
+		return fmt.Errorf("service.DeleteUpload: %w", err)
+	}
+
+	if err = s.removeUpload(ctx, upload); err != nil {
+		return fmt.Errorf("service.DeleteUpload: %w", err)
+	}
+
+	return nil
+}
+
+// ClearExpiredUploads удаляет брошенные загрузки вместе с принятыми частями.
+func (s *Service) ClearExpiredUploads(ctx context.Context) error {
+	uploads, err := s.Store.FindExpiredUploads(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("service.ClearExpiredUploads: %w", err)
+	}
+
+	for _, upload := range uploads {
+		if err = s.removeUpload(ctx, upload); err != nil {
+			return fmt.Errorf("service.ClearExpiredUploads: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// removeUpload удаляет части загрузки, затем саму загрузку; запись, удаленная параллельно, не ошибка.
+// Удаляется и часть после смещения: она могла быть записана запросом, не успевшим сдвинуть смещение.
+func (s *Service) removeUpload(ctx context.Context, upload model.Upload) error {
+	if err := s.StoreFiles.DeleteUpload(ctx, upload.ID, upload.Parts()+1); err != nil {
+		return err
+	}
+
+	err := s.Store.DeleteUpload(ctx, upload.ID, upload.UserID)
+	if err != nil && !errors.Is(err, storage.ErrorNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// uploadChunkSize размер части загрузки из настроек.
+func (s *Service) uploadChunkSize() int64 {
+	if s.Cfg.UploadChunkSize > 0 {
+		return s.Cfg.UploadChunkSize
+	}
+
+	return model.DefaultUploadChunkSize
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Upload(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+	s := New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key", UploadChunkSize: 4, QuotaFileSize: 100})
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	const content = "Hello, world!"
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+
+	_, err = s.CreateUpload(ctx, model.Upload{UserID: userID, Size: 101})
+	assert.ErrorIs(t, err, ErrQuotaFileSize)
+
+	upload, err := s.CreateUpload(ctx, model.Upload{UserID: userID, Size: int64(len(content))})
+	require.NoError(t, err)
+	assert.NotEmpty(t, upload.ID)
+	assert.Equal(t, int64(4), upload.ChunkSize)
+
+	write := func(offset int64, chunk string) (model.Upload, error) {
+		return s.WriteUploadChunk(ctx, upload.ID, userID, offset, strings.NewReader(chunk), int64(len(chunk)))
+	}
+
+	upload, err = write(0, "Hell")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), upload.Offset)
+
+	// повтор уже принятой части и пропуск части
+	_, err = write(0, "Hell")
+	assert.ErrorIs(t, err, ErrUploadOffset)
+	_, err = write(8, "orld")
+	assert.ErrorIs(t, err, ErrUploadOffset)
+	_, err = write(4, "o, w!")
+	assert.ErrorIs(t, err, model.ErrUploadChunkInvalid)
+	_, err = s.WriteUploadChunk(ctx, upload.ID, userID, 4, strings.NewReader("o,"), 4)
+	assert.ErrorIs(t, err, model.ErrUploadChunkInvalid)
+
+	_, err = s.CompleteUpload(ctx, upload.ID, userID, hash)
+	assert.ErrorIs(t, err, ErrUploadIncomplete)
+
+	// после перезапуска клиент узнает смещение и продолжает с него
+	upload, err = s.FindUpload(ctx, upload.ID, userID)
+	require.NoError(t, err)
+	for _, chunk := range []string{"o, w", "orld", "!"} {
+		upload, err = write(upload.Offset, chunk)
+		require.NoError(t, err)
+	}
+
+	_, err = s.CompleteUpload(ctx, upload.ID, userID, "bad")
+	assert.ErrorIs(t, err, model.ErrUploadHashInvalid)
+
+	got, err := s.CompleteUpload(ctx, upload.ID, userID, hash)
+	require.NoError(t, err)
+	assert.Equal(t, model.Content{SHA256: hash, Size: int64(len(content))}, got)
+
+	// завершенная загрузка остается с проверенным хешем, повторное завершение возвращает то же содержимое
+	upload, err = s.FindUpload(ctx, upload.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, hash, upload.SHA256)
+	got, err = s.CompleteUpload(ctx, upload.ID, userID, hash)
+	require.NoError(t, err)
+	assert.Equal(t, model.Content{SHA256: hash, Size: int64(len(content))}, got)
+	_, err = s.CompleteUpload(ctx, upload.ID, userID, strings.Repeat("0", 64))
+	assert.ErrorIs(t, err, ErrUploadChecksum)
+
+	filePath, _, err := storeFiles.StatContent(ctx, hash)
+	require.NoError(t, err)
+	r, err := storeFiles.GetFile(ctx, filePath)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	r.Close()
+	assert.Equal(t, content, string(data))
+
+	// содержимое удалено сборкой мусора: загрузка удаляется, клиент начинает заново
+	require.NoError(t, storeFiles.DeleteFile(ctx, filePath))
+	_, err = s.CompleteUpload(ctx, upload.ID, userID, hash)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+	_, err = s.FindUpload(ctx, upload.ID, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+}
+
+func TestService_CompleteUpload_Checksum(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+	s := New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key"})
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	upload, err := s.CreateUpload(ctx, model.Upload{UserID: userID, Size: 4})
+	require.NoError(t, err)
+	assert.Equal(t, int64(model.DefaultUploadChunkSize), upload.ChunkSize)
+
+	_, err = s.WriteUploadChunk(ctx, upload.ID, userID, 0, strings.NewReader("data"), 4)
+	require.NoError(t, err)
+
+	_, err = s.CompleteUpload(ctx, upload.ID, userID, strings.Repeat("0", 64))
+	assert.ErrorIs(t, err, ErrUploadChecksum)
+
+	// загрузка с испорченным содержимым удаляется, начинать нужно заново
+	_, err = s.FindUpload(ctx, upload.ID, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+}
+
+func TestService_ClearExpiredUploads(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	storeFiles, err := file.NewTemp()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storeFiles.Close() })
+	s := New(store, storeFiles, &config.Config{JWTSecretKey: "test_secret_key", UploadChunkSize: 2})
+
+	userID, err := store.CreateUser(ctx, model.User{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	upload, err := s.CreateUpload(ctx, model.Upload{UserID: userID, Size: 4})
+	require.NoError(t, err)
+	upload, err = s.WriteUploadChunk(ctx, upload.ID, userID, 0, strings.NewReader("da"), 2)
+	require.NoError(t, err)
+
+	require.NoError(t, s.ClearExpiredUploads(ctx))
+	_, err = s.FindUpload(ctx, upload.ID, userID)
+	require.NoError(t, err)
+
+	upload.ExpiresAt = upload.CreatedAt
+	require.NoError(t, store.UpdateUploadOffset(ctx, upload, upload.Offset))
+	require.NoError(t, s.ClearExpiredUploads(ctx))
+
+	_, err = s.FindUpload(ctx, upload.ID, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+	assert.NoFileExists(t, filepath.Join(storeFiles.Path(), "uploads", upload.ID, "0"))
+}
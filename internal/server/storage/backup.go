@@ -0,0 +1,553 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrorRestoreNotEmpty    = errors.New("database is not empty")
+	ErrorBackupTableUnknown = errors.New("table is not covered by backup")
+	ErrorBackupDialect      = errors.New("backup was made from another database type")
+)
+
+// backupTable таблица, которая входит в резервную копию.
+type backupTable struct {
+	name string
+	// selfRef столбец со ссылкой на строку той же таблицы: при восстановлении заполняется после вставки всех строк
+	selfRef string
+}
+
+// backupTables таблицы резервной копии в порядке внешних ключей: таблица идет после тех, на которые ссылается.
+var backupTables = []backupTable{ //nolint:gochecknoglobals
+	{name: "users"},
+	{name: "user_keys"},
+	{name: "refresh_tokens"},
+	{name: "folders", selfRef: "parent_id"},
+	{name: "tags"},
+	{name: "templates"},
+	{name: "data_cards"},
+	{name: "data_creds"},
+	{name: "data_text"},
+	{name: "data_files"},
+	{name: "data_ssh_keys"},
+	{name: "data_identities"},
+	{name: "data_custom"},
+	{name: "item_tags"},
+	{name: "attachments"},
+	{name: "shares"},
+	{name: "organizations"},
+	{name: "org_members"},
+	{name: "collections"},
+	{name: "collection_keys"},
+	{name: "collection_items"},
+	{name: "emergency_access"},
+	{name: "secrets"},
+}
+
+// skipTables таблицы, которые не входят в резервную копию: служебные таблицы goose и SQLite,
+// незавершенные загрузки, части которых не переживают восстановление, и блокировки содержимого.
+var skipTables = map[string]bool{ //nolint:gochecknoglobals
+	"goose_db_version": true,
+	"sqlite_sequence":  true,
+	"uploads":          true,
+	"file_locks":       true,
+}
+
+// fileTables таблицы, строки которых ссылаются на содержимое в хранилище файлов (столбец path).
+var fileTables = map[string]bool{ //nolint:gochecknoglobals
+	"data_files":  true,
+	"attachments": true,
+}
+
+// DumpTable число строк таблицы в снимке.
+type DumpTable struct {
+	Name string
+	Rows int
+}
+
+// DumpInfo сведения о снимке БД.
+type DumpInfo struct {
+	// Dialect диалект БД: postgres или sqlite3; строки разных диалектов несовместимы
+	Dialect string
+	// SchemaVersion версия схемы (миграций), с которой сделан снимок
+	SchemaVersion int64
+	Tables        []DumpTable
+	// Paths пути файлов, на которые ссылаются строки снимка, без повторов
+	Paths []string
+}
+
+// Dump читает все таблицы резервной копии из согласованного снимка БД (одна транзакция только на чтение).
+// Для каждой таблицы open возвращает приемник, в который пишутся строки в формате JSON Lines: объект
+// столбцов на строку; после записи строк приемник закрывается. Таблицы в БД, не известные резервной копии,
+// - ошибка ErrorBackupTableUnknown: копия без них была бы неполной.
+func Dump(ctx context.Context, store Interface, open func(table string) (io.WriteCloser, error)) (info DumpInfo, err error) {
+	m, err := NewMigrator(store)
+	if err != nil {
+		return info, fmt.Errorf("backup.Dump: %w", err)
+	}
+	defer m.Close()
+
+	info.Dialect = m.dialect
+
+	info.SchemaVersion, _, err = m.Versions(ctx)
+	if err != nil {
+		return info, fmt.Errorf("backup.Dump: %w", err)
+	}
+
+	opts := &sql.TxOptions{ReadOnly: true}
+	if m.dialect == "postgres" {
+		opts.Isolation = sql.LevelRepeatableRead
+	}
+
+	tx, err := m.db.BeginTx(ctx, opts)
+	if err != nil {
+		return info, fmt.Errorf("backup.Dump: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err = checkTables(ctx, tx, m.dialect); err != nil {
+		return info, fmt.Errorf("backup.Dump: %w", err)
+	}
+
+	paths := make(map[string]bool)
+
+	for _, t := range backupTables {
+		rows, err := dumpTable(ctx, tx, m.dialect, t.name, open, paths)
+		if err != nil {
+			return info, fmt.Errorf("backup.Dump %s: %w", t.name, err)
+		}
+
+		info.Tables = append(info.Tables, DumpTable{Name: t.name, Rows: rows})
+	}
+
+	for p := range paths {
+		info.Paths = append(info.Paths, p)
+	}
+	sort.Strings(info.Paths)
+
+	return info, nil
+}
+
+// dumpTable пишет строки таблицы в приемник open(table) и собирает в paths пути файлов.
+func dumpTable(ctx context.Context, tx *sql.Tx, dialect, table string,
+	open func(table string) (io.WriteCloser, error), paths map[string]bool,
+) (n int, err error) {
+	query := "SELECT to_jsonb(t)::text FROM " + quoteIdent(table) + " t"
+
+	if dialect != "postgres" {
+		cols, err := tableColumns(ctx, tx, table)
+		if err != nil {
+			return 0, err
+		}
+
+		pairs := make([]string, 0, len(cols))
+		for _, c := range cols {
+			pairs = append(pairs, quoteLiteral(c)+", "+quoteIdent(c))
+		}
+
+		query = "SELECT json_object(" + strings.Join(pairs, ", ") + ") FROM " + quoteIdent(table)
+	}
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	w, err := open(table)
+	if err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	for rows.Next() {
+		var row []byte
+		if err = rows.Scan(&row); err != nil {
+			w.Close()
+			return n, err
+		}
+
+		if fileTables[table] {
+			var ref struct {
+				Path string `json:"path"`
+			}
+			if err = json.Unmarshal(row, &ref); err != nil {
+				w.Close()
+				return n, err
+			}
+
+			if ref.Path != "" {
+				paths[ref.Path] = true
+			}
+		}
+
+		bw.Write(row)      //nolint:errcheck
+		bw.WriteByte('\n') //nolint:errcheck
+		n++
+	}
+
+	if err = rows.Err(); err == nil {
+		err = bw.Flush()
+	}
+
+	if errClose := w.Close(); err == nil {
+		err = errClose
+	}
+
+	return n, err
+}
+
+// Restorer загружает таблицы резервной копии в пустую БД одной транзакцией.
+type Restorer struct {
+	m  *Migrator
+	tx *sql.Tx
+	// deferred строки таблиц со ссылкой на ту же таблицу; ссылки заполняются в Commit
+	deferred []deferredRow
+}
+
+type deferredRow struct {
+	table string
+	row   []byte
+}
+
+// NewRestorer проверяет, что БД пуста и подходит для снимка info: тот же диалект, схема не старее снимка.
+// Схема пустой БД приводится к версии снимка, после загрузки применяются остальные миграции.
+func NewRestorer(ctx context.Context, store Interface, info DumpInfo) (*Restorer, error) {
+	m, err := NewMigrator(store)
+	if err != nil {
+		return nil, fmt.Errorf("backup.NewRestorer: %w", err)
+	}
+
+	r := &Restorer{m: m}
+	if err = r.prepare(ctx, info); err != nil {
+		m.Close()
+		return nil, fmt.Errorf("backup.NewRestorer: %w", err)
+	}
+
+	return r, nil
+}
+
+func (r *Restorer) prepare(ctx context.Context, info DumpInfo) error {
+	if info.Dialect != r.m.dialect {
+		return fmt.Errorf("%w: %s, target %s", ErrorBackupDialect, info.Dialect, r.m.dialect)
+	}
+
+	current, latest, err := r.m.Versions(ctx)
+	if err != nil {
+		return err
+	}
+
+	if info.SchemaVersion > latest {
+		return fmt.Errorf("%w: backup version %d, server version %d", ErrorSchemaTooNew, info.SchemaVersion, latest)
+	}
+
+	if current > 0 {
+		if err = r.checkEmpty(ctx); err != nil {
+			return err
+		}
+	}
+
+	return r.m.MigrateTo(ctx, info.SchemaVersion)
+}
+
+// checkEmpty возвращает ErrorRestoreNotEmpty, если в какой-либо таблице резервной копии есть строки.
+func (r *Restorer) checkEmpty(ctx context.Context) error {
+	existing, err := listTables(ctx, r.m.db, r.m.dialect)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range backupTables {
+		if !existing[t.name] {
+			continue
+		}
+
+		var exists bool
+
+		err = r.m.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+quoteIdent(t.name)+")").Scan(&exists)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return fmt.Errorf("%w: table %s has rows", ErrorRestoreNotEmpty, t.name)
+		}
+	}
+
+	return nil
+}
+
+// LoadTable загружает строки таблицы в формате Dump. Таблицы должны загружаться в порядке Dump.
+func (r *Restorer) LoadTable(ctx context.Context, table string, src io.Reader) (n int, err error) {
+	t, ok := findBackupTable(table)
+	if !ok {
+		return 0, fmt.Errorf("backup.LoadTable: %w: %s", ErrorBackupTableUnknown, table)
+	}
+
+	if r.tx == nil {
+		r.tx, err = r.m.db.BeginTx(ctx, nil)
+		if err != nil {
+			return 0, fmt.Errorf("backup.LoadTable: %w", err)
+		}
+	}
+
+	query := "INSERT INTO " + quoteIdent(table) + " SELECT * FROM jsonb_populate_record(NULL::" + quoteIdent(table) + ", $1::jsonb"
+	if t.selfRef != "" {
+		query += " - " + quoteLiteral(t.selfRef)
+	}
+	query += ")"
+
+	if r.m.dialect != "postgres" {
+		cols, err := tableColumns(ctx, r.tx, table)
+		if err != nil {
+			return 0, fmt.Errorf("backup.LoadTable %s: %w", table, err)
+		}
+
+		values := make([]string, 0, len(cols))
+		for i, c := range cols {
+			values = append(values, "json_extract(?1, "+quoteLiteral(jsonPath(c))+")")
+			if c == t.selfRef {
+				values[i] = "NULL"
+			}
+
+			cols[i] = quoteIdent(c)
+		}
+
+		query = "INSERT INTO " + quoteIdent(table) + " (" + strings.Join(cols, ", ") + ") VALUES (" + strings.Join(values, ", ") + ")"
+	}
+
+	stmt, err := r.tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("backup.LoadTable %s: %w", table, err)
+	}
+	defer stmt.Close()
+
+	br := bufio.NewReader(src)
+
+	for {
+		row, err := br.ReadBytes('\n')
+		if len(row) > 0 && row[len(row)-1] == '\n' {
+			row = row[:len(row)-1]
+		}
+
+		if len(row) > 0 {
+			if _, errExec := stmt.ExecContext(ctx, string(row)); errExec != nil {
+				return n, fmt.Errorf("backup.LoadTable %s row %d: %w", table, n+1, errExec)
+			}
+
+			if t.selfRef != "" {
+				r.deferred = append(r.deferred, deferredRow{table: table, row: row})
+			}
+
+			n++
+		}
+
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+
+		if err != nil {
+			return n, fmt.Errorf("backup.LoadTable %s: %w", table, err)
+		}
+	}
+}
+
+// Commit заполняет отложенные ссылки, продолжает последовательности идентификаторов после загруженных
+// значений, фиксирует транзакцию и применяет миграции новее снимка.
+func (r *Restorer) Commit(ctx context.Context) (err error) {
+	if r.tx == nil {
+		return fmt.Errorf("backup.Commit: %w", sql.ErrTxDone)
+	}
+
+	for _, d := range r.deferred {
+		t, _ := findBackupTable(d.table)
+
+		query := "UPDATE " + quoteIdent(t.name) + " SET " + quoteIdent(t.selfRef) + " = r." + quoteIdent(t.selfRef) +
+			" FROM jsonb_populate_record(NULL::" + quoteIdent(t.name) + ", $1::jsonb) r WHERE " + quoteIdent(t.name) + ".id = r.id"
+		if r.m.dialect != "postgres" {
+			query = "UPDATE " + quoteIdent(t.name) + " SET " + quoteIdent(t.selfRef) + " = json_extract(?1, " +
+				quoteLiteral(jsonPath(t.selfRef)) + ") WHERE id = json_extract(?1, '$.id')"
+		}
+
+		if _, err = r.tx.ExecContext(ctx, query, string(d.row)); err != nil {
+			return fmt.Errorf("backup.Commit %s: %w", t.name, err)
+		}
+	}
+
+	if r.m.dialect == "postgres" {
+		if err = resetSequences(ctx, r.tx); err != nil {
+			return fmt.Errorf("backup.Commit: %w", err)
+		}
+	}
+
+	err = r.tx.Commit()
+	r.tx = nil
+
+	if err != nil {
+		return fmt.Errorf("backup.Commit: %w", err)
+	}
+
+	return r.m.Up(ctx)
+}
+
+// Close откатывает незафиксированную загрузку.
+func (r *Restorer) Close() error {
+	if r.tx != nil {
+		r.tx.Rollback() //nolint:errcheck
+		r.tx = nil
+	}
+
+	return r.m.Close()
+}
+
+// resetSequences продолжает последовательности столбцов serial после максимальных загруженных значений.
+func resetSequences(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND column_default LIKE 'nextval(%'`)
+	if err != nil {
+		return err
+	}
+
+	var serials [][2]string
+
+	for rows.Next() {
+		var table, column string
+		if err = rows.Scan(&table, &column); err != nil {
+			rows.Close()
+			return err
+		}
+
+		serials = append(serials, [2]string{table, column})
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range serials {
+		query := "SELECT setval(pg_get_serial_sequence($1, $2), COALESCE(MAX(" + quoteIdent(s[1]) + "), 0) + 1, false) FROM " +
+			quoteIdent(s[0])
+		if _, err = tx.ExecContext(ctx, query, s[0], s[1]); err != nil {
+			return fmt.Errorf("%s.%s: %w", s[0], s[1], err)
+		}
+	}
+
+	return nil
+}
+
+// checkTables возвращает ErrorBackupTableUnknown, если в БД есть таблица, о которой не знает резервная копия.
+func checkTables(ctx context.Context, q queryer, dialect string) error {
+	existing, err := listTables(ctx, q, dialect)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range backupTables {
+		delete(existing, t.name)
+	}
+
+	for name := range existing {
+		if !skipTables[name] {
+			return fmt.Errorf("%w: %s", ErrorBackupTableUnknown, name)
+		}
+	}
+
+	return nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// listTables возвращает таблицы БД.
+func listTables(ctx context.Context, q queryer, dialect string) (map[string]bool, error) {
+	query := `SELECT name FROM sqlite_master WHERE type = 'table'`
+	if dialect == "postgres" {
+		query = `SELECT table_name FROM information_schema.tables
+			WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'`
+	}
+
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make(map[string]bool)
+
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		tables[name] = true
+	}
+
+	return tables, rows.Err()
+}
+
+// tableColumns возвращает столбцы таблицы SQLite в порядке объявления.
+func tableColumns(ctx context.Context, q queryer, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name FROM pragma_table_info(?) ORDER BY cid", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols []string
+
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		cols = append(cols, name)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrorBackupTableUnknown, table)
+	}
+
+	return cols, nil
+}
+
+func findBackupTable(name string) (backupTable, bool) {
+	for _, t := range backupTables {
+		if t.name == name {
+			return t, true
+		}
+	}
+
+	return backupTable{}, false
+}
+
+// quoteIdent заключает имя таблицы или столбца в двойные кавычки.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteLiteral заключает строку в одинарные кавычки.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// jsonPath путь JSON к ключу объекта для json_extract.
+func jsonPath(key string) string {
+	return `$."` + key + `"`
+}
@@ -0,0 +1,185 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// dumpTables снимает дамп хранилища в буферы по таблицам.
+func dumpTables(t *testing.T, store storage.Interface) (storage.DumpInfo, map[string]*bytes.Buffer) {
+	t.Helper()
+
+	tables := make(map[string]*bytes.Buffer)
+
+	info, err := storage.Dump(context.Background(), store, func(table string) (io.WriteCloser, error) {
+		tables[table] = &bytes.Buffer{}
+		return nopWriteCloser{tables[table]}, nil
+	})
+	require.NoError(t, err)
+
+	return info, tables
+}
+
+func TestDumpRestore(t *testing.T) {
+	ctx := context.Background()
+
+	src := openMigrated(t, "sqlite://"+filepath.Join(t.TempDir(), "src.db"))
+	defer src.Close()
+
+	userID, err := src.CreateUser(ctx, storageUser)
+	require.NoError(t, err)
+
+	parentID, err := src.SaveFolder(ctx, model.Folder{UserID: userID, Name: "parent"})
+	require.NoError(t, err)
+
+	_, err = src.SaveFolder(ctx, model.Folder{UserID: userID, ParentID: parentID, Name: "child"})
+	require.NoError(t, err)
+
+	_, err = src.SaveText(ctx, model.DataText{UserID: userID, Title: "note", Text: "line 1\nline 2 \"quoted\""})
+	require.NoError(t, err)
+
+	fileID, err := src.SaveFile(ctx, model.DataFile{UserID: userID, Title: "doc", Filename: "doc.txt", Path: "_file_storage/a"})
+	require.NoError(t, err)
+
+	_, err = src.SaveAttachment(ctx, model.Attachment{
+		UserID: userID, ItemType: "files", ItemID: fileID, Filename: "att.txt", Path: "_file_storage/b",
+	})
+	require.NoError(t, err)
+
+	info, tables := dumpTables(t, src)
+	assert.Equal(t, "sqlite3", info.Dialect)
+	assert.Positive(t, info.SchemaVersion)
+	assert.Equal(t, []string{"_file_storage/a", "_file_storage/b"}, info.Paths)
+	assert.Contains(t, info.Tables, storage.DumpTable{Name: "folders", Rows: 2})
+
+	dst := openMigrated(t, "sqlite://"+filepath.Join(t.TempDir(), "dst.db"))
+	defer dst.Close()
+
+	r, err := storage.NewRestorer(ctx, dst, info)
+	require.NoError(t, err)
+	defer r.Close()
+
+	for _, table := range info.Tables {
+		n, err := r.LoadTable(ctx, table.Name, tables[table.Name])
+		require.NoError(t, err)
+		assert.Equal(t, table.Rows, n, table.Name)
+	}
+
+	require.NoError(t, r.Commit(ctx))
+
+	id, err := dst.GetUserIDByCredentials(ctx, storageUser.Login, storageUser.Password)
+	require.NoError(t, err)
+	assert.Equal(t, userID, id)
+
+	wantFolders, err := src.FindAllFolders(ctx, userID)
+	require.NoError(t, err)
+	gotFolders, err := dst.FindAllFolders(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, wantFolders, gotFolders)
+
+	wantTexts, err := src.FindAllTexts(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	gotTexts, err := dst.FindAllTexts(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, wantTexts, gotTexts)
+
+	wantFiles, err := src.FindAllFiles(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	gotFiles, err := dst.FindAllFiles(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, wantFiles, gotFiles)
+
+	// идентификаторы новых записей продолжаются после восстановленных
+	newID, err := dst.CreateUser(ctx, model.User{Login: "other", Password: "password"})
+	require.NoError(t, err)
+	assert.Greater(t, newID, userID)
+
+	// повторное восстановление в непустую БД
+	_, err = storage.NewRestorer(ctx, dst, info)
+	assert.ErrorIs(t, err, storage.ErrorRestoreNotEmpty)
+}
+
+func TestNewRestorer(t *testing.T) {
+	ctx := context.Background()
+
+	src := openMigrated(t, "sqlite://"+filepath.Join(t.TempDir(), "src.db"))
+	defer src.Close()
+
+	info, _ := dumpTables(t, src)
+
+	tests := []struct {
+		name    string
+		info    storage.DumpInfo
+		wantErr error
+	}{
+		{name: "same version", info: info},
+		{name: "older version", info: storage.DumpInfo{Dialect: info.Dialect, SchemaVersion: 20230308120000}},
+		{name: "newer version", info: storage.DumpInfo{Dialect: info.Dialect, SchemaVersion: info.SchemaVersion + 1}, wantErr: storage.ErrorSchemaTooNew},
+		{name: "other dialect", info: storage.DumpInfo{Dialect: "postgres", SchemaVersion: info.SchemaVersion}, wantErr: storage.ErrorBackupDialect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst, err := storage.Open(ctx, "sqlite://"+filepath.Join(t.TempDir(), "dst.db"))
+			require.NoError(t, err)
+			defer dst.Close()
+
+			r, err := storage.NewRestorer(ctx, dst, tt.info)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			defer r.Close()
+
+			m, err := storage.NewMigrator(dst)
+			require.NoError(t, err)
+			defer m.Close()
+
+			current, _, err := m.Versions(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.info.SchemaVersion, current)
+		})
+	}
+}
+
+func TestDump_UnknownTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "src.db")
+
+	store := openMigrated(t, "sqlite://"+path)
+	defer store.Close()
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec("create table extra (id integer)")
+	require.NoError(t, err)
+
+	_, err = storage.Dump(context.Background(), store, func(table string) (io.WriteCloser, error) {
+		return nopWriteCloser{io.Discard}, nil
+	})
+	assert.ErrorIs(t, err, storage.ErrorBackupTableUnknown)
+}
+
+func TestDump_Memory(t *testing.T) {
+	_, err := storage.Dump(context.Background(), storage.NewMemory(), func(table string) (io.WriteCloser, error) {
+		return nopWriteCloser{io.Discard}, nil
+	})
+	assert.ErrorIs(t, err, storage.ErrorMigrationsUnsupported)
+}
@@ -4,7 +4,10 @@ import "errors"
 
 var (
 	ErrorRowAlreadyExists = errors.New("row already exists")
+	ErrorNotFound         = errors.New("row not found")
 
 	ErrorUserAlreadyExists = errors.New("user already exists")
 	ErrorUserCredentials   = errors.New("wrong pair login/password")
+
+	ErrorUnknownDSN = errors.New("unknown database dsn scheme")
 )
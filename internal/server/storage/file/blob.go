@@ -0,0 +1,73 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotFound содержимое по ключу отсутствует в хранилище; совместима с fs.ErrNotExist.
+var ErrNotFound = fmt.Errorf("blob %w", fs.ErrNotExist)
+
+// BlobStore хранилище содержимого файлов по ключу. Ключ - относительный путь из сегментов через "/".
+// Реализации: LocalStore (каталог на диске) и S3Store (S3-совместимое объектное хранилище).
+type BlobStore interface {
+	// Put сохраняет содержимое src под ключом key, заменяя прежнее; size - размер, -1 если неизвестен.
+	Put(ctx context.Context, key string, src io.Reader, size int64) error
+	// Get открывает содержимое для чтения; ErrNotFound, если ключа нет.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// GetRange открывает для чтения length байт содержимого со смещения offset; ErrNotFound, если ключа нет.
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	// Stat размер и время изменения содержимого; ErrNotFound, если ключа нет.
+	Stat(ctx context.Context, key string) (BlobInfo, error)
+	// Touch обновляет время изменения содержимого на текущее; ErrNotFound, если ключа нет.
+	Touch(ctx context.Context, key string) error
+	// Delete удаляет содержимое; отсутствие ключа не ошибка.
+	Delete(ctx context.Context, key string) error
+	// Walk вызывает fn для каждого ключа хранилища с префиксом prefix ("" - все ключи);
+	// ошибка fn прерывает обход и возвращается из Walk.
+	Walk(ctx context.Context, prefix string, fn func(key string, info BlobInfo) error) error
+}
+
+// BlobInfo сведения о сохраненном содержимом.
+type BlobInfo struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// limitReadCloser читает из rc не больше n байт и закрывает rc.
+func limitReadCloser(rc io.ReadCloser, n int64) io.ReadCloser {
+	return struct {
+		io.Reader
+		io.Closer
+	}{Reader: io.LimitReader(rc, n), Closer: rc}
+}
+
+// Open открывает хранилище файлов по DSN: s3://... - S3-совместимое хранилище (см. ParseS3DSN),
+// иначе DSN - путь к каталогу на диске.
+func Open(ctx context.Context, dsn string) (repo *StorageFiles, err error) {
+	if !strings.HasPrefix(dsn, "s3://") {
+		return New(dsn)
+	}
+
+	cfg, err := ParseS3DSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("file.Open: %w", err)
+	}
+
+	store := NewS3Store(cfg, nil)
+	if err = store.CheckBucket(ctx); err != nil {
+		return nil, fmt.Errorf("file.Open: %w", err)
+	}
+
+	return NewWithStore(store), nil
+}
+
+// cleanKey очищает ключ от ".." и начального "/", чтобы он не выходил за пределы хранилища.
+func cleanKey(key string) string {
+	return strings.TrimPrefix(path.Clean("/"+key), "/")
+}
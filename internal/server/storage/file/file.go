@@ -1,113 +1,255 @@
 package file
 
 import (
-	"crypto/rand"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"io"
-	"io/fs"
-	"math/big"
 	"os"
-	"path/filepath"
+	"path"
+	"strings"
 )
 
+// URLPrefix префикс путей файлов, сохраняемых в data_files.path; по нему файлы отдаются клиенту
+// независимо от того, где хранится содержимое.
+const URLPrefix = "_file_storage"
+
+var (
+	ErrInvalidHash  = errors.New("invalid sha256 hash")
+	ErrSizeMismatch = errors.New("content size does not match declared size")
+)
+
+// StorageFiles файлы пользователей поверх хранилища BlobStore. Содержимое адресуется хешем SHA-256,
+// одинаковые файлы хранятся один раз; удалять содержимое можно только когда на него не ссылается ни одна запись.
 type StorageFiles struct {
-	path string
+	store BlobStore
+	path  string
+	temp  bool
 }
 
+// New хранилище файлов в каталоге path на диске.
 func New(path string) (repo *StorageFiles, err error) {
-	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
-		err := os.Mkdir(path, os.ModePerm)
-		if err != nil {
-			return nil, err
-		}
+	store, err := NewLocalStore(path)
+	if err != nil {
+		return nil, err
 	}
 
-	repo = &StorageFiles{path: path}
+	return &StorageFiles{store: store, path: path}, nil
+}
+
+// NewTemp создает хранилище во временном каталоге, который удаляется при Close.
+func NewTemp() (repo *StorageFiles, err error) {
+	path, err := os.MkdirTemp("", "gophkeeper_files")
+	if err != nil {
+		return nil, err
+	}
 
-	return repo, nil
+	return &StorageFiles{store: &LocalStore{path: path}, path: path, temp: true}, nil
 }
 
-// SaveFile сохраняет файл пользователя.
-func (repo StorageFiles) SaveFile(src io.ReadCloser) (filePath string, err error) {
-	filePath, err = repo.getPath()
+// NewWithStore хранилище файлов поверх произвольного BlobStore.
+func NewWithStore(store BlobStore) *StorageFiles {
+	if local, ok := store.(*LocalStore); ok {
+		return &StorageFiles{store: store, path: local.Path()}
+	}
+
+	return &StorageFiles{store: store}
+}
+
+// Path возвращает каталог хранилища на диске; пусто, если файлы хранятся не на диске.
+func (repo StorageFiles) Path() string {
+	return repo.path
+}
+
+// SaveFile сохраняет файл пользователя по хешу SHA-256 содержимого и возвращает путь вида
+// _file_storage/sha256/ab/cd/<hash>; size - размер содержимого, -1 если неизвестен.
+// Содержимое другого размера (например, оборванная передача) не сохраняется, ошибка ErrSizeMismatch.
+// Содержимое, которое уже есть в хранилище, повторно не записывается.
+func (repo StorageFiles) SaveFile(ctx context.Context, src io.Reader, size int64) (filePath string, err error) {
+	hash := sha256.New()
+
+	spool, n, err := spoolTemp(io.TeeReader(src, hash))
 	if err != nil {
 		return "", err
 	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
 
-	dst, err := os.Create(filePath)
+	if size >= 0 && n != size {
+		return "", ErrSizeMismatch
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	key := contentKey(sum)
+
+	exists, err := repo.hasContent(ctx, key, n)
+	if err != nil || exists {
+		return URLPrefix + "/" + key, err
+	}
+
+	err = repo.store.Put(ctx, key, spool, n)
 	if err != nil {
 		return "", err
 	}
-	defer dst.Close()
 
-	_, err = io.Copy(dst, src)
+	return URLPrefix + "/" + key, nil
+}
+
+// hasContent проверяет, что содержимое key размером size уже есть в хранилище. Содержимое другого размера
+// (обрезанное при сбое до атомарной записи) считается отсутствующим и перезаписывается.
+// Время изменения повторно используемого содержимого обновляется: срок ожидания сборки мусора
+// отсчитывается от последнего использования, а не от первой записи.
+func (repo StorageFiles) hasContent(ctx context.Context, key string, size int64) (bool, error) {
+	info, err := repo.store.Stat(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+
+	if err != nil || info.Size != size {
+		return false, err
+	}
+
+	err = repo.store.Touch(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		// удалено между проверками
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// TouchFile обновляет время изменения содержимого файла пользователя; ErrNotFound, если его нет в хранилище.
+func (repo StorageFiles) TouchFile(ctx context.Context, filePath string) error {
+	return repo.store.Touch(ctx, blobKey(filePath))
+}
+
+// StatContent сведения о содержимом с хешем SHA-256 hash; ErrNotFound, если его нет в хранилище.
+func (repo StorageFiles) StatContent(ctx context.Context, hash string) (filePath string, info BlobInfo, err error) {
+	if !validHash(hash) {
+		return "", info, ErrInvalidHash
+	}
+
+	key := contentKey(hash)
+	info, err = repo.store.Stat(ctx, key)
 	if err != nil {
-		return "", err
+		return "", info, err
 	}
 
-	return filePath, err
+	return URLPrefix + "/" + key, info, nil
 }
 
 // DeleteFile удаляет файл прользователя.
-func (repo StorageFiles) DeleteFile(filePath string) (err error) {
+func (repo StorageFiles) DeleteFile(ctx context.Context, filePath string) (err error) {
 	if filePath == "" {
 		return nil
 	}
 
-	err = os.Remove(filePath)
-	if !errors.Is(err, fs.ErrNotExist) {
-		return err
-	}
-
-	return nil
+	return repo.store.Delete(ctx, blobKey(filePath))
 }
 
 // GetFile возвращает файл пользователя.
-func (repo StorageFiles) GetFile(filePath string) (fileReader io.ReadCloser, err error) {
-	return os.Open(filePath)
+func (repo StorageFiles) GetFile(ctx context.Context, filePath string) (fileReader io.ReadCloser, err error) {
+	return repo.store.Get(ctx, blobKey(filePath))
+}
+
+// GetFileRange возвращает length байт файла пользователя со смещения offset.
+func (repo StorageFiles) GetFileRange(ctx context.Context, filePath string, offset, length int64) (fileReader io.ReadCloser, err error) {
+	return repo.store.GetRange(ctx, blobKey(filePath), offset, length)
 }
 
-// Close закрывает файловый репозиторий.
+// StatFile возвращает размер и время изменения файла пользователя.
+func (repo StorageFiles) StatFile(ctx context.Context, filePath string) (info BlobInfo, err error) {
+	return repo.store.Stat(ctx, blobKey(filePath))
+}
+
+// RestoreFile записывает содержимое файла пользователя под путем filePath из БД, например при восстановлении
+// из резервной копии. Содержимое другого размера не сохраняется, ошибка ErrSizeMismatch.
+func (repo StorageFiles) RestoreFile(ctx context.Context, filePath string, src io.Reader, size int64) error {
+	return repo.store.Put(ctx, blobKey(filePath), src, size)
+}
+
+// WalkFiles вызывает fn для всего содержимого хранилища, включая части незавершенных загрузок;
+// filePath в том же виде, что пути в БД после CleanPath.
+func (repo StorageFiles) WalkFiles(ctx context.Context, fn func(filePath string, info BlobInfo) error) error {
+	return repo.store.Walk(ctx, "", func(key string, info BlobInfo) error {
+		return fn(URLPrefix+"/"+key, info)
+	})
+}
+
+// Close закрывает файловый репозиторий, временный каталог удаляется вместе с файлами.
 func (repo StorageFiles) Close() error {
+	if repo.temp {
+		return os.RemoveAll(repo.path)
+	}
+
 	return nil
 }
 
-func (repo StorageFiles) getPath() (string, error) {
-	for {
-		bytes, err := generateRandomBytes(32)
-		if err != nil {
-			return "", err
-		}
+// blobKey переводит путь вида _file_storage/<key> в ключ хранилища.
+// Путь очищается от "..", пути без префикса (записанные до его появления) используются как ключ целиком.
+func blobKey(filePath string) string {
+	return cleanKey(strings.TrimPrefix(filePath, URLPrefix+"/"))
+}
 
-		targetDir := filepath.Join(repo.path, string(bytes[0:2]), string(bytes[2:4]), string(bytes[4:6]))
-		targetFile := filepath.Join(targetDir, string(bytes[6:]))
+// CleanPath приводит путь файла к виду _file_storage/<key>, в котором его возвращает WalkFiles.
+func CleanPath(filePath string) string {
+	return URLPrefix + "/" + blobKey(filePath)
+}
 
-		if _, err := os.Stat(targetFile); errors.Is(err, os.ErrNotExist) {
-			if _, err := os.Stat(targetDir); errors.Is(err, os.ErrNotExist) {
-				err := os.MkdirAll(targetDir, os.ModePerm)
-				if err != nil {
-					return "", nil
-				}
-			}
+// ContentHash хеш SHA-256 содержимого по пути вида _file_storage/sha256/ab/cd/<hash>;
+// пусто для путей, записанных до адресации содержимого по хешу.
+func ContentHash(filePath string) string {
+	key := blobKey(filePath)
+	hash := path.Base(key)
 
-			return targetFile, nil
-		}
+	if !validHash(hash) || key != contentKey(hash) {
+		return ""
 	}
+
+	return hash
 }
 
-func generateRandomBytes(n int) ([]byte, error) {
-	const letters = "0123456789abcdef"
+// contentKey ключ содержимого по хешу: sha256/ab/cd/<hash>, первые сегменты ограничивают число файлов в каталоге.
+func contentKey(hash string) string {
+	return "sha256/" + hash[0:2] + "/" + hash[2:4] + "/" + hash
+}
 
-	result := make([]byte, n)
+// validHash проверяет, что hash - SHA-256 в виде 64 шестнадцатеричных символов в нижнем регистре.
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
 
-	for i := 0; i < n; i++ {
-		num, err := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
-		if err != nil {
-			return nil, err
+	for i := 0; i < len(hash); i++ {
+		if !('0' <= hash[i] && hash[i] <= '9' || 'a' <= hash[i] && hash[i] <= 'f') {
+			return false
 		}
+	}
+
+	return true
+}
+
+// spoolTemp сохраняет поток во временный файл и возвращает его открытым с начала вместе с размером.
+func spoolTemp(src io.Reader) (f *os.File, size int64, err error) {
+	f, err = os.CreateTemp("", "gophkeeper_upload")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size, err = io.Copy(f, src)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
 
-		result[i] = letters[num.Int64()]
+		return nil, 0, err
 	}
 
-	return result, nil
+	return f, size, nil
 }
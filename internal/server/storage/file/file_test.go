@@ -1,10 +1,18 @@
 package file
 
 import (
-	"github.com/stretchr/testify/assert"
+	"context"
+	"errors"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
@@ -19,7 +27,7 @@ func TestNew(t *testing.T) {
 	}{
 		{
 			name: "new",
-			args: args{path: "_file_storage"},
+			args: args{path: filepath.Join(t.TempDir(), "files")},
 		},
 	}
 	for _, tt := range tests {
@@ -41,7 +49,7 @@ func TestStorageFiles_Close(t *testing.T) {
 	}{
 		{
 			name:   "close",
-			fields: fields{path: "_file_storage"},
+			fields: fields{path: t.TempDir()},
 		},
 	}
 	for _, tt := range tests {
@@ -56,145 +64,341 @@ func TestStorageFiles_Close(t *testing.T) {
 }
 
 func TestStorageFiles_DeleteFile(t *testing.T) {
-	type fields struct {
-		path string
-	}
 	type args struct {
 		filePath string
 	}
 	tests := []struct {
 		name    string
-		fields  fields
 		args    args
 		wantErr bool
 	}{
 		{
-			name:   "new",
-			fields: fields{path: "_file_storage"},
+			name: "empty path",
+		},
+		{
+			name: "missing file",
+			args: args{filePath: URLPrefix + "/aa/bb/cc/missing"},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo := StorageFiles{
-				path: tt.fields.path,
-			}
-			err := repo.DeleteFile(tt.args.filePath)
+			repo, err := New(t.TempDir())
+			require.NoError(t, err)
+			err = repo.DeleteFile(context.Background(), tt.args.filePath)
 			assert.NoError(t, err)
 		})
 	}
 }
 
 func TestStorageFiles_GetFile(t *testing.T) {
-	type fields struct {
-		path string
-	}
+	repo, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = repo.GetFile(context.Background(), URLPrefix+"/aa/bb/cc/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestStorageFiles_SaveFile(t *testing.T) {
 	type args struct {
-		filePath string
+		src  string
+		size int64
 	}
 	tests := []struct {
-		name           string
-		fields         fields
-		args           args
-		wantFileReader io.ReadCloser
-		wantErr        bool
+		name string
+		args args
 	}{
 		{
-			name:   "new",
-			args:   args{filePath: "file.go"},
-			fields: fields{path: "."},
+			name: "known size",
+			args: args{src: "Hello, world!", size: 13},
+		},
+		{
+			name: "unknown size",
+			args: args{src: "Hello, world!", size: -1},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo := StorageFiles{
-				path: tt.fields.path,
-			}
-			_, err := repo.GetFile(tt.args.filePath)
-			assert.NoError(t, err)
+			ctx := context.Background()
+			repo, err := New(t.TempDir())
+			require.NoError(t, err)
 
+			filePath, err := repo.SaveFile(ctx, strings.NewReader(tt.args.src), tt.args.size)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(filePath, URLPrefix+"/"))
+
+			info, err := repo.StatFile(ctx, filePath)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.args.src)), info.Size)
 		})
 	}
 }
 
-func TestStorageFiles_SaveFile(t *testing.T) {
+func TestNewTemp(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewTemp()
+	assert.NoError(t, err)
 
-	r := io.NopCloser(strings.NewReader("Hello, world!"))
+	filePath, err := repo.SaveFile(ctx, strings.NewReader("Hello, world!"), -1)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(filePath, URLPrefix+"/"))
 
-	type fields struct {
-		path string
-	}
-	type args struct {
-		src io.ReadCloser
-	}
+	r, err := repo.GetFile(ctx, filePath)
+	assert.NoError(t, err)
+	content, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	r.Close()
+	assert.Equal(t, "Hello, world!", string(content))
+
+	assert.NoError(t, repo.DeleteFile(ctx, filePath))
+	_, err = repo.GetFile(ctx, filePath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	assert.NoError(t, repo.Close())
+	_, err = os.Stat(repo.Path())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func Test_blobKey(t *testing.T) {
 	tests := []struct {
-		name         string
-		fields       fields
-		args         args
-		wantFilePath string
-		wantErr      bool
+		name     string
+		filePath string
+		want     string
 	}{
-		{
-			name:   "save file",
-			fields: fields{path: "_file_storage"},
-			args:   args{src: r},
-		},
+		{name: "prefixed", filePath: URLPrefix + "/aa/bb/cc/dd", want: "aa/bb/cc/dd"},
+		{name: "escape", filePath: URLPrefix + "/../../etc/passwd", want: "etc/passwd"},
+		{name: "legacy", filePath: "aa/bb/cc/dd", want: "aa/bb/cc/dd"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo := StorageFiles{
-				path: tt.fields.path,
-			}
-			_, err := repo.SaveFile(tt.args.src)
-			assert.NoError(t, err)
+			assert.Equal(t, tt.want, blobKey(tt.filePath))
 		})
 	}
 }
 
-func TestStorageFiles_getPath(t *testing.T) {
-	type fields struct {
-		path string
-	}
+func TestContentHash(t *testing.T) {
+	const hash = "315f5bdb76d078c43b8ac0064e4a0164612b1fce77c869345bfc94c75894edd3"
+
 	tests := []struct {
-		name    string
-		fields  fields
-		want    string
-		wantErr bool
+		name     string
+		filePath string
+		want     string
 	}{
-		{
-			name:   "get path",
-			fields: fields{path: "_file_storage"},
-		},
+		{name: "content", filePath: URLPrefix + "/sha256/31/5f/" + hash, want: hash},
+		{name: "legacy", filePath: URLPrefix + "/aa/bb/cc/dd"},
+		{name: "wrong prefix", filePath: URLPrefix + "/sha256/00/00/" + hash},
+		{name: "empty"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo := StorageFiles{
-				path: tt.fields.path,
-			}
-			_, err := repo.getPath()
-			assert.NoError(t, err)
+			assert.Equal(t, tt.want, ContentHash(tt.filePath))
 		})
 	}
 }
 
-func Test_generateRandomBytes(t *testing.T) {
-	type args struct {
-		n int
-	}
+func TestStorageFiles_WalkFiles(t *testing.T) {
+	ctx := context.Background()
+	repo, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	filePath, err := repo.SaveFile(ctx, strings.NewReader("Hello, world!"), -1)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveUploadPart(ctx, "upload", 0, strings.NewReader("part"), 4))
+
+	got := make(map[string]int64)
+	require.NoError(t, repo.WalkFiles(ctx, func(filePath string, info BlobInfo) error {
+		got[filePath] = info.Size
+
+		return nil
+	}))
+
+	assert.Equal(t, map[string]int64{filePath: 13, URLPrefix + "/uploads/upload/0": 4}, got)
+	assert.Equal(t, filePath, CleanPath(strings.TrimPrefix(filePath, URLPrefix+"/")))
+}
+
+func TestStorageFiles_SaveFile_Dedup(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := New(dir)
+	require.NoError(t, err)
+
+	// sha256("Hello, world!")
+	const hash = "315f5bdb76d078c43b8ac0064e4a0164612b1fce77c869345bfc94c75894edd3"
+
+	_, _, err = repo.StatContent(ctx, hash)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	first, err := repo.SaveFile(ctx, strings.NewReader("Hello, world!"), 13)
+	require.NoError(t, err)
+	assert.Equal(t, URLPrefix+"/sha256/31/5f/"+hash, first)
+
+	// повторное использование содержимого продлевает срок ожидания сборки мусора
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "sha256", "31", "5f", hash), old, old))
+
+	second, err := repo.SaveFile(ctx, strings.NewReader("Hello, world!"), -1)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	info, err := repo.StatFile(ctx, second)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), info.ModTime, time.Hour)
+
+	other, err := repo.SaveFile(ctx, strings.NewReader("other"), -1)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, other)
+
+	filePath, info, err := repo.StatContent(ctx, hash)
+	require.NoError(t, err)
+	assert.Equal(t, first, filePath)
+	assert.Equal(t, int64(13), info.Size)
+
+	_, _, err = repo.StatContent(ctx, "../../etc/passwd")
+	assert.ErrorIs(t, err, ErrInvalidHash)
+	_, _, err = repo.StatContent(ctx, strings.ToUpper(hash))
+	assert.ErrorIs(t, err, ErrInvalidHash)
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	testBlobStore(t, store)
+}
+
+func TestLocalStore_PutAtomic(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	const key = "aa/bb/blob"
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("first"), 5))
+
+	errBroken := errors.New("connection reset")
 	tests := []struct {
 		name    string
-		args    args
-		want    []byte
-		wantErr bool
+		src     io.Reader
+		size    int64
+		wantErr error
 	}{
-		{
-			name: "random generate",
-			args: args{n: 32},
-		},
+		{name: "broken source", src: io.MultiReader(strings.NewReader("sec"), iotest.ErrReader(errBroken)), size: -1, wantErr: errBroken},
+		{name: "shorter than size", src: strings.NewReader("sec"), size: 6, wantErr: ErrSizeMismatch},
+		{name: "longer than size", src: strings.NewReader("second"), size: 3, wantErr: ErrSizeMismatch},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := generateRandomBytes(tt.args.n)
-			assert.NoError(t, err)
+			assert.ErrorIs(t, store.Put(ctx, key, tt.src, tt.size), tt.wantErr)
+
+			// прежнее содержимое не тронуто, временных файлов не осталось
+			r, err := store.Get(ctx, key)
+			require.NoError(t, err)
+			content, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			assert.Equal(t, "first", string(content))
+
+			entries, err := os.ReadDir(filepath.Join(store.Path(), "aa", "bb"))
+			require.NoError(t, err)
+			assert.Len(t, entries, 1)
 		})
 	}
 }
+
+func TestStorageFiles_SaveFile_Size(t *testing.T) {
+	ctx := context.Background()
+	repo, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	// sha256("Hello, world!")
+	const hash = "315f5bdb76d078c43b8ac0064e4a0164612b1fce77c869345bfc94c75894edd3"
+
+	_, err = repo.SaveFile(ctx, strings.NewReader("Hello"), 13)
+	assert.ErrorIs(t, err, ErrSizeMismatch)
+	_, _, err = repo.StatContent(ctx, hash)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// обрезанное содержимое, записанное до атомарной записи, заменяется при повторной загрузке
+	require.NoError(t, repo.store.Put(ctx, contentKey(hash), strings.NewReader("Hello"), 5))
+
+	filePath, err := repo.SaveFile(ctx, strings.NewReader("Hello, world!"), 13)
+	require.NoError(t, err)
+	info, err := repo.StatFile(ctx, filePath)
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), info.Size)
+}
+
+// testBlobStore общие проверки реализаций BlobStore.
+func testBlobStore(t *testing.T, store BlobStore) {
+	ctx := context.Background()
+	key := "aa/bb/cc/blob"
+
+	_, err := store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Stat(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, key))
+
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("first"), 5))
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("second version"), -1))
+
+	info, err := store.Stat(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(14), info.Size)
+	assert.False(t, info.ModTime.IsZero())
+
+	require.NoError(t, store.Touch(ctx, key))
+	touched, err := store.Stat(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size, touched.Size)
+	assert.False(t, touched.ModTime.Before(info.ModTime))
+	assert.ErrorIs(t, store.Touch(ctx, "missing"), ErrNotFound)
+
+	r, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "second version", string(content))
+
+	r, err = store.GetRange(ctx, key, 7, 3)
+	require.NoError(t, err)
+	content, err = io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "ver", string(content))
+
+	_, err = store.GetRange(ctx, "missing", 0, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Put(ctx, "empty", strings.NewReader(""), 0))
+	info, err = store.Stat(ctx, "empty")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size)
+
+	require.NoError(t, store.Put(ctx, "aa/bc", strings.NewReader("other"), 5))
+
+	walk := func(prefix string) map[string]int64 {
+		keys := make(map[string]int64)
+		require.NoError(t, store.Walk(ctx, prefix, func(key string, info BlobInfo) error {
+			assert.False(t, info.ModTime.IsZero())
+			keys[key] = info.Size
+
+			return nil
+		}))
+
+		return keys
+	}
+	assert.Equal(t, map[string]int64{key: 14, "aa/bc": 5, "empty": 0}, walk(""))
+	assert.Equal(t, map[string]int64{key: 14}, walk("aa/bb/"))
+	assert.Equal(t, map[string]int64{key: 14, "aa/bc": 5}, walk("aa/b"))
+	assert.Empty(t, walk("missing/"))
+
+	errStop := errors.New("stop")
+	assert.ErrorIs(t, store.Walk(ctx, "", func(string, BlobInfo) error { return errStop }), errStop)
+
+	require.NoError(t, store.Delete(ctx, "aa/bc"))
+	require.NoError(t, store.Delete(ctx, "empty"))
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
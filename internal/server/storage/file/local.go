@@ -0,0 +1,207 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalStore хранилище содержимого в каталоге на диске, ключ - путь внутри каталога.
+type LocalStore struct {
+	path string
+}
+
+// NewLocalStore открывает каталог хранилища, создавая его при необходимости.
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &LocalStore{path: dir}, nil
+}
+
+// Path каталог хранилища на диске.
+func (s *LocalStore) Path() string {
+	return s.path
+}
+
+// Put записывает содержимое во временный файл рядом с итоговым, сбрасывает его на диск и переименовывает:
+// при сбое или обрыве передачи под ключом остается прежнее содержимое, а не обрезанный файл.
+// Если size известен, содержимое другого размера не сохраняется, ошибка ErrSizeMismatch.
+// Временные файлы, оставшиеся после сбоя процесса, не имеют ссылок в БД и удаляются сборкой мусора.
+func (s *LocalStore) Put(ctx context.Context, key string, src io.Reader, size int64) (err error) {
+	diskPath := s.diskPath(key)
+	dir := filepath.Dir(diskPath)
+
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(diskPath)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	n, err := io.Copy(tmp, src)
+	if err != nil {
+		return err
+	}
+
+	if size >= 0 && n != size {
+		return ErrSizeMismatch
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), diskPath); err != nil {
+		return err
+	}
+
+	syncDir(dir)
+
+	return nil
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(s.diskPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return f, err
+}
+
+func (s *LocalStore) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	f, err := os.Open(s.diskPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+
+		return nil, err
+	}
+
+	return limitReadCloser(f, length), nil
+}
+
+func (s *LocalStore) Stat(ctx context.Context, key string) (info BlobInfo, err error) {
+	fi, err := os.Stat(s.diskPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return info, ErrNotFound
+	}
+
+	if err != nil {
+		return info, err
+	}
+
+	return BlobInfo{Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
+
+func (s *LocalStore) Touch(ctx context.Context, key string) error {
+	now := time.Now()
+
+	err := os.Chtimes(s.diskPath(key), now, now)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.diskPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *LocalStore) Walk(ctx context.Context, prefix string, fn func(key string, info BlobInfo) error) error {
+	return filepath.WalkDir(s.path, func(diskPath string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			// каталог удален во время обхода или хранилище еще пусто
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(s.path, diskPath)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if d.IsDir() {
+			// каталоги вне префикса не обходим
+			if key != "." && !strings.HasPrefix(key+"/", prefix) && !strings.HasPrefix(prefix, key+"/") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		fi, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// удален во время обхода
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return fn(key, BlobInfo{Size: fi.Size(), ModTime: fi.ModTime()})
+	})
+}
+
+// syncDir сбрасывает на диск запись каталога, чтобы переименование пережило сбой питания.
+// Не везде поддерживается (например, в Windows), поэтому ошибки не возвращаются.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+
+	_ = d.Sync()
+	d.Close()
+}
+
+// diskPath путь на диске для ключа внутри каталога хранилища.
+func (s *LocalStore) diskPath(key string) string {
+	return filepath.Join(s.path, filepath.FromSlash(cleanKey(key)))
+}
@@ -0,0 +1,412 @@
+package file
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3DefaultRegion   = "us-east-1"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3DateLayout      = "20060102T150405Z"
+	s3ListPageSize    = 1000
+)
+
+var ErrS3DSNInvalid = errors.New("invalid s3 dsn")
+
+// S3Config параметры S3-совместимого хранилища (AWS S3, MinIO и т.п.).
+type S3Config struct {
+	Endpoint  string // host:port сервера
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	TLS       bool
+}
+
+// ParseS3DSN разбирает DSN вида s3://access_key:secret_key@host:port/bucket?region=us-east-1&tls=false.
+// Ключи в DSN экранируются как в URL; по умолчанию region=us-east-1, tls=true.
+func ParseS3DSN(dsn string) (cfg S3Config, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return cfg, fmt.Errorf("%w: %s", ErrS3DSNInvalid, err)
+	}
+
+	bucket := strings.Trim(u.Path, "/")
+	if u.Scheme != "s3" || u.Host == "" || bucket == "" || strings.Contains(bucket, "/") {
+		return cfg, fmt.Errorf("%w: expected s3://access_key:secret_key@host/bucket", ErrS3DSNInvalid)
+	}
+
+	cfg = S3Config{Endpoint: u.Host, Bucket: bucket, Region: s3DefaultRegion, TLS: true}
+	if u.User != nil {
+		cfg.AccessKey = u.User.Username()
+		cfg.SecretKey, _ = u.User.Password()
+	}
+
+	q := u.Query()
+	if region := q.Get("region"); region != "" {
+		cfg.Region = region
+	}
+
+	if v := q.Get("tls"); v != "" {
+		cfg.TLS, err = strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("%w: tls: %s", ErrS3DSNInvalid, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// S3Store хранилище содержимого в бакете S3-совместимого сервера. Запросы подписываются AWS Signature V4,
+// адресация объектов path-style: host/bucket/key. Содержимое передается потоком, без подписи тела.
+type S3Store struct {
+	cfg      S3Config
+	client   *http.Client
+	now      func() time.Time
+	pageSize int
+}
+
+// NewS3Store хранилище в бакете cfg.Bucket; client nil - http.DefaultClient.
+func NewS3Store(cfg S3Config, client *http.Client) *S3Store {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &S3Store{cfg: cfg, client: client, now: time.Now, pageSize: s3ListPageSize}
+}
+
+// CheckBucket проверяет доступность бакета.
+func (s *S3Store) CheckBucket(ctx context.Context) error {
+	res, err := s.do(ctx, http.MethodHead, "", nil, nil, nil, 0)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("s3 bucket %s not found", s.cfg.Bucket)
+	}
+
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, src io.Reader, size int64) error {
+	if size < 0 {
+		spool, n, err := spoolTemp(src)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			spool.Close()
+			os.Remove(spool.Name())
+		}()
+
+		src, size = spool, n
+	}
+
+	res, err := s.do(ctx, http.MethodPut, key, nil, nil, src, size)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	res, err := s.do(ctx, http.MethodGet, key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+// GetRange читает length байт со смещения offset заголовком Range. Если хранилище не поддерживает
+// Range и отдает объект целиком, лишнее пропускается на стороне клиента.
+func (s *S3Store) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)}}
+
+	res, err := s.do(ctx, http.MethodGet, key, nil, header, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusPartialContent {
+		return res.Body, nil
+	}
+
+	if _, err = io.CopyN(io.Discard, res.Body, offset); err != nil {
+		res.Body.Close()
+
+		return nil, err
+	}
+
+	return limitReadCloser(res.Body, length), nil
+}
+
+func (s *S3Store) Stat(ctx context.Context, key string) (info BlobInfo, err error) {
+	res, err := s.do(ctx, http.MethodHead, key, nil, nil, nil, 0)
+	if err != nil {
+		return info, err
+	}
+	res.Body.Close()
+
+	info.Size = res.ContentLength
+	info.ModTime, _ = http.ParseTime(res.Header.Get("Last-Modified"))
+
+	return info, nil
+}
+
+// Touch копирует объект сам в себя с заменой метаданных: так S3 обновляет Last-Modified без передачи содержимого.
+func (s *S3Store) Touch(ctx context.Context, key string) error {
+	header := http.Header{
+		"X-Amz-Copy-Source":        {s3Escape("/"+s.cfg.Bucket+"/"+cleanKey(key), false)},
+		"X-Amz-Metadata-Directive": {"REPLACE"},
+	}
+
+	res, err := s.do(ctx, http.MethodPut, key, nil, header, nil, 0)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	res, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil, 0)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+// Walk перебирает объекты бакета запросами ListObjectsV2 страницами по s.pageSize ключей.
+func (s *S3Store) Walk(ctx context.Context, prefix string, fn func(key string, info BlobInfo) error) error {
+	query := url.Values{"list-type": {"2"}, "max-keys": {strconv.Itoa(s.pageSize)}}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+
+	for {
+		res, err := s.do(ctx, http.MethodGet, "", query, nil, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		var list s3ListResult
+		err = xml.NewDecoder(res.Body).Decode(&list)
+		res.Body.Close()
+
+		if err != nil {
+			return fmt.Errorf("s3 list %s: %w", s.cfg.Bucket, err)
+		}
+
+		for _, v := range list.Contents {
+			if err = fn(v.Key, BlobInfo{Size: v.Size, ModTime: v.LastModified}); err != nil {
+				return err
+			}
+		}
+
+		if !list.IsTruncated || list.NextContinuationToken == "" {
+			return nil
+		}
+
+		query.Set("continuation-token", list.NextContinuationToken)
+	}
+}
+
+// s3ListResult ответ ListObjectsV2.
+type s3ListResult struct {
+	Contents              []s3ListEntry `xml:"Contents"`
+	IsTruncated           bool          `xml:"IsTruncated"`
+	NextContinuationToken string        `xml:"NextContinuationToken"`
+}
+
+// s3ListEntry объект в ответе ListObjectsV2.
+type s3ListEntry struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// s3Error тело ответа S3 с ошибкой.
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do выполняет подписанный запрос к объекту key (пустой key - к бакету) с параметрами query.
+// Ответ 404 - ErrNotFound, прочие коды кроме 2xx - ошибка с кодом S3.
+func (s *S3Store) do(ctx context.Context, method, key string, query url.Values, header http.Header, body io.Reader, size int64) (*http.Response, error) { //nolint:lll
+	scheme := "https"
+	if !s.cfg.TLS {
+		scheme = "http"
+	}
+
+	objectPath := "/" + s.cfg.Bucket
+	if key != "" {
+		objectPath += "/" + cleanKey(key)
+	}
+
+	u := &url.URL{Scheme: scheme, Host: s.cfg.Endpoint, Path: objectPath, RawPath: s3Escape(objectPath, false), RawQuery: s3CanonicalQuery(query)}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	if body != nil {
+		req.ContentLength = size
+		if size == 0 {
+			req.Body = http.NoBody
+		}
+	}
+
+	signV4(req, s.cfg, s.now())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s: %w", method, objectPath, err)
+	}
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res, nil
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	var s3Err s3Error
+	_ = xml.NewDecoder(io.LimitReader(res.Body, 64<<10)).Decode(&s3Err)
+
+	return nil, fmt.Errorf("s3 %s %s: %s %s %s", method, objectPath, res.Status, s3Err.Code, s3Err.Message)
+}
+
+// signV4 подписывает запрос AWS Signature V4 для сервиса s3: подписываются host и все заголовки запроса,
+// тело не подписывается (UNSIGNED-PAYLOAD), если X-Amz-Content-Sha256 не задан заранее.
+func signV4(req *http.Request, cfg S3Config, now time.Time) {
+	amzDate := now.UTC().Format(s3DateLayout)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	signedHeaders, signature := s3Signature(cfg, req.Method, req.URL.Path, req.URL.Query(), headers, amzDate)
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		cfg.AccessKey, s3Scope(cfg.Region, amzDate), signedHeaders, signature))
+}
+
+// s3Signature подпись запроса по каноническому представлению; headers - имена в нижнем регистре.
+func s3Signature(cfg S3Config, method, objectPath string, query url.Values, headers map[string]string, amzDate string) (signedHeaders, signature string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders = strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		s3Escape(objectPath, false),
+		s3CanonicalQuery(query),
+		canonicalHeaders.String(),
+		signedHeaders,
+		headers["x-amz-content-sha256"],
+	}, "\n")
+
+	scope := s3Scope(cfg.Region, amzDate)
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex(canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretKey), amzDate[:8])
+	for _, part := range []string{cfg.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	return signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func s3Scope(region, amzDate string) string {
+	return amzDate[:8] + "/" + region + "/s3/aws4_request"
+}
+
+// s3CanonicalQuery параметры запроса в каноническом виде SigV4; в том же виде они передаются в URL.
+func s3CanonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, s3Escape(name, true)+"="+s3Escape(v, true))
+		}
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape кодирует строку по правилам SigV4 (UriEncode): без изменений остаются только A-Z a-z 0-9 - _ . ~,
+// а "/" - еще и в пути объекта (encodeSlash false); в параметрах запроса "/" кодируется как %2F.
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~", c) >= 0 ||
+			c == '/' && !encodeSlash {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,171 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrChunkSize        = errors.New("upload chunk size mismatch")
+	ErrChecksumMismatch = errors.New("sha256 checksum mismatch")
+)
+
+// uploadPrefix префикс ключей частей загрузок.
+const uploadPrefix = "uploads/"
+
+// Части незавершенных загрузок хранятся рядом с содержимым под ключами uploads/<id>/<n>
+// и сразу пишутся в хранилище, без буферизации в памяти сервера.
+
+// SaveUploadPart записывает часть part загрузки id; src должен содержать ровно size байт.
+func (repo StorageFiles) SaveUploadPart(ctx context.Context, id string, part int, src io.Reader, size int64) error {
+	counter := &countingReader{r: io.LimitReader(src, size)}
+
+	err := repo.store.Put(ctx, uploadKey(id, part), counter, size)
+	// короткую часть S3Store отклоняет ошибкой клиента HTTP, LocalStore - ErrSizeMismatch
+	if errors.Is(err, ErrSizeMismatch) || counter.n != size {
+		err = ErrChunkSize
+	}
+
+	if err != nil {
+		_ = repo.store.Delete(ctx, uploadKey(id, part))
+
+		return err
+	}
+
+	return nil
+}
+
+// CompleteUpload собирает parts частей загрузки id в файл пользователя и возвращает его путь.
+// Содержимое сохраняется, только если его SHA-256 совпадает с hash, иначе - ErrChecksumMismatch.
+// Части загрузки не удаляются, см. DeleteUpload.
+func (repo StorageFiles) CompleteUpload(ctx context.Context, id string, parts int, size int64, hash string) (filePath string, err error) {
+	if !validHash(hash) {
+		return "", ErrInvalidHash
+	}
+
+	src := repo.openUpload(ctx, id, parts)
+	defer src.Close()
+
+	sum := sha256.New()
+	if _, err = io.Copy(sum, src); err != nil {
+		return "", err
+	}
+
+	if hex.EncodeToString(sum.Sum(nil)) != hash {
+		return "", ErrChecksumMismatch
+	}
+
+	key := contentKey(hash)
+
+	exists, err := repo.hasContent(ctx, key, size)
+	if err != nil || exists {
+		return URLPrefix + "/" + key, err
+	}
+
+	src = repo.openUpload(ctx, id, parts)
+	defer src.Close()
+
+	if err = repo.store.Put(ctx, key, src, size); err != nil {
+		return "", err
+	}
+
+	return URLPrefix + "/" + key, nil
+}
+
+// DeleteUpload удаляет parts частей загрузки id.
+func (repo StorageFiles) DeleteUpload(ctx context.Context, id string, parts int) error {
+	for part := 0; part < parts; part++ {
+		if err := repo.store.Delete(ctx, uploadKey(id, part)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// openUpload читает части загрузки подряд, каждая открывается только когда дочитана предыдущая.
+func (repo StorageFiles) openUpload(ctx context.Context, id string, parts int) io.ReadCloser {
+	return &partsReader{ctx: ctx, store: repo.store, id: id, parts: parts}
+}
+
+// uploadKey ключ части part загрузки id.
+func uploadKey(id string, part int) string {
+	return uploadPrefix + cleanKey(id) + "/" + strconv.Itoa(part)
+}
+
+// UploadID идентификатор загрузки по пути ее части _file_storage/uploads/<id>/<n>; пусто для прочих путей.
+func UploadID(filePath string) string {
+	key := blobKey(filePath)
+	if !strings.HasPrefix(key, uploadPrefix) {
+		return ""
+	}
+
+	id, _, _ := strings.Cut(strings.TrimPrefix(key, uploadPrefix), "/")
+
+	return id
+}
+
+type partsReader struct {
+	ctx   context.Context
+	store BlobStore
+	id    string
+	parts int
+
+	next    int
+	current io.ReadCloser
+}
+
+func (r *partsReader) Read(p []byte) (n int, err error) {
+	for {
+		if r.current == nil {
+			if r.next == r.parts {
+				return 0, io.EOF
+			}
+
+			r.current, err = r.store.Get(r.ctx, uploadKey(r.id, r.next))
+			if err != nil {
+				return 0, err
+			}
+			r.next++
+		}
+
+		n, err = r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			r.current.Close()
+			r.current = nil
+			err = nil
+		}
+
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+
+	err := r.current.Close()
+	r.current = nil
+
+	return err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (n int, err error) {
+	n, err = c.r.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,78 @@
+package file
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageFiles_Upload(t *testing.T) {
+	ctx := context.Background()
+	repo, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	// sha256("Hello, world!")
+	const hash = "315f5bdb76d078c43b8ac0064e4a0164612b1fce77c869345bfc94c75894edd3"
+
+	for i, chunk := range []string{"Hello", ", wor", "ld!"} {
+		require.NoError(t, repo.SaveUploadPart(ctx, "upload", i, strings.NewReader(chunk), int64(len(chunk))))
+	}
+
+	// часть короче заявленного размера не сохраняется
+	err = repo.SaveUploadPart(ctx, "upload", 3, strings.NewReader("abc"), 5)
+	assert.ErrorIs(t, err, ErrChunkSize)
+	_, err = repo.store.Stat(ctx, uploadKey("upload", 3))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = repo.CompleteUpload(ctx, "upload", 3, 13, strings.Repeat("0", 64))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	_, err = repo.CompleteUpload(ctx, "upload", 3, 13, "../../etc/passwd")
+	assert.ErrorIs(t, err, ErrInvalidHash)
+	_, err = repo.CompleteUpload(ctx, "upload", 4, 13, hash)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	filePath, err := repo.CompleteUpload(ctx, "upload", 3, 13, hash)
+	require.NoError(t, err)
+	assert.Equal(t, URLPrefix+"/sha256/31/5f/"+hash, filePath)
+
+	r, err := repo.GetFile(ctx, filePath)
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	r.Close()
+	assert.Equal(t, "Hello, world!", string(content))
+
+	require.NoError(t, repo.DeleteUpload(ctx, "upload", 3))
+	for i := 0; i < 3; i++ {
+		_, err = repo.store.Stat(ctx, uploadKey("upload", i))
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+}
+
+func Test_uploadKey(t *testing.T) {
+	assert.Equal(t, "uploads/abc/0", uploadKey("abc", 0))
+	assert.Equal(t, "uploads/etc/passwd/1", uploadKey("../../etc/passwd", 1))
+}
+
+func TestUploadID(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		want     string
+	}{
+		{name: "part", filePath: URLPrefix + "/uploads/abc/0", want: "abc"},
+		{name: "without prefix", filePath: "uploads/abc/1", want: "abc"},
+		{name: "content", filePath: URLPrefix + "/sha256/aa/bb/cc"},
+		{name: "escape", filePath: URLPrefix + "/../uploads/abc/0", want: "abc"},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UploadID(tt.filePath))
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+)
+
+// itemTables таблицы записей по типам, используются в связях с папками и метками.
+var itemTables = map[string]string{
+	model.ItemTypeCard:     "data_cards",
+	model.ItemTypeCred:     "data_creds",
+	model.ItemTypeText:     "data_text",
+	model.ItemTypeFile:     "data_files",
+	model.ItemTypeSSH:      "data_ssh_keys",
+	model.ItemTypeIdentity: "data_identities",
+	model.ItemTypeCustom:   "data_custom",
+}
+
+// nullID значение внешнего ключа для записи в БД: 0 означает отсутствие связи (NULL).
+func nullID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+
+	return &id
+}
+
+// normalizeTagIDs сортирует идентификаторы меток и убирает повторы.
+// Пустой набор - пустой срез, а не nil, чтобы в ответе API был [].
+func normalizeTagIDs(ids []int) []int {
+	res := make([]int, 0, len(ids))
+	seen := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		res = append(res, id)
+	}
+	sort.Ints(res)
+
+	return res
+}
+
+// normalizeFields копия пользовательских полей; пустой список - [], а не null.
+func normalizeFields(fields model.Fields) model.Fields {
+	res := make(model.Fields, len(fields))
+	copy(res, fields)
+
+	return res
+}
+
+// normalizeURLs копия адресов учетной записи; пустой список - [], а не null.
+func normalizeURLs(urls model.CredURLs) model.CredURLs {
+	res := make(model.CredURLs, len(urls))
+	copy(res, urls)
+
+	return res
+}
+
+// parseTagIDs разбирает список меток, собранный group_concat в SQLite.
+func parseTagIDs(s string) ([]int, error) {
+	if s == "" {
+		return []int{}, nil
+	}
+
+	parts := strings.Split(s, ",")
+	ids := make([]int, 0, len(parts))
+	for _, p := range parts {
+		id, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return normalizeTagIDs(ids), nil
+}
+
+// itemFilterSQL дополняет условие выборки записей типа itemType фильтром model.ItemFilter.
+// placeholder возвращает обозначение n-го параметра запроса ($n в postgres, ? в SQLite).
+func itemFilterSQL(itemType string, filter model.ItemFilter, args []any, placeholder func(n int) string) (string, []any) {
+	var where strings.Builder
+
+	if filter.FolderID != 0 {
+		args = append(args, filter.FolderID)
+		fmt.Fprintf(&where, " AND folder_id=%s", placeholder(len(args)))
+	}
+
+	if filter.TagID != 0 {
+		args = append(args, filter.TagID)
+		fmt.Fprintf(&where, " AND EXISTS (SELECT 1 FROM item_tags WHERE item_type='%s' AND item_id=%s.id AND tag_id=%s)",
+			itemType, itemTables[itemType], placeholder(len(args)))
+	}
+
+	return where.String(), args
+}
+
+// attachmentFilterSQL дополняет условие выборки вложений фильтром model.AttachmentFilter.
+func attachmentFilterSQL(filter model.AttachmentFilter, args []any, placeholder func(n int) string) (string, []any) {
+	var where strings.Builder
+
+	if filter.ItemType != "" {
+		args = append(args, filter.ItemType)
+		fmt.Fprintf(&where, " AND item_type=%s", placeholder(len(args)))
+	}
+
+	if filter.ItemID != 0 {
+		args = append(args, filter.ItemID)
+		fmt.Fprintf(&where, " AND item_id=%s", placeholder(len(args)))
+	}
+
+	return where.String(), args
+}
+
+// usageTables таблицы данных пользователя для квот и их столбцы, размер которых входит в объем: те же поля,
+// что учитывает PayloadSize моделей. К файлам и вложениям добавляется размер содержимого (size).
+// Каждая строка таблицы - одна запись в квоте числа записей.
+var usageTables = []struct { //nolint:gochecknoglobals
+	name    string
+	columns []string
+	content bool
+}{
+	{name: "data_cards", columns: []string{"title", "number", "date", "cvv", "meta", "fields"}},
+	{name: "data_creds", columns: []string{"title", "username", "password", "meta", "totp", "fields", "urls"}},
+	{name: "data_text", columns: []string{"title", "text", "meta", "fields"}},
+	{name: "data_files", columns: []string{"title", "filename", "meta", "fields"}, content: true},
+	{name: "data_ssh_keys", columns: []string{"title", "private_key", "public_key", "fingerprint", "passphrase", "meta", "fields"}},
+	{name: "data_identities", columns: []string{
+		"title", "kind", "full_name", "number", "country", "issue_date", "expiry_date", "address", "meta", "fields",
+	}},
+	{name: "data_custom", columns: []string{"title", `"values"`, "meta", "fields"}},
+	{name: "attachments", columns: []string{"filename"}, content: true},
+	{name: "folders", columns: []string{"name"}},
+	{name: "tags", columns: []string{"name"}},
+	{name: "templates", columns: []string{"name", "schema"}},
+	{name: "shares", columns: []string{"item_key", "owner_key", "data"}},
+	{name: "secrets", columns: []string{"data"}},
+	{name: "collection_items", columns: []string{"data"}},
+}
+
+// usageSQL запрос использования хранилища пользователем: объем данных и содержимого и число строк
+// таблиц usageTables. size - выражение размера столбца в байтах, в postgres и sqlite оно различается.
+func usageSQL(userID int, size func(column string) string, placeholder func(n int) string) (string, []any) {
+	var args []any
+	next := func() string {
+		args = append(args, userID)
+
+		return placeholder(len(args))
+	}
+
+	bytes := make([]string, 0, len(usageTables))
+	items := make([]string, 0, len(usageTables))
+
+	for _, t := range usageTables {
+		sizes := make([]string, 0, len(t.columns)+1)
+		for _, column := range t.columns {
+			sizes = append(sizes, size(column))
+		}
+
+		if t.content {
+			sizes = append(sizes, "size")
+		}
+
+		bytes = append(bytes, "SELECT COALESCE(SUM("+strings.Join(sizes, "+")+"),0) FROM "+t.name+" WHERE user_id="+next())
+	}
+
+	for _, t := range usageTables {
+		items = append(items, "SELECT COUNT(*) FROM "+t.name+" WHERE user_id="+next())
+	}
+
+	query := "SELECT CAST((" + strings.Join(bytes, ") + (") + ") AS bigint), CAST((" + strings.Join(items, ") + (") + ") AS bigint)"
+
+	return query, args
+}
+
+// fileRefsSQL запрос числа файлов и вложений с путем из параметров 1 и 2: содержимое хранится
+// по хешу и может быть общим для нескольких записей.
+func fileRefsSQL(placeholder func(n int) string) string {
+	return "SELECT CAST((SELECT COUNT(*) FROM data_files WHERE path=" + placeholder(1) +
+		") + (SELECT COUNT(*) FROM attachments WHERE path=" + placeholder(2) + ") AS bigint)"
+}
+
+// userFileRefsSQL запрос числа файлов и вложений пользователя: параметры 1 и 3 - пользователь, 2 и 4 - путь.
+func userFileRefsSQL(placeholder func(n int) string) string {
+	return "SELECT CAST((SELECT COUNT(*) FROM data_files WHERE user_id=" + placeholder(1) + " AND path=" + placeholder(2) +
+		") + (SELECT COUNT(*) FROM attachments WHERE user_id=" + placeholder(3) + " AND path=" + placeholder(4) + ") AS bigint)"
+}
+
+// fileRefsAllSQL запрос всех файлов и вложений с непустым путем к содержимому.
+const fileRefsAllSQL = "SELECT '" + model.FileRefTableFiles + "' AS \"table\", id, user_id, path FROM data_files WHERE path<>''" +
+	" UNION ALL SELECT '" + model.FileRefTableAttachments + "', id, user_id, path FROM attachments WHERE path<>''" +
+	" ORDER BY 1, 2"
+
+func pgPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func sqlitePlaceholder(int) string {
+	return "?"
+}
@@ -0,0 +1,1822 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/hash"
+)
+
+// memoryUser запись таблицы users.
+type memoryUser struct {
+	id       int
+	password string
+	signKey  string
+}
+
+// Memory хранилище в памяти процесса, для тестов и локального запуска без БД.
+// Данные теряются при остановке сервера.
+type Memory struct {
+	mu sync.RWMutex
+
+	// seq последовательности идентификаторов по таблицам, как serial в postgres
+	seq map[string]int
+
+	users  map[string]memoryUser
+	tokens []model.RefreshToken
+	cards  map[int]model.DataCard
+	creds  map[int]model.DataCred
+	texts  map[int]model.DataText
+	files  map[int]model.DataFile
+	ssh    map[int]model.DataSSHKey
+
+	identities map[int]model.DataIdentity
+	custom     map[int]model.DataCustom
+
+	folders   map[int]model.Folder
+	tags      map[int]model.Tag
+	templates map[int]model.Template
+
+	attachments map[int]model.Attachment
+
+	keys   map[int]model.UserKeys
+	shares map[int]model.Share
+
+	orgs            map[int]model.Organization
+	members         map[memberKey]model.Member
+	collections     map[int]model.Collection
+	collectionKeys  map[memberKey]string
+	collectionItems map[int]model.CollectionItem
+
+	emergency map[int]model.EmergencyAccess
+
+	secrets map[string]model.Secret
+	uploads map[string]model.Upload
+
+	// fileLocks блокировки содержимого по пути, канал закрывается при снятии; отдельно от mu,
+	// потому что fn под блокировкой обращается к хранилищу
+	fileLocksMu sync.Mutex
+	fileLocks   map[string]chan struct{}
+}
+
+// memberKey первичный ключ участника организации (org_id, user_id) или ключа коллекции (collection_id, user_id).
+type memberKey struct {
+	id     int
+	userID int
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		seq:   make(map[string]int),
+		users: make(map[string]memoryUser),
+		cards: make(map[int]model.DataCard),
+		creds: make(map[int]model.DataCred),
+		texts: make(map[int]model.DataText),
+		files: make(map[int]model.DataFile),
+		ssh:   make(map[int]model.DataSSHKey),
+
+		identities: make(map[int]model.DataIdentity),
+		custom:     make(map[int]model.DataCustom),
+
+		folders:   make(map[int]model.Folder),
+		tags:      make(map[int]model.Tag),
+		templates: make(map[int]model.Template),
+
+		attachments: make(map[int]model.Attachment),
+
+		keys:   make(map[int]model.UserKeys),
+		shares: make(map[int]model.Share),
+
+		orgs:            make(map[int]model.Organization),
+		members:         make(map[memberKey]model.Member),
+		collections:     make(map[int]model.Collection),
+		collectionKeys:  make(map[memberKey]string),
+		collectionItems: make(map[int]model.CollectionItem),
+
+		emergency: make(map[int]model.EmergencyAccess),
+
+		secrets: make(map[string]model.Secret),
+		uploads: make(map[string]model.Upload),
+
+		fileLocks: make(map[string]chan struct{}),
+	}
+}
+
+func (m *Memory) nextID(table string) int {
+	m.seq[table]++
+
+	return m.seq[table]
+}
+
+// sortedIDs идентификаторы записей пользователя по убыванию, как ORDER BY id DESC.
+func sortedIDs(ids []int) []int {
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+
+	return ids
+}
+
+// matchItem проверяет запись по фильтру списка, как условия itemFilterSQL.
+func matchItem(filter model.ItemFilter, folderID int, tagIDs []int) bool {
+	if filter.FolderID != 0 && folderID != filter.FolderID {
+		return false
+	}
+
+	if filter.TagID == 0 {
+		return true
+	}
+
+	for _, id := range tagIDs {
+		if id == filter.TagID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeTagID копия списка меток без tagID.
+func removeTagID(tagIDs []int, tagID int) []int {
+	res := make([]int, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		if id != tagID {
+			res = append(res, id)
+		}
+	}
+
+	return res
+}
+
+func (m *Memory) Close() {}
+
+func (m *Memory) CreateUser(ctx context.Context, user model.User) (userID int, err error) {
+	h, err := hash.GenerateRandom(2 * aes.BlockSize)
+	if err != nil {
+		return userID, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[user.Login]; ok {
+		return userID, ErrorUserAlreadyExists
+	}
+
+	userID = m.nextID("users")
+	m.users[user.Login] = memoryUser{id: userID, password: hash.Md5(user.Password), signKey: crypt.EncodeBase64(h)}
+
+	return userID, nil
+}
+
+func (m *Memory) GetUserIDByCredentials(ctx context.Context, login, password string) (userID int, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	u, ok := m.users[login]
+	if !ok || u.password != hash.Md5(password) {
+		return 0, ErrorUserCredentials
+	}
+
+	return u.id, nil
+}
+
+func (m *Memory) GetSignKey(ctx context.Context, login, password string) (signKey string, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	u, ok := m.users[login]
+	if !ok || u.password != hash.Md5(password) {
+		return signKey, ErrorUserCredentials
+	}
+
+	return u.signKey, nil
+}
+
+func (m *Memory) SetRefreshToken(ctx context.Context, refreshToken model.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	refreshToken.CreatedAt = time.Now()
+	m.tokens = append(m.tokens, refreshToken)
+
+	return nil
+}
+
+func (m *Memory) GetRefreshTokenUserID(ctx context.Context, token string) (userID int, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	for _, t := range m.tokens {
+		if t.Token == token && t.ExpiredAt.After(now) {
+			return t.UserID, nil
+		}
+	}
+
+	return userID, ErrorNotFound
+}
+
+func (m *Memory) ClearExpiredRefreshTokens(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	tokens := m.tokens[:0]
+	for _, t := range m.tokens {
+		if !t.ExpiredAt.Before(now) {
+			tokens = append(tokens, t)
+		}
+	}
+	m.tokens = tokens
+
+	return nil
+}
+
+func (m *Memory) SaveCard(ctx context.Context, card model.DataCard) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if card.ID == 0 {
+		card.ID = m.nextID("data_cards")
+	} else if v, ok := m.cards[card.ID]; !ok || v.UserID != card.UserID {
+		return card.ID, ErrorNotFound
+	}
+
+	card.TagIDs = normalizeTagIDs(card.TagIDs)
+	card.Fields = normalizeFields(card.Fields)
+	m.cards[card.ID] = card
+
+	return card.ID, nil
+}
+
+func (m *Memory) FindCard(ctx context.Context, cardID, userID int) (card model.DataCard, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	card, ok := m.cards[cardID]
+	if !ok || card.UserID != userID {
+		return model.DataCard{}, ErrorNotFound
+	}
+
+	return card, nil
+}
+
+func (m *Memory) FindAllCards(ctx context.Context, userID int, filter model.ItemFilter) (cards []model.DataCard, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.cards {
+		if v.UserID == userID && matchItem(filter, v.FolderID, v.TagIDs) {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range sortedIDs(ids) {
+		cards = append(cards, m.cards[id])
+	}
+
+	return cards, nil
+}
+
+func (m *Memory) DeleteCard(ctx context.Context, cardID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.cards[cardID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.cards, cardID)
+	m.deleteItemRefs(model.ItemTypeCard, cardID)
+
+	return nil
+}
+
+func (m *Memory) SaveFile(ctx context.Context, file model.DataFile) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if file.ID == 0 {
+		file.ID = m.nextID("data_files")
+	} else if v, ok := m.files[file.ID]; !ok || v.UserID != file.UserID {
+		return file.ID, ErrorNotFound
+	}
+
+	file.TagIDs = normalizeTagIDs(file.TagIDs)
+	file.Fields = normalizeFields(file.Fields)
+	m.files[file.ID] = file
+
+	return file.ID, nil
+}
+
+func (m *Memory) DeleteFile(ctx context.Context, fileID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.files[fileID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.files, fileID)
+	m.deleteItemRefs(model.ItemTypeFile, fileID)
+
+	return nil
+}
+
+func (m *Memory) FindFile(ctx context.Context, fileID, userID int) (file model.DataFile, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	file, ok := m.files[fileID]
+	if !ok || file.UserID != userID {
+		return model.DataFile{}, ErrorNotFound
+	}
+
+	return file, nil
+}
+
+func (m *Memory) FindAllFiles(ctx context.Context, userID int, filter model.ItemFilter) (files []model.DataFile, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.files {
+		if v.UserID == userID && matchItem(filter, v.FolderID, v.TagIDs) {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range sortedIDs(ids) {
+		files = append(files, m.files[id])
+	}
+
+	return files, nil
+}
+
+func (m *Memory) SaveCred(ctx context.Context, cred model.DataCred) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cred.ID == 0 {
+		cred.ID = m.nextID("data_creds")
+	} else if v, ok := m.creds[cred.ID]; !ok || v.UserID != cred.UserID {
+		return cred.ID, ErrorNotFound
+	}
+
+	cred.TagIDs = normalizeTagIDs(cred.TagIDs)
+	cred.Fields = normalizeFields(cred.Fields)
+	cred.URLs = normalizeURLs(cred.URLs)
+	m.creds[cred.ID] = cred
+
+	return cred.ID, nil
+}
+
+func (m *Memory) DeleteCred(ctx context.Context, credID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.creds[credID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.creds, credID)
+	m.deleteItemRefs(model.ItemTypeCred, credID)
+
+	return nil
+}
+
+func (m *Memory) FindCred(ctx context.Context, credID, userID int) (cred model.DataCred, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cred, ok := m.creds[credID]
+	if !ok || cred.UserID != userID {
+		return model.DataCred{}, ErrorNotFound
+	}
+
+	return cred, nil
+}
+
+func (m *Memory) FindAllCreds(ctx context.Context, userID int, filter model.ItemFilter) (creds []model.DataCred, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.creds {
+		if v.UserID == userID && matchItem(filter, v.FolderID, v.TagIDs) {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range sortedIDs(ids) {
+		creds = append(creds, m.creds[id])
+	}
+
+	return creds, nil
+}
+
+func (m *Memory) SaveText(ctx context.Context, text model.DataText) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if text.ID == 0 {
+		text.ID = m.nextID("data_text")
+	} else if v, ok := m.texts[text.ID]; !ok || v.UserID != text.UserID {
+		return text.ID, ErrorNotFound
+	}
+
+	text.TagIDs = normalizeTagIDs(text.TagIDs)
+	text.Fields = normalizeFields(text.Fields)
+	m.texts[text.ID] = text
+
+	return text.ID, nil
+}
+
+func (m *Memory) DeleteText(ctx context.Context, textID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.texts[textID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.texts, textID)
+	m.deleteItemRefs(model.ItemTypeText, textID)
+
+	return nil
+}
+
+func (m *Memory) FindText(ctx context.Context, textID, userID int) (text model.DataText, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	text, ok := m.texts[textID]
+	if !ok || text.UserID != userID {
+		return model.DataText{}, ErrorNotFound
+	}
+
+	return text, nil
+}
+
+func (m *Memory) FindAllTexts(ctx context.Context, userID int, filter model.ItemFilter) (texts []model.DataText, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.texts {
+		if v.UserID == userID && matchItem(filter, v.FolderID, v.TagIDs) {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range sortedIDs(ids) {
+		texts = append(texts, m.texts[id])
+	}
+
+	return texts, nil
+}
+
+func (m *Memory) SaveSSHKey(ctx context.Context, key model.DataSSHKey) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if key.ID == 0 {
+		key.ID = m.nextID("data_ssh_keys")
+	} else if v, ok := m.ssh[key.ID]; !ok || v.UserID != key.UserID {
+		return key.ID, ErrorNotFound
+	}
+
+	key.TagIDs = normalizeTagIDs(key.TagIDs)
+	key.Fields = normalizeFields(key.Fields)
+	m.ssh[key.ID] = key
+
+	return key.ID, nil
+}
+
+func (m *Memory) DeleteSSHKey(ctx context.Context, keyID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.ssh[keyID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.ssh, keyID)
+	m.deleteItemRefs(model.ItemTypeSSH, keyID)
+
+	return nil
+}
+
+func (m *Memory) FindSSHKey(ctx context.Context, keyID, userID int) (key model.DataSSHKey, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key, ok := m.ssh[keyID]
+	if !ok || key.UserID != userID {
+		return model.DataSSHKey{}, ErrorNotFound
+	}
+
+	return key, nil
+}
+
+func (m *Memory) FindAllSSHKeys(ctx context.Context, userID int, filter model.ItemFilter) (keys []model.DataSSHKey, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.ssh {
+		if v.UserID == userID && matchItem(filter, v.FolderID, v.TagIDs) {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range sortedIDs(ids) {
+		keys = append(keys, m.ssh[id])
+	}
+
+	return keys, nil
+}
+
+func (m *Memory) SaveIdentity(ctx context.Context, doc model.DataIdentity) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if doc.ID == 0 {
+		doc.ID = m.nextID("data_identities")
+	} else if v, ok := m.identities[doc.ID]; !ok || v.UserID != doc.UserID {
+		return doc.ID, ErrorNotFound
+	}
+
+	doc.TagIDs = normalizeTagIDs(doc.TagIDs)
+	doc.Fields = normalizeFields(doc.Fields)
+	m.identities[doc.ID] = doc
+
+	return doc.ID, nil
+}
+
+func (m *Memory) DeleteIdentity(ctx context.Context, docID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.identities[docID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.identities, docID)
+	m.deleteItemRefs(model.ItemTypeIdentity, docID)
+
+	return nil
+}
+
+func (m *Memory) FindIdentity(ctx context.Context, docID, userID int) (doc model.DataIdentity, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	doc, ok := m.identities[docID]
+	if !ok || doc.UserID != userID {
+		return model.DataIdentity{}, ErrorNotFound
+	}
+
+	return doc, nil
+}
+
+func (m *Memory) FindAllIdentities(ctx context.Context, userID int, filter model.ItemFilter) (docs []model.DataIdentity, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.identities {
+		if v.UserID == userID && matchItem(filter, v.FolderID, v.TagIDs) {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range sortedIDs(ids) {
+		docs = append(docs, m.identities[id])
+	}
+
+	return docs, nil
+}
+
+func (m *Memory) SaveCustomItem(ctx context.Context, item model.DataCustom) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if item.ID == 0 {
+		item.ID = m.nextID("data_custom")
+	} else if v, ok := m.custom[item.ID]; !ok || v.UserID != item.UserID {
+		return item.ID, ErrorNotFound
+	}
+
+	item.TagIDs = normalizeTagIDs(item.TagIDs)
+	item.Fields = normalizeFields(item.Fields)
+	m.custom[item.ID] = item
+
+	return item.ID, nil
+}
+
+func (m *Memory) DeleteCustomItem(ctx context.Context, itemID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.custom[itemID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.custom, itemID)
+	m.deleteItemRefs(model.ItemTypeCustom, itemID)
+
+	return nil
+}
+
+func (m *Memory) FindCustomItem(ctx context.Context, itemID, userID int) (item model.DataCustom, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	item, ok := m.custom[itemID]
+	if !ok || item.UserID != userID {
+		return model.DataCustom{}, ErrorNotFound
+	}
+
+	return item, nil
+}
+
+func (m *Memory) FindAllCustomItems(ctx context.Context, userID int, filter model.ItemFilter) (items []model.DataCustom, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.custom {
+		if v.UserID == userID && matchItem(filter, v.FolderID, v.TagIDs) {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range sortedIDs(ids) {
+		items = append(items, m.custom[id])
+	}
+
+	return items, nil
+}
+
+func (m *Memory) SaveFolder(ctx context.Context, folder model.Folder) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if folder.ID == 0 {
+		folder.ID = m.nextID("folders")
+	} else if v, ok := m.folders[folder.ID]; !ok || v.UserID != folder.UserID {
+		return folder.ID, ErrorNotFound
+	}
+
+	m.folders[folder.ID] = folder
+
+	return folder.ID, nil
+}
+
+// DeleteFolder удаляет папку вместе с вложенными и убирает удалённые папки из записей,
+// как внешние ключи on delete cascade/set null в БД.
+func (m *Memory) DeleteFolder(ctx context.Context, folderID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.folders[folderID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	deleted := map[int]bool{folderID: true}
+	for changed := true; changed; {
+		changed = false
+		for id, v := range m.folders {
+			if !deleted[id] && deleted[v.ParentID] {
+				deleted[id] = true
+				changed = true
+			}
+		}
+	}
+
+	for id := range deleted {
+		delete(m.folders, id)
+	}
+
+	for id, v := range m.cards {
+		if deleted[v.FolderID] {
+			v.FolderID = 0
+			m.cards[id] = v
+		}
+	}
+	for id, v := range m.creds {
+		if deleted[v.FolderID] {
+			v.FolderID = 0
+			m.creds[id] = v
+		}
+	}
+	for id, v := range m.texts {
+		if deleted[v.FolderID] {
+			v.FolderID = 0
+			m.texts[id] = v
+		}
+	}
+	for id, v := range m.files {
+		if deleted[v.FolderID] {
+			v.FolderID = 0
+			m.files[id] = v
+		}
+	}
+	for id, v := range m.ssh {
+		if deleted[v.FolderID] {
+			v.FolderID = 0
+			m.ssh[id] = v
+		}
+	}
+	for id, v := range m.identities {
+		if deleted[v.FolderID] {
+			v.FolderID = 0
+			m.identities[id] = v
+		}
+	}
+	for id, v := range m.custom {
+		if deleted[v.FolderID] {
+			v.FolderID = 0
+			m.custom[id] = v
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) FindAllFolders(ctx context.Context, userID int) (folders []model.Folder, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.folders {
+		if v.UserID == userID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		folders = append(folders, m.folders[id])
+	}
+
+	return folders, nil
+}
+
+func (m *Memory) SaveTag(ctx context.Context, tag model.Tag) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if tag.ID == 0 {
+		tag.ID = m.nextID("tags")
+	} else if v, ok := m.tags[tag.ID]; !ok || v.UserID != tag.UserID {
+		return tag.ID, ErrorNotFound
+	}
+
+	m.tags[tag.ID] = tag
+
+	return tag.ID, nil
+}
+
+// DeleteTag удаляет метку и её связи с записями.
+func (m *Memory) DeleteTag(ctx context.Context, tagID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.tags[tagID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.tags, tagID)
+
+	for id, v := range m.cards {
+		v.TagIDs = removeTagID(v.TagIDs, tagID)
+		m.cards[id] = v
+	}
+	for id, v := range m.creds {
+		v.TagIDs = removeTagID(v.TagIDs, tagID)
+		m.creds[id] = v
+	}
+	for id, v := range m.texts {
+		v.TagIDs = removeTagID(v.TagIDs, tagID)
+		m.texts[id] = v
+	}
+	for id, v := range m.files {
+		v.TagIDs = removeTagID(v.TagIDs, tagID)
+		m.files[id] = v
+	}
+	for id, v := range m.ssh {
+		v.TagIDs = removeTagID(v.TagIDs, tagID)
+		m.ssh[id] = v
+	}
+	for id, v := range m.identities {
+		v.TagIDs = removeTagID(v.TagIDs, tagID)
+		m.identities[id] = v
+	}
+	for id, v := range m.custom {
+		v.TagIDs = removeTagID(v.TagIDs, tagID)
+		m.custom[id] = v
+	}
+
+	return nil
+}
+
+func (m *Memory) FindAllTags(ctx context.Context, userID int) (tags []model.Tag, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.tags {
+		if v.UserID == userID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		tags = append(tags, m.tags[id])
+	}
+
+	return tags, nil
+}
+
+func (m *Memory) SaveTemplate(ctx context.Context, tpl model.Template) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if tpl.ID == 0 {
+		tpl.ID = m.nextID("templates")
+	} else if v, ok := m.templates[tpl.ID]; !ok || v.UserID != tpl.UserID {
+		return tpl.ID, ErrorNotFound
+	}
+
+	m.templates[tpl.ID] = tpl
+
+	return tpl.ID, nil
+}
+
+// DeleteTemplate удаляет шаблон вместе с записями по нему, их вложениями и доступами, как on delete cascade в БД.
+func (m *Memory) DeleteTemplate(ctx context.Context, tplID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.templates[tplID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.templates, tplID)
+
+	for id, v := range m.custom {
+		if v.TemplateID == tplID {
+			delete(m.custom, id)
+			m.deleteItemRefs(model.ItemTypeCustom, id)
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) FindAllTemplates(ctx context.Context, userID int) (templates []model.Template, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.templates {
+		if v.UserID == userID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		templates = append(templates, m.templates[id])
+	}
+
+	return templates, nil
+}
+
+// deleteItemRefs удаляет вложения и доступы записи вместе с ней, как deleteItem в БД. Вызывается под m.mu.
+func (m *Memory) deleteItemRefs(itemType string, itemID int) {
+	for id, v := range m.attachments {
+		if v.ItemType == itemType && v.ItemID == itemID {
+			delete(m.attachments, id)
+		}
+	}
+
+	for id, v := range m.shares {
+		if v.ItemType == itemType && v.ItemID == itemID {
+			delete(m.shares, id)
+		}
+	}
+}
+
+func (m *Memory) SaveAttachment(ctx context.Context, att model.Attachment) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	att.ID = m.nextID("attachments")
+	m.attachments[att.ID] = att
+
+	return att.ID, nil
+}
+
+func (m *Memory) DeleteAttachment(ctx context.Context, attID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.attachments[attID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.attachments, attID)
+
+	return nil
+}
+
+func (m *Memory) FindAttachment(ctx context.Context, attID, userID int) (att model.Attachment, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	att, ok := m.attachments[attID]
+	if !ok || att.UserID != userID {
+		return model.Attachment{}, ErrorNotFound
+	}
+
+	return att, nil
+}
+
+func (m *Memory) FindAllAttachments(ctx context.Context, userID int, filter model.AttachmentFilter) (atts []model.Attachment, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.attachments {
+		if v.UserID != userID {
+			continue
+		}
+
+		if filter.ItemType != "" && v.ItemType != filter.ItemType {
+			continue
+		}
+
+		if filter.ItemID != 0 && v.ItemID != filter.ItemID {
+			continue
+		}
+
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		atts = append(atts, m.attachments[id])
+	}
+
+	return atts, nil
+}
+
+// login логин пользователя по идентификатору. Вызывается под m.mu.
+func (m *Memory) login(userID int) string {
+	for login, u := range m.users {
+		if u.id == userID {
+			return login
+		}
+	}
+
+	return ""
+}
+
+func (m *Memory) SaveUserKeys(ctx context.Context, keys model.UserKeys) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.keys[keys.UserID] = keys
+
+	return nil
+}
+
+func (m *Memory) FindUserKeys(ctx context.Context, userID int) (keys model.UserKeys, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys, ok := m.keys[userID]
+	if !ok {
+		return keys, ErrorNotFound
+	}
+
+	return keys, nil
+}
+
+func (m *Memory) FindPublicKey(ctx context.Context, login string) (key model.PublicKey, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	u, ok := m.users[login]
+	if !ok {
+		return key, ErrorNotFound
+	}
+
+	keys, ok := m.keys[u.id]
+	if !ok {
+		return key, ErrorNotFound
+	}
+
+	return model.PublicKey{UserID: u.id, Login: login, PublicKey: keys.PublicKey}, nil
+}
+
+func (m *Memory) SaveShare(ctx context.Context, share model.Share) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if share.ID != 0 {
+		v, ok := m.shares[share.ID]
+		if !ok || v.UserID != share.UserID || v.RecipientID != share.RecipientID || v.ItemType != share.ItemType || v.ItemID != share.ItemID {
+			return share.ID, ErrorNotFound
+		}
+
+		m.shares[share.ID] = share
+
+		return share.ID, nil
+	}
+
+	for _, v := range m.shares {
+		if v.UserID == share.UserID && v.RecipientID == share.RecipientID && v.ItemType == share.ItemType && v.ItemID == share.ItemID {
+			return 0, ErrorRowAlreadyExists
+		}
+	}
+
+	share.ID = m.nextID("shares")
+	m.shares[share.ID] = share
+
+	return share.ID, nil
+}
+
+func (m *Memory) UpdateShareData(ctx context.Context, share model.Share) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.shares[share.ID]
+	if !ok || v.RecipientID != share.RecipientID {
+		return ErrorNotFound
+	}
+
+	v.Data = share.Data
+	v.UpdatedAt = share.UpdatedAt
+	m.shares[share.ID] = v
+
+	return nil
+}
+
+func (m *Memory) DeleteShare(ctx context.Context, shareID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.shares[shareID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.shares, shareID)
+
+	return nil
+}
+
+func (m *Memory) FindAllShares(ctx context.Context, userID int) (shares []model.Share, err error) {
+	return m.findShares(func(v model.Share) bool { return v.UserID == userID }), nil
+}
+
+func (m *Memory) FindAllIncomingShares(ctx context.Context, recipientID int) (shares []model.Share, err error) {
+	return m.findShares(func(v model.Share) bool { return v.RecipientID == recipientID }), nil
+}
+
+// findShares доступы, отобранные match, с логинами владельца и получателя, как join в БД.
+func (m *Memory) findShares(match func(v model.Share) bool) (shares []model.Share) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.shares {
+		if match(v) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		v := m.shares[id]
+		v.Owner = m.login(v.UserID)
+		v.Recipient = m.login(v.RecipientID)
+		// идентификаторы пользователей наружу не отдаются, как и в БД
+		v.UserID, v.RecipientID = 0, 0
+		shares = append(shares, v)
+	}
+
+	return shares
+}
+
+func (m *Memory) SaveOrganization(ctx context.Context, org model.Organization, ownerID int) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if org.ID != 0 {
+		if _, ok := m.orgs[org.ID]; !ok {
+			return org.ID, ErrorNotFound
+		}
+
+		m.orgs[org.ID] = model.Organization{ID: org.ID, Name: org.Name, UpdatedAt: org.UpdatedAt}
+
+		return org.ID, nil
+	}
+
+	id = m.nextID("organizations")
+	m.orgs[id] = model.Organization{ID: id, Name: org.Name, UpdatedAt: org.UpdatedAt}
+	m.members[memberKey{id, ownerID}] = model.Member{OrgID: id, UserID: ownerID, Role: model.RoleOwner, Status: model.MemberStatusActive, UpdatedAt: org.UpdatedAt}
+
+	return id, nil
+}
+
+// DeleteOrganization удаляет организацию вместе с участниками и коллекциями, как on delete cascade в БД.
+func (m *Memory) DeleteOrganization(ctx context.Context, orgID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.orgs[orgID]; !ok {
+		return ErrorNotFound
+	}
+
+	delete(m.orgs, orgID)
+
+	for k := range m.members {
+		if k.id == orgID {
+			delete(m.members, k)
+		}
+	}
+
+	for id, v := range m.collections {
+		if v.OrgID == orgID {
+			m.deleteCollection(id)
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) FindAllOrganizations(ctx context.Context, userID int) (orgs []model.Organization, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for k := range m.members {
+		if k.userID == userID {
+			ids = append(ids, k.id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		org := m.orgs[id]
+		member := m.members[memberKey{id, userID}]
+		org.Role, org.Status = member.Role, member.Status
+		orgs = append(orgs, org)
+	}
+
+	return orgs, nil
+}
+
+func (m *Memory) SaveMember(ctx context.Context, member model.Member) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.members[memberKey{member.OrgID, member.UserID}] = model.Member{
+		OrgID:     member.OrgID,
+		UserID:    member.UserID,
+		Role:      member.Role,
+		Status:    member.Status,
+		UpdatedAt: member.UpdatedAt,
+	}
+
+	for _, k := range member.Keys {
+		m.collectionKeys[memberKey{k.CollectionID, k.UserID}] = k.Key
+	}
+
+	return nil
+}
+
+func (m *Memory) DeleteMember(ctx context.Context, orgID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.members[memberKey{orgID, userID}]; !ok {
+		return ErrorNotFound
+	}
+
+	delete(m.members, memberKey{orgID, userID})
+
+	for id, v := range m.collections {
+		if v.OrgID == orgID {
+			delete(m.collectionKeys, memberKey{id, userID})
+		}
+	}
+
+	return nil
+}
+
+// member участник с логином и открытым ключом, как join в БД. Вызывается под m.mu.
+func (m *Memory) member(v model.Member) model.Member {
+	v.Login = m.login(v.UserID)
+	v.PublicKey = m.keys[v.UserID].PublicKey
+
+	return v
+}
+
+func (m *Memory) FindMember(ctx context.Context, orgID, userID int) (member model.Member, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	v, ok := m.members[memberKey{orgID, userID}]
+	if !ok {
+		return member, ErrorNotFound
+	}
+
+	return m.member(v), nil
+}
+
+func (m *Memory) FindAllMembers(ctx context.Context, orgID int) (members []model.Member, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for k, v := range m.members {
+		if k.id == orgID {
+			members = append(members, m.member(v))
+		}
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Login < members[j].Login
+	})
+
+	return members, nil
+}
+
+func (m *Memory) SaveCollection(ctx context.Context, col model.Collection) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id = col.ID
+	if id == 0 {
+		id = m.nextID("collections")
+	} else if v, ok := m.collections[id]; !ok || v.OrgID != col.OrgID {
+		return id, ErrorNotFound
+	}
+
+	m.collections[id] = model.Collection{ID: id, OrgID: col.OrgID, Name: col.Name, UpdatedAt: col.UpdatedAt}
+
+	for _, k := range col.Keys {
+		m.collectionKeys[memberKey{id, k.UserID}] = k.Key
+	}
+
+	return id, nil
+}
+
+// deleteCollection удаляет коллекцию вместе с ключами и записями. Вызывается под m.mu.
+func (m *Memory) deleteCollection(colID int) {
+	delete(m.collections, colID)
+
+	for k := range m.collectionKeys {
+		if k.id == colID {
+			delete(m.collectionKeys, k)
+		}
+	}
+
+	for id, v := range m.collectionItems {
+		if v.CollectionID == colID {
+			delete(m.collectionItems, id)
+		}
+	}
+}
+
+func (m *Memory) DeleteCollection(ctx context.Context, colID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.collections[colID]; !ok {
+		return ErrorNotFound
+	}
+
+	m.deleteCollection(colID)
+
+	return nil
+}
+
+func (m *Memory) FindCollection(ctx context.Context, colID int) (col model.Collection, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	col, ok := m.collections[colID]
+	if !ok {
+		return col, ErrorNotFound
+	}
+
+	return col, nil
+}
+
+func (m *Memory) FindAllCollections(ctx context.Context, userID int) (cols []model.Collection, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.collections {
+		member, ok := m.members[memberKey{v.OrgID, userID}]
+		if !ok || member.Status != model.MemberStatusActive {
+			continue
+		}
+
+		if _, ok = m.collectionKeys[memberKey{id, userID}]; ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		col := m.collections[id]
+		col.Key = m.collectionKeys[memberKey{id, userID}]
+		col.Role = m.members[memberKey{col.OrgID, userID}].Role
+		cols = append(cols, col)
+	}
+
+	return cols, nil
+}
+
+func (m *Memory) SaveCollectionItem(ctx context.Context, item model.CollectionItem) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if item.ID == 0 {
+		item.ID = m.nextID("collection_items")
+	} else if v, ok := m.collectionItems[item.ID]; !ok || v.CollectionID != item.CollectionID {
+		return item.ID, ErrorNotFound
+	} else {
+		item.ItemType = v.ItemType
+	}
+
+	m.collectionItems[item.ID] = item
+
+	return item.ID, nil
+}
+
+func (m *Memory) DeleteCollectionItem(ctx context.Context, itemID, colID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.collectionItems[itemID]; !ok || v.CollectionID != colID {
+		return ErrorNotFound
+	}
+
+	delete(m.collectionItems, itemID)
+
+	return nil
+}
+
+func (m *Memory) FindAllCollectionItems(ctx context.Context, colID int) (items []model.CollectionItem, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, v := range m.collectionItems {
+		if v.CollectionID == colID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		items = append(items, m.collectionItems[id])
+	}
+
+	return items, nil
+}
+
+func (m *Memory) SaveEmergencyAccess(ctx context.Context, access model.EmergencyAccess) (id int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if access.ID == 0 {
+		for _, v := range m.emergency {
+			if v.UserID == access.UserID && v.GranteeID == access.GranteeID {
+				return id, ErrorRowAlreadyExists
+			}
+		}
+
+		access.ID = m.nextID("emergency_access")
+		access.Grantor, access.Grantee = "", ""
+		m.emergency[access.ID] = access
+
+		return access.ID, nil
+	}
+
+	v, ok := m.emergency[access.ID]
+	if !ok || v.UserID != access.UserID || v.GranteeID != access.GranteeID {
+		return access.ID, ErrorNotFound
+	}
+
+	v.WaitHours, v.Key, v.UpdatedAt = access.WaitHours, access.Key, access.UpdatedAt
+	m.emergency[access.ID] = v
+
+	return access.ID, nil
+}
+
+func (m *Memory) UpdateEmergencyStatus(ctx context.Context, access model.EmergencyAccess) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.emergency[access.ID]
+	if !ok {
+		return ErrorNotFound
+	}
+
+	v.Status, v.RequestedAt, v.UpdatedAt = access.Status, access.RequestedAt, access.UpdatedAt
+	m.emergency[access.ID] = v
+
+	return nil
+}
+
+func (m *Memory) DeleteEmergencyAccess(ctx context.Context, accessID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.emergency[accessID]; !ok || v.UserID != userID {
+		return ErrorNotFound
+	}
+
+	delete(m.emergency, accessID)
+
+	return nil
+}
+
+func (m *Memory) FindEmergencyAccess(ctx context.Context, accessID int) (access model.EmergencyAccess, err error) {
+	list := m.findEmergencyAccess(func(v model.EmergencyAccess) bool { return v.ID == accessID })
+	if len(list) == 0 {
+		return access, ErrorNotFound
+	}
+
+	return list[0], nil
+}
+
+func (m *Memory) FindAllEmergencyAccess(ctx context.Context, userID int) (list []model.EmergencyAccess, err error) {
+	return m.findEmergencyAccess(func(v model.EmergencyAccess) bool { return v.UserID == userID }), nil
+}
+
+func (m *Memory) FindAllIncomingEmergencyAccess(ctx context.Context, granteeID int) (list []model.EmergencyAccess, err error) {
+	return m.findEmergencyAccess(func(v model.EmergencyAccess) bool { return v.GranteeID == granteeID }), nil
+}
+
+// findEmergencyAccess экстренные доступы, подходящие под match, с логинами, по возрастанию id.
+func (m *Memory) findEmergencyAccess(match func(v model.EmergencyAccess) bool) (list []model.EmergencyAccess) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, v := range m.emergency {
+		if !match(v) {
+			continue
+		}
+
+		v.Grantor, v.Grantee = m.login(v.UserID), m.login(v.GranteeID)
+		list = append(list, v)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	return list
+}
+
+func (m *Memory) SaveSecret(ctx context.Context, secret model.Secret) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.secrets[secret.ID]; ok {
+		return ErrorRowAlreadyExists
+	}
+
+	secret.Views = 0
+	m.secrets[secret.ID] = secret
+
+	return nil
+}
+
+func (m *Memory) OpenSecret(ctx context.Context, secretID string, now time.Time) (secret model.Secret, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	secret, ok := m.secrets[secretID]
+	if !ok || !secret.ExpiresAt.After(now) || secret.Views >= secret.MaxViews {
+		return model.Secret{}, ErrorNotFound
+	}
+
+	secret.Views++
+	if secret.Views >= secret.MaxViews {
+		delete(m.secrets, secretID)
+	} else {
+		m.secrets[secretID] = secret
+	}
+
+	return secret, nil
+}
+
+func (m *Memory) DeleteSecret(ctx context.Context, secretID string, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	secret, ok := m.secrets[secretID]
+	if !ok || secret.UserID != userID {
+		return ErrorNotFound
+	}
+	delete(m.secrets, secretID)
+
+	return nil
+}
+
+func (m *Memory) FindAllSecrets(ctx context.Context, userID int) (secrets []model.Secret, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, v := range m.secrets {
+		if v.UserID == userID {
+			v.Data = ""
+			secrets = append(secrets, v)
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].CreatedAt.Before(secrets[j].CreatedAt) })
+
+	return secrets, nil
+}
+
+func (m *Memory) ClearExpiredSecrets(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, v := range m.secrets {
+		if v.ExpiresAt.Before(now) {
+			delete(m.secrets, id)
+		}
+	}
+
+	return nil
+}
+
+// LockFile выполняет fn под блокировкой содержимого path; хранилище в памяти доступно только своему процессу.
+func (m *Memory) LockFile(ctx context.Context, path string, fn func() error) error {
+	for {
+		m.fileLocksMu.Lock()
+		held, ok := m.fileLocks[path]
+		if !ok {
+			break
+		}
+		m.fileLocksMu.Unlock()
+
+		select {
+		case <-held:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	released := make(chan struct{})
+	m.fileLocks[path] = released
+	m.fileLocksMu.Unlock()
+
+	defer func() {
+		m.fileLocksMu.Lock()
+		delete(m.fileLocks, path)
+		m.fileLocksMu.Unlock()
+		close(released)
+	}()
+
+	return fn()
+}
+
+func (m *Memory) SaveUpload(ctx context.Context, upload model.Upload) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.uploads[upload.ID]; ok {
+		return ErrorRowAlreadyExists
+	}
+	m.uploads[upload.ID] = upload
+
+	return nil
+}
+
+func (m *Memory) FindUpload(ctx context.Context, uploadID string, userID int) (upload model.Upload, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	upload, ok := m.uploads[uploadID]
+	if !ok || upload.UserID != userID {
+		return model.Upload{}, ErrorNotFound
+	}
+
+	return upload, nil
+}
+
+func (m *Memory) UpdateUploadOffset(ctx context.Context, upload model.Upload, from int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.uploads[upload.ID]
+	if !ok || v.UserID != upload.UserID || v.Offset != from {
+		return ErrorNotFound
+	}
+
+	v.Offset = upload.Offset
+	v.ExpiresAt = upload.ExpiresAt
+	m.uploads[upload.ID] = v
+
+	return nil
+}
+
+func (m *Memory) CompleteUpload(ctx context.Context, upload model.Upload) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.uploads[upload.ID]
+	if !ok || v.UserID != upload.UserID || v.SHA256 != "" {
+		return ErrorNotFound
+	}
+
+	v.SHA256 = upload.SHA256
+	v.ExpiresAt = upload.ExpiresAt
+	m.uploads[upload.ID] = v
+
+	return nil
+}
+
+func (m *Memory) FindCompletedUpload(ctx context.Context, userID int, sha256 string, now time.Time) (upload model.Upload, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	found := false
+	for _, v := range m.uploads {
+		if v.UserID != userID || v.SHA256 != sha256 || v.ExpiresAt.Before(now) {
+			continue
+		}
+
+		if !found || v.ExpiresAt.After(upload.ExpiresAt) {
+			upload, found = v, true
+		}
+	}
+
+	if !found {
+		return model.Upload{}, ErrorNotFound
+	}
+
+	return upload, nil
+}
+
+func (m *Memory) DeleteUpload(ctx context.Context, uploadID string, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	upload, ok := m.uploads[uploadID]
+	if !ok || upload.UserID != userID {
+		return ErrorNotFound
+	}
+	delete(m.uploads, uploadID)
+
+	return nil
+}
+
+func (m *Memory) FindExpiredUploads(ctx context.Context, now time.Time) (uploads []model.Upload, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, v := range m.uploads {
+		if v.ExpiresAt.Before(now) {
+			uploads = append(uploads, v)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool { return uploads[i].ExpiresAt.Before(uploads[j].ExpiresAt) })
+
+	return uploads, nil
+}
+
+// GetUsage объем данных и число записей пользователя; квоты заполняет сервис.
+func (m *Memory) GetUsage(ctx context.Context, userID int) (usage model.Usage, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	addUsage(&usage, m.cards, func(v model.DataCard) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+	addUsage(&usage, m.creds, func(v model.DataCred) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+	addUsage(&usage, m.texts, func(v model.DataText) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+	addUsage(&usage, m.files, func(v model.DataFile) (int, int64) { return v.UserID, v.PayloadSize() + v.Size }, userID)
+	addUsage(&usage, m.ssh, func(v model.DataSSHKey) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+	addUsage(&usage, m.identities, func(v model.DataIdentity) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+	addUsage(&usage, m.custom, func(v model.DataCustom) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+	addUsage(&usage, m.attachments, func(v model.Attachment) (int, int64) { return v.UserID, v.PayloadSize() + v.Size }, userID)
+	addUsage(&usage, m.folders, func(v model.Folder) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+	addUsage(&usage, m.tags, func(v model.Tag) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+	addUsage(&usage, m.templates, func(v model.Template) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+	addUsage(&usage, m.shares, func(v model.Share) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+	addUsage(&usage, m.secrets, func(v model.Secret) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+	addUsage(&usage, m.collectionItems, func(v model.CollectionItem) (int, int64) { return v.UserID, v.PayloadSize() }, userID)
+
+	return usage, nil
+}
+
+// CountFileRefs число записей всех пользователей (файлов и вложений), ссылающихся на содержимое по пути path.
+func (m *Memory) CountFileRefs(ctx context.Context, path string) (refs int, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, v := range m.files {
+		if v.Path == path {
+			refs++
+		}
+	}
+
+	for _, v := range m.attachments {
+		if v.Path == path {
+			refs++
+		}
+	}
+
+	return refs, nil
+}
+
+// CountUserFileRefs число файлов и вложений пользователя userID, ссылающихся на содержимое по пути path.
+func (m *Memory) CountUserFileRefs(ctx context.Context, userID int, path string) (refs int, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, v := range m.files {
+		if v.UserID == userID && v.Path == path {
+			refs++
+		}
+	}
+
+	for _, v := range m.attachments {
+		if v.UserID == userID && v.Path == path {
+			refs++
+		}
+	}
+
+	return refs, nil
+}
+
+// FindFileRefs все файлы и вложения пользователей, ссылающиеся на содержимое в хранилище файлов.
+func (m *Memory) FindFileRefs(ctx context.Context) (refs []model.FileRef, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, v := range m.files {
+		if v.Path != "" {
+			refs = append(refs, model.FileRef{Table: model.FileRefTableFiles, ID: v.ID, UserID: v.UserID, Path: v.Path})
+		}
+	}
+
+	for _, v := range m.attachments {
+		if v.Path != "" {
+			refs = append(refs, model.FileRef{Table: model.FileRefTableAttachments, ID: v.ID, UserID: v.UserID, Path: v.Path})
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Table != refs[j].Table {
+			return refs[i].Table < refs[j].Table
+		}
+
+		return refs[i].ID < refs[j].ID
+	})
+
+	return refs, nil
+}
+
+// FindUploadIDs идентификаторы всех незавершенных загрузок, в том числе просроченных.
+func (m *Memory) FindUploadIDs(ctx context.Context) (ids []string, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for id := range m.uploads {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// addUsage добавляет к usage записи таблицы, принадлежащие пользователю: row возвращает владельца
+// и размер записи, см. usageTables.
+func addUsage[K comparable, T any](usage *model.Usage, rows map[K]T, row func(T) (int, int64), userID int) {
+	for _, v := range rows {
+		if owner, size := row(v); owner == userID {
+			usage.Items++
+			usage.Bytes += size
+		}
+	}
+}
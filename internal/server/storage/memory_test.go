@@ -0,0 +1,14 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/storagetest"
+)
+
+func TestMemory_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		return storage.NewMemory()
+	})
+}
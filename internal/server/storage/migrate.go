@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/rainset/gophkeeper/migrations"
+)
+
+// migrationLockKey ключ pg_advisory_lock, под которым реплики сервера применяют миграции по очереди.
+const migrationLockKey = 7_306_245_103
+
+var (
+	ErrorSchemaTooNew          = errors.New("database schema is newer than server supports")
+	ErrorMigrationsUnsupported = errors.New("storage has no sql schema")
+)
+
+// gooseMu защищает глобальные настройки goose (файловая система, диалект, логгер).
+var gooseMu sync.Mutex //nolint:gochecknoglobals
+
+// Migrator применяет встроенные в бинарный файл миграции (пакет migrations) к БД хранилища.
+type Migrator struct {
+	db      *sql.DB
+	dialect string
+	fsys    fs.FS
+	dir     string
+	// ownDB соединение открыто мигратором и закрывается в Close
+	ownDB bool
+	// advisoryLock блокировка на время миграций (postgres)
+	advisoryLock bool
+}
+
+// NewMigrator возвращает мигратор для хранилища, у которого есть SQL-схема.
+// Для хранилища в памяти возвращается ErrorMigrationsUnsupported.
+func NewMigrator(store Interface) (*Migrator, error) {
+	switch s := store.(type) {
+	case *Database:
+		return &Migrator{
+			db:           stdlib.OpenDB(*s.pgx.Config().ConnConfig),
+			dialect:      "postgres",
+			fsys:         migrations.Postgres,
+			dir:          ".",
+			ownDB:        true,
+			advisoryLock: true,
+		}, nil
+	case *SQLite:
+		// запись в SQLite и так последовательна, а единственное соединение нельзя занимать блокировкой
+		return &Migrator{
+			db:      s.db,
+			dialect: "sqlite3",
+			fsys:    migrations.SQLite,
+			dir:     "sqlite",
+		}, nil
+	default:
+		return nil, ErrorMigrationsUnsupported
+	}
+}
+
+func (m *Migrator) Close() error {
+	if m.ownDB {
+		return m.db.Close()
+	}
+
+	return nil
+}
+
+// run выполняет fn с настройками goose для этого мигратора и, если нужно, под advisory lock.
+func (m *Migrator) run(ctx context.Context, logger *log.Logger, fn func() error) (err error) {
+	if m.advisoryLock {
+		conn, err := m.db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate lock: %w", err)
+		}
+		defer conn.Close()
+
+		if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+			return fmt.Errorf("migrate lock: %w", err)
+		}
+
+		defer func() {
+			_, errUnlock := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+			if err == nil && errUnlock != nil {
+				err = fmt.Errorf("migrate unlock: %w", errUnlock)
+			}
+		}()
+	}
+
+	gooseMu.Lock()
+	defer gooseMu.Unlock()
+
+	goose.SetBaseFS(m.fsys)
+	goose.SetLogger(logger)
+
+	defer func() {
+		goose.SetBaseFS(nil)
+		goose.SetLogger(log.New(os.Stderr, "", log.LstdFlags))
+	}()
+
+	if err = goose.SetDialect(m.dialect); err != nil {
+		return err
+	}
+
+	return fn()
+}
+
+// Up применяет все новые миграции.
+func (m *Migrator) Up(ctx context.Context) error {
+	err := m.run(ctx, log.Default(), func() error {
+		return goose.Up(m.db, m.dir)
+	})
+	if err != nil {
+		return fmt.Errorf("migrate.Up: %w", err)
+	}
+
+	return nil
+}
+
+// Down откатывает последнюю примененную миграцию.
+func (m *Migrator) Down(ctx context.Context) error {
+	err := m.run(ctx, log.Default(), func() error {
+		return goose.Down(m.db, m.dir)
+	})
+	if err != nil {
+		return fmt.Errorf("migrate.Down: %w", err)
+	}
+
+	return nil
+}
+
+// MigrateTo применяет или откатывает миграции так, чтобы версия схемы стала равна version.
+func (m *Migrator) MigrateTo(ctx context.Context, version int64) error {
+	err := m.run(ctx, log.Default(), func() error {
+		current, err := goose.GetDBVersion(m.db)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case current < version:
+			return goose.UpTo(m.db, m.dir, version)
+		case current > version:
+			return goose.DownTo(m.db, m.dir, version)
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("migrate.MigrateTo: %w", err)
+	}
+
+	return nil
+}
+
+// Status выводит в w список миграций и время их применения.
+func (m *Migrator) Status(ctx context.Context, w io.Writer) error {
+	err := m.run(ctx, log.New(w, "", 0), func() error {
+		return goose.Status(m.db, m.dir)
+	})
+	if err != nil {
+		return fmt.Errorf("migrate.Status: %w", err)
+	}
+
+	return nil
+}
+
+// Versions возвращает текущую версию схемы БД и последнюю версию среди встроенных миграций.
+func (m *Migrator) Versions(ctx context.Context) (current, latest int64, err error) {
+	err = m.run(ctx, log.New(io.Discard, "", 0), func() error {
+		current, err = goose.GetDBVersion(m.db)
+		if err != nil {
+			return err
+		}
+
+		all, err := goose.CollectMigrations(m.dir, 0, goose.MaxVersion)
+		if err != nil {
+			return err
+		}
+
+		last, err := all.Last()
+		if err != nil {
+			return err
+		}
+		latest = last.Version
+
+		return nil
+	})
+	if err != nil {
+		return current, latest, fmt.Errorf("migrate.Versions: %w", err)
+	}
+
+	return current, latest, nil
+}
+
+// Check возвращает ErrorSchemaTooNew, если схема БД новее, чем известно этой версии сервера.
+func (m *Migrator) Check(ctx context.Context) error {
+	current, latest, err := m.Versions(ctx)
+	if err != nil {
+		return err
+	}
+
+	if current > latest {
+		return fmt.Errorf("%w: database version %d, server version %d", ErrorSchemaTooNew, current, latest)
+	}
+
+	return nil
+}
@@ -0,0 +1,1952 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/pkg/crypt"
+	"github.com/rainset/gophkeeper/pkg/hash"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqlitePragmas параметры соединения: внешние ключи, ожидание блокировки и формат времени,
+// пригодный для сравнения строк в запросах.
+const sqlitePragmas = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+
+// SQLite хранилище на встроенной БД SQLite (драйвер modernc.org/sqlite, без cgo).
+type SQLite struct {
+	db *sql.DB
+}
+
+// NewSQLite открывает файл БД (или ":memory:"); таблицы создаются миграциями, см. NewMigrator.
+func NewSQLite(ctx context.Context, dataSourceName string) (*SQLite, error) {
+	dsn := sqliteDSN(dataSourceName)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.New: %w", err)
+	}
+
+	// SQLite допускает одного писателя, а каждое соединение с ":memory:" - отдельная БД
+	db.SetMaxOpenConns(1)
+
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite.New: %w", err)
+	}
+
+	return &SQLite{db: db}, nil
+}
+
+// sqliteDSN убирает схему sqlite:// (sqlite:) и добавляет обязательные параметры соединения.
+func sqliteDSN(dataSourceName string) string {
+	dsn := dataSourceName
+	for _, prefix := range []string{"sqlite3://", "sqlite://", "sqlite3:", "sqlite:"} {
+		if strings.HasPrefix(dsn, prefix) {
+			dsn = strings.TrimPrefix(dsn, prefix)
+			break
+		}
+	}
+
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + sqlitePragmas
+	}
+
+	return dsn + "?" + sqlitePragmas
+}
+
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+
+	return false
+}
+
+// execAffected выполняет запрос изменения и возвращает ErrorNotFound, если не затронута ни одна строка.
+func (s *SQLite) execAffected(ctx context.Context, query string, args ...any) error {
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLite) Close() {
+	s.db.Close()
+}
+
+func (s *SQLite) GetSignKey(ctx context.Context, login, password string) (signKey string, err error) {
+	var passHash = hash.Md5(password)
+
+	query := "SELECT sign_key FROM users WHERE login = ? AND password = ?"
+	err = s.db.QueryRowContext(ctx, query, login, passHash).Scan(&signKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return signKey, ErrorUserCredentials
+		}
+
+		return signKey, fmt.Errorf("sqlite.GetSignKey: %w", err)
+	}
+
+	return signKey, nil
+}
+
+func (s *SQLite) CreateUser(ctx context.Context, user model.User) (userID int, err error) {
+	var hMD5 = hash.Md5(user.Password)
+	t := time.Now().UTC()
+
+	h, err := hash.GenerateRandom(2 * aes.BlockSize)
+	if err != nil {
+		return userID, fmt.Errorf("sqlite.CreateUser: %w", err)
+	}
+
+	signKey := crypt.EncodeBase64(h)
+
+	query := "INSERT INTO users (login,password,sign_key,created_at,updated_at) VALUES (?,?,?,?,?) RETURNING id"
+	err = s.db.QueryRowContext(ctx, query, user.Login, hMD5, signKey, t, t).Scan(&userID)
+	if err != nil {
+		if isSQLiteUniqueViolation(err) {
+			return userID, ErrorUserAlreadyExists
+		}
+
+		return userID, fmt.Errorf("sqlite.CreateUser: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (s *SQLite) GetUserIDByCredentials(ctx context.Context, login, password string) (userID int, err error) {
+	var qPass string
+
+	query := "SELECT id,password FROM users WHERE login = ?"
+	err = s.db.QueryRowContext(ctx, query, login).Scan(&userID, &qPass)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrorUserCredentials
+		}
+
+		return userID, fmt.Errorf("sqlite.GetUserIDByCredentials: %w", err)
+	}
+
+	if hash.Md5(password) != qPass {
+		return 0, ErrorUserCredentials
+	}
+
+	return userID, nil
+}
+
+func (s *SQLite) SetRefreshToken(ctx context.Context, in model.RefreshToken) error {
+	query := "INSERT INTO refresh_tokens (user_id,token,created_at,expired_at) VALUES (?,?,?,?)"
+	_, err := s.db.ExecContext(ctx, query, in.UserID, in.Token, time.Now().UTC(), in.ExpiredAt.UTC())
+	if err != nil {
+		return fmt.Errorf("sqlite.SetRefreshToken: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) GetRefreshTokenUserID(ctx context.Context, token string) (userID int, err error) {
+	query := "SELECT user_id FROM refresh_tokens WHERE token=? AND expired_at>?"
+	err = s.db.QueryRowContext(ctx, query, token, time.Now().UTC()).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return userID, ErrorNotFound
+		}
+
+		return userID, fmt.Errorf("sqlite.GetRefreshTokenUserID: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (s *SQLite) ClearExpiredRefreshTokens(ctx context.Context) error {
+	query := "DELETE FROM refresh_tokens WHERE expired_at < ?"
+	_, err := s.db.ExecContext(ctx, query, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("sqlite.ClearExpiredRefreshTokens: %w", err)
+	}
+
+	return nil
+}
+
+// withTx выполняет fn в транзакции; при ошибке транзакция откатывается.
+func (s *SQLite) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// txExecAffected как execAffected, но в рамках транзакции.
+func txExecAffected(ctx context.Context, tx *sql.Tx, query string, args ...any) error {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+// sqliteTagIDs столбец tag_ids выборки записей: метки из item_tags через запятую, разбираются parseTagIDs.
+func sqliteTagIDs(itemType string) string {
+	return fmt.Sprintf("(SELECT group_concat(tag_id) FROM item_tags WHERE item_type='%s' AND item_id=%s.id) AS tag_ids",
+		itemType, itemTables[itemType])
+}
+
+// sqliteSetItemTags заменяет метки записи в рамках транзакции сохранения.
+func sqliteSetItemTags(ctx context.Context, tx *sql.Tx, itemType string, itemID int, tagIDs []int) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM item_tags WHERE item_type=? AND item_id=?", itemType, itemID)
+	if err != nil {
+		return err
+	}
+
+	for _, tagID := range normalizeTagIDs(tagIDs) {
+		_, err = tx.ExecContext(ctx, "INSERT INTO item_tags (item_type,item_id,tag_id) VALUES (?,?,?)", itemType, itemID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteItem удаляет запись пользователя вместе с её метками.
+func (s *SQLite) deleteItem(ctx context.Context, itemType string, itemID, userID int) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		query := fmt.Sprintf("DELETE FROM %s WHERE id=? AND user_id=?", itemTables[itemType])
+		if err := txExecAffected(ctx, tx, query, itemID, userID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "DELETE FROM item_tags WHERE item_type=? AND item_id=?", itemType, itemID)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range []string{"attachments", "shares"} {
+			query := "DELETE FROM " + table + " WHERE item_type=? AND item_id=? AND user_id=?"
+			if _, err = tx.ExecContext(ctx, query, itemType, itemID, userID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// itemRef поля связи записи с папкой и метками, общие для всех типов записей.
+type itemRef struct {
+	folderID sql.NullInt64
+	tagIDs   sql.NullString
+}
+
+func (r *itemRef) apply(folderID *int, tagIDs *[]int) (err error) {
+	*folderID = int(r.folderID.Int64)
+	*tagIDs, err = parseTagIDs(r.tagIDs.String)
+
+	return err
+}
+
+func (s *SQLite) SaveCard(ctx context.Context, card model.DataCard) (id int, err error) {
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		if card.ID == 0 {
+			query := "INSERT INTO data_cards (user_id,title,number,date,cvv,meta,folder_id,fields,updated_at) VALUES (?,?,?,?,?,?,?,?,?) RETURNING id"
+			err := tx.QueryRowContext(ctx, query, card.UserID, card.Title, card.Number, card.Date, card.Cvv, card.Meta, nullID(card.FolderID), normalizeFields(card.Fields), card.UpdatedAt.UTC()).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = card.ID
+			query := "UPDATE data_cards SET title=?,number=?,date=?,cvv=?,meta=?,folder_id=?,fields=?,updated_at=? WHERE user_id=? AND id=?"
+			err := txExecAffected(ctx, tx, query, card.Title, card.Number, card.Date, card.Cvv, card.Meta, nullID(card.FolderID), normalizeFields(card.Fields), card.UpdatedAt.UTC(), card.UserID, card.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return sqliteSetItemTags(ctx, tx, model.ItemTypeCard, id, card.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
+	}
+
+	if isSQLiteUniqueViolation(err) {
+		return id, ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveCard: %w", err)
+	}
+
+	return id, nil
+}
+
+func scanSQLiteCard(row interface{ Scan(dest ...any) error }) (card model.DataCard, err error) {
+	var ref itemRef
+	err = row.Scan(&card.ID, &card.Title, &card.Number, &card.Date, &card.Cvv, &card.Meta, &ref.folderID, &ref.tagIDs, &card.Fields, &card.UpdatedAt)
+	if err != nil {
+		return card, err
+	}
+
+	return card, ref.apply(&card.FolderID, &card.TagIDs)
+}
+
+func (s *SQLite) FindCard(ctx context.Context, cardID, userID int) (card model.DataCard, err error) {
+	query := "SELECT id,title,number,date,cvv,meta,folder_id," + sqliteTagIDs(model.ItemTypeCard) + ",fields,updated_at FROM data_cards WHERE id=? AND user_id=?"
+	card, err = scanSQLiteCard(s.db.QueryRowContext(ctx, query, cardID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return card, ErrorNotFound
+		}
+
+		return card, fmt.Errorf("sqlite.FindCard: %w", err)
+	}
+
+	return card, nil
+}
+
+func (s *SQLite) FindAllCards(ctx context.Context, userID int, filter model.ItemFilter) (cards []model.DataCard, err error) {
+	where, args := itemFilterSQL(model.ItemTypeCard, filter, []any{userID}, sqlitePlaceholder)
+	query := "SELECT id,title,number,date,cvv,meta,folder_id," + sqliteTagIDs(model.ItemTypeCard) + ",fields,updated_at FROM data_cards WHERE user_id=?" + where + " ORDER BY id DESC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return cards, fmt.Errorf("sqlite.FindAllCards: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		card, err := scanSQLiteCard(rows)
+		if err != nil {
+			return cards, fmt.Errorf("sqlite.FindAllCards: %w", err)
+		}
+		cards = append(cards, card)
+	}
+
+	if err = rows.Err(); err != nil {
+		return cards, fmt.Errorf("sqlite.FindAllCards: %w", err)
+	}
+
+	return cards, nil
+}
+
+func (s *SQLite) DeleteCard(ctx context.Context, cardID, userID int) error {
+	err := s.deleteItem(ctx, model.ItemTypeCard, cardID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteCard: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) SaveFile(ctx context.Context, file model.DataFile) (id int, err error) {
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		if file.ID == 0 {
+			query := "INSERT INTO data_files (user_id,title,filename,path,size,sha256,meta,folder_id,fields,updated_at) VALUES (?,?,?,?,?,?,?,?,?,?) RETURNING id"
+			err := tx.QueryRowContext(ctx, query, file.UserID, file.Title, file.Filename, file.Path, file.Size, file.SHA256, file.Meta, nullID(file.FolderID), normalizeFields(file.Fields), file.UpdatedAt.UTC()).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = file.ID
+			query := "UPDATE data_files SET title=?,filename=?,path=?,size=?,sha256=?,meta=?,folder_id=?,fields=?,updated_at=? WHERE user_id=? AND id=?"
+			err := txExecAffected(ctx, tx, query, file.Title, file.Filename, file.Path, file.Size, file.SHA256, file.Meta, nullID(file.FolderID), normalizeFields(file.Fields), file.UpdatedAt.UTC(), file.UserID, file.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return sqliteSetItemTags(ctx, tx, model.ItemTypeFile, id, file.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
+	}
+
+	if isSQLiteUniqueViolation(err) {
+		return id, ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveFile: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *SQLite) DeleteFile(ctx context.Context, fileID, userID int) error {
+	err := s.deleteItem(ctx, model.ItemTypeFile, fileID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteFile: %w", err)
+	}
+
+	return err
+}
+
+func scanSQLiteFile(row interface{ Scan(dest ...any) error }) (file model.DataFile, err error) {
+	var ref itemRef
+	err = row.Scan(&file.ID, &file.Title, &file.Filename, &file.Path, &file.Size, &file.SHA256, &file.Meta, &ref.folderID, &ref.tagIDs, &file.Fields, &file.UpdatedAt)
+	if err != nil {
+		return file, err
+	}
+
+	return file, ref.apply(&file.FolderID, &file.TagIDs)
+}
+
+func (s *SQLite) FindFile(ctx context.Context, fileID, userID int) (file model.DataFile, err error) {
+	query := "SELECT id,title,filename,path,size,sha256,meta,folder_id," + sqliteTagIDs(model.ItemTypeFile) + ",fields,updated_at FROM data_files WHERE id=? AND user_id=?"
+	file, err = scanSQLiteFile(s.db.QueryRowContext(ctx, query, fileID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return file, ErrorNotFound
+		}
+
+		return file, fmt.Errorf("sqlite.FindFile: %w", err)
+	}
+
+	return file, nil
+}
+
+func (s *SQLite) FindAllFiles(ctx context.Context, userID int, filter model.ItemFilter) (files []model.DataFile, err error) {
+	where, args := itemFilterSQL(model.ItemTypeFile, filter, []any{userID}, sqlitePlaceholder)
+	query := "SELECT id,title,filename,path,size,sha256,meta,folder_id," + sqliteTagIDs(model.ItemTypeFile) + ",fields,updated_at FROM data_files WHERE user_id=?" + where + " ORDER BY id DESC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return files, fmt.Errorf("sqlite.FindAllFiles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		file, err := scanSQLiteFile(rows)
+		if err != nil {
+			return files, fmt.Errorf("sqlite.FindAllFiles: %w", err)
+		}
+		files = append(files, file)
+	}
+
+	if err = rows.Err(); err != nil {
+		return files, fmt.Errorf("sqlite.FindAllFiles: %w", err)
+	}
+
+	return files, nil
+}
+
+func (s *SQLite) SaveCred(ctx context.Context, cred model.DataCred) (id int, err error) {
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		if cred.ID == 0 {
+			query := "INSERT INTO data_creds (user_id,title,username,password,meta,folder_id,fields,urls,totp,updated_at) VALUES (?,?,?,?,?,?,?,?,?,?) RETURNING id"
+			err := tx.QueryRowContext(ctx, query, cred.UserID, cred.Title, cred.Username, cred.Password, cred.Meta, nullID(cred.FolderID), normalizeFields(cred.Fields), normalizeURLs(cred.URLs), cred.TOTP, cred.UpdatedAt.UTC()).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = cred.ID
+			query := "UPDATE data_creds SET title=?,username=?,password=?,meta=?,folder_id=?,fields=?,urls=?,totp=?,updated_at=? WHERE id=? AND user_id=?"
+			err := txExecAffected(ctx, tx, query, cred.Title, cred.Username, cred.Password, cred.Meta, nullID(cred.FolderID), normalizeFields(cred.Fields), normalizeURLs(cred.URLs), cred.TOTP, cred.UpdatedAt.UTC(), cred.ID, cred.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return sqliteSetItemTags(ctx, tx, model.ItemTypeCred, id, cred.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
+	}
+
+	if isSQLiteUniqueViolation(err) {
+		return id, ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveCred: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *SQLite) DeleteCred(ctx context.Context, credID, userID int) error {
+	err := s.deleteItem(ctx, model.ItemTypeCred, credID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteCred: %w", err)
+	}
+
+	return err
+}
+
+func scanSQLiteCred(row interface{ Scan(dest ...any) error }) (cred model.DataCred, err error) {
+	var ref itemRef
+	err = row.Scan(&cred.ID, &cred.Title, &cred.Username, &cred.Password, &cred.Meta, &ref.folderID, &ref.tagIDs, &cred.Fields, &cred.URLs, &cred.TOTP, &cred.UpdatedAt)
+	if err != nil {
+		return cred, err
+	}
+
+	return cred, ref.apply(&cred.FolderID, &cred.TagIDs)
+}
+
+func (s *SQLite) FindCred(ctx context.Context, credID, userID int) (cred model.DataCred, err error) {
+	query := "SELECT id,title,username,password,meta,folder_id," + sqliteTagIDs(model.ItemTypeCred) + ",fields,urls,totp,updated_at FROM data_creds WHERE id=? AND user_id=?"
+	cred, err = scanSQLiteCred(s.db.QueryRowContext(ctx, query, credID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return cred, ErrorNotFound
+		}
+
+		return cred, fmt.Errorf("sqlite.FindCred: %w", err)
+	}
+
+	return cred, nil
+}
+
+func (s *SQLite) FindAllCreds(ctx context.Context, userID int, filter model.ItemFilter) (creds []model.DataCred, err error) {
+	where, args := itemFilterSQL(model.ItemTypeCred, filter, []any{userID}, sqlitePlaceholder)
+	query := "SELECT id,title,username,password,meta,folder_id," + sqliteTagIDs(model.ItemTypeCred) + ",fields,urls,totp,updated_at FROM data_creds WHERE user_id=?" + where + " ORDER BY id DESC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return creds, fmt.Errorf("sqlite.FindAllCreds: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		cred, err := scanSQLiteCred(rows)
+		if err != nil {
+			return creds, fmt.Errorf("sqlite.FindAllCreds: %w", err)
+		}
+		creds = append(creds, cred)
+	}
+
+	if err = rows.Err(); err != nil {
+		return creds, fmt.Errorf("sqlite.FindAllCreds: %w", err)
+	}
+
+	return creds, nil
+}
+
+func (s *SQLite) SaveText(ctx context.Context, text model.DataText) (id int, err error) {
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		if text.ID == 0 {
+			query := "INSERT INTO data_text (user_id,title,text,meta,folder_id,fields,updated_at) VALUES (?,?,?,?,?,?,?) RETURNING id"
+			err := tx.QueryRowContext(ctx, query, text.UserID, text.Title, text.Text, text.Meta, nullID(text.FolderID), normalizeFields(text.Fields), text.UpdatedAt.UTC()).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = text.ID
+			query := "UPDATE data_text SET title=?,text=?,meta=?,folder_id=?,fields=?,updated_at=? WHERE id=? AND user_id=?"
+			err := txExecAffected(ctx, tx, query, text.Title, text.Text, text.Meta, nullID(text.FolderID), normalizeFields(text.Fields), text.UpdatedAt.UTC(), text.ID, text.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return sqliteSetItemTags(ctx, tx, model.ItemTypeText, id, text.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
+	}
+
+	if isSQLiteUniqueViolation(err) {
+		return id, ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveText: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *SQLite) DeleteText(ctx context.Context, textID, userID int) error {
+	err := s.deleteItem(ctx, model.ItemTypeText, textID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteText: %w", err)
+	}
+
+	return err
+}
+
+func scanSQLiteText(row interface{ Scan(dest ...any) error }) (text model.DataText, err error) {
+	var ref itemRef
+	err = row.Scan(&text.ID, &text.Title, &text.Text, &text.Meta, &ref.folderID, &ref.tagIDs, &text.Fields, &text.UpdatedAt)
+	if err != nil {
+		return text, err
+	}
+
+	return text, ref.apply(&text.FolderID, &text.TagIDs)
+}
+
+func (s *SQLite) FindText(ctx context.Context, textID, userID int) (text model.DataText, err error) {
+	query := "SELECT id,title,text,meta,folder_id," + sqliteTagIDs(model.ItemTypeText) + ",fields,updated_at FROM data_text WHERE id=? AND user_id=?"
+	text, err = scanSQLiteText(s.db.QueryRowContext(ctx, query, textID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return text, ErrorNotFound
+		}
+
+		return text, fmt.Errorf("sqlite.FindText: %w", err)
+	}
+
+	return text, nil
+}
+
+func (s *SQLite) FindAllTexts(ctx context.Context, userID int, filter model.ItemFilter) (texts []model.DataText, err error) {
+	where, args := itemFilterSQL(model.ItemTypeText, filter, []any{userID}, sqlitePlaceholder)
+	query := "SELECT id,title,text,meta,folder_id," + sqliteTagIDs(model.ItemTypeText) + ",fields,updated_at FROM data_text WHERE user_id=?" + where + " ORDER BY id DESC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return texts, fmt.Errorf("sqlite.FindAllTexts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		text, err := scanSQLiteText(rows)
+		if err != nil {
+			return texts, fmt.Errorf("sqlite.FindAllTexts: %w", err)
+		}
+		texts = append(texts, text)
+	}
+
+	if err = rows.Err(); err != nil {
+		return texts, fmt.Errorf("sqlite.FindAllTexts: %w", err)
+	}
+
+	return texts, nil
+}
+
+func (s *SQLite) SaveSSHKey(ctx context.Context, key model.DataSSHKey) (id int, err error) {
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		if key.ID == 0 {
+			query := "INSERT INTO data_ssh_keys (user_id,title,private_key,public_key,fingerprint,passphrase,meta,folder_id,fields,updated_at) VALUES (?,?,?,?,?,?,?,?,?,?) RETURNING id"
+			err := tx.QueryRowContext(ctx, query, key.UserID, key.Title, key.PrivateKey, key.PublicKey, key.Fingerprint, key.Passphrase, key.Meta, nullID(key.FolderID), normalizeFields(key.Fields), key.UpdatedAt.UTC()).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = key.ID
+			query := "UPDATE data_ssh_keys SET title=?,private_key=?,public_key=?,fingerprint=?,passphrase=?,meta=?,folder_id=?,fields=?,updated_at=? WHERE id=? AND user_id=?"
+			err := txExecAffected(ctx, tx, query, key.Title, key.PrivateKey, key.PublicKey, key.Fingerprint, key.Passphrase, key.Meta, nullID(key.FolderID), normalizeFields(key.Fields), key.UpdatedAt.UTC(), key.ID, key.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return sqliteSetItemTags(ctx, tx, model.ItemTypeSSH, id, key.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveSSHKey: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *SQLite) DeleteSSHKey(ctx context.Context, keyID, userID int) error {
+	err := s.deleteItem(ctx, model.ItemTypeSSH, keyID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteSSHKey: %w", err)
+	}
+
+	return err
+}
+
+func scanSQLiteSSHKey(row interface{ Scan(dest ...any) error }) (key model.DataSSHKey, err error) {
+	var ref itemRef
+	err = row.Scan(&key.ID, &key.Title, &key.PrivateKey, &key.PublicKey, &key.Fingerprint, &key.Passphrase, &key.Meta, &ref.folderID, &ref.tagIDs, &key.Fields, &key.UpdatedAt)
+	if err != nil {
+		return key, err
+	}
+
+	return key, ref.apply(&key.FolderID, &key.TagIDs)
+}
+
+func (s *SQLite) FindSSHKey(ctx context.Context, keyID, userID int) (key model.DataSSHKey, err error) {
+	query := "SELECT id,title,private_key,public_key,fingerprint,passphrase,meta,folder_id," + sqliteTagIDs(model.ItemTypeSSH) + ",fields,updated_at FROM data_ssh_keys WHERE id=? AND user_id=?"
+	key, err = scanSQLiteSSHKey(s.db.QueryRowContext(ctx, query, keyID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return key, ErrorNotFound
+		}
+
+		return key, fmt.Errorf("sqlite.FindSSHKey: %w", err)
+	}
+
+	return key, nil
+}
+
+func (s *SQLite) FindAllSSHKeys(ctx context.Context, userID int, filter model.ItemFilter) (keys []model.DataSSHKey, err error) {
+	where, args := itemFilterSQL(model.ItemTypeSSH, filter, []any{userID}, sqlitePlaceholder)
+	query := "SELECT id,title,private_key,public_key,fingerprint,passphrase,meta,folder_id," + sqliteTagIDs(model.ItemTypeSSH) + ",fields,updated_at FROM data_ssh_keys WHERE user_id=?" + where + " ORDER BY id DESC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return keys, fmt.Errorf("sqlite.FindAllSSHKeys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		key, err := scanSQLiteSSHKey(rows)
+		if err != nil {
+			return keys, fmt.Errorf("sqlite.FindAllSSHKeys: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return keys, fmt.Errorf("sqlite.FindAllSSHKeys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (s *SQLite) SaveIdentity(ctx context.Context, doc model.DataIdentity) (id int, err error) {
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		if doc.ID == 0 {
+			query := "INSERT INTO data_identities (user_id,title,kind,full_name,number,country,issue_date,expiry_date,address,meta,folder_id,fields,updated_at) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?) RETURNING id"
+			err := tx.QueryRowContext(ctx, query, doc.UserID, doc.Title, doc.Kind, doc.FullName, doc.Number, doc.Country, doc.IssueDate, doc.ExpiryDate, doc.Address, doc.Meta, nullID(doc.FolderID), normalizeFields(doc.Fields), doc.UpdatedAt.UTC()).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = doc.ID
+			query := "UPDATE data_identities SET title=?,kind=?,full_name=?,number=?,country=?,issue_date=?,expiry_date=?,address=?,meta=?,folder_id=?,fields=?,updated_at=? WHERE id=? AND user_id=?"
+			err := txExecAffected(ctx, tx, query, doc.Title, doc.Kind, doc.FullName, doc.Number, doc.Country, doc.IssueDate, doc.ExpiryDate, doc.Address, doc.Meta, nullID(doc.FolderID), normalizeFields(doc.Fields), doc.UpdatedAt.UTC(), doc.ID, doc.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return sqliteSetItemTags(ctx, tx, model.ItemTypeIdentity, id, doc.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveIdentity: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *SQLite) DeleteIdentity(ctx context.Context, docID, userID int) error {
+	err := s.deleteItem(ctx, model.ItemTypeIdentity, docID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteIdentity: %w", err)
+	}
+
+	return err
+}
+
+func scanSQLiteIdentity(row interface{ Scan(dest ...any) error }) (doc model.DataIdentity, err error) {
+	var ref itemRef
+	err = row.Scan(&doc.ID, &doc.Title, &doc.Kind, &doc.FullName, &doc.Number, &doc.Country, &doc.IssueDate, &doc.ExpiryDate, &doc.Address, &doc.Meta, &ref.folderID, &ref.tagIDs, &doc.Fields, &doc.UpdatedAt)
+	if err != nil {
+		return doc, err
+	}
+
+	return doc, ref.apply(&doc.FolderID, &doc.TagIDs)
+}
+
+func (s *SQLite) FindIdentity(ctx context.Context, docID, userID int) (doc model.DataIdentity, err error) {
+	query := "SELECT id,title,kind,full_name,number,country,issue_date,expiry_date,address,meta,folder_id," + sqliteTagIDs(model.ItemTypeIdentity) + ",fields,updated_at FROM data_identities WHERE id=? AND user_id=?"
+	doc, err = scanSQLiteIdentity(s.db.QueryRowContext(ctx, query, docID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return doc, ErrorNotFound
+		}
+
+		return doc, fmt.Errorf("sqlite.FindIdentity: %w", err)
+	}
+
+	return doc, nil
+}
+
+func (s *SQLite) FindAllIdentities(ctx context.Context, userID int, filter model.ItemFilter) (docs []model.DataIdentity, err error) {
+	where, args := itemFilterSQL(model.ItemTypeIdentity, filter, []any{userID}, sqlitePlaceholder)
+	query := "SELECT id,title,kind,full_name,number,country,issue_date,expiry_date,address,meta,folder_id," + sqliteTagIDs(model.ItemTypeIdentity) + ",fields,updated_at FROM data_identities WHERE user_id=?" + where + " ORDER BY id DESC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return docs, fmt.Errorf("sqlite.FindAllIdentities: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		doc, err := scanSQLiteIdentity(rows)
+		if err != nil {
+			return docs, fmt.Errorf("sqlite.FindAllIdentities: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return docs, fmt.Errorf("sqlite.FindAllIdentities: %w", err)
+	}
+
+	return docs, nil
+}
+
+func (s *SQLite) SaveCustomItem(ctx context.Context, item model.DataCustom) (id int, err error) {
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		if item.ID == 0 {
+			query := `INSERT INTO data_custom (user_id,template_id,title,"values",meta,folder_id,fields,updated_at) VALUES (?,?,?,?,?,?,?,?) RETURNING id`
+			err := tx.QueryRowContext(ctx, query, item.UserID, item.TemplateID, item.Title, item.Values, item.Meta, nullID(item.FolderID), normalizeFields(item.Fields), item.UpdatedAt.UTC()).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = item.ID
+			query := `UPDATE data_custom SET template_id=?,title=?,"values"=?,meta=?,folder_id=?,fields=?,updated_at=? WHERE id=? AND user_id=?`
+			err := txExecAffected(ctx, tx, query, item.TemplateID, item.Title, item.Values, item.Meta, nullID(item.FolderID), normalizeFields(item.Fields), item.UpdatedAt.UTC(), item.ID, item.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return sqliteSetItemTags(ctx, tx, model.ItemTypeCustom, id, item.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveCustomItem: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *SQLite) DeleteCustomItem(ctx context.Context, itemID, userID int) error {
+	err := s.deleteItem(ctx, model.ItemTypeCustom, itemID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteCustomItem: %w", err)
+	}
+
+	return err
+}
+
+func scanSQLiteCustomItem(row interface{ Scan(dest ...any) error }) (item model.DataCustom, err error) {
+	var ref itemRef
+	err = row.Scan(&item.ID, &item.TemplateID, &item.Title, &item.Values, &item.Meta, &ref.folderID, &ref.tagIDs, &item.Fields, &item.UpdatedAt)
+	if err != nil {
+		return item, err
+	}
+
+	return item, ref.apply(&item.FolderID, &item.TagIDs)
+}
+
+func (s *SQLite) FindCustomItem(ctx context.Context, itemID, userID int) (item model.DataCustom, err error) {
+	query := `SELECT id,template_id,title,"values",meta,folder_id,` + sqliteTagIDs(model.ItemTypeCustom) + ",fields,updated_at FROM data_custom WHERE id=? AND user_id=?"
+	item, err = scanSQLiteCustomItem(s.db.QueryRowContext(ctx, query, itemID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return item, ErrorNotFound
+		}
+
+		return item, fmt.Errorf("sqlite.FindCustomItem: %w", err)
+	}
+
+	return item, nil
+}
+
+func (s *SQLite) FindAllCustomItems(ctx context.Context, userID int, filter model.ItemFilter) (items []model.DataCustom, err error) {
+	where, args := itemFilterSQL(model.ItemTypeCustom, filter, []any{userID}, sqlitePlaceholder)
+	query := `SELECT id,template_id,title,"values",meta,folder_id,` + sqliteTagIDs(model.ItemTypeCustom) + ",fields,updated_at FROM data_custom WHERE user_id=?" + where + " ORDER BY id DESC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return items, fmt.Errorf("sqlite.FindAllCustomItems: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanSQLiteCustomItem(rows)
+		if err != nil {
+			return items, fmt.Errorf("sqlite.FindAllCustomItems: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return items, fmt.Errorf("sqlite.FindAllCustomItems: %w", err)
+	}
+
+	return items, nil
+}
+
+func (s *SQLite) SaveFolder(ctx context.Context, folder model.Folder) (id int, err error) {
+	if folder.ID == 0 {
+		query := "INSERT INTO folders (user_id,parent_id,name,updated_at) VALUES (?,?,?,?) RETURNING id"
+		err = s.db.QueryRowContext(ctx, query, folder.UserID, nullID(folder.ParentID), folder.Name, folder.UpdatedAt.UTC()).Scan(&id)
+	} else {
+		id = folder.ID
+		query := "UPDATE folders SET parent_id=?,name=?,updated_at=? WHERE id=? AND user_id=?"
+		err = s.execAffected(ctx, query, nullID(folder.ParentID), folder.Name, folder.UpdatedAt.UTC(), folder.ID, folder.UserID)
+		if errors.Is(err, ErrorNotFound) {
+			return id, ErrorNotFound
+		}
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveFolder: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteFolder удаляет папку вместе с вложенными (каскадом по parent_id); у записей из удалённых
+// папок folder_id обнуляется здесь, так как в SQLite на этот столбец нет внешнего ключа.
+func (s *SQLite) DeleteFolder(ctx context.Context, folderID, userID int) error {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, table := range itemTables {
+			query := "WITH RECURSIVE sub(id) AS (SELECT id FROM folders WHERE id=? AND user_id=? " +
+				"UNION ALL SELECT folders.id FROM folders JOIN sub ON folders.parent_id=sub.id) " +
+				"UPDATE " + table + " SET folder_id=NULL WHERE folder_id IN (SELECT id FROM sub)"
+			if _, err := tx.ExecContext(ctx, query, folderID, userID); err != nil {
+				return err
+			}
+		}
+
+		return txExecAffected(ctx, tx, "DELETE FROM folders WHERE id=? AND user_id=?", folderID, userID)
+	})
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteFolder: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) FindAllFolders(ctx context.Context, userID int) (folders []model.Folder, err error) {
+	query := "SELECT id,parent_id,name,updated_at FROM folders WHERE user_id=? ORDER BY id"
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return folders, fmt.Errorf("sqlite.FindAllFolders: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			folder   model.Folder
+			parentID sql.NullInt64
+		)
+		err = rows.Scan(&folder.ID, &parentID, &folder.Name, &folder.UpdatedAt)
+		if err != nil {
+			return folders, fmt.Errorf("sqlite.FindAllFolders: %w", err)
+		}
+		folder.ParentID = int(parentID.Int64)
+		folders = append(folders, folder)
+	}
+
+	if err = rows.Err(); err != nil {
+		return folders, fmt.Errorf("sqlite.FindAllFolders: %w", err)
+	}
+
+	return folders, nil
+}
+
+func (s *SQLite) SaveTag(ctx context.Context, tag model.Tag) (id int, err error) {
+	if tag.ID == 0 {
+		query := "INSERT INTO tags (user_id,name,updated_at) VALUES (?,?,?) RETURNING id"
+		err = s.db.QueryRowContext(ctx, query, tag.UserID, tag.Name, tag.UpdatedAt.UTC()).Scan(&id)
+	} else {
+		id = tag.ID
+		query := "UPDATE tags SET name=?,updated_at=? WHERE id=? AND user_id=?"
+		err = s.execAffected(ctx, query, tag.Name, tag.UpdatedAt.UTC(), tag.ID, tag.UserID)
+		if errors.Is(err, ErrorNotFound) {
+			return id, ErrorNotFound
+		}
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveTag: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *SQLite) DeleteTag(ctx context.Context, tagID, userID int) error {
+	err := s.execAffected(ctx, "DELETE FROM tags WHERE id=? AND user_id=?", tagID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteTag: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) FindAllTags(ctx context.Context, userID int) (tags []model.Tag, err error) {
+	query := "SELECT id,name,updated_at FROM tags WHERE user_id=? ORDER BY id"
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return tags, fmt.Errorf("sqlite.FindAllTags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag model.Tag
+		err = rows.Scan(&tag.ID, &tag.Name, &tag.UpdatedAt)
+		if err != nil {
+			return tags, fmt.Errorf("sqlite.FindAllTags: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return tags, fmt.Errorf("sqlite.FindAllTags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (s *SQLite) SaveTemplate(ctx context.Context, tpl model.Template) (id int, err error) {
+	if tpl.ID == 0 {
+		query := "INSERT INTO templates (user_id,name,schema,updated_at) VALUES (?,?,?,?) RETURNING id"
+		err = s.db.QueryRowContext(ctx, query, tpl.UserID, tpl.Name, tpl.Schema, tpl.UpdatedAt.UTC()).Scan(&id)
+	} else {
+		id = tpl.ID
+		query := "UPDATE templates SET name=?,schema=?,updated_at=? WHERE id=? AND user_id=?"
+		err = s.execAffected(ctx, query, tpl.Name, tpl.Schema, tpl.UpdatedAt.UTC(), tpl.ID, tpl.UserID)
+		if errors.Is(err, ErrorNotFound) {
+			return id, ErrorNotFound
+		}
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveTemplate: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteTemplate удаляет шаблон; записи по нему удаляются каскадно по template_id, их метки, вложения и доступы - здесь же.
+func (s *SQLite) DeleteTemplate(ctx context.Context, tplID, userID int) error {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, table := range []string{"item_tags", "attachments", "shares"} {
+			query := "DELETE FROM " + table + " WHERE item_type=? AND item_id IN (SELECT id FROM data_custom WHERE template_id=? AND user_id=?)"
+			if _, err := tx.ExecContext(ctx, query, model.ItemTypeCustom, tplID, userID); err != nil {
+				return err
+			}
+		}
+
+		return txExecAffected(ctx, tx, "DELETE FROM templates WHERE id=? AND user_id=?", tplID, userID)
+	})
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteTemplate: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) FindAllTemplates(ctx context.Context, userID int) (templates []model.Template, err error) {
+	query := "SELECT id,name,schema,updated_at FROM templates WHERE user_id=? ORDER BY id"
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return templates, fmt.Errorf("sqlite.FindAllTemplates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tpl model.Template
+		err = rows.Scan(&tpl.ID, &tpl.Name, &tpl.Schema, &tpl.UpdatedAt)
+		if err != nil {
+			return templates, fmt.Errorf("sqlite.FindAllTemplates: %w", err)
+		}
+		templates = append(templates, tpl)
+	}
+
+	if err = rows.Err(); err != nil {
+		return templates, fmt.Errorf("sqlite.FindAllTemplates: %w", err)
+	}
+
+	return templates, nil
+}
+
+func (s *SQLite) SaveAttachment(ctx context.Context, att model.Attachment) (id int, err error) {
+	query := "INSERT INTO attachments (user_id,item_type,item_id,filename,path,size,updated_at) VALUES (?,?,?,?,?,?,?) RETURNING id"
+	err = s.db.QueryRowContext(ctx, query, att.UserID, att.ItemType, att.ItemID, att.Filename, att.Path, att.Size, att.UpdatedAt.UTC()).Scan(&id)
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveAttachment: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *SQLite) DeleteAttachment(ctx context.Context, attID, userID int) error {
+	err := s.execAffected(ctx, "DELETE FROM attachments WHERE id=? AND user_id=?", attID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteAttachment: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) FindAttachment(ctx context.Context, attID, userID int) (att model.Attachment, err error) {
+	query := "SELECT id,item_type,item_id,filename,path,size,updated_at FROM attachments WHERE id=? AND user_id=?"
+	err = s.db.QueryRowContext(ctx, query, attID, userID).Scan(&att.ID, &att.ItemType, &att.ItemID, &att.Filename, &att.Path, &att.Size, &att.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return att, ErrorNotFound
+		}
+
+		return att, fmt.Errorf("sqlite.FindAttachment: %w", err)
+	}
+
+	return att, nil
+}
+
+func (s *SQLite) FindAllAttachments(ctx context.Context, userID int, filter model.AttachmentFilter) (atts []model.Attachment, err error) {
+	where, args := attachmentFilterSQL(filter, []any{userID}, sqlitePlaceholder)
+	query := "SELECT id,item_type,item_id,filename,path,size,updated_at FROM attachments WHERE user_id=?" + where + " ORDER BY id"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return atts, fmt.Errorf("sqlite.FindAllAttachments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var att model.Attachment
+		err = rows.Scan(&att.ID, &att.ItemType, &att.ItemID, &att.Filename, &att.Path, &att.Size, &att.UpdatedAt)
+		if err != nil {
+			return atts, fmt.Errorf("sqlite.FindAllAttachments: %w", err)
+		}
+		atts = append(atts, att)
+	}
+
+	if err = rows.Err(); err != nil {
+		return atts, fmt.Errorf("sqlite.FindAllAttachments: %w", err)
+	}
+
+	return atts, nil
+}
+
+// SaveUserKeys сохраняет пару ключей пользователя, заменяя прежнюю.
+func (s *SQLite) SaveUserKeys(ctx context.Context, keys model.UserKeys) error {
+	query := "INSERT INTO user_keys (user_id,public_key,private_key,updated_at) VALUES (?,?,?,?) " +
+		"ON CONFLICT (user_id) DO UPDATE SET public_key=excluded.public_key,private_key=excluded.private_key,updated_at=excluded.updated_at"
+	_, err := s.db.ExecContext(ctx, query, keys.UserID, keys.PublicKey, keys.PrivateKey, keys.UpdatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("sqlite.SaveUserKeys: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) FindUserKeys(ctx context.Context, userID int) (keys model.UserKeys, err error) {
+	query := "SELECT user_id,public_key,private_key,updated_at FROM user_keys WHERE user_id=?"
+	err = s.db.QueryRowContext(ctx, query, userID).Scan(&keys.UserID, &keys.PublicKey, &keys.PrivateKey, &keys.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return keys, ErrorNotFound
+		}
+
+		return keys, fmt.Errorf("sqlite.FindUserKeys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (s *SQLite) FindPublicKey(ctx context.Context, login string) (key model.PublicKey, err error) {
+	query := "SELECT u.id,u.login,k.public_key FROM users u JOIN user_keys k ON k.user_id=u.id WHERE u.login=?"
+	err = s.db.QueryRowContext(ctx, query, login).Scan(&key.UserID, &key.Login, &key.PublicKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return key, ErrorNotFound
+		}
+
+		return key, fmt.Errorf("sqlite.FindPublicKey: %w", err)
+	}
+
+	return key, nil
+}
+
+// SaveShare открывает запись получателю или обновляет доступ; получатель и запись доступа не меняются.
+func (s *SQLite) SaveShare(ctx context.Context, share model.Share) (id int, err error) {
+	if share.ID == 0 {
+		query := "INSERT INTO shares (user_id,recipient_id,item_type,item_id,access,item_key,owner_key,data,updated_at) VALUES (?,?,?,?,?,?,?,?,?) RETURNING id"
+		err = s.db.QueryRowContext(ctx, query, share.UserID, share.RecipientID, share.ItemType, share.ItemID, share.Access, share.ItemKey, share.OwnerKey, share.Data, share.UpdatedAt.UTC()).Scan(&id)
+	} else {
+		id = share.ID
+		query := "UPDATE shares SET access=?,item_key=?,owner_key=?,data=?,updated_at=? WHERE id=? AND user_id=? AND recipient_id=? AND item_type=? AND item_id=?"
+		err = s.execAffected(ctx, query, share.Access, share.ItemKey, share.OwnerKey, share.Data, share.UpdatedAt.UTC(), share.ID, share.UserID, share.RecipientID, share.ItemType, share.ItemID)
+		if errors.Is(err, ErrorNotFound) {
+			return id, ErrorNotFound
+		}
+	}
+
+	if isSQLiteUniqueViolation(err) {
+		return id, ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveShare: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateShareData сохраняет запись, измененную получателем.
+func (s *SQLite) UpdateShareData(ctx context.Context, share model.Share) error {
+	err := s.execAffected(ctx, "UPDATE shares SET data=?,updated_at=? WHERE id=? AND recipient_id=?", share.Data, share.UpdatedAt.UTC(), share.ID, share.RecipientID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.UpdateShareData: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) DeleteShare(ctx context.Context, shareID, userID int) error {
+	err := s.execAffected(ctx, "DELETE FROM shares WHERE id=? AND user_id=?", shareID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteShare: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) FindAllShares(ctx context.Context, userID int) (shares []model.Share, err error) {
+	shares, err = s.findShares(ctx, shareColumns+" WHERE s.user_id=? ORDER BY s.id", userID)
+	if err != nil {
+		return shares, fmt.Errorf("sqlite.FindAllShares: %w", err)
+	}
+
+	return shares, nil
+}
+
+func (s *SQLite) FindAllIncomingShares(ctx context.Context, recipientID int) (shares []model.Share, err error) {
+	shares, err = s.findShares(ctx, shareColumns+" WHERE s.recipient_id=? ORDER BY s.id", recipientID)
+	if err != nil {
+		return shares, fmt.Errorf("sqlite.FindAllIncomingShares: %w", err)
+	}
+
+	return shares, nil
+}
+
+func (s *SQLite) findShares(ctx context.Context, query string, args ...any) (shares []model.Share, err error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return shares, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v model.Share
+		err = rows.Scan(&v.ID, &v.Owner, &v.Recipient, &v.ItemType, &v.ItemID, &v.Access, &v.ItemKey, &v.OwnerKey, &v.Data, &v.UpdatedAt)
+		if err != nil {
+			return shares, err
+		}
+		shares = append(shares, v)
+	}
+
+	return shares, rows.Err()
+}
+
+// SaveOrganization создает организацию с владельцем ownerID или переименовывает существующую.
+func (s *SQLite) SaveOrganization(ctx context.Context, org model.Organization, ownerID int) (id int, err error) {
+	if org.ID != 0 {
+		err = s.execAffected(ctx, "UPDATE organizations SET name=?,updated_at=? WHERE id=?", org.Name, org.UpdatedAt.UTC(), org.ID)
+		if err != nil && !errors.Is(err, ErrorNotFound) {
+			return org.ID, fmt.Errorf("sqlite.SaveOrganization: %w", err)
+		}
+
+		return org.ID, err
+	}
+
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, "INSERT INTO organizations (name,updated_at) VALUES (?,?) RETURNING id", org.Name, org.UpdatedAt.UTC()).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO org_members (org_id,user_id,role,status,updated_at) VALUES (?,?,?,?,?)",
+			id, ownerID, model.RoleOwner, model.MemberStatusActive, org.UpdatedAt.UTC())
+
+		return err
+	})
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveOrganization: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteOrganization удаляет организацию; участники, коллекции и их записи удаляются каскадно.
+func (s *SQLite) DeleteOrganization(ctx context.Context, orgID int) error {
+	err := s.execAffected(ctx, "DELETE FROM organizations WHERE id=?", orgID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteOrganization: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) FindAllOrganizations(ctx context.Context, userID int) (orgs []model.Organization, err error) {
+	query := "SELECT o.id,o.name,m.role,m.status,o.updated_at FROM organizations o JOIN org_members m ON m.org_id=o.id WHERE m.user_id=? ORDER BY o.id"
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return orgs, fmt.Errorf("sqlite.FindAllOrganizations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v model.Organization
+		if err = rows.Scan(&v.ID, &v.Name, &v.Role, &v.Status, &v.UpdatedAt); err != nil {
+			return orgs, fmt.Errorf("sqlite.FindAllOrganizations: %w", err)
+		}
+		orgs = append(orgs, v)
+	}
+
+	return orgs, rows.Err()
+}
+
+// SaveMember добавляет участника или меняет его роль и состояние вместе с его ключами коллекций.
+func (s *SQLite) SaveMember(ctx context.Context, member model.Member) error {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		query := "INSERT INTO org_members (org_id,user_id,role,status,updated_at) VALUES (?,?,?,?,?) " +
+			"ON CONFLICT (org_id,user_id) DO UPDATE SET role=excluded.role,status=excluded.status,updated_at=excluded.updated_at"
+		_, err := tx.ExecContext(ctx, query, member.OrgID, member.UserID, member.Role, member.Status, member.UpdatedAt.UTC())
+		if err != nil {
+			return err
+		}
+
+		return sqliteSaveCollectionKeys(ctx, tx, member.Keys)
+	})
+	if err != nil {
+		return fmt.Errorf("sqlite.SaveMember: %w", err)
+	}
+
+	return nil
+}
+
+// sqliteSaveCollectionKeys сохраняет ключи коллекций участников, заменяя прежние.
+func sqliteSaveCollectionKeys(ctx context.Context, tx *sql.Tx, keys []model.CollectionKey) error {
+	for _, k := range keys {
+		query := "INSERT INTO collection_keys (collection_id,user_id,key) VALUES (?,?,?) " +
+			"ON CONFLICT (collection_id,user_id) DO UPDATE SET key=excluded.key"
+		if _, err := tx.ExecContext(ctx, query, k.CollectionID, k.UserID, k.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteMember исключает участника из организации вместе с его ключами коллекций.
+func (s *SQLite) DeleteMember(ctx context.Context, orgID, userID int) error {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM collection_keys WHERE user_id=? AND collection_id IN (SELECT id FROM collections WHERE org_id=?)", userID, orgID)
+		if err != nil {
+			return err
+		}
+
+		return txExecAffected(ctx, tx, "DELETE FROM org_members WHERE org_id=? AND user_id=?", orgID, userID)
+	})
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteMember: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) FindMember(ctx context.Context, orgID, userID int) (member model.Member, err error) {
+	members, err := s.findMembers(ctx, memberColumns+" WHERE m.org_id=? AND m.user_id=?", orgID, userID)
+	if err != nil {
+		return member, fmt.Errorf("sqlite.FindMember: %w", err)
+	}
+
+	if len(members) == 0 {
+		return member, ErrorNotFound
+	}
+
+	return members[0], nil
+}
+
+func (s *SQLite) FindAllMembers(ctx context.Context, orgID int) (members []model.Member, err error) {
+	members, err = s.findMembers(ctx, memberColumns+" WHERE m.org_id=? ORDER BY u.login", orgID)
+	if err != nil {
+		return members, fmt.Errorf("sqlite.FindAllMembers: %w", err)
+	}
+
+	return members, nil
+}
+
+func (s *SQLite) findMembers(ctx context.Context, query string, args ...any) (members []model.Member, err error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return members, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v model.Member
+		if err = rows.Scan(&v.OrgID, &v.UserID, &v.Login, &v.Role, &v.Status, &v.PublicKey, &v.UpdatedAt); err != nil {
+			return members, err
+		}
+		members = append(members, v)
+	}
+
+	return members, rows.Err()
+}
+
+// SaveCollection создает или переименовывает коллекцию и сохраняет ключи коллекции для участников.
+func (s *SQLite) SaveCollection(ctx context.Context, col model.Collection) (id int, err error) {
+	id = col.ID
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		if col.ID == 0 {
+			err := tx.QueryRowContext(ctx, "INSERT INTO collections (org_id,name,updated_at) VALUES (?,?,?) RETURNING id", col.OrgID, col.Name, col.UpdatedAt.UTC()).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			err := txExecAffected(ctx, tx, "UPDATE collections SET name=?,updated_at=? WHERE id=? AND org_id=?", col.Name, col.UpdatedAt.UTC(), col.ID, col.OrgID)
+			if err != nil {
+				return err
+			}
+		}
+
+		for i := range col.Keys {
+			col.Keys[i].CollectionID = id
+		}
+
+		return sqliteSaveCollectionKeys(ctx, tx, col.Keys)
+	})
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return id, fmt.Errorf("sqlite.SaveCollection: %w", err)
+	}
+
+	return id, err
+}
+
+// DeleteCollection удаляет коллекцию; записи и ключи коллекции удаляются каскадно.
+func (s *SQLite) DeleteCollection(ctx context.Context, colID int) error {
+	err := s.execAffected(ctx, "DELETE FROM collections WHERE id=?", colID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteCollection: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) FindCollection(ctx context.Context, colID int) (col model.Collection, err error) {
+	err = s.db.QueryRowContext(ctx, "SELECT id,org_id,name,updated_at FROM collections WHERE id=?", colID).Scan(&col.ID, &col.OrgID, &col.Name, &col.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return col, ErrorNotFound
+		}
+
+		return col, fmt.Errorf("sqlite.FindCollection: %w", err)
+	}
+
+	return col, nil
+}
+
+// FindAllCollections коллекции организаций, в которых пользователь участвует, с его ключом коллекции и ролью.
+func (s *SQLite) FindAllCollections(ctx context.Context, userID int) (cols []model.Collection, err error) {
+	query := "SELECT c.id,c.org_id,c.name,k.key,m.role,c.updated_at FROM collections c " +
+		"JOIN org_members m ON m.org_id=c.org_id AND m.user_id=? AND m.status=? " +
+		"JOIN collection_keys k ON k.collection_id=c.id AND k.user_id=? ORDER BY c.id"
+	rows, err := s.db.QueryContext(ctx, query, userID, model.MemberStatusActive, userID)
+	if err != nil {
+		return cols, fmt.Errorf("sqlite.FindAllCollections: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v model.Collection
+		if err = rows.Scan(&v.ID, &v.OrgID, &v.Name, &v.Key, &v.Role, &v.UpdatedAt); err != nil {
+			return cols, fmt.Errorf("sqlite.FindAllCollections: %w", err)
+		}
+		cols = append(cols, v)
+	}
+
+	return cols, rows.Err()
+}
+
+func (s *SQLite) SaveCollectionItem(ctx context.Context, item model.CollectionItem) (id int, err error) {
+	if item.ID == 0 {
+		query := "INSERT INTO collection_items (collection_id,user_id,item_type,data,updated_at) VALUES (?,?,?,?,?) RETURNING id"
+		err = s.db.QueryRowContext(ctx, query, item.CollectionID, nullID(item.UserID), item.ItemType, item.Data, item.UpdatedAt.UTC()).Scan(&id)
+		if err != nil {
+			return id, fmt.Errorf("sqlite.SaveCollectionItem: %w", err)
+		}
+
+		return id, nil
+	}
+
+	query := "UPDATE collection_items SET user_id=?,data=?,updated_at=? WHERE id=? AND collection_id=?"
+	err = s.execAffected(ctx, query, nullID(item.UserID), item.Data, item.UpdatedAt.UTC(), item.ID, item.CollectionID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return item.ID, fmt.Errorf("sqlite.SaveCollectionItem: %w", err)
+	}
+
+	return item.ID, err
+}
+
+func (s *SQLite) DeleteCollectionItem(ctx context.Context, itemID, colID int) error {
+	err := s.execAffected(ctx, "DELETE FROM collection_items WHERE id=? AND collection_id=?", itemID, colID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteCollectionItem: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) FindAllCollectionItems(ctx context.Context, colID int) (items []model.CollectionItem, err error) {
+	query := "SELECT id,collection_id,coalesce(user_id,0) AS user_id,item_type,data,updated_at FROM collection_items WHERE collection_id=? ORDER BY id"
+	rows, err := s.db.QueryContext(ctx, query, colID)
+	if err != nil {
+		return items, fmt.Errorf("sqlite.FindAllCollectionItems: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v model.CollectionItem
+		if err = rows.Scan(&v.ID, &v.CollectionID, &v.UserID, &v.ItemType, &v.Data, &v.UpdatedAt); err != nil {
+			return items, fmt.Errorf("sqlite.FindAllCollectionItems: %w", err)
+		}
+		items = append(items, v)
+	}
+
+	return items, rows.Err()
+}
+
+// SaveEmergencyAccess назначает доверенное лицо или меняет период ожидания и ключ хранилища.
+func (s *SQLite) SaveEmergencyAccess(ctx context.Context, access model.EmergencyAccess) (id int, err error) {
+	if access.ID == 0 {
+		query := "INSERT INTO emergency_access (user_id,grantee_id,wait_hours,key,status,requested_at,updated_at) VALUES (?,?,?,?,?,?,?) RETURNING id"
+		err = s.db.QueryRowContext(ctx, query, access.UserID, access.GranteeID, access.WaitHours, access.Key, access.Status, access.RequestedAt.UTC(), access.UpdatedAt.UTC()).Scan(&id)
+	} else {
+		id = access.ID
+		query := "UPDATE emergency_access SET wait_hours=?,key=?,updated_at=? WHERE id=? AND user_id=? AND grantee_id=?"
+		err = s.execAffected(ctx, query, access.WaitHours, access.Key, access.UpdatedAt.UTC(), access.ID, access.UserID, access.GranteeID)
+		if errors.Is(err, ErrorNotFound) {
+			return id, ErrorNotFound
+		}
+	}
+
+	if isSQLiteUniqueViolation(err) {
+		return id, ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("sqlite.SaveEmergencyAccess: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateEmergencyStatus сохраняет состояние экстренного доступа и время запроса.
+func (s *SQLite) UpdateEmergencyStatus(ctx context.Context, access model.EmergencyAccess) error {
+	query := "UPDATE emergency_access SET status=?,requested_at=?,updated_at=? WHERE id=?"
+	err := s.execAffected(ctx, query, access.Status, access.RequestedAt.UTC(), access.UpdatedAt.UTC(), access.ID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.UpdateEmergencyStatus: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) DeleteEmergencyAccess(ctx context.Context, accessID, userID int) error {
+	err := s.execAffected(ctx, "DELETE FROM emergency_access WHERE id=? AND user_id=?", accessID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteEmergencyAccess: %w", err)
+	}
+
+	return err
+}
+
+func (s *SQLite) FindEmergencyAccess(ctx context.Context, accessID int) (access model.EmergencyAccess, err error) {
+	list, err := s.findEmergencyAccess(ctx, emergencyColumns+" WHERE e.id=?", accessID)
+	if err != nil {
+		return access, fmt.Errorf("sqlite.FindEmergencyAccess: %w", err)
+	}
+
+	if len(list) == 0 {
+		return access, ErrorNotFound
+	}
+
+	return list[0], nil
+}
+
+func (s *SQLite) FindAllEmergencyAccess(ctx context.Context, userID int) (list []model.EmergencyAccess, err error) {
+	list, err = s.findEmergencyAccess(ctx, emergencyColumns+" WHERE e.user_id=? ORDER BY e.id", userID)
+	if err != nil {
+		return list, fmt.Errorf("sqlite.FindAllEmergencyAccess: %w", err)
+	}
+
+	return list, nil
+}
+
+func (s *SQLite) FindAllIncomingEmergencyAccess(ctx context.Context, granteeID int) (list []model.EmergencyAccess, err error) {
+	list, err = s.findEmergencyAccess(ctx, emergencyColumns+" WHERE e.grantee_id=? ORDER BY e.id", granteeID)
+	if err != nil {
+		return list, fmt.Errorf("sqlite.FindAllIncomingEmergencyAccess: %w", err)
+	}
+
+	return list, nil
+}
+
+func (s *SQLite) findEmergencyAccess(ctx context.Context, query string, args ...any) (list []model.EmergencyAccess, err error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v model.EmergencyAccess
+		err = rows.Scan(&v.ID, &v.UserID, &v.Grantor, &v.GranteeID, &v.Grantee, &v.WaitHours, &v.Key, &v.Status, &v.RequestedAt, &v.UpdatedAt)
+		if err != nil {
+			return list, err
+		}
+		list = append(list, v)
+	}
+
+	return list, rows.Err()
+}
+
+func (s *SQLite) SaveSecret(ctx context.Context, secret model.Secret) error {
+	query := "INSERT INTO secrets (id,user_id,data,max_views,expires_at,created_at) VALUES (?,?,?,?,?,?)"
+	_, err := s.db.ExecContext(ctx, query, secret.ID, secret.UserID, secret.Data, secret.MaxViews, secret.ExpiresAt.UTC(), secret.CreatedAt.UTC())
+	if isSQLiteUniqueViolation(err) {
+		return ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return fmt.Errorf("sqlite.SaveSecret: %w", err)
+	}
+
+	return nil
+}
+
+// OpenSecret засчитывает просмотр секрета и возвращает его; секрет, просмотренный max_views раз,
+// удаляется. Истекший или уже удаленный секрет - ErrorNotFound.
+func (s *SQLite) OpenSecret(ctx context.Context, secretID string, now time.Time) (secret model.Secret, err error) {
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		query := "UPDATE secrets SET views=views+1 WHERE id=? AND expires_at>? AND views<max_views " +
+			"RETURNING id,user_id,data,max_views,views,expires_at,created_at"
+		err := tx.QueryRowContext(ctx, query, secretID, now.UTC()).
+			Scan(&secret.ID, &secret.UserID, &secret.Data, &secret.MaxViews, &secret.Views, &secret.ExpiresAt, &secret.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrorNotFound
+		}
+
+		if err != nil || secret.Views < secret.MaxViews {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM secrets WHERE id=?", secretID)
+
+		return err
+	})
+
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return secret, fmt.Errorf("sqlite.OpenSecret: %w", err)
+	}
+
+	return secret, err
+}
+
+func (s *SQLite) DeleteSecret(ctx context.Context, secretID string, userID int) error {
+	err := s.execAffected(ctx, "DELETE FROM secrets WHERE id=? AND user_id=?", secretID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteSecret: %w", err)
+	}
+
+	return err
+}
+
+// FindAllSecrets секреты пользователя без содержимого.
+func (s *SQLite) FindAllSecrets(ctx context.Context, userID int) (secrets []model.Secret, err error) {
+	query := "SELECT id,user_id,max_views,views,expires_at,created_at FROM secrets WHERE user_id=? ORDER BY created_at"
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return secrets, fmt.Errorf("sqlite.FindAllSecrets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v model.Secret
+		if err = rows.Scan(&v.ID, &v.UserID, &v.MaxViews, &v.Views, &v.ExpiresAt, &v.CreatedAt); err != nil {
+			return secrets, fmt.Errorf("sqlite.FindAllSecrets: %w", err)
+		}
+		secrets = append(secrets, v)
+	}
+
+	return secrets, rows.Err()
+}
+
+func (s *SQLite) ClearExpiredSecrets(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM secrets WHERE expires_at < ?", time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("sqlite.ClearExpiredSecrets: %w", err)
+	}
+
+	return nil
+}
+
+func scanSQLiteUpload(row interface{ Scan(dest ...any) error }) (v model.Upload, err error) {
+	err = row.Scan(&v.ID, &v.UserID, &v.Size, &v.ChunkSize, &v.Offset, &v.SHA256, &v.ExpiresAt, &v.CreatedAt)
+
+	return v, err
+}
+
+func (s *SQLite) SaveUpload(ctx context.Context, upload model.Upload) error {
+	query := "INSERT INTO uploads (id,user_id,size,chunk_size,upload_offset,sha256,expires_at,created_at) VALUES (?,?,?,?,?,?,?,?)"
+	_, err := s.db.ExecContext(ctx, query, upload.ID, upload.UserID, upload.Size, upload.ChunkSize, upload.Offset,
+		upload.SHA256, upload.ExpiresAt.UTC(), upload.CreatedAt.UTC())
+	if isSQLiteUniqueViolation(err) {
+		return ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return fmt.Errorf("sqlite.SaveUpload: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) FindUpload(ctx context.Context, uploadID string, userID int) (upload model.Upload, err error) {
+	upload, err = scanSQLiteUpload(s.db.QueryRowContext(ctx, uploadColumns+" WHERE id=? AND user_id=?", uploadID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return upload, ErrorNotFound
+	}
+
+	if err != nil {
+		return upload, fmt.Errorf("sqlite.FindUpload: %w", err)
+	}
+
+	return upload, nil
+}
+
+// UpdateUploadOffset переводит смещение загрузки из from в to и продлевает срок жизни.
+// Если смещение уже изменилось (параллельная запись той же части) - ErrorNotFound.
+func (s *SQLite) UpdateUploadOffset(ctx context.Context, upload model.Upload, from int64) error {
+	query := "UPDATE uploads SET upload_offset=?,expires_at=? WHERE id=? AND user_id=? AND upload_offset=?"
+	err := s.execAffected(ctx, query, upload.Offset, upload.ExpiresAt.UTC(), upload.ID, upload.UserID, from)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.UpdateUploadOffset: %w", err)
+	}
+
+	return err
+}
+
+// CompleteUpload сохраняет проверенный хеш содержимого загрузки и новый срок жизни.
+// Если загрузка уже завершена или ее нет - ErrorNotFound.
+func (s *SQLite) CompleteUpload(ctx context.Context, upload model.Upload) error {
+	query := "UPDATE uploads SET sha256=?,expires_at=? WHERE id=? AND user_id=? AND sha256=''"
+	err := s.execAffected(ctx, query, upload.SHA256, upload.ExpiresAt.UTC(), upload.ID, upload.UserID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.CompleteUpload: %w", err)
+	}
+
+	return err
+}
+
+// FindCompletedUpload завершенная загрузка пользователя с содержимым sha256, срок жизни которой не истек к now.
+func (s *SQLite) FindCompletedUpload(ctx context.Context, userID int, sha256 string, now time.Time) (upload model.Upload, err error) {
+	query := uploadColumns + " WHERE user_id=? AND sha256=? AND expires_at>=? ORDER BY expires_at DESC LIMIT 1"
+	upload, err = scanSQLiteUpload(s.db.QueryRowContext(ctx, query, userID, sha256, now.UTC()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return upload, ErrorNotFound
+	}
+
+	if err != nil {
+		return upload, fmt.Errorf("sqlite.FindCompletedUpload: %w", err)
+	}
+
+	return upload, nil
+}
+
+func (s *SQLite) DeleteUpload(ctx context.Context, uploadID string, userID int) error {
+	err := s.execAffected(ctx, "DELETE FROM uploads WHERE id=? AND user_id=?", uploadID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("sqlite.DeleteUpload: %w", err)
+	}
+
+	return err
+}
+
+// FindExpiredUploads загрузки всех пользователей, срок жизни которых истек к моменту now.
+func (s *SQLite) FindExpiredUploads(ctx context.Context, now time.Time) (uploads []model.Upload, err error) {
+	rows, err := s.db.QueryContext(ctx, uploadColumns+" WHERE expires_at<? ORDER BY expires_at", now.UTC())
+	if err != nil {
+		return uploads, fmt.Errorf("sqlite.FindExpiredUploads: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v, err := scanSQLiteUpload(rows)
+		if err != nil {
+			return uploads, fmt.Errorf("sqlite.FindExpiredUploads: %w", err)
+		}
+		uploads = append(uploads, v)
+	}
+
+	return uploads, rows.Err()
+}
+
+// GetUsage объем данных и число записей пользователя; квоты заполняет сервис.
+func (s *SQLite) GetUsage(ctx context.Context, userID int) (usage model.Usage, err error) {
+	query, args := usageSQL(userID, func(column string) string { return "length(CAST(" + column + " AS blob))" }, sqlitePlaceholder)
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&usage.Bytes, &usage.Items)
+	if err != nil {
+		return usage, fmt.Errorf("sqlite.GetUsage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// FindFileRefs все файлы и вложения пользователей, ссылающиеся на содержимое в хранилище файлов.
+func (s *SQLite) FindFileRefs(ctx context.Context) (refs []model.FileRef, err error) {
+	rows, err := s.db.QueryContext(ctx, fileRefsAllSQL)
+	if err != nil {
+		return refs, fmt.Errorf("sqlite.FindFileRefs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v model.FileRef
+		if err = rows.Scan(&v.Table, &v.ID, &v.UserID, &v.Path); err != nil {
+			return refs, fmt.Errorf("sqlite.FindFileRefs: %w", err)
+		}
+		refs = append(refs, v)
+	}
+
+	return refs, rows.Err()
+}
+
+// FindUploadIDs идентификаторы всех незавершенных загрузок, в том числе просроченных.
+func (s *SQLite) FindUploadIDs(ctx context.Context) (ids []string, err error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id FROM uploads ORDER BY id")
+	if err != nil {
+		return ids, fmt.Errorf("sqlite.FindUploadIDs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return ids, fmt.Errorf("sqlite.FindUploadIDs: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// CountFileRefs число записей всех пользователей (файлов и вложений), ссылающихся на содержимое по пути path.
+func (s *SQLite) CountFileRefs(ctx context.Context, path string) (refs int, err error) {
+	err = s.db.QueryRowContext(ctx, fileRefsSQL(sqlitePlaceholder), path, path).Scan(&refs)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite.CountFileRefs: %w", err)
+	}
+
+	return refs, nil
+}
+
+// CountUserFileRefs число файлов и вложений пользователя userID, ссылающихся на содержимое по пути path.
+func (s *SQLite) CountUserFileRefs(ctx context.Context, userID int, path string) (refs int, err error) {
+	err = s.db.QueryRowContext(ctx, userFileRefsSQL(sqlitePlaceholder), userID, path, userID, path).Scan(&refs)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite.CountUserFileRefs: %w", err)
+	}
+
+	return refs, nil
+}
+
+const (
+	// fileLockTTL срок аренды блокировки содержимого: блокировку процесса, завершившегося
+	// не сняв ее, другие процессы перехватывают по истечении срока
+	fileLockTTL = time.Minute
+	// fileLockRetry пауза между попытками взять занятую блокировку
+	fileLockRetry = 10 * time.Millisecond
+)
+
+// LockFile выполняет fn под блокировкой содержимого path, общей для процессов, работающих с файлом БД
+// (сервер и server gc). Единственное соединение нельзя занять транзакцией на время fn, поэтому блокировка -
+// арендованная строка file_locks: ее вставляет владелец и удаляет после fn, просроченную может перехватить другой.
+func (s *SQLite) LockFile(ctx context.Context, path string, fn func() error) error {
+	owner, err := hash.GenerateRandomString(16)
+	if err != nil {
+		return fmt.Errorf("sqlite.LockFile: %w", err)
+	}
+
+	query := `INSERT INTO file_locks (path,owner,expires_at) VALUES (?,?,?)
+		ON CONFLICT (path) DO UPDATE SET owner=excluded.owner,expires_at=excluded.expires_at WHERE file_locks.expires_at<?`
+
+	for {
+		now := time.Now()
+		res, err := s.db.ExecContext(ctx, query, path, owner, now.Add(fileLockTTL).UTC(), now.UTC())
+		if err != nil {
+			return fmt.Errorf("sqlite.LockFile: %w", err)
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("sqlite.LockFile: %w", err)
+		}
+
+		if n > 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("sqlite.LockFile: %w", ctx.Err())
+		case <-time.After(fileLockRetry):
+		}
+	}
+
+	err = fn()
+
+	// блокировка снимается и после отмены ctx, иначе содержимое осталось бы заблокированным до конца аренды
+	_, errDel := s.db.ExecContext(context.Background(), "DELETE FROM file_locks WHERE path=? AND owner=?", path, owner)
+	if err != nil {
+		return err
+	}
+
+	if errDel != nil {
+		return fmt.Errorf("sqlite.LockFile: %w", errDel)
+	}
+
+	return nil
+}
@@ -8,11 +8,13 @@ import (
 	"github.com/rainset/gophkeeper/pkg/crypt"
 	"github.com/rainset/gophkeeper/pkg/hash"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rainset/gophkeeper/internal/server/model"
 )
@@ -28,27 +30,145 @@ type Interface interface {
 
 	SaveCard(ctx context.Context, card model.DataCard) (id int, err error)
 	FindCard(ctx context.Context, cardID, userID int) (card model.DataCard, err error)
-	FindAllCards(ctx context.Context, userID int) (cards []model.DataCard, err error)
+	FindAllCards(ctx context.Context, userID int, filter model.ItemFilter) (cards []model.DataCard, err error)
 	DeleteCard(ctx context.Context, cardID, userID int) error
 
 	SaveFile(ctx context.Context, file model.DataFile) (id int, err error)
 	DeleteFile(ctx context.Context, fileID, userID int) error
 	FindFile(ctx context.Context, fileID, userID int) (file model.DataFile, err error)
-	FindAllFiles(ctx context.Context, userID int) (files []model.DataFile, err error)
+	FindAllFiles(ctx context.Context, userID int, filter model.ItemFilter) (files []model.DataFile, err error)
 
 	SaveCred(ctx context.Context, cred model.DataCred) (id int, err error)
 	DeleteCred(ctx context.Context, credID, userID int) error
 	FindCred(ctx context.Context, credID, userID int) (cred model.DataCred, err error)
-	FindAllCreds(ctx context.Context, userID int) (creds []model.DataCred, err error)
+	FindAllCreds(ctx context.Context, userID int, filter model.ItemFilter) (creds []model.DataCred, err error)
 
 	SaveText(ctx context.Context, text model.DataText) (id int, err error)
 	DeleteText(ctx context.Context, textID, userID int) error
 	FindText(ctx context.Context, textID, userID int) (text model.DataText, err error)
-	FindAllTexts(ctx context.Context, userID int) (texts []model.DataText, err error)
+	FindAllTexts(ctx context.Context, userID int, filter model.ItemFilter) (texts []model.DataText, err error)
+
+	SaveSSHKey(ctx context.Context, key model.DataSSHKey) (id int, err error)
+	DeleteSSHKey(ctx context.Context, keyID, userID int) error
+	FindSSHKey(ctx context.Context, keyID, userID int) (key model.DataSSHKey, err error)
+	FindAllSSHKeys(ctx context.Context, userID int, filter model.ItemFilter) (keys []model.DataSSHKey, err error)
+
+	SaveIdentity(ctx context.Context, doc model.DataIdentity) (id int, err error)
+	DeleteIdentity(ctx context.Context, docID, userID int) error
+	FindIdentity(ctx context.Context, docID, userID int) (doc model.DataIdentity, err error)
+	FindAllIdentities(ctx context.Context, userID int, filter model.ItemFilter) (docs []model.DataIdentity, err error)
+
+	SaveCustomItem(ctx context.Context, item model.DataCustom) (id int, err error)
+	DeleteCustomItem(ctx context.Context, itemID, userID int) error
+	FindCustomItem(ctx context.Context, itemID, userID int) (item model.DataCustom, err error)
+	FindAllCustomItems(ctx context.Context, userID int, filter model.ItemFilter) (items []model.DataCustom, err error)
+
+	SaveFolder(ctx context.Context, folder model.Folder) (id int, err error)
+	DeleteFolder(ctx context.Context, folderID, userID int) error
+	FindAllFolders(ctx context.Context, userID int) (folders []model.Folder, err error)
+
+	SaveTag(ctx context.Context, tag model.Tag) (id int, err error)
+	DeleteTag(ctx context.Context, tagID, userID int) error
+	FindAllTags(ctx context.Context, userID int) (tags []model.Tag, err error)
+
+	SaveTemplate(ctx context.Context, tpl model.Template) (id int, err error)
+	DeleteTemplate(ctx context.Context, tplID, userID int) error
+	FindAllTemplates(ctx context.Context, userID int) (templates []model.Template, err error)
+
+	SaveAttachment(ctx context.Context, att model.Attachment) (id int, err error)
+	DeleteAttachment(ctx context.Context, attID, userID int) error
+	FindAttachment(ctx context.Context, attID, userID int) (att model.Attachment, err error)
+	FindAllAttachments(ctx context.Context, userID int, filter model.AttachmentFilter) (atts []model.Attachment, err error)
+
+	SaveUserKeys(ctx context.Context, keys model.UserKeys) error
+	FindUserKeys(ctx context.Context, userID int) (keys model.UserKeys, err error)
+	FindPublicKey(ctx context.Context, login string) (key model.PublicKey, err error)
+
+	SaveShare(ctx context.Context, share model.Share) (id int, err error)
+	UpdateShareData(ctx context.Context, share model.Share) error
+	DeleteShare(ctx context.Context, shareID, userID int) error
+	FindAllShares(ctx context.Context, userID int) (shares []model.Share, err error)
+	FindAllIncomingShares(ctx context.Context, recipientID int) (shares []model.Share, err error)
+
+	SaveOrganization(ctx context.Context, org model.Organization, ownerID int) (id int, err error)
+	DeleteOrganization(ctx context.Context, orgID int) error
+	FindAllOrganizations(ctx context.Context, userID int) (orgs []model.Organization, err error)
+
+	SaveMember(ctx context.Context, member model.Member) error
+	DeleteMember(ctx context.Context, orgID, userID int) error
+	FindMember(ctx context.Context, orgID, userID int) (member model.Member, err error)
+	FindAllMembers(ctx context.Context, orgID int) (members []model.Member, err error)
+
+	SaveCollection(ctx context.Context, col model.Collection) (id int, err error)
+	DeleteCollection(ctx context.Context, colID int) error
+	FindCollection(ctx context.Context, colID int) (col model.Collection, err error)
+	FindAllCollections(ctx context.Context, userID int) (cols []model.Collection, err error)
+
+	SaveCollectionItem(ctx context.Context, item model.CollectionItem) (id int, err error)
+	DeleteCollectionItem(ctx context.Context, itemID, colID int) error
+	FindAllCollectionItems(ctx context.Context, colID int) (items []model.CollectionItem, err error)
+
+	SaveEmergencyAccess(ctx context.Context, access model.EmergencyAccess) (id int, err error)
+	UpdateEmergencyStatus(ctx context.Context, access model.EmergencyAccess) error
+	DeleteEmergencyAccess(ctx context.Context, accessID, userID int) error
+	FindEmergencyAccess(ctx context.Context, accessID int) (access model.EmergencyAccess, err error)
+	FindAllEmergencyAccess(ctx context.Context, userID int) (list []model.EmergencyAccess, err error)
+	FindAllIncomingEmergencyAccess(ctx context.Context, granteeID int) (list []model.EmergencyAccess, err error)
+
+	SaveSecret(ctx context.Context, secret model.Secret) error
+	OpenSecret(ctx context.Context, secretID string, now time.Time) (secret model.Secret, err error)
+	DeleteSecret(ctx context.Context, secretID string, userID int) error
+	FindAllSecrets(ctx context.Context, userID int) (secrets []model.Secret, err error)
+	ClearExpiredSecrets(ctx context.Context) error
+
+	SaveUpload(ctx context.Context, upload model.Upload) error
+	FindUpload(ctx context.Context, uploadID string, userID int) (upload model.Upload, err error)
+	UpdateUploadOffset(ctx context.Context, upload model.Upload, from int64) error
+	CompleteUpload(ctx context.Context, upload model.Upload) error
+	FindCompletedUpload(ctx context.Context, userID int, sha256 string, now time.Time) (upload model.Upload, err error)
+	DeleteUpload(ctx context.Context, uploadID string, userID int) error
+	FindExpiredUploads(ctx context.Context, now time.Time) (uploads []model.Upload, err error)
+
+	GetUsage(ctx context.Context, userID int) (usage model.Usage, err error)
+	CountFileRefs(ctx context.Context, path string) (refs int, err error)
+	CountUserFileRefs(ctx context.Context, userID int, path string) (refs int, err error)
+	LockFile(ctx context.Context, path string, fn func() error) error
+	FindFileRefs(ctx context.Context) (refs []model.FileRef, err error)
+	FindUploadIDs(ctx context.Context) (ids []string, err error)
+
+	Close()
+}
+
+// Open выбирает реализацию хранилища по схеме DSN:
+// postgres:// и postgresql:// - PostgreSQL, sqlite:// (sqlite:, file:) - встроенная SQLite,
+// memory: - хранилище в памяти процесса.
+func Open(ctx context.Context, dataSourceName string) (Interface, error) {
+	scheme, _, found := strings.Cut(dataSourceName, ":")
+	if !found {
+		return nil, fmt.Errorf("storage.Open: %w: %q", ErrorUnknownDSN, dataSourceName)
+	}
+
+	switch strings.ToLower(scheme) {
+	case "postgres", "postgresql":
+		db, err := pgxpool.New(ctx, dataSourceName)
+		if err != nil {
+			return nil, fmt.Errorf("storage.Open: %w", err)
+		}
+
+		return newDatabase(db), nil
+	case "sqlite", "sqlite3", "file":
+		return NewSQLite(ctx, dataSourceName)
+	case "memory":
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("storage.Open: %w: %q", ErrorUnknownDSN, scheme)
+	}
 }
 
 type Database struct {
 	pgx *pgxpool.Pool
+	// fileLockSlots ограничивает число соединений, занятых блокировками содержимого, см. LockFile
+	fileLockSlots chan struct{}
 }
 
 func New(ctx context.Context, dataSourceName string) *Database {
@@ -61,8 +181,19 @@ func New(ctx context.Context, dataSourceName string) *Database {
 
 	log.Print("DB: connection initialized...")
 
+	return newDatabase(db)
+}
+
+// newDatabase хранилище поверх пула соединений; блокировкам содержимого отдается не больше половины пула.
+func newDatabase(db *pgxpool.Pool) *Database {
+	slots := int(db.Config().MaxConns) / 2
+	if slots < 1 {
+		slots = 1
+	}
+
 	return &Database{
-		pgx: db,
+		pgx:           db,
+		fileLockSlots: make(chan struct{}, slots),
 	}
 }
 
@@ -75,7 +206,15 @@ func (d *Database) GetSignKey(ctx context.Context, login, password string) (sign
 
 	sql := "SELECT sign_key FROM users WHERE login = $1 AND password = $2"
 	err = d.pgx.QueryRow(ctx, sql, login, passHash).Scan(&signKey)
-	return signKey, fmt.Errorf("db.GetSignKey: %w", err)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return signKey, ErrorUserCredentials
+		}
+
+		return signKey, fmt.Errorf("db.GetSignKey: %w", err)
+	}
+
+	return signKey, nil
 }
 
 func (d *Database) CreateUser(ctx context.Context, user model.User) (userID int, err error) {
@@ -99,9 +238,11 @@ func (d *Database) CreateUser(ctx context.Context, user model.User) (userID int,
 				return userID, ErrorUserAlreadyExists
 			}
 		}
+
+		return userID, fmt.Errorf("db.CreateUser: %w", err)
 	}
 
-	return userID, fmt.Errorf("db.CreateUser: %w", err)
+	return userID, nil
 }
 func (d *Database) GetUserIDByCredentials(ctx context.Context, login, password string) (userID int, err error) {
 	var qPass string
@@ -111,27 +252,38 @@ func (d *Database) GetUserIDByCredentials(ctx context.Context, login, password s
 
 	err = d.pgx.QueryRow(ctx, sql, login).Scan(&userID, &qPass)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrorUserCredentials
+		}
+
 		return userID, fmt.Errorf("db.GetUserIDByCredentials: %w", err)
 	}
 
 	if hash != qPass {
-		return userID, ErrorUserCredentials
+		return 0, ErrorUserCredentials
 	}
 
-	return userID, fmt.Errorf("db.GetUserIDByCredentials: %w", err)
+	return userID, nil
 }
 
 func (d *Database) SetRefreshToken(ctx context.Context, in model.RefreshToken) error {
 	sql := "INSERT INTO refresh_tokens (user_id, token , created_at, expired_at)  VALUES ($1, $2, $3, $4)"
 	_, err := d.pgx.Exec(ctx, sql, in.UserID, in.Token, time.Now(), in.ExpiredAt)
+	if err != nil {
+		return fmt.Errorf("db.SetRefreshToken: %w", err)
+	}
 
-	return fmt.Errorf("db.SetRefreshToken: %w", err)
+	return nil
 }
 func (d *Database) GetRefreshTokenUserID(ctx context.Context, token string) (userID int, err error) {
 	sql := "SELECT user_id FROM refresh_tokens WHERE token=$1 AND expired_at>NOW()"
 
 	err = d.pgx.QueryRow(ctx, sql, token).Scan(&userID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return userID, ErrorNotFound
+		}
+
 		return userID, fmt.Errorf("db.GetRefreshTokenUserID: %w", err)
 	}
 
@@ -141,18 +293,90 @@ func (d *Database) GetRefreshTokenUserID(ctx context.Context, token string) (use
 func (d *Database) ClearExpiredRefreshTokens(ctx context.Context) error {
 	sql := "DELETE FROM refresh_tokens WHERE expired_at < NOW()"
 	_, err := d.pgx.Exec(ctx, sql)
+	if err != nil {
+		return fmt.Errorf("db.ClearExpiredRefreshTokens: %w", err)
+	}
+
+	return nil
+}
+
+// pgTagIDs столбец tag_ids выборки записей: метки из item_tags по возрастанию id.
+func pgTagIDs(itemType string) string {
+	return fmt.Sprintf("ARRAY(SELECT tag_id FROM item_tags WHERE item_type='%s' AND item_id=%s.id ORDER BY tag_id) AS tag_ids",
+		itemType, itemTables[itemType])
+}
+
+// setItemTags заменяет метки записи в рамках транзакции сохранения.
+func setItemTags(ctx context.Context, tx pgx.Tx, itemType string, itemID int, tagIDs []int) error {
+	_, err := tx.Exec(ctx, "DELETE FROM item_tags WHERE item_type=$1 AND item_id=$2", itemType, itemID)
+	if err != nil {
+		return err
+	}
+
+	for _, tagID := range normalizeTagIDs(tagIDs) {
+		_, err = tx.Exec(ctx, "INSERT INTO item_tags (item_type,item_id,tag_id) VALUES ($1,$2,$3)", itemType, itemID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteItem удаляет запись пользователя вместе с её метками.
+func (d *Database) deleteItem(ctx context.Context, itemType string, itemID, userID int) error {
+	return pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		sql := fmt.Sprintf("DELETE FROM %s WHERE id=$1 AND user_id=$2", itemTables[itemType])
+		tag, err := tx.Exec(ctx, sql, itemID, userID)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return ErrorNotFound
+		}
+
+		_, err = tx.Exec(ctx, "DELETE FROM item_tags WHERE item_type=$1 AND item_id=$2", itemType, itemID)
+		if err != nil {
+			return err
+		}
 
-	return fmt.Errorf("db.ClearExpiredRefreshTokens: %w", err)
+		for _, table := range []string{"attachments", "shares"} {
+			sql := "DELETE FROM " + table + " WHERE item_type=$1 AND item_id=$2 AND user_id=$3"
+			if _, err = tx.Exec(ctx, sql, itemType, itemID, userID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 func (d *Database) SaveCard(ctx context.Context, card model.DataCard) (id int, err error) {
-	if card.ID == 0 {
-		sql := "INSERT INTO data_cards (user_id,title,number,date,cvv,meta,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id"
-		err = d.pgx.QueryRow(ctx, sql, card.UserID, card.Title, card.Number, card.Date, card.Cvv, card.Meta, card.UpdatedAt).Scan(&id)
-	} else {
-		id = card.ID
-		sql := "UPDATE data_cards SET title=$1,number=$2,date=$3,cvv=$4,meta=$5,updated_at=$6 WHERE user_id=$7 AND id=$8"
-		_, err = d.pgx.Exec(ctx, sql, card.Title, card.Number, card.Date, card.Cvv, card.Meta, card.UpdatedAt, card.UserID, card.ID)
+	err = pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		if card.ID == 0 {
+			sql := "INSERT INTO data_cards (user_id,title,number,date,cvv,meta,folder_id,fields,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING id"
+			err := tx.QueryRow(ctx, sql, card.UserID, card.Title, card.Number, card.Date, card.Cvv, card.Meta, nullID(card.FolderID), normalizeFields(card.Fields), card.UpdatedAt).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = card.ID
+			sql := "UPDATE data_cards SET title=$1,number=$2,date=$3,cvv=$4,meta=$5,folder_id=$6,fields=$7,updated_at=$8 WHERE user_id=$9 AND id=$10"
+			tag, err := tx.Exec(ctx, sql, card.Title, card.Number, card.Date, card.Cvv, card.Meta, nullID(card.FolderID), normalizeFields(card.Fields), card.UpdatedAt, card.UserID, card.ID)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return ErrorNotFound
+			}
+		}
+
+		return setItemTags(ctx, tx, model.ItemTypeCard, id, card.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
 	}
 
 	var pgErr *pgconn.PgError
@@ -162,35 +386,69 @@ func (d *Database) SaveCard(ctx context.Context, card model.DataCard) (id int, e
 		}
 	}
 
-	return id, fmt.Errorf("db.SaveCard: %w", err)
+	if err != nil {
+		return id, fmt.Errorf("db.SaveCard: %w", err)
+	}
+
+	return id, nil
 }
 func (d *Database) FindCard(ctx context.Context, cardID, userID int) (card model.DataCard, err error) {
-	sql := "SELECT id,title,number,date,cvv,meta,updated_at FROM data_cards WHERE id=$1 AND user_id = $2"
+	sql := "SELECT id,title,number,date,cvv,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeCard) + ",fields,updated_at FROM data_cards WHERE id=$1 AND user_id = $2"
 	err = pgxscan.Get(ctx, d.pgx, &card, sql, cardID, userID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return card, ErrorNotFound
+		}
+
+		return card, fmt.Errorf("db.FindCard: %w", err)
+	}
 
-	return card, fmt.Errorf("db.FindCard: %w", err)
+	return card, nil
 }
-func (d *Database) FindAllCards(ctx context.Context, userID int) (cards []model.DataCard, err error) {
-	sql := "SELECT id,title,number,date,cvv,meta,updated_at FROM data_cards WHERE user_id = $1 ORDER BY id DESC"
-	err = pgxscan.Select(ctx, d.pgx, &cards, sql, userID)
+func (d *Database) FindAllCards(ctx context.Context, userID int, filter model.ItemFilter) (cards []model.DataCard, err error) {
+	where, args := itemFilterSQL(model.ItemTypeCard, filter, []any{userID}, pgPlaceholder)
+	sql := "SELECT id,title,number,date,cvv,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeCard) + ",fields,updated_at FROM data_cards WHERE user_id = $1" + where + " ORDER BY id DESC"
+	err = pgxscan.Select(ctx, d.pgx, &cards, sql, args...)
+	if err != nil {
+		return cards, fmt.Errorf("db.FindAllCards: %w", err)
+	}
 
-	return cards, fmt.Errorf("db.FindAllCards: %w", err)
+	return cards, nil
 }
 func (d *Database) DeleteCard(ctx context.Context, cardID, userID int) (err error) {
-	sql := "DELETE FROM data_cards WHERE id=$1 AND user_id =$2"
-	_, err = d.pgx.Exec(ctx, sql, cardID, userID)
+	err = d.deleteItem(ctx, model.ItemTypeCard, cardID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("db.DeleteCard: %w", err)
+	}
 
-	return fmt.Errorf("db.DeleteCard: %w", err)
+	return err
 }
 
 func (d *Database) SaveFile(ctx context.Context, file model.DataFile) (id int, err error) {
-	if file.ID == 0 {
-		sql := "INSERT INTO data_files (user_id,title,filename,path,meta,updated_at) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id"
-		err = d.pgx.QueryRow(ctx, sql, file.UserID, file.Title, file.Filename, file.Path, file.Meta, file.UpdatedAt).Scan(&id)
-	} else {
-		id = file.ID
-		sql := "UPDATE data_files SET title=$1,filename=$2,path=$3,meta=$4, updated_at=$5 WHERE user_id=$6 AND id=$7"
-		_, err = d.pgx.Exec(ctx, sql, file.Title, file.Filename, file.Path, file.Meta, file.UpdatedAt, file.UserID, file.ID)
+	err = pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		if file.ID == 0 {
+			sql := "INSERT INTO data_files (user_id,title,filename,path,size,sha256,meta,folder_id,fields,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING id"
+			err := tx.QueryRow(ctx, sql, file.UserID, file.Title, file.Filename, file.Path, file.Size, file.SHA256, file.Meta, nullID(file.FolderID), normalizeFields(file.Fields), file.UpdatedAt).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = file.ID
+			sql := "UPDATE data_files SET title=$1,filename=$2,path=$3,size=$4,sha256=$5,meta=$6,folder_id=$7,fields=$8,updated_at=$9 WHERE user_id=$10 AND id=$11"
+			tag, err := tx.Exec(ctx, sql, file.Title, file.Filename, file.Path, file.Size, file.SHA256, file.Meta, nullID(file.FolderID), normalizeFields(file.Fields), file.UpdatedAt, file.UserID, file.ID)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return ErrorNotFound
+			}
+		}
+
+		return setItemTags(ctx, tx, model.ItemTypeFile, id, file.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
 	}
 
 	var pgErr *pgconn.PgError
@@ -200,35 +458,69 @@ func (d *Database) SaveFile(ctx context.Context, file model.DataFile) (id int, e
 		}
 	}
 
-	return id, fmt.Errorf("db.SaveFile: %w", err)
+	if err != nil {
+		return id, fmt.Errorf("db.SaveFile: %w", err)
+	}
+
+	return id, nil
 }
 func (d *Database) DeleteFile(ctx context.Context, fileID, userID int) (err error) {
-	sql := "DELETE  FROM data_files WHERE id=$1 AND user_id =$2"
-	_, err = d.pgx.Exec(ctx, sql, fileID, userID)
+	err = d.deleteItem(ctx, model.ItemTypeFile, fileID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("db.DeleteFile: %w", err)
+	}
 
-	return fmt.Errorf("db.DeleteFile: %w", err)
+	return err
 }
 func (d *Database) FindFile(ctx context.Context, fileID, userID int) (file model.DataFile, err error) {
-	sql := "SELECT id,title,filename,path,meta,updated_at FROM data_files WHERE id=$1 AND user_id = $2"
+	sql := "SELECT id,title,filename,path,size,sha256,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeFile) + ",fields,updated_at FROM data_files WHERE id=$1 AND user_id = $2"
 	err = pgxscan.Get(ctx, d.pgx, &file, sql, fileID, userID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return file, ErrorNotFound
+		}
+
+		return file, fmt.Errorf("db.FindFile: %w", err)
+	}
 
-	return file, fmt.Errorf("db.FindFile: %w", err)
+	return file, nil
 }
-func (d *Database) FindAllFiles(ctx context.Context, userID int) (files []model.DataFile, err error) {
-	sql := "SELECT id,title,filename,path,meta,updated_at FROM data_files WHERE user_id = $1 ORDER BY id DESC"
-	err = pgxscan.Select(ctx, d.pgx, &files, sql, userID)
+func (d *Database) FindAllFiles(ctx context.Context, userID int, filter model.ItemFilter) (files []model.DataFile, err error) {
+	where, args := itemFilterSQL(model.ItemTypeFile, filter, []any{userID}, pgPlaceholder)
+	sql := "SELECT id,title,filename,path,size,sha256,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeFile) + ",fields,updated_at FROM data_files WHERE user_id = $1" + where + " ORDER BY id DESC"
+	err = pgxscan.Select(ctx, d.pgx, &files, sql, args...)
+	if err != nil {
+		return files, fmt.Errorf("db.FindAllFiles: %w", err)
+	}
 
-	return files, fmt.Errorf("db.FindAllFiles: %w", err)
+	return files, nil
 }
 
 func (d *Database) SaveCred(ctx context.Context, cred model.DataCred) (id int, err error) {
-	if cred.ID == 0 {
-		sql := "INSERT INTO data_creds (user_id,title,username,password,meta,updated_at) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id"
-		err = d.pgx.QueryRow(ctx, sql, cred.UserID, cred.Title, cred.Username, cred.Password, cred.Meta, cred.UpdatedAt).Scan(&id)
-	} else {
-		id = cred.ID
-		sql := "UPDATE data_creds SET title=$1,username=$2,password=$3,meta=$4, updated_at=$5 WHERE id=$6 AND user_id=$7"
-		_, err = d.pgx.Exec(ctx, sql, cred.Title, cred.Username, cred.Password, cred.Meta, cred.UpdatedAt, cred.ID, cred.UserID)
+	err = pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		if cred.ID == 0 {
+			sql := "INSERT INTO data_creds (user_id,title,username,password,meta,folder_id,fields,urls,totp,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING id"
+			err := tx.QueryRow(ctx, sql, cred.UserID, cred.Title, cred.Username, cred.Password, cred.Meta, nullID(cred.FolderID), normalizeFields(cred.Fields), normalizeURLs(cred.URLs), cred.TOTP, cred.UpdatedAt).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = cred.ID
+			sql := "UPDATE data_creds SET title=$1,username=$2,password=$3,meta=$4,folder_id=$5,fields=$6,urls=$7,totp=$8,updated_at=$9 WHERE id=$10 AND user_id=$11"
+			tag, err := tx.Exec(ctx, sql, cred.Title, cred.Username, cred.Password, cred.Meta, nullID(cred.FolderID), normalizeFields(cred.Fields), normalizeURLs(cred.URLs), cred.TOTP, cred.UpdatedAt, cred.ID, cred.UserID)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return ErrorNotFound
+			}
+		}
+
+		return setItemTags(ctx, tx, model.ItemTypeCred, id, cred.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
 	}
 
 	var pgErr *pgconn.PgError
@@ -238,35 +530,69 @@ func (d *Database) SaveCred(ctx context.Context, cred model.DataCred) (id int, e
 		}
 	}
 
-	return id, fmt.Errorf("db.SaveCred: %w", err)
+	if err != nil {
+		return id, fmt.Errorf("db.SaveCred: %w", err)
+	}
+
+	return id, nil
 }
 func (d *Database) DeleteCred(ctx context.Context, credID, userID int) (err error) {
-	sql := "DELETE FROM data_creds WHERE id=$1 AND user_id =$2"
-	_, err = d.pgx.Exec(ctx, sql, credID, userID)
+	err = d.deleteItem(ctx, model.ItemTypeCred, credID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("db.DeleteCred: %w", err)
+	}
 
-	return fmt.Errorf("db.DeleteCred: %w", err)
+	return err
 }
 func (d *Database) FindCred(ctx context.Context, credID, userID int) (cred model.DataCred, err error) {
-	sql := "SELECT id,title,username,password,meta,updated_at FROM data_creds WHERE id=$1 AND user_id = $2"
+	sql := "SELECT id,title,username,password,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeCred) + ",fields,urls,totp,updated_at FROM data_creds WHERE id=$1 AND user_id = $2"
 	err = pgxscan.Get(ctx, d.pgx, &cred, sql, credID, userID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return cred, ErrorNotFound
+		}
+
+		return cred, fmt.Errorf("db.FindCred: %w", err)
+	}
 
-	return cred, fmt.Errorf("db.FindCred: %w", err)
+	return cred, nil
 }
-func (d *Database) FindAllCreds(ctx context.Context, userID int) (creds []model.DataCred, err error) {
-	sql := "SELECT id,title,username,password,meta,updated_at FROM data_creds WHERE user_id = $1 ORDER BY id DESC"
-	err = pgxscan.Select(ctx, d.pgx, &creds, sql, userID)
+func (d *Database) FindAllCreds(ctx context.Context, userID int, filter model.ItemFilter) (creds []model.DataCred, err error) {
+	where, args := itemFilterSQL(model.ItemTypeCred, filter, []any{userID}, pgPlaceholder)
+	sql := "SELECT id,title,username,password,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeCred) + ",fields,urls,totp,updated_at FROM data_creds WHERE user_id = $1" + where + " ORDER BY id DESC"
+	err = pgxscan.Select(ctx, d.pgx, &creds, sql, args...)
+	if err != nil {
+		return creds, fmt.Errorf("db.FindAllCreds: %w", err)
+	}
 
-	return creds, fmt.Errorf("db.FindAllCreds: %w", err)
+	return creds, nil
 }
 
 func (d *Database) SaveText(ctx context.Context, text model.DataText) (id int, err error) {
-	if text.ID == 0 {
-		sql := "INSERT INTO data_text (user_id,title,text,meta,updated_at) VALUES ($1,$2,$3,$4,$5) RETURNING id"
-		err = d.pgx.QueryRow(ctx, sql, text.UserID, text.Title, text.Text, text.Meta, text.UpdatedAt).Scan(&id)
-	} else {
-		id = text.ID
-		sql := "UPDATE data_text SET title=$1,text=$2,meta=$3, updated_at=$4 WHERE id=$5 AND user_id=$6"
-		_, err = d.pgx.Exec(ctx, sql, text.Title, text.Text, text.Meta, text.UpdatedAt, text.ID, text.UserID)
+	err = pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		if text.ID == 0 {
+			sql := "INSERT INTO data_text (user_id,title,text,meta,folder_id,fields,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id"
+			err := tx.QueryRow(ctx, sql, text.UserID, text.Title, text.Text, text.Meta, nullID(text.FolderID), normalizeFields(text.Fields), text.UpdatedAt).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = text.ID
+			sql := "UPDATE data_text SET title=$1,text=$2,meta=$3,folder_id=$4,fields=$5,updated_at=$6 WHERE id=$7 AND user_id=$8"
+			tag, err := tx.Exec(ctx, sql, text.Title, text.Text, text.Meta, nullID(text.FolderID), normalizeFields(text.Fields), text.UpdatedAt, text.ID, text.UserID)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return ErrorNotFound
+			}
+		}
+
+		return setItemTags(ctx, tx, model.ItemTypeText, id, text.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
 	}
 
 	var pgErr *pgconn.PgError
@@ -276,23 +602,1179 @@ func (d *Database) SaveText(ctx context.Context, text model.DataText) (id int, e
 		}
 	}
 
-	return id, fmt.Errorf("db.SaveText: %w", err)
+	if err != nil {
+		return id, fmt.Errorf("db.SaveText: %w", err)
+	}
+
+	return id, nil
 }
 func (d *Database) DeleteText(ctx context.Context, textID, userID int) (err error) {
-	sql := "DELETE FROM data_text WHERE id=$1 AND user_id =$2"
-	_, err = d.pgx.Exec(ctx, sql, textID, userID)
+	err = d.deleteItem(ctx, model.ItemTypeText, textID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("db.DeleteText: %w", err)
+	}
 
-	return fmt.Errorf("db.DeleteText: %w", err)
+	return err
 }
 func (d *Database) FindText(ctx context.Context, textID, userID int) (text model.DataText, err error) {
-	sql := "SELECT id,title,text,meta,updated_at FROM data_text WHERE id=$1 AND user_id = $2"
+	sql := "SELECT id,title,text,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeText) + ",fields,updated_at FROM data_text WHERE id=$1 AND user_id = $2"
 	err = pgxscan.Get(ctx, d.pgx, &text, sql, textID, userID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return text, ErrorNotFound
+		}
+
+		return text, fmt.Errorf("db.FindText: %w", err)
+	}
+
+	return text, nil
+}
+func (d *Database) FindAllTexts(ctx context.Context, userID int, filter model.ItemFilter) (texts []model.DataText, err error) {
+	where, args := itemFilterSQL(model.ItemTypeText, filter, []any{userID}, pgPlaceholder)
+	sql := "SELECT id,title,text,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeText) + ",fields,updated_at FROM data_text WHERE user_id = $1" + where + " ORDER BY id DESC"
+	err = pgxscan.Select(ctx, d.pgx, &texts, sql, args...)
+	if err != nil {
+		return texts, fmt.Errorf("db.FindAllTexts: %w", err)
+	}
+
+	return texts, nil
+}
+
+func (d *Database) SaveSSHKey(ctx context.Context, key model.DataSSHKey) (id int, err error) {
+	err = pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		if key.ID == 0 {
+			sql := "INSERT INTO data_ssh_keys (user_id,title,private_key,public_key,fingerprint,passphrase,meta,folder_id,fields,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING id"
+			err := tx.QueryRow(ctx, sql, key.UserID, key.Title, key.PrivateKey, key.PublicKey, key.Fingerprint, key.Passphrase, key.Meta, nullID(key.FolderID), normalizeFields(key.Fields), key.UpdatedAt).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = key.ID
+			sql := "UPDATE data_ssh_keys SET title=$1,private_key=$2,public_key=$3,fingerprint=$4,passphrase=$5,meta=$6,folder_id=$7,fields=$8,updated_at=$9 WHERE id=$10 AND user_id=$11"
+			tag, err := tx.Exec(ctx, sql, key.Title, key.PrivateKey, key.PublicKey, key.Fingerprint, key.Passphrase, key.Meta, nullID(key.FolderID), normalizeFields(key.Fields), key.UpdatedAt, key.ID, key.UserID)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return ErrorNotFound
+			}
+		}
+
+		return setItemTags(ctx, tx, model.ItemTypeSSH, id, key.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("db.SaveSSHKey: %w", err)
+	}
+
+	return id, nil
+}
+
+func (d *Database) DeleteSSHKey(ctx context.Context, keyID, userID int) (err error) {
+	err = d.deleteItem(ctx, model.ItemTypeSSH, keyID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("db.DeleteSSHKey: %w", err)
+	}
+
+	return err
+}
+
+func (d *Database) FindSSHKey(ctx context.Context, keyID, userID int) (key model.DataSSHKey, err error) {
+	sql := "SELECT id,title,private_key,public_key,fingerprint,passphrase,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeSSH) + ",fields,updated_at FROM data_ssh_keys WHERE id=$1 AND user_id = $2"
+	err = pgxscan.Get(ctx, d.pgx, &key, sql, keyID, userID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return key, ErrorNotFound
+		}
+
+		return key, fmt.Errorf("db.FindSSHKey: %w", err)
+	}
+
+	return key, nil
+}
+
+func (d *Database) FindAllSSHKeys(ctx context.Context, userID int, filter model.ItemFilter) (keys []model.DataSSHKey, err error) {
+	where, args := itemFilterSQL(model.ItemTypeSSH, filter, []any{userID}, pgPlaceholder)
+	sql := "SELECT id,title,private_key,public_key,fingerprint,passphrase,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeSSH) + ",fields,updated_at FROM data_ssh_keys WHERE user_id = $1" + where + " ORDER BY id DESC"
+	err = pgxscan.Select(ctx, d.pgx, &keys, sql, args...)
+	if err != nil {
+		return keys, fmt.Errorf("db.FindAllSSHKeys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (d *Database) SaveIdentity(ctx context.Context, doc model.DataIdentity) (id int, err error) {
+	err = pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		if doc.ID == 0 {
+			sql := "INSERT INTO data_identities (user_id,title,kind,full_name,number,country,issue_date,expiry_date,address,meta,folder_id,fields,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13) RETURNING id"
+			err := tx.QueryRow(ctx, sql, doc.UserID, doc.Title, doc.Kind, doc.FullName, doc.Number, doc.Country, doc.IssueDate, doc.ExpiryDate, doc.Address, doc.Meta, nullID(doc.FolderID), normalizeFields(doc.Fields), doc.UpdatedAt).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = doc.ID
+			sql := "UPDATE data_identities SET title=$1,kind=$2,full_name=$3,number=$4,country=$5,issue_date=$6,expiry_date=$7,address=$8,meta=$9,folder_id=$10,fields=$11,updated_at=$12 WHERE id=$13 AND user_id=$14"
+			tag, err := tx.Exec(ctx, sql, doc.Title, doc.Kind, doc.FullName, doc.Number, doc.Country, doc.IssueDate, doc.ExpiryDate, doc.Address, doc.Meta, nullID(doc.FolderID), normalizeFields(doc.Fields), doc.UpdatedAt, doc.ID, doc.UserID)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return ErrorNotFound
+			}
+		}
+
+		return setItemTags(ctx, tx, model.ItemTypeIdentity, id, doc.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("db.SaveIdentity: %w", err)
+	}
+
+	return id, nil
+}
+
+func (d *Database) DeleteIdentity(ctx context.Context, docID, userID int) (err error) {
+	err = d.deleteItem(ctx, model.ItemTypeIdentity, docID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("db.DeleteIdentity: %w", err)
+	}
+
+	return err
+}
+
+func (d *Database) FindIdentity(ctx context.Context, docID, userID int) (doc model.DataIdentity, err error) {
+	sql := "SELECT id,title,kind,full_name,number,country,issue_date,expiry_date,address,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeIdentity) + ",fields,updated_at FROM data_identities WHERE id=$1 AND user_id = $2"
+	err = pgxscan.Get(ctx, d.pgx, &doc, sql, docID, userID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return doc, ErrorNotFound
+		}
+
+		return doc, fmt.Errorf("db.FindIdentity: %w", err)
+	}
+
+	return doc, nil
+}
 
-	return text, fmt.Errorf("db.FindText: %w", err)
+func (d *Database) FindAllIdentities(ctx context.Context, userID int, filter model.ItemFilter) (docs []model.DataIdentity, err error) {
+	where, args := itemFilterSQL(model.ItemTypeIdentity, filter, []any{userID}, pgPlaceholder)
+	sql := "SELECT id,title,kind,full_name,number,country,issue_date,expiry_date,address,meta,COALESCE(folder_id,0) AS folder_id," + pgTagIDs(model.ItemTypeIdentity) + ",fields,updated_at FROM data_identities WHERE user_id = $1" + where + " ORDER BY id DESC"
+	err = pgxscan.Select(ctx, d.pgx, &docs, sql, args...)
+	if err != nil {
+		return docs, fmt.Errorf("db.FindAllIdentities: %w", err)
+	}
+
+	return docs, nil
+}
+
+func (d *Database) SaveCustomItem(ctx context.Context, item model.DataCustom) (id int, err error) {
+	err = pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		if item.ID == 0 {
+			sql := `INSERT INTO data_custom (user_id,template_id,title,"values",meta,folder_id,fields,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id`
+			err := tx.QueryRow(ctx, sql, item.UserID, item.TemplateID, item.Title, item.Values, item.Meta, nullID(item.FolderID), normalizeFields(item.Fields), item.UpdatedAt).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			id = item.ID
+			sql := `UPDATE data_custom SET template_id=$1,title=$2,"values"=$3,meta=$4,folder_id=$5,fields=$6,updated_at=$7 WHERE id=$8 AND user_id=$9`
+			tag, err := tx.Exec(ctx, sql, item.TemplateID, item.Title, item.Values, item.Meta, nullID(item.FolderID), normalizeFields(item.Fields), item.UpdatedAt, item.ID, item.UserID)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return ErrorNotFound
+			}
+		}
+
+		return setItemTags(ctx, tx, model.ItemTypeCustom, id, item.TagIDs)
+	})
+
+	if errors.Is(err, ErrorNotFound) {
+		return id, ErrorNotFound
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("db.SaveCustomItem: %w", err)
+	}
+
+	return id, nil
+}
+
+func (d *Database) DeleteCustomItem(ctx context.Context, itemID, userID int) (err error) {
+	err = d.deleteItem(ctx, model.ItemTypeCustom, itemID, userID)
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("db.DeleteCustomItem: %w", err)
+	}
+
+	return err
+}
+
+func (d *Database) FindCustomItem(ctx context.Context, itemID, userID int) (item model.DataCustom, err error) {
+	sql := `SELECT id,template_id,title,"values",meta,COALESCE(folder_id,0) AS folder_id,` + pgTagIDs(model.ItemTypeCustom) + ",fields,updated_at FROM data_custom WHERE id=$1 AND user_id = $2"
+	err = pgxscan.Get(ctx, d.pgx, &item, sql, itemID, userID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return item, ErrorNotFound
+		}
+
+		return item, fmt.Errorf("db.FindCustomItem: %w", err)
+	}
+
+	return item, nil
+}
+
+func (d *Database) FindAllCustomItems(ctx context.Context, userID int, filter model.ItemFilter) (items []model.DataCustom, err error) {
+	where, args := itemFilterSQL(model.ItemTypeCustom, filter, []any{userID}, pgPlaceholder)
+	sql := `SELECT id,template_id,title,"values",meta,COALESCE(folder_id,0) AS folder_id,` + pgTagIDs(model.ItemTypeCustom) + ",fields,updated_at FROM data_custom WHERE user_id = $1" + where + " ORDER BY id DESC"
+	err = pgxscan.Select(ctx, d.pgx, &items, sql, args...)
+	if err != nil {
+		return items, fmt.Errorf("db.FindAllCustomItems: %w", err)
+	}
+
+	return items, nil
 }
-func (d *Database) FindAllTexts(ctx context.Context, userID int) (texts []model.DataText, err error) {
-	sql := "SELECT id,title,text,meta,updated_at FROM data_text WHERE user_id = $1 ORDER BY id DESC"
-	err = pgxscan.Select(ctx, d.pgx, &texts, sql, userID)
 
-	return texts, fmt.Errorf("db.FindAllTexts: %w", err)
+func (d *Database) SaveFolder(ctx context.Context, folder model.Folder) (id int, err error) {
+	if folder.ID == 0 {
+		sql := "INSERT INTO folders (user_id,parent_id,name,updated_at) VALUES ($1,$2,$3,$4) RETURNING id"
+		err = d.pgx.QueryRow(ctx, sql, folder.UserID, nullID(folder.ParentID), folder.Name, folder.UpdatedAt).Scan(&id)
+	} else {
+		id = folder.ID
+		sql := "UPDATE folders SET parent_id=$1,name=$2,updated_at=$3 WHERE id=$4 AND user_id=$5"
+		var tag pgconn.CommandTag
+		tag, err = d.pgx.Exec(ctx, sql, nullID(folder.ParentID), folder.Name, folder.UpdatedAt, folder.ID, folder.UserID)
+		if err == nil && tag.RowsAffected() == 0 {
+			return id, ErrorNotFound
+		}
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("db.SaveFolder: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteFolder удаляет папку вместе с вложенными; записи из них остаются без папки (on delete set null).
+func (d *Database) DeleteFolder(ctx context.Context, folderID, userID int) (err error) {
+	sql := "DELETE FROM folders WHERE id=$1 AND user_id=$2"
+	tag, err := d.pgx.Exec(ctx, sql, folderID, userID)
+	if err != nil {
+		return fmt.Errorf("db.DeleteFolder: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+func (d *Database) FindAllFolders(ctx context.Context, userID int) (folders []model.Folder, err error) {
+	sql := "SELECT id,COALESCE(parent_id,0) AS parent_id,name,updated_at FROM folders WHERE user_id = $1 ORDER BY id"
+	err = pgxscan.Select(ctx, d.pgx, &folders, sql, userID)
+	if err != nil {
+		return folders, fmt.Errorf("db.FindAllFolders: %w", err)
+	}
+
+	return folders, nil
+}
+
+func (d *Database) SaveTag(ctx context.Context, tag model.Tag) (id int, err error) {
+	if tag.ID == 0 {
+		sql := "INSERT INTO tags (user_id,name,updated_at) VALUES ($1,$2,$3) RETURNING id"
+		err = d.pgx.QueryRow(ctx, sql, tag.UserID, tag.Name, tag.UpdatedAt).Scan(&id)
+	} else {
+		id = tag.ID
+		sql := "UPDATE tags SET name=$1,updated_at=$2 WHERE id=$3 AND user_id=$4"
+		var cmd pgconn.CommandTag
+		cmd, err = d.pgx.Exec(ctx, sql, tag.Name, tag.UpdatedAt, tag.ID, tag.UserID)
+		if err == nil && cmd.RowsAffected() == 0 {
+			return id, ErrorNotFound
+		}
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("db.SaveTag: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteTag удаляет метку, связи с записями удаляются каскадно.
+func (d *Database) DeleteTag(ctx context.Context, tagID, userID int) (err error) {
+	sql := "DELETE FROM tags WHERE id=$1 AND user_id=$2"
+	tag, err := d.pgx.Exec(ctx, sql, tagID, userID)
+	if err != nil {
+		return fmt.Errorf("db.DeleteTag: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+func (d *Database) FindAllTags(ctx context.Context, userID int) (tags []model.Tag, err error) {
+	sql := "SELECT id,name,updated_at FROM tags WHERE user_id = $1 ORDER BY id"
+	err = pgxscan.Select(ctx, d.pgx, &tags, sql, userID)
+	if err != nil {
+		return tags, fmt.Errorf("db.FindAllTags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (d *Database) SaveTemplate(ctx context.Context, tpl model.Template) (id int, err error) {
+	if tpl.ID == 0 {
+		sql := "INSERT INTO templates (user_id,name,schema,updated_at) VALUES ($1,$2,$3,$4) RETURNING id"
+		err = d.pgx.QueryRow(ctx, sql, tpl.UserID, tpl.Name, tpl.Schema, tpl.UpdatedAt).Scan(&id)
+	} else {
+		id = tpl.ID
+		sql := "UPDATE templates SET name=$1,schema=$2,updated_at=$3 WHERE id=$4 AND user_id=$5"
+		var cmd pgconn.CommandTag
+		cmd, err = d.pgx.Exec(ctx, sql, tpl.Name, tpl.Schema, tpl.UpdatedAt, tpl.ID, tpl.UserID)
+		if err == nil && cmd.RowsAffected() == 0 {
+			return id, ErrorNotFound
+		}
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("db.SaveTemplate: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteTemplate удаляет шаблон; записи по нему удаляются каскадно, их метки, вложения и доступы - здесь же.
+func (d *Database) DeleteTemplate(ctx context.Context, tplID, userID int) (err error) {
+	err = pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		for _, table := range []string{"item_tags", "attachments", "shares"} {
+			sql := "DELETE FROM " + table + " WHERE item_type=$1 AND item_id IN (SELECT id FROM data_custom WHERE template_id=$2 AND user_id=$3)"
+			if _, err := tx.Exec(ctx, sql, model.ItemTypeCustom, tplID, userID); err != nil {
+				return err
+			}
+		}
+
+		tag, err := tx.Exec(ctx, "DELETE FROM templates WHERE id=$1 AND user_id=$2", tplID, userID)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return ErrorNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("db.DeleteTemplate: %w", err)
+	}
+
+	return err
+}
+
+func (d *Database) FindAllTemplates(ctx context.Context, userID int) (templates []model.Template, err error) {
+	sql := "SELECT id,name,schema,updated_at FROM templates WHERE user_id = $1 ORDER BY id"
+	err = pgxscan.Select(ctx, d.pgx, &templates, sql, userID)
+	if err != nil {
+		return templates, fmt.Errorf("db.FindAllTemplates: %w", err)
+	}
+
+	return templates, nil
+}
+
+func (d *Database) SaveAttachment(ctx context.Context, att model.Attachment) (id int, err error) {
+	sql := "INSERT INTO attachments (user_id,item_type,item_id,filename,path,size,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id"
+	err = d.pgx.QueryRow(ctx, sql, att.UserID, att.ItemType, att.ItemID, att.Filename, att.Path, att.Size, att.UpdatedAt).Scan(&id)
+	if err != nil {
+		return id, fmt.Errorf("db.SaveAttachment: %w", err)
+	}
+
+	return id, nil
+}
+
+func (d *Database) DeleteAttachment(ctx context.Context, attID, userID int) (err error) {
+	tag, err := d.pgx.Exec(ctx, "DELETE FROM attachments WHERE id=$1 AND user_id=$2", attID, userID)
+	if err != nil {
+		return fmt.Errorf("db.DeleteAttachment: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+func (d *Database) FindAttachment(ctx context.Context, attID, userID int) (att model.Attachment, err error) {
+	sql := "SELECT id,item_type,item_id,filename,path,size,updated_at FROM attachments WHERE id=$1 AND user_id=$2"
+	err = pgxscan.Get(ctx, d.pgx, &att, sql, attID, userID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return att, ErrorNotFound
+		}
+
+		return att, fmt.Errorf("db.FindAttachment: %w", err)
+	}
+
+	return att, nil
+}
+
+func (d *Database) FindAllAttachments(ctx context.Context, userID int, filter model.AttachmentFilter) (atts []model.Attachment, err error) {
+	where, args := attachmentFilterSQL(filter, []any{userID}, pgPlaceholder)
+	sql := "SELECT id,item_type,item_id,filename,path,size,updated_at FROM attachments WHERE user_id=$1" + where + " ORDER BY id"
+	err = pgxscan.Select(ctx, d.pgx, &atts, sql, args...)
+	if err != nil {
+		return atts, fmt.Errorf("db.FindAllAttachments: %w", err)
+	}
+
+	return atts, nil
+}
+
+// SaveUserKeys сохраняет пару ключей пользователя, заменяя прежнюю.
+func (d *Database) SaveUserKeys(ctx context.Context, keys model.UserKeys) error {
+	sql := "INSERT INTO user_keys (user_id,public_key,private_key,updated_at) VALUES ($1,$2,$3,$4) " +
+		"ON CONFLICT (user_id) DO UPDATE SET public_key=excluded.public_key,private_key=excluded.private_key,updated_at=excluded.updated_at"
+	_, err := d.pgx.Exec(ctx, sql, keys.UserID, keys.PublicKey, keys.PrivateKey, keys.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("db.SaveUserKeys: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Database) FindUserKeys(ctx context.Context, userID int) (keys model.UserKeys, err error) {
+	sql := "SELECT user_id,public_key,private_key,updated_at FROM user_keys WHERE user_id=$1"
+	err = pgxscan.Get(ctx, d.pgx, &keys, sql, userID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return keys, ErrorNotFound
+		}
+
+		return keys, fmt.Errorf("db.FindUserKeys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (d *Database) FindPublicKey(ctx context.Context, login string) (key model.PublicKey, err error) {
+	sql := "SELECT u.id AS user_id,u.login,k.public_key FROM users u JOIN user_keys k ON k.user_id=u.id WHERE u.login=$1"
+	err = pgxscan.Get(ctx, d.pgx, &key, sql, login)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return key, ErrorNotFound
+		}
+
+		return key, fmt.Errorf("db.FindPublicKey: %w", err)
+	}
+
+	return key, nil
+}
+
+// SaveShare открывает запись получателю или обновляет доступ; получатель и запись доступа не меняются.
+func (d *Database) SaveShare(ctx context.Context, share model.Share) (id int, err error) {
+	if share.ID == 0 {
+		sql := "INSERT INTO shares (user_id,recipient_id,item_type,item_id,access,item_key,owner_key,data,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING id"
+		err = d.pgx.QueryRow(ctx, sql, share.UserID, share.RecipientID, share.ItemType, share.ItemID, share.Access, share.ItemKey, share.OwnerKey, share.Data, share.UpdatedAt).Scan(&id)
+	} else {
+		id = share.ID
+		sql := "UPDATE shares SET access=$1,item_key=$2,owner_key=$3,data=$4,updated_at=$5 WHERE id=$6 AND user_id=$7 AND recipient_id=$8 AND item_type=$9 AND item_id=$10"
+		var cmd pgconn.CommandTag
+		cmd, err = d.pgx.Exec(ctx, sql, share.Access, share.ItemKey, share.OwnerKey, share.Data, share.UpdatedAt, share.ID, share.UserID, share.RecipientID, share.ItemType, share.ItemID)
+		if err == nil && cmd.RowsAffected() == 0 {
+			return id, ErrorNotFound
+		}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return id, ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("db.SaveShare: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateShareData сохраняет запись, измененную получателем.
+func (d *Database) UpdateShareData(ctx context.Context, share model.Share) error {
+	tag, err := d.pgx.Exec(ctx, "UPDATE shares SET data=$1,updated_at=$2 WHERE id=$3 AND recipient_id=$4", share.Data, share.UpdatedAt, share.ID, share.RecipientID)
+	if err != nil {
+		return fmt.Errorf("db.UpdateShareData: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+func (d *Database) DeleteShare(ctx context.Context, shareID, userID int) error {
+	tag, err := d.pgx.Exec(ctx, "DELETE FROM shares WHERE id=$1 AND user_id=$2", shareID, userID)
+	if err != nil {
+		return fmt.Errorf("db.DeleteShare: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+// shareColumns поля доступа с логинами владельца и получателя.
+const shareColumns = "SELECT s.id,o.login AS owner,r.login AS recipient,s.item_type,s.item_id,s.access,s.item_key,s.owner_key,s.data,s.updated_at " +
+	"FROM shares s JOIN users o ON o.id=s.user_id JOIN users r ON r.id=s.recipient_id"
+
+func (d *Database) FindAllShares(ctx context.Context, userID int) (shares []model.Share, err error) {
+	err = pgxscan.Select(ctx, d.pgx, &shares, shareColumns+" WHERE s.user_id=$1 ORDER BY s.id", userID)
+	if err != nil {
+		return shares, fmt.Errorf("db.FindAllShares: %w", err)
+	}
+
+	return shares, nil
+}
+
+func (d *Database) FindAllIncomingShares(ctx context.Context, recipientID int) (shares []model.Share, err error) {
+	err = pgxscan.Select(ctx, d.pgx, &shares, shareColumns+" WHERE s.recipient_id=$1 ORDER BY s.id", recipientID)
+	if err != nil {
+		return shares, fmt.Errorf("db.FindAllIncomingShares: %w", err)
+	}
+
+	return shares, nil
+}
+
+// SaveOrganization создает организацию с владельцем ownerID или переименовывает существующую.
+func (d *Database) SaveOrganization(ctx context.Context, org model.Organization, ownerID int) (id int, err error) {
+	if org.ID != 0 {
+		tag, err := d.pgx.Exec(ctx, "UPDATE organizations SET name=$1,updated_at=$2 WHERE id=$3", org.Name, org.UpdatedAt, org.ID)
+		if err != nil {
+			return org.ID, fmt.Errorf("db.SaveOrganization: %w", err)
+		}
+
+		if tag.RowsAffected() == 0 {
+			return org.ID, ErrorNotFound
+		}
+
+		return org.ID, nil
+	}
+
+	err = pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, "INSERT INTO organizations (name,updated_at) VALUES ($1,$2) RETURNING id", org.Name, org.UpdatedAt).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, "INSERT INTO org_members (org_id,user_id,role,status,updated_at) VALUES ($1,$2,$3,$4,$5)",
+			id, ownerID, model.RoleOwner, model.MemberStatusActive, org.UpdatedAt)
+
+		return err
+	})
+	if err != nil {
+		return id, fmt.Errorf("db.SaveOrganization: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteOrganization удаляет организацию; участники, коллекции и их записи удаляются каскадно.
+func (d *Database) DeleteOrganization(ctx context.Context, orgID int) error {
+	tag, err := d.pgx.Exec(ctx, "DELETE FROM organizations WHERE id=$1", orgID)
+	if err != nil {
+		return fmt.Errorf("db.DeleteOrganization: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+func (d *Database) FindAllOrganizations(ctx context.Context, userID int) (orgs []model.Organization, err error) {
+	sql := "SELECT o.id,o.name,m.role,m.status,o.updated_at FROM organizations o JOIN org_members m ON m.org_id=o.id WHERE m.user_id=$1 ORDER BY o.id"
+	err = pgxscan.Select(ctx, d.pgx, &orgs, sql, userID)
+	if err != nil {
+		return orgs, fmt.Errorf("db.FindAllOrganizations: %w", err)
+	}
+
+	return orgs, nil
+}
+
+// SaveMember добавляет участника или меняет его роль и состояние вместе с его ключами коллекций.
+func (d *Database) SaveMember(ctx context.Context, member model.Member) error {
+	err := pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		sql := "INSERT INTO org_members (org_id,user_id,role,status,updated_at) VALUES ($1,$2,$3,$4,$5) " +
+			"ON CONFLICT (org_id,user_id) DO UPDATE SET role=excluded.role,status=excluded.status,updated_at=excluded.updated_at"
+		_, err := tx.Exec(ctx, sql, member.OrgID, member.UserID, member.Role, member.Status, member.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		return pgSaveCollectionKeys(ctx, tx, member.Keys)
+	})
+	if err != nil {
+		return fmt.Errorf("db.SaveMember: %w", err)
+	}
+
+	return nil
+}
+
+// pgSaveCollectionKeys сохраняет ключи коллекций участников, заменяя прежние.
+func pgSaveCollectionKeys(ctx context.Context, tx pgx.Tx, keys []model.CollectionKey) error {
+	for _, k := range keys {
+		sql := "INSERT INTO collection_keys (collection_id,user_id,key) VALUES ($1,$2,$3) " +
+			"ON CONFLICT (collection_id,user_id) DO UPDATE SET key=excluded.key"
+		if _, err := tx.Exec(ctx, sql, k.CollectionID, k.UserID, k.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteMember исключает участника из организации вместе с его ключами коллекций.
+func (d *Database) DeleteMember(ctx context.Context, orgID, userID int) error {
+	err := pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, "DELETE FROM collection_keys WHERE user_id=$1 AND collection_id IN (SELECT id FROM collections WHERE org_id=$2)", userID, orgID)
+		if err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, "DELETE FROM org_members WHERE org_id=$1 AND user_id=$2", orgID, userID)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return ErrorNotFound
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return fmt.Errorf("db.DeleteMember: %w", err)
+	}
+
+	return err
+}
+
+// memberColumns поля участника с логином и открытым ключом.
+const memberColumns = "SELECT m.org_id,m.user_id,u.login,m.role,m.status,coalesce(k.public_key,'') AS public_key,m.updated_at " +
+	"FROM org_members m JOIN users u ON u.id=m.user_id LEFT JOIN user_keys k ON k.user_id=m.user_id"
+
+func (d *Database) FindMember(ctx context.Context, orgID, userID int) (member model.Member, err error) {
+	err = pgxscan.Get(ctx, d.pgx, &member, memberColumns+" WHERE m.org_id=$1 AND m.user_id=$2", orgID, userID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return member, ErrorNotFound
+		}
+
+		return member, fmt.Errorf("db.FindMember: %w", err)
+	}
+
+	return member, nil
+}
+
+func (d *Database) FindAllMembers(ctx context.Context, orgID int) (members []model.Member, err error) {
+	err = pgxscan.Select(ctx, d.pgx, &members, memberColumns+" WHERE m.org_id=$1 ORDER BY u.login", orgID)
+	if err != nil {
+		return members, fmt.Errorf("db.FindAllMembers: %w", err)
+	}
+
+	return members, nil
+}
+
+// SaveCollection создает или переименовывает коллекцию и сохраняет ключи коллекции для участников.
+func (d *Database) SaveCollection(ctx context.Context, col model.Collection) (id int, err error) {
+	id = col.ID
+	err = pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		if col.ID == 0 {
+			err := tx.QueryRow(ctx, "INSERT INTO collections (org_id,name,updated_at) VALUES ($1,$2,$3) RETURNING id", col.OrgID, col.Name, col.UpdatedAt).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			tag, err := tx.Exec(ctx, "UPDATE collections SET name=$1,updated_at=$2 WHERE id=$3 AND org_id=$4", col.Name, col.UpdatedAt, col.ID, col.OrgID)
+			if err != nil {
+				return err
+			}
+
+			if tag.RowsAffected() == 0 {
+				return ErrorNotFound
+			}
+		}
+
+		for i := range col.Keys {
+			col.Keys[i].CollectionID = id
+		}
+
+		return pgSaveCollectionKeys(ctx, tx, col.Keys)
+	})
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return id, fmt.Errorf("db.SaveCollection: %w", err)
+	}
+
+	return id, err
+}
+
+// DeleteCollection удаляет коллекцию; записи и ключи коллекции удаляются каскадно.
+func (d *Database) DeleteCollection(ctx context.Context, colID int) error {
+	tag, err := d.pgx.Exec(ctx, "DELETE FROM collections WHERE id=$1", colID)
+	if err != nil {
+		return fmt.Errorf("db.DeleteCollection: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+func (d *Database) FindCollection(ctx context.Context, colID int) (col model.Collection, err error) {
+	err = pgxscan.Get(ctx, d.pgx, &col, "SELECT id,org_id,name,updated_at FROM collections WHERE id=$1", colID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return col, ErrorNotFound
+		}
+
+		return col, fmt.Errorf("db.FindCollection: %w", err)
+	}
+
+	return col, nil
+}
+
+// FindAllCollections коллекции организаций, в которых пользователь участвует, с его ключом коллекции и ролью.
+func (d *Database) FindAllCollections(ctx context.Context, userID int) (cols []model.Collection, err error) {
+	sql := "SELECT c.id,c.org_id,c.name,k.key,m.role,c.updated_at FROM collections c " +
+		"JOIN org_members m ON m.org_id=c.org_id AND m.user_id=$1 AND m.status=$2 " +
+		"JOIN collection_keys k ON k.collection_id=c.id AND k.user_id=$1 ORDER BY c.id"
+	err = pgxscan.Select(ctx, d.pgx, &cols, sql, userID, model.MemberStatusActive)
+	if err != nil {
+		return cols, fmt.Errorf("db.FindAllCollections: %w", err)
+	}
+
+	return cols, nil
+}
+
+func (d *Database) SaveCollectionItem(ctx context.Context, item model.CollectionItem) (id int, err error) {
+	if item.ID == 0 {
+		sql := "INSERT INTO collection_items (collection_id,user_id,item_type,data,updated_at) VALUES ($1,$2,$3,$4,$5) RETURNING id"
+		err = d.pgx.QueryRow(ctx, sql, item.CollectionID, nullID(item.UserID), item.ItemType, item.Data, item.UpdatedAt).Scan(&id)
+		if err != nil {
+			return id, fmt.Errorf("db.SaveCollectionItem: %w", err)
+		}
+
+		return id, nil
+	}
+
+	sql := "UPDATE collection_items SET user_id=$1,data=$2,updated_at=$3 WHERE id=$4 AND collection_id=$5"
+	tag, err := d.pgx.Exec(ctx, sql, nullID(item.UserID), item.Data, item.UpdatedAt, item.ID, item.CollectionID)
+	if err != nil {
+		return item.ID, fmt.Errorf("db.SaveCollectionItem: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return item.ID, ErrorNotFound
+	}
+
+	return item.ID, nil
+}
+
+func (d *Database) DeleteCollectionItem(ctx context.Context, itemID, colID int) error {
+	tag, err := d.pgx.Exec(ctx, "DELETE FROM collection_items WHERE id=$1 AND collection_id=$2", itemID, colID)
+	if err != nil {
+		return fmt.Errorf("db.DeleteCollectionItem: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+func (d *Database) FindAllCollectionItems(ctx context.Context, colID int) (items []model.CollectionItem, err error) {
+	sql := "SELECT id,collection_id,coalesce(user_id,0) AS user_id,item_type,data,updated_at FROM collection_items WHERE collection_id=$1 ORDER BY id"
+	err = pgxscan.Select(ctx, d.pgx, &items, sql, colID)
+	if err != nil {
+		return items, fmt.Errorf("db.FindAllCollectionItems: %w", err)
+	}
+
+	return items, nil
+}
+
+// SaveEmergencyAccess назначает доверенное лицо или меняет период ожидания и ключ хранилища.
+func (d *Database) SaveEmergencyAccess(ctx context.Context, access model.EmergencyAccess) (id int, err error) {
+	if access.ID == 0 {
+		sql := "INSERT INTO emergency_access (user_id,grantee_id,wait_hours,key,status,requested_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id"
+		err = d.pgx.QueryRow(ctx, sql, access.UserID, access.GranteeID, access.WaitHours, access.Key, access.Status, access.RequestedAt, access.UpdatedAt).Scan(&id)
+	} else {
+		id = access.ID
+		sql := "UPDATE emergency_access SET wait_hours=$1,key=$2,updated_at=$3 WHERE id=$4 AND user_id=$5 AND grantee_id=$6"
+		var cmd pgconn.CommandTag
+		cmd, err = d.pgx.Exec(ctx, sql, access.WaitHours, access.Key, access.UpdatedAt, access.ID, access.UserID, access.GranteeID)
+		if err == nil && cmd.RowsAffected() == 0 {
+			return id, ErrorNotFound
+		}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return id, ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return id, fmt.Errorf("db.SaveEmergencyAccess: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateEmergencyStatus сохраняет состояние экстренного доступа и время запроса.
+func (d *Database) UpdateEmergencyStatus(ctx context.Context, access model.EmergencyAccess) error {
+	sql := "UPDATE emergency_access SET status=$1,requested_at=$2,updated_at=$3 WHERE id=$4"
+	tag, err := d.pgx.Exec(ctx, sql, access.Status, access.RequestedAt, access.UpdatedAt, access.ID)
+	if err != nil {
+		return fmt.Errorf("db.UpdateEmergencyStatus: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+func (d *Database) DeleteEmergencyAccess(ctx context.Context, accessID, userID int) error {
+	tag, err := d.pgx.Exec(ctx, "DELETE FROM emergency_access WHERE id=$1 AND user_id=$2", accessID, userID)
+	if err != nil {
+		return fmt.Errorf("db.DeleteEmergencyAccess: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+// emergencyColumns поля экстренного доступа с логинами владельца и доверенного лица.
+const emergencyColumns = "SELECT e.id,e.user_id,g.login AS grantor,e.grantee_id,r.login AS grantee,e.wait_hours,e.key,e.status,e.requested_at,e.updated_at " +
+	"FROM emergency_access e JOIN users g ON g.id=e.user_id JOIN users r ON r.id=e.grantee_id"
+
+func (d *Database) FindEmergencyAccess(ctx context.Context, accessID int) (access model.EmergencyAccess, err error) {
+	err = pgxscan.Get(ctx, d.pgx, &access, emergencyColumns+" WHERE e.id=$1", accessID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return access, ErrorNotFound
+		}
+
+		return access, fmt.Errorf("db.FindEmergencyAccess: %w", err)
+	}
+
+	return access, nil
+}
+
+func (d *Database) FindAllEmergencyAccess(ctx context.Context, userID int) (list []model.EmergencyAccess, err error) {
+	err = pgxscan.Select(ctx, d.pgx, &list, emergencyColumns+" WHERE e.user_id=$1 ORDER BY e.id", userID)
+	if err != nil {
+		return list, fmt.Errorf("db.FindAllEmergencyAccess: %w", err)
+	}
+
+	return list, nil
+}
+
+func (d *Database) FindAllIncomingEmergencyAccess(ctx context.Context, granteeID int) (list []model.EmergencyAccess, err error) {
+	err = pgxscan.Select(ctx, d.pgx, &list, emergencyColumns+" WHERE e.grantee_id=$1 ORDER BY e.id", granteeID)
+	if err != nil {
+		return list, fmt.Errorf("db.FindAllIncomingEmergencyAccess: %w", err)
+	}
+
+	return list, nil
+}
+
+func (d *Database) SaveSecret(ctx context.Context, secret model.Secret) error {
+	sql := "INSERT INTO secrets (id,user_id,data,max_views,expires_at,created_at) VALUES ($1,$2,$3,$4,$5,$6)"
+	_, err := d.pgx.Exec(ctx, sql, secret.ID, secret.UserID, secret.Data, secret.MaxViews, secret.ExpiresAt, secret.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return fmt.Errorf("db.SaveSecret: %w", err)
+	}
+
+	return nil
+}
+
+// OpenSecret засчитывает просмотр секрета и возвращает его; секрет, просмотренный max_views раз,
+// удаляется. Истекший или уже удаленный секрет - ErrorNotFound.
+func (d *Database) OpenSecret(ctx context.Context, secretID string, now time.Time) (secret model.Secret, err error) {
+	err = pgx.BeginFunc(ctx, d.pgx, func(tx pgx.Tx) error {
+		sql := "UPDATE secrets SET views=views+1 WHERE id=$1 AND expires_at>$2 AND views<max_views " +
+			"RETURNING id,user_id,data,max_views,views,expires_at,created_at"
+		err := pgxscan.Get(ctx, tx, &secret, sql, secretID, now)
+		if err != nil {
+			if pgxscan.NotFound(err) {
+				return ErrorNotFound
+			}
+
+			return err
+		}
+
+		if secret.Views < secret.MaxViews {
+			return nil
+		}
+
+		_, err = tx.Exec(ctx, "DELETE FROM secrets WHERE id=$1", secretID)
+
+		return err
+	})
+
+	if err != nil && !errors.Is(err, ErrorNotFound) {
+		return secret, fmt.Errorf("db.OpenSecret: %w", err)
+	}
+
+	return secret, err
+}
+
+func (d *Database) DeleteSecret(ctx context.Context, secretID string, userID int) error {
+	tag, err := d.pgx.Exec(ctx, "DELETE FROM secrets WHERE id=$1 AND user_id=$2", secretID, userID)
+	if err != nil {
+		return fmt.Errorf("db.DeleteSecret: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+// FindAllSecrets секреты пользователя без содержимого.
+func (d *Database) FindAllSecrets(ctx context.Context, userID int) (secrets []model.Secret, err error) {
+	sql := "SELECT id,user_id,max_views,views,expires_at,created_at FROM secrets WHERE user_id=$1 ORDER BY created_at"
+	err = pgxscan.Select(ctx, d.pgx, &secrets, sql, userID)
+	if err != nil {
+		return secrets, fmt.Errorf("db.FindAllSecrets: %w", err)
+	}
+
+	return secrets, nil
+}
+
+func (d *Database) ClearExpiredSecrets(ctx context.Context) error {
+	_, err := d.pgx.Exec(ctx, "DELETE FROM secrets WHERE expires_at < NOW()")
+	if err != nil {
+		return fmt.Errorf("db.ClearExpiredSecrets: %w", err)
+	}
+
+	return nil
+}
+
+// uploadColumns поля сессии загрузки.
+const uploadColumns = "SELECT id,user_id,size,chunk_size,upload_offset,sha256,expires_at,created_at FROM uploads"
+
+func (d *Database) SaveUpload(ctx context.Context, upload model.Upload) error {
+	sql := "INSERT INTO uploads (id,user_id,size,chunk_size,upload_offset,sha256,expires_at,created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)"
+	_, err := d.pgx.Exec(ctx, sql, upload.ID, upload.UserID, upload.Size, upload.ChunkSize, upload.Offset, upload.SHA256,
+		upload.ExpiresAt, upload.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return ErrorRowAlreadyExists
+	}
+
+	if err != nil {
+		return fmt.Errorf("db.SaveUpload: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Database) FindUpload(ctx context.Context, uploadID string, userID int) (upload model.Upload, err error) {
+	err = pgxscan.Get(ctx, d.pgx, &upload, uploadColumns+" WHERE id=$1 AND user_id=$2", uploadID, userID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return upload, ErrorNotFound
+		}
+
+		return upload, fmt.Errorf("db.FindUpload: %w", err)
+	}
+
+	return upload, nil
+}
+
+// UpdateUploadOffset переводит смещение загрузки из from в to и продлевает срок жизни.
+// Если смещение уже изменилось (параллельная запись той же части) - ErrorNotFound.
+func (d *Database) UpdateUploadOffset(ctx context.Context, upload model.Upload, from int64) error {
+	sql := "UPDATE uploads SET upload_offset=$1,expires_at=$2 WHERE id=$3 AND user_id=$4 AND upload_offset=$5"
+	tag, err := d.pgx.Exec(ctx, sql, upload.Offset, upload.ExpiresAt, upload.ID, upload.UserID, from)
+	if err != nil {
+		return fmt.Errorf("db.UpdateUploadOffset: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+// CompleteUpload сохраняет проверенный хеш содержимого загрузки и новый срок жизни.
+// Если загрузка уже завершена или ее нет - ErrorNotFound.
+func (d *Database) CompleteUpload(ctx context.Context, upload model.Upload) error {
+	sql := "UPDATE uploads SET sha256=$1,expires_at=$2 WHERE id=$3 AND user_id=$4 AND sha256=''"
+	tag, err := d.pgx.Exec(ctx, sql, upload.SHA256, upload.ExpiresAt, upload.ID, upload.UserID)
+	if err != nil {
+		return fmt.Errorf("db.CompleteUpload: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+// FindCompletedUpload завершенная загрузка пользователя с содержимым sha256, срок жизни которой не истек к now.
+func (d *Database) FindCompletedUpload(ctx context.Context, userID int, sha256 string, now time.Time) (upload model.Upload, err error) {
+	sql := uploadColumns + " WHERE user_id=$1 AND sha256=$2 AND expires_at>=$3 ORDER BY expires_at DESC LIMIT 1"
+	err = pgxscan.Get(ctx, d.pgx, &upload, sql, userID, sha256, now)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return upload, ErrorNotFound
+		}
+
+		return upload, fmt.Errorf("db.FindCompletedUpload: %w", err)
+	}
+
+	return upload, nil
+}
+
+func (d *Database) DeleteUpload(ctx context.Context, uploadID string, userID int) error {
+	tag, err := d.pgx.Exec(ctx, "DELETE FROM uploads WHERE id=$1 AND user_id=$2", uploadID, userID)
+	if err != nil {
+		return fmt.Errorf("db.DeleteUpload: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrorNotFound
+	}
+
+	return nil
+}
+
+// FindExpiredUploads загрузки всех пользователей, срок жизни которых истек к моменту now.
+func (d *Database) FindExpiredUploads(ctx context.Context, now time.Time) (uploads []model.Upload, err error) {
+	err = pgxscan.Select(ctx, d.pgx, &uploads, uploadColumns+" WHERE expires_at<$1 ORDER BY expires_at", now)
+	if err != nil {
+		return uploads, fmt.Errorf("db.FindExpiredUploads: %w", err)
+	}
+
+	return uploads, nil
+}
+
+// GetUsage объем данных и число записей пользователя; квоты заполняет сервис.
+func (d *Database) GetUsage(ctx context.Context, userID int) (usage model.Usage, err error) {
+	sql, args := usageSQL(userID, func(column string) string { return "octet_length(CAST(" + column + " AS text))" }, pgPlaceholder)
+	err = d.pgx.QueryRow(ctx, sql, args...).Scan(&usage.Bytes, &usage.Items)
+	if err != nil {
+		return usage, fmt.Errorf("db.GetUsage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// FindFileRefs все файлы и вложения пользователей, ссылающиеся на содержимое в хранилище файлов.
+func (d *Database) FindFileRefs(ctx context.Context) (refs []model.FileRef, err error) {
+	err = pgxscan.Select(ctx, d.pgx, &refs, fileRefsAllSQL)
+	if err != nil {
+		return refs, fmt.Errorf("db.FindFileRefs: %w", err)
+	}
+
+	return refs, nil
+}
+
+// FindUploadIDs идентификаторы всех незавершенных загрузок, в том числе просроченных.
+func (d *Database) FindUploadIDs(ctx context.Context) (ids []string, err error) {
+	err = pgxscan.Select(ctx, d.pgx, &ids, "SELECT id FROM uploads ORDER BY id")
+	if err != nil {
+		return ids, fmt.Errorf("db.FindUploadIDs: %w", err)
+	}
+
+	return ids, nil
+}
+
+// CountFileRefs число записей всех пользователей (файлов и вложений), ссылающихся на содержимое по пути path.
+func (d *Database) CountFileRefs(ctx context.Context, path string) (refs int, err error) {
+	err = d.pgx.QueryRow(ctx, fileRefsSQL(pgPlaceholder), path, path).Scan(&refs)
+	if err != nil {
+		return 0, fmt.Errorf("db.CountFileRefs: %w", err)
+	}
+
+	return refs, nil
+}
+
+// fileLockClass первый ключ pg_advisory_xact_lock для блокировок содержимого, второй - hashtext(path).
+const fileLockClass = 730_624
+
+// LockFile выполняет fn под блокировкой содержимого path, общей для всех процессов, работающих с БД
+// (реплики сервера, server gc). Блокировка держится транзакцией на отдельном соединении, а fn выполняет
+// запросы через пул, поэтому одновременно блокировки занимают не больше половины соединений пула:
+// остальных хватает и запросам внутри fn, и прочим запросам.
+func (d *Database) LockFile(ctx context.Context, path string, fn func() error) error {
+	select {
+	case d.fileLockSlots <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("db.LockFile: %w", ctx.Err())
+	}
+	defer func() { <-d.fileLockSlots }()
+
+	tx, err := d.pgx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("db.LockFile: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1, hashtext($2))", fileLockClass, path); err != nil {
+		return fmt.Errorf("db.LockFile: %w", err)
+	}
+
+	if err = fn(); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("db.LockFile: %w", err)
+	}
+
+	return nil
+}
+
+// CountUserFileRefs число файлов и вложений пользователя userID, ссылающихся на содержимое по пути path.
+func (d *Database) CountUserFileRefs(ctx context.Context, userID int, path string) (refs int, err error) {
+	err = d.pgx.QueryRow(ctx, userFileRefsSQL(pgPlaceholder), userID, path, userID, path).Scan(&refs)
+	if err != nil {
+		return 0, fmt.Errorf("db.CountUserFileRefs: %w", err)
+	}
+
+	return refs, nil
 }
@@ -0,0 +1,21 @@
+//go:build integration
+
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgres_Conformance(t *testing.T) {
+	cfg, err := config.ReadConfig()
+	require.NoError(t, err)
+
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		return openMigrated(t, cfg.DatabaseDsn)
+	})
+}
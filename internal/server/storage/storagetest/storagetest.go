@@ -0,0 +1,1369 @@
+// Package storagetest набор тестов соответствия для реализаций storage.Interface.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rainset/gophkeeper/internal/server/model"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NewStore возвращает готовое к работе хранилище; закрытие хранилища на стороне тестов.
+type NewStore func(t *testing.T) storage.Interface
+
+// Run запускает набор тестов соответствия для реализации storage.Interface.
+func Run(t *testing.T, newStore NewStore) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store storage.Interface)
+	}{
+		{name: "Users", fn: testUsers},
+		{name: "RefreshTokens", fn: testRefreshTokens},
+		{name: "Cards", fn: testCards},
+		{name: "Creds", fn: testCreds},
+		{name: "Texts", fn: testTexts},
+		{name: "Files", fn: testFiles},
+		{name: "SSHKeys", fn: testSSHKeys},
+		{name: "Identities", fn: testIdentities},
+		{name: "CustomItems", fn: testCustomItems},
+		{name: "Folders", fn: testFolders},
+		{name: "Tags", fn: testTags},
+		{name: "Templates", fn: testTemplates},
+		{name: "Attachments", fn: testAttachments},
+		{name: "Shares", fn: testShares},
+		{name: "Organizations", fn: testOrganizations},
+		{name: "EmergencyAccess", fn: testEmergencyAccess},
+		{name: "Secrets", fn: testSecrets},
+		{name: "Uploads", fn: testUploads},
+		{name: "FileLock", fn: testFileLock},
+		{name: "Usage", fn: testUsage},
+		{name: "FileRefs", fn: testFileRefs},
+		{name: "ItemRefs", fn: testItemRefs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStore(t)
+			t.Cleanup(store.Close)
+			tt.fn(t, store)
+		})
+	}
+}
+
+// uniqueLogin логин, не пересекающийся с данными предыдущих запусков на общей БД.
+func uniqueLogin(prefix string) string {
+	return fmt.Sprintf("%s_%d", prefix, time.Now().UnixNano())
+}
+
+func createUser(t *testing.T, store storage.Interface) int {
+	t.Helper()
+
+	userID, err := store.CreateUser(context.Background(), model.User{Login: uniqueLogin("user"), Password: "password"})
+	require.NoError(t, err)
+	require.NotZero(t, userID)
+
+	return userID
+}
+
+// now время с точностью до микросекунд, которую сохраняют все реализации.
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+func testUsers(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	login := uniqueLogin("user")
+
+	userID, err := store.CreateUser(ctx, model.User{Login: login, Password: "password"})
+	require.NoError(t, err)
+	assert.NotZero(t, userID)
+
+	_, err = store.CreateUser(ctx, model.User{Login: login, Password: "other"})
+	assert.ErrorIs(t, err, storage.ErrorUserAlreadyExists)
+
+	gotID, err := store.GetUserIDByCredentials(ctx, login, "password")
+	require.NoError(t, err)
+	assert.Equal(t, userID, gotID)
+
+	_, err = store.GetUserIDByCredentials(ctx, login, "wrong")
+	assert.ErrorIs(t, err, storage.ErrorUserCredentials)
+
+	_, err = store.GetUserIDByCredentials(ctx, uniqueLogin("missing"), "password")
+	assert.ErrorIs(t, err, storage.ErrorUserCredentials)
+
+	signKey, err := store.GetSignKey(ctx, login, "password")
+	require.NoError(t, err)
+	assert.NotEmpty(t, signKey)
+
+	_, err = store.GetSignKey(ctx, login, "wrong")
+	assert.ErrorIs(t, err, storage.ErrorUserCredentials)
+}
+
+func testRefreshTokens(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+
+	active := uniqueLogin("active")
+	expired := uniqueLogin("expired")
+
+	require.NoError(t, store.SetRefreshToken(ctx, model.RefreshToken{UserID: userID, Token: active, ExpiredAt: time.Now().Add(time.Hour)}))
+	require.NoError(t, store.SetRefreshToken(ctx, model.RefreshToken{UserID: userID, Token: expired, ExpiredAt: time.Now().Add(-time.Hour)}))
+
+	gotID, err := store.GetRefreshTokenUserID(ctx, active)
+	require.NoError(t, err)
+	assert.Equal(t, userID, gotID)
+
+	_, err = store.GetRefreshTokenUserID(ctx, expired)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	_, err = store.GetRefreshTokenUserID(ctx, uniqueLogin("missing"))
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	require.NoError(t, store.ClearExpiredRefreshTokens(ctx))
+
+	gotID, err = store.GetRefreshTokenUserID(ctx, active)
+	require.NoError(t, err)
+	assert.Equal(t, userID, gotID)
+}
+
+func testCards(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	card := model.DataCard{UserID: userID, Title: "title", Number: "number", Date: "date", Cvv: "cvv", Meta: "meta", TagIDs: []int{}, Fields: model.Fields{{Label: "pin", Type: model.FieldTypeHidden, Value: "1234"}}, UpdatedAt: now()}
+
+	id, err := store.SaveCard(ctx, card)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+	card.ID = id
+
+	got, err := store.FindCard(ctx, id, userID)
+	require.NoError(t, err)
+	assert.True(t, card.UpdatedAt.Equal(got.UpdatedAt))
+	got.UserID, got.UpdatedAt = card.UserID, card.UpdatedAt
+	assert.Equal(t, card, got)
+
+	card.Title, card.Cvv, card.UpdatedAt = "new title", "new cvv", now()
+	_, err = store.SaveCard(ctx, card)
+	require.NoError(t, err)
+
+	got, err = store.FindCard(ctx, id, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "new title", got.Title)
+	assert.Equal(t, "new cvv", got.Cvv)
+
+	// чужие записи недоступны
+	_, err = store.FindCard(ctx, id, otherID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	other := card
+	other.UserID = otherID
+	_, err = store.SaveCard(ctx, other)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+	assert.ErrorIs(t, store.DeleteCard(ctx, id, otherID), storage.ErrorNotFound)
+
+	secondID, err := store.SaveCard(ctx, model.DataCard{UserID: userID, Title: "second", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	list, err := store.FindAllCards(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, secondID, list[0].ID)
+	assert.Equal(t, id, list[1].ID)
+	// запись без пользовательских полей возвращает пустой список, а не null
+	assert.Equal(t, model.Fields{}, list[0].Fields)
+	assert.Equal(t, card.Fields, list[1].Fields)
+
+	list, err = store.FindAllCards(ctx, otherID, model.ItemFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	require.NoError(t, store.DeleteCard(ctx, id, userID))
+	assert.ErrorIs(t, store.DeleteCard(ctx, id, userID), storage.ErrorNotFound)
+
+	_, err = store.FindCard(ctx, id, userID)
+	assert.True(t, errors.Is(err, storage.ErrorNotFound))
+}
+
+func testCreds(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	cred := model.DataCred{UserID: userID, Title: "title", Username: "username", Password: "password", Meta: "meta", TagIDs: []int{}, Fields: model.Fields{{Label: "email", Type: model.FieldTypeEmail, Value: "user@example.com"}, {Label: "site", Type: model.FieldTypeURL, Value: "https://example.com"}}, URLs: model.CredURLs{{URL: "https://example.com", Match: model.URLMatchDomain}}, TOTP: "otpauth://totp/example?secret=GEZDGNBVGY3TQOJQ", UpdatedAt: now()}
+
+	id, err := store.SaveCred(ctx, cred)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+	cred.ID = id
+
+	got, err := store.FindCred(ctx, id, userID)
+	require.NoError(t, err)
+	assert.True(t, cred.UpdatedAt.Equal(got.UpdatedAt))
+	got.UserID, got.UpdatedAt = cred.UserID, cred.UpdatedAt
+	assert.Equal(t, cred, got)
+
+	cred.Password, cred.URLs, cred.UpdatedAt = "new password", model.CredURLs{{URL: "^https://git", Match: model.URLMatchRegex}}, now()
+	_, err = store.SaveCred(ctx, cred)
+	require.NoError(t, err)
+
+	got, err = store.FindCred(ctx, id, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "new password", got.Password)
+	assert.Equal(t, cred.URLs, got.URLs)
+
+	_, err = store.FindCred(ctx, id, otherID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	cred.ID = id + 1000
+	_, err = store.SaveCred(ctx, cred)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	list, err := store.FindAllCreds(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, store.DeleteCred(ctx, id, userID))
+	assert.ErrorIs(t, store.DeleteCred(ctx, id, userID), storage.ErrorNotFound)
+
+	_, err = store.FindCred(ctx, id, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+}
+
+func testTexts(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	text := model.DataText{UserID: userID, Title: "title", Text: "text", Meta: "meta", TagIDs: []int{}, Fields: model.Fields{}, UpdatedAt: now()}
+
+	id, err := store.SaveText(ctx, text)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+	text.ID = id
+
+	got, err := store.FindText(ctx, id, userID)
+	require.NoError(t, err)
+	assert.True(t, text.UpdatedAt.Equal(got.UpdatedAt))
+	got.UserID, got.UpdatedAt = text.UserID, text.UpdatedAt
+	assert.Equal(t, text, got)
+
+	text.Text, text.UpdatedAt = "new text", now()
+	_, err = store.SaveText(ctx, text)
+	require.NoError(t, err)
+
+	got, err = store.FindText(ctx, id, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "new text", got.Text)
+
+	_, err = store.FindText(ctx, id, otherID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	list, err := store.FindAllTexts(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, store.DeleteText(ctx, id, userID))
+	assert.ErrorIs(t, store.DeleteText(ctx, id, userID), storage.ErrorNotFound)
+
+	_, err = store.FindText(ctx, id, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+}
+
+func testSSHKeys(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	key := model.DataSSHKey{
+		UserID:      userID,
+		Title:       "github",
+		PrivateKey:  "private",
+		PublicKey:   "ssh-ed25519 AAAA",
+		Fingerprint: "SHA256:abc",
+		Passphrase:  "secret",
+		Meta:        "meta",
+		TagIDs:      []int{},
+		Fields:      model.Fields{},
+		UpdatedAt:   now(),
+	}
+
+	id, err := store.SaveSSHKey(ctx, key)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+	key.ID = id
+
+	got, err := store.FindSSHKey(ctx, id, userID)
+	require.NoError(t, err)
+	assert.True(t, key.UpdatedAt.Equal(got.UpdatedAt))
+	got.UserID, got.UpdatedAt = key.UserID, key.UpdatedAt
+	assert.Equal(t, key, got)
+
+	key.Passphrase, key.UpdatedAt = "", now()
+	_, err = store.SaveSSHKey(ctx, key)
+	require.NoError(t, err)
+
+	got, err = store.FindSSHKey(ctx, id, userID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Passphrase)
+
+	_, err = store.FindSSHKey(ctx, id, otherID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	_, err = store.SaveSSHKey(ctx, model.DataSSHKey{ID: id, UserID: otherID, Title: "x", UpdatedAt: now()})
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	list, err := store.FindAllSSHKeys(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, store.DeleteSSHKey(ctx, id, userID))
+	assert.ErrorIs(t, store.DeleteSSHKey(ctx, id, userID), storage.ErrorNotFound)
+
+	_, err = store.FindSSHKey(ctx, id, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+}
+
+func testIdentities(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	doc := model.DataIdentity{
+		UserID:     userID,
+		Title:      "паспорт",
+		Kind:       model.IdentityKindPassport,
+		FullName:   "name",
+		Number:     "number",
+		Country:    "country",
+		IssueDate:  "issue",
+		ExpiryDate: "expiry",
+		Address:    "address",
+		Meta:       "meta",
+		TagIDs:     []int{},
+		Fields:     model.Fields{},
+		UpdatedAt:  now(),
+	}
+
+	id, err := store.SaveIdentity(ctx, doc)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+	doc.ID = id
+
+	got, err := store.FindIdentity(ctx, id, userID)
+	require.NoError(t, err)
+	assert.True(t, doc.UpdatedAt.Equal(got.UpdatedAt))
+	got.UserID, got.UpdatedAt = doc.UserID, doc.UpdatedAt
+	assert.Equal(t, doc, got)
+
+	doc.Kind, doc.ExpiryDate, doc.UpdatedAt = model.IdentityKindIDCard, "new expiry", now()
+	_, err = store.SaveIdentity(ctx, doc)
+	require.NoError(t, err)
+
+	got, err = store.FindIdentity(ctx, id, userID)
+	require.NoError(t, err)
+	assert.Equal(t, model.IdentityKindIDCard, got.Kind)
+	assert.Equal(t, "new expiry", got.ExpiryDate)
+
+	_, err = store.FindIdentity(ctx, id, otherID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	list, err := store.FindAllIdentities(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, store.DeleteIdentity(ctx, id, userID))
+	assert.ErrorIs(t, store.DeleteIdentity(ctx, id, userID), storage.ErrorNotFound)
+
+	_, err = store.FindIdentity(ctx, id, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+}
+
+func testCustomItems(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	tplID, err := store.SaveTemplate(ctx, model.Template{UserID: userID, Name: "db", Schema: "schema", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	item := model.DataCustom{
+		UserID:     userID,
+		TemplateID: tplID,
+		Title:      "db prod",
+		Values:     "values",
+		Meta:       "meta",
+		TagIDs:     []int{},
+		Fields:     model.Fields{},
+		UpdatedAt:  now(),
+	}
+
+	id, err := store.SaveCustomItem(ctx, item)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+	item.ID = id
+
+	got, err := store.FindCustomItem(ctx, id, userID)
+	require.NoError(t, err)
+	assert.True(t, item.UpdatedAt.Equal(got.UpdatedAt))
+	got.UserID, got.UpdatedAt = item.UserID, item.UpdatedAt
+	assert.Equal(t, item, got)
+
+	item.Values, item.UpdatedAt = "new values", now()
+	_, err = store.SaveCustomItem(ctx, item)
+	require.NoError(t, err)
+
+	got, err = store.FindCustomItem(ctx, id, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "new values", got.Values)
+
+	_, err = store.FindCustomItem(ctx, id, otherID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	list, err := store.FindAllCustomItems(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, store.DeleteCustomItem(ctx, id, userID))
+	assert.ErrorIs(t, store.DeleteCustomItem(ctx, id, userID), storage.ErrorNotFound)
+
+	_, err = store.FindCustomItem(ctx, id, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+}
+
+func testFiles(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	file := model.DataFile{UserID: userID, Title: "title", Filename: "file.txt", Path: "aa/bb/cc/dd", Size: 1234, SHA256: "hash", Meta: "meta", TagIDs: []int{}, Fields: model.Fields{}, UpdatedAt: now()}
+
+	id, err := store.SaveFile(ctx, file)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+	file.ID = id
+
+	got, err := store.FindFile(ctx, id, userID)
+	require.NoError(t, err)
+	assert.True(t, file.UpdatedAt.Equal(got.UpdatedAt))
+	got.UserID, got.UpdatedAt = file.UserID, file.UpdatedAt
+	assert.Equal(t, file, got)
+
+	file.Path, file.SHA256, file.UpdatedAt = "ee/ff/gg/hh", "other", now()
+	_, err = store.SaveFile(ctx, file)
+	require.NoError(t, err)
+
+	got, err = store.FindFile(ctx, id, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "ee/ff/gg/hh", got.Path)
+	assert.Equal(t, "other", got.SHA256)
+
+	_, err = store.FindFile(ctx, id, otherID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	list, err := store.FindAllFiles(ctx, userID, model.ItemFilter{})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, store.DeleteFile(ctx, id, userID))
+	assert.ErrorIs(t, store.DeleteFile(ctx, id, userID), storage.ErrorNotFound)
+
+	_, err = store.FindFile(ctx, id, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+}
+
+func testFolders(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	rootID, err := store.SaveFolder(ctx, model.Folder{UserID: userID, Name: "root", UpdatedAt: now()})
+	require.NoError(t, err)
+	require.NotZero(t, rootID)
+
+	childID, err := store.SaveFolder(ctx, model.Folder{UserID: userID, ParentID: rootID, Name: "child", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	list, err := store.FindAllFolders(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, rootID, list[0].ID)
+	assert.Equal(t, 0, list[0].ParentID)
+	assert.Equal(t, childID, list[1].ID)
+	assert.Equal(t, rootID, list[1].ParentID)
+	assert.Equal(t, "child", list[1].Name)
+
+	_, err = store.SaveFolder(ctx, model.Folder{ID: childID, UserID: userID, Name: "renamed", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	list, err = store.FindAllFolders(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", list[1].Name)
+	assert.Equal(t, 0, list[1].ParentID)
+
+	_, err = store.SaveFolder(ctx, model.Folder{ID: childID, UserID: otherID, Name: "other", UpdatedAt: now()})
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+	assert.ErrorIs(t, store.DeleteFolder(ctx, childID, otherID), storage.ErrorNotFound)
+
+	list, err = store.FindAllFolders(ctx, otherID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// вложенные папки удаляются вместе с родительской
+	_, err = store.SaveFolder(ctx, model.Folder{ID: childID, UserID: userID, ParentID: rootID, Name: "child", UpdatedAt: now()})
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteFolder(ctx, rootID, userID))
+	assert.ErrorIs(t, store.DeleteFolder(ctx, rootID, userID), storage.ErrorNotFound)
+
+	list, err = store.FindAllFolders(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func testTags(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	id, err := store.SaveTag(ctx, model.Tag{UserID: userID, Name: "tag", UpdatedAt: now()})
+	require.NoError(t, err)
+	require.NotZero(t, id)
+
+	_, err = store.SaveTag(ctx, model.Tag{ID: id, UserID: userID, Name: "renamed", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	list, err := store.FindAllTags(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, id, list[0].ID)
+	assert.Equal(t, "renamed", list[0].Name)
+
+	_, err = store.SaveTag(ctx, model.Tag{ID: id, UserID: otherID, Name: "other", UpdatedAt: now()})
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+	assert.ErrorIs(t, store.DeleteTag(ctx, id, otherID), storage.ErrorNotFound)
+
+	list, err = store.FindAllTags(ctx, otherID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	require.NoError(t, store.DeleteTag(ctx, id, userID))
+	assert.ErrorIs(t, store.DeleteTag(ctx, id, userID), storage.ErrorNotFound)
+}
+
+func testTemplates(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	id, err := store.SaveTemplate(ctx, model.Template{UserID: userID, Name: "db", Schema: "schema", UpdatedAt: now()})
+	require.NoError(t, err)
+	require.NotZero(t, id)
+
+	_, err = store.SaveTemplate(ctx, model.Template{ID: id, UserID: userID, Name: "database", Schema: "new schema", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	list, err := store.FindAllTemplates(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, id, list[0].ID)
+	assert.Equal(t, "database", list[0].Name)
+	assert.Equal(t, "new schema", list[0].Schema)
+
+	_, err = store.SaveTemplate(ctx, model.Template{ID: id, UserID: otherID, Name: "other", Schema: "schema", UpdatedAt: now()})
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+	assert.ErrorIs(t, store.DeleteTemplate(ctx, id, otherID), storage.ErrorNotFound)
+
+	list, err = store.FindAllTemplates(ctx, otherID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// записи по шаблону удаляются вместе с ним
+	tagID, err := store.SaveTag(ctx, model.Tag{UserID: userID, Name: "tag", UpdatedAt: now()})
+	require.NoError(t, err)
+	itemID, err := store.SaveCustomItem(ctx, model.DataCustom{UserID: userID, TemplateID: id, Title: "item", Values: "values", TagIDs: []int{tagID}, UpdatedAt: now()})
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteTemplate(ctx, id, userID))
+	assert.ErrorIs(t, store.DeleteTemplate(ctx, id, userID), storage.ErrorNotFound)
+
+	_, err = store.FindCustomItem(ctx, itemID, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+}
+
+func testAttachments(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	cardID, err := store.SaveCard(ctx, model.DataCard{UserID: userID, Title: "card", Number: "1234", Date: "12/30", Cvv: "123", UpdatedAt: now()})
+	require.NoError(t, err)
+	credID, err := store.SaveCred(ctx, model.DataCred{UserID: userID, Title: "cred", Username: "user", Password: "pass", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	att := model.Attachment{UserID: userID, ItemType: model.ItemTypeCard, ItemID: cardID, Filename: "scan.png", Path: "_file_storage/aa/bb/cc/scan", Size: 2048, UpdatedAt: now()}
+	id, err := store.SaveAttachment(ctx, att)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+
+	keyID, err := store.SaveAttachment(ctx, model.Attachment{UserID: userID, ItemType: model.ItemTypeCred, ItemID: credID, Filename: "key.pem", Path: "_file_storage/aa/bb/cc/key", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	got, err := store.FindAttachment(ctx, id, userID)
+	require.NoError(t, err)
+	assert.Equal(t, id, got.ID)
+	assert.Equal(t, att.ItemType, got.ItemType)
+	assert.Equal(t, att.ItemID, got.ItemID)
+	assert.Equal(t, att.Filename, got.Filename)
+	assert.Equal(t, att.Path, got.Path)
+	assert.True(t, att.UpdatedAt.Equal(got.UpdatedAt))
+
+	_, err = store.FindAttachment(ctx, id, otherID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+	assert.ErrorIs(t, store.DeleteAttachment(ctx, id, otherID), storage.ErrorNotFound)
+
+	list, err := store.FindAllAttachments(ctx, userID, model.AttachmentFilter{})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	list, err = store.FindAllAttachments(ctx, userID, model.AttachmentFilter{ItemType: model.ItemTypeCard, ItemID: cardID})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, id, list[0].ID)
+
+	list, err = store.FindAllAttachments(ctx, otherID, model.AttachmentFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// вложения удаляются вместе с записью
+	require.NoError(t, store.DeleteCard(ctx, cardID, userID))
+	_, err = store.FindAttachment(ctx, id, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	require.NoError(t, store.DeleteAttachment(ctx, keyID, userID))
+	assert.ErrorIs(t, store.DeleteAttachment(ctx, keyID, userID), storage.ErrorNotFound)
+
+	// и вместе с записями по удаленному шаблону
+	tplID, err := store.SaveTemplate(ctx, model.Template{UserID: userID, Name: "db", Schema: "schema", UpdatedAt: now()})
+	require.NoError(t, err)
+	itemID, err := store.SaveCustomItem(ctx, model.DataCustom{UserID: userID, TemplateID: tplID, Title: "item", Values: "values", UpdatedAt: now()})
+	require.NoError(t, err)
+	customAttID, err := store.SaveAttachment(ctx, model.Attachment{UserID: userID, ItemType: model.ItemTypeCustom, ItemID: itemID, Filename: "dump.sql", Path: "_file_storage/aa/bb/cc/dump", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteTemplate(ctx, tplID, userID))
+	_, err = store.FindAttachment(ctx, customAttID, userID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+}
+
+func testShares(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	ownerLogin, recipientLogin := uniqueLogin("owner"), uniqueLogin("recipient")
+	ownerID, err := store.CreateUser(ctx, model.User{Login: ownerLogin, Password: "password"})
+	require.NoError(t, err)
+	recipientID, err := store.CreateUser(ctx, model.User{Login: recipientLogin, Password: "password"})
+	require.NoError(t, err)
+
+	_, err = store.FindUserKeys(ctx, ownerID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+	_, err = store.FindPublicKey(ctx, recipientLogin)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	for _, id := range []int{ownerID, recipientID} {
+		require.NoError(t, store.SaveUserKeys(ctx, model.UserKeys{UserID: id, PublicKey: "old", PrivateKey: "old", UpdatedAt: now()}))
+		require.NoError(t, store.SaveUserKeys(ctx, model.UserKeys{UserID: id, PublicKey: "public", PrivateKey: "private", UpdatedAt: now()}))
+	}
+
+	keys, err := store.FindUserKeys(ctx, ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, "public", keys.PublicKey)
+	assert.Equal(t, "private", keys.PrivateKey)
+
+	pub, err := store.FindPublicKey(ctx, recipientLogin)
+	require.NoError(t, err)
+	assert.Equal(t, recipientID, pub.UserID)
+	assert.Equal(t, recipientLogin, pub.Login)
+	assert.Equal(t, "public", pub.PublicKey)
+
+	cardID, err := store.SaveCard(ctx, model.DataCard{UserID: ownerID, Title: "card", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	share := model.Share{UserID: ownerID, RecipientID: recipientID, ItemType: model.ItemTypeCard, ItemID: cardID,
+		Access: model.ShareAccessRead, ItemKey: "item key", OwnerKey: "owner key", Data: "data", UpdatedAt: now()}
+	id, err := store.SaveShare(ctx, share)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+
+	_, err = store.SaveShare(ctx, share)
+	assert.ErrorIs(t, err, storage.ErrorRowAlreadyExists)
+
+	share.ID = id
+	share.Access = model.ShareAccessWrite
+	_, err = store.SaveShare(ctx, share)
+	require.NoError(t, err)
+
+	// получателя доступа сменить нельзя
+	moved := share
+	moved.RecipientID = ownerID
+	_, err = store.SaveShare(ctx, moved)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	list, err := store.FindAllShares(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, id, list[0].ID)
+	assert.Equal(t, ownerLogin, list[0].Owner)
+	assert.Equal(t, recipientLogin, list[0].Recipient)
+	assert.Equal(t, model.ShareAccessWrite, list[0].Access)
+	assert.Equal(t, "item key", list[0].ItemKey)
+	assert.Equal(t, "owner key", list[0].OwnerKey)
+
+	require.NoError(t, store.UpdateShareData(ctx, model.Share{ID: id, RecipientID: recipientID, Data: "changed", UpdatedAt: now()}))
+	assert.ErrorIs(t, store.UpdateShareData(ctx, model.Share{ID: id, RecipientID: ownerID, Data: "x", UpdatedAt: now()}), storage.ErrorNotFound)
+
+	list, err = store.FindAllIncomingShares(ctx, recipientID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "changed", list[0].Data)
+	assert.Equal(t, ownerLogin, list[0].Owner)
+
+	list, err = store.FindAllIncomingShares(ctx, ownerID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	assert.ErrorIs(t, store.DeleteShare(ctx, id, recipientID), storage.ErrorNotFound)
+	require.NoError(t, store.DeleteShare(ctx, id, ownerID))
+
+	// доступы удаляются вместе с записью
+	share.ID = 0
+	_, err = store.SaveShare(ctx, share)
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteCard(ctx, cardID, ownerID))
+
+	list, err = store.FindAllIncomingShares(ctx, recipientID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+// testOrganizations проверяет организации: участников, приглашения, ключи и записи коллекций и каскадное удаление.
+func testOrganizations(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	ownerLogin, memberLogin := uniqueLogin("owner"), uniqueLogin("member")
+	ownerID, err := store.CreateUser(ctx, model.User{Login: ownerLogin, Password: "password"})
+	require.NoError(t, err)
+	memberID, err := store.CreateUser(ctx, model.User{Login: memberLogin, Password: "password"})
+	require.NoError(t, err)
+	require.NoError(t, store.SaveUserKeys(ctx, model.UserKeys{UserID: memberID, PublicKey: "public", PrivateKey: "private", UpdatedAt: now()}))
+
+	_, err = store.SaveOrganization(ctx, model.Organization{ID: -1, Name: "missing", UpdatedAt: now()}, ownerID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	orgID, err := store.SaveOrganization(ctx, model.Organization{Name: "team", UpdatedAt: now()}, ownerID)
+	require.NoError(t, err)
+	require.NotZero(t, orgID)
+	_, err = store.SaveOrganization(ctx, model.Organization{ID: orgID, Name: "renamed", UpdatedAt: now()}, ownerID)
+	require.NoError(t, err)
+
+	orgs, err := store.FindAllOrganizations(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, "renamed", orgs[0].Name)
+	assert.Equal(t, model.RoleOwner, orgs[0].Role)
+	assert.Equal(t, model.MemberStatusActive, orgs[0].Status)
+
+	colID, err := store.SaveCollection(ctx, model.Collection{OrgID: orgID, Name: "prod", UpdatedAt: now(),
+		Keys: []model.CollectionKey{{UserID: ownerID, Key: "owner key"}}})
+	require.NoError(t, err)
+	require.NotZero(t, colID)
+	_, err = store.SaveCollection(ctx, model.Collection{ID: colID, OrgID: orgID + 1, Name: "x", UpdatedAt: now()})
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	col, err := store.FindCollection(ctx, colID)
+	require.NoError(t, err)
+	assert.Equal(t, orgID, col.OrgID)
+	assert.Equal(t, "prod", col.Name)
+	_, err = store.FindCollection(ctx, -1)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	// приглашенный участник не видит коллекций, пока не примет приглашение
+	member := model.Member{OrgID: orgID, UserID: memberID, Role: model.RoleReadOnly, Status: model.MemberStatusInvited, UpdatedAt: now(),
+		Keys: []model.CollectionKey{{CollectionID: colID, UserID: memberID, Key: "member key"}}}
+	require.NoError(t, store.SaveMember(ctx, member))
+
+	cols, err := store.FindAllCollections(ctx, memberID)
+	require.NoError(t, err)
+	assert.Empty(t, cols)
+
+	member.Status = model.MemberStatusActive
+	member.Keys = nil
+	require.NoError(t, store.SaveMember(ctx, member))
+
+	found, err := store.FindMember(ctx, orgID, memberID)
+	require.NoError(t, err)
+	assert.Equal(t, memberLogin, found.Login)
+	assert.Equal(t, "public", found.PublicKey)
+	assert.Equal(t, model.RoleReadOnly, found.Role)
+	assert.Equal(t, model.MemberStatusActive, found.Status)
+	_, err = store.FindMember(ctx, orgID+1, memberID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	members, err := store.FindAllMembers(ctx, orgID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, memberLogin, members[0].Login)
+	assert.Equal(t, ownerLogin, members[1].Login)
+	assert.Empty(t, members[1].PublicKey)
+
+	cols, err = store.FindAllCollections(ctx, memberID)
+	require.NoError(t, err)
+	require.Len(t, cols, 1)
+	assert.Equal(t, "member key", cols[0].Key)
+	assert.Equal(t, model.RoleReadOnly, cols[0].Role)
+
+	itemID, err := store.SaveCollectionItem(ctx, model.CollectionItem{CollectionID: colID, ItemType: model.ItemTypeCred, Data: "data", UpdatedAt: now()})
+	require.NoError(t, err)
+	require.NotZero(t, itemID)
+	_, err = store.SaveCollectionItem(ctx, model.CollectionItem{ID: itemID, CollectionID: colID, UserID: memberID, Data: "changed", UpdatedAt: now()})
+	require.NoError(t, err)
+	_, err = store.SaveCollectionItem(ctx, model.CollectionItem{ID: itemID, CollectionID: colID + 1, Data: "x", UpdatedAt: now()})
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	items, err := store.FindAllCollectionItems(ctx, colID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "changed", items[0].Data)
+	assert.Equal(t, model.ItemTypeCred, items[0].ItemType)
+	assert.Equal(t, memberID, items[0].UserID)
+
+	assert.ErrorIs(t, store.DeleteCollectionItem(ctx, itemID, colID+1), storage.ErrorNotFound)
+	require.NoError(t, store.DeleteCollectionItem(ctx, itemID, colID))
+
+	// исключенный участник теряет ключи коллекций
+	require.NoError(t, store.DeleteMember(ctx, orgID, memberID))
+	assert.ErrorIs(t, store.DeleteMember(ctx, orgID, memberID), storage.ErrorNotFound)
+	require.NoError(t, store.SaveMember(ctx, member))
+
+	cols, err = store.FindAllCollections(ctx, memberID)
+	require.NoError(t, err)
+	assert.Empty(t, cols)
+
+	_, err = store.SaveCollectionItem(ctx, model.CollectionItem{CollectionID: colID, ItemType: model.ItemTypeText, Data: "data", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteOrganization(ctx, orgID))
+	assert.ErrorIs(t, store.DeleteOrganization(ctx, orgID), storage.ErrorNotFound)
+
+	_, err = store.FindCollection(ctx, colID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+	items, err = store.FindAllCollectionItems(ctx, colID)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+	orgs, err = store.FindAllOrganizations(ctx, memberID)
+	require.NoError(t, err)
+	assert.Empty(t, orgs)
+}
+
+func testEmergencyAccess(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	grantorLogin, granteeLogin := uniqueLogin("grantor"), uniqueLogin("grantee")
+	grantorID, err := store.CreateUser(ctx, model.User{Login: grantorLogin, Password: "password"})
+	require.NoError(t, err)
+	granteeID, err := store.CreateUser(ctx, model.User{Login: granteeLogin, Password: "password"})
+	require.NoError(t, err)
+
+	access := model.EmergencyAccess{UserID: grantorID, GranteeID: granteeID, WaitHours: 48, Key: "key",
+		Status: model.EmergencyStatusIdle, UpdatedAt: now()}
+	id, err := store.SaveEmergencyAccess(ctx, access)
+	require.NoError(t, err)
+	require.NotZero(t, id)
+
+	_, err = store.SaveEmergencyAccess(ctx, access)
+	assert.ErrorIs(t, err, storage.ErrorRowAlreadyExists)
+
+	access.ID = id
+	access.WaitHours = 24
+	access.Key = "new key"
+	_, err = store.SaveEmergencyAccess(ctx, access)
+	require.NoError(t, err)
+
+	// доверенное лицо сменить нельзя
+	moved := access
+	moved.GranteeID = grantorID
+	_, err = store.SaveEmergencyAccess(ctx, moved)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	requestedAt := now()
+	require.NoError(t, store.UpdateEmergencyStatus(ctx, model.EmergencyAccess{ID: id, Status: model.EmergencyStatusRequested, RequestedAt: requestedAt, UpdatedAt: now()}))
+	assert.ErrorIs(t, store.UpdateEmergencyStatus(ctx, model.EmergencyAccess{ID: -1, Status: model.EmergencyStatusRequested, UpdatedAt: now()}), storage.ErrorNotFound)
+
+	found, err := store.FindEmergencyAccess(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, grantorID, found.UserID)
+	assert.Equal(t, granteeID, found.GranteeID)
+	assert.Equal(t, grantorLogin, found.Grantor)
+	assert.Equal(t, granteeLogin, found.Grantee)
+	assert.Equal(t, 24, found.WaitHours)
+	assert.Equal(t, "new key", found.Key)
+	assert.Equal(t, model.EmergencyStatusRequested, found.Status)
+	assert.True(t, requestedAt.Equal(found.RequestedAt))
+
+	_, err = store.FindEmergencyAccess(ctx, -1)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	list, err := store.FindAllEmergencyAccess(ctx, grantorID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, granteeLogin, list[0].Grantee)
+
+	list, err = store.FindAllIncomingEmergencyAccess(ctx, granteeID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, grantorLogin, list[0].Grantor)
+
+	list, err = store.FindAllIncomingEmergencyAccess(ctx, grantorID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	assert.ErrorIs(t, store.DeleteEmergencyAccess(ctx, id, granteeID), storage.ErrorNotFound)
+	require.NoError(t, store.DeleteEmergencyAccess(ctx, id, grantorID))
+
+	list, err = store.FindAllEmergencyAccess(ctx, grantorID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func testUploads(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	prefix := uniqueLogin("upload")
+
+	upload := model.Upload{ID: prefix, UserID: userID, Size: 10, ChunkSize: 4, ExpiresAt: now().Add(time.Hour), CreatedAt: now()}
+	expired := model.Upload{ID: prefix + "_expired", UserID: userID, Size: 10, ChunkSize: 4, ExpiresAt: now().Add(-time.Hour), CreatedAt: now()}
+
+	require.NoError(t, store.SaveUpload(ctx, upload))
+	require.NoError(t, store.SaveUpload(ctx, expired))
+	assert.ErrorIs(t, store.SaveUpload(ctx, upload), storage.ErrorRowAlreadyExists)
+
+	got, err := store.FindUpload(ctx, upload.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), got.Size)
+	assert.Equal(t, int64(4), got.ChunkSize)
+	assert.Equal(t, int64(0), got.Offset)
+
+	otherID := createUser(t, store)
+	_, err = store.FindUpload(ctx, upload.ID, otherID)
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	// смещение меняется только с ожидаемого значения
+	next := upload
+	next.Offset = 4
+	next.ExpiresAt = now().Add(2 * time.Hour)
+	require.NoError(t, store.UpdateUploadOffset(ctx, next, 0))
+	assert.ErrorIs(t, store.UpdateUploadOffset(ctx, next, 0), storage.ErrorNotFound)
+
+	got, err = store.FindUpload(ctx, upload.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), got.Offset)
+	assert.WithinDuration(t, next.ExpiresAt, got.ExpiresAt, time.Second)
+
+	// завершенную загрузку находит по хешу только ее владелец и только до истечения срока
+	hash := strings.Repeat("ab", 32)
+	_, err = store.FindCompletedUpload(ctx, userID, hash, time.Now())
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	done := next
+	done.SHA256 = hash
+	require.NoError(t, store.CompleteUpload(ctx, done))
+	assert.ErrorIs(t, store.CompleteUpload(ctx, done), storage.ErrorNotFound)
+
+	got, err = store.FindCompletedUpload(ctx, userID, hash, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, upload.ID, got.ID)
+	assert.Equal(t, hash, got.SHA256)
+
+	_, err = store.FindCompletedUpload(ctx, otherID, hash, time.Now())
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+	_, err = store.FindCompletedUpload(ctx, userID, hash, next.ExpiresAt.Add(time.Hour))
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	list, err := store.FindExpiredUploads(ctx, time.Now())
+	require.NoError(t, err)
+	ids := make([]string, 0, len(list))
+	for _, v := range list {
+		ids = append(ids, v.ID)
+	}
+	assert.Contains(t, ids, expired.ID)
+	assert.NotContains(t, ids, upload.ID)
+
+	ids, err = store.FindUploadIDs(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, ids, upload.ID)
+	assert.Contains(t, ids, expired.ID)
+
+	assert.ErrorIs(t, store.DeleteUpload(ctx, upload.ID, otherID), storage.ErrorNotFound)
+	require.NoError(t, store.DeleteUpload(ctx, upload.ID, userID))
+	require.NoError(t, store.DeleteUpload(ctx, expired.ID, userID))
+	assert.ErrorIs(t, store.DeleteUpload(ctx, upload.ID, userID), storage.ErrorNotFound)
+}
+
+// testFileLock проверяет, что блокировка содержимого не пускает второго владельца, пока первый не выполнил fn.
+func testFileLock(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	path := "_file_storage/sha256/" + uniqueLogin("lock")
+
+	// ошибка fn возвращается как есть, блокировка при этом снимается
+	errFn := errors.New("fn failed")
+	assert.ErrorIs(t, store.LockFile(ctx, path, func() error { return errFn }), errFn)
+
+	var (
+		active  int32
+		overlap int32
+		wg      sync.WaitGroup
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := store.LockFile(ctx, path, func() error {
+				if atomic.AddInt32(&active, 1) > 1 {
+					atomic.StoreInt32(&overlap, 1)
+				}
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&active, -1)
+
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Zero(t, atomic.LoadInt32(&overlap))
+
+	// занятую блокировку ждут не дольше ctx
+	held := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_ = store.LockFile(ctx, path, func() error {
+			close(held)
+			<-release
+
+			return nil
+		})
+	}()
+	<-held
+
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, store.LockFile(timeout, path, func() error { return nil }), context.DeadlineExceeded)
+	close(release)
+
+	require.NoError(t, store.LockFile(ctx, path, func() error { return nil }))
+}
+
+// testItemRefs проверяет папки и метки записей: сохранение, фильтры списков и удаление связей.
+func testSecrets(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	prefix := uniqueLogin("secret")
+
+	once := model.Secret{ID: prefix + "_once", UserID: userID, Data: "data", MaxViews: 1, ExpiresAt: now().Add(time.Hour), CreatedAt: now()}
+	twice := model.Secret{ID: prefix + "_twice", UserID: userID, Data: "data", MaxViews: 2, ExpiresAt: now().Add(time.Hour), CreatedAt: now().Add(time.Second)}
+	expired := model.Secret{ID: prefix + "_expired", UserID: userID, Data: "data", MaxViews: 1, ExpiresAt: now().Add(-time.Hour), CreatedAt: now()}
+
+	for _, v := range []model.Secret{once, twice, expired} {
+		require.NoError(t, store.SaveSecret(ctx, v))
+	}
+	assert.ErrorIs(t, store.SaveSecret(ctx, once), storage.ErrorRowAlreadyExists)
+
+	list, err := store.FindAllSecrets(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Empty(t, list[0].Data)
+
+	secret, err := store.OpenSecret(ctx, once.ID, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "data", secret.Data)
+	assert.Equal(t, 1, secret.Views)
+
+	_, err = store.OpenSecret(ctx, once.ID, time.Now())
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	_, err = store.OpenSecret(ctx, expired.ID, time.Now())
+	assert.ErrorIs(t, err, storage.ErrorNotFound)
+
+	secret, err = store.OpenSecret(ctx, twice.ID, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, secret.Views)
+
+	list, err = store.FindAllSecrets(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, twice.ID, list[1].ID)
+	assert.Equal(t, 1, list[1].Views)
+
+	require.NoError(t, store.ClearExpiredSecrets(ctx))
+	list, err = store.FindAllSecrets(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	assert.ErrorIs(t, store.DeleteSecret(ctx, twice.ID, createUser(t, store)), storage.ErrorNotFound)
+	require.NoError(t, store.DeleteSecret(ctx, twice.ID, userID))
+	assert.ErrorIs(t, store.DeleteSecret(ctx, twice.ID, userID), storage.ErrorNotFound)
+}
+
+func testItemRefs(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+
+	rootID, err := store.SaveFolder(ctx, model.Folder{UserID: userID, Name: "root", UpdatedAt: now()})
+	require.NoError(t, err)
+	childID, err := store.SaveFolder(ctx, model.Folder{UserID: userID, ParentID: rootID, Name: "child", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	workID, err := store.SaveTag(ctx, model.Tag{UserID: userID, Name: "work", UpdatedAt: now()})
+	require.NoError(t, err)
+	homeID, err := store.SaveTag(ctx, model.Tag{UserID: userID, Name: "home", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	cardID, err := store.SaveCard(ctx, model.DataCard{UserID: userID, Title: "card", FolderID: childID, TagIDs: []int{homeID, workID, workID}, UpdatedAt: now()})
+	require.NoError(t, err)
+	credID, err := store.SaveCred(ctx, model.DataCred{UserID: userID, Title: "cred", FolderID: rootID, TagIDs: []int{workID}, UpdatedAt: now()})
+	require.NoError(t, err)
+	textID, err := store.SaveText(ctx, model.DataText{UserID: userID, Title: "text", TagIDs: []int{homeID}, UpdatedAt: now()})
+	require.NoError(t, err)
+	fileID, err := store.SaveFile(ctx, model.DataFile{UserID: userID, Title: "file", FolderID: childID, UpdatedAt: now()})
+	require.NoError(t, err)
+	sshID, err := store.SaveSSHKey(ctx, model.DataSSHKey{UserID: userID, Title: "ssh", FolderID: rootID, TagIDs: []int{homeID}, UpdatedAt: now()})
+	require.NoError(t, err)
+
+	card, err := store.FindCard(ctx, cardID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, childID, card.FolderID)
+	assert.Equal(t, []int{workID, homeID}, card.TagIDs)
+
+	cards, err := store.FindAllCards(ctx, userID, model.ItemFilter{FolderID: childID})
+	require.NoError(t, err)
+	require.Len(t, cards, 1)
+	assert.Equal(t, []int{workID, homeID}, cards[0].TagIDs)
+
+	cards, err = store.FindAllCards(ctx, userID, model.ItemFilter{FolderID: rootID})
+	require.NoError(t, err)
+	assert.Empty(t, cards)
+
+	creds, err := store.FindAllCreds(ctx, userID, model.ItemFilter{TagID: workID})
+	require.NoError(t, err)
+	require.Len(t, creds, 1)
+	assert.Equal(t, credID, creds[0].ID)
+
+	texts, err := store.FindAllTexts(ctx, userID, model.ItemFilter{TagID: workID})
+	require.NoError(t, err)
+	assert.Empty(t, texts)
+
+	texts, err = store.FindAllTexts(ctx, userID, model.ItemFilter{TagID: homeID})
+	require.NoError(t, err)
+	require.Len(t, texts, 1)
+	assert.Equal(t, textID, texts[0].ID)
+
+	files, err := store.FindAllFiles(ctx, userID, model.ItemFilter{FolderID: childID, TagID: homeID})
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	keys, err := store.FindAllSSHKeys(ctx, userID, model.ItemFilter{FolderID: rootID, TagID: homeID})
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, sshID, keys[0].ID)
+
+	// повторное сохранение заменяет метки
+	card.UserID, card.TagIDs, card.UpdatedAt = userID, []int{homeID}, now()
+	_, err = store.SaveCard(ctx, card)
+	require.NoError(t, err)
+
+	cards, err = store.FindAllCards(ctx, userID, model.ItemFilter{TagID: workID})
+	require.NoError(t, err)
+	assert.Empty(t, cards)
+
+	// удалённая метка снимается с записей
+	require.NoError(t, store.DeleteTag(ctx, homeID, userID))
+
+	card, err = store.FindCard(ctx, cardID, userID)
+	require.NoError(t, err)
+	assert.Empty(t, card.TagIDs)
+
+	// записи из удалённых папок (включая вложенные) остаются без папки
+	require.NoError(t, store.DeleteFolder(ctx, rootID, userID))
+
+	card, err = store.FindCard(ctx, cardID, userID)
+	require.NoError(t, err)
+	assert.Zero(t, card.FolderID)
+
+	cred, err := store.FindCred(ctx, credID, userID)
+	require.NoError(t, err)
+	assert.Zero(t, cred.FolderID)
+	assert.Equal(t, []int{workID}, cred.TagIDs)
+
+	file, err := store.FindFile(ctx, fileID, userID)
+	require.NoError(t, err)
+	assert.Zero(t, file.FolderID)
+
+	key, err := store.FindSSHKey(ctx, sshID, userID)
+	require.NoError(t, err)
+	assert.Zero(t, key.FolderID)
+	assert.Empty(t, key.TagIDs)
+}
+
+func testUsage(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+
+	usage, err := store.GetUsage(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, model.Usage{}, usage)
+
+	// объем - данные записей всех типов (PayloadSize) и содержимое файлов и вложений, число - все строки
+	text := model.DataText{UserID: userID, Title: "text", Text: "текст", Meta: "meta", UpdatedAt: now()}
+	file := model.DataFile{UserID: userID, Title: "file", Filename: "f", Path: "_file_storage/aa/bb/cc/f", Size: 100, UpdatedAt: now()}
+	card := model.DataCard{UserID: userID, Title: "card", Number: "4242", Cvv: "123", UpdatedAt: now()}
+	cred := model.DataCred{UserID: userID, Title: "cred", Username: "user", Password: "secret", TOTP: "totp", UpdatedAt: now()}
+	key := model.DataSSHKey{UserID: userID, Title: "ssh", PrivateKey: "private", PublicKey: "public", Fingerprint: "SHA256:x", UpdatedAt: now()}
+	doc := model.DataIdentity{UserID: userID, Title: "passport", Kind: model.IdentityKindPassport, FullName: "Иван", Number: "1", UpdatedAt: now()}
+	folder := model.Folder{UserID: userID, Name: "folder", UpdatedAt: now()}
+	tag := model.Tag{UserID: userID, Name: "tag", UpdatedAt: now()}
+	tpl := model.Template{UserID: userID, Name: "template", Schema: `{"fields":[]}`, UpdatedAt: now()}
+
+	_, err = store.SaveText(ctx, text)
+	require.NoError(t, err)
+	_, err = store.SaveFile(ctx, file)
+	require.NoError(t, err)
+	card.ID, err = store.SaveCard(ctx, card)
+	require.NoError(t, err)
+	_, err = store.SaveCred(ctx, cred)
+	require.NoError(t, err)
+	_, err = store.SaveSSHKey(ctx, key)
+	require.NoError(t, err)
+	_, err = store.SaveIdentity(ctx, doc)
+	require.NoError(t, err)
+	_, err = store.SaveFolder(ctx, folder)
+	require.NoError(t, err)
+	_, err = store.SaveTag(ctx, tag)
+	require.NoError(t, err)
+	tpl.ID, err = store.SaveTemplate(ctx, tpl)
+	require.NoError(t, err)
+
+	custom := model.DataCustom{UserID: userID, TemplateID: tpl.ID, Title: "custom", Values: `{"a":"b"}`, UpdatedAt: now()}
+	att := model.Attachment{UserID: userID, ItemType: model.ItemTypeCard, ItemID: card.ID, Filename: "a", Path: "_file_storage/aa/bb/cc/a", Size: 50, UpdatedAt: now()}
+	share := model.Share{UserID: userID, RecipientID: otherID, ItemType: model.ItemTypeCard, ItemID: card.ID,
+		Access: model.ShareAccessRead, ItemKey: "item key", OwnerKey: "owner key", Data: "data", UpdatedAt: now()}
+	secret := model.Secret{ID: uniqueLogin("usage"), UserID: userID, Data: "secret data", MaxViews: 1, ExpiresAt: now().Add(time.Hour), CreatedAt: now()}
+
+	_, err = store.SaveCustomItem(ctx, custom)
+	require.NoError(t, err)
+	_, err = store.SaveAttachment(ctx, att)
+	require.NoError(t, err)
+	_, err = store.SaveShare(ctx, share)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveSecret(ctx, secret))
+
+	orgID, err := store.SaveOrganization(ctx, model.Organization{Name: "team", UpdatedAt: now()}, userID)
+	require.NoError(t, err)
+	colID, err := store.SaveCollection(ctx, model.Collection{OrgID: orgID, Name: "prod", UpdatedAt: now(),
+		Keys: []model.CollectionKey{{UserID: userID, Key: "key"}}})
+	require.NoError(t, err)
+	item := model.CollectionItem{CollectionID: colID, UserID: userID, ItemType: model.ItemTypeCard, Data: "collection data", UpdatedAt: now()}
+	item.ID, err = store.SaveCollectionItem(ctx, item)
+	require.NoError(t, err)
+
+	_, err = store.SaveCred(ctx, model.DataCred{UserID: otherID, Title: "other", UpdatedAt: now()})
+	require.NoError(t, err)
+
+	want := model.Usage{
+		Bytes: text.PayloadSize() + file.PayloadSize() + file.Size + card.PayloadSize() + cred.PayloadSize() +
+			key.PayloadSize() + doc.PayloadSize() + folder.PayloadSize() + tag.PayloadSize() + tpl.PayloadSize() +
+			custom.PayloadSize() + att.PayloadSize() + att.Size + share.PayloadSize() + secret.PayloadSize() + item.PayloadSize(),
+		Items: 14,
+	}
+	usage, err = store.GetUsage(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, want, usage)
+
+	// запись коллекции учитывается у участника, который сохранил ее последним
+	item.UserID = otherID
+	item.Data = "changed"
+	_, err = store.SaveCollectionItem(ctx, item)
+	require.NoError(t, err)
+
+	usage, err = store.GetUsage(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, want.Items-1, usage.Items)
+
+	other := model.DataCred{Title: "other"}
+	usage, err = store.GetUsage(ctx, otherID)
+	require.NoError(t, err)
+	assert.Equal(t, model.Usage{Bytes: other.PayloadSize() + item.PayloadSize(), Items: 2}, usage)
+}
+
+func testFileRefs(t *testing.T, store storage.Interface) {
+	ctx := context.Background()
+	userID := createUser(t, store)
+	otherID := createUser(t, store)
+	path := "_file_storage/sha256/" + uniqueLogin("blob")
+
+	refs, err := store.CountFileRefs(ctx, path)
+	require.NoError(t, err)
+	assert.Zero(t, refs)
+
+	fileID, err := store.SaveFile(ctx, model.DataFile{UserID: userID, Title: "file", Filename: "f", Path: path, UpdatedAt: now()})
+	require.NoError(t, err)
+	_, err = store.SaveFile(ctx, model.DataFile{UserID: otherID, Title: "file", Filename: "f", Path: path, UpdatedAt: now()})
+	require.NoError(t, err)
+	_, err = store.SaveAttachment(ctx, model.Attachment{UserID: userID, ItemType: model.ItemTypeFile, ItemID: fileID, Filename: "a", Path: path, UpdatedAt: now()})
+	require.NoError(t, err)
+
+	refs, err = store.CountFileRefs(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, refs)
+
+	for id, want := range map[int]int{userID: 2, otherID: 1, createUser(t, store): 0} {
+		refs, err = store.CountUserFileRefs(ctx, id, path)
+		require.NoError(t, err)
+		assert.Equal(t, want, refs)
+	}
+
+	all, err := store.FindFileRefs(ctx)
+	require.NoError(t, err)
+	var found []model.FileRef
+	for _, v := range all {
+		assert.NotEmpty(t, v.Path)
+		if v.Path == path {
+			found = append(found, v)
+		}
+	}
+	require.Len(t, found, 3)
+	assert.Equal(t, model.FileRefTableAttachments, found[0].Table)
+	assert.Equal(t, userID, found[0].UserID)
+	assert.Equal(t, model.FileRef{Table: model.FileRefTableFiles, ID: fileID, UserID: userID, Path: path}, found[1])
+	assert.Equal(t, model.FileRefTableFiles, found[2].Table)
+	assert.Equal(t, otherID, found[2].UserID)
+
+	require.NoError(t, store.DeleteFile(ctx, fileID, userID))
+
+	refs, err = store.CountFileRefs(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, refs, "вложения удаляются вместе с записью")
+
+	refs, err = store.CountUserFileRefs(ctx, userID, path)
+	require.NoError(t, err)
+	assert.Zero(t, refs)
+}
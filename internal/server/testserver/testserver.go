@@ -0,0 +1,67 @@
+// Package testserver запускает полный сервер gophkeeper (gin, сервис, хранилище в памяти и файлы
+// во временном каталоге) в httptest.NewTLSServer для сквозных тестов без postgres.
+package testserver
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rainset/gophkeeper/internal/server/config"
+	"github.com/rainset/gophkeeper/internal/server/handler"
+	"github.com/rainset/gophkeeper/internal/server/service"
+	"github.com/rainset/gophkeeper/internal/server/storage"
+	"github.com/rainset/gophkeeper/internal/server/storage/file"
+)
+
+type Server struct {
+	*httptest.Server
+
+	Cfg        *config.Config
+	Store      storage.Interface
+	StoreFiles *file.StorageFiles
+	Service    *service.Service
+}
+
+// New запускает сервер; остановка и удаление данных при завершении теста.
+// Запросы и ответы проверяются по спецификации OpenAPI (режим разработки).
+func New(t testing.TB) *Server {
+	t.Helper()
+
+	storeFiles, err := file.NewTemp()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		FileStorage:        storeFiles.Path(),
+		JWTSecretKey:       "test_secret_key",
+		JWTAccessTokenTTL:  "10m",
+		JWTRefreshTokenTTL: "1h",
+		EnableTLS:          true,
+		DevMode:            true,
+	}
+
+	store := storage.NewMemory()
+	svc := service.New(store, storeFiles, cfg)
+	srv := httptest.NewTLSServer(handler.NewHandler(svc).Init())
+	cfg.ServerAddress = srv.Listener.Addr().String()
+
+	t.Cleanup(func() {
+		srv.Close()
+		store.Close()
+		storeFiles.Close()
+	})
+
+	return &Server{
+		Server:     srv,
+		Cfg:        cfg,
+		Store:      store,
+		StoreFiles: storeFiles,
+		Service:    svc,
+	}
+}
+
+// Address адрес сервера host:port для конфигурации клиента.
+func (s *Server) Address() string {
+	return s.Cfg.ServerAddress
+}
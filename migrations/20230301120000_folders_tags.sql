@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+create table folders (
+    "id"         serial primary key,
+    "user_id"    int not null references users on delete cascade,
+    "parent_id"  int references folders (id) on delete cascade,
+    "name"       text not null,
+    "updated_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+create index "folders_user_id_idx" ON folders ("user_id");
+
+create table tags (
+    "id"         serial primary key,
+    "user_id"    int not null references users on delete cascade,
+    "name"       text not null,
+    "updated_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+create index "tags_user_id_idx" ON tags ("user_id");
+
+create table item_tags (
+    "item_type" text not null,
+    "item_id"   int not null,
+    "tag_id"    int not null references tags (id) on delete cascade,
+    primary key ("item_type", "item_id", "tag_id")
+);
+create index "item_tags_tag_id_idx" ON item_tags ("tag_id");
+
+alter table data_cards add column "folder_id" int references folders (id) on delete set null;
+alter table data_creds add column "folder_id" int references folders (id) on delete set null;
+alter table data_text add column "folder_id" int references folders (id) on delete set null;
+alter table data_files add column "folder_id" int references folders (id) on delete set null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table data_cards drop column "folder_id";
+alter table data_creds drop column "folder_id";
+alter table data_text drop column "folder_id";
+alter table data_files drop column "folder_id";
+DROP TABLE "item_tags";
+DROP TABLE "tags";
+DROP TABLE "folders";
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table data_cards add column fields jsonb not null default '[]';
+alter table data_creds add column fields jsonb not null default '[]';
+alter table data_text add column fields jsonb not null default '[]';
+alter table data_files add column fields jsonb not null default '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table data_cards drop column fields;
+alter table data_creds drop column fields;
+alter table data_text drop column fields;
+alter table data_files drop column fields;
+-- +goose StatementEnd
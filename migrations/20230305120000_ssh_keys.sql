@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+create table data_ssh_keys (
+    "id"          serial primary key,
+    "user_id"     int not null references users on delete cascade,
+    "title"       character varying not null,
+    "private_key" text not null,
+    "public_key"  text not null,
+    "fingerprint" text not null,
+    "passphrase"  text not null default '',
+    "meta"        text not null,
+    "folder_id"   int references folders (id) on delete set null,
+    "fields"      jsonb not null default '[]',
+    "updated_at"  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+create index "data_ssh_keys_user_id_idx" ON data_ssh_keys ("user_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from item_tags where item_type = 'ssh';
+DROP TABLE "data_ssh_keys";
+-- +goose StatementEnd
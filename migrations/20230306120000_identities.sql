@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+create table data_identities (
+    "id"          serial primary key,
+    "user_id"     int not null references users on delete cascade,
+    "title"       character varying not null,
+    "kind"        text not null,
+    "full_name"   text not null,
+    "number"      text not null,
+    "country"     text not null default '',
+    "issue_date"  text not null default '',
+    "expiry_date" text not null default '',
+    "address"     text not null default '',
+    "meta"        text not null,
+    "folder_id"   int references folders (id) on delete set null,
+    "fields"      jsonb not null default '[]',
+    "updated_at"  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+create index "data_identities_user_id_idx" ON data_identities ("user_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from item_tags where item_type = 'identity';
+DROP TABLE "data_identities";
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+create table templates (
+    "id"         serial primary key,
+    "user_id"    int not null references users on delete cascade,
+    "name"       text not null,
+    "schema"     text not null,
+    "updated_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+create index "templates_user_id_idx" ON templates ("user_id");
+
+create table data_custom (
+    "id"          serial primary key,
+    "user_id"     int not null references users on delete cascade,
+    "template_id" int not null references templates (id) on delete cascade,
+    "title"       character varying not null,
+    "values"      text not null,
+    "meta"        text not null,
+    "folder_id"   int references folders (id) on delete set null,
+    "fields"      jsonb not null default '[]',
+    "updated_at"  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+create index "data_custom_user_id_idx" ON data_custom ("user_id");
+create index "data_custom_template_id_idx" ON data_custom ("template_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from item_tags where item_type = 'custom';
+DROP TABLE "data_custom";
+DROP TABLE "templates";
+-- +goose StatementEnd
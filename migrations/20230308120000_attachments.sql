@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+create table attachments (
+    "id"         serial primary key,
+    "user_id"    int not null references users on delete cascade,
+    "item_type"  character varying not null,
+    "item_id"    int not null,
+    "filename"   text not null,
+    "path"       character varying not null,
+    "updated_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+create index "attachments_item_idx" ON attachments ("user_id", "item_type", "item_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "attachments";
+-- +goose StatementEnd
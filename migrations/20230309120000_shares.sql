@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+create table user_keys (
+    "user_id"     int primary key references users on delete cascade,
+    "public_key"  text not null,
+    "private_key" text not null,
+    "updated_at"  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+create table shares (
+    "id"           serial primary key,
+    "user_id"      int not null references users on delete cascade,
+    "recipient_id" int not null references users (id) on delete cascade,
+    "item_type"    character varying not null,
+    "item_id"      int not null,
+    "access"       character varying not null,
+    "item_key"     text not null,
+    "owner_key"    text not null,
+    "data"         text not null,
+    "updated_at"   timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    unique ("user_id", "recipient_id", "item_type", "item_id")
+);
+create index "shares_recipient_id_idx" ON shares ("recipient_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "shares";
+DROP TABLE "user_keys";
+-- +goose StatementEnd
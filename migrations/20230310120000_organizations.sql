@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+create table organizations (
+    "id"         serial primary key,
+    "name"       character varying not null,
+    "updated_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+create table org_members (
+    "org_id"     int not null references organizations on delete cascade,
+    "user_id"    int not null references users on delete cascade,
+    "role"       character varying not null,
+    "status"     character varying not null,
+    "updated_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    primary key ("org_id", "user_id")
+);
+create index "org_members_user_id_idx" ON org_members ("user_id");
+
+create table collections (
+    "id"         serial primary key,
+    "org_id"     int not null references organizations on delete cascade,
+    "name"       character varying not null,
+    "updated_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+create table collection_keys (
+    "collection_id" int not null references collections on delete cascade,
+    "user_id"       int not null references users on delete cascade,
+    "key"           text not null,
+    primary key ("collection_id", "user_id")
+);
+
+create table collection_items (
+    "id"            serial primary key,
+    "collection_id" int not null references collections on delete cascade,
+    "item_type"     character varying not null,
+    "data"          text not null,
+    "updated_at"    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+create index "collection_items_collection_id_idx" ON collection_items ("collection_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "collection_items";
+DROP TABLE "collection_keys";
+DROP TABLE "collections";
+DROP TABLE "org_members";
+DROP TABLE "organizations";
+-- +goose StatementEnd
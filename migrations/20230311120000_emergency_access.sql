@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+create table emergency_access (
+    "id"           serial primary key,
+    "user_id"      int not null references users on delete cascade,
+    "grantee_id"   int not null references users (id) on delete cascade,
+    "wait_hours"   int not null,
+    "key"          text not null,
+    "status"       character varying not null,
+    "requested_at" timestamptz not null,
+    "updated_at"   timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    unique ("user_id", "grantee_id")
+);
+create index "emergency_access_grantee_id_idx" ON emergency_access ("grantee_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "emergency_access";
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+create table secrets (
+    "id"         character varying primary key,
+    "user_id"    int not null references users on delete cascade,
+    "data"       text not null,
+    "max_views"  int not null,
+    "views"      int not null default 0,
+    "expires_at" timestamptz not null,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+create index "secrets_user_id_idx" ON secrets ("user_id");
+create index "secrets_expires_at_idx" ON secrets ("expires_at");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "secrets";
+-- +goose StatementEnd
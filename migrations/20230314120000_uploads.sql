@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+create table uploads (
+    "id"            character varying primary key,
+    "user_id"       int not null references users on delete cascade,
+    "size"          bigint not null,
+    "chunk_size"    bigint not null,
+    "upload_offset" bigint not null default 0,
+    "expires_at"    timestamptz not null,
+    "created_at"    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+create index "uploads_user_id_idx" ON uploads ("user_id");
+create index "uploads_expires_at_idx" ON uploads ("expires_at");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "uploads";
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table data_files add column sha256 character varying not null default '';
+update data_files set sha256 = right(path, 64) where path like '_file_storage/sha256/%';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table data_files drop column sha256;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table uploads add column sha256 character varying not null default '';
+create index "uploads_user_id_sha256_idx" ON uploads ("user_id", "sha256");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index "uploads_user_id_sha256_idx";
+alter table uploads drop column sha256;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table collection_items add column user_id int references users on delete set null;
+create index "collection_items_user_id_idx" ON collection_items ("user_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index "collection_items_user_id_idx";
+alter table collection_items drop column user_id;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+// Package migrations содержит SQL миграции схемы БД, встроенные в бинарный файл сервера.
+package migrations
+
+import "embed"
+
+// Postgres миграции для PostgreSQL, каталог ".".
+//
+//go:embed *.sql
+var Postgres embed.FS
+
+// SQLite миграции для SQLite, каталог "sqlite".
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
@@ -0,0 +1,70 @@
+-- +goose Up
+-- +goose StatementBegin
+create table users (
+    id         integer primary key autoincrement,
+    login      text not null unique,
+    password   text not null,
+    sign_key   text not null,
+    created_at timestamp not null default current_timestamp,
+    updated_at timestamp not null default current_timestamp
+);
+create index users_login_idx on users (login);
+
+create table data_creds (
+    id         integer primary key autoincrement,
+    user_id    integer not null references users (id) on delete cascade,
+    title      text not null,
+    username   text not null,
+    password   text not null,
+    meta       text not null,
+    updated_at timestamp not null default current_timestamp
+);
+
+create table data_files (
+    id         integer primary key autoincrement,
+    user_id    integer references users (id) on delete cascade,
+    title      text not null,
+    filename   text not null,
+    path       text not null,
+    meta       text not null,
+    updated_at timestamp not null default current_timestamp
+);
+
+create table data_cards (
+    id         integer primary key autoincrement,
+    user_id    integer not null references users (id) on delete cascade,
+    title      text not null,
+    number     text not null,
+    date       text not null,
+    cvv        text not null,
+    meta       text not null,
+    updated_at timestamp not null default current_timestamp
+);
+
+create table data_text (
+    id         integer primary key autoincrement,
+    user_id    integer not null references users (id) on delete cascade,
+    title      text not null,
+    text       text not null,
+    meta       text not null,
+    updated_at timestamp not null default current_timestamp
+);
+
+create table refresh_tokens (
+    id         integer primary key autoincrement,
+    user_id    integer not null references users (id) on delete cascade,
+    token      text not null,
+    created_at timestamp not null default current_timestamp,
+    expired_at timestamp not null default current_timestamp
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table data_cards;
+drop table data_files;
+drop table data_creds;
+drop table data_text;
+drop table refresh_tokens;
+drop table users;
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+create table folders (
+    id         integer primary key autoincrement,
+    user_id    integer not null references users (id) on delete cascade,
+    parent_id  integer references folders (id) on delete cascade,
+    name       text not null,
+    updated_at timestamp not null default current_timestamp
+);
+create index folders_user_id_idx on folders (user_id);
+
+create table tags (
+    id         integer primary key autoincrement,
+    user_id    integer not null references users (id) on delete cascade,
+    name       text not null,
+    updated_at timestamp not null default current_timestamp
+);
+create index tags_user_id_idx on tags (user_id);
+
+create table item_tags (
+    item_type text not null,
+    item_id   integer not null,
+    tag_id    integer not null references tags (id) on delete cascade,
+    primary key (item_type, item_id, tag_id)
+);
+create index item_tags_tag_id_idx on item_tags (tag_id);
+
+-- без внешнего ключа: SQLite не удаляет такие столбцы в Down, folder_id обнуляется в DeleteFolder
+alter table data_cards add column folder_id integer;
+alter table data_creds add column folder_id integer;
+alter table data_text add column folder_id integer;
+alter table data_files add column folder_id integer;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table data_cards drop column folder_id;
+alter table data_creds drop column folder_id;
+alter table data_text drop column folder_id;
+alter table data_files drop column folder_id;
+drop table item_tags;
+drop table tags;
+drop table folders;
+-- +goose StatementEnd
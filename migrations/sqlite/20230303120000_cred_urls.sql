@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table data_creds add column urls text not null default '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table data_creds drop column urls;
+-- +goose StatementEnd
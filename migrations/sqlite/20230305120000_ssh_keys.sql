@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+create table data_ssh_keys (
+    id          integer primary key autoincrement,
+    user_id     integer not null references users (id) on delete cascade,
+    title       text not null,
+    private_key text not null,
+    public_key  text not null,
+    fingerprint text not null,
+    passphrase  text not null default '',
+    meta        text not null,
+    folder_id   integer,
+    fields      text not null default '[]',
+    updated_at  timestamp not null default current_timestamp
+);
+create index data_ssh_keys_user_id_idx on data_ssh_keys (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from item_tags where item_type = 'ssh';
+drop table data_ssh_keys;
+-- +goose StatementEnd
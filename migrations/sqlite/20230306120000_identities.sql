@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+create table data_identities (
+    id          integer primary key autoincrement,
+    user_id     integer not null references users (id) on delete cascade,
+    title       text not null,
+    kind        text not null,
+    full_name   text not null,
+    number      text not null,
+    country     text not null default '',
+    issue_date  text not null default '',
+    expiry_date text not null default '',
+    address     text not null default '',
+    meta        text not null,
+    folder_id   integer,
+    fields      text not null default '[]',
+    updated_at  timestamp not null default current_timestamp
+);
+create index data_identities_user_id_idx on data_identities (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from item_tags where item_type = 'identity';
+drop table data_identities;
+-- +goose StatementEnd
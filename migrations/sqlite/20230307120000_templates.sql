@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+create table templates (
+    id         integer primary key autoincrement,
+    user_id    integer not null references users (id) on delete cascade,
+    name       text not null,
+    schema     text not null,
+    updated_at timestamp not null default current_timestamp
+);
+create index templates_user_id_idx on templates (user_id);
+
+create table data_custom (
+    id          integer primary key autoincrement,
+    user_id     integer not null references users (id) on delete cascade,
+    template_id integer not null references templates (id) on delete cascade,
+    title       text not null,
+    "values"    text not null,
+    meta        text not null,
+    folder_id   integer,
+    fields      text not null default '[]',
+    updated_at  timestamp not null default current_timestamp
+);
+create index data_custom_user_id_idx on data_custom (user_id);
+create index data_custom_template_id_idx on data_custom (template_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from item_tags where item_type = 'custom';
+drop table data_custom;
+drop table templates;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+create table attachments (
+    id         integer primary key autoincrement,
+    user_id    integer not null references users (id) on delete cascade,
+    item_type  text not null,
+    item_id    integer not null,
+    filename   text not null,
+    path       text not null,
+    updated_at timestamp not null default current_timestamp
+);
+create index attachments_item_idx on attachments (user_id, item_type, item_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table attachments;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+create table user_keys (
+    user_id     integer primary key references users (id) on delete cascade,
+    public_key  text not null,
+    private_key text not null,
+    updated_at  timestamp not null default current_timestamp
+);
+
+create table shares (
+    id           integer primary key autoincrement,
+    user_id      integer not null references users (id) on delete cascade,
+    recipient_id integer not null references users (id) on delete cascade,
+    item_type    text not null,
+    item_id      integer not null,
+    access       text not null,
+    item_key     text not null,
+    owner_key    text not null,
+    data         text not null,
+    updated_at   timestamp not null default current_timestamp,
+    unique (user_id, recipient_id, item_type, item_id)
+);
+create index shares_recipient_id_idx on shares (recipient_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table shares;
+drop table user_keys;
+-- +goose StatementEnd
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+create table organizations (
+    id         integer primary key autoincrement,
+    name       text not null,
+    updated_at timestamp not null default current_timestamp
+);
+
+create table org_members (
+    org_id     integer not null references organizations (id) on delete cascade,
+    user_id    integer not null references users (id) on delete cascade,
+    role       text not null,
+    status     text not null,
+    updated_at timestamp not null default current_timestamp,
+    primary key (org_id, user_id)
+);
+create index org_members_user_id_idx on org_members (user_id);
+
+create table collections (
+    id         integer primary key autoincrement,
+    org_id     integer not null references organizations (id) on delete cascade,
+    name       text not null,
+    updated_at timestamp not null default current_timestamp
+);
+
+create table collection_keys (
+    collection_id integer not null references collections (id) on delete cascade,
+    user_id       integer not null references users (id) on delete cascade,
+    key           text not null,
+    primary key (collection_id, user_id)
+);
+
+create table collection_items (
+    id            integer primary key autoincrement,
+    collection_id integer not null references collections (id) on delete cascade,
+    item_type     text not null,
+    data          text not null,
+    updated_at    timestamp not null default current_timestamp
+);
+create index collection_items_collection_id_idx on collection_items (collection_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table collection_items;
+drop table collection_keys;
+drop table collections;
+drop table org_members;
+drop table organizations;
+-- +goose StatementEnd
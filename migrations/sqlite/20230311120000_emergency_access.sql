@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+create table emergency_access (
+    id           integer primary key autoincrement,
+    user_id      integer not null references users (id) on delete cascade,
+    grantee_id   integer not null references users (id) on delete cascade,
+    wait_hours   integer not null,
+    key          text not null,
+    status       text not null,
+    requested_at timestamp not null,
+    updated_at   timestamp not null default current_timestamp,
+    unique (user_id, grantee_id)
+);
+create index emergency_access_grantee_id_idx on emergency_access (grantee_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table emergency_access;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+create table secrets (
+    id         text primary key,
+    user_id    integer not null references users (id) on delete cascade,
+    data       text not null,
+    max_views  integer not null,
+    views      integer not null default 0,
+    expires_at timestamp not null,
+    created_at timestamp not null default current_timestamp
+);
+create index secrets_user_id_idx on secrets (user_id);
+create index secrets_expires_at_idx on secrets (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table secrets;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table data_files add column size integer not null default 0;
+alter table attachments add column size integer not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table attachments drop column size;
+alter table data_files drop column size;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+create table uploads (
+    id            text primary key,
+    user_id       integer not null references users (id) on delete cascade,
+    size          integer not null,
+    chunk_size    integer not null,
+    upload_offset integer not null default 0,
+    expires_at    timestamp not null,
+    created_at    timestamp not null default current_timestamp
+);
+create index uploads_user_id_idx on uploads (user_id);
+create index uploads_expires_at_idx on uploads (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table uploads;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- блокировки содержимого между процессами (сервер и server gc); в PostgreSQL вместо них pg_advisory_xact_lock
+create table file_locks (
+    path       text primary key,
+    owner      text not null,
+    expires_at timestamp not null
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table file_locks;
+-- +goose StatementEnd
@@ -0,0 +1,97 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// X25519KeySize размер открытого и закрытого ключей X25519.
+const X25519KeySize = curve25519.ScalarSize
+
+// x25519Info контекст вывода ключа шифрования из общего секрета X25519.
+var x25519Info = []byte("gophkeeper x25519 seal")
+
+// GenerateX25519 создает пару ключей X25519.
+func GenerateX25519() (public, private []byte, err error) {
+	private = make([]byte, X25519KeySize)
+	if _, err = io.ReadFull(rand.Reader, private); err != nil {
+		return nil, nil, err
+	}
+
+	public, err = curve25519.X25519(private, curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return public, private, nil
+}
+
+// SealX25519 шифрует data для владельца открытого ключа recipient: общий секрет эфемерного ключа
+// и ключа получателя через HKDF-SHA256 дает ключ AES-256-GCM. Результат - эфемерный открытый ключ и шифртекст Encrypt.
+func SealX25519(data, recipient []byte) ([]byte, error) {
+	ephPublic, ephPrivate, err := GenerateX25519()
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := curve25519.X25519(ephPrivate, recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x25519Key(secret, ephPublic, recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := Encrypt(data, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ephPublic, sealed...), nil
+}
+
+// OpenX25519 расшифровывает результат SealX25519 закрытым ключом получателя.
+func OpenX25519(sealed, private []byte) ([]byte, error) {
+	if len(sealed) < X25519KeySize {
+		return nil, errors.New("sealed data too short")
+	}
+
+	public, err := curve25519.X25519(private, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	ephPublic := sealed[:X25519KeySize]
+
+	secret, err := curve25519.X25519(private, ephPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x25519Key(secret, ephPublic, public)
+	if err != nil {
+		return nil, err
+	}
+
+	return Decrypt(sealed[X25519KeySize:], key)
+}
+
+// x25519Key ключ AES-256 из общего секрета; открытые ключи обеих сторон входят в соль,
+// чтобы шифртекст нельзя было переадресовать другому получателю.
+func x25519Key(secret, ephPublic, recipient []byte) ([]byte, error) {
+	salt := append(append([]byte{}, ephPublic...), recipient...)
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, x25519Info), key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
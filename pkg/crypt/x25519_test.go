@@ -0,0 +1,47 @@
+package crypt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealX25519(t *testing.T) {
+	public, private, err := GenerateX25519()
+	require.NoError(t, err)
+	require.Len(t, public, X25519KeySize)
+	require.Len(t, private, X25519KeySize)
+
+	otherPublic, otherPrivate, err := GenerateX25519()
+	require.NoError(t, err)
+	assert.NotEqual(t, public, otherPublic)
+
+	sealed, err := SealX25519([]byte("item key"), public)
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "item key")
+
+	tests := []struct {
+		name    string
+		sealed  []byte
+		private []byte
+		want    []byte
+		wantErr bool
+	}{
+		{name: "recipient", sealed: sealed, private: private, want: []byte("item key")},
+		{name: "other key", sealed: sealed, private: otherPrivate, wantErr: true},
+		{name: "tampered", sealed: append(append([]byte{}, sealed[:len(sealed)-1]...), sealed[len(sealed)-1]^1), private: private, wantErr: true},
+		{name: "too short", sealed: sealed[:X25519KeySize-1], private: private, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OpenX25519(tt.sealed, tt.private)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Package totp генерирует одноразовые коды по времени (RFC 6238) и разбирает otpauth:// URI.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithm хеш-функция HMAC.
+type Algorithm string
+
+const (
+	SHA1   Algorithm = "SHA1"
+	SHA256 Algorithm = "SHA256"
+	SHA512 Algorithm = "SHA512"
+)
+
+// Значения по умолчанию из Key Uri Format (Google Authenticator).
+const (
+	DefaultDigits = 6
+	DefaultPeriod = 30
+)
+
+var (
+	ErrSecretInvalid    = errors.New("totp: invalid base32 secret")
+	ErrAlgorithmInvalid = errors.New("totp: unsupported algorithm")
+	ErrDigitsInvalid    = errors.New("totp: digits must be 6 or 8")
+	ErrPeriodInvalid    = errors.New("totp: period must be positive")
+	ErrURIInvalid       = errors.New("totp: invalid otpauth uri")
+)
+
+// Key параметры генератора кодов.
+type Key struct {
+	Secret    []byte
+	Algorithm Algorithm
+	Digits    int
+	Period    int
+	Issuer    string
+	Account   string
+}
+
+func (k Key) Validate() error {
+	if len(k.Secret) == 0 {
+		return ErrSecretInvalid
+	}
+
+	if _, err := k.Algorithm.hash(); err != nil {
+		return err
+	}
+
+	if k.Digits != 6 && k.Digits != 8 {
+		return ErrDigitsInvalid
+	}
+
+	if k.Period <= 0 {
+		return ErrPeriodInvalid
+	}
+
+	return nil
+}
+
+func (a Algorithm) hash() (func() hash.Hash, error) {
+	switch a {
+	case SHA1:
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	}
+
+	return nil, ErrAlgorithmInvalid
+}
+
+// Generate возвращает код для момента t.
+func Generate(k Key, t time.Time) (string, error) {
+	err := k.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	h, _ := k.Algorithm.hash()
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(t.Unix()/int64(k.Period)))
+
+	mac := hmac.New(h, k.Secret)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// динамическое усечение, RFC 4226 раздел 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < k.Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", k.Digits, code%mod), nil
+}
+
+// Remaining время до смены кода, действующего в момент t.
+func Remaining(k Key, t time.Time) time.Duration {
+	if k.Period <= 0 {
+		return 0
+	}
+
+	period := time.Duration(k.Period) * time.Second
+
+	return period - time.Duration(t.UnixNano())%period
+}
+
+// DecodeSecret декодирует base32-секрет; пробелы, регистр и отсутствие дополнения не важны.
+func DecodeSecret(s string) ([]byte, error) {
+	s = strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	s = strings.TrimRight(s, "=")
+
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	if err != nil || len(secret) == 0 {
+		return nil, ErrSecretInvalid
+	}
+
+	return secret, nil
+}
+
+// ParseURI разбирает otpauth://totp/Issuer:account?secret=...&algorithm=...&digits=...&period=...
+func ParseURI(uri string) (k Key, err error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || u.Scheme != "otpauth" || !strings.EqualFold(u.Host, "totp") {
+		return k, ErrURIInvalid
+	}
+
+	q := u.Query()
+
+	k.Secret, err = DecodeSecret(q.Get("secret"))
+	if err != nil {
+		return k, err
+	}
+
+	k.Algorithm = SHA1
+	if v := q.Get("algorithm"); v != "" {
+		k.Algorithm = Algorithm(strings.ToUpper(v))
+	}
+
+	k.Digits = DefaultDigits
+	if v := q.Get("digits"); v != "" {
+		k.Digits, err = strconv.Atoi(v)
+		if err != nil {
+			return k, ErrDigitsInvalid
+		}
+	}
+
+	k.Period = DefaultPeriod
+	if v := q.Get("period"); v != "" {
+		k.Period, err = strconv.Atoi(v)
+		if err != nil {
+			return k, ErrPeriodInvalid
+		}
+	}
+
+	// метка имеет вид "Issuer:account" или "account", параметр issuer приоритетнее
+	label := strings.TrimPrefix(u.Path, "/")
+	if i := strings.Index(label, ":"); i >= 0 {
+		k.Issuer, k.Account = strings.TrimSpace(label[:i]), strings.TrimSpace(label[i+1:])
+	} else {
+		k.Account = strings.TrimSpace(label)
+	}
+	if v := q.Get("issuer"); v != "" {
+		k.Issuer = v
+	}
+
+	return k, k.Validate()
+}
+
+// Parse принимает otpauth:// URI или base32-секрет с параметрами по умолчанию.
+func Parse(s string) (Key, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToLower(s), "otpauth://") {
+		return ParseURI(s)
+	}
+
+	secret, err := DecodeSecret(s)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{Secret: secret, Algorithm: SHA1, Digits: DefaultDigits, Period: DefaultPeriod}, nil
+}
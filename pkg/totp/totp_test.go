@@ -0,0 +1,138 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Тестовые векторы RFC 6238, приложение B.
+func TestGenerate_RFC6238(t *testing.T) {
+	secrets := map[Algorithm][]byte{
+		SHA1:   []byte("12345678901234567890"),
+		SHA256: []byte("12345678901234567890123456789012"),
+		SHA512: []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+
+	tests := []struct {
+		unix int64
+		alg  Algorithm
+		want string
+	}{
+		{59, SHA1, "94287082"},
+		{59, SHA256, "46119246"},
+		{59, SHA512, "90693936"},
+		{1111111109, SHA1, "07081804"},
+		{1111111109, SHA256, "68084774"},
+		{1111111109, SHA512, "25091201"},
+		{1111111111, SHA1, "14050471"},
+		{1111111111, SHA256, "67062674"},
+		{1111111111, SHA512, "99943326"},
+		{1234567890, SHA1, "89005924"},
+		{1234567890, SHA256, "91819424"},
+		{1234567890, SHA512, "93441116"},
+		{2000000000, SHA1, "69279037"},
+		{2000000000, SHA256, "90698825"},
+		{2000000000, SHA512, "38618901"},
+		{20000000000, SHA1, "65353130"},
+		{20000000000, SHA256, "77737706"},
+		{20000000000, SHA512, "47863826"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.alg)+"/"+time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			k := Key{Secret: secrets[tt.alg], Algorithm: tt.alg, Digits: 8, Period: 30}
+			got, err := Generate(k, time.Unix(tt.unix, 0))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGenerate_invalid(t *testing.T) {
+	valid := Key{Secret: []byte("secret"), Algorithm: SHA1, Digits: 6, Period: 30}
+
+	tests := []struct {
+		name    string
+		modify  func(k *Key)
+		wantErr error
+	}{
+		{name: "no secret", modify: func(k *Key) { k.Secret = nil }, wantErr: ErrSecretInvalid},
+		{name: "algorithm", modify: func(k *Key) { k.Algorithm = "MD5" }, wantErr: ErrAlgorithmInvalid},
+		{name: "digits", modify: func(k *Key) { k.Digits = 7 }, wantErr: ErrDigitsInvalid},
+		{name: "period", modify: func(k *Key) { k.Period = 0 }, wantErr: ErrPeriodInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := valid
+			tt.modify(&k)
+			_, err := Generate(k, time.Now())
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	k := Key{Period: 30}
+	assert.Equal(t, 30*time.Second, Remaining(k, time.Unix(60, 0)))
+	assert.Equal(t, 1*time.Second, Remaining(k, time.Unix(89, 0)))
+	assert.Equal(t, 500*time.Millisecond, Remaining(k, time.Unix(89, int64(500*time.Millisecond))))
+}
+
+func TestParseURI(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		name    string
+		uri     string
+		want    Key
+		wantErr error
+	}{
+		{
+			name: "defaults",
+			uri:  "otpauth://totp/Example:alice@example.com?secret=" + secret + "&issuer=Example",
+			want: Key{Secret: []byte("12345678901234567890"), Algorithm: SHA1, Digits: 6, Period: 30, Issuer: "Example", Account: "alice@example.com"},
+		},
+		{
+			name: "custom params",
+			uri:  "otpauth://totp/ACME%20Co:john?secret=" + secret + "&algorithm=sha256&digits=8&period=60",
+			want: Key{Secret: []byte("12345678901234567890"), Algorithm: SHA256, Digits: 8, Period: 60, Issuer: "ACME Co", Account: "john"},
+		},
+		{
+			name: "no issuer",
+			uri:  "otpauth://totp/john?secret=" + secret,
+			want: Key{Secret: []byte("12345678901234567890"), Algorithm: SHA1, Digits: 6, Period: 30, Account: "john"},
+		},
+		{name: "hotp", uri: "otpauth://hotp/john?secret=" + secret + "&counter=1", wantErr: ErrURIInvalid},
+		{name: "no secret", uri: "otpauth://totp/john", wantErr: ErrSecretInvalid},
+		{name: "bad digits", uri: "otpauth://totp/john?secret=" + secret + "&digits=x", wantErr: ErrDigitsInvalid},
+		{name: "bad algorithm", uri: "otpauth://totp/john?secret=" + secret + "&algorithm=MD5", wantErr: ErrAlgorithmInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseURI(tt.uri)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	k, err := Parse("gezd gnbv gy3t qojq gezd gnbv gy3t qojq")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("12345678901234567890"), k.Secret)
+	assert.Equal(t, DefaultDigits, k.Digits)
+
+	k, err = Parse("OTPAUTH://totp/john?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	require.NoError(t, err)
+	assert.Equal(t, "john", k.Account)
+
+	_, err = Parse("not a secret!")
+	assert.ErrorIs(t, err, ErrSecretInvalid)
+}